@@ -2,36 +2,49 @@ package autojoin
 
 import (
 	"context"
-	"database/sql"
 	"fmt"
 	"log"
+	"sync"
 	"time"
 
 	waProto "go.mau.fi/whatsmeow/binary/proto"
 	"go.mau.fi/whatsmeow/types"
 	"go.mau.fi/whatsmeow/types/events"
 
+	"promote/internal/model"
+	"promote/internal/sender"
 	"promote/internal/storage"
 	"promote/internal/wa"
+	"promote/internal/webhook"
 )
 
 // AutoJoiner handles automatic group joining from invite links
 type AutoJoiner struct {
 	Store   *storage.Store
 	Manager *wa.Manager
-	
-	// Rate limiting: last join time per account
-	lastJoinTime map[string]time.Time
-	minInterval  time.Duration // Minimum interval between joins (default: 3 seconds)
+	// Hooks (opsional) mengirim event autojoin.* ke webhook.
+	Hooks *webhook.Dispatcher
+	// Sender (opsional) mengirim template intro pasca-join; nil = intro tidak dikirim.
+	Sender *sender.Sender
+	
+	// Rate limiting: waktu paling awal join berikutnya per akun (jeda acak join_jitter_*_sec)
+	mu         sync.Mutex
+	nextJoinAt map[string]time.Time
+
+	// wake membangunkan worker antrean persetujuan
+	wake chan struct{}
+	// retry percobaan ulang join yang gagal sementara
+	retry retryConfig
 }
 
 // New creates a new AutoJoiner instance
 func New(store *storage.Store, manager *wa.Manager) *AutoJoiner {
 	return &AutoJoiner{
-		Store:        store,
-		Manager:      manager,
-		lastJoinTime: make(map[string]time.Time),
-		minInterval:  3 * time.Second, // Safe default
+		Store:      store,
+		Manager:    manager,
+		nextJoinAt: make(map[string]time.Time),
+		wake:       make(chan struct{}, 1),
+		retry:      retryConfigFromEnv(),
 	}
 }
 
@@ -75,77 +88,111 @@ func (aj *AutoJoiner) HandleMessage(accountID string, evt *events.Message) {
 
 // ProcessInviteCode processes a single invite code
 func (aj *AutoJoiner) ProcessInviteCode(ctx context.Context, accountID, inviteCode, sharedBy, sharedIn string) {
+	aj.process(ctx, accountID, inviteCode, sharedBy, sharedIn, false)
+}
+
+// process menjalankan filter dan join untuk satu kode undangan dan mengembalikan status akhir
+// (joined/failed/skipped, pending jika masuk antrean persetujuan, atau retrying jika gagal
+// sementara dan dijadwalkan ulang atau ditunda ke jendela join berikutnya) beserta alasannya.
+// approved=true untuk item antrean yang sudah disetujui operator: flag enabled,
+// whitelist/blacklist dan batas jumlah anggota tidak dicek ulang, dan jeda antar join ditunggu alih-alih di-skip.
+func (aj *AutoJoiner) process(ctx context.Context, accountID, inviteCode, sharedBy, sharedIn string, approved bool) (string, string) {
 	// Normalize and validate code
 	code := NormalizeInviteCode(inviteCode)
 	if !ValidateInviteCode(code) {
 		log.Printf("[autojoin] invalid invite code: %s", inviteCode)
-		aj.logAttempt(accountID, "", "", code, sharedBy, sharedIn, "skipped", string(FilterReasonInvalidCode))
-		return
+		return aj.skip(accountID, "", code, sharedBy, sharedIn, FilterReasonInvalidCode)
 	}
 	
 	// Load settings for this account
-	settings, err := aj.loadSettings(accountID)
+	settings, err := aj.Store.LoadAutoJoinSettings(accountID)
 	if err != nil {
 		log.Printf("[autojoin] failed to load settings for account %s: %v", accountID, err)
-		return
+		return model.AutoJoinFailed, err.Error()
 	}
 	
 	// Check if auto-join is enabled
-	if !settings.Enabled {
+	if !settings.Enabled && !approved {
 		log.Printf("[autojoin] auto-join disabled for account %s", accountID)
-		aj.logAttempt(accountID, "", "", code, sharedBy, sharedIn, "skipped", string(FilterReasonDisabled))
-		return
+		return aj.skip(accountID, "", code, sharedBy, sharedIn, FilterReasonDisabled)
 	}
 	
 	// Count joins today
-	joinsToday, err := aj.countJoinsToday(accountID)
+	joinsToday, err := aj.Store.CountJoinsToday(accountID)
 	if err != nil {
 		log.Printf("[autojoin] failed to count joins today: %v", err)
-		return
+		return model.AutoJoinFailed, err.Error()
 	}
 	
 	// Create filter
 	filter := &Filter{
-		Enabled:            settings.Enabled,
+		Enabled:            true,
 		DailyLimit:         settings.DailyLimit,
-		WhitelistContacts:  ParseJSONArray(settings.WhitelistContacts),
-		BlacklistKeywords:  ParseJSONArray(settings.BlacklistKeywords),
 		PreviewBeforeJoin:  settings.PreviewBeforeJoin,
 	}
+	if !approved {
+		filter.WhitelistContacts = settings.WhitelistContacts
+		filter.BlacklistKeywords = settings.BlacklistKeywords
+		filter.MinParticipants = settings.MinParticipants
+		filter.MaxParticipants = settings.MaxParticipants
+	}
 	
 	// Preview group info if enabled
 	var groupName string
+	participants := -1
 	if filter.PreviewBeforeJoin {
 		groupInfo, err := aj.previewGroup(ctx, accountID, code)
 		if err != nil {
 			log.Printf("[autojoin] failed to preview group: %v", err)
-			aj.logAttempt(accountID, "", "", code, sharedBy, sharedIn, "failed", fmt.Sprintf("preview_failed: %v", err))
-			return
+			reason, retrying := aj.retryLater(accountID, "", code, sharedBy, sharedIn, fmt.Errorf("preview_failed: %w", err))
+			aj.logAttempt(accountID, "", "", code, sharedBy, sharedIn, model.AutoJoinFailed, reason)
+			if retrying {
+				return model.AutoJoinRetrying, reason
+			}
+			return model.AutoJoinFailed, reason
 		}
 		groupName = groupInfo.Name
+		participants = len(groupInfo.Participants)
 		log.Printf("[autojoin] preview: group '%s' has %d participants", groupName, len(groupInfo.Participants))
 	}
 	
+	// Check if already joined
+	if aj.isAlreadyJoined(accountID, code) {
+		log.Printf("[autojoin] already joined this group (code: %s)", code)
+		return aj.skip(accountID, groupName, code, sharedBy, sharedIn, FilterReasonAlreadyJoined)
+	}
+	
 	// Apply filters
-	shouldJoin, reason := filter.ShouldJoin(sharedBy, groupName, int(joinsToday))
+	shouldJoin, reason := filter.ShouldJoin(sharedBy, groupName, participants, joinsToday)
+	
+	// Mode persetujuan: link yang lolos filter (limit harian tidak menghalangi antre) menunggu
+	// keputusan operator. Join manual sudah merupakan keputusan operator.
+	if settings.RequireApproval && !approved && sharedBy != "manual" && (shouldJoin || reason == FilterReasonDailyLimit) {
+		return aj.enqueue(accountID, groupName, code, sharedBy, sharedIn)
+	}
+	
 	if !shouldJoin {
 		log.Printf("[autojoin] skipped joining group (code: %s) - reason: %s", code, reason)
-		aj.logAttempt(accountID, "", groupName, code, sharedBy, sharedIn, "skipped", string(reason))
-		return
+		return aj.skip(accountID, groupName, code, sharedBy, sharedIn, reason)
 	}
 	
-	// Check rate limiting
-	if !aj.checkRateLimit(accountID) {
-		log.Printf("[autojoin] rate limit - waiting before next join")
-		aj.logAttempt(accountID, "", groupName, code, sharedBy, sharedIn, "skipped", string(FilterReasonRateLimit))
-		return
+	// Jendela join: di luar jam yang diizinkan link ditunda ke antrean sampai jendela berikutnya
+	// (item antrean sudah dicek drainQueue). Join manual adalah keputusan operator dan tidak
+	// dibatasi jendela.
+	if open, next := nextJoinWindow(settings, time.Now()); !open && !approved && sharedBy != "manual" {
+		return aj.deferToWindow(model.AutoJoinQueueItem{
+			AccountID:  accountID,
+			InviteCode: code,
+			GroupName:  groupName,
+			SharedBy:   sharedBy,
+			SharedIn:   sharedIn,
+		}, next)
 	}
 	
-	// Check if already joined
-	if aj.isAlreadyJoined(accountID, code) {
-		log.Printf("[autojoin] already joined this group (code: %s)", code)
-		aj.logAttempt(accountID, "", groupName, code, sharedBy, sharedIn, "skipped", string(FilterReasonAlreadyJoined))
-		return
+	// Check rate limiting
+	if !approved && !aj.checkRateLimit(accountID) {
+		log.Printf("[autojoin] rate limit - waiting before next join")
+		return aj.skip(accountID, groupName, code, sharedBy, sharedIn, FilterReasonRateLimit)
 	}
 	
 	// Rate limit: wait before joining
@@ -155,8 +202,12 @@ func (aj *AutoJoiner) ProcessInviteCode(ctx context.Context, accountID, inviteCo
 	groupJID, err := aj.joinGroup(ctx, accountID, code)
 	if err != nil {
 		log.Printf("[autojoin] failed to join group (code: %s): %v", code, err)
-		aj.logAttempt(accountID, "", groupName, code, sharedBy, sharedIn, "failed", err.Error())
-		return
+		reason, retrying := aj.retryLater(accountID, groupName, code, sharedBy, sharedIn, err)
+		aj.logAttempt(accountID, "", groupName, code, sharedBy, sharedIn, model.AutoJoinFailed, reason)
+		if retrying {
+			return model.AutoJoinRetrying, reason
+		}
+		return model.AutoJoinFailed, reason
 	}
 	
 	// Success!
@@ -172,10 +223,16 @@ func (aj *AutoJoiner) ProcessInviteCode(ctx context.Context, accountID, inviteCo
 	}
 	
 	// Log success
-	aj.logAttempt(accountID, groupJID.String(), groupName, code, sharedBy, sharedIn, "joined", "")
+	aj.logAttempt(accountID, groupJID.String(), groupName, code, sharedBy, sharedIn, model.AutoJoinJoined, "")
+	if settings.AutoEnableAfterJoin {
+		aj.scheduleEnable(accountID, groupJID.String(), groupName, settings.AutoEnableSettleMin)
+	}
+	aj.scheduleIntro(settings, groupJID.String())
 	
-	// Update last join time
-	aj.lastJoinTime[accountID] = time.Now()
+	// Jadwalkan join berikutnya setelah jeda acak
+	aj.mu.Lock()
+	aj.nextJoinAt[accountID] = time.Now().Add(joinJitter(settings))
+	aj.mu.Unlock()
 	
 	// Sync groups to database (async)
 	go func() {
@@ -184,6 +241,13 @@ func (aj *AutoJoiner) ProcessInviteCode(ctx context.Context, accountID, inviteCo
 			log.Printf("[autojoin] failed to sync groups after join: %v", err)
 		}
 	}()
+	return model.AutoJoinJoined, ""
+}
+
+// skip mencatat percobaan yang di-skip dan mengembalikan status/alasannya.
+func (aj *AutoJoiner) skip(accountID, groupName, code, sharedBy, sharedIn string, reason FilterReason) (string, string) {
+	aj.logAttempt(accountID, "", groupName, code, sharedBy, sharedIn, model.AutoJoinSkipped, string(reason))
+	return model.AutoJoinSkipped, string(reason)
 }
 
 // joinGroup joins a group using invite code
@@ -234,24 +298,12 @@ func (aj *AutoJoiner) previewGroup(ctx context.Context, accountID, inviteCode st
 
 // checkRateLimit checks if we can join now (without waiting)
 func (aj *AutoJoiner) checkRateLimit(accountID string) bool {
-	lastJoin, exists := aj.lastJoinTime[accountID]
-	if !exists {
-		return true
-	}
-	elapsed := time.Since(lastJoin)
-	return elapsed >= aj.minInterval
+	return aj.joinWait(accountID) == 0
 }
 
 // waitForRateLimit waits if necessary to respect rate limits
 func (aj *AutoJoiner) waitForRateLimit(ctx context.Context, accountID string) {
-	lastJoin, exists := aj.lastJoinTime[accountID]
-	if !exists {
-		return
-	}
-	
-	elapsed := time.Since(lastJoin)
-	if elapsed < aj.minInterval {
-		waitTime := aj.minInterval - elapsed
+	if waitTime := aj.joinWait(accountID); waitTime > 0 {
 		log.Printf("[autojoin] rate limit: waiting %v before next join", waitTime)
 		select {
 		case <-time.After(waitTime):
@@ -262,12 +314,8 @@ func (aj *AutoJoiner) waitForRateLimit(ctx context.Context, accountID string) {
 
 // isAlreadyJoined checks if we already joined this group
 func (aj *AutoJoiner) isAlreadyJoined(accountID, inviteCode string) bool {
-	var count int
-	err := aj.Store.DB.QueryRow(`
-		SELECT COUNT(*) FROM auto_join_logs 
-		WHERE account_id=? AND invite_code=? AND status='joined'
-	`, accountID, inviteCode).Scan(&count)
-	return err == nil && count > 0
+	joined, err := aj.Store.HasJoinedInvite(accountID, inviteCode)
+	return err == nil && joined
 }
 
 // extractTextFromMessage extracts text content from various message types
@@ -304,63 +352,25 @@ func (aj *AutoJoiner) extractTextFromMessage(msg *waProto.Message) string {
 	return ""
 }
 
-// Helper functions for database operations
-
-func (aj *AutoJoiner) loadSettings(accountID string) (*AutoJoinSettings, error) {
-	settings := &AutoJoinSettings{
-		Enabled:            false,
-		DailyLimit:         20, // Default
-		PreviewBeforeJoin:  true,
-		WhitelistContacts:  "[]",
-		BlacklistKeywords:  "[]",
-	}
-	
-	err := aj.Store.DB.QueryRow(`
-		SELECT enabled, daily_limit, preview_before_join, 
-		       COALESCE(whitelist_contacts, '[]'), COALESCE(blacklist_keywords, '[]')
-		FROM auto_join_settings WHERE account_id=?
-	`, accountID).Scan(&settings.Enabled, &settings.DailyLimit, &settings.PreviewBeforeJoin,
-		&settings.WhitelistContacts, &settings.BlacklistKeywords)
-	
-	if err == sql.ErrNoRows {
-		// No settings yet, return defaults
-		return settings, nil
-	}
-	
-	return settings, err
-}
-
-func (aj *AutoJoiner) countJoinsToday(accountID string) (int64, error) {
-	var count int64
-	err := aj.Store.DB.QueryRow(`
-		SELECT COUNT(*) FROM auto_join_logs 
-		WHERE account_id=? AND status='joined' 
-		AND joined_at >= datetime('now', 'start of day')
-	`, accountID).Scan(&count)
-	return count, err
-}
-
 func (aj *AutoJoiner) logAttempt(accountID, groupID, groupName, inviteCode, sharedBy, sharedIn, status, reason string) error {
-	_, err := aj.Store.DB.Exec(`
-		INSERT INTO auto_join_logs 
-		(account_id, group_id, group_name, invite_code, shared_by, shared_in, status, reason, joined_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, CURRENT_TIMESTAMP)
-	`, accountID, nullStr(groupID), nullStr(groupName), inviteCode, nullStr(sharedBy), nullStr(sharedIn), status, nullStr(reason))
+	err := aj.Store.LogAutoJoinAttempt(model.AutoJoinLog{
+		AccountID:  accountID,
+		GroupID:    groupID,
+		GroupName:  groupName,
+		InviteCode: inviteCode,
+		SharedBy:   sharedBy,
+		SharedIn:   sharedIn,
+		Status:     status,
+		Reason:     reason,
+	})
+	aj.Hooks.Emit("autojoin."+status, map[string]any{
+		"account_id":  accountID,
+		"group_id":    groupID,
+		"group_name":  groupName,
+		"invite_code": inviteCode,
+		"shared_by":   sharedBy,
+		"shared_in":   sharedIn,
+		"reason":      reason,
+	})
 	return err
 }
-
-func nullStr(s string) interface{} {
-	if s == "" {
-		return nil
-	}
-	return s
-}
-
-// AutoJoinSettings represents auto-join configuration for an account
-type AutoJoinSettings struct {
-	Enabled            bool
-	DailyLimit         int
-	PreviewBeforeJoin  bool
-	WhitelistContacts  string // JSON array
-	BlacklistKeywords  string // JSON array
-}
@@ -0,0 +1,35 @@
+package autojoin
+
+import (
+	"log"
+	"time"
+
+	"promote/internal/webhook"
+)
+
+// scheduleEnable menjadwalkan grup hasil join diaktifkan setelah settleMin menit (0 = segera).
+func (aj *AutoJoiner) scheduleEnable(accountID, groupID, groupName string, settleMin int) {
+	at := time.Now().Add(time.Duration(settleMin) * time.Minute)
+	if err := aj.Store.ScheduleGroupEnable(accountID, groupID, groupName, at); err != nil {
+		log.Printf("[autojoin] schedule enable for group %s: %v", groupID, err)
+		return
+	}
+	if settleMin <= 0 {
+		aj.enableSettled()
+		return
+	}
+	log.Printf("[autojoin] group %s will be enabled at %s", groupID, at.Format(time.RFC3339))
+}
+
+// enableSettled mengaktifkan grup hasil auto-join yang jeda settle-nya sudah lewat.
+func (aj *AutoJoiner) enableSettled() {
+	ids, err := aj.Store.EnableSettledGroups()
+	if err != nil {
+		log.Printf("[autojoin] enable settled groups: %v", err)
+		return
+	}
+	for _, id := range ids {
+		log.Printf("[autojoin] group %s enabled for broadcast", id)
+		aj.Hooks.Emit(webhook.EventAutoJoinEnabled, map[string]any{"group_id": id})
+	}
+}
@@ -15,6 +15,10 @@ const (
 	FilterReasonAlreadyJoined  FilterReason = "already_joined"
 	FilterReasonInvalidCode    FilterReason = "invalid_invite_code"
 	FilterReasonRateLimit      FilterReason = "rate_limit"
+	FilterReasonTooFewMembers  FilterReason = "too_few_members"
+	FilterReasonTooManyMembers FilterReason = "too_many_members"
+	// FilterReasonOutsideWindow link ditunda karena di luar jendela join akun.
+	FilterReasonOutsideWindow FilterReason = "outside_join_window"
 )
 
 // Filter handles filtering logic untuk auto-join
@@ -24,11 +28,15 @@ type Filter struct {
 	WhitelistContacts  []string // JID list, empty = allow all
 	BlacklistKeywords  []string // Lowercase keywords
 	PreviewBeforeJoin  bool
+	// MinParticipants/MaxParticipants batas jumlah anggota grup; 0 = tanpa batas.
+	MinParticipants int
+	MaxParticipants int
 }
 
 // ShouldJoin menentukan apakah boleh join berdasarkan filter rules
+// participants < 0 berarti jumlah anggota tidak diketahui (tanpa preview); filter anggota dilewati.
 // Returns: (should join, reason if not)
-func (f *Filter) ShouldJoin(senderJID string, groupName string, joinsToday int) (bool, FilterReason) {
+func (f *Filter) ShouldJoin(senderJID string, groupName string, participants, joinsToday int) (bool, FilterReason) {
 	// Check if enabled
 	if !f.Enabled {
 		return false, FilterReasonDisabled
@@ -50,6 +58,16 @@ func (f *Filter) ShouldJoin(senderJID string, groupName string, joinsToday int)
 	if groupName != "" && f.isBlacklisted(groupName) {
 		return false, FilterReasonBlacklisted
 	}
+
+	// Check participant count (only known after preview)
+	if participants >= 0 {
+		if f.MinParticipants > 0 && participants < f.MinParticipants {
+			return false, FilterReasonTooFewMembers
+		}
+		if f.MaxParticipants > 0 && participants > f.MaxParticipants {
+			return false, FilterReasonTooManyMembers
+		}
+	}
 	
 	return true, ""
 }
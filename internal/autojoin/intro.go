@@ -0,0 +1,59 @@
+package autojoin
+
+import (
+	"context"
+	"log"
+	"math/rand"
+	"time"
+
+	"promote/internal/model"
+)
+
+// scheduleIntro menjadwalkan template intro ke grup hasil join: IntroDelayMin menit setelah join
+// ditambah jitter acak 0..IntroJitterMin menit.
+func (aj *AutoJoiner) scheduleIntro(settings model.AutoJoinSettings, groupID string) {
+	if settings.IntroTemplateID == "" || aj.Sender == nil {
+		return
+	}
+	delay := time.Duration(settings.IntroDelayMin) * time.Minute
+	if settings.IntroJitterMin > 0 {
+		delay += time.Duration(rand.Int63n(int64(settings.IntroJitterMin)*int64(time.Minute) + 1))
+	}
+	due := time.Now().Add(delay)
+	created, err := aj.Store.ScheduleAutoJoinIntro(settings.AccountID, groupID, settings.IntroTemplateID, due)
+	if err != nil {
+		log.Printf("[autojoin] schedule intro for group %s: %v", groupID, err)
+		return
+	}
+	if created {
+		log.Printf("[autojoin] intro for group %s scheduled at %s", groupID, due.Format(time.RFC3339))
+	}
+}
+
+// sendDueIntros mengirim intro yang sudah jatuh tempo, satu per satu; kegagalan kirim dicatat
+// tanpa retry.
+func (aj *AutoJoiner) sendDueIntros(ctx context.Context) {
+	if aj.Sender == nil {
+		return
+	}
+	intros, err := aj.Store.DueAutoJoinIntros(20)
+	if err != nil {
+		log.Printf("[autojoin] list due intros: %v", err)
+		return
+	}
+	for _, it := range intros {
+		if ctx.Err() != nil {
+			return
+		}
+		status, reason := model.IntroSent, ""
+		if err := aj.Sender.SendTemplateToGroup(ctx, it.AccountID, it.GroupID, it.TemplateID); err != nil {
+			status, reason = model.IntroFailed, err.Error()
+			log.Printf("[autojoin] intro to group %s failed: %v", it.GroupID, err)
+		} else {
+			log.Printf("[autojoin] intro sent to group %s (account %s)", it.GroupID, it.AccountID)
+		}
+		if err := aj.Store.FinishAutoJoinIntro(it.ID, status, reason); err != nil {
+			log.Printf("[autojoin] finish intro %d: %v", it.ID, err)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package autojoin
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"promote/internal/model"
+	"promote/internal/webhook"
+)
+
+// queuePoll interval worker memeriksa item antrean yang sudah disetujui.
+const queuePoll = time.Minute
+
+// enqueue memasukkan link ke antrean persetujuan (mode require_approval).
+func (aj *AutoJoiner) enqueue(accountID, groupName, code, sharedBy, sharedIn string) (string, string) {
+	id, created, err := aj.Store.EnqueueAutoJoin(model.AutoJoinQueueItem{
+		AccountID:  accountID,
+		InviteCode: code,
+		GroupName:  groupName,
+		SharedBy:   sharedBy,
+		SharedIn:   sharedIn,
+	})
+	if err != nil {
+		log.Printf("[autojoin] failed to queue code %s for account %s: %v", code, accountID, err)
+		return model.AutoJoinFailed, err.Error()
+	}
+	if !created {
+		return model.AutoJoinPending, "already_queued"
+	}
+	log.Printf("[autojoin] queued group link for approval (code: %s, account: %s)", code, accountID)
+	aj.Hooks.Emit(webhook.EventAutoJoinQueued, map[string]any{
+		"queue_id":    id,
+		"account_id":  accountID,
+		"group_name":  groupName,
+		"invite_code": code,
+		"shared_by":   sharedBy,
+		"shared_in":   sharedIn,
+	})
+	return model.AutoJoinPending, ""
+}
+
+// Start menjalankan worker yang men-join item antrean yang sudah disetujui, satu per satu
+// dengan jeda acak antar join di dalam jendela join akun dan tetap menghormati limit harian, serta mengaktifkan
+// grup hasil join yang jeda settle-nya sudah lewat dan mengirim intro pasca-join yang jatuh tempo.
+func (aj *AutoJoiner) Start(ctx context.Context) {
+	go func() {
+		tick := time.NewTicker(queuePoll)
+		defer tick.Stop()
+		for {
+			aj.enableSettled()
+			aj.drainQueue(ctx)
+			aj.sendDueIntros(ctx)
+			select {
+			case <-ctx.Done():
+				return
+			case <-tick.C:
+			case <-aj.wake:
+			}
+		}
+	}()
+}
+
+// Wake membangunkan worker antrean (mis. setelah operator menyetujui item).
+func (aj *AutoJoiner) Wake() {
+	select {
+	case aj.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (aj *AutoJoiner) drainQueue(ctx context.Context) {
+	items, err := aj.Store.ApprovedAutoJoins(50)
+	if err != nil {
+		log.Printf("[autojoin] list approved queue: %v", err)
+		return
+	}
+	// Akun yang limit hariannya habis ditunda sampai hari berikutnya; akun yang jeda acak antar
+	// join-nya masih lama dilewati sampai putaran berikutnya.
+	atLimit := map[string]bool{}
+	for _, it := range items {
+		if ctx.Err() != nil {
+			return
+		}
+		if atLimit[it.AccountID] || aj.dailyLimitReached(it.AccountID) {
+			atLimit[it.AccountID] = true
+			continue
+		}
+		if aj.joinWait(it.AccountID) > queuePoll {
+			continue
+		}
+		if settings, err := aj.Store.LoadAutoJoinSettings(it.AccountID); err == nil {
+			if open, next := nextJoinWindow(settings, time.Now()); !open {
+				aj.deferToWindow(it, next)
+				continue
+			}
+		}
+		status, reason := aj.process(ctx, it.AccountID, it.InviteCode, it.SharedBy, it.SharedIn, true)
+		if status == model.AutoJoinRetrying {
+			// item sudah dijadwalkan ulang oleh retryLater
+			continue
+		}
+		if err := aj.Store.FinishAutoJoinQueue(it.ID, status, reason); err != nil {
+			log.Printf("[autojoin] finish queue item %d: %v", it.ID, err)
+		}
+	}
+}
+
+func (aj *AutoJoiner) dailyLimitReached(accountID string) bool {
+	settings, err := aj.Store.LoadAutoJoinSettings(accountID)
+	if err != nil {
+		return true
+	}
+	n, err := aj.Store.CountJoinsToday(accountID)
+	return err != nil || n >= settings.DailyLimit
+}
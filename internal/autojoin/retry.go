@@ -0,0 +1,108 @@
+package autojoin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.mau.fi/whatsmeow"
+
+	"promote/internal/model"
+)
+
+// retryConfig percobaan ulang join yang gagal sementara (AUTOJOIN_RETRY_MAX, AUTOJOIN_RETRY_BASE_SEC).
+type retryConfig struct {
+	// Max jumlah percobaan ulang per kode undangan; 0 = tanpa retry.
+	Max int
+	// Base jeda retry pertama; digandakan tiap percobaan sampai MaxDelay.
+	Base     time.Duration
+	MaxDelay time.Duration
+}
+
+func retryConfigFromEnv() retryConfig {
+	c := retryConfig{Max: 5, Base: 2 * time.Minute, MaxDelay: 6 * time.Hour}
+	if n, err := strconv.Atoi(strings.TrimSpace(os.Getenv("AUTOJOIN_RETRY_MAX"))); err == nil && n >= 0 {
+		c.Max = n
+	}
+	if n, err := strconv.Atoi(strings.TrimSpace(os.Getenv("AUTOJOIN_RETRY_BASE_SEC"))); err == nil && n > 0 {
+		c.Base = time.Duration(n) * time.Second
+	}
+	return c
+}
+
+// backoff jeda sebelum percobaan ulang ke-attempt (mulai 1).
+func (c retryConfig) backoff(attempt int) time.Duration {
+	d := c.Base
+	for i := 1; i < attempt && d < c.MaxDelay; i++ {
+		d *= 2
+	}
+	if d > c.MaxDelay {
+		d = c.MaxDelay
+	}
+	return d
+}
+
+// isTemporary true untuk kegagalan yang layak dicoba ulang: timeout, koneksi putus, dan error
+// server WhatsApp 5xx/429. Link tidak valid/dicabut dan grup tidak ada tidak dicoba ulang.
+func isTemporary(err error) bool {
+	switch {
+	case err == nil,
+		errors.Is(err, whatsmeow.ErrInviteLinkInvalid),
+		errors.Is(err, whatsmeow.ErrInviteLinkRevoked),
+		errors.Is(err, whatsmeow.ErrGroupNotFound),
+		errors.Is(err, context.Canceled):
+		return false
+	case errors.Is(err, whatsmeow.ErrIQTimedOut),
+		errors.Is(err, whatsmeow.ErrNotConnected),
+		errors.Is(err, whatsmeow.ErrIQDisconnected),
+		errors.Is(err, whatsmeow.ErrIQRateOverLimit),
+		errors.Is(err, whatsmeow.ErrIQInternalServerError),
+		errors.Is(err, whatsmeow.ErrIQServiceUnavailable),
+		errors.Is(err, whatsmeow.ErrIQPartialServerError),
+		errors.Is(err, context.DeadlineExceeded):
+		return true
+	}
+	s := strings.ToLower(err.Error())
+	return strings.Contains(s, "timeout") || strings.Contains(s, "timed out") ||
+		strings.Contains(s, "connect:") || strings.Contains(s, "eof") || strings.Contains(s, "reset")
+}
+
+// retryLater menjadwalkan ulang join yang gagal sementara dengan backoff eksponensial. Mengembalikan
+// alasan untuk log beserta true jika retry dijadwalkan; false jika error permanen, retry dimatikan,
+// atau batas percobaan sudah tercapai (alasan menyebut jumlah percobaan).
+func (aj *AutoJoiner) retryLater(accountID, groupName, code, sharedBy, sharedIn string, cause error) (string, bool) {
+	reason := cause.Error()
+	if aj.retry.Max <= 0 || !isTemporary(cause) {
+		return reason, false
+	}
+	attempts, err := aj.Store.AutoJoinAttempts(accountID, code)
+	if err != nil {
+		log.Printf("[autojoin] retry attempts for code %s: %v", code, err)
+		return reason, false
+	}
+	if attempts >= aj.retry.Max {
+		return fmt.Sprintf("gave up after %d retries: %s", attempts, reason), false
+	}
+	attempts++
+	next := time.Now().Add(aj.retry.backoff(attempts))
+	err = aj.Store.ScheduleAutoJoinRetry(model.AutoJoinQueueItem{
+		AccountID:  accountID,
+		InviteCode: code,
+		GroupName:  groupName,
+		SharedBy:   sharedBy,
+		SharedIn:   sharedIn,
+		NotBefore:  &next,
+		Attempts:   attempts,
+	}, reason)
+	if err != nil {
+		log.Printf("[autojoin] schedule retry for code %s: %v", code, err)
+		return reason, false
+	}
+	log.Printf("[autojoin] retry %d/%d for code %s (account %s) at %s", attempts, aj.retry.Max, code, accountID, next.Format(time.RFC3339))
+	return fmt.Sprintf("%s (retry %d/%d at %s)", reason, attempts, aj.retry.Max, next.Format(time.RFC3339)), true
+}
@@ -0,0 +1,79 @@
+package autojoin
+
+import (
+	"log"
+	"math/rand"
+	"time"
+
+	"promote/internal/model"
+)
+
+// wib zona waktu jendela join (sama dengan scheduler), fallback +07:00 jika tzdata tidak ada.
+var wib = func() *time.Location {
+	loc, err := time.LoadLocation("Asia/Jakarta")
+	if err != nil || loc == nil {
+		loc = time.FixedZone("WIB", 7*3600)
+	}
+	return loc
+}()
+
+// nextJoinWindow true jika now berada di jendela join akun (atau akun tanpa jendela). Jika tidak,
+// mengembalikan awal jendela berikutnya; zero time bila tidak ada jendela sama sekali.
+func nextJoinWindow(settings model.AutoJoinSettings, now time.Time) (bool, time.Time) {
+	if settings.JoinWindows == nil {
+		return true, time.Time{}
+	}
+	now = now.In(wib)
+	if settings.JoinWindows.Active(now) {
+		return true, time.Time{}
+	}
+	next, ok := settings.JoinWindows.Next(now)
+	if !ok {
+		return false, time.Time{}
+	}
+	return false, next.Start
+}
+
+// deferToWindow menunda item ke awal jendela join berikutnya (minimal satu hari ke depan jika
+// akun tidak punya jendela sama sekali).
+func (aj *AutoJoiner) deferToWindow(it model.AutoJoinQueueItem, next time.Time) (string, string) {
+	if next.IsZero() {
+		next = time.Now().Add(24 * time.Hour)
+	}
+	it.NotBefore = &next
+	reason := string(FilterReasonOutsideWindow)
+	if err := aj.Store.DeferAutoJoin(it, reason); err != nil {
+		log.Printf("[autojoin] defer code %s for account %s: %v", it.InviteCode, it.AccountID, err)
+		return model.AutoJoinFailed, err.Error()
+	}
+	log.Printf("[autojoin] outside join window, code %s (account %s) deferred to %s",
+		it.InviteCode, it.AccountID, next.Format(time.RFC3339))
+	return model.AutoJoinRetrying, reason
+}
+
+// joinJitter jeda acak JoinJitterMinSec..JoinJitterMaxSec detik sebelum join berikutnya akun.
+func joinJitter(settings model.AutoJoinSettings) time.Duration {
+	lo, hi := settings.JoinJitterMinSec, settings.JoinJitterMaxSec
+	if hi < lo {
+		hi = lo
+	}
+	d := time.Duration(lo) * time.Second
+	if hi > lo {
+		d += time.Duration(rand.Int63n(int64(hi-lo)*int64(time.Second) + 1))
+	}
+	return d
+}
+
+// joinWait sisa jeda sebelum akun boleh join lagi (0 = boleh sekarang).
+func (aj *AutoJoiner) joinWait(accountID string) time.Duration {
+	aj.mu.Lock()
+	next, ok := aj.nextJoinAt[accountID]
+	aj.mu.Unlock()
+	if !ok {
+		return 0
+	}
+	if d := time.Until(next); d > 0 {
+		return d
+	}
+	return 0
+}
@@ -0,0 +1,186 @@
+// Package autoleave keluar otomatis dari grup bernilai rendah (gagal kirim beruntun, anggota
+// terlalu sedikit, atau lama tanpa kiriman sukses) menurut kebijakan di settings "auto_leave",
+// sehingga kapasitas grup akun tidak habis oleh grup yang tidak berguna dan berisiko.
+package autoleave
+
+import (
+	"context"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"promote/internal/model"
+	"promote/internal/storage"
+	"promote/internal/wa"
+	"promote/internal/webhook"
+)
+
+// Leaver menjalankan penilaian dan auto-leave secara berkala.
+type Leaver struct {
+	Store   *storage.Store
+	Manager *wa.Manager
+	// Hooks (opsional) mengirim event autoleave.left ke webhook.
+	Hooks *webhook.Dispatcher
+	// Interval antar putaran (AUTO_LEAVE_HOURS, default 6 jam).
+	Interval time.Duration
+	// Delay jeda antar keluar grup agar tidak terlihat massal.
+	Delay time.Duration
+}
+
+// Result ringkasan satu putaran.
+type Result struct {
+	Accounts int `json:"accounts"`
+	Checked  int `json:"checked"`
+	Left     int `json:"left"`
+	DryRun   int `json:"dry_run"`
+	Errors   int `json:"errors"`
+}
+
+func (r *Result) add(o Result) {
+	r.Accounts += o.Accounts
+	r.Checked += o.Checked
+	r.Left += o.Left
+	r.DryRun += o.DryRun
+	r.Errors += o.Errors
+}
+
+// New membuat Leaver; interval dibaca dari env.
+func New(store *storage.Store, manager *wa.Manager) *Leaver {
+	l := &Leaver{Store: store, Manager: manager, Interval: 6 * time.Hour, Delay: 5 * time.Second}
+	if n, _ := strconv.Atoi(strings.TrimSpace(os.Getenv("AUTO_LEAVE_HOURS"))); n > 0 {
+		l.Interval = time.Duration(n) * time.Hour
+	}
+	return l
+}
+
+// Start menjalankan putaran pertama setelah jeda (memberi waktu akun terhubung dan grup
+// tersinkron), lalu berkala. Putaran dilewati selama kebijakan nonaktif.
+func (l *Leaver) Start(ctx context.Context) {
+	go func() {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(15 * time.Minute):
+		}
+		tick := time.NewTicker(l.Interval)
+		defer tick.Stop()
+		for {
+			policy, err := l.Store.AutoLeavePolicy()
+			if err != nil {
+				log.Printf("[autoleave] load policy: %v", err)
+			} else if policy.Enabled {
+				res, err := l.RunAll(ctx, policy)
+				if err != nil {
+					log.Printf("[autoleave] run failed: %v", err)
+				} else {
+					log.Printf("[autoleave] run done accounts=%d checked=%d left=%d dry_run=%d errors=%d",
+						res.Accounts, res.Checked, res.Left, res.DryRun, res.Errors)
+				}
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-tick.C:
+			}
+		}
+	}()
+}
+
+// RunAll menerapkan kebijakan ke semua akun aktif yang sedang terhubung.
+func (l *Leaver) RunAll(ctx context.Context, policy model.AutoLeavePolicy) (Result, error) {
+	var total Result
+	accs, err := l.Store.ListAccounts()
+	if err != nil {
+		return total, err
+	}
+	for _, a := range accs {
+		if !a.Enabled {
+			continue
+		}
+		if _, connected := l.Manager.ConnectionState(a.ID); !connected {
+			continue
+		}
+		res, err := l.RunAccount(ctx, a.ID, policy)
+		if err != nil {
+			log.Printf("[autoleave] account=%s run failed: %v", a.ID, err)
+			continue
+		}
+		total.add(res)
+	}
+	return total, nil
+}
+
+// Preview grup akun yang akan ditinggalkan menurut kebijakan (tanpa batas MaxPerRun).
+func (l *Leaver) Preview(accountID string, policy model.AutoLeavePolicy) ([]model.AutoLeaveCandidate, error) {
+	list, err := l.Store.AutoLeaveCandidates(accountID)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	out := []model.AutoLeaveCandidate{}
+	for _, c := range list {
+		if c.Reason = policy.Reason(c, now); c.Reason != "" {
+			out = append(out, c)
+		}
+	}
+	return out, nil
+}
+
+// RunAccount keluar dari maksimal policy.MaxPerRun grup akun yang memenuhi kebijakan; dalam
+// mode DryRun grup hanya dicatat.
+func (l *Leaver) RunAccount(ctx context.Context, accountID string, policy model.AutoLeavePolicy) (Result, error) {
+	res := Result{Accounts: 1}
+	list, err := l.Preview(accountID, policy)
+	if err != nil {
+		return res, err
+	}
+	res.Checked = len(list)
+	if len(list) > policy.MaxPerRun {
+		list = list[:policy.MaxPerRun]
+	}
+	for i, c := range list {
+		if i > 0 && !policy.DryRun && l.Delay > 0 {
+			select {
+			case <-ctx.Done():
+				return res, ctx.Err()
+			case <-time.After(l.Delay):
+			}
+		}
+		entry := model.AutoLeaveLog{AccountID: accountID, GroupID: c.GroupID, GroupName: c.GroupName, Reason: c.Reason}
+		switch {
+		case policy.DryRun:
+			entry.Status = model.AutoLeaveDryRun
+			res.DryRun++
+		default:
+			if err := l.leave(ctx, accountID, c.GroupID); err != nil {
+				entry.Status, entry.Error = model.AutoLeaveFailed, err.Error()
+				res.Errors++
+				log.Printf("[autoleave] account=%s group=%s: %v", accountID, c.GroupID, err)
+			} else {
+				entry.Status = model.AutoLeaveLeft
+				res.Left++
+				log.Printf("[autoleave] account=%s left group=%s (%s)", accountID, c.GroupID, c.Reason)
+				l.Hooks.Emit(webhook.EventAutoLeaveLeft, map[string]any{
+					"account_id": accountID,
+					"group_id":   c.GroupID,
+					"group_name": c.GroupName,
+					"reason":     c.Reason,
+				})
+			}
+		}
+		if err := l.Store.LogAutoLeave(entry); err != nil {
+			log.Printf("[autoleave] log group=%s: %v", c.GroupID, err)
+		}
+	}
+	return res, nil
+}
+
+func (l *Leaver) leave(ctx context.Context, accountID, groupID string) error {
+	if err := l.Manager.LeaveGroup(ctx, accountID, groupID); err != nil {
+		return err
+	}
+	_, err := l.Store.MarkGroupLeft(accountID, groupID)
+	return err
+}
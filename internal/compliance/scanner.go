@@ -0,0 +1,173 @@
+// Package compliance memindai deskripsi grup untuk aturan seperti "no promo" / "dilarang jualan"
+// dan menandai (atau menonaktifkan) grup yang melarang promosi.
+package compliance
+
+import (
+	"context"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"promote/internal/model"
+	"promote/internal/storage"
+	"promote/internal/wa"
+)
+
+// Scanner menjalankan scan deskripsi grup secara berkala.
+type Scanner struct {
+	Store   *storage.Store
+	Manager *wa.Manager
+	// Interval antar scan penuh (COMPLIANCE_SCAN_HOURS, default 24 jam).
+	Interval time.Duration
+}
+
+// Result ringkasan satu kali scan.
+type Result struct {
+	Accounts int `json:"accounts"`
+	Checked  int `json:"checked"`
+	Flagged  int `json:"flagged"`
+	Paused   int `json:"paused"`
+	Cleared  int `json:"cleared"`
+}
+
+// New membuat Scanner; interval dibaca dari COMPLIANCE_SCAN_HOURS.
+func New(store *storage.Store, manager *wa.Manager) *Scanner {
+	s := &Scanner{Store: store, Manager: manager, Interval: 24 * time.Hour}
+	if n, err := strconv.Atoi(strings.TrimSpace(os.Getenv("COMPLIANCE_SCAN_HOURS"))); err == nil && n > 0 {
+		s.Interval = time.Duration(n) * time.Hour
+	}
+	return s
+}
+
+// Start menjalankan scan pertama setelah jeda singkat (memberi waktu akun terhubung), lalu berkala.
+func (s *Scanner) Start(ctx context.Context) {
+	go func() {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(5 * time.Minute):
+		}
+		tick := time.NewTicker(s.Interval)
+		defer tick.Stop()
+		for {
+			res, err := s.ScanAll(ctx)
+			if err != nil {
+				log.Printf("[compliance] scan failed: %v", err)
+			} else {
+				log.Printf("[compliance] scan done accounts=%d checked=%d flagged=%d paused=%d cleared=%d",
+					res.Accounts, res.Checked, res.Flagged, res.Paused, res.Cleared)
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-tick.C:
+			}
+		}
+	}()
+}
+
+// ScanAll memindai semua akun aktif yang sedang terhubung.
+func (s *Scanner) ScanAll(ctx context.Context) (Result, error) {
+	var total Result
+	accs, err := s.Store.ListAccounts()
+	if err != nil {
+		return total, err
+	}
+	for _, a := range accs {
+		if !a.Enabled {
+			continue
+		}
+		if _, connected := s.Manager.ConnectionState(a.ID); !connected {
+			continue
+		}
+		res, err := s.ScanAccount(ctx, a.ID)
+		if err != nil {
+			log.Printf("[compliance] account=%s scan failed: %v", a.ID, err)
+			continue
+		}
+		total.Accounts++
+		total.Checked += res.Checked
+		total.Flagged += res.Flagged
+		total.Paused += res.Paused
+		total.Cleared += res.Cleared
+	}
+	return total, nil
+}
+
+// ScanAccount mengambil deskripsi grup milik akun dan mencocokkannya dengan aturan aktif.
+func (s *Scanner) ScanAccount(ctx context.Context, accountID string) (Result, error) {
+	res := Result{Accounts: 1}
+	rules, err := s.Store.ListComplianceRules()
+	if err != nil {
+		return res, err
+	}
+	cctx, cancel := context.WithTimeout(ctx, 60*time.Second)
+	defer cancel()
+	descs, err := s.Manager.GroupDescriptions(cctx, accountID)
+	if err != nil {
+		return res, err
+	}
+	groups, err := s.Store.ListGroups(accountID)
+	if err != nil {
+		return res, err
+	}
+	now := time.Now()
+	for _, g := range groups {
+		desc, ok := descs[g.ID]
+		if !ok {
+			continue
+		}
+		res.Checked++
+		rule := Match(desc, rules)
+		if rule == nil {
+			if g.ComplianceFlag != "" {
+				res.Cleared++
+			}
+			if err := s.Store.SaveGroupCompliance(g.ID, desc, "", false, now); err != nil {
+				return res, err
+			}
+			continue
+		}
+		pause := rule.Action == model.CompliancePause && g.Enabled
+		if err := s.Store.SaveGroupCompliance(g.ID, desc, rule.Phrase, pause, now); err != nil {
+			return res, err
+		}
+		res.Flagged++
+		if pause {
+			res.Paused++
+			log.Printf("[compliance] group=%s paused: description matches %q", g.ID, rule.Phrase)
+		}
+	}
+	return res, nil
+}
+
+// Match mengembalikan aturan aktif pertama yang frasanya muncul di teks (per kata utuh,
+// tanpa peduli huruf besar/kecil dan tanda baca), atau nil.
+func Match(text string, rules []model.ComplianceRule) *model.ComplianceRule {
+	norm := " " + normalize(text) + " "
+	for i := range rules {
+		r := &rules[i]
+		if !r.Enabled {
+			continue
+		}
+		p := normalize(r.Phrase)
+		if p != "" && strings.Contains(norm, " "+p+" ") {
+			return r
+		}
+	}
+	return nil
+}
+
+// normalize: huruf kecil, non huruf/angka jadi spasi, spasi dirapikan.
+func normalize(s string) string {
+	s = strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+		return ' '
+	}, s)
+	return strings.Join(strings.Fields(s), " ")
+}
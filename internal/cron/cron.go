@@ -0,0 +1,203 @@
+// Package cron menjalankan task housekeeping berulang (prune log, GC upload, rollup statistik,
+// cek kesehatan template, refresh segmen audiens, checkpoint WAL). Status tiap task disimpan di
+// SQLite sehingga jadwal berlanjut setelah restart; satu task tidak pernah berjalan tumpang
+// tindih dengan dirinya sendiri.
+package cron
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+
+	"promote/internal/model"
+	"promote/internal/storage"
+)
+
+var (
+	ErrUnknownTask = errors.New("unknown task")
+	ErrRunning     = errors.New("task already running")
+)
+
+// Task adalah satu pekerjaan berulang. Run mengembalikan ringkasan singkat hasilnya.
+type Task struct {
+	Name   string
+	Every  time.Duration
+	Jitter time.Duration
+	Run    func(ctx context.Context) (string, error)
+}
+
+type entry struct {
+	task    Task
+	state   model.CronTask
+	running bool
+}
+
+// Runner menjadwalkan dan menjalankan Task.
+type Runner struct {
+	Store *storage.Store
+	// Tick interval pengecekan task jatuh tempo.
+	Tick time.Duration
+
+	mu      sync.Mutex
+	entries map[string]*entry
+	ctx     context.Context
+}
+
+// New membuat Runner kosong; daftarkan task dengan Add sebelum Start.
+func New(store *storage.Store) *Runner {
+	return &Runner{Store: store, Tick: 30 * time.Second, entries: map[string]*entry{}, ctx: context.Background()}
+}
+
+// Add mendaftarkan task. Status terakhir dari DB dipakai untuk menentukan jadwal berikutnya.
+func (r *Runner) Add(t Task) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.entries[t.Name] = &entry{task: t, state: model.CronTask{Name: t.Name}}
+}
+
+// Start memuat status tersimpan lalu mengecek task jatuh tempo setiap Tick.
+func (r *Runner) Start(ctx context.Context) {
+	saved, err := r.Store.LoadCronTasks()
+	if err != nil {
+		log.Printf("[cron] load state: %v", err)
+	}
+	now := time.Now()
+	r.mu.Lock()
+	r.ctx = ctx
+	for name, e := range r.entries {
+		if st, ok := saved[name]; ok {
+			e.state = st
+			e.state.Name = name
+		}
+		// Task yang belum pernah jalan (atau jadwalnya hilang) dimulai setelah jeda acak pendek
+		// agar tidak semuanya berjalan bersamaan saat start.
+		if e.state.NextRunAt == nil {
+			next := now.Add(time.Minute + jitter(e.task.Jitter))
+			e.state.NextRunAt = &next
+		}
+	}
+	r.mu.Unlock()
+
+	go func() {
+		tick := time.NewTicker(r.Tick)
+		defer tick.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-tick.C:
+				r.runDue(ctx)
+			}
+		}
+	}()
+}
+
+func (r *Runner) runDue(ctx context.Context) {
+	now := time.Now()
+	r.mu.Lock()
+	var due []*entry
+	for _, e := range r.entries {
+		if !e.running && e.state.NextRunAt != nil && !now.Before(*e.state.NextRunAt) {
+			e.running = true
+			due = append(due, e)
+		}
+	}
+	r.mu.Unlock()
+	for _, e := range due {
+		go r.run(ctx, e)
+	}
+}
+
+// Trigger menjalankan task sekarang di background. Gagal jika task sedang berjalan.
+func (r *Runner) Trigger(name string) error {
+	r.mu.Lock()
+	e, ok := r.entries[name]
+	if !ok {
+		r.mu.Unlock()
+		return ErrUnknownTask
+	}
+	if e.running {
+		r.mu.Unlock()
+		return ErrRunning
+	}
+	e.running = true
+	ctx := r.ctx
+	r.mu.Unlock()
+	go r.run(ctx, e)
+	return nil
+}
+
+// run mengeksekusi task; e.running sudah diset oleh pemanggil.
+func (r *Runner) run(ctx context.Context, e *entry) {
+	started := time.Now()
+	r.mu.Lock()
+	e.state.LastStartedAt = &started
+	r.mu.Unlock()
+
+	msg, err := safeRun(ctx, e.task)
+
+	finished := time.Now()
+	next := finished.Add(e.task.Every + jitter(e.task.Jitter))
+	r.mu.Lock()
+	e.running = false
+	e.state.LastFinishedAt = &finished
+	e.state.LastDurationMs = finished.Sub(started).Milliseconds()
+	e.state.LastMessage = msg
+	e.state.Runs++
+	e.state.NextRunAt = &next
+	if err != nil {
+		e.state.LastStatus = "error"
+		e.state.LastError = err.Error()
+	} else {
+		e.state.LastStatus = "ok"
+		e.state.LastError = ""
+	}
+	st := e.state
+	r.mu.Unlock()
+
+	if err != nil {
+		log.Printf("[cron] task=%s failed in %s: %v", st.Name, finished.Sub(started), err)
+	} else {
+		log.Printf("[cron] task=%s ok in %s: %s", st.Name, finished.Sub(started), msg)
+	}
+	if err := r.Store.SaveCronTask(st); err != nil {
+		log.Printf("[cron] save state task=%s: %v", st.Name, err)
+	}
+}
+
+// safeRun mengubah panic di task menjadi error agar loop cron tetap hidup.
+func safeRun(ctx context.Context, t Task) (msg string, err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("panic: %v", p)
+		}
+	}()
+	return t.Run(ctx)
+}
+
+// Status mengembalikan status semua task, urut nama.
+func (r *Runner) Status() []model.CronTask {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	out := make([]model.CronTask, 0, len(r.entries))
+	for _, e := range r.entries {
+		st := e.state
+		st.Every = e.task.Every.String()
+		st.Running = e.running
+		out = append(out, st)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+func jitter(max time.Duration) time.Duration {
+	if max <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int63n(int64(max)))
+}
@@ -0,0 +1,234 @@
+package cron
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"promote/internal/digest"
+	"promote/internal/mediacache"
+	"promote/internal/paths"
+	"promote/internal/storage"
+)
+
+// Housekeeping adalah dependensi task housekeeping bawaan.
+type Housekeeping struct {
+	Store     *storage.Store
+	UploadDir string
+	Digest    *digest.Runner
+	Client    *http.Client
+	// LogRetention umur maksimum logs/ack/event akun (LOG_RETENTION_DAYS, default 90 hari).
+	LogRetention time.Duration
+	// Media (opsional) cache media remote yang dipangkas berkala.
+	Media *mediacache.Cache
+	// UploadMinAge file upload tanpa referensi baru dihapus setelah umur ini (UPLOAD_GC_MIN_AGE_HOURS, default 24).
+	UploadMinAge time.Duration
+}
+
+// NewHousekeeping membuat Housekeeping dengan retensi dari env.
+func NewHousekeeping(store *storage.Store, uploadDir string, dg *digest.Runner) *Housekeeping {
+	h := &Housekeeping{
+		Store:        store,
+		UploadDir:    uploadDir,
+		Digest:       dg,
+		Client:       &http.Client{Timeout: 20 * time.Second},
+		LogRetention: 90 * 24 * time.Hour,
+		UploadMinAge: 24 * time.Hour,
+	}
+	if n := envInt("LOG_RETENTION_DAYS"); n > 0 {
+		h.LogRetention = time.Duration(n) * 24 * time.Hour
+	}
+	if n := envInt("UPLOAD_GC_MIN_AGE_HOURS"); n > 0 {
+		h.UploadMinAge = time.Duration(n) * time.Hour
+	}
+	return h
+}
+
+func envInt(key string) int {
+	n, _ := strconv.Atoi(strings.TrimSpace(os.Getenv(key)))
+	return n
+}
+
+// Register mendaftarkan semua task housekeeping ke runner.
+func (h *Housekeeping) Register(r *Runner) {
+	r.Add(Task{Name: "prune_logs", Every: 6 * time.Hour, Jitter: 10 * time.Minute, Run: h.PruneLogs})
+	r.Add(Task{Name: "upload_gc", Every: 24 * time.Hour, Jitter: 30 * time.Minute, Run: h.UploadGC})
+	if h.Digest != nil {
+		r.Add(Task{Name: "stats_rollup", Every: 6 * time.Hour, Jitter: 10 * time.Minute, Run: h.StatsRollup})
+	}
+	r.Add(Task{Name: "template_health", Every: 12 * time.Hour, Jitter: 30 * time.Minute, Run: h.TemplateHealth})
+	if h.Media != nil {
+		r.Add(Task{Name: "media_cache_gc", Every: 6 * time.Hour, Jitter: 10 * time.Minute, Run: h.MediaCacheGC})
+	}
+	r.Add(Task{Name: "segment_refresh", Every: 6 * time.Hour, Jitter: 15 * time.Minute, Run: h.SegmentRefresh})
+	r.Add(Task{Name: "wal_checkpoint", Every: time.Hour, Jitter: 5 * time.Minute, Run: h.WALCheckpoint})
+}
+
+// PruneLogs menghapus log kirim, catatan ack, event akun, dan log webhook yang melewati retensi,
+// serta sesi login yang sudah kedaluwarsa.
+func (h *Housekeeping) PruneLogs(ctx context.Context) (string, error) {
+	cutoff := time.Now().Add(-h.LogRetention)
+	logs, err := h.Store.PruneLogs(cutoff)
+	if err != nil {
+		return "", err
+	}
+	acks, err := h.Store.PruneMessageAcks(cutoff)
+	if err != nil {
+		return "", err
+	}
+	events, err := h.Store.PruneAccountEvents(cutoff)
+	if err != nil {
+		return "", err
+	}
+	sessions, err := h.Store.PruneSessions()
+	if err != nil {
+		return "", err
+	}
+	hooks, err := h.Store.PruneWebhookDeliveries(cutoff)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("logs=%d acks=%d account_events=%d sessions=%d webhook_deliveries=%d", logs, acks, events, sessions, hooks), nil
+}
+
+// UploadGC menghapus file di UploadDir yang tidak lagi direferensikan dan lebih tua dari UploadMinAge.
+func (h *Housekeeping) UploadGC(ctx context.Context) (string, error) {
+	refs, err := h.Store.ReferencedUploads()
+	if err != nil {
+		return "", err
+	}
+	entries, err := os.ReadDir(h.UploadDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "no upload dir", nil
+		}
+		return "", err
+	}
+	cutoff := time.Now().Add(-h.UploadMinAge)
+	var removed int
+	var freed int64
+	for _, e := range entries {
+		if ctx.Err() != nil {
+			return "", ctx.Err()
+		}
+		if e.IsDir() || refs[e.Name()] {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil || info.ModTime().After(cutoff) {
+			continue
+		}
+		if err := os.Remove(filepath.Join(h.UploadDir, e.Name())); err != nil {
+			continue
+		}
+		removed++
+		freed += info.Size()
+	}
+	return fmt.Sprintf("removed=%d freed_bytes=%d kept=%d", removed, freed, len(entries)-removed), nil
+}
+
+// SegmentRefresh menghitung ulang anggota semua audiens bersegmen dari cache anggota grup.
+func (h *Housekeeping) SegmentRefresh(ctx context.Context) (string, error) {
+	segments, members, err := h.Store.RefreshSegments()
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("segments=%d members=%d", segments, members), nil
+}
+
+// MediaCacheGC membuang entri cache media yang kedaluwarsa atau melebihi batas ukuran.
+func (h *Housekeeping) MediaCacheGC(ctx context.Context) (string, error) {
+	removed, freed := h.Media.Prune()
+	entries, size := h.Media.Stats()
+	return fmt.Sprintf("removed=%d freed_bytes=%d entries=%d bytes=%d", removed, freed, entries, size), nil
+}
+
+// StatsRollup membuat ulang digest kemarin (tanpa push) agar angka tersimpan mencakup
+// seluruh hari, termasuk aktivitas setelah digest terjadwal (DIGEST_AT) dibuat.
+func (h *Housekeeping) StatsRollup(ctx context.Context) (string, error) {
+	day := time.Now().In(h.Digest.Location()).AddDate(0, 0, -1).Format("2006-01-02")
+	list, err := h.Digest.Run(ctx, day, false)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("day=%s accounts=%d", day, len(list)), nil
+}
+
+// TemplateHealth memeriksa media template aktif (file lokal ada, URL remote bisa diakses)
+// dan menyimpan masalah pertama per template.
+func (h *Housekeeping) TemplateHealth(ctx context.Context) (string, error) {
+	list, err := h.Store.ListTemplateMedia()
+	if err != nil {
+		return "", err
+	}
+	var broken int
+	now := time.Now()
+	for _, t := range list {
+		problem := ""
+		for _, u := range t.URLs {
+			if err := h.checkMedia(ctx, u); err != nil {
+				problem = u + ": " + err.Error()
+				break
+			}
+		}
+		if problem != "" {
+			broken++
+		}
+		if err := h.Store.SetTemplateHealth(t.ID, problem, now); err != nil {
+			return "", err
+		}
+	}
+	return fmt.Sprintf("checked=%d broken=%d", len(list), broken), nil
+}
+
+func (h *Housekeeping) checkMedia(ctx context.Context, u string) error {
+	if strings.HasPrefix(u, "/uploads/") || strings.HasPrefix(u, "uploads/") {
+		p, err := paths.UploadFile(h.UploadDir, u)
+		if err != nil {
+			return err
+		}
+		_, err = os.Stat(p)
+		return err
+	}
+	cctx, cancel := context.WithTimeout(ctx, 15*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(cctx, http.MethodHead, u, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := h.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	// Sebagian server tidak mendukung HEAD; ulangi dengan GET satu byte.
+	if resp.StatusCode == http.StatusMethodNotAllowed || resp.StatusCode == http.StatusNotImplemented {
+		req, _ = http.NewRequestWithContext(cctx, http.MethodGet, u, nil)
+		req.Header.Set("Range", "bytes=0-0")
+		if resp, err = h.Client.Do(req); err != nil {
+			return err
+		}
+		resp.Body.Close()
+	}
+	if resp.StatusCode >= 400 {
+		return fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// WALCheckpoint memindahkan isi WAL ke file DB dan memotongnya.
+func (h *Housekeeping) WALCheckpoint(ctx context.Context) (string, error) {
+	busy, frames, done, err := h.Store.WALCheckpoint()
+	if err != nil {
+		return "", err
+	}
+	if busy {
+		return "", fmt.Errorf("checkpoint busy (frames=%d checkpointed=%d)", frames, done)
+	}
+	return fmt.Sprintf("frames=%d checkpointed=%d", frames, done), nil
+}
@@ -0,0 +1,216 @@
+// Package digest membuat rekap harian per akun (terkirim, gagal, pemakaian limit, insiden, join)
+// dan opsional mengirimkannya ke webhook dan/atau DM ke pemilik.
+package digest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"promote/internal/model"
+	"promote/internal/storage"
+	"promote/internal/wa"
+)
+
+// Runner membuat digest sekali sehari pada jam yang dikonfigurasi (WIB).
+type Runner struct {
+	Store   *storage.Store
+	Manager *wa.Manager
+	// At jam pembuatan digest "HH:MM" WIB (DIGEST_AT, default 23:55).
+	At string
+	// WebhookURL menerima POST JSON {day, digests} (DIGEST_WEBHOOK_URL, opsional).
+	WebhookURL string
+	// OwnerJID penerima DM ringkasan, nomor atau JID (DIGEST_OWNER, opsional).
+	OwnerJID string
+	// DMAccount akun pengirim DM (DIGEST_DM_ACCOUNT); kosong = akun terhubung pertama.
+	DMAccount string
+
+	loc    *time.Location
+	client *http.Client
+}
+
+// New membuat Runner dari env DIGEST_AT, DIGEST_WEBHOOK_URL, DIGEST_OWNER, DIGEST_DM_ACCOUNT.
+func New(store *storage.Store, manager *wa.Manager) *Runner {
+	loc, err := time.LoadLocation("Asia/Jakarta")
+	if err != nil || loc == nil {
+		loc = time.FixedZone("WIB", 7*3600)
+	}
+	r := &Runner{
+		Store:      store,
+		Manager:    manager,
+		At:         "23:55",
+		WebhookURL: strings.TrimSpace(os.Getenv("DIGEST_WEBHOOK_URL")),
+		OwnerJID:   ownerJID(os.Getenv("DIGEST_OWNER")),
+		DMAccount:  strings.TrimSpace(os.Getenv("DIGEST_DM_ACCOUNT")),
+		loc:        loc,
+		client:     &http.Client{Timeout: 15 * time.Second},
+	}
+	if at := strings.TrimSpace(os.Getenv("DIGEST_AT")); at != "" {
+		if _, err := time.Parse("15:04", at); err == nil {
+			r.At = at
+		} else {
+			log.Printf("[digest] invalid DIGEST_AT=%q, using %s", at, r.At)
+		}
+	}
+	return r
+}
+
+// ownerJID menerima nomor (0812..., +62812..., 62812...) atau JID lengkap.
+func ownerJID(s string) string {
+	s = strings.TrimSpace(s)
+	if s == "" || strings.Contains(s, "@") {
+		return s
+	}
+	s = strings.NewReplacer("+", "", " ", "", "-", "").Replace(s)
+	if strings.HasPrefix(s, "0") {
+		s = "62" + s[1:]
+	}
+	return s + "@s.whatsapp.net"
+}
+
+// Start memeriksa tiap menit; digest hari ini dibuat sekali setelah jam At terlewati.
+func (r *Runner) Start(ctx context.Context) {
+	go func() {
+		tick := time.NewTicker(time.Minute)
+		defer tick.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-tick.C:
+			}
+			now := time.Now().In(r.loc)
+			if now.Format("15:04") < r.At {
+				continue
+			}
+			day := now.Format("2006-01-02")
+			done, err := r.Store.HasDigest(day)
+			if err != nil {
+				log.Printf("[digest] check day=%s err=%v", day, err)
+				continue
+			}
+			if done {
+				continue
+			}
+			if _, err := r.Run(ctx, day, true); err != nil {
+				log.Printf("[digest] day=%s failed: %v", day, err)
+			}
+		}
+	}()
+}
+
+// Run membuat dan menyimpan digest untuk hari (YYYY-MM-DD WIB; kosong = hari ini).
+// Jika push true, hasil dikirim ke webhook/DM pemilik yang dikonfigurasi.
+func (r *Runner) Run(ctx context.Context, day string, push bool) ([]model.Digest, error) {
+	if day == "" {
+		day = time.Now().In(r.loc).Format("2006-01-02")
+	}
+	from, err := time.ParseInLocation("2006-01-02", day, r.loc)
+	if err != nil {
+		return nil, fmt.Errorf("invalid day %q", day)
+	}
+	list, err := r.Store.BuildDigests(day, from, from.AddDate(0, 0, 1))
+	if err != nil {
+		return nil, err
+	}
+	for _, d := range list {
+		if err := r.Store.SaveDigest(d); err != nil {
+			return nil, err
+		}
+	}
+	log.Printf("[digest] day=%s accounts=%d", day, len(list))
+	if push {
+		r.push(ctx, day, list)
+	}
+	return list, nil
+}
+
+func (r *Runner) push(ctx context.Context, day string, list []model.Digest) {
+	if r.WebhookURL != "" {
+		if err := r.postWebhook(ctx, day, list); err != nil {
+			log.Printf("[digest] webhook failed: %v", err)
+		}
+	}
+	if r.OwnerJID != "" && r.Manager != nil {
+		acc := r.dmAccount()
+		if acc == "" {
+			log.Printf("[digest] no connected account to DM owner")
+			return
+		}
+		if err := r.Manager.SendText(ctx, acc, r.OwnerJID, Format(day, list)); err != nil {
+			log.Printf("[digest] DM owner via account=%s failed: %v", acc, err)
+		}
+	}
+}
+
+func (r *Runner) postWebhook(ctx context.Context, day string, list []model.Digest) error {
+	if list == nil {
+		list = []model.Digest{}
+	}
+	body, err := json.Marshal(map[string]any{"day": day, "digests": list})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, r.WebhookURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// dmAccount memilih akun pengirim DM: DMAccount jika terhubung, selain itu akun aktif terhubung pertama.
+func (r *Runner) dmAccount() string {
+	if r.DMAccount != "" {
+		if _, ok := r.Manager.ConnectionState(r.DMAccount); ok {
+			return r.DMAccount
+		}
+		return ""
+	}
+	accs, err := r.Store.ListAccounts()
+	if err != nil {
+		return ""
+	}
+	for _, a := range accs {
+		if _, ok := r.Manager.ConnectionState(a.ID); a.Enabled && ok {
+			return a.ID
+		}
+	}
+	return ""
+}
+
+// Format menyusun digest menjadi teks ringkas untuk DM WhatsApp.
+func Format(day string, list []model.Digest) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "*Digest %s*\n", day)
+	var sent, failed int64
+	for _, d := range list {
+		name := d.Label
+		if name == "" {
+			name = d.AccountID
+		}
+		fmt.Fprintf(&b, "\n%s\nterkirim %d/%d (%.0f%%), gagal %d, soft bounce %d, insiden %d, join %d\n",
+			name, d.Sent, d.DailyLimit, d.Utilization*100, d.Failed, d.SoftBounced, d.Incidents, d.Joins)
+		sent += d.Sent
+		failed += d.Failed
+	}
+	fmt.Fprintf(&b, "\nTotal: terkirim %d, gagal %d", sent, failed)
+	return b.String()
+}
+
+// Location zona waktu yang dipakai untuk batas hari (WIB).
+func (r *Runner) Location() *time.Location { return r.loc }
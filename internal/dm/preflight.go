@@ -0,0 +1,107 @@
+// Package dm menyiapkan audiens DM campaign. Preflight membersihkan daftar target (nomor tidak
+// valid, daftar supresi, riwayat DM gagal, nomor tidak terdaftar WhatsApp) dan menyimpan hasilnya
+// sebagai snapshot yang dipakai saat campaign DM dijalankan.
+package dm
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"promote/internal/model"
+	"promote/internal/storage"
+	"promote/internal/wa"
+)
+
+// ErrEmptyAudience dikembalikan jika tidak ada nomor maupun grup sumber.
+var ErrEmptyAudience = errors.New("numbers, group_ids or audience_ids required")
+
+// Preflight membersihkan audiens DM sebelum campaign berjalan.
+type Preflight struct {
+	Store   *storage.Store
+	Manager *wa.Manager
+	// Nomor yang DM terakhirnya gagal dalam jangka ini dilewati (DM_FAILED_LOOKBACK_DAYS, default 30).
+	FailedLookback time.Duration
+}
+
+// New membuat Preflight; lookback dibaca dari DM_FAILED_LOOKBACK_DAYS.
+func New(store *storage.Store, manager *wa.Manager) *Preflight {
+	p := &Preflight{Store: store, Manager: manager, FailedLookback: 30 * 24 * time.Hour}
+	if n, err := strconv.Atoi(strings.TrimSpace(os.Getenv("DM_FAILED_LOOKBACK_DAYS"))); err == nil && n >= 0 {
+		p.FailedLookback = time.Duration(n) * 24 * time.Hour
+	}
+	return p
+}
+
+// Run menyusun audiens dari nomor eksplisit, anggota grup (cache) dan audiens tersimpan, menyaringnya, lalu
+// menyimpan snapshot untuk campaign. Pemeriksaan lokal dijalankan dulu agar query IsOnWhatsApp
+// hanya untuk nomor yang tersisa.
+func (p *Preflight) Run(ctx context.Context, campaignID, accountID string, numbers, groupIDs, audienceIDs []string) (model.DMRun, error) {
+	run := model.DMRun{CampaignID: campaignID, AccountID: accountID, Audience: []string{}, Removed: map[string][]string{}}
+	members, err := p.Store.GroupParticipantNumbers(groupIDs)
+	if err != nil {
+		return run, err
+	}
+	listed, err := p.Store.AudienceNumbers(audienceIDs)
+	if err != nil {
+		return run, err
+	}
+	raw := append(append(append([]string{}, numbers...), members...), listed...)
+	if len(raw) == 0 {
+		return run, ErrEmptyAudience
+	}
+
+	suppressed, err := p.Store.DMSuppressedSet()
+	if err != nil {
+		return run, err
+	}
+	failed, err := p.Store.RecentlyFailedDMs(time.Now().Add(-p.FailedLookback))
+	if err != nil {
+		return run, err
+	}
+
+	seen := map[string]bool{}
+	var candidates []string
+	for _, in := range raw {
+		n := storage.NormalizeNumber(in)
+		switch {
+		case n == "":
+			run.Removed[model.DMRemovedInvalid] = append(run.Removed[model.DMRemovedInvalid], strings.TrimSpace(in))
+			continue
+		case seen[n]:
+			continue
+		}
+		seen[n] = true
+		switch {
+		case suppressed[n]:
+			run.Removed[model.DMRemovedSuppressed] = append(run.Removed[model.DMRemovedSuppressed], n)
+		case failed[n]:
+			run.Removed[model.DMRemovedPriorFailed] = append(run.Removed[model.DMRemovedPriorFailed], n)
+		default:
+			candidates = append(candidates, n)
+		}
+	}
+	run.InputCount = len(seen) + len(run.Removed[model.DMRemovedInvalid])
+
+	if len(candidates) > 0 {
+		onWA, err := p.Manager.CheckOnWhatsApp(ctx, accountID, candidates)
+		if err != nil {
+			return run, err
+		}
+		for _, n := range candidates {
+			if onWA[n] {
+				run.Audience = append(run.Audience, n)
+			} else {
+				run.Removed[model.DMRemovedNotOnWhatsApp] = append(run.Removed[model.DMRemovedNotOnWhatsApp], n)
+			}
+		}
+	}
+
+	if err := p.Store.SaveDMRun(&run); err != nil {
+		return run, err
+	}
+	return run, nil
+}
@@ -0,0 +1,160 @@
+package doctor
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"promote/internal/paths"
+	"promote/internal/service"
+	"promote/internal/storage"
+	"promote/internal/wa"
+)
+
+const usage = `usage:
+  promote doctor [-fix] [-only foreign_keys,orphan_sessions,missing_uploads] [-json] [-env-file PATH]
+
+doctor memeriksa integritas data di DATA_DIR (atau DB_DSN): referensi yatim antar tabel, file sesi
+WhatsApp tanpa akun, dan template yang merujuk upload yang hilang. Tanpa -fix hanya melapor;
+-fix menjalankan perbaikan untuk cek yang bermasalah (atau hanya cek di -only). Sebaiknya
+dijalankan saat service berhenti. Exit code 1 jika masih ada temuan.`
+
+// Command menjalankan "promote doctor" dan mengembalikan exit code.
+func Command(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("doctor", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() { fmt.Fprintln(stderr, usage) }
+	fix := fs.Bool("fix", false, "jalankan perbaikan")
+	only := fs.String("only", "", "daftar cek dipisah koma (default semua)")
+	asJSON := fs.Bool("json", false, "keluaran JSON")
+	envFile := fs.String("env-file", "", "muat KEY=VALUE dari file sebelum membuka DB")
+	if err := fs.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return 0
+		}
+		return 2
+	}
+	if err := run(stdout, *fix, *only, *asJSON, *envFile); err != nil {
+		fmt.Fprintln(stderr, "doctor:", err)
+		if errors.Is(err, ErrUnknownCheck) {
+			return 2
+		}
+		return 1
+	}
+	return 0
+}
+
+// errIssues menandai laporan yang masih berisi temuan (exit code 1).
+type errIssues int
+
+func (e errIssues) Error() string { return fmt.Sprintf("%d issue(s) remaining", int(e)) }
+
+func run(stdout io.Writer, fix bool, only string, asJSON bool, envFile string) error {
+	if envFile != "" {
+		if err := service.LoadEnvFile(envFile); err != nil {
+			return err
+		}
+	}
+	dirs, err := paths.FromEnv()
+	if err != nil {
+		return err
+	}
+	dsn := os.Getenv("DB_DSN")
+	if dsn == "" {
+		dsn = dirs.DSN()
+	}
+	store, err := storage.Open(dsn)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+	d := New(store, &wa.Manager{BaseDSN: dsn, SessionDir: dirs.SessionDir}, dirs.UploadDir)
+
+	var checks []string
+	for _, c := range strings.Split(only, ",") {
+		if c = strings.TrimSpace(c); c != "" {
+			checks = append(checks, c)
+		}
+	}
+	for _, c := range checks {
+		if !known(c) {
+			return fmt.Errorf("%w %q (valid: %s)", ErrUnknownCheck, c, strings.Join(Checks, ", "))
+		}
+	}
+
+	rep, err := d.Check()
+	if err != nil {
+		return err
+	}
+	var repairs []RepairResult
+	if fix && !rep.OK {
+		todo := failing(rep, checks)
+		if len(todo) > 0 {
+			if repairs, err = d.Repair(todo); err != nil {
+				return err
+			}
+			if rep, err = d.Check(); err != nil {
+				return err
+			}
+		}
+	}
+	if asJSON {
+		enc := json.NewEncoder(stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(map[string]any{"report": rep, "repairs": repairs}); err != nil {
+			return err
+		}
+	} else {
+		printText(stdout, rep, repairs, fix)
+	}
+	if !rep.OK {
+		return errIssues(len(rep.Issues))
+	}
+	return nil
+}
+
+// failing cek yang punya temuan, dibatasi ke only jika diisi.
+func failing(rep Report, only []string) []string {
+	want := map[string]bool{}
+	for _, c := range only {
+		want[c] = true
+	}
+	var out []string
+	seen := map[string]bool{}
+	for _, is := range rep.Issues {
+		if seen[is.Check] || (len(want) > 0 && !want[is.Check]) {
+			continue
+		}
+		seen[is.Check] = true
+		out = append(out, is.Check)
+	}
+	return out
+}
+
+func printText(w io.Writer, rep Report, repairs []RepairResult, fix bool) {
+	for _, r := range repairs {
+		if r.Error != "" {
+			fmt.Fprintf(w, "repair %-16s FAILED: %s\n", r.Check, r.Error)
+			continue
+		}
+		fmt.Fprintf(w, "repair %-16s %d fixed %s\n", r.Check, r.Repaired, r.Detail)
+	}
+	if rep.OK {
+		fmt.Fprintln(w, "ok: no integrity issues found")
+		return
+	}
+	for _, is := range rep.Issues {
+		fmt.Fprintf(w, "[%s] %s (%d)\n", is.Check, is.Message, is.Count)
+		for _, s := range is.Samples {
+			fmt.Fprintf(w, "    - %s\n", s)
+		}
+		fmt.Fprintf(w, "    repair: %s\n", is.Repair)
+	}
+	if !fix {
+		fmt.Fprintln(w, "run `promote doctor -fix` (optionally with -only CHECK) to apply the repairs")
+	}
+}
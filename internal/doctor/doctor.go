@@ -0,0 +1,316 @@
+// Package doctor memeriksa integritas data (referensi yatim di DB utama, file sesi WhatsApp tanpa
+// akun, template yang merujuk upload yang hilang) dan menyediakan perbaikan terpandu per jenis cek.
+// Dipakai oleh perintah "promote doctor", log ringkasan saat start, dan /api/doctor.
+package doctor
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"promote/internal/storage"
+	"promote/internal/wa"
+)
+
+// Nama cek; dipakai juga untuk memilih perbaikan.
+const (
+	CheckForeignKeys = "foreign_keys"
+	CheckSessions    = "orphan_sessions"
+	CheckUploads     = "missing_uploads"
+)
+
+const (
+	// orphanSessionsDir subdirektori tujuan file sesi yatim (dipindah, tidak dihapus).
+	orphanSessionsDir  = "orphan-sessions"
+	maxSamplesPerIssue = 5
+)
+
+// Checks semua cek dalam urutan eksekusi.
+var Checks = []string{CheckForeignKeys, CheckSessions, CheckUploads}
+
+// ErrUnknownCheck dikembalikan untuk nama cek yang tidak dikenal.
+var ErrUnknownCheck = errors.New("unknown check")
+
+// Issue satu temuan.
+type Issue struct {
+	Check   string `json:"check"`
+	Message string `json:"message"`
+	Count   int    `json:"count"`
+	// Samples contoh item yang bermasalah (ID, nama file).
+	Samples []string `json:"samples,omitempty"`
+	// Repair deskripsi perbaikan yang dijalankan oleh Repair(Check).
+	Repair string `json:"repair"`
+}
+
+// Report hasil satu kali pemeriksaan.
+type Report struct {
+	CheckedAt time.Time `json:"checked_at"`
+	OK        bool      `json:"ok"`
+	Issues    []Issue   `json:"issues"`
+}
+
+// RepairResult hasil perbaikan satu cek.
+type RepairResult struct {
+	Check    string `json:"check"`
+	Repaired int    `json:"repaired"`
+	Detail   string `json:"detail,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
+
+// Doctor menjalankan cek integritas.
+type Doctor struct {
+	Store *storage.Store
+	// Sessions memetakan akun ke file sesi whatsmeow; nil = cek sesi dilewati.
+	Sessions *wa.Manager
+	// UploadDir lokasi file /uploads/.
+	UploadDir string
+}
+
+// New membuat Doctor.
+func New(store *storage.Store, sessions *wa.Manager, uploadDir string) *Doctor {
+	return &Doctor{Store: store, Sessions: sessions, UploadDir: uploadDir}
+}
+
+// Check menjalankan semua cek. Error salah satu cek menghentikan pemeriksaan.
+func (d *Doctor) Check() (Report, error) {
+	rep := Report{CheckedAt: time.Now().UTC(), Issues: []Issue{}}
+	for _, name := range Checks {
+		issues, err := d.run(name)
+		if err != nil {
+			return rep, fmt.Errorf("%s: %w", name, err)
+		}
+		rep.Issues = append(rep.Issues, issues...)
+	}
+	rep.OK = len(rep.Issues) == 0
+	return rep, nil
+}
+
+func (d *Doctor) run(name string) ([]Issue, error) {
+	switch name {
+	case CheckForeignKeys:
+		return d.checkForeignKeys()
+	case CheckSessions:
+		return d.checkSessions()
+	case CheckUploads:
+		return d.checkUploads()
+	}
+	return nil, fmt.Errorf("%w %q", ErrUnknownCheck, name)
+}
+
+// Repair menjalankan perbaikan untuk cek yang diberikan (kosong = semua). Nama tidak dikenal
+// menghasilkan ErrUnknownCheck sebelum ada yang diubah; kegagalan satu cek dicatat di hasilnya.
+func (d *Doctor) Repair(checks []string) ([]RepairResult, error) {
+	if len(checks) == 0 {
+		checks = Checks
+	}
+	for _, c := range checks {
+		if !known(c) {
+			return nil, fmt.Errorf("%w %q", ErrUnknownCheck, c)
+		}
+	}
+	out := make([]RepairResult, 0, len(checks))
+	for _, c := range checks {
+		var (
+			res RepairResult
+			err error
+		)
+		switch c {
+		case CheckForeignKeys:
+			res, err = d.repairForeignKeys()
+		case CheckSessions:
+			res, err = d.repairSessions()
+		case CheckUploads:
+			res, err = d.repairUploads()
+		}
+		res.Check = c
+		if err != nil {
+			res.Error = err.Error()
+		}
+		out = append(out, res)
+	}
+	return out, nil
+}
+
+func known(c string) bool {
+	for _, k := range Checks {
+		if k == c {
+			return true
+		}
+	}
+	return false
+}
+
+func (d *Doctor) checkForeignKeys() ([]Issue, error) {
+	list, err := d.Store.ForeignKeyViolations()
+	if err != nil {
+		return nil, err
+	}
+	var out []Issue
+	for _, v := range list {
+		out = append(out, Issue{
+			Check:   CheckForeignKeys,
+			Message: fmt.Sprintf("%s.%s references missing %s", v.Table, v.Column, v.Parent),
+			Count:   len(v.RowIDs),
+			Samples: v.Keys,
+			Repair:  v.Repair(),
+		})
+	}
+	return out, nil
+}
+
+func (d *Doctor) repairForeignKeys() (RepairResult, error) {
+	list, err := d.Store.ForeignKeyViolations()
+	if err != nil {
+		return RepairResult{}, err
+	}
+	n, err := d.Store.RepairForeignKeys(list)
+	return RepairResult{Repaired: int(n), Detail: fmt.Sprintf("%d constraint(s)", len(list))}, err
+}
+
+// orphanSessions file sesi di SessionDir yang tidak cocok dengan akun mana pun.
+func (d *Doctor) orphanSessions() ([]string, error) {
+	if d.Sessions == nil {
+		return nil, nil
+	}
+	pattern := d.Sessions.SessionFile("*")
+	if pattern == "" {
+		return nil, nil
+	}
+	files, err := filepath.Glob(pattern)
+	if err != nil {
+		return nil, err
+	}
+	accs, err := d.Store.ListAccounts()
+	if err != nil {
+		return nil, err
+	}
+	owned := map[string]bool{}
+	for _, a := range accs {
+		owned[filepath.Clean(d.Sessions.SessionFile(a.ID))] = true
+	}
+	var out []string
+	for _, f := range files {
+		if !owned[filepath.Clean(f)] {
+			out = append(out, f)
+		}
+	}
+	sort.Strings(out)
+	return out, nil
+}
+
+func (d *Doctor) checkSessions() ([]Issue, error) {
+	files, err := d.orphanSessions()
+	if err != nil || len(files) == 0 {
+		return nil, err
+	}
+	samples := make([]string, 0, maxSamplesPerIssue)
+	for _, f := range files {
+		if len(samples) == maxSamplesPerIssue {
+			break
+		}
+		samples = append(samples, filepath.Base(f))
+	}
+	return []Issue{{
+		Check:   CheckSessions,
+		Message: "WhatsApp session databases without a matching account",
+		Count:   len(files),
+		Samples: samples,
+		Repair:  "move files to " + orphanSessionsDir + "/ next to the session databases",
+	}}, nil
+}
+
+// repairSessions memindahkan (bukan menghapus) file sesi yatim beserta -wal/-shm ke orphan-sessions/.
+func (d *Doctor) repairSessions() (RepairResult, error) {
+	files, err := d.orphanSessions()
+	if err != nil || len(files) == 0 {
+		return RepairResult{}, err
+	}
+	dst := filepath.Join(filepath.Dir(files[0]), orphanSessionsDir)
+	if err := os.MkdirAll(dst, 0o700); err != nil {
+		return RepairResult{}, err
+	}
+	res := RepairResult{Detail: "moved to " + dst}
+	for _, f := range files {
+		for _, suffix := range []string{"", "-wal", "-shm", "-journal"} {
+			err := os.Rename(f+suffix, filepath.Join(dst, filepath.Base(f)+suffix))
+			if err != nil && !errors.Is(err, os.ErrNotExist) {
+				return res, err
+			}
+		}
+		res.Repaired++
+	}
+	return res, nil
+}
+
+// brokenTemplate template yang merujuk file upload yang tidak ada.
+type brokenTemplate struct {
+	id, name string
+	missing  []string
+}
+
+func (d *Doctor) brokenTemplates() ([]brokenTemplate, error) {
+	refs, names, err := d.Store.TemplateUploads()
+	if err != nil {
+		return nil, err
+	}
+	var out []brokenTemplate
+	for id, files := range refs {
+		var missing []string
+		for _, f := range files {
+			if _, err := os.Stat(filepath.Join(d.UploadDir, f)); errors.Is(err, os.ErrNotExist) {
+				missing = append(missing, f)
+			}
+		}
+		if len(missing) > 0 {
+			out = append(out, brokenTemplate{id: id, name: names[id], missing: missing})
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].name < out[j].name })
+	return out, nil
+}
+
+func (d *Doctor) checkUploads() ([]Issue, error) {
+	list, err := d.brokenTemplates()
+	if err != nil || len(list) == 0 {
+		return nil, err
+	}
+	samples := make([]string, 0, maxSamplesPerIssue)
+	for _, t := range list {
+		if len(samples) == maxSamplesPerIssue {
+			break
+		}
+		samples = append(samples, fmt.Sprintf("%s (%s): %s", t.name, t.id, strings.Join(t.missing, ", ")))
+	}
+	return []Issue{{
+		Check:   CheckUploads,
+		Message: "templates reference uploads that no longer exist",
+		Count:   len(list),
+		Samples: samples,
+		Repair:  "disable the templates and record the missing files as their health error",
+	}}, nil
+}
+
+// repairUploads menonaktifkan template yang rusak; file yang hilang dicatat sebagai health error
+// agar terlihat di UI sampai template diperbaiki.
+func (d *Doctor) repairUploads() (RepairResult, error) {
+	list, err := d.brokenTemplates()
+	if err != nil {
+		return RepairResult{}, err
+	}
+	var res RepairResult
+	now := time.Now()
+	for _, t := range list {
+		if err := d.Store.SetTemplateEnabled(t.id, false); err != nil {
+			return res, err
+		}
+		if err := d.Store.SetTemplateHealth(t.id, "missing upload: "+strings.Join(t.missing, ", "), now); err != nil {
+			return res, err
+		}
+		res.Repaired++
+	}
+	return res, nil
+}
@@ -0,0 +1,236 @@
+// Package dynvar me-resolve variabel template dinamis seperti {stock:sku123} saat kirim dengan
+// memanggil endpoint HTTP yang dikonfigurasi per variabel (tabel dynamic_vars). Hasil di-cache
+// per (variabel, key) selama cache_ttl_sec; saat endpoint gagal, nilai cache lama atau fallback
+// dipakai agar pengiriman tidak ikut gagal.
+package dynvar
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"promote/internal/model"
+	"promote/internal/storage"
+)
+
+const (
+	defaultTimeout = 3 * time.Second
+	maxTimeout     = 30 * time.Second
+	// retryAfter: setelah lookup gagal, nilai lama/fallback dipakai selama ini sebelum mencoba lagi.
+	retryAfter = 30 * time.Second
+	maxBody    = 64 << 10
+	maxValue   = 1024
+)
+
+// placeholderRe mencocokkan {name:key}; {field:key} ditangani sender sebagai custom field grup.
+var placeholderRe = regexp.MustCompile(`\{([a-z][a-z0-9_]{0,31}):([^{}\s]{1,128})\}`)
+
+type entry struct {
+	value   string
+	expires time.Time
+	// failed: value adalah fallback karena lookup gagal tanpa nilai cache sebelumnya.
+	failed bool
+}
+
+// Resolver mengganti placeholder variabel dinamis di teks.
+type Resolver struct {
+	Store  *storage.Store
+	Client *http.Client
+
+	mu    sync.Mutex
+	cache map[string]entry
+}
+
+// New membuat Resolver; timeout per request diatur per variabel (timeout_ms).
+func New(store *storage.Store) *Resolver {
+	return &Resolver{Store: store, Client: &http.Client{}, cache: map[string]entry{}}
+}
+
+// Has true jika teks mungkin berisi placeholder variabel dinamis.
+func Has(text string) bool {
+	return strings.Contains(text, ":") && placeholderRe.MatchString(text)
+}
+
+// Render mengganti semua {name:key} yang name-nya terdaftar dan aktif. Placeholder dengan nama
+// yang tidak dikenal dibiarkan apa adanya.
+func (r *Resolver) Render(ctx context.Context, text string) string {
+	if r == nil || !Has(text) {
+		return text
+	}
+	vars, err := r.enabledVars()
+	if err != nil {
+		log.Printf("[dynvar] load vars err=%v", err)
+		return text
+	}
+	resolved := map[string]string{}
+	return placeholderRe.ReplaceAllStringFunc(text, func(m string) string {
+		if v, ok := resolved[m]; ok {
+			return v
+		}
+		sub := placeholderRe.FindStringSubmatch(m)
+		def, ok := vars[sub[1]]
+		if !ok {
+			return m
+		}
+		val, err := r.Lookup(ctx, def, sub[2])
+		if err != nil {
+			log.Printf("[dynvar] %s err=%v", m, err)
+		}
+		resolved[m] = val
+		return val
+	})
+}
+
+func (r *Resolver) enabledVars() (map[string]model.DynamicVar, error) {
+	list, err := r.Store.ListDynamicVars()
+	if err != nil {
+		return nil, err
+	}
+	out := make(map[string]model.DynamicVar, len(list))
+	for _, v := range list {
+		if v.Enabled {
+			out[v.Name] = v
+		}
+	}
+	return out, nil
+}
+
+// Lookup mengembalikan nilai variabel untuk key, dari cache bila masih segar. Jika fetch gagal,
+// nilai cache lama (bila ada) atau Fallback dikembalikan bersama error-nya.
+func (r *Resolver) Lookup(ctx context.Context, v model.DynamicVar, key string) (string, error) {
+	ck := v.Name + "\x00" + key
+	now := time.Now()
+	r.mu.Lock()
+	e, cached := r.cache[ck]
+	r.mu.Unlock()
+	if cached && now.Before(e.expires) {
+		return e.value, nil
+	}
+
+	val, err := r.Fetch(ctx, v, key)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err != nil {
+		if cached && !e.failed {
+			// Nilai lama lebih baik daripada fallback; coba lagi setelah retryAfter.
+			r.cache[ck] = entry{value: e.value, expires: now.Add(retryAfter)}
+			return e.value, err
+		}
+		r.cache[ck] = entry{value: v.Fallback, expires: now.Add(retryAfter), failed: true}
+		return v.Fallback, err
+	}
+	if ttl := time.Duration(v.CacheTTLSec) * time.Second; ttl > 0 {
+		r.cache[ck] = entry{value: val, expires: now.Add(ttl)}
+	} else {
+		// Tanpa cache, tapi tetap simpan sebagai nilai cadangan jika fetch berikutnya gagal.
+		r.cache[ck] = entry{value: val, expires: now}
+	}
+	return val, nil
+}
+
+// Fetch memanggil endpoint variabel tanpa cache.
+func (r *Resolver) Fetch(ctx context.Context, v model.DynamicVar, key string) (string, error) {
+	timeout := time.Duration(v.TimeoutMs) * time.Millisecond
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+	if timeout > maxTimeout {
+		timeout = maxTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	u := strings.ReplaceAll(v.URLTemplate, "{key}", url.PathEscape(key))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return "", err
+	}
+	if v.JSONPath != "" {
+		req.Header.Set("Accept", "application/json")
+	}
+	resp, err := r.Client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 400 {
+		return "", fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxBody))
+	if err != nil {
+		return "", err
+	}
+	var val string
+	if v.JSONPath == "" {
+		val = strings.TrimSpace(string(body))
+	} else if val, err = extract(body, v.JSONPath); err != nil {
+		return "", err
+	}
+	if len(val) > maxValue {
+		val = val[:maxValue]
+	}
+	return val, nil
+}
+
+// extract mengambil nilai dari JSON dengan path bertitik, mis. "data.items.0.price".
+func extract(body []byte, path string) (string, error) {
+	var doc any
+	if err := json.Unmarshal(body, &doc); err != nil {
+		return "", fmt.Errorf("invalid JSON response: %w", err)
+	}
+	cur := doc
+	for _, seg := range strings.Split(path, ".") {
+		switch node := cur.(type) {
+		case map[string]any:
+			next, ok := node[seg]
+			if !ok {
+				return "", fmt.Errorf("json path %q: key %q not found", path, seg)
+			}
+			cur = next
+		case []any:
+			i, err := strconv.Atoi(seg)
+			if err != nil || i < 0 || i >= len(node) {
+				return "", fmt.Errorf("json path %q: bad index %q", path, seg)
+			}
+			cur = node[i]
+		default:
+			return "", fmt.Errorf("json path %q: %q is not an object or array", path, seg)
+		}
+	}
+	switch val := cur.(type) {
+	case nil:
+		return "", fmt.Errorf("json path %q: value is null", path)
+	case string:
+		return val, nil
+	case float64:
+		return strconv.FormatFloat(val, 'f', -1, 64), nil
+	case bool:
+		return strconv.FormatBool(val), nil
+	default:
+		b, _ := json.Marshal(val)
+		return string(b), nil
+	}
+}
+
+// Invalidate membuang cache satu variabel (setelah konfigurasinya diubah atau dihapus).
+func (r *Resolver) Invalidate(name string) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for k := range r.cache {
+		if strings.HasPrefix(k, name+"\x00") {
+			delete(r.cache, k)
+		}
+	}
+}
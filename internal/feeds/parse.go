@@ -0,0 +1,180 @@
+package feeds
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+)
+
+// Item adalah entri feed yang sudah dinormalisasi dari RSS, Atom, atau JSON Feed.
+type Item struct {
+	GUID    string
+	Title   string
+	Link    string
+	Summary string
+	Image   string
+}
+
+type rssDoc struct {
+	Channel struct {
+		Items []struct {
+			Title       string `xml:"title"`
+			Link        string `xml:"link"`
+			GUID        string `xml:"guid"`
+			Description string `xml:"description"`
+			Enclosure   []struct {
+				URL  string `xml:"url,attr"`
+				Type string `xml:"type,attr"`
+			} `xml:"enclosure"`
+			MediaContent []struct {
+				URL    string `xml:"url,attr"`
+				Medium string `xml:"medium,attr"`
+				Type   string `xml:"type,attr"`
+			} `xml:"http://search.yahoo.com/mrss/ content"`
+			MediaThumb []struct {
+				URL string `xml:"url,attr"`
+			} `xml:"http://search.yahoo.com/mrss/ thumbnail"`
+		} `xml:"item"`
+	} `xml:"channel"`
+}
+
+type atomDoc struct {
+	Entries []struct {
+		ID      string `xml:"id"`
+		Title   string `xml:"title"`
+		Summary string `xml:"summary"`
+		Content string `xml:"content"`
+		Links   []struct {
+			Href string `xml:"href,attr"`
+			Rel  string `xml:"rel,attr"`
+			Type string `xml:"type,attr"`
+		} `xml:"link"`
+	} `xml:"entry"`
+}
+
+type jsonFeedDoc struct {
+	Items []struct {
+		ID          json.RawMessage `json:"id"`
+		URL         string          `json:"url"`
+		Title       string          `json:"title"`
+		Summary     string          `json:"summary"`
+		ContentText string          `json:"content_text"`
+		ContentHTML string          `json:"content_html"`
+		Image       string          `json:"image"`
+		BannerImage string          `json:"banner_image"`
+	} `json:"items"`
+}
+
+// Parse mendeteksi format feed (JSON Feed, RSS 2.0, atau Atom) dan mengembalikan item-itemnya
+// sesuai urutan di dokumen (biasanya terbaru dulu).
+func Parse(body []byte) ([]Item, error) {
+	trim := strings.TrimSpace(string(body))
+	if strings.HasPrefix(trim, "{") {
+		var doc jsonFeedDoc
+		if err := json.Unmarshal(body, &doc); err != nil {
+			return nil, fmt.Errorf("json feed: %w", err)
+		}
+		var out []Item
+		for _, it := range doc.Items {
+			id := strings.Trim(string(it.ID), `"`)
+			summary := it.Summary
+			if summary == "" {
+				summary = it.ContentText
+			}
+			if summary == "" {
+				summary = it.ContentHTML
+			}
+			img := it.Image
+			if img == "" {
+				img = it.BannerImage
+			}
+			out = append(out, normalize(Item{GUID: id, Title: it.Title, Link: it.URL, Summary: summary, Image: img}))
+		}
+		return out, nil
+	}
+
+	var rss rssDoc
+	if err := xml.Unmarshal(body, &rss); err == nil && len(rss.Channel.Items) > 0 {
+		var out []Item
+		for _, it := range rss.Channel.Items {
+			img := ""
+			for _, e := range it.Enclosure {
+				if strings.HasPrefix(e.Type, "image/") {
+					img = e.URL
+					break
+				}
+			}
+			if img == "" {
+				for _, m := range it.MediaContent {
+					if m.Medium == "image" || strings.HasPrefix(m.Type, "image/") {
+						img = m.URL
+						break
+					}
+				}
+			}
+			if img == "" && len(it.MediaThumb) > 0 {
+				img = it.MediaThumb[0].URL
+			}
+			out = append(out, normalize(Item{GUID: it.GUID, Title: it.Title, Link: it.Link, Summary: it.Description, Image: img}))
+		}
+		return out, nil
+	}
+
+	var atom atomDoc
+	if err := xml.Unmarshal(body, &atom); err != nil {
+		return nil, fmt.Errorf("unrecognized feed format: %w", err)
+	}
+	var out []Item
+	for _, e := range atom.Entries {
+		link, img := "", ""
+		for _, l := range e.Links {
+			switch {
+			case l.Rel == "enclosure" && strings.HasPrefix(l.Type, "image/"):
+				img = l.Href
+			case (l.Rel == "" || l.Rel == "alternate") && link == "":
+				link = l.Href
+			}
+		}
+		summary := e.Summary
+		if summary == "" {
+			summary = e.Content
+		}
+		out = append(out, normalize(Item{GUID: e.ID, Title: e.Title, Link: link, Summary: summary, Image: img}))
+	}
+	return out, nil
+}
+
+var (
+	tagRe   = regexp.MustCompile(`(?s)<[^>]*>`)
+	spaceRe = regexp.MustCompile(`\s+`)
+)
+
+func normalize(it Item) Item {
+	it.Title = cleanText(it.Title, 200)
+	it.Summary = cleanText(it.Summary, 400)
+	it.Link = strings.TrimSpace(it.Link)
+	it.Image = strings.TrimSpace(it.Image)
+	it.GUID = strings.TrimSpace(it.GUID)
+	if it.GUID == "" {
+		it.GUID = it.Link
+	}
+	if it.GUID == "" {
+		it.GUID = it.Title
+	}
+	return it
+}
+
+// cleanText membuang tag HTML, merapikan spasi, dan memotong ke maxRunes karakter.
+func cleanText(s string, maxRunes int) string {
+	s = tagRe.ReplaceAllString(s, " ")
+	s = html.UnescapeString(s)
+	s = strings.TrimSpace(spaceRe.ReplaceAllString(s, " "))
+	r := []rune(s)
+	if len(r) > maxRunes {
+		s = strings.TrimSpace(string(r[:maxRunes])) + "…"
+	}
+	return s
+}
@@ -0,0 +1,175 @@
+// Package feeds memantau RSS/Atom/JSON feed per campaign dan membuat template promo
+// otomatis dari item baru (judul, ringkasan, link, og:image yang diimpor ke uploads).
+package feeds
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"promote/internal/model"
+	"promote/internal/ogp"
+	"promote/internal/storage"
+)
+
+// maxItemsPerPoll membatasi template baru per poll; item lebih lama ditandai "skipped"
+// agar poll pertama pada feed besar tidak membanjiri rotasi.
+const maxItemsPerPoll = 5
+
+// Watcher mem-poll feed yang jatuh tempo secara berkala.
+type Watcher struct {
+	Store     *storage.Store
+	Client    *http.Client
+	UploadDir string
+	// Interval cek feed yang jatuh tempo (poll_minutes per feed tetap berlaku).
+	Interval time.Duration
+}
+
+// New membuat Watcher dengan konfigurasi default. client dipakai untuk feed, halaman og:image
+// dan unduhan gambar; berikan client ber-FetchPolicy (Sender.Client) agar URL feed tidak bisa
+// diarahkan ke jaringan internal.
+func New(store *storage.Store, client *http.Client) *Watcher {
+	return &Watcher{
+		Store:     store,
+		Client:    client,
+		UploadDir: "uploads",
+		Interval:  time.Minute,
+	}
+}
+
+// Start menjalankan loop polling di goroutine sampai ctx selesai.
+func (w *Watcher) Start(ctx context.Context) {
+	go func() {
+		tick := time.NewTicker(w.Interval)
+		defer tick.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-tick.C:
+				w.pollDue(ctx)
+			}
+		}
+	}()
+}
+
+func (w *Watcher) pollDue(ctx context.Context) {
+	due, err := w.Store.DueFeeds(time.Now())
+	if err != nil {
+		log.Printf("[feeds] list due feeds: %v", err)
+		return
+	}
+	for _, f := range due {
+		n, err := w.Poll(ctx, f)
+		if err != nil {
+			log.Printf("[feeds] feed=%s poll failed: %v", f.ID, err)
+			continue
+		}
+		if n > 0 {
+			log.Printf("[feeds] feed=%s created %d template(s)", f.ID, n)
+		}
+	}
+}
+
+// Poll mengambil feed sekali dan membuat template untuk item baru. Mengembalikan jumlah template dibuat.
+func (w *Watcher) Poll(ctx context.Context, f model.Feed) (int, error) {
+	items, err := w.fetch(ctx, f.URL)
+	if err != nil {
+		_ = w.Store.MarkFeedPolled(f.ID, time.Now(), err.Error())
+		return 0, err
+	}
+	created := 0
+	for _, it := range items {
+		if it.GUID == "" {
+			continue
+		}
+		exists, err := w.Store.FeedItemExists(f.ID, it.GUID)
+		if err != nil {
+			return created, err
+		}
+		if exists {
+			continue
+		}
+		rec := model.FeedItem{FeedID: f.ID, GUID: it.GUID, Title: it.Title, Link: it.Link, Summary: it.Summary}
+		if created >= maxItemsPerPoll {
+			rec.Status = "skipped"
+			if _, err := w.Store.SaveFeedItem(rec, nil, false); err != nil {
+				return created, err
+			}
+			continue
+		}
+		tpl := w.buildTemplate(ctx, it, &rec)
+		tpl.CampaignID = f.CampaignID
+		enabled := f.Mode == model.FeedModeAuto
+		rec.Status = "pending"
+		if enabled {
+			rec.Status = "approved"
+		}
+		if _, err := w.Store.SaveFeedItem(rec, tpl, enabled); err != nil {
+			return created, err
+		}
+		created++
+	}
+	_ = w.Store.MarkFeedPolled(f.ID, time.Now(), "")
+	return created, nil
+}
+
+func (w *Watcher) fetch(ctx context.Context, url string) ([]Item, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", "promotenews-feed/1.0")
+	res, err := w.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return nil, fmt.Errorf("fetch feed: status %d", res.StatusCode)
+	}
+	body, err := io.ReadAll(io.LimitReader(res.Body, 5<<20))
+	if err != nil {
+		return nil, err
+	}
+	return Parse(body)
+}
+
+// buildTemplate menyusun isi template dari item; gambar diambil dari feed atau og:image halaman
+// lalu diimpor ke uploads. Jika gambar gagal, template tetap dibuat sebagai teks saja.
+func (w *Watcher) buildTemplate(ctx context.Context, it Item, rec *model.FeedItem) *storage.FeedTemplate {
+	img := it.Image
+	if img == "" && it.Link != "" {
+		if p, err := ogp.Fetch(ctx, w.Client, it.Link); err == nil {
+			img = p.Image
+			if rec.Summary == "" && p.Description != "" {
+				rec.Summary = cleanText(p.Description, 400)
+			}
+		}
+	}
+	var lines []string
+	if rec.Title != "" {
+		lines = append(lines, "*"+rec.Title+"*")
+	}
+	if rec.Summary != "" {
+		lines = append(lines, rec.Summary)
+	}
+	if rec.Link != "" {
+		lines = append(lines, rec.Link)
+	}
+	text := strings.Join(lines, "\n\n")
+	name := "[feed] " + cleanText(rec.Title, 60)
+	if img != "" {
+		local, err := ogp.ImportImage(ctx, w.Client, img, w.UploadDir, 0)
+		if err == nil {
+			rec.ImageURL = local
+			return &storage.FeedTemplate{Name: name, ImageURLs: []string{local}, ImageCaption: text}
+		}
+		log.Printf("[feeds] import image %s: %v", img, err)
+	}
+	return &storage.FeedTemplate{Name: name, TextOnly: text}
+}
@@ -0,0 +1,78 @@
+// Package grouptrial mencatat sinyal masa uji grup baru dari event WhatsApp: balasan anggota ke
+// pesan akun dan akun dikeluarkan dari grup. Verdict masa uji dihitung scheduler.
+package grouptrial
+
+import (
+	"log"
+
+	waProto "go.mau.fi/whatsmeow/binary/proto"
+	"go.mau.fi/whatsmeow/types"
+	"go.mau.fi/whatsmeow/types/events"
+
+	"promote/internal/storage"
+	"promote/internal/wa"
+)
+
+// Tracker menerima event pesan dan info grup lewat Manager.AddMessageHandler/AddGroupInfoHandler.
+type Tracker struct {
+	Store   *storage.Store
+	Manager *wa.Manager
+}
+
+// New membuat Tracker.
+func New(store *storage.Store, manager *wa.Manager) *Tracker {
+	return &Tracker{Store: store, Manager: manager}
+}
+
+// HandleMessage menghitung balasan (quote) anggota grup ke pesan akun selama masa uji.
+func (t *Tracker) HandleMessage(accountID string, evt *events.Message) {
+	if evt == nil || evt.Message == nil || evt.Info.IsFromMe || !evt.Info.IsGroup {
+		return
+	}
+	quoted := quotedParticipant(evt.Message)
+	if quoted == "" {
+		return
+	}
+	jid, err := types.ParseJID(quoted)
+	if err != nil || !t.Manager.IsSelf(accountID, jid) {
+		return
+	}
+	if err := t.Store.RecordTrialReply(evt.Info.Chat.String()); err != nil {
+		log.Printf("[grouptrial] record reply group=%s err=%v", evt.Info.Chat, err)
+	}
+}
+
+// HandleGroupInfo menandai masa uji grup saat akun dikeluarkan oleh anggota lain (bukan keluar sendiri).
+func (t *Tracker) HandleGroupInfo(accountID string, evt *events.GroupInfo) {
+	if evt == nil || len(evt.Leave) == 0 {
+		return
+	}
+	if evt.Sender != nil && t.Manager.IsSelf(accountID, *evt.Sender) {
+		return
+	}
+	for _, j := range evt.Leave {
+		if !t.Manager.IsSelf(accountID, j) {
+			continue
+		}
+		log.Printf("[grouptrial] account=%s removed from group=%s", accountID, evt.JID)
+		if err := t.Store.RecordTrialKick(accountID, evt.JID.String()); err != nil {
+			log.Printf("[grouptrial] record kick group=%s err=%v", evt.JID, err)
+		}
+		return
+	}
+}
+
+// quotedParticipant pengirim pesan yang di-quote (balasan); kosong jika bukan balasan.
+func quotedParticipant(msg *waProto.Message) string {
+	switch {
+	case msg.GetExtendedTextMessage().GetContextInfo() != nil:
+		return msg.GetExtendedTextMessage().GetContextInfo().GetParticipant()
+	case msg.GetImageMessage().GetContextInfo() != nil:
+		return msg.GetImageMessage().GetContextInfo().GetParticipant()
+	case msg.GetVideoMessage().GetContextInfo() != nil:
+		return msg.GetVideoMessage().GetContextInfo().GetParticipant()
+	case msg.GetStickerMessage().GetContextInfo() != nil:
+		return msg.GetStickerMessage().GetContextInfo().GetParticipant()
+	}
+	return ""
+}
@@ -1,11 +1,13 @@
 package httpapi
 
 import (
+	"bytes"
 	"context"
-	"database/sql"
+	"encoding/base64"
 	"encoding/csv"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"log"
 	"net/http"
@@ -18,10 +20,24 @@ import (
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/google/uuid"
 
+	"promote/internal/autoleave"
+	"promote/internal/compliance"
+	"promote/internal/cron"
+	"promote/internal/digest"
+	"promote/internal/dm"
+	"promote/internal/doctor"
+	"promote/internal/feeds"
+	"promote/internal/lint"
+	"promote/internal/linkhealth"
 	"promote/internal/model"
+	"promote/internal/scrape"
+	"promote/internal/mediacache"
 	"promote/internal/sender"
+	"promote/internal/shortlink"
 	"promote/internal/storage"
+	"promote/internal/voicenote"
 	"promote/internal/wa"
+	"promote/internal/webhook"
 )
 
 type API struct {
@@ -30,19 +46,53 @@ type API struct {
 	Sender     *sender.Sender
 	AutoJoiner interface {
 		ProcessInviteCode(ctx context.Context, accountID, inviteCode, sharedBy, sharedIn string)
+		// Wake membangunkan worker antrean persetujuan setelah item disetujui.
+		Wake()
 	}
 	Router *chi.Mux
-}
-
-func NewRouter(store *storage.Store, manager *wa.Manager, autoJoiner interface {
+	Options
+}
+
+// Options berisi subsistem tambahan yang diekspos lewat API. Field nil berarti fitur tidak aktif.
+type Options struct {
+	Feeds      *feeds.Watcher
+	Links      *shortlink.Service
+	Compliance *compliance.Scanner
+	Digest     *digest.Runner
+	Cron       *cron.Runner
+	Scraper    *scrape.Scraper
+	// DMPreflight membersihkan audiens DM campaign (IsOnWhatsApp, supresi, riwayat gagal).
+	DMPreflight *dm.Preflight
+	// UploadDir lokasi file /uploads/ (default "uploads" relatif CWD).
+	UploadDir string
+	// MediaPrefetch menyiapkan media template/campaign aktif ke cache lokal.
+	MediaPrefetch *mediacache.Prefetcher
+	// Webhooks membangunkan worker webhook setelah test/retry (nil = tunggu poll berikutnya).
+	Webhooks *webhook.Dispatcher
+	// Doctor cek integritas data (referensi yatim, sesi tanpa akun, upload hilang).
+	Doctor *doctor.Doctor
+	// LinkHealth cek berkala link undangan grup milik sendiri (dicabut / grup penuh).
+	LinkHealth *linkhealth.Monitor
+	// AutoLeave keluar otomatis dari grup bernilai rendah menurut kebijakan "auto_leave".
+	AutoLeave *autoleave.Leaver
+	// AdminAPIKey (ADMIN_API_KEY) selalu diterima sebagai API key, di samping key di tabel api_keys.
+	AdminAPIKey string
+}
+
+func NewRouter(store *storage.Store, manager *wa.Manager, snd *sender.Sender, autoJoiner interface {
 	ProcessInviteCode(ctx context.Context, accountID, inviteCode, sharedBy, sharedIn string)
-}) *chi.Mux {
+	Wake()
+}, opts Options) *chi.Mux {
+	if snd == nil {
+		snd = sender.New(store, manager)
+	}
 	api := &API{
 		Store:      store,
 		Manager:    manager,
-		Sender:     sender.New(store, manager),
+		Sender:     snd,
 		AutoJoiner: autoJoiner,
 		Router:     chi.NewRouter(),
+		Options:    opts,
 	}
 	r := api.Router
 	r.Use(middleware.RequestID)
@@ -51,24 +101,148 @@ func NewRouter(store *storage.Store, manager *wa.Manager, autoJoiner interface {
 	r.Use(middleware.Recoverer)
 	r.Use(middleware.Timeout(120 * time.Second))
 	r.Use(cors)
+	r.Use(api.authenticate)
+	r.Use(api.resolveAccountRef)
+	api.logAuthMode()
+	if api.Links != nil && api.Links.Host() != "" {
+		r.Use(api.shortLinkHost)
+	}
 
 	api.routes()
 	return r
 }
 
+// uploadDir lokasi file yang disajikan di /uploads/.
+func (a *API) uploadDir() string {
+	if a.UploadDir == "" {
+		return "uploads"
+	}
+	return a.UploadDir
+}
+
 func (a *API) routes() {
+	// Route lain (baca, kirim) cukup login dengan role apa pun begitu autentikasi aktif (authenticate);
+	// route khusus admin (hapus akun, pengaturan scheduler/pool/cron, user, API key) lewat adm.
+	adm := a.Router.With(a.requireAdmin)
+
 	a.Router.Get("/api/health", a.handleHealth)
+	// Metrik Prometheus per akun (streak gagal, menit sejak sukses terakhir)
+	a.Router.Get("/metrics", a.handleMetrics)
+	// Login multi-user (role admin|operator) dan manajemen pengguna
+	a.Router.Post("/api/auth/login", a.handleLogin)
+	a.Router.Post("/api/auth/logout", a.handleLogout)
+	a.Router.Get("/api/auth/me", a.handleMe)
+	adm.Get("/api/users", a.handleListUsers)
+	adm.Post("/api/users", a.handleCreateUser)
+	adm.Put("/api/users/{id}", a.handleUpdateUser)
+	adm.Delete("/api/users/{id}", a.handleDeleteUser)
+	// API key (wajib untuk semua request yang mengubah data begitu autentikasi aktif)
+	adm.Get("/api/keys", a.handleListAPIKeys)
+	adm.Post("/api/keys", a.handleCreateAPIKey)
+	adm.Delete("/api/keys/{id}", a.handleRevokeAPIKey)
 	a.Router.Get("/api/accounts", a.handleListAccounts)
+	a.Router.Get("/api/accounts/status", a.handleAccountsStatus)
 	a.Router.Post("/api/accounts", a.handleCreateAccount)
-	a.Router.Put("/api/accounts/{id}", a.handleUpdateAccount)
-	a.Router.Delete("/api/accounts/{id}", a.handleDeleteAccount)
-	a.Router.Post("/api/accounts/{id}/force_delete", a.handleForceDeleteAccount)
+	adm.Put("/api/accounts/{id}", a.handleUpdateAccount)
+	adm.Delete("/api/accounts/{id}", a.handleDeleteAccount)
+	adm.Post("/api/accounts/{id}/force_delete", a.handleForceDeleteAccount)
+	// Mode observer: akun hanya untuk monitoring (inbox, sync grup, analitik), tidak pernah mengirim
+	adm.Put("/api/accounts/{id}/observer", a.handleSetAccountObserver)
+	// Warmup nomor baru: limit harian naik bertahap menurut umur akun (profil default atau khusus akun)
+	a.Router.Get("/api/settings/warmup", a.handleGetWarmupDefault)
+	adm.Put("/api/settings/warmup", a.handleSetWarmupDefault)
+	a.Router.Get("/api/accounts/{id}/warmup", a.handleGetAccountWarmup)
+	adm.Put("/api/accounts/{id}/warmup", a.handleSetAccountWarmup)
+	adm.Delete("/api/accounts/{id}/warmup", a.handleDeleteAccountWarmup)
+	adm.Post("/api/accounts/{id}/warmup/restart", a.handleRestartAccountWarmup)
 	// Accounts ops helpers
 	a.Router.Get("/api/accounts/search", a.handleSearchAccounts)
-	a.Router.Post("/api/accounts/delete_by_msisdn", a.handleDeleteByMSISDN)
+	adm.Post("/api/accounts/delete_by_msisdn", a.handleDeleteByMSISDN)
 
 	a.Router.Get("/api/groups", a.handleListGroups)
 	a.Router.Post("/api/groups/toggle", a.handleToggleGroup)
+	// Job scraping anggota grup bertahap (rate-limited) dengan progress
+	a.Router.Get("/api/groups/scrape", a.handleScrapeStatus)
+	a.Router.Post("/api/groups/scrape", a.handleScrapeStart)
+	a.Router.Delete("/api/groups/scrape", a.handleScrapeStop)
+	// Custom field per grup untuk placeholder {field:key}
+	a.Router.Post("/api/groups/fields/import", a.handleImportGroupFields)
+	a.Router.Get("/api/groups/{gid}/fields", a.handleGetGroupFields)
+	a.Router.Put("/api/groups/{gid}/fields", a.handleSetGroupFields)
+	a.Router.Delete("/api/groups/{gid}/fields/{key}", a.handleDeleteGroupField)
+	// Variabel dinamis {name:key} (stok/harga live dari HTTP lookup, di-cache)
+	a.Router.Get("/api/dynamic-vars", a.handleListDynamicVars)
+	adm.Put("/api/dynamic-vars/{name}", a.handleUpsertDynamicVar)
+	adm.Delete("/api/dynamic-vars/{name}", a.handleDeleteDynamicVar)
+	adm.Post("/api/dynamic-vars/{name}/test", a.handleTestDynamicVar)
+	// Webhook keluar (send/account/autojoin) dan log pengirimannya
+	a.Router.Get("/api/webhooks", a.handleListWebhooks)
+	adm.Post("/api/webhooks", a.handleCreateWebhook)
+	adm.Put("/api/webhooks/{id}", a.handleUpdateWebhook)
+	adm.Delete("/api/webhooks/{id}", a.handleDeleteWebhook)
+	adm.Post("/api/webhooks/{id}/test", a.handleTestWebhook)
+	a.Router.Get("/api/webhooks/deliveries", a.handleListWebhookDeliveries)
+	adm.Post("/api/webhooks/deliveries/{id}/retry", a.handleRetryWebhookDelivery)
+	// Prefetch & validasi media ke cache lokal (otomatis di luar jendela kirim)
+	a.Router.Get("/api/media/prefetch", a.handlePrefetchStatus)
+	a.Router.Post("/api/media/prefetch", a.handleStartPrefetch)
+	// Atribut grup & targeting campaign (tag, bahasa, min anggota, risk, tanggal join)
+	a.Router.Patch("/api/groups/{gid}", a.handlePatchGroup)
+	// Batas kiriman per grup (harian/mingguan, dihitung dari semua akun) di luar cooldown
+	a.Router.Get("/api/settings/group-caps", a.handleGetGroupSendCaps)
+	adm.Put("/api/settings/group-caps", a.handleSetGroupSendCaps)
+	// Strategi rotasi akun pengirim (random, round_robin, lru, weighted sisa kuota)
+	a.Router.Get("/api/settings/account-rotation", a.handleGetAccountRotation)
+	adm.Put("/api/settings/account-rotation", a.handleSetAccountRotation)
+	a.Router.Get("/api/groups/{gid}/caps", a.handleGetGroupCaps)
+	a.Router.Put("/api/groups/{gid}/caps", a.handleSetGroupCaps)
+	a.Router.Get("/api/tags", a.handleListTags)
+	a.Router.Get("/api/campaigns/{id}/targeting", a.handleGetCampaignTargeting)
+	a.Router.Put("/api/campaigns/{id}/targeting", a.handleSetCampaignTargeting)
+	a.Router.Post("/api/targeting/preview", a.handlePreviewTargeting)
+
+	// Jendela kirim grup per hari (WIB), perkiraan jendela berikutnya dan pembagian limit harian per jendela
+	a.Router.Get("/api/settings/windows", a.handleGetSendWindows)
+	adm.Put("/api/settings/windows", a.handleSetSendWindows)
+	a.Router.Get("/api/settings/windows/forecast", a.handleSendWindowForecast)
+	// Jendela kirim scheduler: global dan khusus per akun (menggantikan global untuk akun itu)
+	a.Router.Get("/api/scheduler/windows", a.handleGetSendWindows)
+	adm.Put("/api/scheduler/windows", a.handleSetSendWindows)
+	a.Router.Get("/api/scheduler/windows/{accountID}", a.handleGetAccountSendWindows)
+	adm.Put("/api/scheduler/windows/{accountID}", a.handleSetAccountSendWindows)
+	adm.Delete("/api/scheduler/windows/{accountID}", a.handleDeleteAccountSendWindows)
+	a.Router.Get("/api/settings/window-budget", a.handleGetWindowBudget)
+	adm.Put("/api/settings/window-budget", a.handleSetWindowBudget)
+	// Ritme scheduler: interval tick, jendela minimum, tidur di luar jendela
+	a.Router.Get("/api/settings/scheduler", a.handleGetSchedulerTiming)
+	adm.Put("/api/settings/scheduler", a.handleSetSchedulerTiming)
+	// WhatsApp Channels milik akun: sync, aktif/nonaktif, posting manual, riwayat dan aturan posting terjadwal
+	a.Router.Get("/api/channels", a.handleListChannels)
+	adm.Post("/api/accounts/{id}/channels/sync", a.handleSyncChannels)
+	adm.Patch("/api/channels/{cid}", a.handleSetChannelEnabled)
+	adm.Post("/api/channels/{cid}/post", a.handlePostToChannel)
+	a.Router.Get("/api/channels/{cid}/posts", a.handleListChannelPosts)
+	a.Router.Get("/api/settings/channels", a.handleGetChannelRules)
+	adm.Put("/api/settings/channels", a.handleSetChannelRules)
+	// Status (story) broadcast: limit harian per akun, posting manual, riwayat dan jeda antar status
+	adm.Put("/api/accounts/{id}/status-limit", a.handleSetStatusLimit)
+	adm.Post("/api/accounts/{id}/status", a.handlePostStatus)
+	a.Router.Get("/api/status-posts", a.handleListStatusPosts)
+	a.Router.Get("/api/settings/status", a.handleGetStatusSettings)
+	adm.Put("/api/settings/status", a.handleSetStatusSettings)
+	// Grup komunitas WhatsApp: grup pengumuman dikecualikan dari broadcast kecuali diaktifkan
+	a.Router.Get("/api/settings/community", a.handleGetCommunitySettings)
+	adm.Put("/api/settings/community", a.handleSetCommunitySettings)
+	// Policy unduhan media remote sender (anti-SSRF, batas ukuran/redirect/konkurensi)
+	a.Router.Get("/api/settings/fetch", a.handleGetFetchPolicy)
+	adm.Put("/api/settings/fetch", a.handleSetFetchPolicy)
+	// Mirror promo sukses ke channel Telegram (bot token + chat)
+	a.Router.Get("/api/settings/telegram", a.handleGetTelegramMirror)
+	adm.Put("/api/settings/telegram", a.handleSetTelegramMirror)
+
+	// Safe mode: semua kiriman dialihkan ke grup uji akun
+	a.Router.Get("/api/settings/safe-mode", a.handleGetSafeMode)
+	adm.Put("/api/settings/safe-mode", a.handleSetSafeMode)
 	a.Router.Get("/api/stats", a.handleStats)
 	a.Router.Get("/api/diag", a.handleDiag)
 
@@ -78,47 +252,254 @@ func (a *API) routes() {
 	a.Router.Post("/api/templates/{id}/toggle", a.handleToggleTemplate)
 	a.Router.Put("/api/templates/{id}", a.handleUpdateTemplate)
 	a.Router.Delete("/api/templates/{id}", a.handleDeleteTemplate)
+	// Lint konten template: cek draft tanpa simpan, atau cek ulang & simpan hasil template tersimpan
+	a.Router.Post("/api/templates/lint", a.handleLintTemplateDraft)
+	a.Router.Post("/api/templates/{id}/lint", a.handleLintTemplate)
+	// Riwayat versi template (snapshot tiap perubahan) dan konten persis yang dipakai satu log kirim
+	a.Router.Get("/api/templates/{id}/versions", a.handleListTemplateVersions)
+	a.Router.Get("/api/templates/{id}/versions/{version}", a.handleGetTemplateVersion)
+	a.Router.Get("/api/logs/{id}/content", a.handleLogContent)
+	// Ganti satu URL media di semua template sekaligus (dengan validasi file baru)
+	a.Router.Post("/api/templates/media/replace", a.handleReplaceTemplateMedia)
+
+	// Campaigns management (teks + media; targeting opsional)
+	a.Router.Get("/api/campaigns", a.handleListCampaigns)
+	a.Router.Post("/api/campaigns", a.handleCreateCampaign)
+	a.Router.Get("/api/campaigns/{id}", a.handleGetCampaign)
+	a.Router.Put("/api/campaigns/{id}", a.handleUpdateCampaign)
+	a.Router.Delete("/api/campaigns/{id}", a.handleDeleteCampaign)
+	a.Router.Get("/api/campaigns/{id}/cross-promos", a.handleListCrossPromos)
+	// Preflight audiens DM campaign & daftar supresi
+	a.Router.Post("/api/campaigns/{id}/dm-preflight", a.handleDMPreflight)
+	a.Router.Get("/api/campaigns/{id}/dm-runs", a.handleListDMRuns)
+	a.Router.Get("/api/dm-runs/{id}", a.handleGetDMRun)
+	// Campaign mode dm: antrean nomor dari snapshot preflight, dikirim bertahap oleh scheduler
+	a.Router.Post("/api/campaigns/{id}/dm-targets", a.handleAddDMTargets)
+	a.Router.Get("/api/campaigns/{id}/dm-targets", a.handleListDMTargets)
+	a.Router.Delete("/api/campaigns/{id}/dm-targets", a.handleCancelDMTargets)
+	adm.Put("/api/accounts/{id}/dm-limit", a.handleSetDMLimit)
+	a.Router.Get("/api/dm/suppressions", a.handleListDMSuppressions)
+	a.Router.Post("/api/dm/suppressions", a.handleAddDMSuppressions)
+	a.Router.Delete("/api/dm/suppressions/{number}", a.handleRemoveDMSuppression)
+	// Daftar supresi global (nomor + grup), ditegakkan sender untuk kiriman grup, DM dan mention;
+	// balasan STOP otomatis masuk daftar ini
+	a.Router.Get("/api/suppressions", a.handleListSuppressions)
+	a.Router.Post("/api/suppressions", a.handleAddSuppressions)
+	a.Router.Delete("/api/suppressions/{target}", a.handleRemoveSuppression)
+	// Audiens: daftar kontak (manual, CSV, anggota grup) untuk preflight campaign DM (audience_ids)
+	a.Router.Get("/api/audiences", a.handleListAudiences)
+	a.Router.Post("/api/audiences", a.handleCreateAudience)
+	a.Router.Get("/api/audiences/{id}", a.handleGetAudience)
+	a.Router.Put("/api/audiences/{id}", a.handleUpdateAudience)
+	a.Router.Delete("/api/audiences/{id}", a.handleDeleteAudience)
+	a.Router.Get("/api/audiences/{id}/members", a.handleListAudienceMembers)
+	a.Router.Post("/api/audiences/{id}/members", a.handleAddAudienceMembers)
+	a.Router.Post("/api/audiences/{id}/members/csv", a.handleImportAudienceCSV)
+	a.Router.Post("/api/audiences/{id}/members/groups", a.handleImportAudienceGroups)
+	a.Router.Delete("/api/audiences/{id}/members/{number}", a.handleRemoveAudienceMember)
+	// Segmen: audiens yang anggotanya dihitung dari cache anggota grup (refresh manual & berkala)
+	a.Router.Post("/api/segments/preview", a.handlePreviewSegment)
+	a.Router.Put("/api/audiences/{id}/segment", a.handleSetAudienceSegment)
+	a.Router.Delete("/api/audiences/{id}/segment", a.handleClearAudienceSegment)
+	a.Router.Post("/api/audiences/{id}/refresh", a.handleRefreshAudience)
+	a.Router.Get("/api/audiences/{id}/export.csv", a.handleExportAudienceCSV)
+	// Alur opt-in "balas KATA" per campaign (DM follow-up) dan opt-in yang tertangkap
+	a.Router.Get("/api/reply-flows", a.handleListReplyFlows)
+	a.Router.Get("/api/campaigns/{id}/reply-flow", a.handleGetReplyFlow)
+	adm.Put("/api/campaigns/{id}/reply-flow", a.handlePutReplyFlow)
+	adm.Delete("/api/campaigns/{id}/reply-flow", a.handleDeleteReplyFlow)
+	a.Router.Get("/api/campaigns/{id}/opt-ins", a.handleListOptIns)
+	// Jadwal kirim per campaign/akun (jam WIB, hari, batch, jeda, limit harian)
+	a.Router.Get("/api/schedules", a.handleListSchedules)
+	adm.Post("/api/schedules", a.handleCreateSchedule)
+	a.Router.Get("/api/schedules/{id}", a.handleGetSchedule)
+	adm.Put("/api/schedules/{id}", a.handleUpdateSchedule)
+	adm.Delete("/api/schedules/{id}", a.handleDeleteSchedule)
+	// Jadwal berulang ala cron (hari + jam WIB -> template/campaign ke grup bertag), next_run_at terhitung
+	a.Router.Get("/api/recurring", a.handleListRecurring)
+	adm.Post("/api/recurring", a.handleCreateRecurring)
+	a.Router.Get("/api/recurring/{id}", a.handleGetRecurring)
+	adm.Put("/api/recurring/{id}", a.handleUpdateRecurring)
+	adm.Delete("/api/recurring/{id}", a.handleDeleteRecurring)
+	// Hari libur (YYYY-MM-DD WIB): semua broadcast otomatis dilewati pada tanggal ini
+	a.Router.Get("/api/holidays", a.handleListHolidays)
+	adm.Post("/api/holidays", a.handleSetHoliday)
+	adm.Delete("/api/holidays/{date}", a.handleDeleteHoliday)
+	a.Router.Post("/api/tools/og-draft", a.handleOGDraft)
 
 	// Pairing & connect endpoints
 	a.Router.Get("/api/accounts/{id}/pair/qr", a.handleAccountPairQR)
 	a.Router.Post("/api/accounts/{id}/pair/number", a.handleAccountPairByNumber)
 	a.Router.Post("/api/accounts/{id}/connect", a.handleAccountConnect)
+	// Link pairing swalayan: URL berbatas waktu yang hanya membuka QR pairing satu akun
+	adm.Post("/api/accounts/{id}/pairing-links", a.handleCreatePairingLink)
+	adm.Get("/api/accounts/{id}/pairing-links", a.handleListPairingLinks)
+	adm.Delete("/api/accounts/{id}/pairing-links/{linkID}", a.handleRevokePairingLink)
+	a.Router.Get("/pair/{token}", a.handlePairingPage)
+	a.Router.Get("/pair/{token}/qr", a.handlePairingLinkQR)
+	a.Router.Get("/pair/{token}/status", a.handlePairingLinkStatus)
 
 	// Account logout
 	a.Router.Post("/api/accounts/{id}/logout", a.handleAccountLogout)
 
 	// Refresh groups from WhatsApp
 	a.Router.Post("/api/accounts/{id}/groups/refresh", a.handleRefreshGroups)
+	// Buat grup baru dari akun (subject + peserta awal), opsional langsung aktif untuk broadcast
+	adm.Post("/api/accounts/{id}/groups", a.handleCreateGroup)
 
 	// Bulk enable groups for an account (ops helper)
 	a.Router.Post("/api/accounts/{id}/groups/enable_all", a.handleEnableAllGroups)
 	// Ops: reset risiko + cooldown untuk percepat eligibility troubleshooting
-	a.Router.Post("/api/accounts/{id}/groups/reset_risk_cooldown", a.handleResetRiskCooldown)
+	adm.Post("/api/accounts/{id}/groups/reset_risk_cooldown", a.handleResetRiskCooldown)
+	// Error budget per akun (jeda otomatis saat rasio gagal melewati budget)
+	a.Router.Get("/api/error-budget", a.handleGetErrorBudget)
+	adm.Put("/api/error-budget", a.handleSetErrorBudget)
+	adm.Post("/api/accounts/{id}/error-budget/resume", a.handleResumeErrorBudget)
+	// Throttle WhatsApp (rate-limit/spam): akun dihentikan otomatis, bisa dilepas manual
+	adm.Post("/api/accounts/{id}/throttle/clear", a.handleClearThrottle)
+	// SLO: persentil latensi kirim, keterlambatan jadwal dan uptime akun
+	a.Router.Get("/api/slo", a.handleSLO)
+	// Cold-start ramp: sebar kiriman pertama grup baru per hari per akun
+	a.Router.Get("/api/cold-start", a.handleGetColdStart)
+	adm.Put("/api/cold-start", a.handleSetColdStart)
+	adm.Post("/api/cold-start/plan", a.handlePlanColdStart)
+	// Masa uji grup baru: N kiriman pertama dinilai, grup lolos atau dinonaktifkan otomatis
+	a.Router.Get("/api/settings/group-trial", a.handleGetGroupTrialSettings)
+	adm.Put("/api/settings/group-trial", a.handleSetGroupTrialSettings)
+	a.Router.Get("/api/group-trials", a.handleListGroupTrials)
+	// Profil WhatsApp Business per akun (sync dari WA + versi terkelola terpusat)
+	a.Router.Get("/api/business-profiles", a.handleListBusinessProfiles)
+	a.Router.Get("/api/accounts/{id}/business-profile", a.handleGetBusinessProfile)
+	adm.Put("/api/accounts/{id}/business-profile", a.handleSetBusinessProfile)
+	adm.Post("/api/accounts/{id}/business-profile/sync", a.handleSyncBusinessProfile)
 
 	// Group participants & CSV export
 	a.Router.Get("/api/accounts/{id}/groups/{gid}/participants", a.handleGroupParticipants)
 	a.Router.Get("/api/accounts/{id}/groups/{gid}/participants.csv", a.handleGroupParticipantsCSV)
 	a.Router.Post("/api/accounts/{id}/groups/{gid}/participants/refresh", a.handleRefreshParticipants)
+	// Keluar dari grup (mis. grup mati) dan tandai terhapus di DB
+	adm.Post("/api/accounts/{id}/groups/{gid}/leave", a.handleLeaveGroup)
+	// Kelola nama, deskripsi dan foto grup lewat akun (akun harus admin grup)
+	adm.Put("/api/accounts/{id}/groups/{gid}/subject", a.handleSetGroupSubject)
+	adm.Put("/api/accounts/{id}/groups/{gid}/description", a.handleSetGroupDescription)
+	adm.Put("/api/accounts/{id}/groups/{gid}/photo", a.handleSetGroupPhoto)
+	// Permintaan bergabung grup dengan persetujuan admin: daftar pending, approve/reject massal
+	a.Router.Get("/api/accounts/{id}/groups/{gid}/join-requests", a.handleListJoinRequests)
+	adm.Post("/api/accounts/{id}/groups/{gid}/join-requests/approve", a.handleApproveJoinRequests)
+	adm.Post("/api/accounts/{id}/groups/{gid}/join-requests/reject", a.handleRejectJoinRequests)
+	// Link undangan grup milik sendiri: ambil/reset link, daftar hasil cek dan cek manual
+	adm.Post("/api/accounts/{id}/groups/{gid}/invite-link", a.handleRefreshGroupLink)
+	a.Router.Get("/api/group-links", a.handleListGroupLinks)
+	a.Router.Post("/api/group-links/check", a.handleCheckGroupLinks)
+	// Auto-leave grup bernilai rendah (gagal beruntun, anggota sedikit, lama tanpa kiriman)
+	a.Router.Get("/api/settings/auto-leave", a.handleGetAutoLeavePolicy)
+	adm.Put("/api/settings/auto-leave", a.handleSetAutoLeavePolicy)
+	a.Router.Get("/api/auto-leave/candidates", a.handleAutoLeaveCandidates)
+	a.Router.Get("/api/auto-leave/logs", a.handleListAutoLeaveLogs)
+	adm.Post("/api/auto-leave/run", a.handleRunAutoLeave)
 
 	// Send test (manual trigger) endpoint
 	a.Router.Post("/api/send/test", a.handleSendTest)
+	// Kiriman sekali jalan pada waktu tertentu (lewat outbox; batalkan via /api/queue/{id}/cancel)
+	a.Router.Post("/api/send/schedule", a.handleScheduleSend)
+	// Edit pesan teks terkirim (dalam batas waktu edit WhatsApp)
+	adm.Post("/api/messages/edit", a.handleEditMessages)
+	// Hapus pesan terkirim untuk semua orang (per log, campaign, atau sesi kirim)
+	adm.Post("/api/messages/revoke", a.handleRevokeMessages)
 
 	// Force one-off scheduler send (ignore safe window) for diagnostics
 	a.Router.Post("/api/scheduler/trigger", a.handleSchedulerTrigger)
+	// Jeda darurat scheduler: pause boleh oleh siapa pun yang login, resume hanya admin
+	a.Router.Get("/api/scheduler/status", a.handleSchedulerStatus)
+	a.Router.Post("/api/scheduler/pause", a.handleSchedulerPause)
+	adm.Post("/api/scheduler/resume", a.handleSchedulerResume)
+	// Dry-run: kiriman grup hanya dicatat sebagai would_send
+	a.Router.Get("/api/scheduler/dry-run", a.handleGetSchedulerDryRun)
+	adm.Put("/api/scheduler/dry-run", a.handleSetSchedulerDryRun)
 
 	// Auto-join management
 	a.Router.Get("/api/accounts/{id}/autojoin/settings", a.handleGetAutoJoinSettings)
 	a.Router.Put("/api/accounts/{id}/autojoin/settings", a.handleUpdateAutoJoinSettings)
 	a.Router.Post("/api/accounts/{id}/autojoin/enable", a.handleToggleAutoJoin)
 	a.Router.Get("/api/accounts/{id}/autojoin/logs", a.handleGetAutoJoinLogs)
+	// Intro pasca-join: template perkenalan terjadwal setelah auto-join
+	a.Router.Get("/api/accounts/{id}/autojoin/intros", a.handleListAutoJoinIntros)
+	// Requeue manual percobaan join yang gagal (join sementara-gagal dicoba ulang otomatis dengan backoff)
+	a.Router.Post("/api/autojoin/logs/{logID}/retry", a.handleRetryAutoJoin)
 	a.Router.Post("/api/autojoin/manual", a.handleManualJoin)
-
-	// Log streaming (SSE)
+	// Antrean persetujuan auto-join (mode require_approval): daftar, approve/reject massal
+	a.Router.Get("/api/autojoin/queue", a.handleListAutoJoinQueue)
+	a.Router.Post("/api/autojoin/queue/approve", a.handleApproveAutoJoinQueue)
+	a.Router.Post("/api/autojoin/queue/reject", a.handleRejectAutoJoinQueue)
+	// Impor massal link undangan (JSON/CSV) ke antrean, dibagi ke beberapa akun dengan jeda
+	a.Router.Post("/api/autojoin/import", a.handleImportAutoJoins)
+
+	// Feed watcher (RSS/Atom/JSON -> template)
+	a.Router.Get("/api/feeds", a.handleListFeeds)
+	a.Router.Post("/api/feeds", a.handleCreateFeed)
+	a.Router.Put("/api/feeds/{id}", a.handleUpdateFeed)
+	a.Router.Delete("/api/feeds/{id}", a.handleDeleteFeed)
+	a.Router.Post("/api/feeds/{id}/poll", a.handlePollFeed)
+	a.Router.Get("/api/feeds/{id}/items", a.handleListFeedItems)
+	a.Router.Post("/api/feeds/items/{itemID}/approve", a.handleApproveFeedItem)
+	a.Router.Post("/api/feeds/items/{itemID}/reject", a.handleRejectFeedItem)
+
+	// Export/import konfigurasi workspace (DR & kloning environment)
+	adm.Get("/api/export/workspace", a.handleExportWorkspace)
+	adm.Post("/api/import/workspace", a.handleImportWorkspace)
+	// Konfigurasi deklaratif antar instance: export spec, lalu apply (rekonsiliasi) di instance lain
+	adm.Get("/api/config/export", a.handleExportConfig)
+	adm.Post("/api/config/apply", a.handleApplyConfig)
+
+	// Pool akun: rotasi pengirim untuk grup yang diikuti beberapa akun
+	a.Router.Get("/api/pools", a.handleListPools)
+	adm.Post("/api/pools", a.handleCreatePool)
+	adm.Put("/api/pools/{id}", a.handleUpdatePool)
+	adm.Delete("/api/pools/{id}", a.handleDeletePool)
+	adm.Put("/api/accounts/{id}/pool", a.handleSetAccountPool)
+
+	// Compliance: aturan deskripsi grup ("dilarang promo") dan grup yang ditandai
+	a.Router.Get("/api/compliance/rules", a.handleListComplianceRules)
+	a.Router.Post("/api/compliance/rules", a.handleUpsertComplianceRule)
+	a.Router.Delete("/api/compliance/rules/{id}", a.handleDeleteComplianceRule)
+	a.Router.Get("/api/compliance/flags", a.handleListComplianceFlags)
+	a.Router.Post("/api/compliance/scan", a.handleComplianceScan)
+
+	// Digest harian per akun
+	a.Router.Get("/api/digests", a.handleListDigests)
+	a.Router.Post("/api/digests/run", a.handleRunDigest)
+
+	// Cron housekeeping: status dan trigger manual per task
+	a.Router.Get("/api/cron", a.handleCronStatus)
+	adm.Post("/api/cron/{name}/run", a.handleCronRun)
+	// Cek integritas data dan perbaikan terpandu (sama dengan `promote doctor`)
+	adm.Get("/api/doctor", a.handleDoctorCheck)
+	adm.Post("/api/doctor/repair", a.handleDoctorRepair)
+
+	// Antrean outbox: inspeksi & manipulasi item pending
+	a.Router.Get("/api/queue", a.handleListQueue)
+	a.Router.Post("/api/queue/purge", a.handleQueuePurge)
+	a.Router.Post("/api/queue/{id}/priority", a.handleQueuePriority)
+	a.Router.Post("/api/queue/{id}/postpone", a.handleQueuePostpone)
+	a.Router.Post("/api/queue/{id}/cancel", a.handleQueueCancel)
+
+	// Short link ter-tracking (domain kustom via SHORTLINK_BASE_URL)
+	a.Router.Get("/api/shortlinks", a.handleListShortLinks)
+	a.Router.Post("/api/shortlinks", a.handleCreateShortLink)
+	a.Router.Delete("/api/shortlinks/{slug}", a.handleDeleteShortLink)
+	a.Router.Get("/r/{slug}", a.handleShortLinkRedirect)
+
+	// Log streaming (SSE); anotasi operator ikut dikirim sebagai event "annotation"
 	a.Router.Get("/api/logs/stream", a.handleLogsStream)
+	// Anotasi operator (catatan shift/insiden) dan timeline log + anotasi
+	a.Router.Get("/api/annotations", a.handleListAnnotations)
+	a.Router.Post("/api/annotations", a.handleCreateAnnotation)
+	adm.Delete("/api/annotations/{id}", a.handleDeleteAnnotation)
+	a.Router.Get("/api/timeline", a.handleTimeline)
 
 	// Uploads (multipart) endpoint and static serving
 	a.Router.Post("/api/upload", a.handleUpload)
-	a.Router.Handle("/uploads/*", http.StripPrefix("/uploads/", http.FileServer(http.Dir("uploads"))))
+	a.Router.Handle("/uploads/*", http.StripPrefix("/uploads/", http.FileServer(http.Dir(a.uploadDir()))))
 
 	// Favicon to avoid 404 noise
 	a.Router.Get("/favicon.ico", a.handleFavicon)
@@ -131,7 +512,7 @@ func cors(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Access-Control-Allow-Origin", "*")
 		w.Header().Set("Access-Control-Allow-Methods", "GET,POST,PUT,PATCH,DELETE,OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-API-Key")
 		if r.Method == http.MethodOptions {
 			w.WriteHeader(http.StatusOK)
 			return
@@ -160,6 +541,7 @@ func (a *API) handleCreateAccount(w http.ResponseWriter, r *http.Request) {
 		writeErr(w, http.StatusBadRequest, "invalid JSON")
 		return
 	}
+	req.Label = strings.TrimSpace(req.Label)
 	if req.Label == "" {
 		writeErr(w, http.StatusBadRequest, "label required")
 		return
@@ -169,6 +551,10 @@ func (a *API) handleCreateAccount(w http.ResponseWriter, r *http.Request) {
 		enabled = *req.Enabled
 	}
 	id, err := a.Store.CreateAccount(req.Label, req.Msisdn, enabled, req.DailyLimit)
+	if errors.Is(err, storage.ErrAccountLabelTaken) {
+		writeErr(w, http.StatusConflict, err.Error())
+		return
+	}
 	if err != nil {
 		writeErr(w, http.StatusInternalServerError, err.Error())
 		return
@@ -185,6 +571,23 @@ func (a *API) handleListAccounts(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusOK, list)
 }
 
+// Batch status semua akun (koneksi, kuota hari ini, kirim terakhir) dalam satu response.
+// Tidak memicu connect/refresh sehingga aman dipanggil saat dashboard load.
+func (a *API) handleAccountsStatus(w http.ResponseWriter, r *http.Request) {
+	list, err := a.Store.ListAccountStatuses()
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	for i := range list {
+		list[i].Paired, list[i].Connected = a.Manager.ConnectionState(list[i].ID)
+	}
+	if list == nil {
+		list = []model.AccountStatus{}
+	}
+	writeJSON(w, http.StatusOK, list)
+}
+
 // Update & Delete Account
 type updateAccountReq struct {
 	Label      string `json:"label"`
@@ -209,11 +612,21 @@ func (a *API) handleUpdateAccount(w http.ResponseWriter, r *http.Request) {
 		writeErr(w, http.StatusBadRequest, "invalid JSON")
 		return
 	}
+	req.Label = strings.TrimSpace(req.Label)
+	if req.Label == "" {
+		writeErr(w, http.StatusBadRequest, "label required")
+		return
+	}
 	enabled := true
 	if req.Enabled != nil {
 		enabled = *req.Enabled
 	}
-	if err := a.Store.UpdateAccount(id, req.Label, req.Msisdn, enabled, req.DailyLimit); err != nil {
+	err = a.Store.UpdateAccount(id, req.Label, req.Msisdn, enabled, req.DailyLimit)
+	if errors.Is(err, storage.ErrAccountLabelTaken) {
+		writeErr(w, http.StatusConflict, err.Error())
+		return
+	}
+	if err != nil {
 		writeErr(w, http.StatusInternalServerError, err.Error())
 		return
 	}
@@ -291,10 +704,13 @@ func (a *API) handleStats(w http.ResponseWriter, r *http.Request) {
 		writeErr(w, http.StatusInternalServerError, err.Error())
 		return
 	}
+	// Soft bounce hari ini dipisah dari success agar statistik tidak terlalu optimis
+	softBounced, _ := a.Store.CountSoftBouncesSince(a.Store.Day.Start(time.Now()))
 	writeJSON(w, http.StatusOK, map[string]int64{
-		"total":   total,
-		"success": success,
-		"failed":  failed,
+		"total":        total,
+		"success":      success,
+		"failed":       failed,
+		"soft_bounced": softBounced,
 	})
 }
 
@@ -303,32 +719,28 @@ func (a *API) handleStats(w http.ResponseWriter, r *http.Request) {
 // and per-account: enabled, status, daily_limit, sent_today, eligible_groups.
 func (a *API) handleDiag(w http.ResponseWriter, r *http.Request) {
 	// Determine WIB location with fallback if tzdata missing
-	loc, err := time.LoadLocation("Asia/Jakarta")
-	if err != nil || loc == nil {
-		loc = time.FixedZone("WIB", 7*3600)
-	}
+	loc := wibLocation()
 	now := time.Now().In(loc)
-	windows := [][2]int{{45, 150}, {180, 330}, {1290, 1410}} // 00:45–02:30, 03:00–05:30, 21:30–23:30
-	m := now.Hour()*60 + now.Minute()
-	inWindow := false
-	for _, w2 := range windows {
-		if m >= w2[0] && m <= w2[1] {
-			inWindow = true
-			break
-		}
+	// Jendela hari ini dari setting send_windows (per hari)
+	weekWindows, err := a.Store.SendWindows()
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
 	}
+	windows := weekWindows.For(now)
+	inWindow := weekWindows.Active(now)
 
 	// Count active templates
-	var templatesActive int64
-	_ = a.Store.DB.QueryRow(`SELECT COUNT(*) FROM templates WHERE enabled=1`).Scan(&templatesActive)
+	templatesActive, _ := a.Store.CountActiveTemplates()
+
+	cooldownHr, riskThreshold := storage.EligibilityFromEnv()
 
 	// Accounts diagnostics (enabled accounts only)
-	rows, err := a.Store.DB.Query(`SELECT id,label,enabled,daily_limit,status FROM accounts WHERE enabled=1 ORDER BY created_at DESC`)
+	list, err := a.Store.ListAccounts()
 	if err != nil {
 		writeErr(w, http.StatusInternalServerError, err.Error())
 		return
 	}
-	defer rows.Close()
 
 	type accDiag struct {
 		ID             string `json:"id"`
@@ -341,41 +753,24 @@ func (a *API) handleDiag(w http.ResponseWriter, r *http.Request) {
 	}
 	var accounts []accDiag
 
-	for rows.Next() {
-		var (
-			id, label, status string
-			enabledInt        int
-			dailyLimit        int
-		)
-		if err := rows.Scan(&id, &label, &enabledInt, &dailyLimit, &status); err != nil {
+	for _, acc := range list {
+		if !acc.Enabled {
 			continue
 		}
 
 		// Sent today
-		var sentToday int64
-		_ = a.Store.DB.QueryRow(`
-			SELECT COALESCE(SUM(CASE WHEN status='sent' THEN 1 ELSE 0 END), 0)
-			FROM logs
-			WHERE account_id=? AND ts >= datetime('now','start of day') AND ts < datetime('now','start of day','+1 day')`,
-			id,
-		).Scan(&sentToday)
-
-		// Eligible groups (cooldown 48h, risk < 3, enabled)
-		var eligible int64
-		_ = a.Store.DB.QueryRow(`
-			SELECT COUNT(*)
-			FROM groups
-			WHERE account_id=? AND enabled=1 AND (last_sent_at IS NULL OR last_sent_at < datetime('now','-48 hours')) AND risk_score < 3`,
-			id,
-		).Scan(&eligible)
+		sentToday, _ := a.Store.CountAccountSentToday(acc.ID)
+
+		// Eligible groups: filter yang sama dengan scheduler (cooldown, risk, supresi, batas grup)
+		eligible, _ := a.Store.CountEligibleGroups(acc.ID, cooldownHr, riskThreshold)
 
 		accounts = append(accounts, accDiag{
-			ID:             id,
-			Label:          label,
-			Enabled:        enabledInt == 1,
-			Status:         status,
-			DailyLimit:     dailyLimit,
-			SentToday:      sentToday,
+			ID:             acc.ID,
+			Label:          acc.Label,
+			Enabled:        acc.Enabled,
+			Status:         acc.Status,
+			DailyLimit:     acc.DailyLimit,
+			SentToday:      int64(sentToday),
 			EligibleGroups: eligible,
 		})
 	}
@@ -410,19 +805,28 @@ func (a *API) handleAccountPairQR(w http.ResponseWriter, r *http.Request) {
 	}
 	ctx, cancel := context.WithTimeout(r.Context(), 90*time.Second)
 	defer cancel()
-	png, _, err := a.Manager.StartPairing(ctx, id)
+	qr, err := a.Manager.PairingQR(ctx, id)
 	if err != nil {
-		writeErr(w, http.StatusBadRequest, err.Error())
+		writeWAErr(w, err)
 		return
 	}
 	_ = a.Store.UpdateAccountStatus(id, model.StatusPairing, "", nil)
-	w.Header().Set("Content-Type", "image/png")
 	// Hindari caching QR kadaluarsa oleh browser/proxy
 	w.Header().Set("Cache-Control", "no-store, no-cache, must-revalidate, max-age=0")
 	w.Header().Set("Pragma", "no-cache")
 	w.Header().Set("Expires", "0")
+	// ?format=json untuk klien API/mobile: kode mentah + PNG base64 + waktu kedaluwarsa
+	if r.URL.Query().Get("format") == "json" {
+		writeJSON(w, http.StatusOK, map[string]any{
+			"code":       qr.Code,
+			"png_base64": base64.StdEncoding.EncodeToString(qr.PNG),
+			"expires_at": qr.ExpiresAt.UTC(),
+		})
+		return
+	}
+	w.Header().Set("Content-Type", "image/png")
 	w.WriteHeader(http.StatusOK)
-	_, _ = w.Write(png)
+	_, _ = w.Write(qr.PNG)
 }
 
 // Pair via phone number (if supported by whatsmeow)
@@ -455,10 +859,13 @@ func (a *API) handleAccountPairByNumber(w http.ResponseWriter, r *http.Request)
 	code, err := a.Manager.RequestPairingCode(ctx, id, req.Msisdn)
 	if err != nil {
 		if errors.Is(err, wa.ErrPairingByNumberUnsupported) {
-			writeErr(w, http.StatusNotImplemented, "Pairing via nomor tidak didukung oleh whatsmeow saat ini. Gunakan QR.")
+			writeJSON(w, http.StatusNotImplemented, map[string]any{
+				"error": "Pairing via nomor tidak didukung oleh whatsmeow saat ini. Gunakan QR.",
+				"code":  wa.ErrorCode(err),
+			})
 			return
 		}
-		writeErr(w, http.StatusBadRequest, err.Error())
+		writeWAErr(w, err)
 		return
 	}
 	if code == "" {
@@ -481,7 +888,7 @@ func (a *API) handleAccountConnect(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	if err := a.Manager.ConnectIfPaired(id); err != nil {
-		writeErr(w, http.StatusBadRequest, err.Error())
+		writeWAErr(w, err)
 		return
 	}
 	_ = a.Store.UpdateAccountStatus(id, model.StatusOnline, "", nil)
@@ -512,7 +919,7 @@ func (a *API) handleRefreshGroups(w http.ResponseWriter, r *http.Request) {
 	}
 	n, err := a.Manager.FetchAndSyncGroups(r.Context(), id)
 	if err != nil {
-		writeErr(w, http.StatusBadRequest, err.Error())
+		writeWAErr(w, err)
 		return
 	}
 	writeJSON(w, http.StatusOK, map[string]any{"refreshed": n})
@@ -535,7 +942,7 @@ func (a *API) handleGroupParticipants(w http.ResponseWriter, r *http.Request) {
 	defer cancel()
 	parts, err := a.Manager.GetGroupParticipants(ctx, id, gid)
 	if err != nil {
-		writeErr(w, http.StatusBadRequest, err.Error())
+		writeWAErr(w, err)
 		return
 	}
 	writeJSON(w, http.StatusOK, parts)
@@ -558,7 +965,7 @@ func (a *API) handleGroupParticipantsCSV(w http.ResponseWriter, r *http.Request)
 	defer cancel()
 	parts, err := a.Manager.GetGroupParticipants(ctx, id, gid)
 	if err != nil {
-		writeErr(w, http.StatusBadRequest, err.Error())
+		writeWAErr(w, err)
 		return
 	}
 	w.Header().Set("Content-Type", "text/csv")
@@ -613,7 +1020,7 @@ func (a *API) handleRefreshParticipants(w http.ResponseWriter, r *http.Request)
 	defer cancel()
 	parts, err := a.Manager.GetGroupParticipants(ctx, id, gid)
 	if err != nil {
-		writeErr(w, http.StatusBadRequest, err.Error())
+		writeWAErr(w, err)
 		return
 	}
 	
@@ -623,19 +1030,91 @@ func (a *API) handleRefreshParticipants(w http.ResponseWriter, r *http.Request)
 	})
 }
 
+// Keluar dari grup di WhatsApp lalu tandai grup ditinggalkan di DB.
+func (a *API) handleLeaveGroup(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	gid := chi.URLParam(r, "gid")
+	exists, err := a.Store.AccountExists(id)
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if !exists {
+		writeErr(w, http.StatusNotFound, "account not found")
+		return
+	}
+	if err := a.Manager.LeaveGroup(r.Context(), id, gid); err != nil {
+		writeWAErr(w, err)
+		return
+	}
+	found, err := a.Store.MarkGroupLeft(id, gid)
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"left": gid, "group_found": found})
+}
+
 // Send test API
 type sendTestReq struct {
-	AccountID     string   `json:"account_id"`
-	GroupID       string   `json:"group_id"`
-	TextOnly      string   `json:"text_only"`
-	ImageURLs     []string `json:"image_urls"`
-	ImageCaption  string   `json:"image_caption"`
-	VideoURLs     []string `json:"video_urls"`
-	VideoCaption  string   `json:"video_caption"`
-	AudioURLs     []string `json:"audio_urls"`
-	StickerURLs   []string `json:"sticker_urls"`
-	DocURLs       []string `json:"doc_urls"`
-	DocCaption    string   `json:"doc_caption"`
+	AccountID        string              `json:"account_id"`
+	GroupID          string              `json:"group_id"`
+	TextOnly         string              `json:"text_only"`
+	ImageURLs        []string            `json:"image_urls"`
+	ImageCaption     string              `json:"image_caption"`
+	VideoURLs        []string            `json:"video_urls"`
+	VideoCaption     string              `json:"video_caption"`
+	Products         []model.ProductItem `json:"products"`
+	GifURLs          []string            `json:"gif_urls"`
+	AudioURLs        []string            `json:"audio_urls"`
+	VoiceURLs        []string            `json:"voice_urls"`
+	StickerURLs      []string            `json:"sticker_urls"`
+	DocURLs          []string            `json:"doc_urls"`
+	DocCaption       string              `json:"doc_caption"`
+	LinkPreview      bool                `json:"link_preview"`
+	ContactName      string              `json:"contact_name"`
+	ContactPhone     string              `json:"contact_phone"`
+	MediaFailPolicy  string              `json:"media_fail_policy"`
+	FallbackImageURL string              `json:"fallback_image_url"`
+	MentionAll       bool                `json:"mention_all"`
+}
+
+// validate memeriksa konten kiriman; pesan error kosong jika valid.
+func (req sendTestReq) validate() string {
+	if !validContactPhone(req.ContactPhone) {
+		return "contact_phone must be a phone number"
+	}
+	if msg := checkMediaFailPolicy(req.MediaFailPolicy, req.FallbackImageURL); msg != "" {
+		return msg
+	}
+	if req.MentionAll && strings.TrimSpace(req.TextOnly) == "" {
+		return "mention_all requires text_only"
+	}
+	return checkProducts(req.Products)
+}
+
+// content konten kiriman dari body request.
+func (req sendTestReq) content() sender.MessageContent {
+	return sender.MessageContent{
+		TextOnly:         req.TextOnly,
+		ImageURLs:        req.ImageURLs,
+		ImageCaption:     req.ImageCaption,
+		VideoURLs:        req.VideoURLs,
+		VideoCaption:     req.VideoCaption,
+		Products:         req.Products,
+		GifURLs:          req.GifURLs,
+		AudioURLs:        req.AudioURLs,
+		VoiceURLs:        req.VoiceURLs,
+		StickerURLs:      req.StickerURLs,
+		DocURLs:          req.DocURLs,
+		DocCaption:       req.DocCaption,
+		LinkPreview:      req.LinkPreview,
+		ContactName:      strings.TrimSpace(req.ContactName),
+		ContactPhone:     strings.TrimSpace(req.ContactPhone),
+		MediaFailPolicy:  req.MediaFailPolicy,
+		FallbackImageURL: strings.TrimSpace(req.FallbackImageURL),
+		MentionAll:       req.MentionAll,
+	}
 }
 
 func (a *API) handleSendTest(w http.ResponseWriter, r *http.Request) {
@@ -648,24 +1127,36 @@ func (a *API) handleSendTest(w http.ResponseWriter, r *http.Request) {
 		writeErr(w, http.StatusBadRequest, "account_id and group_id required")
 		return
 	}
+	if msg := req.validate(); msg != "" {
+		writeErr(w, http.StatusBadRequest, msg)
+		return
+	}
+	// account_id boleh label:/msisdn:, group_id boleh JID, link undangan, shorthand atau nama grup
+	accountID, err := a.Store.ResolveAccountRef(req.AccountID)
+	if errors.Is(err, storage.ErrAccountNotFound) {
+		writeErr(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if errors.Is(err, storage.ErrAccountAmbiguous) {
+		writeErr(w, http.StatusConflict, err.Error())
+		return
+	}
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
 	ctx, cancel := context.WithTimeout(r.Context(), 60*time.Second)
 	defer cancel()
-	content := sender.MessageContent{
-		TextOnly:      req.TextOnly,
-		ImageURLs:     req.ImageURLs,
-		ImageCaption:  req.ImageCaption,
-		VideoURLs:     req.VideoURLs,
-		VideoCaption:  req.VideoCaption,
-		AudioURLs:     req.AudioURLs,
-		StickerURLs:   req.StickerURLs,
-		DocURLs:       req.DocURLs,
-		DocCaption:    req.DocCaption,
-	}
-	if err := a.Sender.SendToGroup(ctx, req.AccountID, req.GroupID, content); err != nil {
+	groupID, err := a.resolveGroupRef(ctx, accountID, req.GroupID)
+	if err != nil {
+		writeGroupRefErr(w, err)
+		return
+	}
+	if err := a.Sender.SendToGroup(ctx, accountID, groupID, req.content()); err != nil {
 		writeErr(w, http.StatusBadRequest, err.Error())
 		return
 	}
-	writeJSON(w, http.StatusOK, map[string]any{"status": "sent"})
+	writeJSON(w, http.StatusOK, map[string]any{"status": "sent", "group_id": groupID})
 }
 
 func (a *API) handleLogsStream(w http.ResponseWriter, r *http.Request) {
@@ -678,7 +1169,7 @@ func (a *API) handleLogsStream(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	lastID := int64(0)
+	lastID, lastNoteID := int64(0), int64(0)
 	ticker := time.NewTicker(2 * time.Second)
 	defer ticker.Stop()
 
@@ -686,55 +1177,27 @@ func (a *API) handleLogsStream(w http.ResponseWriter, r *http.Request) {
 	_, _ = w.Write([]byte(":ok\n\n"))
 	flusher.Flush()
 
-	// Send last 50 logs on initial connect
-	initialRows, err := a.Store.DB.Query(`SELECT id, ts, account_id, group_id, COALESCE(campaign_id,''), COALESCE(campaign_session_id,''), status, COALESCE(error,''), message_preview, attempt, scheduled_for
-		FROM logs ORDER BY id DESC LIMIT 50`)
-	if err == nil {
-		var initialLogs []map[string]any
-		for initialRows.Next() {
-			var (
-				id                                                          int64
-				ts                                                          time.Time
-				accountID, groupID, campaignID, sessionID, status, errMsg string
-				preview                                                     string
-				attempt                                                     int
-				scheduled                                                   sql.NullTime
-			)
-			if err := initialRows.Scan(&id, &ts, &accountID, &groupID, &campaignID, &sessionID, &status, &errMsg, &preview, &attempt, &scheduled); err != nil {
-				continue
+	// Send last 50 logs on initial connect, with recent operator annotations interleaved by time
+	if initial, err := a.Store.RecentLogs(50); err == nil {
+		notes, _ := a.Store.ListAnnotations(model.TimelineFilter{Limit: 20})
+		for _, n := range notes {
+			if n.ID > lastNoteID {
+				lastNoteID = n.ID
 			}
-			if id > lastID {
-				lastID = id
-			}
-			initialLogs = append(initialLogs, map[string]any{
-				"id":                    id,
-				"ts":                    ts.Format(time.RFC3339),
-				"account_id":            accountID,
-				"group_id":              groupID,
-				"campaign_id":           campaignID,
-				"campaign_session_id":   sessionID,
-				"status":                status,
-				"error":                 errMsg,
-				"message_preview":       preview,
-				"attempt":               attempt,
-				"scheduled_for": func() string {
-					if scheduled.Valid {
-						return scheduled.Time.Format(time.RFC3339)
-					}
-					return ""
-				}(),
-			})
 		}
-		initialRows.Close()
 		// Send in reverse order (oldest first)
-		for i := len(initialLogs) - 1; i >= 0; i-- {
-			b, err := json.Marshal(initialLogs[i])
-			if err != nil {
-				continue
+		j := len(notes) - 1
+		for i := len(initial) - 1; i >= 0; i-- {
+			for ; j >= 0 && !notes[j].At.After(initial[i].TS); j-- {
+				writeAnnotationEvent(w, notes[j])
+			}
+			if initial[i].ID > lastID {
+				lastID = initial[i].ID
 			}
-			_, _ = w.Write([]byte("data: "))
-			_, _ = w.Write(b)
-			_, _ = w.Write([]byte("\n\n"))
+			writeLogEvent(w, initial[i])
+		}
+		for ; j >= 0; j-- {
+			writeAnnotationEvent(w, notes[j])
 		}
 		flusher.Flush()
 	}
@@ -744,120 +1207,189 @@ func (a *API) handleLogsStream(w http.ResponseWriter, r *http.Request) {
 		case <-r.Context().Done():
 			return
 		case <-ticker.C:
-			rows, err := a.Store.DB.Query(`SELECT id, ts, account_id, group_id, COALESCE(campaign_id,''), COALESCE(campaign_session_id,''), status, COALESCE(error,''), message_preview, attempt, scheduled_for
-				FROM logs WHERE id > ? ORDER BY id ASC LIMIT 100`, lastID)
+			list, err := a.Store.LogsAfter(lastID, 100)
 			if err != nil {
 				// keep trying
 				continue
 			}
-			for rows.Next() {
-				var (
-					id                                                          int64
-					ts                                                          time.Time
-					accountID, groupID, campaignID, sessionID, status, errMsg string
-					preview                                                     string
-					attempt                                                     int
-					scheduled                                                   sql.NullTime
-				)
-				if err := rows.Scan(&id, &ts, &accountID, &groupID, &campaignID, &sessionID, &status, &errMsg, &preview, &attempt, &scheduled); err != nil {
-					continue
-				}
-				if id > lastID {
-					lastID = id
-				}
-				payload := map[string]any{
-					"id":                    id,
-					"ts":                    ts.Format(time.RFC3339),
-					"account_id":            accountID,
-					"group_id":              groupID,
-					"campaign_id":           campaignID,
-					"campaign_session_id":   sessionID,
-					"status":                status,
-					"error":                 errMsg,
-					"message_preview":       preview,
-					"attempt":               attempt,
-					"scheduled_for": func() string {
-						if scheduled.Valid {
-							return scheduled.Time.Format(time.RFC3339)
-						}
-						return ""
-					}(),
-				}
-				b, err := json.Marshal(payload)
-				if err != nil {
-					continue
+			for _, e := range list {
+				if e.ID > lastID {
+					lastID = e.ID
 				}
-				_, _ = w.Write([]byte("data: "))
-				_, _ = w.Write(b)
-				_, _ = w.Write([]byte("\n\n"))
+				writeLogEvent(w, e)
+			}
+			notes, err := a.Store.AnnotationsAfter(lastNoteID, 100)
+			if err != nil {
+				notes = nil
+			}
+			for _, n := range notes {
+				lastNoteID = n.ID
+				writeAnnotationEvent(w, n)
+			}
+			if len(list)+len(notes) > 0 {
 				flusher.Flush()
 			}
-			rows.Close()
 		}
 	}
 }
 
+// writeLogEvent menulis satu log kirim sebagai event SSE.
+func writeLogEvent(w http.ResponseWriter, e model.LogEntry) {
+	scheduled := ""
+	if !e.ScheduledFor.IsZero() {
+		scheduled = e.ScheduledFor.Format(time.RFC3339)
+	}
+	b, err := json.Marshal(map[string]any{
+		"id":                  e.ID,
+		"ts":                  e.TS.Format(time.RFC3339),
+		"account_id":          e.AccountID,
+		"group_id":            e.GroupID,
+		"campaign_id":         e.CampaignID,
+		"campaign_session_id": e.SessionID,
+		"status":              e.Status,
+		"error":               e.Error,
+		"message_preview":     e.MessagePrev,
+		"attempt":             e.Attempt,
+		"scheduled_for":       scheduled,
+	})
+	if err != nil {
+		return
+	}
+	_, _ = w.Write([]byte("data: "))
+	_, _ = w.Write(b)
+	_, _ = w.Write([]byte("\n\n"))
+}
+
+// writeAnnotationEvent mengirim anotasi operator sebagai event SSE bernama "annotation" agar
+// klien lama yang hanya mendengar onmessage tidak terganggu.
+func writeAnnotationEvent(w http.ResponseWriter, n model.Annotation) {
+	b, err := json.Marshal(n)
+	if err != nil {
+		return
+	}
+	_, _ = w.Write([]byte("event: annotation\ndata: "))
+	_, _ = w.Write(b)
+	_, _ = w.Write([]byte("\n\n"))
+}
+
 /********** Templates (Global) Management **********/
 
 type upsertTemplateReq struct {
-	Name          string   `json:"name"`
-	TextOnly      string   `json:"text_only"`
-	ImageURLs     []string `json:"image_urls"`
-	ImageCaption  string   `json:"image_caption"`
-	VideoURLs     []string `json:"video_urls"`
-	VideoCaption  string   `json:"video_caption"`
-	AudioURLs     []string `json:"audio_urls"`
-	StickerURLs   []string `json:"sticker_urls"`
-	DocURLs       []string `json:"doc_urls"`
-	DocCaption    string   `json:"doc_caption"`
-	Enabled       bool     `json:"enabled"`
+	Name             string              `json:"name"`
+	TextOnly         string              `json:"text_only"`
+	ImageURLs        []string            `json:"image_urls"`
+	ImageCaption     string              `json:"image_caption"`
+	VideoURLs        []string            `json:"video_urls"`
+	VideoCaption     string              `json:"video_caption"`
+	Products         []model.ProductItem `json:"products"`
+	GifURLs          []string            `json:"gif_urls"`
+	AudioURLs        []string            `json:"audio_urls"`
+	VoiceURLs        []string            `json:"voice_urls"`
+	StickerURLs      []string            `json:"sticker_urls"`
+	DocURLs          []string            `json:"doc_urls"`
+	DocCaption       string              `json:"doc_caption"`
+	LinkPreview      bool                `json:"link_preview"`
+	ContactName      string              `json:"contact_name"`
+	ContactPhone     string              `json:"contact_phone"`
+	MediaFailPolicy  string              `json:"media_fail_policy"`
+	FallbackImageURL string              `json:"fallback_image_url"`
+	MentionAll       bool                `json:"mention_all"`
+	Languages        []string            `json:"languages"`
+	Enabled          bool                `json:"enabled"`
+}
+
+// check memvalidasi isi template; pesan error atau "" jika valid.
+func (req upsertTemplateReq) check() string {
+	if !validContactPhone(req.ContactPhone) {
+		return "contact_phone must be a phone number"
+	}
+	if msg := checkMediaFailPolicy(req.MediaFailPolicy, req.FallbackImageURL); msg != "" {
+		return msg
+	}
+	if req.MentionAll && strings.TrimSpace(req.TextOnly) == "" {
+		return "mention_all requires text_only"
+	}
+	return checkProducts(req.Products)
+}
+
+func (req upsertTemplateReq) template() model.Template {
+	return model.Template{
+		Name:             req.Name,
+		TextOnly:         req.TextOnly,
+		ImageURLs:        req.ImageURLs,
+		ImageCaption:     req.ImageCaption,
+		VideoURLs:        req.VideoURLs,
+		VideoCaption:     req.VideoCaption,
+		Products:         req.Products,
+		GifURLs:          req.GifURLs,
+		AudioURLs:        req.AudioURLs,
+		VoiceURLs:        req.VoiceURLs,
+		StickerURLs:      req.StickerURLs,
+		DocURLs:          req.DocURLs,
+		DocCaption:       req.DocCaption,
+		LinkPreview:      req.LinkPreview,
+		ContactName:      strings.TrimSpace(req.ContactName),
+		ContactPhone:     strings.TrimSpace(req.ContactPhone),
+		MediaFailPolicy:  req.MediaFailPolicy,
+		FallbackImageURL: strings.TrimSpace(req.FallbackImageURL),
+		MentionAll:       req.MentionAll,
+		Languages:        req.Languages,
+		Enabled:          req.Enabled,
+	}
+}
+
+// checkProducts memvalidasi item produk; "" jika valid.
+func checkProducts(list []model.ProductItem) string {
+	for i, p := range list {
+		if err := p.Validate(); err != nil {
+			return fmt.Sprintf("products[%d]: %v", i, err)
+		}
+	}
+	return ""
+}
+
+// validContactPhone true jika kosong (tanpa kartu kontak) atau berisi nomor telepon
+// (minimal 6 digit; spasi, +, - dan kurung diabaikan).
+func validContactPhone(phone string) bool {
+	phone = strings.TrimSpace(phone)
+	if phone == "" {
+		return true
+	}
+	digits := 0
+	for _, r := range phone {
+		switch {
+		case r >= '0' && r <= '9':
+			digits++
+		case strings.ContainsRune("+- ()", r):
+		default:
+			return false
+		}
+	}
+	return digits >= 6
+}
+
+// checkMediaFailPolicy memvalidasi media_fail_policy; "fallback" wajib disertai fallback_image_url http(s).
+func checkMediaFailPolicy(policy, fallbackURL string) string {
+	if !sender.ValidMediaFailPolicy(policy) {
+		return "media_fail_policy must be abort, skip or fallback"
+	}
+	fallbackURL = strings.TrimSpace(fallbackURL)
+	if policy == sender.MediaFailFallback && fallbackURL == "" {
+		return "fallback_image_url required for media_fail_policy=fallback"
+	}
+	if fallbackURL != "" && !strings.HasPrefix(fallbackURL, "http://") && !strings.HasPrefix(fallbackURL, "https://") {
+		return "fallback_image_url must be an http(s) URL"
+	}
+	return ""
 }
 
 func (a *API) handleListTemplates(w http.ResponseWriter, r *http.Request) {
-	rows, err := a.Store.DB.Query(`SELECT 
-		id, name, 
-		COALESCE(text_only,''), 
-		COALESCE(images_json,''), COALESCE(images_caption,''),
-		COALESCE(videos_json,''), COALESCE(videos_caption,''),
-		COALESCE(audio_json,''),
-		COALESCE(stickers_json,''),
-		COALESCE(docs_json,''), COALESCE(docs_caption,''),
-		enabled, created_at, updated_at
-		FROM templates ORDER BY created_at DESC`)
+	list, err := a.Store.ListTemplates()
 	if err != nil {
 		writeErr(w, http.StatusInternalServerError, err.Error())
 		return
 	}
-	defer rows.Close()
-	var out []map[string]any
-	for rows.Next() {
-		var (
-			id, name, textOnly, imgJSON, imgCaption, vidJSON, vidCaption, audJSON, stJSON, docJSON, docCaption string
-			enabledInt                                                                                          int
-			created, updated                                                                                    time.Time
-		)
-		if err := rows.Scan(&id, &name, &textOnly, &imgJSON, &imgCaption, &vidJSON, &vidCaption, &audJSON, &stJSON, &docJSON, &docCaption, &enabledInt, &created, &updated); err != nil {
-			writeErr(w, http.StatusInternalServerError, err.Error())
-			return
-		}
-		out = append(out, map[string]any{
-			"id":            id,
-			"name":          name,
-			"text_only":     textOnly,
-			"image_urls":    parseJSONArray(imgJSON),
-			"image_caption": imgCaption,
-			"video_urls":    parseJSONArray(vidJSON),
-			"video_caption": vidCaption,
-			"audio_urls":    parseJSONArray(audJSON),
-			"sticker_urls":  parseJSONArray(stJSON),
-			"doc_urls":      parseJSONArray(docJSON),
-			"doc_caption":   docCaption,
-			"enabled":       enabledInt == 1,
-			"created_at":    created.Format(time.RFC3339),
-			"updated_at":    updated.Format(time.RFC3339),
-		})
-	}
-	writeJSON(w, http.StatusOK, out)
+	writeJSON(w, http.StatusOK, list)
 }
 
 func (a *API) handleCreateTemplate(w http.ResponseWriter, r *http.Request) {
@@ -866,22 +1398,19 @@ func (a *API) handleCreateTemplate(w http.ResponseWriter, r *http.Request) {
 		writeErr(w, http.StatusBadRequest, "invalid JSON")
 		return
 	}
-	id := uuid.NewString()
-	_, err := a.Store.DB.Exec(`INSERT INTO templates (id,name,text_only,images_json,images_caption,videos_json,videos_caption,audio_json,stickers_json,docs_json,docs_caption,enabled,created_at,updated_at)
-		VALUES (?,?,?,?,?,?,?,?,?,?,?, ?, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)`,
-		id, req.Name, req.TextOnly,
-		toJSONArray(req.ImageURLs), req.ImageCaption,
-		toJSONArray(req.VideoURLs), req.VideoCaption,
-		toJSONArray(req.AudioURLs),
-		toJSONArray(req.StickerURLs),
-		toJSONArray(req.DocURLs), req.DocCaption,
-		btoi(req.Enabled),
-	)
+	if msg := req.check(); msg != "" {
+		writeErr(w, http.StatusBadRequest, msg)
+		return
+	}
+	t := req.template()
+	rep := lint.Template(t)
+	t.Lint = &rep
+	id, err := a.Store.CreateTemplate(t)
 	if err != nil {
 		writeErr(w, http.StatusInternalServerError, err.Error())
 		return
 	}
-	writeJSON(w, http.StatusCreated, map[string]any{"id": id})
+	writeJSON(w, http.StatusCreated, map[string]any{"id": id, "lint": rep})
 }
 
 func (a *API) handleToggleTemplate(w http.ResponseWriter, r *http.Request) {
@@ -893,31 +1422,18 @@ func (a *API) handleToggleTemplate(w http.ResponseWriter, r *http.Request) {
 		writeErr(w, http.StatusBadRequest, "invalid JSON")
 		return
 	}
-	res, err := a.Store.DB.Exec(`UPDATE templates SET enabled=?, updated_at=CURRENT_TIMESTAMP WHERE id=?`, btoi(body.Enabled), id)
-	if err != nil {
-		writeErr(w, http.StatusInternalServerError, err.Error())
+	err := a.Store.SetTemplateEnabled(id, body.Enabled)
+	if errors.Is(err, storage.ErrTemplateNotFound) {
+		writeErr(w, http.StatusNotFound, err.Error())
 		return
 	}
-	if n, _ := res.RowsAffected(); n == 0 {
-		writeErr(w, http.StatusNotFound, "template not found")
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
 		return
 	}
 	writeJSON(w, http.StatusOK, map[string]any{"updated": 1})
 }
 
-func parseJSONArray(s string) []string {
-	var arr []string
-	if strings.TrimSpace(s) == "" {
-		return arr
-	}
-	_ = json.Unmarshal([]byte(s), &arr)
-	return arr
-}
-func toJSONArray(arr []string) string {
-	b, _ := json.Marshal(arr)
-	return string(b)
-}
-
 func btoi(b bool) int {
 	if b {
 		return 1
@@ -935,42 +1451,36 @@ func (a *API) handleUpdateTemplate(w http.ResponseWriter, r *http.Request) {
 		writeErr(w, http.StatusBadRequest, "invalid JSON")
 		return
 	}
-	// Normalize enabled default true if omitted
-	enabled := req.Enabled
-	// Run update
-	res, err := a.Store.DB.Exec(`UPDATE templates
-		SET name=?, text_only=?, images_json=?, images_caption=?, videos_json=?, videos_caption=?, audio_json=?, stickers_json=?, docs_json=?, docs_caption=?, enabled=?, updated_at=CURRENT_TIMESTAMP
-		WHERE id=?`,
-		req.Name, req.TextOnly,
-		toJSONArray(req.ImageURLs), req.ImageCaption,
-		toJSONArray(req.VideoURLs), req.VideoCaption,
-		toJSONArray(req.AudioURLs),
-		toJSONArray(req.StickerURLs),
-		toJSONArray(req.DocURLs), req.DocCaption,
-		btoi(enabled),
-		id,
-	)
-	if err != nil {
-		writeErr(w, http.StatusInternalServerError, err.Error())
+	if msg := req.check(); msg != "" {
+		writeErr(w, http.StatusBadRequest, msg)
 		return
 	}
-	if n, _ := res.RowsAffected(); n == 0 {
-		writeErr(w, http.StatusNotFound, "template not found")
+	t := req.template()
+	t.ID = id
+	rep := lint.Template(t)
+	t.Lint = &rep
+	err := a.Store.UpdateTemplate(t)
+	if errors.Is(err, storage.ErrTemplateNotFound) {
+		writeErr(w, http.StatusNotFound, err.Error())
 		return
 	}
-	writeJSON(w, http.StatusOK, map[string]any{"updated": 1})
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"updated": 1, "lint": rep})
 }
 
 // Delete template by ID.
 func (a *API) handleDeleteTemplate(w http.ResponseWriter, r *http.Request) {
 	id := chi.URLParam(r, "id")
-	res, err := a.Store.DB.Exec(`DELETE FROM templates WHERE id=?`, id)
-	if err != nil {
-		writeErr(w, http.StatusInternalServerError, err.Error())
+	err := a.Store.DeleteTemplate(id)
+	if errors.Is(err, storage.ErrTemplateNotFound) {
+		writeErr(w, http.StatusNotFound, err.Error())
 		return
 	}
-	if n, _ := res.RowsAffected(); n == 0 {
-		writeErr(w, http.StatusNotFound, "template not found")
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
 		return
 	}
 	writeJSON(w, http.StatusOK, map[string]any{"deleted": 1})
@@ -978,7 +1488,8 @@ func (a *API) handleDeleteTemplate(w http.ResponseWriter, r *http.Request) {
 
 /********** End Templates Management **********/
 
-// Upload file (multipart) for images, videos, audio/voice, stickers (webp), documents.
+// Upload file (multipart) for images, videos, GIF videos (mp4), audio, voice notes, stickers (webp), documents.
+// Voice note (kind=voice_note) dikonversi ke OGG/Opus saat upload (butuh ffmpeg).
 func (a *API) handleUpload(w http.ResponseWriter, r *http.Request) {
 	if err := r.ParseMultipartForm(50 << 20); err != nil {
 		writeErr(w, http.StatusBadRequest, "parse multipart failed")
@@ -993,6 +1504,7 @@ func (a *API) handleUpload(w http.ResponseWriter, r *http.Request) {
 	defer file.Close()
 
 	ext := strings.ToLower(filepath.Ext(header.Filename))
+	var src io.Reader = file
 	switch kind {
 	case "image":
 		if ext == "" {
@@ -1002,10 +1514,30 @@ func (a *API) handleUpload(w http.ResponseWriter, r *http.Request) {
 		if ext == "" {
 			ext = ".mp4"
 		}
+	case "gif":
+		// GifPlayback WhatsApp butuh mp4; file .gif asli tidak diputar sebagai GIF.
+		if ext != ".mp4" {
+			writeErr(w, http.StatusBadRequest, "gif must be an .mp4 video")
+			return
+		}
 	case "audio":
 		if ext == "" {
 			ext = ".mp3"
 		}
+	case "voice_note":
+		data, err := io.ReadAll(file)
+		if err != nil {
+			writeErr(w, http.StatusBadRequest, "read file failed")
+			return
+		}
+		if !voicenote.IsOggOpus(data) {
+			if data, err = voicenote.Convert(r.Context(), data); err != nil {
+				writeErr(w, http.StatusUnprocessableEntity, err.Error())
+				return
+			}
+		}
+		src = bytes.NewReader(data)
+		ext = ".ogg"
 	case "sticker":
 		// WA sticker recommended: .webp
 		ext = ".webp"
@@ -1018,12 +1550,12 @@ func (a *API) handleUpload(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := os.MkdirAll("uploads", 0o755); err != nil {
+	if err := os.MkdirAll(a.uploadDir(), 0o755); err != nil {
 		writeErr(w, http.StatusInternalServerError, "mkdir uploads failed")
 		return
 	}
 	fname := uuid.NewString() + ext
-	path := filepath.Join("uploads", fname)
+	path := filepath.Join(a.uploadDir(), fname)
 
 	out, err := os.Create(path)
 	if err != nil {
@@ -1031,7 +1563,7 @@ func (a *API) handleUpload(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	defer out.Close()
-	if _, err := io.Copy(out, file); err != nil {
+	if _, err := io.Copy(out, src); err != nil {
 		writeErr(w, http.StatusInternalServerError, "write file failed")
 		return
 	}
@@ -1048,11 +1580,15 @@ func (a *API) handleUpload(w http.ResponseWriter, r *http.Request) {
 		if m == "mp4" || m == "mov" || m == "mkv" {
 			mime = "video/" + m
 		}
+	case "gif":
+		mime = "video/mp4"
 	case "audio":
 		m := strings.TrimPrefix(ext, ".")
 		if m == "mp3" || m == "ogg" || m == "wav" || m == "m4a" {
 			mime = "audio/" + m
 		}
+	case "voice_note":
+		mime = voicenote.Mimetype
 	case "sticker":
 		mime = "image/webp"
 	case "doc":
@@ -1124,10 +1660,15 @@ small.mono{font-family:ui-monospace,Menlo,Consolas,monospace;color:var(--muted)}
 </style>
 </head>
 <body>
-<header><h1>Promote WA Dashboard</h1></header>
+<header><h1>Promote WA Dashboard</h1>
+  <div class="row"><small id="auth-user" class="mono"></small>
+    <button id="btn-login" class="secondary" type="button">Login</button>
+    <button id="btn-logout" class="secondary" type="button" style="display:none">Logout</button></div>
+</header>
 <main>
 <section id="health">
   <div class="row"><strong>Status server:</strong><span id="health-status" class="ok">menunggu...</span><small class="mono" id="health-time"></small></div>
+  <div class="row"><label><input type="checkbox" id="safe-mode"> Safe mode (semua kiriman ke grup uji akun)</label><small class="err" id="safe-mode-warn"></small></div>
 </section>
 
 <section id="account-create">
@@ -1167,6 +1708,64 @@ small.mono{font-family:ui-monospace,Menlo,Consolas,monospace;color:var(--muted)}
   </div>
 </section>
 
+<section id="autojoin">
+  <h3>Auto-Join Grup</h3>
+  <div class="row">
+    <select id="aj-account"></select>
+    <label><input type="checkbox" id="aj-enabled"> Aktif</label>
+    <label>Limit/hari <input id="aj-limit" type="number" min="1" max="100" value="20" style="width:80px"></label>
+    <label><input type="checkbox" id="aj-preview" checked> Preview sebelum join</label>
+    <label><input type="checkbox" id="aj-approval"> Perlu persetujuan</label>
+    <label>Anggota min <input id="aj-min" type="number" min="0" value="0" style="width:80px"></label>
+    <label>maks <input id="aj-max" type="number" min="0" value="0" style="width:80px"></label>
+    <label><input type="checkbox" id="aj-autoenable"> Aktifkan grup setelah join</label>
+    <label>setelah <input id="aj-settle" type="number" min="0" value="0" style="width:70px"> menit</label>
+  </div>
+  <div class="row">
+    <label>Template intro <input id="aj-intro" placeholder="ID template (kosong = tanpa intro)" style="width:260px"></label>
+    <label>kirim <input id="aj-intro-delay" type="number" min="0" value="30" style="width:70px"> menit setelah join</label>
+    <label>+ acak s/d <input id="aj-intro-jitter" type="number" min="0" value="15" style="width:70px"> menit</label>
+  </div>
+  <div class="row">
+    <label>Jendela join (WIB) <input id="aj-windows" placeholder="09:00-12:00, 15:00-21:00 (kosong = kapan saja)" style="width:300px"></label>
+    <label>Jeda antar join <input id="aj-jitter-min" type="number" min="0" max="3600" value="3" style="width:70px"></label>
+    <label>s/d <input id="aj-jitter-max" type="number" min="0" max="3600" value="3" style="width:70px"> detik</label>
+  </div>
+  <div class="row">
+    <input id="aj-whitelist" placeholder="Whitelist kontak (JID/nomor, pisah koma)" style="width:320px">
+    <input id="aj-blacklist" placeholder="Blacklist kata di nama grup (pisah koma)" style="width:320px">
+    <button id="btn-aj-save">Simpan Pengaturan</button>
+  </div>
+  <div class="row">
+    <input id="aj-link" placeholder="https://chat.whatsapp.com/... atau kode undangan" style="width:420px">
+    <button id="btn-aj-join" class="secondary">Join Manual</button>
+  </div>
+  <div class="row" style="margin-top:8px">
+    <select id="aj-status">
+      <option value="">Semua status</option>
+      <option value="joined">joined</option>
+      <option value="failed">failed</option>
+      <option value="skipped">skipped</option>
+    </select>
+    <button id="btn-aj-logs" class="secondary">Muat Log</button>
+    <small class="mono" id="aj-stats"></small>
+  </div>
+  <table style="margin-top:8px">
+    <thead><tr><th>Waktu</th><th>Grup</th><th>Kode</th><th>Dibagikan oleh</th><th>Status</th><th>Alasan</th></tr></thead>
+    <tbody id="aj-logs-tbody"></tbody>
+  </table>
+  <h4>Antrean Persetujuan</h4>
+  <div class="row">
+    <button id="btn-aj-approve" class="secondary">Approve Terpilih</button>
+    <button id="btn-aj-reject" class="danger">Reject Terpilih</button>
+    <small class="mono" id="aj-queue-info"></small>
+  </div>
+  <table style="margin-top:8px">
+    <thead><tr><th><input type="checkbox" id="aj-queue-all"></th><th>Masuk</th><th>Grup</th><th>Kode</th><th>Dibagikan oleh</th><th>Status</th></tr></thead>
+    <tbody id="aj-queue-tbody"></tbody>
+  </table>
+</section>
+
 <section id="groups">
   <h3>Grup (per Akun)</h3>
   <div class="row">
@@ -1174,9 +1773,12 @@ small.mono{font-family:ui-monospace,Menlo,Consolas,monospace;color:var(--muted)}
     <button id="btn-refresh" class="secondary">Refresh dari WhatsApp</button>
     <button onclick="enableAllGroups()" class="secondary" style="margin-left:1rem;">✓ Enable All</button>
     <button onclick="disableAllGroups()" class="secondary" style="margin-left:0.5rem;">✗ Disable All</button>
+    <button id="btn-scrape" class="secondary" style="margin-left:1rem;" title="Ambil anggota grup aktif satu per satu dengan jeda">Scrape Anggota</button>
+    <button id="btn-scrape-stop" class="secondary" style="display:none">Stop</button>
+    <small id="scrape-progress" class="mono"></small>
   </div>
   <table style="margin-top:8px">
-    <thead><tr><th>Nama Grup</th><th>Enabled</th><th>Terakhir Kirim</th><th>Risk</th><th>ID</th><th>Aksi</th></tr></thead>
+    <thead><tr><th>Nama Grup</th><th>Enabled</th><th>Uji</th><th>Terakhir Kirim</th><th>Risk</th><th>ID</th><th>Aksi</th></tr></thead>
     <tbody id="groups-tbody"></tbody>
   </table>
 </section>
@@ -1210,10 +1812,20 @@ small.mono{font-family:ui-monospace,Menlo,Consolas,monospace;color:var(--muted)}
     <textarea id="send-vid-caption" placeholder="Caption khusus untuk video" rows="2" style="width:300px"></textarea>
   </div>
   <div class="row" style="margin-top:8px">
-    <label for="send-file-audio">Audio/Voice</label>
+    <label for="send-file-gif">GIF (mp4)</label>
+    <input type="file" id="send-file-gif" accept="video/mp4" multiple>
+    <small class="mono">Diputar berulang; caption = Caption Video</small>
+  </div>
+  <div class="row" style="margin-top:8px">
+    <label for="send-file-audio">Audio</label>
     <input type="file" id="send-file-audio" accept="audio/*" multiple>
     <small class="mono">Audio tidak support caption</small>
   </div>
+  <div class="row" style="margin-top:8px">
+    <label for="send-file-voice">Voice Note (PTT)</label>
+    <input type="file" id="send-file-voice" accept="audio/*" multiple>
+    <small class="mono">Dikonversi ke OGG/Opus saat upload</small>
+  </div>
   <div class="row" style="margin-top:8px">
     <label for="send-file-sticker">Sticker (webp)</label>
     <input type="file" id="send-file-sticker" accept="image/webp" multiple>
@@ -1259,6 +1871,15 @@ small.mono{font-family:ui-monospace,Menlo,Consolas,monospace;color:var(--muted)}
   </div>
 </section>
 
+<section id="digests">
+  <h3>Digest Harian</h3>
+  <div class="row"><button class="secondary" id="btn-digest-run">Buat digest hari ini</button></div>
+  <table style="margin-top:8px">
+    <thead><tr><th>Hari</th><th>Akun</th><th>Terkirim</th><th>Gagal</th><th>Soft bounce</th><th>Limit</th><th>Insiden</th><th>Join</th></tr></thead>
+    <tbody id="digest-body"></tbody>
+  </table>
+</section>
+
 <section id="logs">
   <h3>Log Aktivitas</h3>
   <div class="row" style="justify-content:space-between;margin-bottom:10px;">
@@ -1278,6 +1899,16 @@ small.mono{font-family:ui-monospace,Menlo,Consolas,monospace;color:var(--muted)}
       <button id="logs-next" class="secondary">Next ›</button>
     </div>
   </div>
+  <div class="row">
+    <select id="note-severity" style="width:110px;">
+      <option value="info">info</option>
+      <option value="warning">warning</option>
+      <option value="critical">critical</option>
+    </select>
+    <input id="note-account" placeholder="ID akun (opsional)" style="width:160px">
+    <input id="note-text" placeholder="Catatan shift/insiden, mis. akun dijeda karena banyak gagal" style="width:420px">
+    <button id="btn-note-add" class="secondary">Tambah Catatan</button>
+  </div>
   <table>
     <thead><tr><th>Waktu</th><th>Akun</th><th>Grup</th><th>Status</th><th>Preview</th><th>Error</th></tr></thead>
     <tbody id="logs-tbody"></tbody>
@@ -1318,6 +1949,12 @@ small.mono{font-family:ui-monospace,Menlo,Consolas,monospace;color:var(--muted)}
     <label for="tpl-text-only">Text-Only Message</label>
     <textarea id="tpl-text-only" placeholder="Pesan text saja (tanpa media)" rows="3" style="width:100%"></textarea>
   </div>
+  <div class="row" style="margin-top:8px">
+    <label for="tpl-og-url">Isi dari URL</label>
+    <input id="tpl-og-url" placeholder="https://contoh.com/artikel" style="width:300px">
+    <button id="tpl-og-fetch" class="secondary">Ambil Preview</button>
+    <label><input type="checkbox" id="tpl-link-preview"> Kirim dengan link preview</label>
+  </div>
   <div class="row" style="margin-top:8px">
     <label for="file-image">Gambar</label>
     <input type="file" id="file-image" accept="image/*" multiple>
@@ -1331,10 +1968,20 @@ small.mono{font-family:ui-monospace,Menlo,Consolas,monospace;color:var(--muted)}
     <textarea id="tpl-vid-caption" placeholder="Caption untuk video" rows="2" style="width:300px"></textarea>
   </div>
   <div class="row" style="margin-top:8px">
-    <label for="file-audio">Audio/Voice</label>
+    <label for="file-gif">GIF (mp4)</label>
+    <input type="file" id="file-gif" accept="video/mp4" multiple>
+    <small class="mono">Diputar berulang; caption = Caption Video</small>
+  </div>
+  <div class="row" style="margin-top:8px">
+    <label for="file-audio">Audio</label>
     <input type="file" id="file-audio" accept="audio/*" multiple>
     <small class="mono">Audio tidak support caption</small>
   </div>
+  <div class="row" style="margin-top:8px">
+    <label for="file-voice">Voice Note (PTT)</label>
+    <input type="file" id="file-voice" accept="audio/*" multiple>
+    <small class="mono">Dikonversi ke OGG/Opus saat upload</small>
+  </div>
   <div class="row" style="margin-top:8px">
     <label for="file-sticker">Sticker (webp)</label>
     <input type="file" id="file-sticker" accept="image/webp" multiple>
@@ -1346,9 +1993,26 @@ small.mono{font-family:ui-monospace,Menlo,Consolas,monospace;color:var(--muted)}
     <label for="tpl-doc-caption">Caption Dokumen</label>
     <textarea id="tpl-doc-caption" placeholder="Caption untuk dokumen" rows="2" style="width:300px"></textarea>
   </div>
-  <small class="mono">Template baru: Text-only untuk pesan murni teks, atau media dengan caption terpisah. Gunakan {group_name} dan {time_now} untuk personalisasi.</small>
+  <div class="row" style="margin-top:8px">
+    <label for="tpl-contact-name">Kartu Kontak</label>
+    <input id="tpl-contact-name" placeholder="Nama (mis. CS Toko)" style="width:180px">
+    <input id="tpl-contact-phone" placeholder="Nomor (mis. +62812...)" style="width:180px">
+    <small class="mono">Dikirim terakhir sebagai vCard (tap-to-save); kosongkan nomor jika tidak perlu</small>
+  </div>
+  <div class="row" style="margin-top:8px">
+    <label for="tpl-media-fail">Jika media gagal</label>
+    <select id="tpl-media-fail">
+      <option value="abort">Hentikan sesi</option>
+      <option value="skip">Lewati media, lanjut</option>
+      <option value="fallback">Ganti gambar cadangan</option>
+    </select>
+    <input id="tpl-fallback-url" placeholder="URL gambar cadangan (untuk opsi ganti)" style="width:300px">
+    <label><input type="checkbox" id="tpl-mention-all"> Mention semua anggota (teks-only)</label>
+    <input id="tpl-languages" placeholder="Bahasa grup, mis. id, ms (kosong = semua)" style="width:260px">
+  </div>
+  <small class="mono">Template baru: Text-only untuk pesan murni teks, atau media dengan caption terpisah. Gunakan {group_name}, {time_now}, {field:nama} (custom field grup, mis. {field:discount}), serta variabel dinamis {nama:key} dari /api/dynamic-vars (mis. {stock:sku123}) untuk personalisasi.</small>
   <table style="margin-top:8px">
-    <thead><tr><th>Nama</th><th>Aktif</th><th>Text-Only</th><th>Images</th><th>Videos</th><th>Audio</th><th>Stickers</th><th>Docs</th><th>Aksi</th></tr></thead>
+    <thead><tr><th>Nama</th><th>Aktif</th><th>Text-Only</th><th>Images</th><th>Videos</th><th>GIF</th><th>Audio</th><th>Voice</th><th>Stickers</th><th>Docs</th><th>Aksi</th></tr></thead>
     <tbody id="tpl-tbody"></tbody>
   </table>
 </section>
@@ -1356,7 +2020,38 @@ small.mono{font-family:ui-monospace,Menlo,Consolas,monospace;color:var(--muted)}
 
 <script>
 var $ = function(s){ return document.querySelector(s); };
-var api = function(p,opt){ opt=opt||{}; var h=opt.headers||{}; h['Content-Type']='application/json'; opt.headers=h; return fetch(p,opt); };
+// Token sesi login (atau API key) disimpan di localStorage; login diminta jika server membalas 401.
+function authHeaders(h){ h=h||{}; var k=localStorage.getItem('apiKey'); if(k) h['Authorization']='Bearer '+k; return h; }
+async function login(){
+  var u = prompt('Login diperlukan. Username (kosongkan untuk memakai API key):');
+  if(u===null) return false;
+  if(!u){
+    var k = prompt('API key:'); if(!k) return false;
+    localStorage.setItem('apiKey',k.trim()); loadMe(); return true;
+  }
+  var pw = prompt('Password untuk '+u+':'); if(pw===null) return false;
+  var r = await fetch('/api/auth/login',{method:'POST',headers:{'Content-Type':'application/json'},body:JSON.stringify({username:u,password:pw})});
+  if(!r.ok){ alert('Login gagal'); return false; }
+  var j = await r.json(); localStorage.setItem('apiKey', j.token); loadMe(); return true;
+}
+async function logout(){
+  await api('/api/auth/logout',{method:'POST'});
+  localStorage.removeItem('apiKey'); loadMe();
+}
+async function loadMe(){
+  var r = await fetch('/api/auth/me',{headers:authHeaders()}); if(!r.ok) return;
+  var j = await r.json(); var p = j.principal;
+  $('#auth-user').textContent = p ? (p.name+' ('+p.role+')') : (j.auth_enabled ? 'belum login' : 'auth nonaktif');
+  $('#btn-login').style.display = p ? 'none' : '';
+  $('#btn-logout').style.display = (p && p.kind==='user') ? '' : 'none';
+}
+var api = async function(p,opt){
+  opt=opt||{}; var h=opt.headers||{}; h['Content-Type']='application/json'; opt.headers=authHeaders(h);
+  var r = await fetch(p,opt);
+  if(r.status===401 && await login()){ opt.headers=authHeaders(h); r = await fetch(p,opt); }
+  if(r.status===403){ alert('Aksi ini hanya untuk admin'); }
+  return r;
+};
 
 function escapeHtml(s){
   s = (s==null ? '' : String(s));
@@ -1380,6 +2075,20 @@ function handleGroupAction(action, groupId) {
   }
 }
 
+async function loadSafeMode(){
+  var r = await api('/api/settings/safe-mode'); if(!r.ok) return;
+  var j = await r.json();
+  $('#safe-mode').checked = !!j.enabled;
+  var miss = (j.accounts_without_test||[]).map(function(a){ return a.label||a.id; });
+  $('#safe-mode-warn').textContent = (j.enabled && miss.length) ? ' tanpa grup uji (kiriman dibatalkan): '+miss.join(', ') : '';
+}
+
+async function toggleSafeMode(){
+  var r = await api('/api/settings/safe-mode', { method:'PUT', body: JSON.stringify({ enabled: $('#safe-mode').checked }) });
+  if(!r.ok){ alert('Gagal ubah safe mode: '+await r.text()); }
+  await loadSafeMode();
+}
+
 async function pollHealth(){
   try{
     var r = await api('/api/health'); var j = await r.json();
@@ -1436,6 +2145,7 @@ async function loadAccounts(){
   var sel = $('#groups-account'); sel.innerHTML = '';
   var sel2 = $('#send-account'); if (sel2) sel2.innerHTML = '';
   var sel3 = $('#pair-account'); if (sel3) sel3.innerHTML = '';
+  var sel4 = $('#aj-account'); if (sel4) sel4.innerHTML = '';
   list.forEach(function(a){
     tb.appendChild(rowAccount(a));
     var opt = document.createElement('option'); opt.value = a.id; opt.textContent = a.label + ' ('+(a.msisdn||'-')+')';
@@ -1448,7 +2158,14 @@ async function loadAccounts(){
       var opt3 = document.createElement('option'); opt3.value = a.id; opt3.textContent = a.label + ' ('+(a.msisdn||'-')+')';
       sel3.appendChild(opt3);
     }
+    if (sel4) {
+      var opt4 = document.createElement('option'); opt4.value = a.id; opt4.textContent = a.label + ' ('+(a.msisdn||'-')+')';
+      sel4.appendChild(opt4);
+    }
   });
+  if (sel4 && sel4.value) {
+    await loadAutoJoinPanel();
+  }
   // Load groups for send-test section after accounts loaded
   if (sel2 && sel2.value) {
     await loadSendGroups();
@@ -1490,8 +2207,14 @@ async function createAccount(){
 
 var qrTimer = null;
 
+// QR diambil lewat fetch agar header Authorization ikut terkirim (<img src> tidak bisa), lalu
+// ditampilkan sebagai blob URL.
 async function showQR(id){
-  $('#qr-img').src = '/api/accounts/'+id+'/pair/qr?ts='+(Date.now());
+  var r = await api('/api/accounts/'+id+'/pair/qr?ts='+(Date.now()));
+  if(!r.ok) return;
+  var img = $('#qr-img'), old = img.src;
+  img.src = URL.createObjectURL(await r.blob());
+  if(old && old.indexOf('blob:')===0) URL.revokeObjectURL(old);
 }
 
 function startQRRefresh(id){
@@ -1555,6 +2278,7 @@ function rowGroup(g){
   tr.innerHTML =
     '<td>'+escapeHtml(g.name||'-')+'</td>'+
     '<td><input type="checkbox" data-id="'+g.id+'" class="g-toggle" '+(g.enabled?'checked':'')+'></td>'+
+    '<td><input type="checkbox" data-id="'+g.id+'" class="g-test" title="Grup uji (safe mode)" '+(g.test_group?'checked':'')+'></td>'+
     '<td>'+(g.last_sent_at? new Date(g.last_sent_at).toLocaleString():'-')+'</td>'+
     '<td>'+g.risk_score+'</td>'+
     '<td><small class="mono">'+g.id+'</small></td>'+
@@ -1574,6 +2298,36 @@ async function loadGroups(){
   list.forEach(function(g){ tb.appendChild(rowGroup(g)); });
 }
 
+async function setTestGroup(id, on){
+  var r = await api('/api/groups/'+encodeURIComponent(id),{method:'PATCH',body:JSON.stringify({test_group:on})});
+  if(!r.ok){ var t=await r.text(); alert('Set grup uji gagal: '+t); }
+  loadGroups(); loadSafeMode();
+}
+
+var scrapeTimer = null;
+async function loadScrape(){
+  var r = await api('/api/groups/scrape'); if(!r.ok) return;
+  var p = await r.json();
+  var txt = '';
+  if(p.running){ txt = 'Scraping '+p.done+'/'+p.total+' (gagal '+p.failed+', jeda '+p.delay+')'; }
+  else if(p.finished_at){ txt = 'Scrape terakhir: '+p.refreshed+'/'+p.total+' ok, gagal '+p.failed+(p.cancelled?' (dihentikan)':'')+(p.last_error?' — '+p.last_error:''); }
+  $('#scrape-progress').textContent = txt;
+  $('#btn-scrape-stop').style.display = p.running ? '' : 'none';
+  clearTimeout(scrapeTimer);
+  if(p.running){ scrapeTimer = setTimeout(loadScrape, 3000); }
+}
+
+async function startScrape(){
+  var r = await api('/api/groups/scrape',{method:'POST',body:JSON.stringify({account_id:$('#groups-account').value||''})});
+  if(!r.ok){ alert('Scrape gagal: '+await r.text()); }
+  loadScrape();
+}
+
+async function stopScrape(){
+  await api('/api/groups/scrape',{method:'DELETE'});
+  loadScrape();
+}
+
 async function toggleGroup(id, enabled){
   var r = await api('/api/groups/toggle',{method:'POST',body:JSON.stringify({group_id:id,enabled:enabled})});
   if(!r.ok){ var t=await r.text(); alert('Toggle gagal: '+t); }
@@ -1697,6 +2451,128 @@ async function toggleAutoJoin(accountId, enabled){
   }
 }
 
+function splitList(v){
+  return v.split(',').map(function(x){ return x.trim(); }).filter(function(x){ return x; });
+}
+
+async function loadAutoJoinPanel(){
+  var acc = $('#aj-account') ? $('#aj-account').value : '';
+  if(!acc) return;
+  var r = await api('/api/accounts/'+encodeURIComponent(acc)+'/autojoin/settings');
+  if(r.ok){
+    var s = await r.json();
+    $('#aj-enabled').checked = !!s.enabled;
+    $('#aj-limit').value = s.daily_limit;
+    $('#aj-preview').checked = !!s.preview_before_join;
+    $('#aj-approval').checked = !!s.require_approval;
+    $('#aj-min').value = s.min_participants || 0;
+    $('#aj-max').value = s.max_participants || 0;
+    $('#aj-autoenable').checked = !!s.auto_enable_after_join;
+    $('#aj-settle').value = s.auto_enable_settle_min || 0;
+    $('#aj-intro').value = s.intro_template_id || '';
+    $('#aj-intro-delay').value = s.intro_delay_min;
+    $('#aj-intro-jitter').value = s.intro_jitter_min;
+    $('#aj-windows').value = formatJoinWindows(s.join_windows);
+    $('#aj-jitter-min').value = s.join_jitter_min_sec;
+    $('#aj-jitter-max').value = s.join_jitter_max_sec;
+    $('#aj-whitelist').value = (s.whitelist_contacts||[]).join(', ');
+    $('#aj-blacklist').value = (s.blacklist_keywords||[]).join(', ');
+  }
+  await loadAutoJoinLogs();
+  await loadAutoJoinQueue();
+}
+
+// Jendela join: sama setiap hari ditampilkan sebagai daftar "HH:MM-HH:MM", selain itu sebagai JSON per hari.
+function formatJoinWindows(w){
+  if(!w) return '';
+  var mon = (w.mon||[]).join(', ');
+  var same = ['sun','tue','wed','thu','fri','sat'].every(function(d){ return (w[d]||[]).join(', ') === mon; });
+  return same ? mon : JSON.stringify(w);
+}
+
+function parseJoinWindows(v){
+  v = v.trim();
+  if(!v) return null;
+  if(v.charAt(0) === '{'){ try { return JSON.parse(v); } catch(e){ return v; } }
+  return { 'sun-sat': splitList(v) };
+}
+
+async function saveAutoJoinSettings(){
+  var acc = $('#aj-account').value; if(!acc) return;
+  var body = {
+    enabled: $('#aj-enabled').checked,
+    daily_limit: parseInt($('#aj-limit').value, 10) || 20,
+    preview_before_join: $('#aj-preview').checked,
+    require_approval: $('#aj-approval').checked,
+    min_participants: parseInt($('#aj-min').value, 10) || 0,
+    max_participants: parseInt($('#aj-max').value, 10) || 0,
+    auto_enable_after_join: $('#aj-autoenable').checked,
+    auto_enable_settle_min: parseInt($('#aj-settle').value, 10) || 0,
+    intro_template_id: $('#aj-intro').value.trim(),
+    intro_delay_min: parseInt($('#aj-intro-delay').value, 10) || 0,
+    intro_jitter_min: parseInt($('#aj-intro-jitter').value, 10) || 0,
+    join_windows: parseJoinWindows($('#aj-windows').value),
+    join_jitter_min_sec: parseInt($('#aj-jitter-min').value, 10) || 0,
+    join_jitter_max_sec: parseInt($('#aj-jitter-max').value, 10) || 0,
+    whitelist_contacts: splitList($('#aj-whitelist').value),
+    blacklist_keywords: splitList($('#aj-blacklist').value)
+  };
+  var r = await api('/api/accounts/'+encodeURIComponent(acc)+'/autojoin/settings', { method:'PUT', body: JSON.stringify(body) });
+  if(!r.ok){ alert('Gagal simpan auto-join: '+await r.text()); return; }
+  await loadAutoJoinPanel();
+  await loadAutoJoinStatus(acc);
+}
+
+async function manualAutoJoin(){
+  var acc = $('#aj-account').value;
+  var link = $('#aj-link').value.trim();
+  if(!acc || !link){ alert('Pilih akun dan isi link undangan'); return; }
+  var body = { account_id: acc };
+  if(link.indexOf('/') >= 0) body.invite_link = link; else body.invite_code = link;
+  var r = await api('/api/autojoin/manual', { method:'POST', body: JSON.stringify(body) });
+  if(!r.ok){ alert('Gagal join: '+await r.text()); return; }
+  $('#aj-link').value = '';
+  // Join diproses di background; log dimuat ulang setelah jeda singkat.
+  setTimeout(loadAutoJoinLogs, 5000);
+}
+
+async function loadAutoJoinLogs(){
+  var acc = $('#aj-account') ? $('#aj-account').value : '';
+  if(!acc) return;
+  var q = '?limit=50';
+  var st = $('#aj-status').value; if(st) q += '&status='+encodeURIComponent(st);
+  var r = await api('/api/accounts/'+encodeURIComponent(acc)+'/autojoin/logs'+q);
+  if(!r.ok) return;
+  var j = await r.json(); var s = j.stats||{};
+  $('#aj-stats').textContent = 'hari ini: '+(s.joined_today||0)+' | joined: '+(s.total_joined||0)+' | failed: '+(s.total_failed||0)+' | skipped: '+(s.total_skipped||0);
+  $('#aj-logs-tbody').innerHTML = (j.logs||[]).map(function(l){
+    var cls = l.status==='joined' ? 'ok' : (l.status==='failed' ? 'err' : '');
+    var retry = l.status==='failed' ? ' <button class="aj-retry" data-log-id="'+l.id+'">Retry</button>' : '';
+    return '<tr><td class="mono">'+escapeHtml(l.joined_at)+'</td><td>'+escapeHtml(l.group_name||l.group_id||'-')+'</td><td class="mono">'+escapeHtml(l.invite_code)+'</td><td class="mono">'+escapeHtml(l.shared_by||'-')+'</td><td class="'+cls+'">'+escapeHtml(l.status)+retry+'</td><td>'+escapeHtml(l.reason||'')+'</td></tr>';
+  }).join('');
+}
+
+async function loadAutoJoinQueue(){
+  var acc = $('#aj-account') ? $('#aj-account').value : '';
+  if(!acc) return;
+  var r = await api('/api/autojoin/queue?status=pending&account_id='+encodeURIComponent(acc));
+  if(!r.ok) return;
+  var list = await r.json();
+  $('#aj-queue-all').checked = false;
+  $('#aj-queue-info').textContent = list.length+' menunggu';
+  $('#aj-queue-tbody').innerHTML = list.map(function(q){
+    return '<tr><td><input type="checkbox" class="aj-queue-cb" value="'+q.id+'"></td><td class="mono">'+escapeHtml(q.created_at)+'</td><td>'+escapeHtml(q.group_name||'-')+'</td><td class="mono">'+escapeHtml(q.invite_code)+'</td><td class="mono">'+escapeHtml(q.shared_by||'-')+'</td><td>'+escapeHtml(q.status)+'</td></tr>';
+  }).join('');
+}
+
+async function decideAutoJoinQueue(approve){
+  var ids = Array.prototype.map.call(document.querySelectorAll('.aj-queue-cb:checked'), function(cb){ return parseInt(cb.value, 10); });
+  if(!ids.length){ alert('Pilih item antrean dulu'); return; }
+  var r = await api('/api/autojoin/queue/'+(approve?'approve':'reject'), { method:'POST', body: JSON.stringify({ ids: ids }) });
+  if(!r.ok){ alert('Gagal memproses antrean: '+await r.text()); return; }
+  await loadAutoJoinQueue();
+}
+
 function renderParticipants(list){
   var tb = document.getElementById('participants-tbody'); 
   if(!tb) return;
@@ -1918,6 +2794,20 @@ async function loadStats(){
   $('#s-failed').textContent = j.failed||0;
 }
 
+async function loadDigests(){
+  var r = await api('/api/digests?limit=30'); if(!r.ok) return;
+  var list = await r.json(); var tb = $('#digest-body'); if(!tb) return;
+  tb.innerHTML = list.map(function(d){
+    return '<tr><td class="mono">'+escapeHtml(d.day)+'</td><td>'+escapeHtml(d.label||d.account_id)+'</td><td>'+d.sent+'</td><td>'+d.failed+'</td><td>'+d.soft_bounced+'</td><td>'+Math.round(d.utilization*100)+'% dari '+d.daily_limit+'</td><td>'+d.incidents+'</td><td>'+d.joins+'</td></tr>';
+  }).join('');
+}
+
+async function runDigest(){
+  var r = await api('/api/digests/run', { method:'POST', body: JSON.stringify({}) });
+  if(!r.ok){ alert('Gagal buat digest: '+await r.text()); return; }
+  await loadDigests();
+}
+
 async function sendTest(){
   var acc = $('#send-account') ? $('#send-account').value : '';
   var gidDropdown = $('#send-group') ? $('#send-group').value : '';
@@ -1942,7 +2832,7 @@ async function sendTest(){
   // Upload langsung dari file input, tanpa perlu URL manual
   async function upload(kind, file){
     var fd = new FormData(); fd.append('kind', kind); fd.append('file', file);
-    var r = await fetch('/api/upload', { method:'POST', body: fd });
+    var r = await fetch('/api/upload', { method:'POST', body: fd, headers: authHeaders() });
     if(!r.ok){ throw new Error(await r.text()); }
     var j = await r.json(); return j.url;
   }
@@ -1959,7 +2849,9 @@ async function sendTest(){
   try{
     var images = await collect('image','send-file-image');
     var videos = await collect('video','send-file-video');
+    var gifs = await collect('gif','send-file-gif');
     var audios = await collect('audio','send-file-audio');
+    var voices = await collect('voice_note','send-file-voice');
     var stickers = await collect('sticker','send-file-sticker');
     var docs = await collect('doc','send-file-doc');
     
@@ -1973,7 +2865,9 @@ async function sendTest(){
         image_caption: imgCaption,
         video_urls: videos, 
         video_caption: vidCaption,
+        gif_urls: gifs,
         audio_urls: audios, 
+        voice_urls: voices,
         sticker_urls: stickers, 
         doc_urls: docs,
         doc_caption: docCaption
@@ -1993,6 +2887,37 @@ function bindEvents(){
   $('#acc-create').addEventListener('click', createAccount);
   var btnSave = document.getElementById('acc-save');
   if (btnSave) btnSave.addEventListener('click', saveAccount);
+  var safeMode = document.getElementById('safe-mode');
+  if (safeMode) safeMode.addEventListener('change', toggleSafeMode);
+  var btnDigest = document.getElementById('btn-digest-run');
+  if (btnDigest) btnDigest.addEventListener('click', runDigest);
+  var ajAccount = document.getElementById('aj-account');
+  if (ajAccount) ajAccount.addEventListener('change', loadAutoJoinPanel);
+  var btnAjSave = document.getElementById('btn-aj-save');
+  if (btnAjSave) btnAjSave.addEventListener('click', saveAutoJoinSettings);
+  var btnAjJoin = document.getElementById('btn-aj-join');
+  if (btnAjJoin) btnAjJoin.addEventListener('click', manualAutoJoin);
+  var btnAjLogs = document.getElementById('btn-aj-logs');
+  if (btnAjLogs) btnAjLogs.addEventListener('click', loadAutoJoinLogs);
+  var btnNoteAdd = document.getElementById('btn-note-add');
+  if (btnNoteAdd) btnNoteAdd.addEventListener('click', addAnnotation);
+  var ajStatus = document.getElementById('aj-status');
+  if (ajStatus) ajStatus.addEventListener('change', loadAutoJoinLogs);
+  var btnAjApprove = document.getElementById('btn-aj-approve');
+  if (btnAjApprove) btnAjApprove.addEventListener('click', function(){ decideAutoJoinQueue(true); });
+  var btnAjReject = document.getElementById('btn-aj-reject');
+  if (btnAjReject) btnAjReject.addEventListener('click', function(){ decideAutoJoinQueue(false); });
+  var ajLogs = document.getElementById('aj-logs-tbody');
+  if (ajLogs) ajLogs.addEventListener('click', async function(e){
+    var btn = e.target.closest('button.aj-retry'); if(!btn) return;
+    var r = await api('/api/autojoin/logs/'+btn.getAttribute('data-log-id')+'/retry', { method:'POST' });
+    if(!r.ok){ alert('Gagal requeue: '+await r.text()); return; }
+    btn.disabled = true; btn.textContent = 'Queued';
+  });
+  var ajQueueAll = document.getElementById('aj-queue-all');
+  if (ajQueueAll) ajQueueAll.addEventListener('change', function(){
+    document.querySelectorAll('.aj-queue-cb').forEach(function(cb){ cb.checked = ajQueueAll.checked; });
+  });
   $('#accounts-tbody').addEventListener('click', function(e){
     var btn = e.target.closest('button'); if(!btn) return;
     var id = btn.getAttribute('data-id');
@@ -2006,6 +2931,10 @@ function bindEvents(){
   });
   $('#btn-refresh').addEventListener('click', refreshGroups);
   $('#groups-account').addEventListener('change', loadGroups);
+  $('#btn-scrape').addEventListener('click', startScrape);
+  $('#btn-login').addEventListener('click', login);
+  $('#btn-logout').addEventListener('click', logout);
+  $('#btn-scrape-stop').addEventListener('click', stopScrape);
   
   // Send test: load groups when account changes
   var sendAccSel = $('#send-account');
@@ -2034,6 +2963,8 @@ function bindEvents(){
     });
   }
   $('#groups-tbody').addEventListener('change', function(e){
+    var tg = e.target.closest('.g-test');
+    if(tg){ setTestGroup(tg.getAttribute('data-id'), tg.checked); return; }
     var cb = e.target.closest('.g-toggle'); if(!cb) return;
     toggleGroup(cb.getAttribute('data-id'), cb.checked);
   });
@@ -2114,6 +3045,8 @@ function bindEvents(){
   if (btnTpl) btnTpl.addEventListener('click', createTemplate);
   var btnTplSave = document.getElementById('tpl-save');
   if (btnTplSave) btnTplSave.addEventListener('click', saveTemplate);
+  var btnOg = document.getElementById('tpl-og-fetch');
+  if (btnOg) btnOg.addEventListener('click', fillTemplateFromURL);
   
   // Template test modal event listeners
   var testAccountSel = $('#test-account');
@@ -2183,6 +3116,40 @@ function rowLog(l){
   return tr;
 }
 
+// authEventSource pengganti EventSource yang membawa header Authorization: stream SSE dibaca
+// lewat fetch, event diteruskan ke onmessage/addEventListener, dan koneksi diulang 5 detik setelah putus.
+function authEventSource(url){
+  var es = { onmessage: null, handlers: {}, addEventListener: function(t,fn){ this.handlers[t]=fn; } };
+  function dispatch(frame){
+    var type = 'message', data = [];
+    frame.split('\n').forEach(function(line){
+      if(line.indexOf('event:')===0) type = line.slice(6).trim();
+      else if(line.indexOf('data:')===0) data.push(line.slice(5).replace(/^ /,''));
+    });
+    if(!data.length) return;
+    var ev = { data: data.join('\n') };
+    if(type==='message'){ if(es.onmessage) es.onmessage(ev); }
+    else if(es.handlers[type]) es.handlers[type](ev);
+  }
+  async function run(){
+    try{
+      var r = await fetch(url,{headers:authHeaders()});
+      if(r.status===401 && await login()) r = await fetch(url,{headers:authHeaders()});
+      if(!r.ok || !r.body) throw new Error('stream '+r.status);
+      var reader = r.body.getReader(), dec = new TextDecoder(), buf = '';
+      for(;;){
+        var c = await reader.read(); if(c.done) break;
+        buf += dec.decode(c.value,{stream:true}).replace(/\r/g,'');
+        var i;
+        while((i = buf.indexOf('\n\n')) >= 0){ dispatch(buf.slice(0,i)); buf = buf.slice(i+2); }
+      }
+    }catch(e){}
+    setTimeout(run, 5000);
+  }
+  run();
+  return es;
+}
+
 var esLogs = null;
 var allLogs = [];
 var currentPage = 1;
@@ -2190,7 +3157,7 @@ var logsPerPage = 25;
 
 function logsConnect(){
   try{
-    esLogs = new EventSource('/api/logs/stream');
+    esLogs = authEventSource('/api/logs/stream');
     esLogs.onmessage = function(ev){
       try{
         var l = JSON.parse(ev.data);
@@ -2211,9 +3178,31 @@ function logsConnect(){
         updateLogsInfo();
       }catch(e){}
     };
+    // Anotasi operator ditampilkan sebagai baris log berstatus "catatan:<severity>".
+    esLogs.addEventListener('annotation', function(ev){
+      try{
+        var n = JSON.parse(ev.data);
+        allLogs.unshift({
+          ts: n.at, account_id: n.account_id, group_id: n.group_id,
+          status: 'catatan:'+n.severity,
+          message_preview: n.text + (n.author ? ' — '+n.author : '')
+        });
+        if(allLogs.length > 500) allLogs = allLogs.slice(0, 500);
+        if(currentPage === 1) renderLogsPage();
+        updateLogsInfo();
+      }catch(e){}
+    });
   }catch(e){}
 }
 
+async function addAnnotation(){
+  var text = $('#note-text').value.trim(); if(!text) return;
+  var body = { text: text, severity: $('#note-severity').value, account_id: $('#note-account').value.trim() };
+  var r = await api('/api/annotations', { method:'POST', body: JSON.stringify(body) });
+  if(!r.ok){ alert('Gagal simpan catatan: '+await r.text()); return; }
+  $('#note-text').value = '';
+}
+
 function renderLogsPage(){
   var tb = document.getElementById('logs-tbody');
   if(!tb) return;
@@ -2271,7 +3260,7 @@ function goToLogsPage(page){
 function renderAccountCard(acc){
   var card = document.createElement('section');
   card.style.marginTop = '8px';
-  card.innerHTML = '<div class="row"><strong>'+escapeHtml(acc.label)+'</strong> <small class="mono">('+(acc.msisdn?escapeHtml(acc.msisdn):'-')+')</small> <span class="'+(acc.status==='online'?'ok':'err')+'">'+escapeHtml(acc.status||'-')+'</span> <button class="secondary" data-act="connect" data-id="'+acc.id+'">Connect</button> <button class="secondary" data-act="logout" data-id="'+acc.id+'">Logout</button> <button class="secondary" data-act="refresh" data-id="'+acc.id+'">Refresh Grup</button></div>'+
+  card.innerHTML = '<div class="row"><strong>'+escapeHtml(acc.label)+'</strong> <small class="mono">('+(acc.msisdn?escapeHtml(acc.msisdn):'-')+')</small> <span class="'+(acc.status==='online'?'ok':'err')+'">'+escapeHtml(acc.status||'-')+'</span> '+(acc.daily_limit?'<small class="mono">kuota '+(acc.sent_today||0)+'/'+acc.daily_limit+'</small> ':'')+(acc.soft_bounced_today?'<small class="err">soft bounce '+acc.soft_bounced_today+'</small> ':'')+'<button class="secondary" data-act="connect" data-id="'+acc.id+'">Connect</button> <button class="secondary" data-act="logout" data-id="'+acc.id+'">Logout</button> <button class="secondary" data-act="refresh" data-id="'+acc.id+'">Refresh Grup</button></div>'+
                    '<table><thead><tr><th>Nama Grup</th><th>Enabled</th><th>Terakhir Kirim</th><th>Risk</th><th>ID</th></tr></thead><tbody><tr><td colspan="5"><small class="mono">Memuat...</small></td></tr></tbody></table>';
   card.setAttribute('data-acc-id', acc.id);
   return card;
@@ -2307,32 +3296,15 @@ async function refreshOneAccount(accID){
 async function loadGroupsByNumber(){
   var container = document.getElementById('groups-container'); if (!container) return;
   container.innerHTML = '';
-  var ra = await api('/api/accounts'); var accs = await ra.json();
-  accs.forEach(function(acc){
+  // Satu request status + satu request grup untuk semua akun (tanpa loop connect/refresh per akun)
+  var rs = await api('/api/accounts/status'); var accs = await rs.json();
+  var rg = await api('/api/groups'); var all = await rg.json();
+  var byAcc = {};
+  (Array.isArray(all)?all:[]).forEach(function(g){ (byAcc[g.account_id] = byAcc[g.account_id] || []).push(g); });
+  (Array.isArray(accs)?accs:[]).forEach(function(acc){
     container.appendChild(renderAccountCard(acc));
+    renderAccountGroups(acc.id, byAcc[acc.id] || []);
   });
-  for (var i=0;i<accs.length;i++){
-    try{
-      var acc = accs[i];
-      // upayakan connect jika belum online
-      if ((acc.status||'').toLowerCase() !== 'online') {
-        try { await api('/api/accounts/'+encodeURIComponent(acc.id)+'/connect', { method:'POST' }); } catch(_){}
-        await new Promise(function(res){ setTimeout(res, 500); });
-      }
-      var rg = await api('/api/groups?account_id='+encodeURIComponent(acc.id));
-      var glist = await rg.json();
-      if (!Array.isArray(glist) || glist.length === 0) {
-        // coba refresh dari WhatsApp jika kosong, lalu fetch ulang
-        await api('/api/accounts/'+encodeURIComponent(acc.id)+'/groups/refresh', { method:'POST' });
-        await new Promise(function(res){ setTimeout(res, 800); });
-        rg = await api('/api/groups?account_id='+encodeURIComponent(acc.id));
-        glist = await rg.json();
-      }
-      renderAccountGroups(acc.id, glist);
-    }catch(e){
-      try { renderAccountGroups(accs[i].id, []); } catch(_){}
-    }
-  }
 }
 function rowTemplate(t){
   var tr = document.createElement('tr');
@@ -2344,7 +3316,9 @@ function rowTemplate(t){
     '<td>'+textOnlyPreview+'</td>'+
     '<td>'+(t.image_urls||[]).length+'</td>'+
     '<td>'+(t.video_urls||[]).length+'</td>'+
+    '<td>'+(t.gif_urls||[]).length+'</td>'+
     '<td>'+(t.audio_urls||[]).length+'</td>'+
+    '<td>'+(t.voice_urls||[]).length+'</td>'+
     '<td>'+(t.sticker_urls||[]).length+'</td>'+
     '<td>'+(t.doc_urls||[]).length+'</td>'+
     '<td>'+
@@ -2390,7 +3364,7 @@ async function createTemplate(){
   
   async function upload(kind, file){
     var fd = new FormData(); fd.append('kind', kind); fd.append('file', file);
-    var r = await fetch('/api/upload', { method:'POST', body: fd });
+    var r = await fetch('/api/upload', { method:'POST', body: fd, headers: authHeaders() });
     if(!r.ok){ throw new Error(await r.text()); }
     var j = await r.json(); return j.url;
   }
@@ -2407,9 +3381,12 @@ async function createTemplate(){
   try{
     var imgs = await collect('image','file-image');
     var vids = await collect('video','file-video');
+    var gifs = await collect('gif','file-gif');
     var auds = await collect('audio','file-audio');
+    var vns  = await collect('voice_note','file-voice');
     var sts  = await collect('sticker','file-sticker');
     var docs = await collect('doc','file-doc');
+    imgs = ogDraftImages.concat(imgs);
     
     var r = await api('/api/templates', { 
       method:'POST', 
@@ -2420,10 +3397,19 @@ async function createTemplate(){
         image_caption: imgCaption,
         video_urls: vids, 
         video_caption: vidCaption,
+        gif_urls: gifs,
         audio_urls: auds, 
+        voice_urls: vns,
         sticker_urls: sts, 
         doc_urls: docs,
         doc_caption: docCaption,
+        link_preview: !!(document.getElementById('tpl-link-preview') && document.getElementById('tpl-link-preview').checked),
+        contact_name: document.getElementById('tpl-contact-name') ? document.getElementById('tpl-contact-name').value : '',
+        contact_phone: document.getElementById('tpl-contact-phone') ? document.getElementById('tpl-contact-phone').value : '',
+        media_fail_policy: document.getElementById('tpl-media-fail') ? document.getElementById('tpl-media-fail').value : 'abort',
+        fallback_image_url: document.getElementById('tpl-fallback-url') ? document.getElementById('tpl-fallback-url').value : '',
+        mention_all: !!(document.getElementById('tpl-mention-all') && document.getElementById('tpl-mention-all').checked),
+        languages: document.getElementById('tpl-languages') ? splitList(document.getElementById('tpl-languages').value) : [],
         enabled: true 
       }) 
     });
@@ -2435,8 +3421,16 @@ async function createTemplate(){
     if (document.getElementById('tpl-img-caption')) document.getElementById('tpl-img-caption').value = '';
     if (document.getElementById('tpl-vid-caption')) document.getElementById('tpl-vid-caption').value = '';
     if (document.getElementById('tpl-doc-caption')) document.getElementById('tpl-doc-caption').value = '';
+    if (document.getElementById('tpl-link-preview')) document.getElementById('tpl-link-preview').checked = false;
+    if (document.getElementById('tpl-contact-name')) document.getElementById('tpl-contact-name').value = '';
+    if (document.getElementById('tpl-contact-phone')) document.getElementById('tpl-contact-phone').value = '';
+    if (document.getElementById('tpl-media-fail')) document.getElementById('tpl-media-fail').value = 'abort';
+    if (document.getElementById('tpl-fallback-url')) document.getElementById('tpl-fallback-url').value = '';
+    if (document.getElementById('tpl-mention-all')) document.getElementById('tpl-mention-all').checked = false;
+    if (document.getElementById('tpl-languages')) document.getElementById('tpl-languages').value = '';
+    ogDraftImages = [];
     
-    var fileInputs = ['file-image','file-video','file-audio','file-sticker','file-doc'];
+    var fileInputs = ['file-image','file-video','file-gif','file-audio','file-voice','file-sticker','file-doc'];
     fileInputs.forEach(function(id){
       var el = document.getElementById(id);
       if(el) el.value = '';
@@ -2449,6 +3443,30 @@ async function createTemplate(){
   }
 }
 
+ // ---- Template: draft dari URL (Open Graph) ----
+var ogDraftImages = [];
+async function fillTemplateFromURL(){
+  var url = document.getElementById('tpl-og-url').value.trim();
+  if(!url){ alert('URL wajib'); return; }
+  try{
+    var r = await api('/api/tools/og-draft', { method:'POST', body: JSON.stringify({ url: url }) });
+    if(!r.ok){ throw new Error(await r.text()); }
+    var j = await r.json();
+    var d = j.draft || {};
+    document.getElementById('tpl-name').value = d.name || '';
+    ogDraftImages = d.image_urls || [];
+    if (ogDraftImages.length) {
+      document.getElementById('tpl-text-only').value = '';
+      document.getElementById('tpl-img-caption').value = d.image_caption || '';
+    } else {
+      document.getElementById('tpl-text-only').value = d.text_only || '';
+    }
+    document.getElementById('tpl-link-preview').checked = !!d.link_preview;
+  }catch(e){
+    alert('Gagal ambil preview: '+e.message);
+  }
+}
+
  // ---- Template: Edit/Save/Delete ----
 function startEditTemplate(id){
   var t = tplById[id];
@@ -2465,6 +3483,20 @@ function startEditTemplate(id){
   if (imgCaptionEl) imgCaptionEl.value = t.image_caption || '';
   if (vidCaptionEl) vidCaptionEl.value = t.video_caption || '';
   if (docCaptionEl) docCaptionEl.value = t.doc_caption || '';
+  var linkPrevEl = document.getElementById('tpl-link-preview');
+  if (linkPrevEl) linkPrevEl.checked = !!t.link_preview;
+  var contactNameEl = document.getElementById('tpl-contact-name');
+  var contactPhoneEl = document.getElementById('tpl-contact-phone');
+  if (contactNameEl) contactNameEl.value = t.contact_name || '';
+  if (contactPhoneEl) contactPhoneEl.value = t.contact_phone || '';
+  var mediaFailEl = document.getElementById('tpl-media-fail');
+  var fallbackUrlEl = document.getElementById('tpl-fallback-url');
+  if (mediaFailEl) mediaFailEl.value = t.media_fail_policy || 'abort';
+  if (fallbackUrlEl) fallbackUrlEl.value = t.fallback_image_url || '';
+  var mentionAllEl = document.getElementById('tpl-mention-all');
+  if (mentionAllEl) mentionAllEl.checked = !!t.mention_all;
+  var languagesEl = document.getElementById('tpl-languages');
+  if (languagesEl) languagesEl.value = (t.languages||[]).join(', ');
   
   var btnSave = document.getElementById('tpl-save'); 
   if (btnSave) btnSave.disabled = false;
@@ -2488,7 +3520,7 @@ async function saveTemplate(){
     
     async function upload(kind, file){
       var fd = new FormData(); fd.append('kind', kind); fd.append('file', file);
-      var r = await fetch('/api/upload', { method:'POST', body: fd });
+      var r = await fetch('/api/upload', { method:'POST', body: fd, headers: authHeaders() });
       if(!r.ok){ throw new Error(await r.text()); }
       var j = await r.json(); return j.url;
     }
@@ -2505,7 +3537,9 @@ async function saveTemplate(){
     
     var imgsNew = await collect('image','file-image');
     var vidsNew = await collect('video','file-video');
+    var gifsNew = await collect('gif','file-gif');
     var audsNew = await collect('audio','file-audio');
+    var vnsNew  = await collect('voice_note','file-voice');
     var stsNew  = await collect('sticker','file-sticker');
     var docsNew = await collect('doc','file-doc');
     
@@ -2516,10 +3550,20 @@ async function saveTemplate(){
       image_caption: imgCaption,
       video_urls: (t.video_urls||[]).concat(vidsNew||[]),
       video_caption: vidCaption,
+      gif_urls: (t.gif_urls||[]).concat(gifsNew||[]),
+      products: t.products || [],
       audio_urls: (t.audio_urls||[]).concat(audsNew||[]),
+      voice_urls: (t.voice_urls||[]).concat(vnsNew||[]),
       sticker_urls: (t.sticker_urls||[]).concat(stsNew||[]),
       doc_urls: (t.doc_urls||[]).concat(docsNew||[]),
       doc_caption: docCaption,
+      link_preview: !!(document.getElementById('tpl-link-preview') && document.getElementById('tpl-link-preview').checked),
+      contact_name: document.getElementById('tpl-contact-name') ? document.getElementById('tpl-contact-name').value : '',
+      contact_phone: document.getElementById('tpl-contact-phone') ? document.getElementById('tpl-contact-phone').value : '',
+      media_fail_policy: document.getElementById('tpl-media-fail') ? document.getElementById('tpl-media-fail').value : 'abort',
+      fallback_image_url: document.getElementById('tpl-fallback-url') ? document.getElementById('tpl-fallback-url').value : '',
+      mention_all: !!(document.getElementById('tpl-mention-all') && document.getElementById('tpl-mention-all').checked),
+      languages: document.getElementById('tpl-languages') ? splitList(document.getElementById('tpl-languages').value) : (t.languages||[]),
       enabled: !!t.enabled
     };
     
@@ -2529,7 +3573,7 @@ async function saveTemplate(){
     // reset state & clear file inputs
     editingTemplateId = null;
     var btnSave = document.getElementById('tpl-save'); if (btnSave) btnSave.disabled = true;
-    var ids = ['file-image','file-video','file-audio','file-sticker','file-doc'];
+    var ids = ['file-image','file-video','file-gif','file-audio','file-voice','file-sticker','file-doc'];
     for(var i=0;i<ids.length;i++){ var el = document.getElementById(ids[i]); if(el){ el.value=''; } }
     
     await loadTemplates();
@@ -2639,10 +3683,19 @@ async function sendTestTemplate(){
         image_caption: t.image_caption || '',
         video_urls: t.video_urls || [], 
         video_caption: t.video_caption || '',
+        gif_urls: t.gif_urls || [],
+        products: t.products || [],
         audio_urls: t.audio_urls || [], 
+        voice_urls: t.voice_urls || [],
         sticker_urls: t.sticker_urls || [], 
         doc_urls: t.doc_urls || [],
-        doc_caption: t.doc_caption || ''
+        doc_caption: t.doc_caption || '',
+        link_preview: !!t.link_preview,
+        contact_name: t.contact_name || '',
+        contact_phone: t.contact_phone || '',
+        media_fail_policy: t.media_fail_policy || 'abort',
+        fallback_image_url: t.fallback_image_url || '',
+        mention_all: !!t.mention_all
       })
     });
     if(!r.ok){ throw new Error(await r.text()); }
@@ -2680,7 +3733,7 @@ async function uploadAndAppend(kind, targetFieldId){
     var fd = new FormData();
     fd.append('kind', kind);
     fd.append('file', fileEl.files[0]);
-    var r = await fetch('/api/upload', { method:'POST', body: fd });
+    var r = await fetch('/api/upload', { method:'POST', body: fd, headers: authHeaders() });
     if(!r.ok){
       var t = await r.text(); throw new Error(t);
     }
@@ -2774,9 +3827,13 @@ async function deleteAccount(id){
 // ---- End Akun ----
 async function boot(){
   bindEvents();
+  await loadMe();
   await pollHealth();
+  await loadSafeMode();
+  await loadScrape();
   await loadAccounts();
   await loadStats();
+  await loadDigests();
   logsConnect();
   await loadGroupsByNumber();
   await loadTemplates();
@@ -2807,12 +3864,11 @@ func (a *API) handleEnableAllGroups(w http.ResponseWriter, r *http.Request) {
 		writeErr(w, http.StatusNotFound, "account not found")
 		return
 	}
-	res, err := a.Store.DB.Exec(`UPDATE groups SET enabled=1 WHERE account_id=?`, id)
+	n, err := a.Store.EnableAllGroups(id)
 	if err != nil {
 		writeErr(w, http.StatusInternalServerError, err.Error())
 		return
 	}
-	n, _ := res.RowsAffected()
 	writeJSON(w, http.StatusOK, map[string]any{"updated": n})
 }
 
@@ -2820,24 +3876,23 @@ func (a *API) handleEnableAllGroups(w http.ResponseWriter, r *http.Request) {
 // Strategy: ensure there is at least one active template, then iterate enabled accounts:
 // - connect if paired
 // - check daily limit
-// - pick one eligible group (enabled=1, cooldown 48h, risk<3) randomly
+// - pick one eligible group randomly with the scheduler's filter (SCHEDULER_COOLDOWN_HOURS, SCHEDULER_RISK_THRESHOLD)
 // - send using random active template
 func (a *API) handleSchedulerTrigger(w http.ResponseWriter, r *http.Request) {
 	// Ensure there is at least one active template
-	var nTpl int64
-	_ = a.Store.DB.QueryRow(`SELECT COUNT(*) FROM templates WHERE enabled=1`).Scan(&nTpl)
-	if nTpl == 0 {
+	if nTpl, _ := a.Store.CountActiveTemplates(); nTpl == 0 {
 		writeErr(w, http.StatusBadRequest, "no active template")
 		return
 	}
 
+	cooldownHr, riskThreshold := storage.EligibilityFromEnv()
+
 	// List enabled accounts
-	rows, err := a.Store.DB.Query(`SELECT id, COALESCE(daily_limit,100) FROM accounts WHERE enabled=1 ORDER BY created_at DESC`)
+	list, err := a.Store.ListAccounts()
 	if err != nil {
 		writeErr(w, http.StatusInternalServerError, err.Error())
 		return
 	}
-	defer rows.Close()
 
 	type attemptInfo struct {
 		AccountID string `json:"account_id"`
@@ -2847,13 +3902,11 @@ func (a *API) handleSchedulerTrigger(w http.ResponseWriter, r *http.Request) {
 	}
 	var lastErr string
 
-	for rows.Next() {
-		var accID string
-		var daily int
-		if err := rows.Scan(&accID, &daily); err != nil {
-			lastErr = err.Error()
+	for _, acc := range list {
+		if !acc.Enabled || acc.Observer {
 			continue
 		}
+		accID, daily := acc.ID, acc.DailyLimit
 		if daily <= 0 {
 			daily = 100
 		}
@@ -2863,28 +3916,13 @@ func (a *API) handleSchedulerTrigger(w http.ResponseWriter, r *http.Request) {
 			continue
 		}
 		// Count sent today
-		var sentToday int64
-		_ = a.Store.DB.QueryRow(`
-			SELECT COALESCE(SUM(CASE WHEN status='sent' THEN 1 ELSE 0 END), 0)
-			FROM logs
-			WHERE account_id=? AND ts >= datetime('now','start of day') AND ts < datetime('now','start of day','+1 day')
-		`, accID).Scan(&sentToday)
-		if int(sentToday) >= daily {
+		sentToday, _ := a.Store.CountAccountSentToday(accID)
+		if sentToday >= daily {
 			continue
 		}
-		// Pick one eligible group
-		var groupID string
-		err := a.Store.DB.QueryRow(`
-			SELECT id
-			FROM groups
-			WHERE account_id=? AND enabled=1 AND (last_sent_at IS NULL OR last_sent_at < datetime('now','-48 hours')) AND risk_score < 3
-			ORDER BY RANDOM()
-			LIMIT 1
-		`, accID).Scan(&groupID)
+		// Pick one eligible group (filter scheduler; grup langsung di-reserve seperti kiriman scheduler)
+		groupID, err := a.Store.PickTargetedGroup(accID, cooldownHr, riskThreshold, model.Targeting{}, nil)
 		if err != nil {
-			if errors.Is(err, sql.ErrNoRows) {
-				continue
-			}
 			lastErr = err.Error()
 			continue
 		}
@@ -2938,17 +3976,26 @@ func (a *API) handleForceDeleteAccount(w http.ResponseWriter, r *http.Request) {
 	a.Manager.DropAccount(id)
 
 	// Hapus akun dari database (ON DELETE CASCADE akan menghapus groups terkait)
-	res, err := a.Store.DB.Exec(`DELETE FROM accounts WHERE id=?`, id)
+	exists, err := a.Store.AccountExists(id)
 	if err != nil {
 		writeErr(w, http.StatusInternalServerError, err.Error())
 		return
 	}
-	n, _ := res.RowsAffected()
+	var n int64
+	if exists {
+		if err := a.Store.DeleteAccount(id); err != nil {
+			writeErr(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		n = 1
+	}
 
 	// Best-effort: hapus file sesi whatsmeow per akun jika memakai SQLite file terpisah
-	// Pola file default yang dipakai Manager: promote_wa_{accountID}.db
+	// (lokasi mengikuti SESSION_DIR/DB_DSN, lihat Manager.SessionFile).
 	// Abaikan error jika file tidak ditemukan.
-	_ = os.Remove("promote_wa_" + id + ".db")
+	if fn := a.Manager.SessionFile(id); fn != "" {
+		_ = os.Remove(fn)
+	}
 
 	writeJSON(w, http.StatusOK, map[string]any{
 		"deleted": n,
@@ -2962,46 +4009,11 @@ func (a *API) handleSearchAccounts(w http.ResponseWriter, r *http.Request) {
 	ms := strings.TrimSpace(r.URL.Query().Get("msisdn"))
 	lb := strings.TrimSpace(r.URL.Query().Get("label"))
 
-	var (
-		rows *sql.Rows
-		err  error
-	)
-	switch {
-	case ms != "" && lb != "":
-		rows, err = a.Store.DB.Query(`SELECT id,label,msisdn,enabled,daily_limit,status,COALESCE(last_error,''),created_at,updated_at 
-			FROM accounts 
-			WHERE msisdn LIKE ? OR label LIKE ?
-			ORDER BY created_at DESC`, "%"+ms+"%", "%"+lb+"%")
-	case ms != "":
-		rows, err = a.Store.DB.Query(`SELECT id,label,msisdn,enabled,daily_limit,status,COALESCE(last_error,''),created_at,updated_at 
-			FROM accounts 
-			WHERE msisdn LIKE ?
-			ORDER BY created_at DESC`, "%"+ms+"%")
-	case lb != "":
-		rows, err = a.Store.DB.Query(`SELECT id,label,msisdn,enabled,daily_limit,status,COALESCE(last_error,''),created_at,updated_at 
-			FROM accounts 
-			WHERE label LIKE ?
-			ORDER BY created_at DESC`, "%"+lb+"%")
-	default:
-		rows, err = a.Store.DB.Query(`SELECT id,label,msisdn,enabled,daily_limit,status,COALESCE(last_error,''),created_at,updated_at 
-			FROM accounts ORDER BY created_at DESC`)
-	}
+	list, err := a.Store.SearchAccounts(ms, lb)
 	if err != nil {
 		writeErr(w, http.StatusInternalServerError, err.Error())
 		return
 	}
-	defer rows.Close()
-
-	var list []model.Account
-	for rows.Next() {
-		var a1 model.Account
-		var enabledInt int
-		if err := rows.Scan(&a1.ID, &a1.Label, &a1.Msisdn, &enabledInt, &a1.DailyLimit, &a1.Status, &a1.LastError, &a1.CreatedAt, &a1.UpdatedAt); err != nil {
-			continue
-		}
-		a1.Enabled = enabledInt == 1
-		list = append(list, a1)
-	}
 	writeJSON(w, http.StatusOK, list)
 }
 
@@ -3022,16 +4034,15 @@ func (a *API) handleDeleteByMSISDN(w http.ResponseWriter, r *http.Request) {
 		writeErr(w, http.StatusBadRequest, "msisdn required")
 		return
 	}
-	var id string
-	err := a.Store.DB.QueryRow(`SELECT id FROM accounts WHERE msisdn=? LIMIT 1`, ms).Scan(&id)
+	id, err := a.Store.AccountIDByMsisdn(ms)
 	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			writeJSON(w, http.StatusOK, map[string]any{"deleted": 0})
-			return
-		}
 		writeErr(w, http.StatusInternalServerError, err.Error())
 		return
 	}
+	if id == "" {
+		writeJSON(w, http.StatusOK, map[string]any{"deleted": 0})
+		return
+	}
 
 	// Best-effort session cleanup
 	if err := a.Manager.Logout(id); err != nil {
@@ -3040,17 +4051,17 @@ func (a *API) handleDeleteByMSISDN(w http.ResponseWriter, r *http.Request) {
 	a.Manager.DropAccount(id)
 
 	// Delete from DB
-	res, err := a.Store.DB.Exec(`DELETE FROM accounts WHERE id=?`, id)
-	if err != nil {
+	if err := a.Store.DeleteAccount(id); err != nil {
 		writeErr(w, http.StatusInternalServerError, err.Error())
 		return
 	}
-	n, _ := res.RowsAffected()
 
 	// Remove per-account whatsmeow session file (best-effort)
-	_ = os.Remove("promote_wa_" + id + ".db")
+	if fn := a.Manager.SessionFile(id); fn != "" {
+		_ = os.Remove(fn)
+	}
 
-	writeJSON(w, http.StatusOK, map[string]any{"deleted": n, "id": id})
+	writeJSON(w, http.StatusOK, map[string]any{"deleted": 1, "id": id})
 }
 
 type resetRiskCooldownReq struct {
@@ -3069,40 +4080,10 @@ func (a *API) handleResetRiskCooldown(w http.ResponseWriter, r *http.Request) {
 	var body resetRiskCooldownReq
 	_ = json.NewDecoder(r.Body).Decode(&body)
 
-	if len(body.GroupIDs) > 0 {
-		tx, err := a.Store.DB.Begin()
-		if err != nil {
-			writeErr(w, http.StatusInternalServerError, err.Error())
-			return
-		}
-		var updated int64
-		for _, gid := range body.GroupIDs {
-			g := strings.TrimSpace(gid)
-			if g == "" {
-				continue
-			}
-			res, err := tx.Exec(`UPDATE groups SET risk_score=0, last_sent_at=NULL WHERE id=? AND account_id=?`, g, id)
-			if err != nil {
-				_ = tx.Rollback()
-				writeErr(w, http.StatusInternalServerError, err.Error())
-				return
-			}
-			n, _ := res.RowsAffected()
-			updated += n
-		}
-		if err := tx.Commit(); err != nil {
-			writeErr(w, http.StatusInternalServerError, err.Error())
-			return
-		}
-		writeJSON(w, http.StatusOK, map[string]any{"updated": updated})
-		return
-	}
-
-	res, err := a.Store.DB.Exec(`UPDATE groups SET risk_score=0, last_sent_at=NULL WHERE account_id=?`, id)
+	n, err := a.Store.ResetGroupRisk(id, body.GroupIDs)
 	if err != nil {
 		writeErr(w, http.StatusInternalServerError, err.Error())
 		return
 	}
-	n, _ := res.RowsAffected()
 	writeJSON(w, http.StatusOK, map[string]any{"updated": n})
 }
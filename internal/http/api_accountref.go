@@ -0,0 +1,76 @@
+package httpapi
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"promote/internal/storage"
+)
+
+// accountPathPrefixes awalan path yang segmen berikutnya adalah ID akun.
+var accountPathPrefixes = []string{"/api/accounts/", "/api/scheduler/windows/"}
+
+// resolveAccountRef mengizinkan akun dialamatkan dengan "label:<label>" atau "msisdn:<nomor>"
+// selain UUID, baik sebagai segmen path akun (mis. GET /api/accounts/label:toko-1/groups,
+// /api/scheduler/windows/msisdn:628123) maupun filter ?account_id=. Referensi diganti ID akun
+// sebelum routing sehingga handler tetap hanya melihat UUID.
+func (a *API) resolveAccountRef(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r2 := r
+		// Pakai path ter-escape agar label berisi "/" (dikirim sebagai %2F) tetap satu segmen.
+		escaped := r.URL.EscapedPath()
+		for _, prefix := range accountPathPrefixes {
+			if !strings.HasPrefix(escaped, prefix) {
+				continue
+			}
+			rawRef, rawTail, hasTail := strings.Cut(strings.TrimPrefix(escaped, prefix), "/")
+			ref, err := url.PathUnescape(rawRef)
+			if err != nil || !storage.IsAccountRef(ref) {
+				break
+			}
+			id, ok := a.accountRefID(w, ref)
+			if !ok {
+				return
+			}
+			r2 = r.Clone(r.Context())
+			r2.URL.RawPath = prefix + id
+			if hasTail {
+				r2.URL.RawPath += "/" + rawTail
+			}
+			r2.URL.Path, _ = url.PathUnescape(r2.URL.RawPath)
+			break
+		}
+		q := r2.URL.Query()
+		if ref := q.Get("account_id"); storage.IsAccountRef(ref) {
+			id, ok := a.accountRefID(w, ref)
+			if !ok {
+				return
+			}
+			if r2 == r {
+				r2 = r.Clone(r.Context())
+			}
+			q.Set("account_id", id)
+			r2.URL.RawQuery = q.Encode()
+		}
+		next.ServeHTTP(w, r2)
+	})
+}
+
+// accountRefID menerjemahkan referensi akun ke ID; jika gagal, respons error sudah ditulis.
+func (a *API) accountRefID(w http.ResponseWriter, ref string) (string, bool) {
+	id, err := a.Store.ResolveAccountRef(ref)
+	switch {
+	case errors.Is(err, storage.ErrAccountNotFound):
+		writeErr(w, http.StatusNotFound, err.Error())
+		return "", false
+	case errors.Is(err, storage.ErrAccountAmbiguous):
+		writeErr(w, http.StatusConflict, err.Error())
+		return "", false
+	case err != nil:
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return "", false
+	}
+	return id, true
+}
@@ -0,0 +1,30 @@
+package httpapi
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"promote/internal/model"
+	"promote/internal/storage/storagetest"
+)
+
+func TestAccountRefResolvedOutsideAccountRoutes(t *testing.T) {
+	h, st := newTestRouter(t)
+	storagetest.SeedAccount(t, st, storagetest.Account{ID: "a"})
+	if _, err := st.EnqueueOutbox(model.OutboxItem{AccountID: "a", GroupID: "g1@g.us"}); err != nil {
+		t.Fatal(err)
+	}
+
+	rec := doRequest(h, http.MethodGet, "/api/scheduler/windows/label:acc-a", "")
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"a"`) {
+		t.Fatalf("windows by label = %d %s, want account a", rec.Code, rec.Body.String())
+	}
+	rec = doRequest(h, http.MethodGet, "/api/queue?account_id=label:acc-a", "")
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "g1@g.us") {
+		t.Fatalf("queue filtered by label = %d %s, want the item of account a", rec.Code, rec.Body.String())
+	}
+	if rec := doRequest(h, http.MethodGet, "/api/queue?account_id=label:missing", ""); rec.Code != http.StatusNotFound {
+		t.Fatalf("queue filtered by unknown label = %d, want 404", rec.Code)
+	}
+}
@@ -0,0 +1,38 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"promote/internal/model"
+)
+
+// Strategi rotasi akun pengirim {"strategy":"random"|"round_robin"|"lru"|"weighted"}.
+func (a *API) handleGetAccountRotation(w http.ResponseWriter, r *http.Request) {
+	rot, err := a.Store.AccountRotation()
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, rot)
+}
+
+// Ubah strategi rotasi; berlaku mulai siklus kirim berikutnya.
+func (a *API) handleSetAccountRotation(w http.ResponseWriter, r *http.Request) {
+	var rot model.AccountRotation
+	if err := json.NewDecoder(r.Body).Decode(&rot); err != nil {
+		writeErr(w, http.StatusBadRequest, "invalid JSON")
+		return
+	}
+	rot.Strategy = strings.ToLower(strings.TrimSpace(rot.Strategy))
+	if err := rot.Validate(); err != nil {
+		writeErr(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := a.Store.SetAccountRotation(rot); err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, rot)
+}
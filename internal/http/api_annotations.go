@@ -0,0 +1,154 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"promote/internal/model"
+	"promote/internal/storage"
+)
+
+// timelineFilter membaca ?account_id=&group_id=&before=(RFC3339)&limit= (default 100, maks 500);
+// false jika respons error sudah ditulis.
+func timelineFilter(w http.ResponseWriter, r *http.Request) (model.TimelineFilter, bool) {
+	q := r.URL.Query()
+	f := model.TimelineFilter{AccountID: q.Get("account_id"), GroupID: q.Get("group_id"), Limit: 100}
+	if v := q.Get("before"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			writeErr(w, http.StatusBadRequest, "before must be RFC3339")
+			return f, false
+		}
+		f.Before = t
+	}
+	if n, err := strconv.Atoi(q.Get("limit")); err == nil && n > 0 && n <= 500 {
+		f.Limit = n
+	}
+	return f, true
+}
+
+// Anotasi operator (catatan shift/insiden), terbaru dulu. Filter akun/grup juga menyertakan catatan umum.
+func (a *API) handleListAnnotations(w http.ResponseWriter, r *http.Request) {
+	f, ok := timelineFilter(w, r)
+	if !ok {
+		return
+	}
+	list, err := a.Store.ListAnnotations(f)
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if list == nil {
+		list = []model.Annotation{}
+	}
+	writeJSON(w, http.StatusOK, list)
+}
+
+// Tambah anotasi: {"text": "...", "severity": "info|warning|critical", "at": RFC3339 (opsional,
+// default sekarang), "account_id": "...", "group_id": "..."}. Penulis diambil dari pemanggil.
+func (a *API) handleCreateAnnotation(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Text      string `json:"text"`
+		Severity  string `json:"severity"`
+		At        string `json:"at"`
+		AccountID string `json:"account_id"`
+		GroupID   string `json:"group_id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeErr(w, http.StatusBadRequest, "invalid JSON")
+		return
+	}
+	n := model.Annotation{
+		Text:      strings.TrimSpace(body.Text),
+		Severity:  strings.ToLower(strings.TrimSpace(body.Severity)),
+		AccountID: strings.TrimSpace(body.AccountID),
+		GroupID:   strings.TrimSpace(body.GroupID),
+	}
+	if n.Text == "" {
+		writeErr(w, http.StatusBadRequest, "text required")
+		return
+	}
+	switch n.Severity {
+	case "":
+		n.Severity = model.AnnotationInfo
+	case model.AnnotationInfo, model.AnnotationWarning, model.AnnotationCritical:
+	default:
+		writeErr(w, http.StatusBadRequest, "severity must be info, warning or critical")
+		return
+	}
+	if body.At != "" {
+		t, err := time.Parse(time.RFC3339, body.At)
+		if err != nil {
+			writeErr(w, http.StatusBadRequest, "at must be RFC3339")
+			return
+		}
+		n.At = t
+	}
+	if n.AccountID != "" && !a.accountExists(w, n.AccountID) {
+		return
+	}
+	if n.GroupID != "" {
+		exists, err := a.Store.GroupExists(n.GroupID)
+		if err != nil {
+			writeErr(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		if !exists {
+			writeErr(w, http.StatusNotFound, "group not found")
+			return
+		}
+	}
+	if p := principalFrom(r.Context()); p != nil {
+		n.Author = p.Name
+	}
+	id, err := a.Store.CreateAnnotation(n)
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	created, err := a.Store.GetAnnotation(id)
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusCreated, created)
+}
+
+func (a *API) handleDeleteAnnotation(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		writeErr(w, http.StatusBadRequest, "invalid id")
+		return
+	}
+	err = a.Store.DeleteAnnotation(id)
+	if errors.Is(err, storage.ErrAnnotationNotFound) {
+		writeErr(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"deleted": id})
+}
+
+// Timeline log kirim dengan anotasi operator disisipkan urut waktu (terbaru dulu);
+// ?before= untuk halaman berikutnya.
+func (a *API) handleTimeline(w http.ResponseWriter, r *http.Request) {
+	f, ok := timelineFilter(w, r)
+	if !ok {
+		return
+	}
+	list, err := a.Store.Timeline(f)
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, list)
+}
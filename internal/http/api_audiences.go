@@ -0,0 +1,351 @@
+package httpapi
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"promote/internal/model"
+	"promote/internal/storage"
+)
+
+// maxAudienceCSV membatasi ukuran CSV import anggota audiens.
+const maxAudienceCSV = 10 << 20
+
+type audienceReq struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Numbers     []string `json:"numbers"`
+	// Segment (opsional saat create) menjadikan audiens segmen yang langsung di-refresh.
+	Segment *model.SegmentRule `json:"segment"`
+}
+
+type audienceMembersReq struct {
+	Numbers []string `json:"numbers"`
+}
+
+type audienceGroupsReq struct {
+	AccountID     string   `json:"account_id"`
+	GroupIDs      []string `json:"group_ids"`
+	ExcludeAdmins bool     `json:"exclude_admins"`
+}
+
+// getAudience memuat audiens {id}; false jika respons error sudah ditulis.
+func (a *API) getAudience(w http.ResponseWriter, r *http.Request) (model.Audience, bool) {
+	aud, err := a.Store.GetAudience(chi.URLParam(r, "id"))
+	if errors.Is(err, storage.ErrAudienceNotFound) {
+		writeErr(w, http.StatusNotFound, err.Error())
+		return aud, false
+	}
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return aud, false
+	}
+	return aud, true
+}
+
+// addAudienceMembers menormalisasi nomor lalu menyimpannya; nomor tidak valid dikembalikan di "invalid".
+func (a *API) addAudienceMembers(w http.ResponseWriter, audienceID string, members []model.AudienceMember) {
+	valid, invalid := make([]model.AudienceMember, 0, len(members)), []string{}
+	for _, m := range members {
+		n := storage.NormalizeNumber(m.Number)
+		if n == "" {
+			invalid = append(invalid, m.Number)
+			continue
+		}
+		m.Number = n
+		m.Name = strings.TrimSpace(m.Name)
+		valid = append(valid, m)
+	}
+	added, err := a.Store.AddAudienceMembers(audienceID, valid)
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"added": added, "duplicates": len(valid) - added, "invalid": invalid})
+}
+
+func (a *API) handleListAudiences(w http.ResponseWriter, r *http.Request) {
+	list, err := a.Store.ListAudiences()
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if list == nil {
+		list = []model.Audience{}
+	}
+	writeJSON(w, http.StatusOK, list)
+}
+
+// Buat audiens; numbers (opsional) langsung ditambahkan sebagai anggota manual, segment (opsional)
+// langsung dievaluasi.
+func (a *API) handleCreateAudience(w http.ResponseWriter, r *http.Request) {
+	var req audienceReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErr(w, http.StatusBadRequest, "invalid JSON")
+		return
+	}
+	aud := model.Audience{Name: strings.TrimSpace(req.Name), Description: strings.TrimSpace(req.Description), Segment: req.Segment}
+	if aud.Name == "" {
+		writeErr(w, http.StatusBadRequest, "name required")
+		return
+	}
+	if aud.Segment != nil {
+		if err := aud.Segment.Validate(); err != nil {
+			writeErr(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+	if err := a.Store.CreateAudience(&aud); err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	members := make([]model.AudienceMember, 0, len(req.Numbers))
+	for _, n := range req.Numbers {
+		if n := storage.NormalizeNumber(n); n != "" {
+			members = append(members, model.AudienceMember{Number: n, Source: model.AudienceSourceManual})
+		}
+	}
+	if _, err := a.Store.AddAudienceMembers(aud.ID, members); err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if aud.Segment != nil {
+		if _, err := a.Store.RefreshSegment(aud.ID); err != nil {
+			writeErr(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+	aud, err := a.Store.GetAudience(aud.ID)
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusCreated, aud)
+}
+
+func (a *API) handleGetAudience(w http.ResponseWriter, r *http.Request) {
+	if aud, ok := a.getAudience(w, r); ok {
+		writeJSON(w, http.StatusOK, aud)
+	}
+}
+
+// Ubah nama/deskripsi audiens (anggota tidak disentuh).
+func (a *API) handleUpdateAudience(w http.ResponseWriter, r *http.Request) {
+	var req audienceReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErr(w, http.StatusBadRequest, "invalid JSON")
+		return
+	}
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		writeErr(w, http.StatusBadRequest, "name required")
+		return
+	}
+	id := chi.URLParam(r, "id")
+	err := a.Store.UpdateAudience(id, name, strings.TrimSpace(req.Description))
+	if errors.Is(err, storage.ErrAudienceNotFound) {
+		writeErr(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if aud, ok := a.getAudience(w, r); ok {
+		writeJSON(w, http.StatusOK, aud)
+	}
+}
+
+func (a *API) handleDeleteAudience(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	err := a.Store.DeleteAudience(id)
+	if errors.Is(err, storage.ErrAudienceNotFound) {
+		writeErr(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"deleted": id})
+}
+
+// Anggota audiens (?limit=500&offset=0).
+func (a *API) handleListAudienceMembers(w http.ResponseWriter, r *http.Request) {
+	aud, ok := a.getAudience(w, r)
+	if !ok {
+		return
+	}
+	limit, offset := 500, 0
+	if n, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && n > 0 && n <= 5000 {
+		limit = n
+	}
+	if n, err := strconv.Atoi(r.URL.Query().Get("offset")); err == nil && n > 0 {
+		offset = n
+	}
+	list, err := a.Store.ListAudienceMembers(aud.ID, limit, offset)
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if list == nil {
+		list = []model.AudienceMember{}
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"total": aud.MemberCount, "members": list})
+}
+
+// Tambah nomor manual ke audiens.
+func (a *API) handleAddAudienceMembers(w http.ResponseWriter, r *http.Request) {
+	aud, ok := a.getAudience(w, r)
+	if !ok {
+		return
+	}
+	var req audienceMembersReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErr(w, http.StatusBadRequest, "invalid JSON")
+		return
+	}
+	if len(req.Numbers) == 0 {
+		writeErr(w, http.StatusBadRequest, "numbers required")
+		return
+	}
+	members := make([]model.AudienceMember, 0, len(req.Numbers))
+	for _, n := range req.Numbers {
+		members = append(members, model.AudienceMember{Number: n, Source: model.AudienceSourceManual})
+	}
+	a.addAudienceMembers(w, aud.ID, members)
+}
+
+// Import CSV (body mentah atau multipart field "file"). Jika baris pertama header dengan kolom
+// "number"/"phone" (dan opsional "name"), kolom itu yang dipakai; tanpa header kolom pertama
+// dianggap nomor dan kolom kedua nama.
+func (a *API) handleImportAudienceCSV(w http.ResponseWriter, r *http.Request) {
+	aud, ok := a.getAudience(w, r)
+	if !ok {
+		return
+	}
+	var src io.Reader = r.Body
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/") {
+		file, _, err := r.FormFile("file")
+		if err != nil {
+			writeErr(w, http.StatusBadRequest, "file missing")
+			return
+		}
+		defer file.Close()
+		src = file
+	}
+	members, err := parseAudienceCSV(io.LimitReader(src, maxAudienceCSV))
+	if err != nil {
+		writeErr(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	a.addAudienceMembers(w, aud.ID, members)
+}
+
+func parseAudienceCSV(r io.Reader) ([]model.AudienceMember, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+	recs, err := cr.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(recs) == 0 {
+		return nil, errors.New("empty CSV")
+	}
+	recs[0][0] = strings.TrimPrefix(recs[0][0], "\ufeff")
+	numCol, nameCol := 0, 1
+	if storage.NormalizeNumber(recs[0][0]) == "" {
+		numCol, nameCol = -1, -1
+		for i, h := range recs[0] {
+			switch strings.ToLower(strings.TrimSpace(h)) {
+			case "number", "phone", "msisdn", "nomor":
+				numCol = i
+			case "name", "nama":
+				nameCol = i
+			}
+		}
+		if numCol < 0 {
+			return nil, errors.New("header must contain a number column")
+		}
+		recs = recs[1:]
+	}
+	out := make([]model.AudienceMember, 0, len(recs))
+	for _, rec := range recs {
+		if numCol >= len(rec) || strings.TrimSpace(rec[numCol]) == "" {
+			continue
+		}
+		m := model.AudienceMember{Number: rec[numCol], Source: model.AudienceSourceCSV}
+		if nameCol >= 0 && nameCol < len(rec) {
+			m.Name = rec[nameCol]
+		}
+		out = append(out, m)
+	}
+	return out, nil
+}
+
+// Import anggota grup (cache atau diambil lewat akun) ke audiens; exclude_admins melewati admin grup.
+func (a *API) handleImportAudienceGroups(w http.ResponseWriter, r *http.Request) {
+	aud, ok := a.getAudience(w, r)
+	if !ok {
+		return
+	}
+	var req audienceGroupsReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErr(w, http.StatusBadRequest, "invalid JSON")
+		return
+	}
+	if req.AccountID == "" || len(req.GroupIDs) == 0 {
+		writeErr(w, http.StatusBadRequest, "account_id and group_ids required")
+		return
+	}
+	exists, err := a.Store.AccountExists(req.AccountID)
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if !exists {
+		writeErr(w, http.StatusNotFound, "account not found")
+		return
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), 90*time.Second)
+	defer cancel()
+	var members []model.AudienceMember
+	for _, gid := range req.GroupIDs {
+		parts, err := a.Manager.GetGroupParticipants(ctx, req.AccountID, gid)
+		if err != nil {
+			writeErr(w, waErrStatus(err), gid+": "+err.Error())
+			return
+		}
+		for _, p := range parts {
+			if req.ExcludeAdmins && (p.IsAdmin || p.IsSuperAdmin) {
+				continue
+			}
+			members = append(members, model.AudienceMember{Number: p.Number, Source: model.AudienceSourceGroup})
+		}
+	}
+	a.addAudienceMembers(w, aud.ID, members)
+}
+
+func (a *API) handleRemoveAudienceMember(w http.ResponseWriter, r *http.Request) {
+	n := storage.NormalizeNumber(chi.URLParam(r, "number"))
+	ok, err := a.Store.RemoveAudienceMember(chi.URLParam(r, "id"), n)
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if !ok {
+		writeErr(w, http.StatusNotFound, "number not in audience")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"removed": n})
+}
@@ -0,0 +1,307 @@
+package httpapi
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"promote/internal/model"
+	"promote/internal/storage"
+)
+
+// principal adalah identitas pemanggil: pengguna login, API key, atau key admin dari env.
+type principal struct {
+	Kind string `json:"kind"` // user|api_key|admin_key
+	ID   string `json:"id,omitempty"`
+	Name string `json:"name"`
+	Role string `json:"role"`
+}
+
+type principalKey struct{}
+
+const authRequiredMsg = "login or valid API key required"
+
+func principalFrom(ctx context.Context) *principal {
+	p, _ := ctx.Value(principalKey{}).(*principal)
+	return p
+}
+
+// requestToken membaca token sesi/API key dari header X-API-Key atau Authorization: Bearer.
+func requestToken(r *http.Request) string {
+	if k := strings.TrimSpace(r.Header.Get("X-API-Key")); k != "" {
+		return k
+	}
+	if h := r.Header.Get("Authorization"); len(h) > 7 && strings.EqualFold(h[:7], "Bearer ") {
+		return strings.TrimSpace(h[7:])
+	}
+	return ""
+}
+
+// resolvePrincipal mencocokkan token dengan key admin, sesi login, lalu API key.
+func (a *API) resolvePrincipal(token string) (*principal, error) {
+	if token == "" {
+		return nil, nil
+	}
+	if a.AdminAPIKey != "" && subtle.ConstantTimeCompare([]byte(token), []byte(a.AdminAPIKey)) == 1 {
+		return &principal{Kind: "admin_key", Name: "ADMIN_API_KEY", Role: model.RoleAdmin}, nil
+	}
+	u, err := a.Store.SessionUser(token)
+	if err == nil {
+		return &principal{Kind: "user", ID: u.ID, Name: u.Username, Role: u.Role}, nil
+	}
+	if !errors.Is(err, storage.ErrSessionNotFound) {
+		return nil, err
+	}
+	k, err := a.Store.VerifyAPIKey(token)
+	if err == nil {
+		return &principal{Kind: "api_key", ID: k.ID, Name: k.Name, Role: k.Role}, nil
+	}
+	if !errors.Is(err, storage.ErrAPIKeyNotFound) {
+		return nil, err
+	}
+	return nil, nil
+}
+
+// authConfigured bernilai true begitu ada key admin, API key aktif, atau pengguna. Sebelum itu
+// API terbuka agar admin pertama bisa dibuat (POST /api/users atau /api/keys).
+func (a *API) authConfigured() (bool, error) {
+	if a.AdminAPIKey != "" {
+		return true, nil
+	}
+	n, err := a.Store.CountActiveAPIKeys()
+	if err != nil || n > 0 {
+		return n > 0, err
+	}
+	n, err = a.Store.CountUsers()
+	return n > 0, err
+}
+
+// publicPath route yang tetap terbuka tanpa principal setelah autentikasi aktif: health, login,
+// halaman pairing bertoken (/pair/{token}), redirect short link (/r/{slug}), halaman UI dan file
+// upload. Semua /api/* lain (termasuk GET) dan /metrics wajib login atau API key.
+func publicPath(path string) bool {
+	switch path {
+	case "/api/health", "/api/auth/login":
+		return true
+	}
+	if strings.HasPrefix(path, "/api/") || path == "/metrics" {
+		return false
+	}
+	return true
+}
+
+// authenticate menaruh principal di context dan, begitu autentikasi aktif, mewajibkan principal
+// untuk semua route selain publicPath. Role dibatasi lagi oleh requireAdmin.
+func (a *API) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		p, err := a.resolvePrincipal(requestToken(r))
+		if err != nil {
+			writeErr(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		if p != nil {
+			r = r.WithContext(context.WithValue(r.Context(), principalKey{}, p))
+			next.ServeHTTP(w, r)
+			return
+		}
+		if r.Method == http.MethodOptions || publicPath(r.URL.Path) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		configured, err := a.authConfigured()
+		if err != nil {
+			writeErr(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		if configured {
+			writeErr(w, http.StatusUnauthorized, authRequiredMsg)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// requireAdmin membatasi route ke role admin (hapus akun, pengaturan scheduler, user, key).
+func (a *API) requireAdmin(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		p := principalFrom(r.Context())
+		if p == nil {
+			configured, err := a.authConfigured()
+			if err != nil {
+				writeErr(w, http.StatusInternalServerError, err.Error())
+				return
+			}
+			if configured {
+				writeErr(w, http.StatusUnauthorized, authRequiredMsg)
+				return
+			}
+			next.ServeHTTP(w, r)
+			return
+		}
+		if p.Role != model.RoleAdmin {
+			writeErr(w, http.StatusForbidden, "admin role required")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// logAuthMode memberi peringatan saat start jika API masih tanpa autentikasi.
+func (a *API) logAuthMode() {
+	if ok, err := a.authConfigured(); err == nil && !ok {
+		log.Printf("[auth] WARNING: no users or API keys configured; API is open until an admin is created (POST /api/users) or ADMIN_API_KEY is set")
+	}
+}
+
+// sessionTTL dari SESSION_TTL_HOURS (default 168 jam).
+func sessionTTL() time.Duration {
+	if n, err := strconv.Atoi(strings.TrimSpace(os.Getenv("SESSION_TTL_HOURS"))); err == nil && n > 0 {
+		return time.Duration(n) * time.Hour
+	}
+	return 7 * 24 * time.Hour
+}
+
+type loginReq struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+func (a *API) handleLogin(w http.ResponseWriter, r *http.Request) {
+	var req loginReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErr(w, http.StatusBadRequest, "invalid JSON")
+		return
+	}
+	u, token, expires, err := a.Store.Login(req.Username, req.Password, sessionTTL())
+	if errors.Is(err, storage.ErrInvalidCredentials) {
+		writeErr(w, http.StatusUnauthorized, err.Error())
+		return
+	}
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"token": token, "expires_at": expires, "user": u})
+}
+
+func (a *API) handleLogout(w http.ResponseWriter, r *http.Request) {
+	p := principalFrom(r.Context())
+	if p == nil || p.Kind != "user" {
+		writeErr(w, http.StatusBadRequest, "not logged in")
+		return
+	}
+	if err := a.Store.Logout(requestToken(r)); err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"logged_out": true})
+}
+
+// Identitas pemanggil saat ini (null jika anonim) dan apakah autentikasi sudah aktif.
+func (a *API) handleMe(w http.ResponseWriter, r *http.Request) {
+	configured, err := a.authConfigured()
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"principal": principalFrom(r.Context()), "auth_enabled": configured})
+}
+
+type userReq struct {
+	Username string  `json:"username"`
+	Password *string `json:"password"`
+	Role     *string `json:"role"`
+	Disabled *bool   `json:"disabled"`
+}
+
+func (a *API) handleListUsers(w http.ResponseWriter, r *http.Request) {
+	list, err := a.Store.ListUsers()
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if list == nil {
+		list = []model.User{}
+	}
+	writeJSON(w, http.StatusOK, list)
+}
+
+// Buat pengguna; pengguna pertama selalu admin.
+func (a *API) handleCreateUser(w http.ResponseWriter, r *http.Request) {
+	var req userReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErr(w, http.StatusBadRequest, "invalid JSON")
+		return
+	}
+	if strings.TrimSpace(req.Username) == "" || req.Password == nil || len(*req.Password) < 8 {
+		writeErr(w, http.StatusBadRequest, "username and password (min 8 chars) required")
+		return
+	}
+	role := model.RoleOperator
+	if req.Role != nil {
+		role = *req.Role
+	}
+	if !model.ValidRole(role) {
+		writeErr(w, http.StatusBadRequest, "role must be admin or operator")
+		return
+	}
+	if n, err := a.Store.CountUsers(); err == nil && n == 0 {
+		role = model.RoleAdmin
+	}
+	u, err := a.Store.CreateUser(req.Username, *req.Password, role)
+	if errors.Is(err, storage.ErrUserExists) {
+		writeErr(w, http.StatusConflict, err.Error())
+		return
+	}
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusCreated, u)
+}
+
+func (a *API) handleUpdateUser(w http.ResponseWriter, r *http.Request) {
+	var req userReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErr(w, http.StatusBadRequest, "invalid JSON")
+		return
+	}
+	if req.Role != nil && !model.ValidRole(*req.Role) {
+		writeErr(w, http.StatusBadRequest, "role must be admin or operator")
+		return
+	}
+	if req.Password != nil && len(*req.Password) < 8 {
+		writeErr(w, http.StatusBadRequest, "password must be at least 8 chars")
+		return
+	}
+	id := chi.URLParam(r, "id")
+	err := a.Store.UpdateUser(id, storage.UserUpdate{Role: req.Role, Password: req.Password, Disabled: req.Disabled})
+	writeUserResult(w, err, map[string]any{"updated": id})
+}
+
+func (a *API) handleDeleteUser(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	writeUserResult(w, a.Store.DeleteUser(id), map[string]any{"deleted": id})
+}
+
+func writeUserResult(w http.ResponseWriter, err error, ok map[string]any) {
+	switch {
+	case errors.Is(err, storage.ErrUserNotFound):
+		writeErr(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, storage.ErrLastAdmin):
+		writeErr(w, http.StatusConflict, err.Error())
+	case err != nil:
+		writeErr(w, http.StatusInternalServerError, err.Error())
+	default:
+		writeJSON(w, http.StatusOK, ok)
+	}
+}
@@ -2,22 +2,38 @@ package httpapi
 
 import (
 	"context"
-	"database/sql"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/go-chi/chi/v5"
+
+	"promote/internal/model"
+	"promote/internal/storage"
 )
 
 // Auto-join settings structure for API
 type autoJoinSettingsReq struct {
-	Enabled            bool     `json:"enabled"`
-	DailyLimit         int      `json:"daily_limit"`
-	PreviewBeforeJoin  bool     `json:"preview_before_join"`
-	WhitelistContacts  []string `json:"whitelist_contacts"`
-	BlacklistKeywords  []string `json:"blacklist_keywords"`
+	Enabled             bool     `json:"enabled"`
+	DailyLimit          int      `json:"daily_limit"`
+	PreviewBeforeJoin   bool     `json:"preview_before_join"`
+	RequireApproval     bool     `json:"require_approval"`
+	WhitelistContacts   []string `json:"whitelist_contacts"`
+	BlacklistKeywords   []string `json:"blacklist_keywords"`
+	MinParticipants     int      `json:"min_participants"`
+	MaxParticipants     int      `json:"max_participants"`
+	AutoEnableAfterJoin bool     `json:"auto_enable_after_join"`
+	AutoEnableSettleMin int      `json:"auto_enable_settle_min"`
+	IntroTemplateID     string   `json:"intro_template_id"`
+	IntroDelayMin       *int     `json:"intro_delay_min"`
+	IntroJitterMin      *int     `json:"intro_jitter_min"`
+	// JoinWindows jendela join per hari (WIB); hari yang tidak disebut tanpa jendela, null = kapan saja.
+	JoinWindows      *model.WeekWindows `json:"join_windows"`
+	JoinJitterMinSec *int               `json:"join_jitter_min_sec"`
+	JoinJitterMaxSec *int               `json:"join_jitter_max_sec"`
 }
 
 // handleGetAutoJoinSettings returns auto-join settings for an account
@@ -34,57 +50,12 @@ func (a *API) handleGetAutoJoinSettings(w http.ResponseWriter, r *http.Request)
 		return
 	}
 	
-	// Load settings from database
-	var (
-		enabled           int
-		dailyLimit        int
-		previewBeforeJoin int
-		whitelistJSON     string
-		blacklistJSON     string
-	)
-	
-	err = a.Store.DB.QueryRow(`
-		SELECT enabled, daily_limit, preview_before_join, 
-		       COALESCE(whitelist_contacts, '[]'), COALESCE(blacklist_keywords, '[]')
-		FROM auto_join_settings WHERE account_id=?
-	`, accountID).Scan(&enabled, &dailyLimit, &previewBeforeJoin, &whitelistJSON, &blacklistJSON)
-	
-	if err == sql.ErrNoRows {
-		// Return defaults
-		writeJSON(w, http.StatusOK, map[string]any{
-			"enabled":             false,
-			"daily_limit":         20,
-			"preview_before_join": true,
-			"whitelist_contacts":  []string{},
-			"blacklist_keywords":  []string{},
-		})
-		return
-	}
-	
+	settings, err := a.Store.LoadAutoJoinSettings(accountID)
 	if err != nil {
 		writeErr(w, http.StatusInternalServerError, err.Error())
 		return
 	}
-	
-	// Parse JSON arrays
-	var whitelist, blacklist []string
-	_ = json.Unmarshal([]byte(whitelistJSON), &whitelist)
-	_ = json.Unmarshal([]byte(blacklistJSON), &blacklist)
-	
-	if whitelist == nil {
-		whitelist = []string{}
-	}
-	if blacklist == nil {
-		blacklist = []string{}
-	}
-	
-	writeJSON(w, http.StatusOK, map[string]any{
-		"enabled":             enabled == 1,
-		"daily_limit":         dailyLimit,
-		"preview_before_join": previewBeforeJoin == 1,
-		"whitelist_contacts":  whitelist,
-		"blacklist_keywords":  blacklist,
-	})
+	writeJSON(w, http.StatusOK, settings)
 }
 
 // handleUpdateAutoJoinSettings updates auto-join settings for an account
@@ -114,25 +85,68 @@ func (a *API) handleUpdateAutoJoinSettings(w http.ResponseWriter, r *http.Reques
 	if req.DailyLimit > 100 {
 		req.DailyLimit = 100 // Safety cap
 	}
+	if req.MinParticipants < 0 || req.MaxParticipants < 0 {
+		writeErr(w, http.StatusBadRequest, "min_participants/max_participants must be >= 0")
+		return
+	}
+	if (req.MinParticipants > 0 || req.MaxParticipants > 0) && !req.PreviewBeforeJoin {
+		writeErr(w, http.StatusBadRequest, "member-count filters require preview_before_join")
+		return
+	}
+	if req.MaxParticipants > 0 && req.MaxParticipants < req.MinParticipants {
+		writeErr(w, http.StatusBadRequest, "max_participants must be >= min_participants")
+		return
+	}
+	if req.AutoEnableSettleMin < 0 || req.AutoEnableSettleMin > 7*24*60 {
+		writeErr(w, http.StatusBadRequest, "auto_enable_settle_min must be between 0 and 10080")
+		return
+	}
+	def := model.DefaultAutoJoinSettings(accountID)
+	introDelay, introJitter := intOr(req.IntroDelayMin, def.IntroDelayMin), intOr(req.IntroJitterMin, def.IntroJitterMin)
+	if introDelay < 0 || introDelay > 7*24*60 || introJitter < 0 || introJitter > 24*60 {
+		writeErr(w, http.StatusBadRequest, "intro_delay_min must be between 0 and 10080, intro_jitter_min between 0 and 1440")
+		return
+	}
+	jitterMin, jitterMax := intOr(req.JoinJitterMinSec, def.JoinJitterMinSec), intOr(req.JoinJitterMaxSec, def.JoinJitterMaxSec)
+	if jitterMin < 0 || jitterMax > 3600 || jitterMax < jitterMin {
+		writeErr(w, http.StatusBadRequest, "join_jitter_min_sec/join_jitter_max_sec must satisfy 0 <= min <= max <= 3600")
+		return
+	}
+	if req.JoinWindows != nil {
+		if _, ok := req.JoinWindows.Next(time.Now()); !ok {
+			writeErr(w, http.StatusBadRequest, "join_windows must contain at least one window (use null for any time)")
+			return
+		}
+	}
+	if req.IntroTemplateID = strings.TrimSpace(req.IntroTemplateID); req.IntroTemplateID != "" {
+		if _, err := a.Store.GetTemplate(req.IntroTemplateID); errors.Is(err, storage.ErrTemplateNotFound) {
+			writeErr(w, http.StatusBadRequest, "intro template not found")
+			return
+		} else if err != nil {
+			writeErr(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
 	
-	// Convert arrays to JSON
-	whitelistJSON, _ := json.Marshal(req.WhitelistContacts)
-	blacklistJSON, _ := json.Marshal(req.BlacklistKeywords)
-	
-	// Upsert settings
-	_, err = a.Store.DB.Exec(`
-		INSERT INTO auto_join_settings 
-		(account_id, enabled, daily_limit, preview_before_join, whitelist_contacts, blacklist_keywords)
-		VALUES (?, ?, ?, ?, ?, ?)
-		ON CONFLICT(account_id) DO UPDATE SET
-			enabled=excluded.enabled,
-			daily_limit=excluded.daily_limit,
-			preview_before_join=excluded.preview_before_join,
-			whitelist_contacts=excluded.whitelist_contacts,
-			blacklist_keywords=excluded.blacklist_keywords
-	`, accountID, btoi(req.Enabled), req.DailyLimit, btoi(req.PreviewBeforeJoin), 
-	   string(whitelistJSON), string(blacklistJSON))
-	
+	err = a.Store.SaveAutoJoinSettings(model.AutoJoinSettings{
+		AccountID:           accountID,
+		Enabled:             req.Enabled,
+		DailyLimit:          req.DailyLimit,
+		PreviewBeforeJoin:   req.PreviewBeforeJoin,
+		RequireApproval:     req.RequireApproval,
+		WhitelistContacts:   req.WhitelistContacts,
+		BlacklistKeywords:   req.BlacklistKeywords,
+		MinParticipants:     req.MinParticipants,
+		MaxParticipants:     req.MaxParticipants,
+		AutoEnableAfterJoin: req.AutoEnableAfterJoin,
+		AutoEnableSettleMin: req.AutoEnableSettleMin,
+		IntroTemplateID:     req.IntroTemplateID,
+		IntroDelayMin:       introDelay,
+		IntroJitterMin:      introJitter,
+		JoinWindows:         req.JoinWindows,
+		JoinJitterMinSec:    jitterMin,
+		JoinJitterMaxSec:    jitterMax,
+	})
 	if err != nil {
 		writeErr(w, http.StatusInternalServerError, err.Error())
 		return
@@ -166,14 +180,7 @@ func (a *API) handleToggleAutoJoin(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	
-	// Upsert with default settings if not exists
-	_, err = a.Store.DB.Exec(`
-		INSERT INTO auto_join_settings (account_id, enabled, daily_limit, preview_before_join)
-		VALUES (?, ?, 20, 1)
-		ON CONFLICT(account_id) DO UPDATE SET enabled=excluded.enabled
-	`, accountID, btoi(req.Enabled))
-	
-	if err != nil {
+	if err := a.Store.SetAutoJoinEnabled(accountID, req.Enabled); err != nil {
 		writeErr(w, http.StatusInternalServerError, err.Error())
 		return
 	}
@@ -214,94 +221,33 @@ func (a *API) handleGetAutoJoinLogs(w http.ResponseWriter, r *http.Request) {
 	
 	statusFilter := r.URL.Query().Get("status") // joined, failed, skipped, or empty for all
 	
-	// Build query
-	query := `
-		SELECT id, account_id, COALESCE(group_id, ''), COALESCE(group_name, ''), 
-		       invite_code, COALESCE(shared_by, ''), COALESCE(shared_in, ''),
-		       status, COALESCE(reason, ''), joined_at
-		FROM auto_join_logs
-		WHERE account_id=?
-	`
-	args := []interface{}{accountID}
-	
-	if statusFilter != "" {
-		query += " AND status=?"
-		args = append(args, statusFilter)
-	}
-	
-	query += " ORDER BY joined_at DESC LIMIT ?"
-	args = append(args, limit)
-	
-	rows, err := a.Store.DB.Query(query, args...)
+	logs, err := a.Store.ListAutoJoinLogs(accountID, statusFilter, limit)
 	if err != nil {
 		writeErr(w, http.StatusInternalServerError, err.Error())
 		return
 	}
-	defer rows.Close()
-	
-	var logs []map[string]any
-	for rows.Next() {
-		var (
-			id                                                       int64
-			accountID, groupID, groupName, inviteCode, sharedBy, sharedIn string
-			status, reason                                           string
-			joinedAt                                                 time.Time
-		)
-		
-		if err := rows.Scan(&id, &accountID, &groupID, &groupName, &inviteCode, 
-			&sharedBy, &sharedIn, &status, &reason, &joinedAt); err != nil {
-			continue
-		}
-		
-		logs = append(logs, map[string]any{
-			"id":           id,
-			"account_id":   accountID,
-			"group_id":     groupID,
-			"group_name":   groupName,
-			"invite_code":  inviteCode,
-			"shared_by":    sharedBy,
-			"shared_in":    sharedIn,
-			"status":       status,
-			"reason":       reason,
-			"joined_at":    joinedAt.Format(time.RFC3339),
-		})
-	}
-	
 	if logs == nil {
-		logs = []map[string]any{}
+		logs = []model.AutoJoinLog{}
 	}
 	
-	// Get stats
-	var totalJoined, totalFailed, totalSkipped int64
-	_ = a.Store.DB.QueryRow(`
-		SELECT 
-			COALESCE(SUM(CASE WHEN status='joined' THEN 1 ELSE 0 END), 0),
-			COALESCE(SUM(CASE WHEN status='failed' THEN 1 ELSE 0 END), 0),
-			COALESCE(SUM(CASE WHEN status='skipped' THEN 1 ELSE 0 END), 0)
-		FROM auto_join_logs WHERE account_id=?
-	`, accountID).Scan(&totalJoined, &totalFailed, &totalSkipped)
-	
-	// Get today's count
-	var joinedToday int64
-	_ = a.Store.DB.QueryRow(`
-		SELECT COUNT(*) FROM auto_join_logs 
-		WHERE account_id=? AND status='joined' 
-		AND joined_at >= datetime('now', 'start of day')
-	`, accountID).Scan(&joinedToday)
+	stats, err := a.Store.AutoJoinStats(accountID)
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
 	
 	writeJSON(w, http.StatusOK, map[string]any{
-		"logs": logs,
-		"stats": map[string]any{
-			"total_joined":  totalJoined,
-			"total_failed":  totalFailed,
-			"total_skipped": totalSkipped,
-			"joined_today":  joinedToday,
-		},
+		"logs":  logs,
+		"stats": stats,
 	})
 }
 
 // handleManualJoin allows manual joining of a group via link
 func (a *API) handleManualJoin(w http.ResponseWriter, r *http.Request) {
+	if a.AutoJoiner == nil {
+		writeErr(w, http.StatusServiceUnavailable, "auto-join not running")
+		return
+	}
 	var req struct {
 		AccountID  string `json:"account_id"`
 		InviteCode string `json:"invite_code"`
@@ -347,15 +293,133 @@ func (a *API) handleManualJoin(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	
-	// Process the invite code
-	ctx, cancel := context.WithTimeout(r.Context(), 60*time.Second)
-	defer cancel()
-	
-	// Use empty sharedBy/sharedIn for manual joins
-	go a.AutoJoiner.ProcessInviteCode(ctx, req.AccountID, inviteCode, "manual", "manual")
+	// Diproses di background: context request sudah batal begitu handler selesai,
+	// jadi join memakai context sendiri dengan batas waktu.
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+		defer cancel()
+		a.AutoJoiner.ProcessInviteCode(ctx, req.AccountID, inviteCode, "manual", "manual")
+	}()
 	
 	writeJSON(w, http.StatusOK, map[string]any{
 		"status":  "processing",
 		"message": "Join request submitted. Check logs for status.",
 	})
 }
+
+// handleListAutoJoinQueue returns the approval queue (?account_id=&status=pending&limit=N)
+func (a *API) handleListAutoJoinQueue(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	limit := 100
+	if l, err := strconv.Atoi(q.Get("limit")); err == nil && l > 0 && l <= 500 {
+		limit = l
+	}
+	list, err := a.Store.ListAutoJoinQueue(q.Get("account_id"), q.Get("status"), limit)
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if list == nil {
+		list = []model.AutoJoinQueueItem{}
+	}
+	writeJSON(w, http.StatusOK, list)
+}
+
+// handleApproveAutoJoinQueue approves pending queue items; they are joined by the queue worker
+func (a *API) handleApproveAutoJoinQueue(w http.ResponseWriter, r *http.Request) {
+	a.decideAutoJoinQueue(w, r, true)
+}
+
+// handleRejectAutoJoinQueue rejects pending queue items
+func (a *API) handleRejectAutoJoinQueue(w http.ResponseWriter, r *http.Request) {
+	a.decideAutoJoinQueue(w, r, false)
+}
+
+func (a *API) decideAutoJoinQueue(w http.ResponseWriter, r *http.Request, approve bool) {
+	var req struct {
+		IDs []int64 `json:"ids"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErr(w, http.StatusBadRequest, "invalid JSON")
+		return
+	}
+	if len(req.IDs) == 0 {
+		writeErr(w, http.StatusBadRequest, "ids required")
+		return
+	}
+	n, err := a.Store.DecideAutoJoinQueue(req.IDs, approve)
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if approve && n > 0 && a.AutoJoiner != nil {
+		a.AutoJoiner.Wake()
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"updated": n})
+}
+
+// handleRetryAutoJoin requeues a failed join attempt (auto_join_logs id) for the queue worker,
+// resetting its retry count.
+func (a *API) handleRetryAutoJoin(w http.ResponseWriter, r *http.Request) {
+	if a.AutoJoiner == nil {
+		writeErr(w, http.StatusServiceUnavailable, "auto-join not running")
+		return
+	}
+	id, err := strconv.ParseInt(chi.URLParam(r, "logID"), 10, 64)
+	if err != nil {
+		writeErr(w, http.StatusBadRequest, "invalid log id")
+		return
+	}
+	l, err := a.Store.GetAutoJoinLog(id)
+	if errors.Is(err, storage.ErrAutoJoinLogNotFound) {
+		writeErr(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if l.Status != model.AutoJoinFailed {
+		writeErr(w, http.StatusConflict, "only failed attempts can be requeued")
+		return
+	}
+	joined, err := a.Store.HasJoinedInvite(l.AccountID, l.InviteCode)
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if joined {
+		writeErr(w, http.StatusConflict, "account already joined this group")
+		return
+	}
+	qid, err := a.Store.RequeueAutoJoin(model.AutoJoinQueueItem{
+		AccountID:  l.AccountID,
+		InviteCode: l.InviteCode,
+		GroupName:  l.GroupName,
+		SharedBy:   l.SharedBy,
+		SharedIn:   l.SharedIn,
+	})
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	a.AutoJoiner.Wake()
+	writeJSON(w, http.StatusOK, map[string]any{"queue_id": qid, "status": model.AutoJoinApproved})
+}
+
+// Riwayat intro pasca-join akun (pending/sent/failed), terbaru dulu.
+func (a *API) handleListAutoJoinIntros(w http.ResponseWriter, r *http.Request) {
+	limit := 50
+	if n, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && n > 0 && n <= 500 {
+		limit = n
+	}
+	list, err := a.Store.ListAutoJoinIntros(chi.URLParam(r, "id"), limit)
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if list == nil {
+		list = []model.AutoJoinIntro{}
+	}
+	writeJSON(w, http.StatusOK, list)
+}
@@ -0,0 +1,208 @@
+package httpapi
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"promote/internal/autojoin"
+	"promote/internal/model"
+)
+
+// maxAutoJoinImport batas jumlah link per impor.
+const maxAutoJoinImport = 5000
+
+// autoJoinImportRow satu link impor; AccountID kosong = dibagi bergiliran ke akun impor.
+type autoJoinImportRow struct {
+	Link      string
+	AccountID string
+}
+
+// Impor massal link undangan ke antrean auto-join (langsung disetujui). Body JSON
+// {"links":["https://chat.whatsapp.com/...",...],"account_ids":["..."],"interval_sec":300}, atau CSV
+// (Content-Type text/csv) berkolom link[,account_id] dengan ?account_id=...&interval_sec=... di query.
+// Link tanpa akun dibagi bergiliran ke account_ids; join tiap akun diberi jeda interval_sec
+// (default 300 detik) dan tetap mengikuti limit harian auto-join akun.
+func (a *API) handleImportAutoJoins(w http.ResponseWriter, r *http.Request) {
+	if a.AutoJoiner == nil {
+		writeErr(w, http.StatusServiceUnavailable, "auto-join not running")
+		return
+	}
+	q := r.URL.Query()
+	accounts := q["account_id"]
+	interval := 300
+	if n, err := strconv.Atoi(q.Get("interval_sec")); err == nil {
+		interval = n
+	}
+	var rows []autoJoinImportRow
+	ct, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if ct == "text/csv" {
+		var err error
+		if rows, err = readAutoJoinCSV(r.Body); err != nil {
+			writeErr(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	} else {
+		var req struct {
+			Links       []string `json:"links"`
+			AccountIDs  []string `json:"account_ids"`
+			IntervalSec *int     `json:"interval_sec"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeErr(w, http.StatusBadRequest, "invalid JSON")
+			return
+		}
+		for _, l := range req.Links {
+			rows = append(rows, autoJoinImportRow{Link: l})
+		}
+		accounts = append(accounts, req.AccountIDs...)
+		interval = intOr(req.IntervalSec, interval)
+	}
+	if len(rows) == 0 {
+		writeErr(w, http.StatusBadRequest, "links required")
+		return
+	}
+	if len(rows) > maxAutoJoinImport {
+		writeErr(w, http.StatusBadRequest, "too many links (max "+strconv.Itoa(maxAutoJoinImport)+")")
+		return
+	}
+	if interval < 0 || interval > 86400 {
+		writeErr(w, http.StatusBadRequest, "interval_sec must be between 0 and 86400")
+		return
+	}
+
+	// Validasi akun sekali per ID, baik dari daftar impor maupun kolom CSV.
+	known := map[string]bool{}
+	checkAccount := func(id string) bool {
+		if ok, seen := known[id]; seen {
+			return ok
+		}
+		ok, err := a.Store.AccountExists(id)
+		known[id] = err == nil && ok
+		return known[id]
+	}
+	var pool []string
+	for _, id := range accounts {
+		id = strings.TrimSpace(id)
+		if id == "" || known[id] {
+			continue
+		}
+		if !checkAccount(id) {
+			writeErr(w, http.StatusNotFound, "account not found: "+id)
+			return
+		}
+		pool = append(pool, id)
+	}
+
+	now := time.Now()
+	step := time.Duration(interval) * time.Second
+	perAccount := map[string]int{}
+	seen := map[string]bool{}
+	var items []model.AutoJoinQueueItem
+	invalid := []string{}
+	duplicates := 0
+	next := 0
+	for _, row := range rows {
+		code := importInviteCode(row.Link)
+		if code == "" {
+			invalid = append(invalid, row.Link)
+			continue
+		}
+		acc := strings.TrimSpace(row.AccountID)
+		switch {
+		case acc != "":
+			if !checkAccount(acc) {
+				invalid = append(invalid, row.Link)
+				continue
+			}
+		case len(pool) == 0:
+			writeErr(w, http.StatusBadRequest, "account_ids required")
+			return
+		default:
+			acc = pool[next%len(pool)]
+			next++
+		}
+		if seen[acc+"|"+code] {
+			duplicates++
+			continue
+		}
+		seen[acc+"|"+code] = true
+		notBefore := now.Add(time.Duration(perAccount[acc]) * step)
+		perAccount[acc]++
+		items = append(items, model.AutoJoinQueueItem{
+			AccountID:  acc,
+			InviteCode: code,
+			SharedBy:   "import",
+			SharedIn:   "import",
+			NotBefore:  &notBefore,
+		})
+	}
+	queued, err := a.Store.ImportAutoJoins(items)
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if queued > 0 {
+		a.AutoJoiner.Wake()
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"queued":       queued,
+		"already":      len(items) - queued,
+		"duplicates":   duplicates,
+		"invalid":      invalid,
+		"per_account":  perAccount,
+		"interval_sec": interval,
+	})
+}
+
+// readAutoJoinCSV membaca baris link[,account_id]; baris header (link/invite_link/code) dilewati.
+func readAutoJoinCSV(body io.Reader) ([]autoJoinImportRow, error) {
+	cr := csv.NewReader(body)
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+	var rows []autoJoinImportRow
+	for i := 0; ; i++ {
+		rec, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			return rows, nil
+		}
+		if err != nil {
+			return nil, errors.New("invalid CSV: " + err.Error())
+		}
+		if len(rec) == 0 || strings.TrimSpace(rec[0]) == "" {
+			continue
+		}
+		if i == 0 {
+			switch strings.ToLower(strings.TrimSpace(rec[0])) {
+			case "link", "invite_link", "code", "invite_code":
+				continue
+			}
+		}
+		row := autoJoinImportRow{Link: strings.TrimSpace(rec[0])}
+		if len(rec) > 1 {
+			row.AccountID = strings.TrimSpace(rec[1])
+		}
+		rows = append(rows, row)
+	}
+}
+
+// importInviteCode kode undangan dari link chat.whatsapp.com atau kode polos; kosong jika tidak valid.
+func importInviteCode(s string) string {
+	if codes := autojoin.ExtractInviteCodes(s); len(codes) > 0 {
+		if autojoin.ValidateInviteCode(codes[0]) {
+			return codes[0]
+		}
+		return ""
+	}
+	s = strings.TrimSpace(s)
+	if autojoin.ValidateInviteCode(s) {
+		return s
+	}
+	return ""
+}
@@ -0,0 +1,128 @@
+package httpapi
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"promote/internal/autoleave"
+	"promote/internal/model"
+)
+
+// Kebijakan auto-leave grup bernilai rendah.
+func (a *API) handleGetAutoLeavePolicy(w http.ResponseWriter, r *http.Request) {
+	p, err := a.Store.AutoLeavePolicy()
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, p)
+}
+
+// Ubah kebijakan auto-leave {"enabled":true,"dry_run":false,"failure_streak":5,"min_members":5,
+// "inactive_days":60,"max_per_run":5}; kriteria bernilai 0 dimatikan.
+func (a *API) handleSetAutoLeavePolicy(w http.ResponseWriter, r *http.Request) {
+	p := model.DefaultAutoLeavePolicy()
+	if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+		writeErr(w, http.StatusBadRequest, "invalid JSON")
+		return
+	}
+	if err := p.Validate(); err != nil {
+		writeErr(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := a.Store.SetAutoLeavePolicy(p); err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, p)
+}
+
+// Grup akun ?account_id= yang akan ditinggalkan menurut kebijakan saat ini (tanpa keluar).
+func (a *API) handleAutoLeaveCandidates(w http.ResponseWriter, r *http.Request) {
+	if a.AutoLeave == nil {
+		writeErr(w, http.StatusServiceUnavailable, "auto-leave not running")
+		return
+	}
+	id := r.URL.Query().Get("account_id")
+	if id == "" {
+		writeErr(w, http.StatusBadRequest, "account_id required")
+		return
+	}
+	if !a.accountExists(w, id) {
+		return
+	}
+	p, err := a.Store.AutoLeavePolicy()
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	list, err := a.AutoLeave.Preview(id, p)
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"policy": p, "total": len(list), "candidates": list})
+}
+
+// Riwayat auto-leave (opsional ?account_id=&limit=).
+func (a *API) handleListAutoLeaveLogs(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	limit := 100
+	if n, err := strconv.Atoi(q.Get("limit")); err == nil && n > 0 && n <= 1000 {
+		limit = n
+	}
+	list, err := a.Store.ListAutoLeaveLogs(q.Get("account_id"), limit)
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if list == nil {
+		list = []model.AutoLeaveLog{}
+	}
+	writeJSON(w, http.StatusOK, list)
+}
+
+// Jalankan auto-leave sekarang: {"account_id": "..."} untuk satu akun, kosong untuk semua akun
+// terhubung. Berjalan walau kebijakan nonaktif (pemicu manual operator); {"dry_run": true}
+// memaksa mode coba.
+func (a *API) handleRunAutoLeave(w http.ResponseWriter, r *http.Request) {
+	if a.AutoLeave == nil {
+		writeErr(w, http.StatusServiceUnavailable, "auto-leave not running")
+		return
+	}
+	var body struct {
+		AccountID string `json:"account_id"`
+		DryRun    bool   `json:"dry_run"`
+	}
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			writeErr(w, http.StatusBadRequest, "invalid JSON")
+			return
+		}
+	}
+	p, err := a.Store.AutoLeavePolicy()
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	p.DryRun = p.DryRun || body.DryRun
+	ctx, cancel := context.WithTimeout(r.Context(), 110*time.Second)
+	defer cancel()
+	var res autoleave.Result
+	if body.AccountID != "" {
+		if !a.accountExists(w, body.AccountID) {
+			return
+		}
+		res, err = a.AutoLeave.RunAccount(ctx, body.AccountID, p)
+	} else {
+		res, err = a.AutoLeave.RunAll(ctx, p)
+	}
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, res)
+}
@@ -0,0 +1,104 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"promote/internal/model"
+)
+
+// Konfigurasi error budget beserta status per akun di jendela berjalan.
+func (a *API) handleGetErrorBudget(w http.ResponseWriter, r *http.Request) {
+	budget, err := a.Store.ErrorBudget()
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	list, err := a.Store.AccountBudgets(time.Now().Add(-time.Duration(budget.WindowHours) * time.Hour))
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if list == nil {
+		list = []model.AccountBudget{}
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"budget": budget, "accounts": list})
+}
+
+// Ubah budget (field yang tidak dikirim tetap): {"max_fail_pct":5,"window_hours":24,"min_sends":20}.
+func (a *API) handleSetErrorBudget(w http.ResponseWriter, r *http.Request) {
+	budget, err := a.Store.ErrorBudget()
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	var req struct {
+		MaxFailPct  *float64 `json:"max_fail_pct"`
+		WindowHours *int     `json:"window_hours"`
+		MinSends    *int     `json:"min_sends"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErr(w, http.StatusBadRequest, "invalid JSON")
+		return
+	}
+	if req.MaxFailPct != nil {
+		budget.MaxFailPct = *req.MaxFailPct
+	}
+	budget.WindowHours = intOr(req.WindowHours, budget.WindowHours)
+	budget.MinSends = intOr(req.MinSends, budget.MinSends)
+	if err := budget.Validate(); err != nil {
+		writeErr(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := a.Store.SetErrorBudget(budget); err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, budget)
+}
+
+// Lanjutkan akun yang dijeda error budget secara manual. Budget akun tidak dievaluasi selama
+// override_hours (default = jendela budget) agar tidak langsung dijeda lagi oleh kegagalan lama.
+func (a *API) handleResumeErrorBudget(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	budget, err := a.Store.ErrorBudget()
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	var req struct {
+		OverrideHours *int `json:"override_hours"`
+	}
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeErr(w, http.StatusBadRequest, "invalid JSON")
+			return
+		}
+	}
+	hours := intOr(req.OverrideHours, budget.WindowHours)
+	if hours < 0 || hours > 24*30 {
+		writeErr(w, http.StatusBadRequest, "override_hours must be between 0 and 720")
+		return
+	}
+	var until *time.Time
+	if hours > 0 {
+		t := time.Now().Add(time.Duration(hours) * time.Hour)
+		until = &t
+	}
+	ok, err := a.Store.ResumeAccountBudget(id, until)
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if !ok {
+		writeErr(w, http.StatusNotFound, "account not found")
+		return
+	}
+	if a.Manager != nil {
+		a.Manager.EmitAccountEvent(id, "budget_resumed", "manual override")
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"resumed": id, "override_until": until})
+}
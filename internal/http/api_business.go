@@ -0,0 +1,151 @@
+package httpapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/mail"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/go-chi/chi/v5"
+
+	"promote/internal/model"
+	"promote/internal/storage"
+)
+
+// maxAboutLen batas panjang about WhatsApp.
+const maxAboutLen = 139
+
+// Profil WhatsApp Business semua akun (live, terkelola, dan field yang berbeda).
+func (a *API) handleListBusinessProfiles(w http.ResponseWriter, r *http.Request) {
+	list, err := a.Store.ListBusinessProfiles()
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if list == nil {
+		list = []model.AccountBusinessProfile{}
+	}
+	writeJSON(w, http.StatusOK, list)
+}
+
+func (a *API) handleGetBusinessProfile(w http.ResponseWriter, r *http.Request) {
+	p, err := a.Store.GetBusinessProfile(chi.URLParam(r, "id"))
+	if errors.Is(err, storage.ErrAccountNotFound) {
+		writeErr(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, p)
+}
+
+// syncBusinessProfile mengambil profil dari WhatsApp dan menyimpannya (termasuk error sync).
+func (a *API) syncBusinessProfile(ctx context.Context, id string) error {
+	live, isBusiness, err := a.Manager.BusinessProfile(ctx, id)
+	if err != nil {
+		_ = a.Store.SaveBusinessSync(id, false, model.BusinessProfile{}, err.Error())
+		return err
+	}
+	return a.Store.SaveBusinessSync(id, isBusiness, live, "")
+}
+
+// Sync profil bisnis akun dari WhatsApp (akun harus terhubung).
+func (a *API) handleSyncBusinessProfile(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	if _, err := a.Store.GetBusinessProfile(id); errors.Is(err, storage.ErrAccountNotFound) {
+		writeErr(w, http.StatusNotFound, err.Error())
+		return
+	} else if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if err := a.syncBusinessProfile(r.Context(), id); err != nil {
+		writeWAErr(w, err)
+		return
+	}
+	a.handleGetBusinessProfile(w, r)
+}
+
+// Ubah profil bisnis terkelola (field yang tidak dikirim tetap):
+// {"category":"Toko","address":"...","email":"...","catalog_url":"https://wa.me/c/62...","about":"..."}.
+// About langsung diterapkan ke WhatsApp bila akun terhubung; field lain belum bisa diubah lewat
+// WhatsApp Web dan dilaporkan di "manual" sampai diterapkan dari aplikasi WhatsApp Business.
+func (a *API) handleSetBusinessProfile(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	cur, err := a.Store.GetBusinessProfile(id)
+	if errors.Is(err, storage.ErrAccountNotFound) {
+		writeErr(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	var req struct {
+		Category   *string `json:"category"`
+		Address    *string `json:"address"`
+		Email      *string `json:"email"`
+		CatalogURL *string `json:"catalog_url"`
+		About      *string `json:"about"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErr(w, http.StatusBadRequest, "invalid JSON")
+		return
+	}
+	p := cur.Managed
+	for dst, src := range map[*string]*string{
+		&p.Category: req.Category, &p.Address: req.Address, &p.Email: req.Email,
+		&p.CatalogURL: req.CatalogURL, &p.About: req.About,
+	} {
+		if src != nil {
+			*dst = strings.TrimSpace(*src)
+		}
+	}
+	if p.Email != "" {
+		if _, err := mail.ParseAddress(p.Email); err != nil {
+			writeErr(w, http.StatusBadRequest, "email must be a valid address")
+			return
+		}
+	}
+	if p.CatalogURL != "" && !strings.HasPrefix(p.CatalogURL, "https://") && !strings.HasPrefix(p.CatalogURL, "http://") {
+		writeErr(w, http.StatusBadRequest, "catalog_url must be an http(s) URL")
+		return
+	}
+	if utf8.RuneCountInString(p.About) > maxAboutLen {
+		writeErr(w, http.StatusBadRequest, "about must be at most 139 characters")
+		return
+	}
+	if err := a.Store.SetManagedBusinessProfile(id, p); err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	applied := []string{}
+	pushErr := ""
+	if p.About != "" && p.About != cur.Live.About && a.Manager != nil {
+		ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+		defer cancel()
+		if err := a.Manager.SetAbout(ctx, id, p.About); err != nil {
+			pushErr = err.Error()
+		} else {
+			applied = append(applied, "about")
+			_ = a.syncBusinessProfile(ctx, id)
+		}
+	}
+	out, err := a.Store.GetBusinessProfile(id)
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	resp := map[string]any{"profile": out, "applied": applied, "manual": out.Drift}
+	if pushErr != "" {
+		resp["push_error"] = pushErr
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
@@ -0,0 +1,223 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+
+	"promote/internal/model"
+	"promote/internal/storage"
+)
+
+type upsertCampaignReq struct {
+	Name             string           `json:"name"`
+	Text             string           `json:"text"`
+	ImageURLs        []string         `json:"image_urls"`
+	VideoURLs        []string         `json:"video_urls"`
+	StickerURLs      []string         `json:"sticker_urls"`
+	DocURLs          []string         `json:"doc_urls"`
+	Enabled          bool             `json:"enabled"`
+	PerGroupVariants int              `json:"per_group_variants"`
+	Targeting        *model.Targeting `json:"targeting"`
+	Mode             string           `json:"mode"`
+	PromoteGroupID   string           `json:"promote_group_id"`
+}
+
+// crossPromoDefaultText teks bawaan campaign cross-promo tanpa teks.
+const crossPromoDefaultText = "Gabung juga di grup *{promo_name}* ({promo_members} anggota):\n{promo_link}"
+
+// decodeCampaign membaca dan memvalidasi body; false jika respons error sudah ditulis.
+func decodeCampaign(w http.ResponseWriter, r *http.Request) (model.Campaign, bool) {
+	var req upsertCampaignReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErr(w, http.StatusBadRequest, "invalid JSON")
+		return model.Campaign{}, false
+	}
+	c := model.Campaign{
+		Name:             strings.TrimSpace(req.Name),
+		Text:             req.Text,
+		ImageURLs:        cleanURLs(req.ImageURLs),
+		VideoURLs:        cleanURLs(req.VideoURLs),
+		StickerURLs:      cleanURLs(req.StickerURLs),
+		DocURLs:          cleanURLs(req.DocURLs),
+		Enabled:          req.Enabled,
+		PerGroupVariants: req.PerGroupVariants,
+		Targeting:        req.Targeting,
+		Mode:             strings.TrimSpace(req.Mode),
+		PromoteGroupID:   strings.TrimSpace(req.PromoteGroupID),
+	}
+	if c.Mode == "" {
+		c.Mode = model.CampaignModeGroup
+	}
+	if c.Name == "" {
+		writeErr(w, http.StatusBadRequest, "name required")
+		return c, false
+	}
+	if c.Mode == model.CampaignModeCrossPromo && strings.TrimSpace(c.Text) == "" {
+		c.Text = crossPromoDefaultText
+	}
+	if !c.HasContent() {
+		writeErr(w, http.StatusBadRequest, "text or media required")
+		return c, false
+	}
+	switch c.Mode {
+	case model.CampaignModeGroup:
+	case model.CampaignModeDM:
+		// DM hanya mengirim teks dan gambar pertama (lihat Sender.SendDirect).
+		if len(c.VideoURLs)+len(c.StickerURLs)+len(c.DocURLs) > 0 {
+			writeErr(w, http.StatusBadRequest, "dm campaigns support text and images only")
+			return c, false
+		}
+	case model.CampaignModeCrossPromo:
+		if c.PromoteGroupID == "" {
+			writeErr(w, http.StatusBadRequest, "promote_group_id required for crosspromo campaigns")
+			return c, false
+		}
+		if c.Targeting == nil || len(c.Targeting.IncludeTags) == 0 {
+			writeErr(w, http.StatusBadRequest, "crosspromo campaigns need targeting.include_tags")
+			return c, false
+		}
+	default:
+		writeErr(w, http.StatusBadRequest, "mode must be group, dm or crosspromo")
+		return c, false
+	}
+	if c.Targeting != nil {
+		if err := c.Targeting.Validate(); err != nil {
+			writeErr(w, http.StatusBadRequest, err.Error())
+			return c, false
+		}
+	}
+	return c, true
+}
+
+// promoteGroupExists memastikan grup yang dipromosikan campaign cross-promo ada; false jika
+// respons error sudah ditulis.
+func (a *API) promoteGroupExists(w http.ResponseWriter, c model.Campaign) bool {
+	if c.Mode != model.CampaignModeCrossPromo {
+		return true
+	}
+	exists, err := a.Store.GroupExists(c.PromoteGroupID)
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return false
+	}
+	if !exists {
+		writeErr(w, http.StatusBadRequest, "promote_group_id: group not found")
+		return false
+	}
+	return true
+}
+
+func cleanURLs(list []string) []string {
+	out := []string{}
+	for _, u := range list {
+		if u = strings.TrimSpace(u); u != "" {
+			out = append(out, u)
+		}
+	}
+	return out
+}
+
+func (a *API) handleListCampaigns(w http.ResponseWriter, r *http.Request) {
+	list, err := a.Store.ListCampaigns()
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if list == nil {
+		list = []model.Campaign{}
+	}
+	writeJSON(w, http.StatusOK, list)
+}
+
+func (a *API) handleGetCampaign(w http.ResponseWriter, r *http.Request) {
+	c, err := a.Store.GetCampaign(chi.URLParam(r, "id"))
+	if errors.Is(err, storage.ErrCampaignNotFound) {
+		writeErr(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, c)
+}
+
+func (a *API) handleCreateCampaign(w http.ResponseWriter, r *http.Request) {
+	c, ok := decodeCampaign(w, r)
+	if !ok || !a.promoteGroupExists(w, c) {
+		return
+	}
+	id, err := a.Store.CreateCampaign(c)
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusCreated, map[string]any{"id": id})
+}
+
+func (a *API) handleUpdateCampaign(w http.ResponseWriter, r *http.Request) {
+	c, ok := decodeCampaign(w, r)
+	if !ok || !a.promoteGroupExists(w, c) {
+		return
+	}
+	c.ID = chi.URLParam(r, "id")
+	err := a.Store.UpdateCampaign(c)
+	if errors.Is(err, storage.ErrCampaignNotFound) {
+		writeErr(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"updated": c.ID})
+}
+
+func (a *API) handleDeleteCampaign(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	err := a.Store.DeleteCampaign(id)
+	if errors.Is(err, storage.ErrCampaignNotFound) {
+		writeErr(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"deleted": id})
+}
+
+// Pasangan cross-promo yang sudah terkirim untuk grup yang dipromosikan campaign {id} (?limit=, default 200).
+func (a *API) handleListCrossPromos(w http.ResponseWriter, r *http.Request) {
+	c, err := a.Store.GetCampaign(chi.URLParam(r, "id"))
+	if errors.Is(err, storage.ErrCampaignNotFound) {
+		writeErr(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if c.Mode != model.CampaignModeCrossPromo || c.PromoteGroupID == "" {
+		writeErr(w, http.StatusBadRequest, "not a crosspromo campaign")
+		return
+	}
+	limit := 200
+	if n, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && n > 0 && n <= 1000 {
+		limit = n
+	}
+	list, err := a.Store.ListCrossPromos(c.PromoteGroupID, limit)
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if list == nil {
+		list = []model.CrossPromo{}
+	}
+	writeJSON(w, http.StatusOK, list)
+}
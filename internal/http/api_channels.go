@@ -0,0 +1,190 @@
+package httpapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"promote/internal/model"
+	"promote/internal/sender"
+	"promote/internal/storage"
+)
+
+// Daftar WhatsApp Channel hasil sync (?account_id= untuk satu akun).
+func (a *API) handleListChannels(w http.ResponseWriter, r *http.Request) {
+	list, err := a.Store.ListChannels(r.URL.Query().Get("account_id"))
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if list == nil {
+		list = []model.Channel{}
+	}
+	writeJSON(w, http.StatusOK, list)
+}
+
+// Sync channel milik akun (role owner/admin) dari WhatsApp; channel yang tidak lagi dimiliki dihapus.
+func (a *API) handleSyncChannels(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	if !a.accountExists(w, id) {
+		return
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), 60*time.Second)
+	defer cancel()
+	owned, err := a.Manager.OwnedChannels(ctx, id)
+	if err != nil {
+		writeWAErr(w, err)
+		return
+	}
+	removed, err := a.Store.SyncChannels(id, owned)
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	list, err := a.Store.ListChannels(id)
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if list == nil {
+		list = []model.Channel{}
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"synced": len(owned), "removed": removed, "channels": list})
+}
+
+// Aktif/nonaktifkan posting terjadwal ke channel: {"enabled":true}.
+func (a *API) handleSetChannelEnabled(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Enabled *bool `json:"enabled"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErr(w, http.StatusBadRequest, "invalid JSON")
+		return
+	}
+	if req.Enabled == nil {
+		writeErr(w, http.StatusBadRequest, "enabled required")
+		return
+	}
+	cid := chi.URLParam(r, "cid")
+	err := a.Store.SetChannelEnabled(cid, *req.Enabled)
+	if errors.Is(err, storage.ErrChannelNotFound) {
+		writeErr(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	ch, err := a.Store.GetChannel(cid)
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, ch)
+}
+
+// Posting manual ke channel: {"template_id":"..."} atau body kosong untuk template aktif acak.
+// Tidak terikat aturan limit channel.
+func (a *API) handlePostToChannel(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		TemplateID string `json:"template_id"`
+	}
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeErr(w, http.StatusBadRequest, "invalid JSON")
+			return
+		}
+	}
+	ch, err := a.Store.GetChannel(chi.URLParam(r, "cid"))
+	if errors.Is(err, storage.ErrChannelNotFound) {
+		writeErr(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), 2*time.Minute)
+	defer cancel()
+	if req.TemplateID != "" {
+		var t model.Template
+		t, err = a.Store.GetTemplate(req.TemplateID)
+		if err == nil {
+			err = a.Sender.PostToChannel(ctx, ch.AccountID, ch.ID, sender.TemplateContent(t))
+		}
+	} else {
+		err = a.Sender.PostRandomTemplateToChannel(ctx, ch.AccountID, ch.ID)
+	}
+	switch {
+	case err == nil:
+		writeJSON(w, http.StatusOK, map[string]any{"posted": ch.ID})
+	case errors.Is(err, storage.ErrTemplateNotFound):
+		writeErr(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, sender.ErrObserverAccount), errors.Is(err, sender.ErrSafeModeBroadcast):
+		writeErr(w, http.StatusConflict, err.Error())
+	case errors.Is(err, sender.ErrEmptyChannelPost):
+		writeErr(w, http.StatusBadRequest, err.Error())
+	default:
+		writeErr(w, http.StatusBadGateway, err.Error())
+	}
+}
+
+// Riwayat posting channel terbaru (?limit=50).
+func (a *API) handleListChannelPosts(w http.ResponseWriter, r *http.Request) {
+	limit := 50
+	if n, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && n > 0 && n <= 500 {
+		limit = n
+	}
+	list, err := a.Store.ListChannelPosts(chi.URLParam(r, "cid"), limit)
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if list == nil {
+		list = []model.ChannelPost{}
+	}
+	writeJSON(w, http.StatusOK, list)
+}
+
+// Aturan posting terjadwal channel (terpisah dari limit grup).
+func (a *API) handleGetChannelRules(w http.ResponseWriter, r *http.Request) {
+	rules, err := a.Store.ChannelRules()
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, rules)
+}
+
+// Ubah aturan channel (field yang tidak dikirim tetap): {"daily_limit":2,"min_interval_min":360}.
+func (a *API) handleSetChannelRules(w http.ResponseWriter, r *http.Request) {
+	rules, err := a.Store.ChannelRules()
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	var req struct {
+		DailyLimit     *int `json:"daily_limit"`
+		MinIntervalMin *int `json:"min_interval_min"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErr(w, http.StatusBadRequest, "invalid JSON")
+		return
+	}
+	rules.DailyLimit = intOr(req.DailyLimit, rules.DailyLimit)
+	rules.MinIntervalMin = intOr(req.MinIntervalMin, rules.MinIntervalMin)
+	if err := rules.Validate(); err != nil {
+		writeErr(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := a.Store.SetChannelRules(rules); err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, rules)
+}
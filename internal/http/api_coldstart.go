@@ -0,0 +1,72 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"promote/internal/model"
+)
+
+// writeColdStartPlan menulis batas ramp beserta rencana per akun.
+func (a *API) writeColdStartPlan(w http.ResponseWriter, perDay, planned int) {
+	list, err := a.Store.ColdStartPlan(time.Now())
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if list == nil {
+		list = []model.AccountRamp{}
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"per_day": perDay, "planned": planned, "accounts": list})
+}
+
+// Rencana ramp cold-start: grup baru per akun per hari hingga semua grup cold terkirimi.
+func (a *API) handleGetColdStart(w http.ResponseWriter, r *http.Request) {
+	perDay, err := a.Store.ColdStartPerDay()
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	a.writeColdStartPlan(w, perDay, 0)
+}
+
+// Ubah batas ramp {"per_day":30} (0 = nonaktif); rencana yang belum jatuh tempo disusun ulang.
+func (a *API) handleSetColdStart(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		PerDay *int `json:"per_day"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErr(w, http.StatusBadRequest, "invalid JSON")
+		return
+	}
+	if req.PerDay == nil || *req.PerDay < 0 || *req.PerDay > 1000 {
+		writeErr(w, http.StatusBadRequest, "per_day must be between 0 and 1000")
+		return
+	}
+	if err := a.Store.SetColdStartPerDay(*req.PerDay); err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	n, err := a.Store.PlanColdStart(*req.PerDay, time.Now())
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	a.writeColdStartPlan(w, *req.PerDay, n)
+}
+
+// Jalankan planner sekarang (tanpa menunggu tick scheduler), mis. tepat setelah import grup.
+func (a *API) handlePlanColdStart(w http.ResponseWriter, r *http.Request) {
+	perDay, err := a.Store.ColdStartPerDay()
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	n, err := a.Store.PlanColdStart(perDay, time.Now())
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	a.writeColdStartPlan(w, perDay, n)
+}
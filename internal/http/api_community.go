@@ -0,0 +1,44 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"promote/internal/storage"
+)
+
+// Perlakuan grup komunitas WhatsApp: apakah grup pengumuman ikut broadcast, plus jumlah grup per peran.
+func (a *API) handleGetCommunitySettings(w http.ResponseWriter, r *http.Request) {
+	on, err := a.Store.SettingBool(storage.SettingCommunityAnnouncementsEligible)
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	roles, excluded, err := a.Store.CommunityCounts()
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"announcements_eligible": on, "roles": roles, "excluded": excluded})
+}
+
+// Ubah perlakuan grup pengumuman komunitas: {"announcements_eligible":true}. Grup induk komunitas
+// tetap dikecualikan karena tidak bisa dikirimi pesan.
+func (a *API) handleSetCommunitySettings(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		AnnouncementsEligible *bool `json:"announcements_eligible"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErr(w, http.StatusBadRequest, "invalid JSON")
+		return
+	}
+	if req.AnnouncementsEligible == nil {
+		writeErr(w, http.StatusBadRequest, "announcements_eligible required")
+		return
+	}
+	if err := a.Store.SetCommunityAnnouncementsEligible(*req.AnnouncementsEligible); err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	a.handleGetCommunitySettings(w, r)
+}
@@ -0,0 +1,127 @@
+package httpapi
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"promote/internal/compliance"
+	"promote/internal/model"
+)
+
+type upsertComplianceRuleReq struct {
+	Phrase  string `json:"phrase"`
+	Action  string `json:"action"` // flag|pause
+	Enabled *bool  `json:"enabled"`
+}
+
+func (a *API) handleListComplianceRules(w http.ResponseWriter, r *http.Request) {
+	list, err := a.Store.ListComplianceRules()
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if list == nil {
+		list = []model.ComplianceRule{}
+	}
+	writeJSON(w, http.StatusOK, list)
+}
+
+func (a *API) handleUpsertComplianceRule(w http.ResponseWriter, r *http.Request) {
+	var req upsertComplianceRuleReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErr(w, http.StatusBadRequest, "invalid JSON")
+		return
+	}
+	phrase := strings.ToLower(strings.TrimSpace(req.Phrase))
+	if phrase == "" {
+		writeErr(w, http.StatusBadRequest, "phrase required")
+		return
+	}
+	if req.Action == "" {
+		req.Action = model.ComplianceFlag
+	}
+	if req.Action != model.ComplianceFlag && req.Action != model.CompliancePause {
+		writeErr(w, http.StatusBadRequest, "action must be flag or pause")
+		return
+	}
+	enabled := true
+	if req.Enabled != nil {
+		enabled = *req.Enabled
+	}
+	id, err := a.Store.UpsertComplianceRule(phrase, req.Action, enabled)
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"id": id})
+}
+
+func (a *API) handleDeleteComplianceRule(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		writeErr(w, http.StatusBadRequest, "invalid rule id")
+		return
+	}
+	n, err := a.Store.DeleteComplianceRule(id)
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if n == 0 {
+		writeErr(w, http.StatusNotFound, "rule not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"deleted": n})
+}
+
+// Grup yang deskripsinya cocok aturan compliance (opsional ?account_id=).
+func (a *API) handleListComplianceFlags(w http.ResponseWriter, r *http.Request) {
+	list, err := a.Store.ListFlaggedGroups(r.URL.Query().Get("account_id"))
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if list == nil {
+		list = []model.FlaggedGroup{}
+	}
+	writeJSON(w, http.StatusOK, list)
+}
+
+// Jalankan scan sekarang: {"account_id": "..."} untuk satu akun, kosong untuk semua akun terhubung.
+func (a *API) handleComplianceScan(w http.ResponseWriter, r *http.Request) {
+	if a.Compliance == nil {
+		writeErr(w, http.StatusServiceUnavailable, "compliance scanner not running")
+		return
+	}
+	var body struct {
+		AccountID string `json:"account_id"`
+	}
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			writeErr(w, http.StatusBadRequest, "invalid JSON")
+			return
+		}
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), 110*time.Second)
+	defer cancel()
+	var (
+		res compliance.Result
+		err error
+	)
+	if body.AccountID != "" {
+		res, err = a.Compliance.ScanAccount(ctx, body.AccountID)
+	} else {
+		res, err = a.Compliance.ScanAll(ctx)
+	}
+	if err != nil {
+		writeErr(w, http.StatusBadGateway, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, res)
+}
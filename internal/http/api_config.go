@@ -0,0 +1,130 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"promote/internal/lint"
+	"promote/internal/model"
+	"promote/internal/storage"
+)
+
+// configSpecReq format JSON storage.ConfigSpec. Bagian yang tidak dikirim (atau null) tidak
+// disentuh; bagian yang dikirim menggantikan seluruh isinya.
+type configSpecReq struct {
+	Templates []upsertTemplateReq `json:"templates"`
+	Tags      []string            `json:"tags"`
+	Schedules []configScheduleReq `json:"schedules"`
+	Settings  map[string]*string  `json:"settings"`
+	DryRun    bool                `json:"dry_run,omitempty"`
+}
+
+// configScheduleReq jadwal dengan campaign dirujuk nama dan akun dirujuk ID, label: atau msisdn:.
+type configScheduleReq struct {
+	Campaign string `json:"campaign"`
+	Account  string `json:"account"`
+	upsertScheduleReq
+}
+
+// Export konfigurasi deklaratif (template, tag, jadwal, settings) untuk diterapkan ke instance lain.
+func (a *API) handleExportConfig(w http.ResponseWriter, r *http.Request) {
+	spec, err := a.Store.ExportConfig(r.Context())
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	out := configSpecReq{
+		Templates: make([]upsertTemplateReq, 0, len(spec.Templates)),
+		Tags:      spec.Tags,
+		Schedules: make([]configScheduleReq, 0, len(spec.Schedules)),
+		Settings:  spec.Settings,
+	}
+	for _, t := range spec.Templates {
+		out.Templates = append(out.Templates, templateReq(t))
+	}
+	for _, sp := range spec.Schedules {
+		sch := sp.Schedule
+		enabled := sch.Enabled
+		out.Schedules = append(out.Schedules, configScheduleReq{Campaign: sp.Campaign, Account: sp.Account,
+			upsertScheduleReq: upsertScheduleReq{
+				BatchSize: &sch.BatchSize, StartHour: &sch.StartHour, EndHour: &sch.EndHour,
+				MinDelaySec: &sch.MinDelaySec, MaxDelaySec: &sch.MaxDelaySec,
+				DaysMask: sch.DaysMask, DailyLimit: &sch.DailyLimit, Enabled: &enabled,
+			}})
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+// Terapkan spec konfigurasi: DB direkonsiliasi agar cocok dan respons berisi objek yang dibuat,
+// diubah, dan dihapus per jenis. {"dry_run": true} hanya melaporkan tanpa menyimpan.
+func (a *API) handleApplyConfig(w http.ResponseWriter, r *http.Request) {
+	var req configSpecReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErr(w, http.StatusBadRequest, "invalid JSON")
+		return
+	}
+	spec := storage.ConfigSpec{Tags: req.Tags, Settings: req.Settings}
+	if req.Templates != nil {
+		spec.Templates = make([]model.Template, 0, len(req.Templates))
+		for _, tr := range req.Templates {
+			if msg := tr.check(); msg != "" {
+				writeErr(w, http.StatusBadRequest, fmt.Sprintf("template %q: %s", tr.Name, msg))
+				return
+			}
+			t := tr.template()
+			rep := lint.Template(t)
+			t.Lint = &rep
+			spec.Templates = append(spec.Templates, t)
+		}
+	}
+	if req.Schedules != nil {
+		spec.Schedules = make([]storage.ScheduleSpec, 0, len(req.Schedules))
+		for _, sr := range req.Schedules {
+			sch := sr.schedule()
+			if err := sch.Validate(); err != nil {
+				writeErr(w, http.StatusBadRequest, fmt.Sprintf("schedule %s @ %s: %v", sr.Campaign, sr.Account, err))
+				return
+			}
+			spec.Schedules = append(spec.Schedules, storage.ScheduleSpec{Campaign: sr.Campaign, Account: sr.Account, Schedule: sch})
+		}
+	}
+	rep, err := a.Store.ApplyConfig(r.Context(), spec, req.DryRun)
+	if errors.Is(err, storage.ErrInvalidConfig) {
+		writeErr(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, rep)
+}
+
+// templateReq kebalikan upsertTemplateReq.template.
+func templateReq(t model.Template) upsertTemplateReq {
+	return upsertTemplateReq{
+		Name:             t.Name,
+		TextOnly:         t.TextOnly,
+		ImageURLs:        t.ImageURLs,
+		ImageCaption:     t.ImageCaption,
+		VideoURLs:        t.VideoURLs,
+		VideoCaption:     t.VideoCaption,
+		Products:         t.Products,
+		GifURLs:          t.GifURLs,
+		AudioURLs:        t.AudioURLs,
+		VoiceURLs:        t.VoiceURLs,
+		StickerURLs:      t.StickerURLs,
+		DocURLs:          t.DocURLs,
+		DocCaption:       t.DocCaption,
+		LinkPreview:      t.LinkPreview,
+		ContactName:      t.ContactName,
+		ContactPhone:     t.ContactPhone,
+		MediaFailPolicy:  t.MediaFailPolicy,
+		FallbackImageURL: t.FallbackImageURL,
+		MentionAll:       t.MentionAll,
+		Languages:        t.Languages,
+		Enabled:          t.Enabled,
+	}
+}
@@ -0,0 +1,38 @@
+package httpapi
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"promote/internal/cron"
+)
+
+// Status semua task housekeeping (jadwal berikutnya, hasil terakhir).
+func (a *API) handleCronStatus(w http.ResponseWriter, r *http.Request) {
+	if a.Cron == nil {
+		writeErr(w, http.StatusServiceUnavailable, "cron not running")
+		return
+	}
+	writeJSON(w, http.StatusOK, a.Cron.Status())
+}
+
+// Jalankan satu task sekarang (asinkron); 409 jika task masih berjalan.
+func (a *API) handleCronRun(w http.ResponseWriter, r *http.Request) {
+	if a.Cron == nil {
+		writeErr(w, http.StatusServiceUnavailable, "cron not running")
+		return
+	}
+	name := chi.URLParam(r, "name")
+	switch err := a.Cron.Trigger(name); {
+	case errors.Is(err, cron.ErrUnknownTask):
+		writeErr(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, cron.ErrRunning):
+		writeErr(w, http.StatusConflict, err.Error())
+	case err != nil:
+		writeErr(w, http.StatusInternalServerError, err.Error())
+	default:
+		writeJSON(w, http.StatusAccepted, map[string]any{"triggered": name})
+	}
+}
@@ -0,0 +1,51 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"promote/internal/model"
+)
+
+// Daftar digest harian: ?day=YYYY-MM-DD&account_id=...&limit=N
+func (a *API) handleListDigests(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	limit, _ := strconv.Atoi(q.Get("limit"))
+	list, err := a.Store.ListDigests(q.Get("day"), q.Get("account_id"), limit)
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if list == nil {
+		list = []model.Digest{}
+	}
+	writeJSON(w, http.StatusOK, list)
+}
+
+// Buat (ulang) digest: {"day": "YYYY-MM-DD", "push": true}; day kosong = hari ini (WIB).
+func (a *API) handleRunDigest(w http.ResponseWriter, r *http.Request) {
+	if a.Digest == nil {
+		writeErr(w, http.StatusServiceUnavailable, "digest runner not running")
+		return
+	}
+	var body struct {
+		Day  string `json:"day"`
+		Push bool   `json:"push"`
+	}
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			writeErr(w, http.StatusBadRequest, "invalid JSON")
+			return
+		}
+	}
+	list, err := a.Digest.Run(r.Context(), body.Day, body.Push)
+	if err != nil {
+		writeErr(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if list == nil {
+		list = []model.Digest{}
+	}
+	writeJSON(w, http.StatusOK, list)
+}
@@ -0,0 +1,258 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+
+	"promote/internal/dm"
+	"promote/internal/model"
+	"promote/internal/storage"
+)
+
+type dmSuppressionReq struct {
+	Numbers []string `json:"numbers"`
+	Reason  string   `json:"reason"`
+}
+
+type dmTargetsReq struct {
+	RunID string `json:"run_id"`
+}
+
+type dmLimitReq struct {
+	DMDailyLimit int `json:"dm_daily_limit"`
+}
+
+type dmPreflightReq struct {
+	AccountID   string   `json:"account_id"`
+	Numbers     []string `json:"numbers"`
+	GroupIDs    []string `json:"group_ids"`
+	AudienceIDs []string `json:"audience_ids"`
+}
+
+func (a *API) handleListDMSuppressions(w http.ResponseWriter, r *http.Request) {
+	list, err := a.Store.ListDMSuppressions()
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if list == nil {
+		list = []model.DMSuppression{}
+	}
+	writeJSON(w, http.StatusOK, list)
+}
+
+// Tambah nomor ke daftar supresi; nomor tidak valid dikembalikan di "invalid".
+func (a *API) handleAddDMSuppressions(w http.ResponseWriter, r *http.Request) {
+	var req dmSuppressionReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErr(w, http.StatusBadRequest, "invalid JSON")
+		return
+	}
+	added, invalid := 0, []string{}
+	for _, in := range req.Numbers {
+		n := storage.NormalizeNumber(in)
+		if n == "" {
+			invalid = append(invalid, in)
+			continue
+		}
+		if err := a.Store.AddDMSuppression(n, strings.TrimSpace(req.Reason), model.SuppressionManual); err != nil {
+			writeErr(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		added++
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"added": added, "invalid": invalid})
+}
+
+func (a *API) handleRemoveDMSuppression(w http.ResponseWriter, r *http.Request) {
+	n := storage.NormalizeNumber(chi.URLParam(r, "number"))
+	ok, err := a.Store.RemoveDMSuppression(n)
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if !ok {
+		writeErr(w, http.StatusNotFound, "number not suppressed")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"removed": n})
+}
+
+// Preflight audiens DM untuk campaign (numbers, group_ids dan/atau audience_ids): hasilnya
+// disimpan sebagai snapshot dm_runs.
+func (a *API) handleDMPreflight(w http.ResponseWriter, r *http.Request) {
+	if a.DMPreflight == nil {
+		writeErr(w, http.StatusServiceUnavailable, "dm preflight not available")
+		return
+	}
+	campaignID := chi.URLParam(r, "id")
+	if _, err := a.Store.GetCampaign(campaignID); err != nil {
+		if errors.Is(err, storage.ErrCampaignNotFound) {
+			writeErr(w, http.StatusNotFound, err.Error())
+		} else {
+			writeErr(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+	var req dmPreflightReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErr(w, http.StatusBadRequest, "invalid JSON")
+		return
+	}
+	if req.AccountID == "" {
+		writeErr(w, http.StatusBadRequest, "account_id required")
+		return
+	}
+	run, err := a.DMPreflight.Run(r.Context(), campaignID, req.AccountID, req.Numbers, req.GroupIDs, req.AudienceIDs)
+	if errors.Is(err, dm.ErrEmptyAudience) || errors.Is(err, storage.ErrAudienceNotFound) {
+		writeErr(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err != nil {
+		writeErr(w, http.StatusBadGateway, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusCreated, run)
+}
+
+func (a *API) handleListDMRuns(w http.ResponseWriter, r *http.Request) {
+	list, err := a.Store.ListDMRuns(chi.URLParam(r, "id"))
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if list == nil {
+		list = []model.DMRun{}
+	}
+	writeJSON(w, http.StatusOK, list)
+}
+
+func (a *API) handleGetDMRun(w http.ResponseWriter, r *http.Request) {
+	run, err := a.Store.GetDMRun(chi.URLParam(r, "id"))
+	if errors.Is(err, storage.ErrDMRunNotFound) {
+		writeErr(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, run)
+}
+
+// Antrekan audiens snapshot preflight (run_id) sebagai target campaign mode dm; dikirim
+// scheduler lewat akun snapshot tersebut. Nomor yang sudah ada di campaign dilewati.
+func (a *API) handleAddDMTargets(w http.ResponseWriter, r *http.Request) {
+	campaignID := chi.URLParam(r, "id")
+	c, err := a.Store.GetCampaign(campaignID)
+	if errors.Is(err, storage.ErrCampaignNotFound) {
+		writeErr(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if c.Mode != model.CampaignModeDM {
+		writeErr(w, http.StatusConflict, "campaign mode is not dm")
+		return
+	}
+	var req dmTargetsReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErr(w, http.StatusBadRequest, "invalid JSON")
+		return
+	}
+	if req.RunID == "" {
+		writeErr(w, http.StatusBadRequest, "run_id required")
+		return
+	}
+	run, err := a.Store.GetDMRun(req.RunID)
+	if errors.Is(err, storage.ErrDMRunNotFound) {
+		writeErr(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if run.CampaignID != campaignID {
+		writeErr(w, http.StatusBadRequest, "dm run belongs to another campaign")
+		return
+	}
+	observer, err := a.Store.IsObserverAccount(run.AccountID)
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if observer {
+		writeErr(w, http.StatusConflict, "account is in observer mode")
+		return
+	}
+	added, err := a.Store.AddDMTargets(campaignID, run.AccountID, run.ID, run.Audience)
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"added": added, "duplicates": len(run.Audience) - added, "account_id": run.AccountID})
+}
+
+// Target DM campaign beserta jumlah per status (?status=pending|sent|failed|skipped, ?limit=500).
+func (a *API) handleListDMTargets(w http.ResponseWriter, r *http.Request) {
+	campaignID := chi.URLParam(r, "id")
+	limit := 500
+	if n, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && n > 0 && n <= 5000 {
+		limit = n
+	}
+	list, err := a.Store.ListDMTargets(campaignID, r.URL.Query().Get("status"), limit)
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	counts, err := a.Store.DMTargetCounts(campaignID)
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if list == nil {
+		list = []model.DMTarget{}
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"by_status": counts, "targets": list})
+}
+
+// Batalkan semua target DM yang belum terkirim.
+func (a *API) handleCancelDMTargets(w http.ResponseWriter, r *http.Request) {
+	n, err := a.Store.CancelDMTargets(chi.URLParam(r, "id"))
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"cancelled": n})
+}
+
+// Ubah limit DM harian akun (terpisah dari daily_limit kiriman grup).
+func (a *API) handleSetDMLimit(w http.ResponseWriter, r *http.Request) {
+	var req dmLimitReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErr(w, http.StatusBadRequest, "invalid JSON")
+		return
+	}
+	if req.DMDailyLimit < 0 || req.DMDailyLimit > 1000 {
+		writeErr(w, http.StatusBadRequest, "dm_daily_limit must be between 0 and 1000")
+		return
+	}
+	err := a.Store.SetDMDailyLimit(chi.URLParam(r, "id"), req.DMDailyLimit)
+	if errors.Is(err, storage.ErrAccountNotFound) {
+		writeErr(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"dm_daily_limit": req.DMDailyLimit})
+}
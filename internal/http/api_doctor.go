@@ -0,0 +1,56 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"promote/internal/doctor"
+)
+
+// Laporan integritas data (referensi yatim, sesi tanpa akun, template dengan upload hilang).
+func (a *API) handleDoctorCheck(w http.ResponseWriter, r *http.Request) {
+	if a.Doctor == nil {
+		writeErr(w, http.StatusServiceUnavailable, "doctor not configured")
+		return
+	}
+	rep, err := a.Doctor.Check()
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, rep)
+}
+
+// Jalankan perbaikan: {"checks":["foreign_keys","orphan_sessions","missing_uploads"]} (kosong = semua).
+// Mengembalikan hasil per cek dan laporan setelah perbaikan.
+func (a *API) handleDoctorRepair(w http.ResponseWriter, r *http.Request) {
+	if a.Doctor == nil {
+		writeErr(w, http.StatusServiceUnavailable, "doctor not configured")
+		return
+	}
+	var req struct {
+		Checks []string `json:"checks"`
+	}
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeErr(w, http.StatusBadRequest, "invalid JSON")
+			return
+		}
+	}
+	res, err := a.Doctor.Repair(req.Checks)
+	if errors.Is(err, doctor.ErrUnknownCheck) {
+		writeErr(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	rep, err := a.Doctor.Check()
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"repairs": res, "report": rep})
+}
@@ -0,0 +1,134 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+
+	"promote/internal/dynvar"
+	"promote/internal/model"
+	"promote/internal/storage"
+)
+
+type upsertDynamicVarReq struct {
+	URLTemplate string `json:"url_template"`
+	JSONPath    string `json:"json_path"`
+	TimeoutMs   *int   `json:"timeout_ms"`
+	CacheTTLSec *int   `json:"cache_ttl_sec"`
+	Fallback    string `json:"fallback"`
+	Enabled     *bool  `json:"enabled"`
+}
+
+// resolver mengembalikan resolver milik sender agar cache-nya ikut dibersihkan saat config berubah.
+func (a *API) resolver() *dynvar.Resolver {
+	if a.Sender.Vars != nil {
+		return a.Sender.Vars
+	}
+	return dynvar.New(a.Store)
+}
+
+func (a *API) handleListDynamicVars(w http.ResponseWriter, r *http.Request) {
+	list, err := a.Store.ListDynamicVars()
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if list == nil {
+		list = []model.DynamicVar{}
+	}
+	writeJSON(w, http.StatusOK, list)
+}
+
+// Buat/ganti variabel dinamis; template memakai {name:key}, url_template memakai {key}.
+func (a *API) handleUpsertDynamicVar(w http.ResponseWriter, r *http.Request) {
+	name, err := storage.NormalizeDynamicVarName(chi.URLParam(r, "name"))
+	if err != nil {
+		writeErr(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	var req upsertDynamicVarReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErr(w, http.StatusBadRequest, "invalid JSON")
+		return
+	}
+	v := model.DynamicVar{
+		Name:        name,
+		URLTemplate: strings.TrimSpace(req.URLTemplate),
+		JSONPath:    strings.Trim(strings.TrimSpace(req.JSONPath), "."),
+		TimeoutMs:   intOr(req.TimeoutMs, 3000),
+		CacheTTLSec: intOr(req.CacheTTLSec, 300),
+		Fallback:    req.Fallback,
+		Enabled:     req.Enabled == nil || *req.Enabled,
+	}
+	if u, err := url.Parse(strings.ReplaceAll(v.URLTemplate, "{key}", "x")); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		writeErr(w, http.StatusBadRequest, "url_template must be an http(s) URL")
+		return
+	}
+	if v.TimeoutMs < 100 || v.TimeoutMs > 30000 {
+		writeErr(w, http.StatusBadRequest, "timeout_ms must be between 100 and 30000")
+		return
+	}
+	if v.CacheTTLSec < 0 {
+		writeErr(w, http.StatusBadRequest, "cache_ttl_sec must be >= 0")
+		return
+	}
+	if err := a.Store.UpsertDynamicVar(v); err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	a.resolver().Invalidate(name)
+	saved, err := a.Store.GetDynamicVar(name)
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, saved)
+}
+
+func (a *API) handleDeleteDynamicVar(w http.ResponseWriter, r *http.Request) {
+	name := chi.URLParam(r, "name")
+	if err := a.Store.DeleteDynamicVar(name); err != nil {
+		if errors.Is(err, storage.ErrDynamicVarNotFound) {
+			writeErr(w, http.StatusNotFound, err.Error())
+			return
+		}
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	a.resolver().Invalidate(name)
+	writeJSON(w, http.StatusOK, map[string]any{"deleted": name})
+}
+
+// Uji lookup tanpa cache: {"key": "sku123"}.
+func (a *API) handleTestDynamicVar(w http.ResponseWriter, r *http.Request) {
+	v, err := a.Store.GetDynamicVar(chi.URLParam(r, "name"))
+	if err != nil {
+		if errors.Is(err, storage.ErrDynamicVarNotFound) {
+			writeErr(w, http.StatusNotFound, err.Error())
+			return
+		}
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	var req struct {
+		Key string `json:"key"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErr(w, http.StatusBadRequest, "invalid JSON")
+		return
+	}
+	if strings.TrimSpace(req.Key) == "" {
+		writeErr(w, http.StatusBadRequest, "key required")
+		return
+	}
+	val, err := a.resolver().Fetch(r.Context(), v, strings.TrimSpace(req.Key))
+	if err != nil {
+		writeJSON(w, http.StatusOK, map[string]any{"ok": false, "error": err.Error(), "fallback": v.Fallback})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"ok": true, "value": val})
+}
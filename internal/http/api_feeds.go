@@ -0,0 +1,184 @@
+package httpapi
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"promote/internal/model"
+)
+
+type upsertFeedReq struct {
+	CampaignID  string `json:"campaign_id"`
+	Name        string `json:"name"`
+	URL         string `json:"url"`
+	Mode        string `json:"mode"` // approval|auto
+	Enabled     *bool  `json:"enabled"`
+	PollMinutes int    `json:"poll_minutes"`
+}
+
+func (req upsertFeedReq) toFeed() (model.Feed, string) {
+	u, err := url.Parse(strings.TrimSpace(req.URL))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return model.Feed{}, "valid http(s) url required"
+	}
+	if req.Mode != "" && req.Mode != model.FeedModeApproval && req.Mode != model.FeedModeAuto {
+		return model.Feed{}, "mode must be approval or auto"
+	}
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		name = u.Host
+	}
+	enabled := true
+	if req.Enabled != nil {
+		enabled = *req.Enabled
+	}
+	return model.Feed{
+		CampaignID:  strings.TrimSpace(req.CampaignID),
+		Name:        name,
+		URL:         u.String(),
+		Mode:        req.Mode,
+		Enabled:     enabled,
+		PollMinutes: req.PollMinutes,
+	}, ""
+}
+
+func (a *API) handleListFeeds(w http.ResponseWriter, r *http.Request) {
+	list, err := a.Store.ListFeeds()
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if list == nil {
+		list = []model.Feed{}
+	}
+	writeJSON(w, http.StatusOK, list)
+}
+
+func (a *API) handleCreateFeed(w http.ResponseWriter, r *http.Request) {
+	var req upsertFeedReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErr(w, http.StatusBadRequest, "invalid JSON")
+		return
+	}
+	f, msg := req.toFeed()
+	if msg != "" {
+		writeErr(w, http.StatusBadRequest, msg)
+		return
+	}
+	id, err := a.Store.CreateFeed(f)
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusCreated, map[string]any{"id": id})
+}
+
+func (a *API) handleUpdateFeed(w http.ResponseWriter, r *http.Request) {
+	var req upsertFeedReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErr(w, http.StatusBadRequest, "invalid JSON")
+		return
+	}
+	f, msg := req.toFeed()
+	if msg != "" {
+		writeErr(w, http.StatusBadRequest, msg)
+		return
+	}
+	f.ID = chi.URLParam(r, "id")
+	n, err := a.Store.UpdateFeed(f)
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if n == 0 {
+		writeErr(w, http.StatusNotFound, "feed not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"updated": n})
+}
+
+func (a *API) handleDeleteFeed(w http.ResponseWriter, r *http.Request) {
+	n, err := a.Store.DeleteFeed(chi.URLParam(r, "id"))
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if n == 0 {
+		writeErr(w, http.StatusNotFound, "feed not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"deleted": n})
+}
+
+// Poll manual sebuah feed (abaikan poll_minutes).
+func (a *API) handlePollFeed(w http.ResponseWriter, r *http.Request) {
+	if a.Feeds == nil {
+		writeErr(w, http.StatusServiceUnavailable, "feed watcher not running")
+		return
+	}
+	f, err := a.Store.GetFeed(chi.URLParam(r, "id"))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeErr(w, http.StatusNotFound, "feed not found")
+			return
+		}
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), 90*time.Second)
+	defer cancel()
+	n, err := a.Feeds.Poll(ctx, f)
+	if err != nil {
+		writeErr(w, http.StatusBadGateway, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"created": n})
+}
+
+func (a *API) handleListFeedItems(w http.ResponseWriter, r *http.Request) {
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	list, err := a.Store.ListFeedItems(chi.URLParam(r, "id"), r.URL.Query().Get("status"), limit)
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if list == nil {
+		list = []model.FeedItem{}
+	}
+	writeJSON(w, http.StatusOK, list)
+}
+
+func (a *API) handleApproveFeedItem(w http.ResponseWriter, r *http.Request) {
+	a.reviewFeedItem(w, r, true)
+}
+
+func (a *API) handleRejectFeedItem(w http.ResponseWriter, r *http.Request) {
+	a.reviewFeedItem(w, r, false)
+}
+
+func (a *API) reviewFeedItem(w http.ResponseWriter, r *http.Request, approve bool) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "itemID"), 10, 64)
+	if err != nil {
+		writeErr(w, http.StatusBadRequest, "invalid item id")
+		return
+	}
+	n, err := a.Store.ReviewFeedItem(id, approve)
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if n == 0 {
+		writeErr(w, http.StatusNotFound, "feed item not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"updated": n, "approved": approve})
+}
@@ -0,0 +1,55 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"promote/internal/model"
+)
+
+// Policy unduhan media remote (host izin/tolak, alamat privat, ukuran, redirect, konkurensi per host).
+func (a *API) handleGetFetchPolicy(w http.ResponseWriter, r *http.Request) {
+	p, err := a.Store.FetchPolicy()
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, p)
+}
+
+// Ubah policy unduhan; field yang tidak dikirim tetap. {"reset":true} kembali ke bawaan.
+func (a *API) handleSetFetchPolicy(w http.ResponseWriter, r *http.Request) {
+	p, err := a.Store.FetchPolicy()
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	var raw json.RawMessage
+	if err := json.NewDecoder(r.Body).Decode(&raw); err != nil {
+		writeErr(w, http.StatusBadRequest, "invalid JSON")
+		return
+	}
+	var req struct {
+		Reset bool `json:"reset"`
+	}
+	_ = json.Unmarshal(raw, &req)
+	if req.Reset {
+		p = model.DefaultFetchPolicy()
+	} else if err := json.Unmarshal(raw, &p); err != nil {
+		writeErr(w, http.StatusBadRequest, "invalid JSON")
+		return
+	}
+	p.Normalize()
+	if err := p.Validate(); err != nil {
+		writeErr(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := a.Store.SetFetchPolicy(p); err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if a.Sender != nil {
+		a.Sender.SetFetchPolicy(p)
+	}
+	writeJSON(w, http.StatusOK, p)
+}
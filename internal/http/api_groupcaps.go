@@ -0,0 +1,79 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"promote/internal/model"
+)
+
+// Batas kiriman default per grup {"per_day":1,"per_week":3} (0 = tanpa batas).
+func (a *API) handleGetGroupSendCaps(w http.ResponseWriter, r *http.Request) {
+	c, err := a.Store.GroupSendCaps()
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, c)
+}
+
+func (a *API) handleSetGroupSendCaps(w http.ResponseWriter, r *http.Request) {
+	var c model.GroupSendCaps
+	if err := json.NewDecoder(r.Body).Decode(&c); err != nil {
+		writeErr(w, http.StatusBadRequest, "invalid JSON")
+		return
+	}
+	if err := c.Validate(); err != nil {
+		writeErr(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := a.Store.SetGroupSendCaps(c); err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, c)
+}
+
+// Pemakaian batas kiriman grup: kiriman sukses semua akun hari ini dan 7 hari kuota terakhir.
+func (a *API) handleGetGroupCaps(w http.ResponseWriter, r *http.Request) {
+	gid, ok := a.groupFromURL(w, r)
+	if !ok {
+		return
+	}
+	a.writeGroupCaps(w, gid)
+}
+
+// Batas khusus grup {"max_per_day":1,"max_per_week":2}; null = kembali ke batas default,
+// 0 = tanpa batas untuk grup ini.
+func (a *API) handleSetGroupCaps(w http.ResponseWriter, r *http.Request) {
+	gid, ok := a.groupFromURL(w, r)
+	if !ok {
+		return
+	}
+	var req struct {
+		MaxPerDay  *int `json:"max_per_day"`
+		MaxPerWeek *int `json:"max_per_week"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErr(w, http.StatusBadRequest, "invalid JSON")
+		return
+	}
+	if (req.MaxPerDay != nil && *req.MaxPerDay < 0) || (req.MaxPerWeek != nil && *req.MaxPerWeek < 0) {
+		writeErr(w, http.StatusBadRequest, "max_per_day and max_per_week must be >= 0")
+		return
+	}
+	if err := a.Store.SetGroupCaps(gid, req.MaxPerDay, req.MaxPerWeek); err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	a.writeGroupCaps(w, gid)
+}
+
+func (a *API) writeGroupCaps(w http.ResponseWriter, gid string) {
+	u, err := a.Store.GroupCapUsage(gid)
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, u)
+}
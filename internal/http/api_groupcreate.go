@@ -0,0 +1,86 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/go-chi/chi/v5"
+
+	"promote/internal/storage"
+)
+
+// maxGroupSubject batas panjang nama grup WhatsApp (lebih panjang ditolak server dengan 406).
+const maxGroupSubject = 25
+
+type createGroupReq struct {
+	Subject      string   `json:"subject"`
+	Participants []string `json:"participants"`
+	// Enable langsung mengaktifkan grup untuk broadcast.
+	Enable bool `json:"enable"`
+}
+
+// Buat grup WhatsApp baru dari akun {id}, simpan ke groups, dan (opsional) aktifkan untuk broadcast.
+func (a *API) handleCreateGroup(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	var req createGroupReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErr(w, http.StatusBadRequest, "invalid JSON")
+		return
+	}
+	subject := strings.TrimSpace(req.Subject)
+	if subject == "" || utf8.RuneCountInString(subject) > maxGroupSubject {
+		writeErr(w, http.StatusBadRequest, "subject required (max 25 characters)")
+		return
+	}
+	if len(req.Participants) == 0 {
+		writeErr(w, http.StatusBadRequest, "participants required")
+		return
+	}
+	numbers, invalid := make([]string, 0, len(req.Participants)), []string{}
+	seen := map[string]bool{}
+	for _, p := range req.Participants {
+		n := storage.NormalizeNumber(p)
+		if n == "" {
+			invalid = append(invalid, p)
+			continue
+		}
+		if !seen[n] {
+			seen[n] = true
+			numbers = append(numbers, n)
+		}
+	}
+	if len(invalid) > 0 {
+		writeErr(w, http.StatusBadRequest, "invalid participant numbers: "+strings.Join(invalid, ", "))
+		return
+	}
+	if !a.accountExists(w, id) {
+		return
+	}
+
+	gid, participants, err := a.Manager.CreateGroup(r.Context(), id, subject, numbers)
+	if err != nil {
+		writeWAErr(w, err)
+		return
+	}
+	if err := a.Store.UpsertGroup(id, gid, subject); err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	_ = a.Store.SetGroupParticipantCount(gid, participants)
+	_ = a.Store.AddGroupMember(id, gid)
+	if req.Enable {
+		if _, err := a.Store.ToggleGroup(gid, true); err != nil {
+			writeErr(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+	writeJSON(w, http.StatusCreated, map[string]any{
+		"id":           gid,
+		"account_id":   id,
+		"name":         subject,
+		"participants": participants,
+		"enabled":      req.Enable,
+	})
+}
@@ -0,0 +1,171 @@
+package httpapi
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+
+	"promote/internal/storage"
+)
+
+// maxGroupFieldsCSV membatasi ukuran CSV import custom field.
+const maxGroupFieldsCSV = 10 << 20
+
+// groupFromURL mengambil {gid} dan memastikan grupnya ada; false jika respons error sudah ditulis.
+func (a *API) groupFromURL(w http.ResponseWriter, r *http.Request) (string, bool) {
+	gid := chi.URLParam(r, "gid")
+	ok, err := a.Store.GroupExists(gid)
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return "", false
+	}
+	if !ok {
+		writeErr(w, http.StatusNotFound, "group not found")
+		return "", false
+	}
+	return gid, true
+}
+
+// Custom field grup (dipakai template sebagai {field:key}).
+func (a *API) handleGetGroupFields(w http.ResponseWriter, r *http.Request) {
+	gid, ok := a.groupFromURL(w, r)
+	if !ok {
+		return
+	}
+	fields, err := a.Store.GroupFields(gid)
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, fields)
+}
+
+// Set field (merge): {"discount": "20%", "city": ""}; nilai kosong menghapus field.
+func (a *API) handleSetGroupFields(w http.ResponseWriter, r *http.Request) {
+	gid, ok := a.groupFromURL(w, r)
+	if !ok {
+		return
+	}
+	var req map[string]string
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErr(w, http.StatusBadRequest, "invalid JSON")
+		return
+	}
+	fields := make(map[string]string, len(req))
+	for k, v := range req {
+		key, err := storage.NormalizeFieldKey(k)
+		if err != nil {
+			writeErr(w, http.StatusBadRequest, err.Error()+": "+k)
+			return
+		}
+		fields[key] = strings.TrimSpace(v)
+	}
+	if err := a.Store.SetGroupFields(gid, fields); err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	all, err := a.Store.GroupFields(gid)
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, all)
+}
+
+func (a *API) handleDeleteGroupField(w http.ResponseWriter, r *http.Request) {
+	key, err := storage.NormalizeFieldKey(chi.URLParam(r, "key"))
+	if err != nil {
+		writeErr(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	n, err := a.Store.DeleteGroupField(chi.URLParam(r, "gid"), key)
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if n == 0 {
+		writeErr(w, http.StatusNotFound, "field not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"deleted": n})
+}
+
+// Import CSV (body mentah atau multipart field "file"). Baris pertama header: kolom
+// group_id lalu satu kolom per field, mis. "group_id,discount,city". Sel kosong menghapus field.
+func (a *API) handleImportGroupFields(w http.ResponseWriter, r *http.Request) {
+	var src io.Reader = r.Body
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/") {
+		file, _, err := r.FormFile("file")
+		if err != nil {
+			writeErr(w, http.StatusBadRequest, "file missing")
+			return
+		}
+		defer file.Close()
+		src = file
+	}
+	byGroup, err := parseGroupFieldsCSV(io.LimitReader(src, maxGroupFieldsCSV))
+	if err != nil {
+		writeErr(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	updated, unknown, err := a.Store.ImportGroupFields(byGroup)
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if unknown == nil {
+		unknown = []string{}
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"groups_updated": updated, "unknown_groups": unknown})
+}
+
+func parseGroupFieldsCSV(r io.Reader) (map[string]map[string]string, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+	header, err := cr.Read()
+	if err != nil {
+		return nil, errors.New("empty CSV")
+	}
+	if len(header) < 2 || strings.ToLower(strings.TrimSpace(strings.TrimPrefix(header[0], "\ufeff"))) != "group_id" {
+		return nil, errors.New("header must start with group_id followed by field columns")
+	}
+	keys := make([]string, len(header))
+	for i, h := range header[1:] {
+		key, err := storage.NormalizeFieldKey(h)
+		if err != nil {
+			return nil, errors.New(err.Error() + ": " + h)
+		}
+		keys[i+1] = key
+	}
+	out := map[string]map[string]string{}
+	for {
+		rec, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		gid := strings.TrimSpace(rec[0])
+		if gid == "" {
+			continue
+		}
+		if out[gid] == nil {
+			out[gid] = map[string]string{}
+		}
+		for i := 1; i < len(keys); i++ {
+			v := ""
+			if i < len(rec) {
+				v = strings.TrimSpace(rec[i])
+			}
+			out[gid][keys[i]] = v
+		}
+	}
+	return out, nil
+}
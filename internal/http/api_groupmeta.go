@@ -0,0 +1,148 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/go-chi/chi/v5"
+
+	"promote/internal/ogp"
+)
+
+const (
+	// maxGroupPhoto batas ukuran gambar sumber foto grup.
+	maxGroupPhoto = 10 << 20
+	// groupPhotoSide sisi terpanjang foto grup setelah dikonversi ke JPEG.
+	groupPhotoSide = 640
+	// maxGroupDescription batas panjang deskripsi grup WhatsApp.
+	maxGroupDescription = 2048
+)
+
+// accountExists menulis 404/500 dan mengembalikan false jika akun {id} tidak bisa dipakai.
+func (a *API) accountExists(w http.ResponseWriter, id string) bool {
+	exists, err := a.Store.AccountExists(id)
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return false
+	}
+	if !exists {
+		writeErr(w, http.StatusNotFound, "account not found")
+		return false
+	}
+	return true
+}
+
+// Ganti nama grup lewat akun {id} (akun harus admin grup).
+func (a *API) handleSetGroupSubject(w http.ResponseWriter, r *http.Request) {
+	id, gid := chi.URLParam(r, "id"), chi.URLParam(r, "gid")
+	var req struct {
+		Subject string `json:"subject"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErr(w, http.StatusBadRequest, "invalid JSON")
+		return
+	}
+	subject := strings.TrimSpace(req.Subject)
+	if subject == "" || utf8.RuneCountInString(subject) > maxGroupSubject {
+		writeErr(w, http.StatusBadRequest, "subject required (max 25 characters)")
+		return
+	}
+	if !a.accountExists(w, id) {
+		return
+	}
+	if err := a.Manager.SetGroupSubject(r.Context(), id, gid, subject); err != nil {
+		writeWAErr(w, err)
+		return
+	}
+	if err := a.Store.SetGroupName(gid, subject); err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"id": gid, "subject": subject})
+}
+
+// Ganti deskripsi grup lewat akun {id}; description kosong menghapus deskripsi.
+func (a *API) handleSetGroupDescription(w http.ResponseWriter, r *http.Request) {
+	id, gid := chi.URLParam(r, "id"), chi.URLParam(r, "gid")
+	var req struct {
+		Description string `json:"description"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErr(w, http.StatusBadRequest, "invalid JSON")
+		return
+	}
+	desc := strings.TrimSpace(req.Description)
+	if utf8.RuneCountInString(desc) > maxGroupDescription {
+		writeErr(w, http.StatusBadRequest, "description too long (max 2048 characters)")
+		return
+	}
+	if !a.accountExists(w, id) {
+		return
+	}
+	if err := a.Manager.SetGroupDescription(r.Context(), id, gid, desc); err != nil {
+		writeWAErr(w, err)
+		return
+	}
+	if err := a.Store.SetGroupDescription(gid, desc); err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"id": gid, "description": desc})
+}
+
+// Ganti foto grup lewat akun {id}: multipart field "file" atau JSON {"url": "..."}. Gambar
+// JPEG/PNG/GIF dikonversi ke JPEG (sisi terpanjang 640px).
+func (a *API) handleSetGroupPhoto(w http.ResponseWriter, r *http.Request) {
+	id, gid := chi.URLParam(r, "id"), chi.URLParam(r, "gid")
+	var data []byte
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/") {
+		file, _, err := r.FormFile("file")
+		if err != nil {
+			writeErr(w, http.StatusBadRequest, "file missing")
+			return
+		}
+		defer file.Close()
+		if data, err = io.ReadAll(io.LimitReader(file, maxGroupPhoto+1)); err != nil {
+			writeErr(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	} else {
+		var req struct {
+			URL string `json:"url"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeErr(w, http.StatusBadRequest, "invalid JSON")
+			return
+		}
+		if strings.TrimSpace(req.URL) == "" {
+			writeErr(w, http.StatusBadRequest, "file or url required")
+			return
+		}
+		var err error
+		if data, _, err = a.Sender.Fetch(r.Context(), strings.TrimSpace(req.URL)); err != nil {
+			writeErr(w, http.StatusBadRequest, "fetch image: "+err.Error())
+			return
+		}
+	}
+	if len(data) > maxGroupPhoto {
+		writeErr(w, http.StatusBadRequest, "image too large (max 10MB)")
+		return
+	}
+	photo, _, _, err := ogp.Thumbnail(data, groupPhotoSide)
+	if err != nil {
+		writeErr(w, http.StatusBadRequest, "unsupported image: "+err.Error())
+		return
+	}
+	if !a.accountExists(w, id) {
+		return
+	}
+	pictureID, err := a.Manager.SetGroupPhoto(r.Context(), id, gid, photo)
+	if err != nil {
+		writeWAErr(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"id": gid, "picture_id": pictureID})
+}
@@ -0,0 +1,86 @@
+package httpapi
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"go.mau.fi/whatsmeow/types"
+
+	"promote/internal/autojoin"
+)
+
+// groupShorthand JID grup tanpa server, mis. "120363012345678901" atau format lama
+// "6281234567890-1600000000" (nomor pembuat-timestamp).
+var groupShorthand = regexp.MustCompile(`^\+?[0-9]{6,}(-[0-9]+)?$`)
+
+type groupCandidate struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// groupRefError kegagalan resolve referensi grup beserta status HTTP-nya.
+type groupRefError struct {
+	status     int
+	msg        string
+	candidates []groupCandidate
+}
+
+func (e *groupRefError) Error() string { return e.msg }
+
+// resolveGroupRef mengubah referensi grup dari operator menjadi JID:
+//   - JID lengkap dipakai apa adanya asalkan JID grup (…@g.us); JID lain = 400;
+//   - link undangan chat.whatsapp.com/<kode> di-resolve lewat WhatsApp (akun harus online);
+//   - shorthand digit dilengkapi "@g.us" (spasi dan + diabaikan);
+//   - selain itu dicocokkan dengan nama grup milik akun; lebih dari satu kecocokan = 409.
+func (a *API) resolveGroupRef(ctx context.Context, accountID, ref string) (string, error) {
+	ref = strings.TrimSpace(ref)
+	if strings.Contains(ref, "@") {
+		jid, err := types.ParseJID(ref)
+		if err != nil || jid.Server != types.GroupServer || jid.User == "" {
+			return "", &groupRefError{status: http.StatusBadRequest, msg: "invalid group jid: " + ref}
+		}
+		return jid.String(), nil
+	}
+	if codes := autojoin.ExtractInviteCodes(ref); len(codes) > 0 {
+		jid, _, err := a.Manager.GroupFromInvite(ctx, accountID, codes[0])
+		if err != nil {
+			return "", &groupRefError{status: waErrStatus(err), msg: err.Error()}
+		}
+		return jid, nil
+	}
+	if compact := strings.ReplaceAll(ref, " ", ""); groupShorthand.MatchString(compact) {
+		return strings.TrimPrefix(compact, "+") + "@g.us", nil
+	}
+	groups, err := a.Store.FindGroupsByName(accountID, ref)
+	if err != nil {
+		return "", err
+	}
+	switch len(groups) {
+	case 0:
+		return "", &groupRefError{status: http.StatusNotFound, msg: "group not found: " + ref}
+	case 1:
+		return groups[0].ID, nil
+	}
+	e := &groupRefError{status: http.StatusConflict, msg: "group name is ambiguous: " + ref}
+	for _, g := range groups {
+		e.candidates = append(e.candidates, groupCandidate{ID: g.ID, Name: g.Name})
+	}
+	return "", e
+}
+
+// writeGroupRefErr menulis error resolveGroupRef (kandidat disertakan jika nama ambigu).
+func writeGroupRefErr(w http.ResponseWriter, err error) {
+	var ge *groupRefError
+	if !errors.As(err, &ge) {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if len(ge.candidates) > 0 {
+		writeJSON(w, ge.status, map[string]any{"error": ge.msg, "candidates": ge.candidates})
+		return
+	}
+	writeErr(w, ge.status, ge.msg)
+}
@@ -0,0 +1,25 @@
+package httpapi
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"promote/internal/storage/storagetest"
+)
+
+func TestGroupRefRejectsNonGroupJID(t *testing.T) {
+	a := &API{Store: storagetest.Open(t)}
+	for _, ref := range []string{"6281234567890@s.whatsapp.net", "junk@", "a@b@c", "@g.us", "120363@newsletter"} {
+		_, err := a.resolveGroupRef(context.Background(), "a", ref)
+		var ge *groupRefError
+		if !errors.As(err, &ge) || ge.status != http.StatusBadRequest {
+			t.Fatalf("resolveGroupRef(%q) err = %v, want 400", ref, err)
+		}
+	}
+	got, err := a.resolveGroupRef(context.Background(), "a", " 120363012345678901@g.us ")
+	if err != nil || got != "120363012345678901@g.us" {
+		t.Fatalf("resolveGroupRef(group jid) = %q, %v", got, err)
+	}
+}
@@ -0,0 +1,62 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"promote/internal/model"
+)
+
+// Pengaturan masa uji grup baru.
+func (a *API) handleGetGroupTrialSettings(w http.ResponseWriter, r *http.Request) {
+	t, err := a.Store.GroupTrialSettings()
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, t)
+}
+
+// Ubah pengaturan masa uji {"enabled":true,"sends":5,"max_fail_rate":0.4,"min_reply_rate":0}.
+func (a *API) handleSetGroupTrialSettings(w http.ResponseWriter, r *http.Request) {
+	t := model.DefaultGroupTrialSettings()
+	if err := json.NewDecoder(r.Body).Decode(&t); err != nil {
+		writeErr(w, http.StatusBadRequest, "invalid JSON")
+		return
+	}
+	if err := t.Validate(); err != nil {
+		writeErr(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := a.Store.SetGroupTrialSettings(t); err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, t)
+}
+
+// Daftar masa uji grup beserta progres dan verdict; ?verdict=open|promoted|disabled, ?account_id=.
+func (a *API) handleListGroupTrials(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	verdict := q.Get("verdict")
+	switch verdict {
+	case "", "open", model.TrialPromoted, model.TrialDisabled:
+	default:
+		writeErr(w, http.StatusBadRequest, "verdict must be open, promoted or disabled")
+		return
+	}
+	limit := 100
+	if n, err := strconv.Atoi(q.Get("limit")); err == nil && n > 0 && n <= 1000 {
+		limit = n
+	}
+	list, err := a.Store.ListGroupTrials(q.Get("account_id"), verdict, limit)
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if list == nil {
+		list = []model.GroupTrial{}
+	}
+	writeJSON(w, http.StatusOK, list)
+}
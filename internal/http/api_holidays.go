@@ -0,0 +1,62 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"promote/internal/model"
+	"promote/internal/storage"
+)
+
+// Daftar hari libur; ?upcoming=1 hanya tanggal mulai hari ini (WIB).
+func (a *API) handleListHolidays(w http.ResponseWriter, r *http.Request) {
+	from := ""
+	if v := r.URL.Query().Get("upcoming"); v == "1" || v == "true" {
+		from = time.Now().In(wibLocation()).Format(model.HolidayLayout)
+	}
+	list, err := a.Store.ListHolidays(from)
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, list)
+}
+
+// Tambah/ubah hari libur {"date":"2027-03-10","name":"Idul Fitri"}: semua broadcast otomatis
+// dilewati pada tanggal itu (WIB).
+func (a *API) handleSetHoliday(w http.ResponseWriter, r *http.Request) {
+	var h model.Holiday
+	if err := json.NewDecoder(r.Body).Decode(&h); err != nil {
+		writeErr(w, http.StatusBadRequest, "invalid JSON")
+		return
+	}
+	h.Date, h.Name = strings.TrimSpace(h.Date), strings.TrimSpace(h.Name)
+	if err := h.Validate(); err != nil {
+		writeErr(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := a.Store.SetHoliday(h); err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"date": h.Date, "name": h.Name})
+}
+
+func (a *API) handleDeleteHoliday(w http.ResponseWriter, r *http.Request) {
+	date := chi.URLParam(r, "date")
+	err := a.Store.DeleteHoliday(date)
+	if errors.Is(err, storage.ErrHolidayNotFound) {
+		writeErr(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"deleted": date})
+}
@@ -0,0 +1,73 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type joinRequestsReq struct {
+	// Participants JID atau nomor dari daftar pending.
+	Participants []string `json:"participants"`
+	// All memproses semua permintaan yang pending (participants diabaikan).
+	All bool `json:"all"`
+}
+
+// Permintaan bergabung yang menunggu persetujuan di grup {gid} (akun {id} harus admin).
+func (a *API) handleListJoinRequests(w http.ResponseWriter, r *http.Request) {
+	id, gid := chi.URLParam(r, "id"), chi.URLParam(r, "gid")
+	if !a.accountExists(w, id) {
+		return
+	}
+	list, err := a.Manager.ListJoinRequests(r.Context(), id, gid)
+	if err != nil {
+		writeWAErr(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"total": len(list), "requests": list})
+}
+
+func (a *API) handleApproveJoinRequests(w http.ResponseWriter, r *http.Request) {
+	a.resolveJoinRequests(w, r, true)
+}
+
+func (a *API) handleRejectJoinRequests(w http.ResponseWriter, r *http.Request) {
+	a.resolveJoinRequests(w, r, false)
+}
+
+// resolveJoinRequests menyetujui/menolak permintaan bergabung secara massal; hasil per target.
+func (a *API) resolveJoinRequests(w http.ResponseWriter, r *http.Request, approve bool) {
+	id, gid := chi.URLParam(r, "id"), chi.URLParam(r, "gid")
+	var req joinRequestsReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErr(w, http.StatusBadRequest, "invalid JSON")
+		return
+	}
+	if !req.All && len(req.Participants) == 0 {
+		writeErr(w, http.StatusBadRequest, "participants required (or all=true)")
+		return
+	}
+	if req.All {
+		req.Participants = nil
+	}
+	if !a.accountExists(w, id) {
+		return
+	}
+	results, err := a.Manager.ResolveJoinRequests(r.Context(), id, gid, req.Participants, approve)
+	if err != nil {
+		writeWAErr(w, err)
+		return
+	}
+	ok := 0
+	for _, res := range results {
+		if res.OK {
+			ok++
+		}
+	}
+	key := "rejected"
+	if approve {
+		key = "approved"
+	}
+	writeJSON(w, http.StatusOK, map[string]any{key: ok, "failed": len(results) - ok, "results": results})
+}
@@ -0,0 +1,71 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+
+	"promote/internal/model"
+	"promote/internal/storage"
+)
+
+type createAPIKeyReq struct {
+	Name string `json:"name"`
+	Role string `json:"role"`
+}
+
+func (a *API) handleListAPIKeys(w http.ResponseWriter, r *http.Request) {
+	list, err := a.Store.ListAPIKeys()
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if list == nil {
+		list = []model.APIKey{}
+	}
+	writeJSON(w, http.StatusOK, list)
+}
+
+// Buat key baru (role default admin); nilai key hanya dikembalikan di respons ini.
+func (a *API) handleCreateAPIKey(w http.ResponseWriter, r *http.Request) {
+	var req createAPIKeyReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErr(w, http.StatusBadRequest, "invalid JSON")
+		return
+	}
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		writeErr(w, http.StatusBadRequest, "name required")
+		return
+	}
+	if req.Role == "" {
+		req.Role = model.RoleAdmin
+	}
+	if !model.ValidRole(req.Role) {
+		writeErr(w, http.StatusBadRequest, "role must be admin or operator")
+		return
+	}
+	k, key, err := a.Store.CreateAPIKey(req.Name, req.Role)
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusCreated, map[string]any{"key": key, "api_key": k})
+}
+
+func (a *API) handleRevokeAPIKey(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	err := a.Store.RevokeAPIKey(id)
+	if errors.Is(err, storage.ErrAPIKeyNotFound) {
+		writeErr(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"revoked": id})
+}
@@ -0,0 +1,108 @@
+package httpapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"promote/internal/linkhealth"
+	"promote/internal/model"
+	"promote/internal/storage"
+)
+
+// Link undangan grup milik sendiri beserta hasil cek terakhir (opsional ?account_id=&status=).
+func (a *API) handleListGroupLinks(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	list, err := a.Store.ListGroupLinks(q.Get("account_id"), q.Get("status"))
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if list == nil {
+		list = []model.GroupLink{}
+	}
+	writeJSON(w, http.StatusOK, list)
+}
+
+// Jalankan cek link sekarang: {"account_id": "..."} untuk satu akun, kosong untuk semua akun terhubung.
+func (a *API) handleCheckGroupLinks(w http.ResponseWriter, r *http.Request) {
+	if a.LinkHealth == nil {
+		writeErr(w, http.StatusServiceUnavailable, "link health monitor not running")
+		return
+	}
+	var body struct {
+		AccountID string `json:"account_id"`
+	}
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			writeErr(w, http.StatusBadRequest, "invalid JSON")
+			return
+		}
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), 110*time.Second)
+	defer cancel()
+	var (
+		res linkhealth.Result
+		err error
+	)
+	if body.AccountID != "" {
+		res, err = a.LinkHealth.CheckAccount(ctx, body.AccountID)
+	} else {
+		res, err = a.LinkHealth.CheckAll(ctx)
+	}
+	if err != nil {
+		writeErr(w, http.StatusBadGateway, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, res)
+}
+
+// Ambil ulang link undangan grup {gid} lewat akun {id} (akun harus admin) dan simpan.
+// {"reset": true} mencabut link lama dan membuat link baru.
+func (a *API) handleRefreshGroupLink(w http.ResponseWriter, r *http.Request) {
+	id, gid := chi.URLParam(r, "id"), chi.URLParam(r, "gid")
+	var body struct {
+		Reset bool `json:"reset"`
+	}
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			writeErr(w, http.StatusBadRequest, "invalid JSON")
+			return
+		}
+	}
+	if !a.accountExists(w, id) {
+		return
+	}
+	exists, err := a.Store.GroupExists(gid)
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if !exists {
+		writeErr(w, http.StatusNotFound, "group not found")
+		return
+	}
+	link, err := a.Manager.GroupInviteLink(r.Context(), id, gid, body.Reset)
+	if err != nil {
+		writeWAErr(w, err)
+		return
+	}
+	if err := a.Store.SaveGroupLink(gid, id, link); err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	l, err := a.Store.GetGroupLink(gid)
+	if errors.Is(err, storage.ErrGroupLinkNotFound) {
+		writeErr(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, l)
+}
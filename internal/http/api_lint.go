@@ -0,0 +1,42 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"promote/internal/lint"
+	"promote/internal/storage"
+)
+
+// Cek risiko konten draft template (body sama dengan create) tanpa menyimpan.
+func (a *API) handleLintTemplateDraft(w http.ResponseWriter, r *http.Request) {
+	var req upsertTemplateReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErr(w, http.StatusBadRequest, "invalid JSON")
+		return
+	}
+	writeJSON(w, http.StatusOK, lint.Template(req.template()))
+}
+
+// Cek ulang template tersimpan (mis. hasil feed atau sebelum lint ada) dan simpan hasilnya.
+func (a *API) handleLintTemplate(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	t, err := a.Store.GetTemplate(id)
+	if errors.Is(err, storage.ErrTemplateNotFound) {
+		writeErr(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	rep := lint.Template(t)
+	if err := a.Store.SetTemplateLint(id, rep); err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, rep)
+}
@@ -0,0 +1,114 @@
+package httpapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"promote/internal/mediacache"
+)
+
+func (a *API) handlePrefetchStatus(w http.ResponseWriter, r *http.Request) {
+	if a.MediaPrefetch == nil {
+		writeErr(w, http.StatusServiceUnavailable, "media prefetch not configured")
+		return
+	}
+	entries, size := a.MediaPrefetch.Cache.Stats()
+	writeJSON(w, http.StatusOK, map[string]any{
+		"last":          a.MediaPrefetch.Status(),
+		"cache_entries": entries,
+		"cache_bytes":   size,
+		"every":         a.MediaPrefetch.Every.String(),
+	})
+}
+
+// Jalankan prefetch sekarang (mis. setelah mengganti media template) tanpa menunggu jeda idle.
+func (a *API) handleStartPrefetch(w http.ResponseWriter, r *http.Request) {
+	if a.MediaPrefetch == nil {
+		writeErr(w, http.StatusServiceUnavailable, "media prefetch not configured")
+		return
+	}
+	if err := a.MediaPrefetch.Start(context.Background()); err != nil {
+		if errors.Is(err, mediacache.ErrRunning) {
+			writeErr(w, http.StatusConflict, err.Error())
+			return
+		}
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusAccepted, map[string]any{"started": true})
+}
+
+type replaceMediaReq struct {
+	OldURL string `json:"old_url"`
+	NewURL string `json:"new_url"`
+	DryRun bool   `json:"dry_run"`
+}
+
+// Ganti satu URL media di semua template (mis. banner diperbarui). File baru diunduh dan
+// divalidasi untuk setiap jenis media yang memakainya sebelum template diubah.
+func (a *API) handleReplaceTemplateMedia(w http.ResponseWriter, r *http.Request) {
+	var req replaceMediaReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErr(w, http.StatusBadRequest, "invalid JSON")
+		return
+	}
+	req.OldURL, req.NewURL = strings.TrimSpace(req.OldURL), strings.TrimSpace(req.NewURL)
+	if req.OldURL == "" || req.NewURL == "" {
+		writeErr(w, http.StatusBadRequest, "old_url and new_url required")
+		return
+	}
+	if req.OldURL == req.NewURL {
+		writeErr(w, http.StatusBadRequest, "old_url and new_url are identical")
+		return
+	}
+	matches, err := a.Store.ReplaceTemplateMedia(req.OldURL, req.NewURL, false)
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	out := map[string]any{"dry_run": req.DryRun, "templates": matches}
+	if len(matches) == 0 {
+		out["replaced"] = 0
+		writeJSON(w, http.StatusOK, out)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 2*time.Minute)
+	defer cancel()
+	data, ct, err := a.Sender.Fetch(ctx, req.NewURL)
+	if err != nil {
+		writeErr(w, http.StatusUnprocessableEntity, "fetch new_url: "+err.Error())
+		return
+	}
+	checked := map[string]bool{}
+	for _, m := range matches {
+		for _, kind := range m.Kinds {
+			if checked[kind] {
+				continue
+			}
+			checked[kind] = true
+			if err := mediacache.Validate(kind, ct, int64(len(data))); err != nil {
+				writeErr(w, http.StatusUnprocessableEntity, "new_url: "+err.Error())
+				return
+			}
+		}
+	}
+	out["content_type"] = ct
+	out["bytes"] = len(data)
+	if req.DryRun {
+		out["replaced"] = 0
+		writeJSON(w, http.StatusOK, out)
+		return
+	}
+	if matches, err = a.Store.ReplaceTemplateMedia(req.OldURL, req.NewURL, true); err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	out["templates"] = matches
+	out["replaced"] = len(matches)
+	writeJSON(w, http.StatusOK, out)
+}
@@ -0,0 +1,236 @@
+package httpapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"promote/internal/model"
+	"promote/internal/sender"
+	"promote/internal/storage"
+)
+
+// errEditExpired pesan sudah lewat batas waktu edit WhatsApp.
+var errEditExpired = errors.New("edit window expired")
+
+// errRevokeExpired pesan sudah lewat batas waktu hapus-untuk-semua WhatsApp.
+var errRevokeExpired = errors.New("revoke window expired")
+
+type messageResult struct {
+	LogID   int64  `json:"log_id"`
+	GroupID string `json:"group_id,omitempty"`
+	OK      bool   `json:"ok"`
+	Text    string `json:"text,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+// editLog mengedit satu pesan teks terkirim dan memperbarui preview log-nya.
+func (a *API) editLog(ctx context.Context, e model.LogEntry, text string) (string, error) {
+	if e.Status != "sent" || e.MessageID == "" || !strings.HasPrefix(e.MessagePrev, "text-only:") {
+		return "", sender.ErrNotEditable
+	}
+	if time.Since(e.TS) > sender.EditWindow {
+		return "", errEditExpired
+	}
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	final, err := a.Sender.EditText(ctx, e.AccountID, e.GroupID, e.MessageID, text)
+	if err != nil {
+		return "", err
+	}
+	if err := a.Store.SetLogPreview(e.ID, sender.TextPreview(final)); err != nil {
+		return final, err
+	}
+	return final, nil
+}
+
+// Edit pesan teks yang sudah terkirim (mis. salah ketik) tanpa kirim ulang. Target:
+// {"log_id":123,"text":"..."} untuk satu pesan, {"log_ids":[...]} atau {"campaign_id":"..."}
+// untuk semua pesan teks campaign yang masih dalam batas waktu edit WhatsApp.
+func (a *API) handleEditMessages(w http.ResponseWriter, r *http.Request) {
+	if a.Sender == nil {
+		writeErr(w, http.StatusServiceUnavailable, "sender not configured")
+		return
+	}
+	var req struct {
+		LogID      int64   `json:"log_id"`
+		LogIDs     []int64 `json:"log_ids"`
+		CampaignID string  `json:"campaign_id"`
+		Text       string  `json:"text"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErr(w, http.StatusBadRequest, "invalid JSON")
+		return
+	}
+	if strings.TrimSpace(req.Text) == "" {
+		writeErr(w, http.StatusBadRequest, "text required")
+		return
+	}
+
+	if req.LogID > 0 {
+		e, err := a.Store.GetLog(req.LogID)
+		if errors.Is(err, storage.ErrLogNotFound) {
+			writeErr(w, http.StatusNotFound, err.Error())
+			return
+		}
+		if err != nil {
+			writeErr(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		final, err := a.editLog(r.Context(), e, req.Text)
+		switch {
+		case errors.Is(err, sender.ErrNotEditable), errors.Is(err, errEditExpired), errors.Is(err, sender.ErrObserverAccount):
+			writeErr(w, http.StatusConflict, err.Error())
+			return
+		case err != nil:
+			writeErr(w, http.StatusBadGateway, err.Error())
+			return
+		}
+		writeJSON(w, http.StatusOK, messageResult{LogID: e.ID, GroupID: e.GroupID, OK: true, Text: final})
+		return
+	}
+
+	var logs []model.LogEntry
+	switch {
+	case len(req.LogIDs) > 0:
+		for _, id := range req.LogIDs {
+			e, err := a.Store.GetLog(id)
+			if errors.Is(err, storage.ErrLogNotFound) {
+				e = model.LogEntry{ID: id}
+			} else if err != nil {
+				writeErr(w, http.StatusInternalServerError, err.Error())
+				return
+			}
+			logs = append(logs, e)
+		}
+	case req.CampaignID != "":
+		list, err := a.Store.EditableTextLogs(req.CampaignID, time.Now().Add(-sender.EditWindow))
+		if err != nil {
+			writeErr(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		logs = list
+	default:
+		writeErr(w, http.StatusBadRequest, "log_id, log_ids or campaign_id required")
+		return
+	}
+
+	results := make([]messageResult, 0, len(logs))
+	edited := 0
+	for _, e := range logs {
+		res := messageResult{LogID: e.ID, GroupID: e.GroupID}
+		if e.AccountID == "" {
+			res.Error = storage.ErrLogNotFound.Error()
+		} else if final, err := a.editLog(r.Context(), e, req.Text); err != nil {
+			res.Error = err.Error()
+		} else {
+			res.OK, res.Text = true, final
+			edited++
+		}
+		results = append(results, res)
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"edited": edited, "failed": len(results) - edited, "results": results})
+}
+
+// revokeLog menghapus satu pesan terkirim untuk semua orang dan menandai log-nya.
+func (a *API) revokeLog(ctx context.Context, e model.LogEntry) error {
+	if e.Status != "sent" || e.MessageID == "" || e.RevokedAt != nil {
+		return sender.ErrNotRevocable
+	}
+	if time.Since(e.TS) > sender.RevokeWindow {
+		return errRevokeExpired
+	}
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	if err := a.Sender.Revoke(ctx, e.AccountID, e.GroupID, e.MessageID); err != nil {
+		return err
+	}
+	return a.Store.MarkLogRevoked(e.ID)
+}
+
+// Hapus untuk semua orang pesan promo yang sudah terkirim. Target: {"log_id":123} untuk satu
+// pesan, {"log_ids":[...]}, {"campaign_id":"..."} untuk semua grup tujuan campaign, atau
+// {"session_id":"..."} untuk semua bagian satu kiriman (teks, gambar, dst.).
+func (a *API) handleRevokeMessages(w http.ResponseWriter, r *http.Request) {
+	if a.Sender == nil {
+		writeErr(w, http.StatusServiceUnavailable, "sender not configured")
+		return
+	}
+	var req struct {
+		LogID      int64   `json:"log_id"`
+		LogIDs     []int64 `json:"log_ids"`
+		CampaignID string  `json:"campaign_id"`
+		SessionID  string  `json:"session_id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErr(w, http.StatusBadRequest, "invalid JSON")
+		return
+	}
+
+	if req.LogID > 0 {
+		e, err := a.Store.GetLog(req.LogID)
+		if errors.Is(err, storage.ErrLogNotFound) {
+			writeErr(w, http.StatusNotFound, err.Error())
+			return
+		}
+		if err != nil {
+			writeErr(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		err = a.revokeLog(r.Context(), e)
+		switch {
+		case errors.Is(err, sender.ErrNotRevocable), errors.Is(err, errRevokeExpired), errors.Is(err, sender.ErrObserverAccount):
+			writeErr(w, http.StatusConflict, err.Error())
+			return
+		case err != nil:
+			writeErr(w, http.StatusBadGateway, err.Error())
+			return
+		}
+		writeJSON(w, http.StatusOK, messageResult{LogID: e.ID, GroupID: e.GroupID, OK: true})
+		return
+	}
+
+	var logs []model.LogEntry
+	switch {
+	case len(req.LogIDs) > 0:
+		for _, id := range req.LogIDs {
+			e, err := a.Store.GetLog(id)
+			if errors.Is(err, storage.ErrLogNotFound) {
+				e = model.LogEntry{ID: id}
+			} else if err != nil {
+				writeErr(w, http.StatusInternalServerError, err.Error())
+				return
+			}
+			logs = append(logs, e)
+		}
+	case req.CampaignID != "" || req.SessionID != "":
+		list, err := a.Store.RevocableLogs(req.CampaignID, req.SessionID, time.Now().Add(-sender.RevokeWindow))
+		if err != nil {
+			writeErr(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		logs = list
+	default:
+		writeErr(w, http.StatusBadRequest, "log_id, log_ids, campaign_id or session_id required")
+		return
+	}
+
+	results := make([]messageResult, 0, len(logs))
+	revoked := 0
+	for _, e := range logs {
+		res := messageResult{LogID: e.ID, GroupID: e.GroupID}
+		if e.AccountID == "" {
+			res.Error = storage.ErrLogNotFound.Error()
+		} else if err := a.revokeLog(r.Context(), e); err != nil {
+			res.Error = err.Error()
+		} else {
+			res.OK = true
+			revoked++
+		}
+		results = append(results, res)
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"revoked": revoked, "failed": len(results) - revoked, "results": results})
+}
@@ -0,0 +1,75 @@
+package httpapi
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"promote/internal/model"
+)
+
+// metricGauge satu gauge Prometheus per akun; value false = seri dilewati untuk akun itu.
+type metricGauge struct {
+	name, help string
+	value      func(st model.AccountStreak, now time.Time) (float64, bool)
+}
+
+var accountGauges = []metricGauge{
+	{"promote_account_failure_streak", "Kiriman gagal (failed/throttled) berturut-turut sejak kiriman sukses terakhir.",
+		func(st model.AccountStreak, _ time.Time) (float64, bool) { return float64(st.FailureStreak), true }},
+	{"promote_account_minutes_since_last_success", "Menit sejak kiriman sukses terakhir (tidak ada jika akun belum pernah sukses).",
+		func(st model.AccountStreak, now time.Time) (float64, bool) {
+			if st.LastSuccessAt == nil {
+				return 0, false
+			}
+			return now.Sub(*st.LastSuccessAt).Minutes(), true
+		}},
+	{"promote_account_last_success_timestamp_seconds", "Unix time kiriman sukses terakhir.",
+		func(st model.AccountStreak, _ time.Time) (float64, bool) {
+			if st.LastSuccessAt == nil {
+				return 0, false
+			}
+			return float64(st.LastSuccessAt.Unix()), true
+		}},
+	{"promote_account_last_failure_timestamp_seconds", "Unix time kiriman gagal terakhir.",
+		func(st model.AccountStreak, _ time.Time) (float64, bool) {
+			if st.LastFailureAt == nil {
+				return 0, false
+			}
+			return float64(st.LastFailureAt.Unix()), true
+		}},
+	{"promote_account_enabled", "1 jika akun aktif untuk broadcast.",
+		func(st model.AccountStreak, _ time.Time) (float64, bool) { return float64(btoi(st.Enabled)), true }},
+	{"promote_account_throttled", "1 jika akun sedang dihentikan karena throttle WhatsApp.",
+		func(st model.AccountStreak, _ time.Time) (float64, bool) { return float64(btoi(st.Throttled)), true }},
+}
+
+// Metrik Prometheus (text exposition) per akun untuk aturan alert tanpa parsing log:
+// streak gagal beruntun dan menit sejak kiriman sukses terakhir.
+func (a *API) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	streaks, err := a.Store.AccountStreaks()
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	now := time.Now()
+	var b strings.Builder
+	for _, g := range accountGauges {
+		fmt.Fprintf(&b, "# HELP %s %s\n# TYPE %s gauge\n", g.name, g.help, g.name)
+		for _, st := range streaks {
+			if v, ok := g.value(st, now); ok {
+				fmt.Fprintf(&b, "%s{account_id=%s,label=%s} %g\n", g.name, metricLabel(st.AccountID), metricLabel(st.Label), v)
+			}
+		}
+	}
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	_, _ = w.Write([]byte(b.String()))
+}
+
+// metricLabel nilai label Prometheus ber-quote (escape backslash, kutip dan baris baru).
+func metricLabel(s string) string {
+	return `"` + labelEscaper.Replace(s) + `"`
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
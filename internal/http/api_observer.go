@@ -0,0 +1,35 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"promote/internal/storage"
+)
+
+type observerReq struct {
+	Observer bool `json:"observer"`
+}
+
+// Mode observer akun: sesi tetap terhubung untuk monitoring, semua jalur kirim menolak akun ini.
+func (a *API) handleSetAccountObserver(w http.ResponseWriter, r *http.Request) {
+	var req observerReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErr(w, http.StatusBadRequest, "invalid JSON")
+		return
+	}
+	id := chi.URLParam(r, "id")
+	err := a.Store.SetAccountObserver(id, req.Observer)
+	if errors.Is(err, storage.ErrAccountNotFound) {
+		writeErr(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"account_id": id, "observer": req.Observer})
+}
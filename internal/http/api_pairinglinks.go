@@ -0,0 +1,206 @@
+package httpapi
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"promote/internal/model"
+	"promote/internal/storage"
+)
+
+// Link pairing swalayan: admin membuat URL berbatas waktu untuk satu akun, pemegang URL hanya
+// bisa melihat QR pairing dan status akun itu (tanpa akses API lain).
+
+const maxPairingLinkTTL = 24 * time.Hour
+
+type pairingLinkReq struct {
+	TTLMinutes *int `json:"ttl_minutes"`
+}
+
+// pairingLinkURL URL absolut link pairing berdasarkan host request (menghormati X-Forwarded-Proto
+// di belakang reverse proxy).
+func pairingLinkURL(r *http.Request, token string) string {
+	scheme := "http"
+	if r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https" {
+		scheme = "https"
+	}
+	return scheme + "://" + r.Host + "/pair/" + token
+}
+
+// Buat link pairing akun {"ttl_minutes": 60} (maks 1440). Token hanya ditampilkan sekali.
+func (a *API) handleCreatePairingLink(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	if !a.accountExists(w, id) {
+		return
+	}
+	var req pairingLinkReq
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeErr(w, http.StatusBadRequest, "invalid JSON")
+			return
+		}
+	}
+	ttl := time.Duration(intOr(req.TTLMinutes, 60)) * time.Minute
+	if ttl <= 0 || ttl > maxPairingLinkTTL {
+		writeErr(w, http.StatusBadRequest, "ttl_minutes must be between 1 and 1440")
+		return
+	}
+	if paired, _ := a.Manager.ConnectionState(id); paired {
+		writeErr(w, http.StatusConflict, "account already paired")
+		return
+	}
+	createdBy := ""
+	if p := principalFrom(r.Context()); p != nil {
+		createdBy = p.Name
+	}
+	l, token, err := a.Store.CreatePairingLink(id, createdBy, ttl)
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusCreated, map[string]any{
+		"link":  l,
+		"token": token,
+		"path":  "/pair/" + token,
+		"url":   pairingLinkURL(r, token),
+	})
+}
+
+// Daftar link pairing akun (tanpa token).
+func (a *API) handleListPairingLinks(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	if !a.accountExists(w, id) {
+		return
+	}
+	list, err := a.Store.ListPairingLinks(id)
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if list == nil {
+		list = []model.PairingLink{}
+	}
+	writeJSON(w, http.StatusOK, list)
+}
+
+// Cabut link pairing sebelum kedaluwarsa.
+func (a *API) handleRevokePairingLink(w http.ResponseWriter, r *http.Request) {
+	err := a.Store.RevokePairingLink(chi.URLParam(r, "id"), chi.URLParam(r, "linkID"))
+	if errors.Is(err, storage.ErrPairingLinkNotFound) {
+		writeErr(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"revoked": true})
+}
+
+// pairingLinkFrom link aktif untuk token di URL; menulis 404 jika tidak berlaku lagi.
+func (a *API) pairingLinkFrom(w http.ResponseWriter, r *http.Request) (model.PairingLink, bool) {
+	w.Header().Set("Cache-Control", "no-store")
+	l, err := a.Store.PairingLinkByToken(chi.URLParam(r, "token"))
+	if errors.Is(err, storage.ErrPairingLinkNotFound) {
+		writeErr(w, http.StatusNotFound, err.Error())
+		return l, false
+	}
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return l, false
+	}
+	return l, true
+}
+
+// Halaman pairing publik untuk pemegang link.
+func (a *API) handlePairingPage(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Referrer-Policy", "no-referrer")
+	if _, err := a.Store.PairingLinkByToken(chi.URLParam(r, "token")); err != nil {
+		http.Error(w, "Link pairing tidak berlaku atau sudah kedaluwarsa.", http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	_, _ = w.Write([]byte(pairingPageHTML))
+}
+
+// QR pairing (JSON: code, png_base64, expires_at) untuk akun link.
+func (a *API) handlePairingLinkQR(w http.ResponseWriter, r *http.Request) {
+	l, ok := a.pairingLinkFrom(w, r)
+	if !ok {
+		return
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), 90*time.Second)
+	defer cancel()
+	qr, err := a.Manager.PairingQR(ctx, l.AccountID)
+	if err != nil {
+		writeWAErr(w, err)
+		return
+	}
+	_ = a.Store.UpdateAccountStatus(l.AccountID, model.StatusPairing, "", nil)
+	writeJSON(w, http.StatusOK, map[string]any{
+		"code":       qr.Code,
+		"png_base64": base64.StdEncoding.EncodeToString(qr.PNG),
+		"expires_at": qr.ExpiresAt.UTC(),
+	})
+}
+
+// Status pairing akun link; begitu akun terpasang link ditandai terpakai dan tidak bisa dibuka lagi.
+func (a *API) handlePairingLinkStatus(w http.ResponseWriter, r *http.Request) {
+	l, ok := a.pairingLinkFrom(w, r)
+	if !ok {
+		return
+	}
+	paired, connected := a.Manager.ConnectionState(l.AccountID)
+	if paired {
+		if err := a.Store.MarkPairingLinkUsed(l.ID); err != nil {
+			writeErr(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"paired":          paired,
+		"connected":       connected,
+		"link_expires_at": l.ExpiresAt,
+	})
+}
+
+const pairingPageHTML = `<!doctype html>
+<html lang="id"><head><meta charset="utf-8"><meta name="viewport" content="width=device-width,initial-scale=1">
+<meta name="robots" content="noindex"><title>Pairing WhatsApp</title>
+<style>body{font-family:system-ui,sans-serif;max-width:420px;margin:40px auto;padding:0 16px;text-align:center;color:#222}
+img{width:280px;height:280px;border:1px solid #ddd;border-radius:8px}#msg{margin-top:16px}</style></head>
+<body><h2>Pairing WhatsApp</h2>
+<p>Buka WhatsApp &rarr; Perangkat tertaut &rarr; Tautkan perangkat, lalu pindai QR di bawah.</p>
+<img id="qr" alt="QR"><div id="msg">Memuat QR...</div>
+<script>
+var base = location.pathname.replace(/\/$/, '');
+var done = false;
+function msg(t){ document.getElementById('msg').textContent = t; }
+function loadQR(){
+  if (done) return;
+  fetch(base + '/qr', {cache: 'no-store'}).then(function(r){ return r.json().then(function(j){ return [r.status, j]; }); })
+    .then(function(res){
+      var j = res[1];
+      if (res[0] !== 200) { msg(j.error || 'Gagal memuat QR'); if (res[0] !== 404) setTimeout(loadQR, 5000); return; }
+      document.getElementById('qr').src = 'data:image/png;base64,' + j.png_base64;
+      msg('Pindai sebelum QR berganti otomatis.');
+      var wait = Math.max(5000, new Date(j.expires_at).getTime() - Date.now());
+      setTimeout(loadQR, wait);
+    }).catch(function(){ setTimeout(loadQR, 5000); });
+}
+function poll(){
+  fetch(base + '/status', {cache: 'no-store'}).then(function(r){ return r.json(); }).then(function(j){
+    if (j.paired) { done = true; document.getElementById('qr').style.display = 'none'; msg('Berhasil! Nomor Anda sudah terhubung. Halaman ini boleh ditutup.'); return; }
+    if (j.error) { done = true; msg(j.error); return; }
+    setTimeout(poll, 3000);
+  }).catch(function(){ setTimeout(poll, 5000); });
+}
+loadQR(); poll();
+</script></body></html>`
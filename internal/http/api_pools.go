@@ -0,0 +1,112 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+
+	"promote/internal/model"
+)
+
+type upsertPoolReq struct {
+	Name   string `json:"name"`
+	Rotate *bool  `json:"rotate"`
+}
+
+func (a *API) handleListPools(w http.ResponseWriter, r *http.Request) {
+	list, err := a.Store.ListPools()
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if list == nil {
+		list = []model.Pool{}
+	}
+	writeJSON(w, http.StatusOK, list)
+}
+
+func (a *API) handleCreatePool(w http.ResponseWriter, r *http.Request) {
+	var req upsertPoolReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErr(w, http.StatusBadRequest, "invalid JSON")
+		return
+	}
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		writeErr(w, http.StatusBadRequest, "name required")
+		return
+	}
+	rotate := true
+	if req.Rotate != nil {
+		rotate = *req.Rotate
+	}
+	id, err := a.Store.CreatePool(name, rotate)
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusCreated, map[string]any{"id": id})
+}
+
+func (a *API) handleUpdatePool(w http.ResponseWriter, r *http.Request) {
+	var req upsertPoolReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErr(w, http.StatusBadRequest, "invalid JSON")
+		return
+	}
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		writeErr(w, http.StatusBadRequest, "name required")
+		return
+	}
+	rotate := true
+	if req.Rotate != nil {
+		rotate = *req.Rotate
+	}
+	n, err := a.Store.UpdatePool(chi.URLParam(r, "id"), name, rotate)
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if n == 0 {
+		writeErr(w, http.StatusNotFound, "pool not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"updated": n})
+}
+
+func (a *API) handleDeletePool(w http.ResponseWriter, r *http.Request) {
+	n, err := a.Store.DeletePool(chi.URLParam(r, "id"))
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if n == 0 {
+		writeErr(w, http.StatusNotFound, "pool not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"deleted": n})
+}
+
+// Set pool akun: {"pool_id": "..."}; kosong = keluarkan dari pool.
+func (a *API) handleSetAccountPool(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		PoolID string `json:"pool_id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeErr(w, http.StatusBadRequest, "invalid JSON")
+		return
+	}
+	n, err := a.Store.SetAccountPool(chi.URLParam(r, "id"), strings.TrimSpace(body.PoolID))
+	if err != nil {
+		writeErr(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if n == 0 {
+		writeErr(w, http.StatusNotFound, "account not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"updated": n})
+}
@@ -0,0 +1,153 @@
+package httpapi
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"promote/internal/model"
+)
+
+// List antrean outbox. Default hanya pending+claimed; status=all untuk semua.
+func (a *API) handleListQueue(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	f := model.OutboxFilter{
+		AccountID:  q.Get("account_id"),
+		GroupID:    q.Get("group_id"),
+		CampaignID: q.Get("campaign_id"),
+	}
+	f.Limit, _ = strconv.Atoi(q.Get("limit"))
+	f.Offset, _ = strconv.Atoi(q.Get("offset"))
+	switch st := strings.TrimSpace(q.Get("status")); st {
+	case "":
+		f.Statuses = []string{model.OutboxPending, model.OutboxClaimed}
+	case "all":
+	default:
+		for _, s := range strings.Split(st, ",") {
+			if s = strings.TrimSpace(s); s != "" {
+				f.Statuses = append(f.Statuses, s)
+			}
+		}
+	}
+	items, err := a.Store.ListOutbox(f)
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if items == nil {
+		items = []model.OutboxItem{}
+	}
+	counts, err := a.Store.CountOutboxByStatus()
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"items": items, "counts": counts})
+}
+
+func (a *API) handleQueuePriority(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Priority int `json:"priority"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeErr(w, http.StatusBadRequest, "invalid JSON")
+		return
+	}
+	a.mutateQueueItem(w, r, func(id int64) (int64, error) {
+		return a.Store.SetOutboxPriority(id, body.Priority)
+	})
+}
+
+// Postpone: {"until": RFC3339} atau {"minutes": N} dari sekarang.
+func (a *API) handleQueuePostpone(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Until   string `json:"until"`
+		Minutes int    `json:"minutes"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeErr(w, http.StatusBadRequest, "invalid JSON")
+		return
+	}
+	var until time.Time
+	switch {
+	case body.Until != "":
+		t, err := time.Parse(time.RFC3339, body.Until)
+		if err != nil {
+			writeErr(w, http.StatusBadRequest, "until must be RFC3339")
+			return
+		}
+		until = t
+	case body.Minutes > 0:
+		until = time.Now().Add(time.Duration(body.Minutes) * time.Minute)
+	default:
+		writeErr(w, http.StatusBadRequest, "until or minutes required")
+		return
+	}
+	a.mutateQueueItem(w, r, func(id int64) (int64, error) {
+		return a.Store.PostponeOutbox(id, until)
+	})
+}
+
+func (a *API) handleQueueCancel(w http.ResponseWriter, r *http.Request) {
+	a.mutateQueueItem(w, r, a.Store.CancelOutbox)
+}
+
+// Purge: batalkan semua item pending milik campaign.
+func (a *API) handleQueuePurge(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		CampaignID string `json:"campaign_id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeErr(w, http.StatusBadRequest, "invalid JSON")
+		return
+	}
+	if strings.TrimSpace(body.CampaignID) == "" {
+		writeErr(w, http.StatusBadRequest, "campaign_id required")
+		return
+	}
+	n, err := a.Store.PurgeCampaignOutbox(body.CampaignID)
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"canceled": n})
+}
+
+// mutateQueueItem menjalankan fn untuk item {id}; 404 jika tidak ada, 409 jika bukan pending.
+func (a *API) mutateQueueItem(w http.ResponseWriter, r *http.Request, fn func(id int64) (int64, error)) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		writeErr(w, http.StatusBadRequest, "invalid queue id")
+		return
+	}
+	n, err := fn(id)
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if n == 0 {
+		it, err := a.Store.GetOutboxItem(id)
+		if errors.Is(err, sql.ErrNoRows) {
+			writeErr(w, http.StatusNotFound, "queue item not found")
+			return
+		}
+		if err != nil {
+			writeErr(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		writeErr(w, http.StatusConflict, "queue item is "+it.Status+", only pending items can be changed")
+		return
+	}
+	it, err := a.Store.GetOutboxItem(id)
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, it)
+}
@@ -0,0 +1,194 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"promote/internal/model"
+	"promote/internal/storage"
+)
+
+type recurringReq struct {
+	Name       string          `json:"name"`
+	AccountID  string          `json:"account_id"`
+	DaysMask   string          `json:"days_mask"`
+	Times      []string        `json:"times"`
+	TemplateID string          `json:"template_id"`
+	CampaignID string          `json:"campaign_id"`
+	Targeting  model.Targeting `json:"targeting"`
+	Priority   *int            `json:"priority"`
+	Enabled    *bool           `json:"enabled"`
+}
+
+// recurringView jadwal berulang beserta jadwal berikutnya (WIB).
+type recurringView struct {
+	model.RecurringSchedule
+	NextRunAt *time.Time  `json:"next_run_at,omitempty"`
+	NextRuns  []time.Time `json:"next_runs,omitempty"`
+}
+
+// viewRecurring menghitung n jadwal berikutnya dari sekarang (hanya untuk aturan aktif).
+func viewRecurring(r model.RecurringSchedule, n int) recurringView {
+	v := recurringView{RecurringSchedule: r}
+	if !r.Enabled {
+		return v
+	}
+	at := time.Now().In(wibLocation())
+	for i := 0; i < n; i++ {
+		if at = r.NextRun(at); at.IsZero() {
+			break
+		}
+		v.NextRuns = append(v.NextRuns, at)
+	}
+	if len(v.NextRuns) > 0 {
+		v.NextRunAt = &v.NextRuns[0]
+	}
+	if n == 1 {
+		v.NextRuns = nil
+	}
+	return v
+}
+
+// decodeRecurring membaca dan memvalidasi body, serta memastikan akun, template atau campaign
+// ada; false jika respons error sudah ditulis.
+func (a *API) decodeRecurring(w http.ResponseWriter, r *http.Request) (model.RecurringSchedule, bool) {
+	var req recurringReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErr(w, http.StatusBadRequest, "invalid JSON")
+		return model.RecurringSchedule{}, false
+	}
+	rs := model.RecurringSchedule{
+		Name:       strings.TrimSpace(req.Name),
+		AccountID:  strings.TrimSpace(req.AccountID),
+		DaysMask:   strings.TrimSpace(req.DaysMask),
+		Times:      req.Times,
+		TemplateID: strings.TrimSpace(req.TemplateID),
+		CampaignID: strings.TrimSpace(req.CampaignID),
+		Targeting:  req.Targeting,
+		Priority:   intOr(req.Priority, 0),
+		Enabled:    req.Enabled == nil || *req.Enabled,
+	}
+	if err := rs.Validate(); err != nil {
+		writeErr(w, http.StatusBadRequest, err.Error())
+		return rs, false
+	}
+	if rs.AccountID != "" {
+		ok, err := a.Store.AccountExists(rs.AccountID)
+		if err != nil {
+			writeErr(w, http.StatusInternalServerError, err.Error())
+			return rs, false
+		}
+		if !ok {
+			writeErr(w, http.StatusBadRequest, "account not found")
+			return rs, false
+		}
+	}
+	var err error
+	if rs.TemplateID != "" {
+		_, err = a.Store.GetTemplate(rs.TemplateID)
+	} else {
+		_, err = a.Store.GetCampaign(rs.CampaignID)
+	}
+	if errors.Is(err, storage.ErrTemplateNotFound) || errors.Is(err, storage.ErrCampaignNotFound) {
+		writeErr(w, http.StatusBadRequest, err.Error())
+		return rs, false
+	}
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return rs, false
+	}
+	return rs, true
+}
+
+// Daftar jadwal berulang beserta next_run_at.
+func (a *API) handleListRecurring(w http.ResponseWriter, r *http.Request) {
+	list, err := a.Store.ListRecurringSchedules(false)
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	out := make([]recurringView, 0, len(list))
+	for _, rs := range list {
+		out = append(out, viewRecurring(rs, 1))
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+// Satu jadwal berulang beserta ?count= jadwal berikutnya (default 5, maks 50).
+func (a *API) handleGetRecurring(w http.ResponseWriter, r *http.Request) {
+	rs, err := a.Store.GetRecurringSchedule(chi.URLParam(r, "id"))
+	if errors.Is(err, storage.ErrRecurringNotFound) {
+		writeErr(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	n := 5
+	if c, err := strconv.Atoi(r.URL.Query().Get("count")); err == nil && c > 0 && c <= 50 {
+		n = c
+	}
+	writeJSON(w, http.StatusOK, viewRecurring(rs, n))
+}
+
+// Buat jadwal berulang {"name","days_mask":"Mon,Thu","times":["21:45"],"template_id"|"campaign_id",
+// "account_id","targeting":{"include_tags":["jualan"]},"priority","enabled"}.
+func (a *API) handleCreateRecurring(w http.ResponseWriter, r *http.Request) {
+	rs, ok := a.decodeRecurring(w, r)
+	if !ok {
+		return
+	}
+	id, err := a.Store.CreateRecurringSchedule(rs)
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if rs, err = a.Store.GetRecurringSchedule(id); err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusCreated, viewRecurring(rs, 1))
+}
+
+func (a *API) handleUpdateRecurring(w http.ResponseWriter, r *http.Request) {
+	rs, ok := a.decodeRecurring(w, r)
+	if !ok {
+		return
+	}
+	rs.ID = chi.URLParam(r, "id")
+	err := a.Store.UpdateRecurringSchedule(rs)
+	if errors.Is(err, storage.ErrRecurringNotFound) {
+		writeErr(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if rs, err = a.Store.GetRecurringSchedule(rs.ID); err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, viewRecurring(rs, 1))
+}
+
+func (a *API) handleDeleteRecurring(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	err := a.Store.DeleteRecurringSchedule(id)
+	if errors.Is(err, storage.ErrRecurringNotFound) {
+		writeErr(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"deleted": id})
+}
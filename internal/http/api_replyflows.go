@@ -0,0 +1,136 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+
+	"promote/internal/model"
+	"promote/internal/storage"
+)
+
+type replyFlowReq struct {
+	Keyword    string `json:"keyword"`
+	TemplateID string `json:"template_id"`
+	WindowMin  *int   `json:"window_min"`
+	MaxPerHour *int   `json:"max_per_hour"`
+	Enabled    *bool  `json:"enabled"`
+}
+
+func (a *API) handleListReplyFlows(w http.ResponseWriter, r *http.Request) {
+	list, err := a.Store.ListReplyFlows()
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if list == nil {
+		list = []model.ReplyFlow{}
+	}
+	writeJSON(w, http.StatusOK, list)
+}
+
+func (a *API) handleGetReplyFlow(w http.ResponseWriter, r *http.Request) {
+	f, err := a.Store.GetReplyFlow(chi.URLParam(r, "id"))
+	if errors.Is(err, storage.ErrReplyFlowNotFound) {
+		writeErr(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, f)
+}
+
+// Pasang/ganti alur "balas KATA": {"keyword":"PROMO","template_id":"...","window_min":60,"max_per_hour":20}.
+// Template follow-up boleh nonaktif (tidak ikut rotasi grup); yang dikirim hanya teks dan gambar pertamanya.
+func (a *API) handlePutReplyFlow(w http.ResponseWriter, r *http.Request) {
+	campaignID := chi.URLParam(r, "id")
+	if _, err := a.Store.GetCampaign(campaignID); err != nil {
+		if errors.Is(err, storage.ErrCampaignNotFound) {
+			writeErr(w, http.StatusNotFound, err.Error())
+		} else {
+			writeErr(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+	var req replyFlowReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErr(w, http.StatusBadRequest, "invalid JSON")
+		return
+	}
+	f := model.ReplyFlow{
+		CampaignID: campaignID,
+		Keyword:    strings.TrimSpace(req.Keyword),
+		TemplateID: strings.TrimSpace(req.TemplateID),
+		WindowMin:  intOr(req.WindowMin, 60),
+		MaxPerHour: intOr(req.MaxPerHour, 20),
+		Enabled:    req.Enabled == nil || *req.Enabled,
+	}
+	if err := f.Validate(); err != nil {
+		writeErr(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	t, err := a.Store.GetTemplate(f.TemplateID)
+	if errors.Is(err, storage.ErrTemplateNotFound) {
+		writeErr(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if strings.TrimSpace(t.TextOnly) == "" && len(t.ImageURLs) == 0 {
+		writeErr(w, http.StatusBadRequest, "follow-up template needs text or an image")
+		return
+	}
+	if err := a.Store.SaveReplyFlow(f); err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	saved, err := a.Store.GetReplyFlow(campaignID)
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, saved)
+}
+
+func (a *API) handleDeleteReplyFlow(w http.ResponseWriter, r *http.Request) {
+	err := a.Store.DeleteReplyFlow(chi.URLParam(r, "id"))
+	if errors.Is(err, storage.ErrReplyFlowNotFound) {
+		writeErr(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"deleted": true})
+}
+
+// Opt-in campaign beserta jumlah per status DM (?status=pending|sent|failed|suppressed|skipped).
+func (a *API) handleListOptIns(w http.ResponseWriter, r *http.Request) {
+	campaignID := chi.URLParam(r, "id")
+	list, err := a.Store.ListOptIns(campaignID, r.URL.Query().Get("status"))
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	counts, err := a.Store.OptInCounts(campaignID)
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if list == nil {
+		list = []model.ReplyOptIn{}
+	}
+	total := 0
+	for _, n := range counts {
+		total += n
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"total": total, "by_status": counts, "opt_ins": list})
+}
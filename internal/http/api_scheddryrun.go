@@ -0,0 +1,37 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// Mode dry-run scheduler: kiriman grup terjadwal dicatat sebagai log would_send (akun, grup,
+// template) tanpa memanggil WhatsApp.
+func (a *API) handleGetSchedulerDryRun(w http.ResponseWriter, r *http.Request) {
+	on, err := a.Store.SchedulerDryRun()
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"enabled": on})
+}
+
+// Aktifkan/matikan dry-run {"enabled": true}; berlaku mulai tick berikutnya.
+func (a *API) handleSetSchedulerDryRun(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Enabled *bool `json:"enabled"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErr(w, http.StatusBadRequest, "invalid JSON")
+		return
+	}
+	if req.Enabled == nil {
+		writeErr(w, http.StatusBadRequest, "enabled required")
+		return
+	}
+	if err := a.Store.SetSchedulerDryRun(*req.Enabled); err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"enabled": *req.Enabled})
+}
@@ -0,0 +1,103 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"promote/internal/model"
+	"promote/internal/webhook"
+)
+
+// Status scheduler: jeda global, mode dry-run, dan jendela kirim global saat ini.
+func (a *API) handleSchedulerStatus(w http.ResponseWriter, r *http.Request) {
+	p, err := a.Store.SchedulerPause()
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	a.writeSchedulerStatus(w, p)
+}
+
+// Jeda semua kiriman terjadwal mulai tick berikutnya (batch jadwal berhenti sebelum kirim
+// berikutnya) tanpa menghentikan proses: {"reason":"insiden ban"} opsional.
+func (a *API) handleSchedulerPause(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Reason string `json:"reason"`
+	}
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeErr(w, http.StatusBadRequest, "invalid JSON")
+			return
+		}
+	}
+	p, err := a.Store.SchedulerPause()
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if p.Paused {
+		a.writeSchedulerStatus(w, p)
+		return
+	}
+	now := time.Now().UTC()
+	p = model.SchedulerPause{Paused: true, Reason: strings.TrimSpace(req.Reason), PausedAt: &now}
+	if pr := principalFrom(r.Context()); pr != nil {
+		p.By = pr.Name
+	}
+	if err := a.Store.SetSchedulerPause(p); err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	a.Webhooks.Emit(webhook.EventSchedulerPaused, map[string]any{"reason": p.Reason, "by": p.By})
+	a.writeSchedulerStatus(w, p)
+}
+
+// Lanjutkan scheduler setelah dijeda.
+func (a *API) handleSchedulerResume(w http.ResponseWriter, r *http.Request) {
+	p, err := a.Store.SchedulerPause()
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if !p.Paused {
+		a.writeSchedulerStatus(w, p)
+		return
+	}
+	if err := a.Store.SetSchedulerPause(model.SchedulerPause{}); err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	by := ""
+	if pr := principalFrom(r.Context()); pr != nil {
+		by = pr.Name
+	}
+	a.Webhooks.Emit(webhook.EventSchedulerResumed, map[string]any{"by": by, "paused_at": p.PausedAt})
+	a.writeSchedulerStatus(w, model.SchedulerPause{})
+}
+
+func (a *API) writeSchedulerStatus(w http.ResponseWriter, p model.SchedulerPause) {
+	wins, err := a.Store.SendWindows()
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	dryRun, err := a.Store.SchedulerDryRun()
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	now := time.Now().In(wibLocation())
+	out := map[string]any{
+		"paused":    p.Paused,
+		"pause":     p,
+		"dry_run":   dryRun,
+		"tz":        now.Location().String(),
+		"in_window": wins.Active(now),
+	}
+	if next, ok := wins.Next(now); ok {
+		out["next_window"] = next
+	}
+	writeJSON(w, http.StatusOK, out)
+}
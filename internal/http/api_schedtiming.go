@@ -0,0 +1,57 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+// Ritme loop scheduler (interval tick, panjang jendela minimum, tidur di luar jendela, pacing).
+func (a *API) handleGetSchedulerTiming(w http.ResponseWriter, r *http.Request) {
+	t, err := a.Store.SchedulerTiming()
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, t)
+}
+
+// Ubah ritme (field yang tidak dikirim tetap): {"tick_sec":30,"min_window_min":15,"idle_sleep":true,"max_idle_min":15,
+// "pacing":"even"|"fast"}.
+// Berlaku mulai tick berikutnya.
+func (a *API) handleSetSchedulerTiming(w http.ResponseWriter, r *http.Request) {
+	t, err := a.Store.SchedulerTiming()
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	var req struct {
+		TickSec      *int    `json:"tick_sec"`
+		MinWindowMin *int    `json:"min_window_min"`
+		IdleSleep    *bool   `json:"idle_sleep"`
+		MaxIdleMin   *int    `json:"max_idle_min"`
+		Pacing       *string `json:"pacing"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErr(w, http.StatusBadRequest, "invalid JSON")
+		return
+	}
+	t.TickSec = intOr(req.TickSec, t.TickSec)
+	t.MinWindowMin = intOr(req.MinWindowMin, t.MinWindowMin)
+	t.MaxIdleMin = intOr(req.MaxIdleMin, t.MaxIdleMin)
+	if req.IdleSleep != nil {
+		t.IdleSleep = *req.IdleSleep
+	}
+	if req.Pacing != nil {
+		t.Pacing = strings.ToLower(strings.TrimSpace(*req.Pacing))
+	}
+	if err := t.Validate(); err != nil {
+		writeErr(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := a.Store.SetSchedulerTiming(t); err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, t)
+}
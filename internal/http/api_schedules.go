@@ -0,0 +1,152 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+
+	"promote/internal/model"
+	"promote/internal/storage"
+)
+
+type upsertScheduleReq struct {
+	CampaignID  string `json:"campaign_id,omitempty"`
+	AccountID   string `json:"account_id,omitempty"`
+	BatchSize   *int   `json:"batch_size"`
+	StartHour   *int   `json:"start_hour"`
+	EndHour     *int   `json:"end_hour"`
+	MinDelaySec *int   `json:"min_delay_sec"`
+	MaxDelaySec *int   `json:"max_delay_sec"`
+	DaysMask    string `json:"days_mask"`
+	DailyLimit  *int   `json:"daily_limit"`
+	Enabled     *bool  `json:"enabled"`
+}
+
+func intOr(p *int, def int) int {
+	if p == nil {
+		return def
+	}
+	return *p
+}
+
+// schedule mengisi kolom yang tidak dikirim dengan default tabel.
+func (req upsertScheduleReq) schedule() model.Schedule {
+	return model.Schedule{
+		CampaignID:  strings.TrimSpace(req.CampaignID),
+		AccountID:   strings.TrimSpace(req.AccountID),
+		BatchSize:   intOr(req.BatchSize, 50),
+		StartHour:   intOr(req.StartHour, 0),
+		EndHour:     intOr(req.EndHour, 24),
+		MinDelaySec: intOr(req.MinDelaySec, 30),
+		MaxDelaySec: intOr(req.MaxDelaySec, 120),
+		DaysMask:    strings.TrimSpace(req.DaysMask),
+		DailyLimit:  intOr(req.DailyLimit, 100),
+		Enabled:     req.Enabled == nil || *req.Enabled,
+	}
+}
+
+// decodeSchedule membaca body dengan default kolom tabel, memvalidasi, dan memastikan campaign
+// serta akun ada; false jika respons error sudah ditulis.
+func (a *API) decodeSchedule(w http.ResponseWriter, r *http.Request) (model.Schedule, bool) {
+	var req upsertScheduleReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErr(w, http.StatusBadRequest, "invalid JSON")
+		return model.Schedule{}, false
+	}
+	sch := req.schedule()
+	if err := sch.Validate(); err != nil {
+		writeErr(w, http.StatusBadRequest, err.Error())
+		return sch, false
+	}
+	if _, err := a.Store.GetCampaign(sch.CampaignID); err != nil {
+		if errors.Is(err, storage.ErrCampaignNotFound) {
+			writeErr(w, http.StatusBadRequest, err.Error())
+		} else {
+			writeErr(w, http.StatusInternalServerError, err.Error())
+		}
+		return sch, false
+	}
+	ok, err := a.Store.AccountExists(sch.AccountID)
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return sch, false
+	}
+	if !ok {
+		writeErr(w, http.StatusBadRequest, "account not found")
+		return sch, false
+	}
+	return sch, true
+}
+
+func (a *API) handleListSchedules(w http.ResponseWriter, r *http.Request) {
+	list, err := a.Store.ListSchedules(r.URL.Query().Get("campaign_id"), r.URL.Query().Get("account_id"))
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if list == nil {
+		list = []model.Schedule{}
+	}
+	writeJSON(w, http.StatusOK, list)
+}
+
+func (a *API) handleGetSchedule(w http.ResponseWriter, r *http.Request) {
+	sch, err := a.Store.GetSchedule(chi.URLParam(r, "id"))
+	if errors.Is(err, storage.ErrScheduleNotFound) {
+		writeErr(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, sch)
+}
+
+func (a *API) handleCreateSchedule(w http.ResponseWriter, r *http.Request) {
+	sch, ok := a.decodeSchedule(w, r)
+	if !ok {
+		return
+	}
+	id, err := a.Store.CreateSchedule(sch)
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusCreated, map[string]any{"id": id})
+}
+
+func (a *API) handleUpdateSchedule(w http.ResponseWriter, r *http.Request) {
+	sch, ok := a.decodeSchedule(w, r)
+	if !ok {
+		return
+	}
+	sch.ID = chi.URLParam(r, "id")
+	err := a.Store.UpdateSchedule(sch)
+	if errors.Is(err, storage.ErrScheduleNotFound) {
+		writeErr(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"updated": sch.ID})
+}
+
+func (a *API) handleDeleteSchedule(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	err := a.Store.DeleteSchedule(id)
+	if errors.Is(err, storage.ErrScheduleNotFound) {
+		writeErr(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"deleted": id})
+}
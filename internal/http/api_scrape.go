@@ -0,0 +1,55 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"promote/internal/scrape"
+)
+
+type scrapeStartReq struct {
+	AccountID string `json:"account_id"`
+	Force     bool   `json:"force"`
+}
+
+// Progress job scraping anggota yang sedang/terakhir berjalan.
+func (a *API) handleScrapeStatus(w http.ResponseWriter, r *http.Request) {
+	if a.Scraper == nil {
+		writeErr(w, http.StatusServiceUnavailable, "scraper not running")
+		return
+	}
+	writeJSON(w, http.StatusOK, a.Scraper.Status())
+}
+
+// Mulai job scraping (account_id kosong = semua akun); 409 jika masih ada job berjalan.
+func (a *API) handleScrapeStart(w http.ResponseWriter, r *http.Request) {
+	if a.Scraper == nil {
+		writeErr(w, http.StatusServiceUnavailable, "scraper not running")
+		return
+	}
+	var req scrapeStartReq
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeErr(w, http.StatusBadRequest, "invalid JSON")
+			return
+		}
+	}
+	switch err := a.Scraper.Run(req.AccountID, req.Force); {
+	case errors.Is(err, scrape.ErrRunning):
+		writeErr(w, http.StatusConflict, err.Error())
+	case err != nil:
+		writeErr(w, http.StatusInternalServerError, err.Error())
+	default:
+		writeJSON(w, http.StatusAccepted, a.Scraper.Status())
+	}
+}
+
+// Hentikan job scraping; grup yang sudah di-scrape tetap tersimpan di cache.
+func (a *API) handleScrapeStop(w http.ResponseWriter, r *http.Request) {
+	if a.Scraper == nil {
+		writeErr(w, http.StatusServiceUnavailable, "scraper not running")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"stopped": a.Scraper.Stop()})
+}
@@ -0,0 +1,137 @@
+package httpapi
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+
+	"promote/internal/model"
+	"promote/internal/storage"
+)
+
+// maxSegmentPreview jumlah contoh nomor di respons preview segmen.
+const maxSegmentPreview = 50
+
+// decodeSegment membaca dan memvalidasi model.SegmentRule; false jika respons error sudah ditulis.
+func decodeSegment(w http.ResponseWriter, r *http.Request) (model.SegmentRule, bool) {
+	var rule model.SegmentRule
+	if err := json.NewDecoder(r.Body).Decode(&rule); err != nil {
+		writeErr(w, http.StatusBadRequest, "invalid JSON")
+		return rule, false
+	}
+	if err := rule.Validate(); err != nil {
+		writeErr(w, http.StatusBadRequest, err.Error())
+		return rule, false
+	}
+	return rule, true
+}
+
+// Preview aturan segmen tanpa menyimpan: jumlah nomor cocok dan contoh teratas.
+func (a *API) handlePreviewSegment(w http.ResponseWriter, r *http.Request) {
+	rule, ok := decodeSegment(w, r)
+	if !ok {
+		return
+	}
+	matches, err := a.Store.SegmentNumbers(rule)
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	total := len(matches)
+	if len(matches) > maxSegmentPreview {
+		matches = matches[:maxSegmentPreview]
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"total": total, "sample": matches})
+}
+
+// Pasang/ganti aturan segmen audiens lalu refresh anggotanya.
+func (a *API) handleSetAudienceSegment(w http.ResponseWriter, r *http.Request) {
+	rule, ok := decodeSegment(w, r)
+	if !ok {
+		return
+	}
+	id := chi.URLParam(r, "id")
+	err := a.Store.SetAudienceSegment(id, &rule)
+	if errors.Is(err, storage.ErrAudienceNotFound) {
+		writeErr(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	a.refreshAudience(w, r)
+}
+
+// Lepas aturan segmen; anggota hasil refresh terakhir tetap ada sebagai daftar statis.
+func (a *API) handleClearAudienceSegment(w http.ResponseWriter, r *http.Request) {
+	err := a.Store.SetAudienceSegment(chi.URLParam(r, "id"), nil)
+	if errors.Is(err, storage.ErrAudienceNotFound) {
+		writeErr(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if aud, ok := a.getAudience(w, r); ok {
+		writeJSON(w, http.StatusOK, aud)
+	}
+}
+
+// Refresh anggota segmen sekarang (selain job berkala segment_refresh).
+func (a *API) handleRefreshAudience(w http.ResponseWriter, r *http.Request) {
+	a.refreshAudience(w, r)
+}
+
+func (a *API) refreshAudience(w http.ResponseWriter, r *http.Request) {
+	n, err := a.Store.RefreshSegment(chi.URLParam(r, "id"))
+	switch {
+	case errors.Is(err, storage.ErrAudienceNotFound):
+		writeErr(w, http.StatusNotFound, err.Error())
+		return
+	case errors.Is(err, storage.ErrNotSegment):
+		writeErr(w, http.StatusBadRequest, err.Error())
+		return
+	case err != nil:
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if aud, ok := a.getAudience(w, r); ok {
+		writeJSON(w, http.StatusOK, map[string]any{"matched": n, "audience": aud})
+	}
+}
+
+// Export anggota audiens sebagai CSV (number,name,source,added_at).
+func (a *API) handleExportAudienceCSV(w http.ResponseWriter, r *http.Request) {
+	aud, ok := a.getAudience(w, r)
+	if !ok {
+		return
+	}
+	list, err := a.Store.ListAudienceMembers(aud.ID, aud.MemberCount+1, 0)
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	name := strings.Map(func(r rune) rune {
+		if r == '"' || r == '/' || r == '\\' || r < ' ' {
+			return '_'
+		}
+		return r
+	}, aud.Name)
+	w.Header().Set("Content-Type", "text/csv")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"audience-%s.csv\"", name))
+	w.WriteHeader(http.StatusOK)
+	cw := csv.NewWriter(w)
+	defer cw.Flush()
+	_ = cw.Write([]string{"number", "name", "source", "added_at"})
+	for _, m := range list {
+		_ = cw.Write([]string{m.Number, m.Name, m.Source, m.AddedAt.UTC().Format("2006-01-02 15:04:05")})
+	}
+}
@@ -0,0 +1,150 @@
+package httpapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"promote/internal/model"
+	"promote/internal/storage"
+)
+
+// scheduleSendGrace toleransi waktu "at" di masa lalu (jam klien sedikit tertinggal).
+const scheduleSendGrace = 5 * time.Minute
+
+// scheduleSendReq body POST /api/send/schedule: konten seperti /api/send/test, atau template_id /
+// campaign_id, ke satu atau beberapa grup pada waktu at.
+type scheduleSendReq struct {
+	sendTestReq
+	GroupIDs   []string `json:"group_ids"`
+	At         string   `json:"at"`
+	TemplateID string   `json:"template_id"`
+	CampaignID string   `json:"campaign_id"`
+	Priority   *int     `json:"priority"`
+}
+
+// hasContent true jika body membawa konten kustom.
+func (req sendTestReq) hasContent() bool {
+	return strings.TrimSpace(req.TextOnly) != "" || len(req.ImageURLs) > 0 || len(req.VideoURLs) > 0 ||
+		len(req.Products) > 0 || len(req.GifURLs) > 0 || len(req.AudioURLs) > 0 || len(req.VoiceURLs) > 0 ||
+		len(req.StickerURLs) > 0 || len(req.DocURLs) > 0 || strings.TrimSpace(req.ContactPhone) != ""
+}
+
+// Jadwalkan kiriman sekali jalan {"account_id","group_id"|"group_ids":[...],"at": RFC3339, plus
+// konten /api/send/test atau "template_id"/"campaign_id"}. Item masuk outbox dan dikirim scheduler
+// saat jatuh tempo dengan cek risiko, cooldown dan retry seperti kiriman biasa.
+func (a *API) handleScheduleSend(w http.ResponseWriter, r *http.Request) {
+	var req scheduleSendReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErr(w, http.StatusBadRequest, "invalid JSON")
+		return
+	}
+	refs := req.GroupIDs
+	if req.GroupID != "" {
+		refs = append([]string{req.GroupID}, refs...)
+	}
+	if req.AccountID == "" || len(refs) == 0 {
+		writeErr(w, http.StatusBadRequest, "account_id and group_id or group_ids required")
+		return
+	}
+	at, err := time.Parse(time.RFC3339, strings.TrimSpace(req.At))
+	if err != nil {
+		writeErr(w, http.StatusBadRequest, "at must be RFC3339")
+		return
+	}
+	if at.Before(time.Now().Add(-scheduleSendGrace)) {
+		writeErr(w, http.StatusBadRequest, "at is in the past")
+		return
+	}
+	sources := 0
+	for _, set := range []bool{req.TemplateID != "", req.CampaignID != "", req.hasContent()} {
+		if set {
+			sources++
+		}
+	}
+	if sources != 1 {
+		writeErr(w, http.StatusBadRequest, "exactly one of template_id, campaign_id or content required")
+		return
+	}
+	if msg := req.validate(); msg != "" {
+		writeErr(w, http.StatusBadRequest, msg)
+		return
+	}
+	if req.TemplateID != "" {
+		if _, err := a.Store.GetTemplate(req.TemplateID); errors.Is(err, storage.ErrTemplateNotFound) {
+			writeErr(w, http.StatusNotFound, err.Error())
+			return
+		} else if err != nil {
+			writeErr(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+	if req.CampaignID != "" {
+		if _, err := a.Store.GetCampaign(req.CampaignID); errors.Is(err, storage.ErrCampaignNotFound) {
+			writeErr(w, http.StatusNotFound, err.Error())
+			return
+		} else if err != nil {
+			writeErr(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+	var content json.RawMessage
+	if req.hasContent() {
+		if content, err = json.Marshal(req.content()); err != nil {
+			writeErr(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+	accountID, err := a.Store.ResolveAccountRef(req.AccountID)
+	if errors.Is(err, storage.ErrAccountNotFound) {
+		writeErr(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if errors.Is(err, storage.ErrAccountAmbiguous) {
+		writeErr(w, http.StatusConflict, err.Error())
+		return
+	}
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), 60*time.Second)
+	defer cancel()
+	// Semua grup di-resolve dulu agar referensi yang salah tidak meninggalkan jadwal setengah jadi.
+	groupIDs := make([]string, 0, len(refs))
+	seen := map[string]bool{}
+	for _, ref := range refs {
+		groupID, err := a.resolveGroupRef(ctx, accountID, ref)
+		if err != nil {
+			writeGroupRefErr(w, err)
+			return
+		}
+		if !seen[groupID] {
+			seen[groupID] = true
+			groupIDs = append(groupIDs, groupID)
+		}
+	}
+	items := make([]model.OutboxItem, 0, len(groupIDs))
+	for _, groupID := range groupIDs {
+		it := model.OutboxItem{
+			AccountID:   accountID,
+			GroupID:     groupID,
+			CampaignID:  req.CampaignID,
+			TemplateID:  req.TemplateID,
+			Content:     content,
+			Priority:    intOr(req.Priority, 0),
+			Status:      model.OutboxPending,
+			Origin:      model.OutboxOriginScheduled,
+			ScheduledAt: at.UTC(),
+		}
+		if it.ID, err = a.Store.EnqueueOutbox(it); err != nil {
+			writeErr(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		items = append(items, it)
+	}
+	writeJSON(w, http.StatusCreated, map[string]any{"scheduled_at": at.UTC(), "items": items})
+}
@@ -0,0 +1,282 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"promote/internal/model"
+)
+
+// wibLocation zona waktu scheduler (Asia/Jakarta), fallback +07:00 jika tzdata tidak ada.
+func wibLocation() *time.Location {
+	loc, err := time.LoadLocation("Asia/Jakarta")
+	if err != nil || loc == nil {
+		loc = time.FixedZone("WIB", 7*3600)
+	}
+	return loc
+}
+
+// accountWindowsView jendela khusus satu akun.
+type accountWindowsView struct {
+	AccountID string            `json:"account_id"`
+	Days      model.WeekWindows `json:"days"`
+	InWindow  bool              `json:"in_window"`
+}
+
+// Jendela kirim grup per hari (WIB) yang dipakai scheduler beserta jendela khusus per akun.
+func (a *API) handleGetSendWindows(w http.ResponseWriter, r *http.Request) {
+	wins, err := a.Store.SendWindows()
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	perAccount, err := a.Store.AccountSendWindows()
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	now := time.Now().In(wibLocation())
+	accounts := []accountWindowsView{}
+	for id, aw := range perAccount {
+		accounts = append(accounts, accountWindowsView{AccountID: id, Days: aw, InWindow: aw.Active(now)})
+	}
+	sort.Slice(accounts, func(i, j int) bool { return accounts[i].AccountID < accounts[j].AccountID })
+	writeJSON(w, http.StatusOK, map[string]any{
+		"tz":        now.Location().String(),
+		"days":      wins,
+		"in_window": wins.Active(now),
+		"accounts":  accounts,
+	})
+}
+
+// sendWindowsReq body ubah jendela: days (parsial per days mask) atau reset.
+type sendWindowsReq struct {
+	Reset bool            `json:"reset"`
+	Days  json.RawMessage `json:"days"`
+}
+
+// apply menerapkan req ke wins; reset diganti def. ok=false jika body tidak berisi perubahan.
+func (req sendWindowsReq) apply(wins, def model.WeekWindows) (model.WeekWindows, error) {
+	if req.Reset {
+		wins = def
+	}
+	if len(req.Days) > 0 {
+		if err := json.Unmarshal(req.Days, &wins); err != nil {
+			return wins, err
+		}
+	} else if !req.Reset {
+		return wins, errors.New("days or reset required")
+	}
+	return wins, nil
+}
+
+// Ubah jendela per hari: {"days":{"mon-fri":["00:45-02:30","21:30-23:30"],"sat-sun":["08:00-10:00"]}}.
+// Kunci berupa days mask; hari yang tidak disebut tetap, daftar kosong = tidak kirim di hari itu.
+// {"reset":true} mengembalikan jendela bawaan untuk semua hari.
+func (a *API) handleSetSendWindows(w http.ResponseWriter, r *http.Request) {
+	wins, err := a.Store.SendWindows()
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	var req sendWindowsReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErr(w, http.StatusBadRequest, "invalid JSON")
+		return
+	}
+	if wins, err = req.apply(wins, model.DefaultWeekWindows()); err != nil {
+		writeErr(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := a.Store.SetSendWindows(wins); err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	now := time.Now().In(wibLocation())
+	writeJSON(w, http.StatusOK, map[string]any{"tz": now.Location().String(), "days": wins, "in_window": wins.Active(now)})
+}
+
+// Jendela kirim yang berlaku untuk akun: jendela khusus jika ada ("custom": true), selain itu
+// jendela global.
+func (a *API) handleGetAccountSendWindows(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "accountID")
+	if !a.accountExists(w, id) {
+		return
+	}
+	wins, custom, err := a.Store.AccountSendWindow(id)
+	if err == nil && !custom {
+		wins, err = a.Store.SendWindows()
+	}
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	a.writeAccountSendWindows(w, id, wins, custom)
+}
+
+// Jendela khusus akun dengan body sama seperti jendela global. Akun tanpa jendela khusus mulai
+// dari salinan jendela global; {"reset":true} menghapus jendela khusus (kembali ke global).
+func (a *API) handleSetAccountSendWindows(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "accountID")
+	if !a.accountExists(w, id) {
+		return
+	}
+	var req sendWindowsReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErr(w, http.StatusBadRequest, "invalid JSON")
+		return
+	}
+	global, err := a.Store.SendWindows()
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if req.Reset && len(req.Days) == 0 {
+		if _, err := a.Store.DeleteAccountSendWindow(id); err != nil {
+			writeErr(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		a.writeAccountSendWindows(w, id, global, false)
+		return
+	}
+	wins, custom, err := a.Store.AccountSendWindow(id)
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if !custom {
+		wins = global
+	}
+	if wins, err = req.apply(wins, global); err != nil {
+		writeErr(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := a.Store.SetAccountSendWindow(id, wins); err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	a.writeAccountSendWindows(w, id, wins, true)
+}
+
+// Hapus jendela khusus akun; akun kembali memakai jendela global.
+func (a *API) handleDeleteAccountSendWindows(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "accountID")
+	ok, err := a.Store.DeleteAccountSendWindow(id)
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if !ok {
+		writeErr(w, http.StatusNotFound, "account has no custom windows")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"deleted": true})
+}
+
+func (a *API) writeAccountSendWindows(w http.ResponseWriter, id string, wins model.WeekWindows, custom bool) {
+	now := time.Now().In(wibLocation())
+	writeJSON(w, http.StatusOK, map[string]any{
+		"tz":         now.Location().String(),
+		"account_id": id,
+		"custom":     custom,
+		"days":       wins,
+		"in_window":  wins.Active(now),
+	})
+}
+
+// Perkiraan jendela kirim berikutnya dalam ?days=7 hari (maks 31) menurut jendela per hari.
+func (a *API) handleSendWindowForecast(w http.ResponseWriter, r *http.Request) {
+	days := 7
+	if n, err := strconv.Atoi(r.URL.Query().Get("days")); err == nil && n > 0 && n <= 31 {
+		days = n
+	}
+	wins, err := a.Store.SendWindows()
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	now := time.Now().In(wibLocation())
+	list := wins.Upcoming(now, days)
+	if list == nil {
+		list = []model.Occurrence{}
+	}
+	var minutes int
+	for _, o := range list {
+		start := o.Start
+		if start.Before(now) {
+			start = now
+		}
+		minutes += int(o.End.Sub(start) / time.Minute)
+	}
+	out := map[string]any{
+		"tz":           now.Location().String(),
+		"now":          now.Format(time.RFC3339),
+		"in_window":    wins.Active(now),
+		"windows":      list,
+		"open_minutes": minutes,
+	}
+	if next, ok := wins.Next(now); ok {
+		out["next"] = next
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+// windowShare porsi satu jendela hari ini dalam pembagian limit harian.
+type windowShare struct {
+	Window string  `json:"window"`
+	Pct    float64 `json:"pct"`
+}
+
+// Pembagian limit harian ke jendela kirim beserta porsi tiap jendela hari ini.
+func (a *API) handleGetWindowBudget(w http.ResponseWriter, r *http.Request) {
+	b, err := a.Store.WindowBudget()
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	a.writeWindowBudget(w, b)
+}
+
+// Ubah pembagian: {"mode":"proportional"} sesuai durasi jendela, {"mode":"weighted","weights":[50,30,20]}
+// per jendela urut jam mulai, atau {"mode":"off"}. Berlaku mulai tick berikutnya.
+func (a *API) handleSetWindowBudget(w http.ResponseWriter, r *http.Request) {
+	var b model.WindowBudget
+	if err := json.NewDecoder(r.Body).Decode(&b); err != nil {
+		writeErr(w, http.StatusBadRequest, "invalid JSON")
+		return
+	}
+	if err := b.Validate(); err != nil {
+		writeErr(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if b.Mode != model.WindowBudgetWeighted {
+		b.Weights = nil
+	}
+	if err := a.Store.SetWindowBudget(b); err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	a.writeWindowBudget(w, b)
+}
+
+func (a *API) writeWindowBudget(w http.ResponseWriter, b model.WindowBudget) {
+	wins, err := a.Store.SendWindows()
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	now := time.Now().In(wibLocation())
+	ordered, shares := b.Shares(wins.For(now), a.Store.Day.ResetHour)
+	today := []windowShare{}
+	for i, win := range ordered {
+		today = append(today, windowShare{Window: model.FormatWindow(win), Pct: math.Round(shares[i]*1000) / 10})
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"mode": b.Mode, "weights": b.Weights, "today": today})
+}
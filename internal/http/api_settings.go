@@ -0,0 +1,62 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"promote/internal/model"
+	"promote/internal/storage"
+)
+
+// Status safe mode beserta akun yang belum punya grup uji (kiriman akun tsb akan dibatalkan).
+func (a *API) handleGetSafeMode(w http.ResponseWriter, r *http.Request) {
+	on, err := a.Store.SettingBool(storage.SettingSafeMode)
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	accs, err := a.Store.ListAccounts()
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	testGroups := map[string]string{}
+	missing := []model.Account{}
+	for _, acc := range accs {
+		gid, err := a.Store.TestGroupForAccount(acc.ID)
+		if err != nil {
+			writeErr(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		if gid == "" {
+			missing = append(missing, acc)
+			continue
+		}
+		testGroups[acc.ID] = gid
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"enabled":               on,
+		"test_groups":           testGroups,
+		"accounts_without_test": missing,
+	})
+}
+
+// Aktif/nonaktifkan safe mode: {"enabled": true}.
+func (a *API) handleSetSafeMode(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Enabled bool `json:"enabled"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErr(w, http.StatusBadRequest, "invalid JSON")
+		return
+	}
+	v := "0"
+	if req.Enabled {
+		v = "1"
+	}
+	if err := a.Store.SetSetting(storage.SettingSafeMode, v); err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"enabled": req.Enabled})
+}
@@ -0,0 +1,127 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"promote/internal/model"
+	"promote/internal/shortlink"
+	"promote/internal/storage"
+)
+
+type createShortLinkReq struct {
+	TargetURL  string `json:"target_url"`
+	Slug       string `json:"slug"`
+	CampaignID string `json:"campaign_id"`
+	// TTLHours: 0 = default SHORTLINK_TTL_DAYS, -1 = tanpa kedaluwarsa.
+	TTLHours int `json:"ttl_hours"`
+}
+
+func (a *API) handleListShortLinks(w http.ResponseWriter, r *http.Request) {
+	if a.Links == nil {
+		writeErr(w, http.StatusServiceUnavailable, "short links not configured")
+		return
+	}
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	list, err := a.Store.ListShortLinks(r.URL.Query().Get("campaign_id"), limit)
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if list == nil {
+		list = []model.ShortLink{}
+	}
+	for i := range list {
+		list[i].ShortURL = a.Links.URL(list[i].Slug)
+	}
+	writeJSON(w, http.StatusOK, list)
+}
+
+func (a *API) handleCreateShortLink(w http.ResponseWriter, r *http.Request) {
+	if a.Links == nil {
+		writeErr(w, http.StatusServiceUnavailable, "short links not configured")
+		return
+	}
+	var req createShortLinkReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErr(w, http.StatusBadRequest, "invalid JSON")
+		return
+	}
+	opt := shortlink.CreateOptions{
+		Slug:       strings.TrimSpace(req.Slug),
+		CampaignID: strings.TrimSpace(req.CampaignID),
+		TTL:        time.Duration(req.TTLHours) * time.Hour,
+	}
+	l, err := a.Links.Create(req.TargetURL, opt)
+	if err != nil {
+		if errors.Is(err, storage.ErrSlugTaken) {
+			writeErr(w, http.StatusConflict, err.Error())
+			return
+		}
+		writeErr(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusCreated, l)
+}
+
+func (a *API) handleDeleteShortLink(w http.ResponseWriter, r *http.Request) {
+	n, err := a.Store.DeleteShortLink(chi.URLParam(r, "slug"))
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if n == 0 {
+		writeErr(w, http.StatusNotFound, "short link not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"deleted": n})
+}
+
+// Redirect /r/{slug} ke target dan catat klik.
+func (a *API) handleShortLinkRedirect(w http.ResponseWriter, r *http.Request) {
+	a.redirectSlug(w, r, chi.URLParam(r, "slug"))
+}
+
+func (a *API) redirectSlug(w http.ResponseWriter, r *http.Request, slug string) {
+	if a.Links == nil {
+		http.NotFound(w, r)
+		return
+	}
+	// Fetch link preview kita sendiri (User-Agent promotenews) tidak dihitung sebagai klik.
+	count := !strings.Contains(r.UserAgent(), "promotenews")
+	target, ok, err := a.Links.Resolve(slug, count)
+	if err != nil {
+		log.Printf("[shortlink] resolve %s: %v", slug, err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Cache-Control", "no-store")
+	http.Redirect(w, r, target, http.StatusFound)
+}
+
+// shortLinkHost melayani "/<slug>" langsung di root untuk request yang datang lewat
+// domain short link (SHORTLINK_BASE_URL), sehingga dashboard tetap di host lain.
+func (a *API) shortLinkHost(next http.Handler) http.Handler {
+	host := a.Links.Host()
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if host != "" && strings.EqualFold(r.Host, host) && r.Method == http.MethodGet {
+			slug := strings.Trim(r.URL.Path, "/")
+			if slug != "" && !strings.Contains(slug, "/") {
+				a.redirectSlug(w, r, slug)
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
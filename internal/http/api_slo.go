@@ -0,0 +1,96 @@
+package httpapi
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"promote/internal/model"
+	"promote/internal/storage"
+)
+
+// processStart waktu proses mulai, untuk uptime layanan di /api/slo.
+var processStart = time.Now()
+
+// Laporan SLO: round-trip kirim WhatsApp per pesan, keterlambatan kirim terjadwal terhadap
+// rencananya, dan uptime akun. Query: hours (default 24, maks 720), account_id (UUID atau
+// label:/msisdn:), on_time_sec (ambang "tepat waktu", default 60).
+func (a *API) handleSLO(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	hours := 24
+	if v := q.Get("hours"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > 720 {
+			writeErr(w, http.StatusBadRequest, "hours must be between 1 and 720")
+			return
+		}
+		hours = n
+	}
+	onTimeSec := 60
+	if v := q.Get("on_time_sec"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			writeErr(w, http.StatusBadRequest, "on_time_sec must be >= 0")
+			return
+		}
+		onTimeSec = n
+	}
+	accountID := q.Get("account_id")
+	if accountID != "" {
+		id, err := a.Store.ResolveAccountRef(accountID)
+		if errors.Is(err, storage.ErrAccountNotFound) {
+			writeErr(w, http.StatusNotFound, err.Error())
+			return
+		}
+		if errors.Is(err, storage.ErrAccountAmbiguous) {
+			writeErr(w, http.StatusConflict, err.Error())
+			return
+		}
+		if err != nil {
+			writeErr(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		accountID = id
+	}
+
+	until := time.Now()
+	since := until.Add(-time.Duration(hours) * time.Hour)
+	rtt, lag, err := a.Store.SLOSamples(since, accountID)
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	onTime := 0
+	for _, ms := range lag {
+		if ms <= int64(onTimeSec)*1000 {
+			onTime++
+		}
+	}
+	onTimePct := 100.0
+	if len(lag) > 0 {
+		onTimePct = float64(onTime) * 100 / float64(len(lag))
+	}
+	accounts, err := a.Store.AccountUptimes(since, until, accountID)
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"window_hours": hours,
+		"since":        since.UTC().Format(time.RFC3339),
+		"until":        until.UTC().Format(time.RFC3339),
+		"process": map[string]any{
+			"started_at": processStart.UTC().Format(time.RFC3339),
+			"uptime_sec": int64(time.Since(processStart).Seconds()),
+		},
+		"send_latency": model.Summarize(rtt),
+		"schedule_lag": map[string]any{
+			"summary":               model.Summarize(lag),
+			"on_time_threshold_sec": onTimeSec,
+			"on_time":               onTime,
+			"on_time_pct":           onTimePct,
+		},
+		"accounts": accounts,
+	})
+}
@@ -0,0 +1,130 @@
+package httpapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"promote/internal/model"
+	"promote/internal/sender"
+	"promote/internal/storage"
+)
+
+// Ubah limit status (story) harian akun; 0 mematikan posting status terjadwal.
+func (a *API) handleSetStatusLimit(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		StatusDailyLimit int `json:"status_daily_limit"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErr(w, http.StatusBadRequest, "invalid JSON")
+		return
+	}
+	if req.StatusDailyLimit < 0 || req.StatusDailyLimit > 30 {
+		writeErr(w, http.StatusBadRequest, "status_daily_limit must be between 0 and 30")
+		return
+	}
+	err := a.Store.SetStatusDailyLimit(chi.URLParam(r, "id"), req.StatusDailyLimit)
+	if errors.Is(err, storage.ErrAccountNotFound) {
+		writeErr(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"status_daily_limit": req.StatusDailyLimit})
+}
+
+// Posting status manual: {"template_id":"..."} atau body kosong untuk template aktif acak.
+// Tidak terikat limit harian maupun jendela kirim.
+func (a *API) handlePostStatus(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		TemplateID string `json:"template_id"`
+	}
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeErr(w, http.StatusBadRequest, "invalid JSON")
+			return
+		}
+	}
+	id := chi.URLParam(r, "id")
+	if !a.accountExists(w, id) {
+		return
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), 2*time.Minute)
+	defer cancel()
+	var err error
+	if req.TemplateID != "" {
+		var t model.Template
+		t, err = a.Store.GetTemplate(req.TemplateID)
+		if err == nil {
+			err = a.Sender.PostStatus(ctx, id, sender.TemplateContent(t))
+		}
+	} else {
+		err = a.Sender.PostRandomTemplateStatus(ctx, id)
+	}
+	switch {
+	case err == nil:
+		writeJSON(w, http.StatusOK, map[string]any{"posted": id})
+	case errors.Is(err, storage.ErrTemplateNotFound):
+		writeErr(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, sender.ErrObserverAccount), errors.Is(err, sender.ErrSafeModeBroadcast):
+		writeErr(w, http.StatusConflict, err.Error())
+	case errors.Is(err, sender.ErrEmptyStatus):
+		writeErr(w, http.StatusBadRequest, err.Error())
+	default:
+		writeErr(w, http.StatusBadGateway, err.Error())
+	}
+}
+
+// Riwayat posting status terbaru (?account_id=&limit=50).
+func (a *API) handleListStatusPosts(w http.ResponseWriter, r *http.Request) {
+	limit := 50
+	if n, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && n > 0 && n <= 500 {
+		limit = n
+	}
+	list, err := a.Store.ListStatusPosts(r.URL.Query().Get("account_id"), limit)
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if list == nil {
+		list = []model.StatusPost{}
+	}
+	writeJSON(w, http.StatusOK, list)
+}
+
+// Jeda minimum antar status terjadwal satu akun.
+func (a *API) handleGetStatusSettings(w http.ResponseWriter, r *http.Request) {
+	n, err := a.Store.StatusMinInterval()
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"min_interval_min": n})
+}
+
+// Ubah jeda minimum antar status: {"min_interval_min":180}.
+func (a *API) handleSetStatusSettings(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		MinIntervalMin int `json:"min_interval_min"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErr(w, http.StatusBadRequest, "invalid JSON")
+		return
+	}
+	if req.MinIntervalMin < 1 || req.MinIntervalMin > 24*60 {
+		writeErr(w, http.StatusBadRequest, "min_interval_min must be between 1 and 1440")
+		return
+	}
+	if err := a.Store.SetStatusMinInterval(req.MinIntervalMin); err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"min_interval_min": req.MinIntervalMin})
+}
@@ -0,0 +1,116 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+
+	"promote/internal/model"
+	"promote/internal/storage"
+)
+
+type suppressionReq struct {
+	Numbers  []string `json:"numbers"`
+	GroupIDs []string `json:"group_ids"`
+	Reason   string   `json:"reason"`
+}
+
+// suppressionGroupID menerima JID grup lengkap (…@g.us) atau shorthand digit; "" jika bukan grup.
+func suppressionGroupID(ref string) string {
+	ref = strings.ReplaceAll(strings.TrimSpace(ref), " ", "")
+	switch {
+	case strings.HasSuffix(ref, "@g.us"):
+		return ref
+	case groupShorthand.MatchString(ref):
+		return strings.TrimPrefix(ref, "+") + "@g.us"
+	}
+	return ""
+}
+
+// Daftar supresi global: nomor (tidak di-DM/di-mention) dan grup (tidak dikirimi promo).
+func (a *API) handleListSuppressions(w http.ResponseWriter, r *http.Request) {
+	numbers, err := a.Store.ListDMSuppressions()
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	groups, err := a.Store.ListGroupSuppressions()
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if numbers == nil {
+		numbers = []model.DMSuppression{}
+	}
+	if groups == nil {
+		groups = []model.GroupSuppression{}
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"numbers": numbers, "groups": groups})
+}
+
+// Tambah nomor dan/atau grup ke daftar supresi; entri tidak valid dikembalikan di "invalid".
+func (a *API) handleAddSuppressions(w http.ResponseWriter, r *http.Request) {
+	var req suppressionReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErr(w, http.StatusBadRequest, "invalid JSON")
+		return
+	}
+	if len(req.Numbers) == 0 && len(req.GroupIDs) == 0 {
+		writeErr(w, http.StatusBadRequest, "numbers or group_ids required")
+		return
+	}
+	reason := strings.TrimSpace(req.Reason)
+	numbers, groups, invalid := 0, 0, []string{}
+	for _, in := range req.Numbers {
+		n := storage.NormalizeNumber(in)
+		if n == "" {
+			invalid = append(invalid, in)
+			continue
+		}
+		if err := a.Store.AddDMSuppression(n, reason, model.SuppressionManual); err != nil {
+			writeErr(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		numbers++
+	}
+	for _, in := range req.GroupIDs {
+		gid := suppressionGroupID(in)
+		if gid == "" {
+			invalid = append(invalid, in)
+			continue
+		}
+		if err := a.Store.AddGroupSuppression(gid, reason); err != nil {
+			writeErr(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		groups++
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"numbers_added": numbers, "groups_added": groups, "invalid": invalid})
+}
+
+// Hapus satu entri supresi: JID grup (…@g.us) atau nomor.
+func (a *API) handleRemoveSuppression(w http.ResponseWriter, r *http.Request) {
+	target, _ := url.PathUnescape(chi.URLParam(r, "target"))
+	var (
+		ok  bool
+		err error
+	)
+	if strings.HasSuffix(target, "@g.us") {
+		ok, err = a.Store.RemoveGroupSuppression(target)
+	} else {
+		target = storage.NormalizeNumber(target)
+		ok, err = a.Store.RemoveDMSuppression(target)
+	}
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if !ok {
+		writeErr(w, http.StatusNotFound, "not suppressed")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"removed": target})
+}
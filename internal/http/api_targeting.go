@@ -0,0 +1,139 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"promote/internal/model"
+	"promote/internal/storage"
+)
+
+type patchGroupReq struct {
+	Tags      *[]string `json:"tags"`
+	Language  *string   `json:"language"`
+	TestGroup *bool     `json:"test_group"`
+}
+
+// Ubah atribut grup: {"tags": ["kuliner","bandung"], "language": "id", "test_group": true}.
+// Field yang tidak dikirim tidak diubah; tags menggantikan seluruh tag grup; test_group=true
+// menjadikan grup ini satu-satunya grup uji akunnya.
+func (a *API) handlePatchGroup(w http.ResponseWriter, r *http.Request) {
+	gid, ok := a.groupFromURL(w, r)
+	if !ok {
+		return
+	}
+	var req patchGroupReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErr(w, http.StatusBadRequest, "invalid JSON")
+		return
+	}
+	if req.Tags != nil {
+		if err := a.Store.SetGroupTags(gid, *req.Tags); err != nil {
+			writeErr(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+	if req.Language != nil {
+		if err := a.Store.SetGroupLanguage(gid, *req.Language); err != nil {
+			writeErr(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+	if req.TestGroup != nil {
+		if err := a.Store.SetTestGroup(gid, *req.TestGroup); err != nil {
+			writeErr(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"updated": gid})
+}
+
+func (a *API) handleListTags(w http.ResponseWriter, r *http.Request) {
+	list, err := a.Store.ListTags()
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if list == nil {
+		list = []storage.TagCount{}
+	}
+	writeJSON(w, http.StatusOK, list)
+}
+
+func (a *API) handleGetCampaignTargeting(w http.ResponseWriter, r *http.Request) {
+	t, ok, err := a.Store.GetCampaignTargeting(chi.URLParam(r, "id"))
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if !ok {
+		writeErr(w, http.StatusNotFound, "campaign not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, t)
+}
+
+// Simpan targeting campaign; body {} menghapus filter (campaign tidak membatasi grup).
+func (a *API) handleSetCampaignTargeting(w http.ResponseWriter, r *http.Request) {
+	var t model.Targeting
+	if err := json.NewDecoder(r.Body).Decode(&t); err != nil {
+		writeErr(w, http.StatusBadRequest, "invalid JSON")
+		return
+	}
+	if err := t.Validate(); err != nil {
+		writeErr(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	n, err := a.Store.SetCampaignTargeting(chi.URLParam(r, "id"), t)
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if n == 0 {
+		writeErr(w, http.StatusNotFound, "campaign not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, t)
+}
+
+// Pratinjau grup aktif yang cocok: {"targeting": {...}, "account_id": "..."} atau
+// {"campaign_id": "..."} untuk memakai targeting tersimpan.
+func (a *API) handlePreviewTargeting(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Targeting  model.Targeting `json:"targeting"`
+		CampaignID string          `json:"campaign_id"`
+		AccountID  string          `json:"account_id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErr(w, http.StatusBadRequest, "invalid JSON")
+		return
+	}
+	t := req.Targeting
+	if req.CampaignID != "" {
+		saved, ok, err := a.Store.GetCampaignTargeting(req.CampaignID)
+		if err != nil {
+			writeErr(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		if !ok {
+			writeErr(w, http.StatusNotFound, "campaign not found")
+			return
+		}
+		t = saved
+	}
+	if err := t.Validate(); err != nil {
+		writeErr(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	ids, err := a.Store.TargetGroups(t, req.AccountID)
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if ids == nil {
+		ids = []string{}
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"count": len(ids), "group_ids": ids})
+}
@@ -0,0 +1,51 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"promote/internal/model"
+)
+
+// Konfigurasi mirror Telegram; bot token tidak dikembalikan (has_token menandai sudah diisi).
+func (a *API) handleGetTelegramMirror(w http.ResponseWriter, r *http.Request) {
+	m, err := a.Store.TelegramMirror()
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	m.BotToken = ""
+	writeJSON(w, http.StatusOK, m)
+}
+
+// Ubah mirror Telegram {"enabled":true,"bot_token":"123:ABC","chat_id":"@channel"}; field yang
+// tidak dikirim (termasuk bot_token) tetap.
+func (a *API) handleSetTelegramMirror(w http.ResponseWriter, r *http.Request) {
+	m, err := a.Store.TelegramMirror()
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	token := m.BotToken
+	if err := json.NewDecoder(r.Body).Decode(&m); err != nil {
+		writeErr(w, http.StatusBadRequest, "invalid JSON")
+		return
+	}
+	if m.BotToken = strings.TrimSpace(m.BotToken); m.BotToken == "" {
+		m.BotToken = token
+	}
+	m.ChatID = strings.TrimSpace(m.ChatID)
+	if err := m.Validate(); err != nil {
+		writeErr(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := a.Store.SetTelegramMirror(m); err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if a.Sender != nil {
+		a.Sender.SetTelegramMirror(m)
+	}
+	writeJSON(w, http.StatusOK, model.TelegramMirror{Enabled: m.Enabled, HasToken: m.BotToken != "", ChatID: m.ChatID})
+}
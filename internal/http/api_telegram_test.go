@@ -0,0 +1,35 @@
+package httpapi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestTelegramMirrorSettingsHideToken(t *testing.T) {
+	h, st := newTestRouter(t)
+	put := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPut, "/api/settings/telegram", strings.NewReader(body))
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+	if rec := put(`{"enabled":true,"chat_id":"@promo"}`); rec.Code != http.StatusBadRequest {
+		t.Fatalf("enable without token = %d, want 400", rec.Code)
+	}
+	if rec := put(`{"enabled":true,"bot_token":"123:secret","chat_id":"@promo"}`); rec.Code != http.StatusOK || strings.Contains(rec.Body.String(), "secret") {
+		t.Fatalf("set mirror = %d %s, want 200 without token", rec.Code, rec.Body.String())
+	}
+	// Token yang tidak dikirim ulang tetap tersimpan.
+	if rec := put(`{"chat_id":"@promo2"}`); rec.Code != http.StatusOK {
+		t.Fatalf("update chat = %d %s", rec.Code, rec.Body.String())
+	}
+	rec := doRequest(h, http.MethodGet, "/api/settings/telegram", "")
+	if body := rec.Body.String(); strings.Contains(body, "secret") || !strings.Contains(body, `"has_token":true`) || !strings.Contains(body, "@promo2") {
+		t.Fatalf("get mirror = %s, want chat @promo2 with hidden token", body)
+	}
+	if m, err := st.TelegramMirror(); err != nil || m.BotToken != "123:secret" || !m.Enabled {
+		t.Fatalf("stored mirror = %+v err=%v", m, err)
+	}
+}
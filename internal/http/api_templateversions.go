@@ -0,0 +1,70 @@
+package httpapi
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+
+	"promote/internal/storage"
+)
+
+// Riwayat versi template {id}, terbaru dulu (tetap tersedia setelah template dihapus).
+func (a *API) handleListTemplateVersions(w http.ResponseWriter, r *http.Request) {
+	list, err := a.Store.ListTemplateVersions(chi.URLParam(r, "id"))
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if len(list) == 0 {
+		writeErr(w, http.StatusNotFound, storage.ErrTemplateNotFound.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, list)
+}
+
+func (a *API) handleGetTemplateVersion(w http.ResponseWriter, r *http.Request) {
+	version, err := strconv.Atoi(chi.URLParam(r, "version"))
+	if err != nil || version <= 0 {
+		writeErr(w, http.StatusBadRequest, "invalid version")
+		return
+	}
+	a.writeTemplateVersion(w, chi.URLParam(r, "id"), version)
+}
+
+// Konten yang sebenarnya dikirim oleh log {id}: snapshot versi template saat itu.
+func (a *API) handleLogContent(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		writeErr(w, http.StatusBadRequest, "invalid id")
+		return
+	}
+	e, err := a.Store.GetLog(id)
+	if errors.Is(err, storage.ErrLogNotFound) {
+		writeErr(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if e.TemplateID == "" || e.TemplateVersion == 0 {
+		writeErr(w, http.StatusNotFound, "log has no template version")
+		return
+	}
+	a.writeTemplateVersion(w, e.TemplateID, e.TemplateVersion)
+}
+
+func (a *API) writeTemplateVersion(w http.ResponseWriter, templateID string, version int) {
+	v, err := a.Store.GetTemplateVersion(templateID, version)
+	if errors.Is(err, storage.ErrTemplateVersionNotFound) {
+		writeErr(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, v)
+}
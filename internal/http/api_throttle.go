@@ -0,0 +1,26 @@
+package httpapi
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// Akhiri cooldown throttle akun (setelah WhatsApp menolak kiriman karena rate-limit/spam) lebih
+// awal; akun langsung boleh mengirim lagi.
+func (a *API) handleClearThrottle(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	ok, err := a.Store.ClearAccountThrottle(id)
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if !ok {
+		writeErr(w, http.StatusNotFound, "account not found")
+		return
+	}
+	if a.Manager != nil {
+		a.Manager.EmitAccountEvent(id, "throttle_cleared", "manual override")
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"cleared": id})
+}
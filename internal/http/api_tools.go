@@ -0,0 +1,74 @@
+package httpapi
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"promote/internal/ogp"
+)
+
+type ogDraftReq struct {
+	URL         string `json:"url"`
+	ImportImage *bool  `json:"import_image"`
+}
+
+// Scrape Open Graph dari URL dan kembalikan draft template (belum disimpan).
+// Jika og:image ada dan import_image tidak false, gambar diunduh ke uploads dan
+// draft berupa gambar + caption; selain itu draft teks dengan link preview aktif.
+func (a *API) handleOGDraft(w http.ResponseWriter, r *http.Request) {
+	var req ogDraftReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErr(w, http.StatusBadRequest, "invalid JSON")
+		return
+	}
+	u, err := url.Parse(strings.TrimSpace(req.URL))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		writeErr(w, http.StatusBadRequest, "valid http(s) url required")
+		return
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+	defer cancel()
+	client := a.Sender.Client
+	p, err := ogp.Fetch(ctx, client, u.String())
+	if err != nil {
+		writeErr(w, http.StatusBadGateway, err.Error())
+		return
+	}
+
+	var lines []string
+	if p.Title != "" {
+		lines = append(lines, "*"+p.Title+"*")
+	}
+	if p.Description != "" {
+		lines = append(lines, p.Description)
+	}
+	lines = append(lines, p.URL)
+	text := strings.Join(lines, "\n\n")
+	name := p.Title
+	if name == "" {
+		name = u.Host
+	}
+	draft := map[string]any{
+		"name":    name,
+		"enabled": false,
+	}
+	if p.Image != "" && (req.ImportImage == nil || *req.ImportImage) {
+		local, err := ogp.ImportImage(ctx, client, p.Image, a.uploadDir(), 0)
+		if err == nil {
+			draft["image_urls"] = []string{local}
+			draft["image_caption"] = text
+			draft["link_preview"] = false
+			writeJSON(w, http.StatusOK, map[string]any{"preview": p, "draft": draft})
+			return
+		}
+		log.Printf("[og-draft] import image %s: %v", p.Image, err)
+	}
+	draft["text_only"] = text
+	draft["link_preview"] = true
+	writeJSON(w, http.StatusOK, map[string]any{"preview": p, "draft": draft})
+}
@@ -0,0 +1,43 @@
+package httpapi
+
+import (
+	"errors"
+	"net/http"
+
+	"promote/internal/wa"
+)
+
+// waErrStatus status HTTP untuk error wa.Manager; error yang tidak dikenal dianggap kegagalan
+// upstream WhatsApp (502).
+func waErrStatus(err error) int {
+	switch {
+	case errors.Is(err, wa.ErrInvalidJID):
+		return http.StatusBadRequest
+	case errors.Is(err, wa.ErrNotGroupAdmin), errors.Is(err, wa.ErrGroupNoAccess):
+		return http.StatusForbidden
+	case errors.Is(err, wa.ErrGroupNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, wa.ErrNotPaired), errors.Is(err, wa.ErrAlreadyPaired),
+		errors.Is(err, wa.ErrObserverAccount), errors.Is(err, wa.ErrJoinApprovalOff):
+		return http.StatusConflict
+	case errors.Is(err, wa.ErrInviteRevoked), errors.Is(err, wa.ErrInviteInvalid):
+		return http.StatusUnprocessableEntity
+	case errors.Is(err, wa.ErrPairingByNumberUnsupported):
+		return http.StatusNotImplemented
+	case errors.Is(err, wa.ErrNotConnected):
+		return http.StatusServiceUnavailable
+	case errors.Is(err, wa.ErrTimeout):
+		return http.StatusGatewayTimeout
+	}
+	return http.StatusBadGateway
+}
+
+// writeWAErr menulis error wa.Manager dengan status yang sesuai dan field "code" (wa.ErrorCode)
+// agar klien bisa bercabang tanpa mencocokkan teks error.
+func writeWAErr(w http.ResponseWriter, err error) {
+	body := map[string]any{"error": err.Error()}
+	if code := wa.ErrorCode(err); code != "" {
+		body["code"] = code
+	}
+	writeJSON(w, waErrStatus(err), body)
+}
@@ -0,0 +1,138 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"promote/internal/model"
+	"promote/internal/storage"
+)
+
+// Profil warmup default {"enabled":false,"steps":[{"day":1,"limit":5},...]}.
+func (a *API) handleGetWarmupDefault(w http.ResponseWriter, r *http.Request) {
+	p, err := a.Store.WarmupDefault()
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, p)
+}
+
+// decodeWarmup membaca dan memvalidasi profil warmup; false jika respons error sudah ditulis.
+func decodeWarmup(w http.ResponseWriter, r *http.Request) (model.WarmupProfile, bool) {
+	var p model.WarmupProfile
+	if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+		writeErr(w, http.StatusBadRequest, "invalid JSON")
+		return p, false
+	}
+	if err := p.Validate(); err != nil {
+		writeErr(w, http.StatusBadRequest, err.Error())
+		return p, false
+	}
+	return p, true
+}
+
+// Ubah profil warmup default yang berlaku untuk akun tanpa profil khusus.
+func (a *API) handleSetWarmupDefault(w http.ResponseWriter, r *http.Request) {
+	p, ok := decodeWarmup(w, r)
+	if !ok {
+		return
+	}
+	if err := a.Store.SetWarmupDefault(p); err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, p)
+}
+
+// Status warmup akun: profil yang berlaku ("custom" jika khusus akun), hari warmup, dan limit
+// harian efektif yang dipakai scheduler.
+func (a *API) handleGetAccountWarmup(w http.ResponseWriter, r *http.Request) {
+	a.writeAccountWarmup(w, chi.URLParam(r, "id"))
+}
+
+// Profil warmup khusus akun (body sama seperti profil default; {"enabled":false} = tanpa warmup).
+func (a *API) handleSetAccountWarmup(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	if !a.accountExists(w, id) {
+		return
+	}
+	p, ok := decodeWarmup(w, r)
+	if !ok {
+		return
+	}
+	if err := a.Store.SetAccountWarmup(id, p); err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	a.writeAccountWarmup(w, id)
+}
+
+// Hapus profil warmup khusus akun; akun kembali memakai profil default.
+func (a *API) handleDeleteAccountWarmup(w http.ResponseWriter, r *http.Request) {
+	ok, err := a.Store.DeleteAccountWarmup(chi.URLParam(r, "id"))
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if !ok {
+		writeErr(w, http.StatusNotFound, "account has no custom warmup profile")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"deleted": true})
+}
+
+// Mulai ulang warmup akun dari hari ini (mis. akun dipasangkan ke nomor baru).
+func (a *API) handleRestartAccountWarmup(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	ok, err := a.Store.RestartAccountWarmup(id)
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if !ok {
+		writeErr(w, http.StatusNotFound, storage.ErrAccountNotFound.Error())
+		return
+	}
+	a.writeAccountWarmup(w, id)
+}
+
+func (a *API) writeAccountWarmup(w http.ResponseWriter, id string) {
+	since, dailyLimit, err := a.Store.AccountPairedSince(id)
+	if errors.Is(err, storage.ErrAccountNotFound) {
+		writeErr(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	p, custom, err := a.Store.AccountWarmup(id)
+	if err == nil && !custom {
+		p, err = a.Store.WarmupDefault()
+	}
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if dailyLimit <= 0 {
+		dailyLimit = 100
+	}
+	now := time.Now()
+	day := model.WarmupDay(since, now)
+	_, warming := p.LimitForDay(day)
+	writeJSON(w, http.StatusOK, map[string]any{
+		"account_id":      id,
+		"custom":          custom,
+		"profile":         p,
+		"paired_since":    since,
+		"day":             day,
+		"warming_up":      warming,
+		"daily_limit":     dailyLimit,
+		"effective_limit": p.Apply(dailyLimit, since, now),
+	})
+}
@@ -0,0 +1,184 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"promote/internal/model"
+	"promote/internal/storage"
+	"promote/internal/webhook"
+)
+
+type upsertWebhookReq struct {
+	Name    string   `json:"name"`
+	URL     string   `json:"url"`
+	Events  []string `json:"events"`
+	Secret  *string  `json:"secret"`
+	Enabled *bool    `json:"enabled"`
+}
+
+// decodeWebhook membaca dan memvalidasi body; false jika respons error sudah ditulis.
+func decodeWebhook(w http.ResponseWriter, r *http.Request) (model.Webhook, *string, bool) {
+	var req upsertWebhookReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErr(w, http.StatusBadRequest, "invalid JSON")
+		return model.Webhook{}, nil, false
+	}
+	hook := model.Webhook{
+		Name:    strings.TrimSpace(req.Name),
+		URL:     strings.TrimSpace(req.URL),
+		Enabled: req.Enabled == nil || *req.Enabled,
+	}
+	if u, err := url.Parse(hook.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		writeErr(w, http.StatusBadRequest, "url must be an http(s) URL")
+		return hook, nil, false
+	}
+	if hook.Name == "" {
+		hook.Name = hook.URL
+	}
+	for _, e := range req.Events {
+		e = strings.TrimSpace(e)
+		if e == "" {
+			continue
+		}
+		if !webhook.ValidEvent(e) {
+			writeErr(w, http.StatusBadRequest, "unknown event: "+e+" (allowed: "+strings.Join(webhook.Events, ", ")+")")
+			return hook, nil, false
+		}
+		hook.Events = append(hook.Events, e)
+	}
+	if req.Secret != nil {
+		hook.Secret = strings.TrimSpace(*req.Secret)
+	}
+	return hook, req.Secret, true
+}
+
+func writeWebhookErr(w http.ResponseWriter, err error) {
+	if errors.Is(err, storage.ErrWebhookNotFound) {
+		writeErr(w, http.StatusNotFound, err.Error())
+		return
+	}
+	writeErr(w, http.StatusInternalServerError, err.Error())
+}
+
+func (a *API) handleListWebhooks(w http.ResponseWriter, r *http.Request) {
+	list, err := a.Store.ListWebhooks()
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if list == nil {
+		list = []model.Webhook{}
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"webhooks": list, "events": webhook.Events})
+}
+
+// Buat webhook: {"name","url","events":["send.failed",...],"secret"}; events kosong = semua.
+func (a *API) handleCreateWebhook(w http.ResponseWriter, r *http.Request) {
+	hook, _, ok := decodeWebhook(w, r)
+	if !ok {
+		return
+	}
+	id, err := a.Store.CreateWebhook(hook)
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	saved, err := a.Store.GetWebhook(id)
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusCreated, saved)
+}
+
+// Update webhook; secret hanya diganti jika field "secret" dikirim ("" = hapus secret).
+func (a *API) handleUpdateWebhook(w http.ResponseWriter, r *http.Request) {
+	hook, secret, ok := decodeWebhook(w, r)
+	if !ok {
+		return
+	}
+	hook.ID = chi.URLParam(r, "id")
+	if err := a.Store.UpdateWebhook(hook, secret != nil); err != nil {
+		writeWebhookErr(w, err)
+		return
+	}
+	saved, err := a.Store.GetWebhook(hook.ID)
+	if err != nil {
+		writeWebhookErr(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, saved)
+}
+
+func (a *API) handleDeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	if err := a.Store.DeleteWebhook(id); err != nil {
+		writeWebhookErr(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"deleted": id})
+}
+
+// Kirim event "test" ke satu webhook (lewat antrean biasa, jadi tercatat di deliveries).
+func (a *API) handleTestWebhook(w http.ResponseWriter, r *http.Request) {
+	hook, err := a.Store.GetWebhook(chi.URLParam(r, "id"))
+	if err != nil {
+		writeWebhookErr(w, err)
+		return
+	}
+	body, _ := json.Marshal(webhook.Envelope{
+		Event: webhook.EventTest,
+		TS:    time.Now().UTC(),
+		Data:  map[string]any{"webhook_id": hook.ID, "message": "test delivery"},
+	})
+	id, err := a.Store.EnqueueWebhookDelivery(hook.ID, webhook.EventTest, body)
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	a.Webhooks.Wake()
+	writeJSON(w, http.StatusAccepted, map[string]any{"delivery_id": id})
+}
+
+// Log pengiriman: ?webhook_id=&status=pending|delivered|failed&limit=
+func (a *API) handleListWebhookDeliveries(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	limit, _ := strconv.Atoi(q.Get("limit"))
+	list, err := a.Store.ListWebhookDeliveries(q.Get("webhook_id"), q.Get("status"), limit)
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if list == nil {
+		list = []model.WebhookDelivery{}
+	}
+	writeJSON(w, http.StatusOK, list)
+}
+
+// Jadwalkan ulang pengiriman yang sudah gagal permanen.
+func (a *API) handleRetryWebhookDelivery(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		writeErr(w, http.StatusBadRequest, "invalid id")
+		return
+	}
+	ok, err := a.Store.RetryWebhookDelivery(id)
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if !ok {
+		writeErr(w, http.StatusConflict, "delivery not found or not failed")
+		return
+	}
+	a.Webhooks.Wake()
+	writeJSON(w, http.StatusOK, map[string]any{"requeued": id})
+}
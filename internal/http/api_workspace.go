@@ -0,0 +1,185 @@
+package httpapi
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"promote/internal/storage"
+)
+
+// maxWorkspaceArchive membatasi ukuran arsip import (termasuk media).
+const maxWorkspaceArchive = 512 << 20
+
+// Export seluruh konfigurasi workspace sebagai arsip zip:
+//
+//	workspace.json   snapshot tabel (tanpa sesi WhatsApp, log, antrean)
+//	uploads/<file>   media lokal yang direferensikan template/campaign
+func (a *API) handleExportWorkspace(w http.ResponseWriter, r *http.Request) {
+	ws, err := a.Store.ExportWorkspace(r.Context())
+	if err != nil {
+		writeErr(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	files := map[string]bool{}
+	rows := append(append([]map[string]any{}, ws.Tables["templates"]...), ws.Tables["campaigns"]...)
+	for _, row := range rows {
+		for _, v := range row {
+			s, ok := v.(string)
+			if !ok {
+				continue
+			}
+			for _, m := range storage.UploadRefRe.FindAllStringSubmatch(s, -1) {
+				files[m[1]] = true
+			}
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="workspace-%s.zip"`, time.Now().Format("20060102-150405")))
+	zw := zip.NewWriter(w)
+	jw, err := zw.Create("workspace.json")
+	if err != nil {
+		log.Printf("[workspace] export: %v", err)
+		return
+	}
+	enc := json.NewEncoder(jw)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(ws); err != nil {
+		log.Printf("[workspace] export: %v", err)
+		return
+	}
+	for name := range files {
+		f, err := os.Open(filepath.Join(a.uploadDir(), name))
+		if err != nil {
+			log.Printf("[workspace] export: skip missing asset %s: %v", name, err)
+			continue
+		}
+		fw, err := zw.Create("uploads/" + name)
+		if err == nil {
+			_, err = io.Copy(fw, f)
+		}
+		f.Close()
+		if err != nil {
+			log.Printf("[workspace] export asset %s: %v", name, err)
+			return
+		}
+	}
+	if err := zw.Close(); err != nil {
+		log.Printf("[workspace] export: %v", err)
+	}
+}
+
+// Import arsip hasil export (body zip mentah atau multipart field "file"). Data di-merge
+// per primary key; media ditulis ke uploads tanpa menimpa file yang sudah ada.
+func (a *API) handleImportWorkspace(w http.ResponseWriter, r *http.Request) {
+	var src io.Reader = r.Body
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/") {
+		file, _, err := r.FormFile("file")
+		if err != nil {
+			writeErr(w, http.StatusBadRequest, "file missing")
+			return
+		}
+		defer file.Close()
+		src = file
+	}
+	data, err := io.ReadAll(io.LimitReader(src, maxWorkspaceArchive+1))
+	if err != nil {
+		writeErr(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if len(data) > maxWorkspaceArchive {
+		writeErr(w, http.StatusRequestEntityTooLarge, "archive too large")
+		return
+	}
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		writeErr(w, http.StatusBadRequest, "invalid zip archive")
+		return
+	}
+
+	var ws *storage.Workspace
+	var assets []*zip.File
+	for _, f := range zr.File {
+		switch {
+		case f.Name == "workspace.json":
+			rc, err := f.Open()
+			if err != nil {
+				writeErr(w, http.StatusBadRequest, err.Error())
+				return
+			}
+			dec := json.NewDecoder(rc)
+			dec.UseNumber()
+			ws = &storage.Workspace{}
+			err = dec.Decode(ws)
+			rc.Close()
+			if err != nil {
+				writeErr(w, http.StatusBadRequest, "invalid workspace.json")
+				return
+			}
+		case strings.HasPrefix(f.Name, "uploads/") && !f.FileInfo().IsDir():
+			assets = append(assets, f)
+		}
+	}
+	if ws == nil {
+		writeErr(w, http.StatusBadRequest, "workspace.json missing")
+		return
+	}
+
+	written := 0
+	if len(assets) > 0 {
+		if err := os.MkdirAll(a.uploadDir(), 0o755); err != nil {
+			writeErr(w, http.StatusInternalServerError, "mkdir uploads failed")
+			return
+		}
+	}
+	for _, f := range assets {
+		name := path.Base(f.Name)
+		if !storage.UploadRefRe.MatchString("/uploads/" + name) {
+			continue
+		}
+		dst := filepath.Join(a.uploadDir(), name)
+		if _, err := os.Stat(dst); err == nil {
+			continue
+		}
+		if err := extractZipFile(f, dst); err != nil {
+			writeErr(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		written++
+	}
+
+	counts, err := a.Store.ImportWorkspace(r.Context(), ws)
+	if err != nil {
+		writeErr(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"imported": counts, "assets": written})
+}
+
+func extractZipFile(f *zip.File, dst string) error {
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, rc); err != nil {
+		out.Close()
+		os.Remove(dst)
+		return err
+	}
+	return out.Close()
+}
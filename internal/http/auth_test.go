@@ -0,0 +1,115 @@
+package httpapi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"promote/internal/model"
+	"promote/internal/storage"
+	"promote/internal/storage/storagetest"
+)
+
+func newTestRouter(t *testing.T) (http.Handler, *storage.Store) {
+	t.Helper()
+	st := storagetest.Open(t)
+	return NewRouter(st, nil, nil, nil, Options{UploadDir: t.TempDir()}), st
+}
+
+func doRequest(h http.Handler, method, path, token string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader("{}"))
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestAuthRequiredOnceConfigured(t *testing.T) {
+	h, st := newTestRouter(t)
+	// Sebelum ada pengguna/key, API terbuka agar admin pertama bisa dibuat.
+	if rec := doRequest(h, http.MethodGet, "/api/accounts", ""); rec.Code != http.StatusOK {
+		t.Fatalf("open API: GET /api/accounts = %d, want 200", rec.Code)
+	}
+	_, key, err := st.CreateAPIKey("ops", model.RoleAdmin)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range []string{"/api/accounts", "/api/templates", "/api/auth/me", "/metrics"} {
+		if rec := doRequest(h, http.MethodGet, path, ""); rec.Code != http.StatusUnauthorized {
+			t.Fatalf("anonymous GET %s = %d, want 401", path, rec.Code)
+		}
+	}
+	if rec := doRequest(h, http.MethodGet, "/api/accounts", key); rec.Code != http.StatusOK {
+		t.Fatalf("GET /api/accounts with key = %d, want 200", rec.Code)
+	}
+	if rec := doRequest(h, http.MethodGet, "/api/health", ""); rec.Code == http.StatusUnauthorized {
+		t.Fatal("health must stay public")
+	}
+	// Login tetap terbuka: kredensial salah dijawab handler login, bukan middleware.
+	if rec := doRequest(h, http.MethodPost, "/api/auth/login", ""); strings.Contains(rec.Body.String(), authRequiredMsg) {
+		t.Fatalf("login must stay public, got %d %s", rec.Code, rec.Body.String())
+	}
+	for _, path := range []string{"/pair/unknown-token", "/r/unknown"} {
+		if rec := doRequest(h, http.MethodGet, path, ""); strings.Contains(rec.Body.String(), authRequiredMsg) {
+			t.Fatalf("anonymous GET %s = %d, want public", path, rec.Code)
+		}
+	}
+}
+
+func TestOperatorCanViewButExportsNeedLogin(t *testing.T) {
+	h, st := newTestRouter(t)
+	if _, err := st.CreateUser("admin", "rahasia-admin", model.RoleAdmin); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := st.CreateUser("ops", "rahasia-ops", model.RoleOperator); err != nil {
+		t.Fatal(err)
+	}
+	_, token, _, err := st.Login("ops", "rahasia-ops", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	exports := []string{
+		"/api/export/workspace",
+		"/api/config/export",
+		"/api/accounts/a/groups/g@g.us/participants.csv",
+		"/api/audiences/x/export.csv",
+	}
+	for _, path := range exports {
+		if rec := doRequest(h, http.MethodGet, path, ""); rec.Code != http.StatusUnauthorized {
+			t.Fatalf("anonymous GET %s = %d, want 401", path, rec.Code)
+		}
+	}
+	// Snapshot workspace dan spec konfigurasi memuat setting sensitif: hanya admin.
+	for _, path := range exports[:2] {
+		if rec := doRequest(h, http.MethodGet, path, token); rec.Code != http.StatusForbidden {
+			t.Fatalf("operator GET %s = %d, want 403", path, rec.Code)
+		}
+	}
+	if rec := doRequest(h, http.MethodPut, "/api/settings/group-caps", token); rec.Code != http.StatusForbidden {
+		t.Fatalf("operator PUT admin route = %d, want 403", rec.Code)
+	}
+}
+
+func TestRawPairQRNeedsLoginButPairingLinkWorks(t *testing.T) {
+	h, st := newTestRouter(t)
+	storagetest.SeedAccount(t, st, storagetest.Account{ID: "a"})
+	if _, _, err := st.CreateAPIKey("ops", model.RoleAdmin); err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range []string{"/api/accounts/a/pair/qr", "/api/accounts/a/pair/qr?format=json"} {
+		if rec := doRequest(h, http.MethodGet, path, ""); rec.Code != http.StatusUnauthorized {
+			t.Fatalf("anonymous GET %s = %d, want 401", path, rec.Code)
+		}
+	}
+	_, token, err := st.CreatePairingLink("a", "admin", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rec := doRequest(h, http.MethodGet, "/pair/"+token, ""); rec.Code != http.StatusOK {
+		t.Fatalf("pairing link page = %d, want 200", rec.Code)
+	}
+}
@@ -0,0 +1,80 @@
+// Package langdetect menebak bahasa nama/deskripsi grup secara ringan dengan mencocokkan kata
+// khas per bahasa (id, ms, jv, su, en). Teks grup pendek dan bercampur, jadi hasilnya hanya
+// dipakai bila satu bahasa jelas unggul; selain itu dikembalikan kosong.
+package langdetect
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Kode bahasa hasil deteksi (sama dengan kode yang dipakai targeting).
+const (
+	Indonesian = "id"
+	Malay      = "ms"
+	Javanese   = "jv"
+	Sundanese  = "su"
+	English    = "en"
+)
+
+// markers kata khas per bahasa. Kata yang umum di beberapa bahasa (mis. "dan", "untuk" di
+// id/ms) sengaja tidak dimasukkan agar tidak mengaburkan skor.
+var markers = map[string][]string{
+	Indonesian: {"tidak", "bisa", "sudah", "saja", "jual", "beli", "murah", "lowongan", "kerja", "anda",
+		"kamu", "gratis", "terbaru", "harga", "toko", "grup", "komunitas", "warga", "bareng", "kalian",
+		"alumni", "arisan", "pengajian", "kajian", "olshop", "reseller", "dropship", "indonesia", "loker",
+		"mantap", "sekitar"},
+	Malay: {"tak", "boleh", "sahaja", "kerana", "awak", "kedai", "percuma", "jualan", "borong", "malaysia",
+		"selangor", "johor", "ringgit", "rm", "macam", "kereta", "pejabat", "niaga", "peniaga",
+		"jom", "kl", "sabah", "sarawak", "kedah", "perak"},
+	Javanese: {"lan", "karo", "ora", "sing", "kulo", "monggo", "dulur", "sedulur", "paguyuban", "wong",
+		"jowo", "jawa", "konco", "nggih", "sampun", "wis", "iki", "kuwi", "iku", "piye", "arek",
+		"seduluran", "dolanan", "guyub", "suroboyo", "ngalam", "mangan", "opo", "kabeh", "wonten"},
+	Sundanese: {"urang", "sunda", "teu", "naon", "kumaha", "mangga", "abdi", "wargi", "baraya", "akang",
+		"teteh", "jeung", "pisan", "sadaya", "sareng", "parahyangan", "pasundan", "sadulur", "nyaah",
+		"sampurasun", "rampes"},
+	English: {"the", "and", "for", "of", "with", "group", "sale", "buy", "sell", "community", "official",
+		"news", "jobs", "free", "our", "club", "friends", "family", "team", "store", "shop", "online",
+		"best", "daily", "deals"},
+}
+
+var index = func() map[string][]string {
+	idx := map[string][]string{}
+	for lang, words := range markers {
+		for _, w := range words {
+			idx[w] = append(idx[w], lang)
+		}
+	}
+	return idx
+}()
+
+// Detect menebak bahasa dari satu atau beberapa teks (nama, deskripsi). Mengembalikan kode
+// bahasa jika ada bahasa dengan skor tertinggi yang unik, atau "" jika tidak yakin.
+func Detect(texts ...string) string {
+	scores := map[string]int{}
+	for _, text := range texts {
+		for _, tok := range tokens(text) {
+			for _, lang := range index[tok] {
+				scores[lang]++
+			}
+		}
+	}
+	best, bestScore, tie := "", 0, false
+	for lang, n := range scores {
+		switch {
+		case n > bestScore:
+			best, bestScore, tie = lang, n, false
+		case n == bestScore:
+			tie = true
+		}
+	}
+	if bestScore == 0 || tie {
+		return ""
+	}
+	return best
+}
+
+// tokens memecah teks menjadi kata huruf kecil (angka, emoji dan tanda baca menjadi pemisah).
+func tokens(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool { return !unicode.IsLetter(r) })
+}
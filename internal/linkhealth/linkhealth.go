@@ -0,0 +1,203 @@
+// Package linkhealth memeriksa berkala link undangan grup milik sendiri (akun admin): link masih
+// berlaku (belum dicabut) dan grup belum mencapai batas anggota, sehingga link rusak ketahuan
+// sebelum dipakai di template cross-promo.
+package linkhealth
+
+import (
+	"context"
+	"errors"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"promote/internal/model"
+	"promote/internal/storage"
+	"promote/internal/wa"
+)
+
+// Monitor menjalankan cek link undangan secara berkala.
+type Monitor struct {
+	Store   *storage.Store
+	Manager *wa.Manager
+	// Interval antar cek penuh (LINK_HEALTH_HOURS, default 12 jam).
+	Interval time.Duration
+	// MemberCap batas anggota grup WhatsApp (LINK_HEALTH_MEMBER_CAP, default 1024); grup
+	// dengan anggota >= MemberCap ditandai penuh.
+	MemberCap int
+	// Delay jeda antar grup agar cek tidak membanjiri server.
+	Delay time.Duration
+}
+
+// Result ringkasan satu kali cek.
+type Result struct {
+	Accounts int `json:"accounts"`
+	Checked  int `json:"checked"`
+	OK       int `json:"ok"`
+	Revoked  int `json:"revoked"`
+	Full     int `json:"full"`
+	Errors   int `json:"errors"`
+}
+
+func (r *Result) add(o Result) {
+	r.Accounts += o.Accounts
+	r.Checked += o.Checked
+	r.OK += o.OK
+	r.Revoked += o.Revoked
+	r.Full += o.Full
+	r.Errors += o.Errors
+}
+
+// New membuat Monitor; interval dan batas anggota dibaca dari env.
+func New(store *storage.Store, manager *wa.Manager) *Monitor {
+	m := &Monitor{Store: store, Manager: manager, Interval: 12 * time.Hour, MemberCap: 1024, Delay: 2 * time.Second}
+	if n := envInt("LINK_HEALTH_HOURS"); n > 0 {
+		m.Interval = time.Duration(n) * time.Hour
+	}
+	if n := envInt("LINK_HEALTH_MEMBER_CAP"); n > 0 {
+		m.MemberCap = n
+	}
+	return m
+}
+
+func envInt(key string) int {
+	n, _ := strconv.Atoi(strings.TrimSpace(os.Getenv(key)))
+	return n
+}
+
+// Start menjalankan cek pertama setelah jeda singkat (memberi waktu akun terhubung), lalu berkala.
+func (m *Monitor) Start(ctx context.Context) {
+	go func() {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(10 * time.Minute):
+		}
+		tick := time.NewTicker(m.Interval)
+		defer tick.Stop()
+		for {
+			res, err := m.CheckAll(ctx)
+			if err != nil {
+				log.Printf("[linkhealth] check failed: %v", err)
+			} else {
+				log.Printf("[linkhealth] check done accounts=%d checked=%d ok=%d revoked=%d full=%d errors=%d",
+					res.Accounts, res.Checked, res.OK, res.Revoked, res.Full, res.Errors)
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-tick.C:
+			}
+		}
+	}()
+}
+
+// CheckAll memeriksa grup admin semua akun aktif yang sedang terhubung.
+func (m *Monitor) CheckAll(ctx context.Context) (Result, error) {
+	var total Result
+	accs, err := m.Store.ListAccounts()
+	if err != nil {
+		return total, err
+	}
+	for _, a := range accs {
+		if !a.Enabled {
+			continue
+		}
+		if _, connected := m.Manager.ConnectionState(a.ID); !connected {
+			continue
+		}
+		res, err := m.CheckAccount(ctx, a.ID)
+		if err != nil {
+			log.Printf("[linkhealth] account=%s check failed: %v", a.ID, err)
+			continue
+		}
+		total.add(res)
+	}
+	return total, nil
+}
+
+// CheckAccount memeriksa link undangan setiap grup tempat akun menjadi admin. Grup yang belum
+// punya link tersimpan diambilkan link-nya saat ini lebih dulu.
+func (m *Monitor) CheckAccount(ctx context.Context, accountID string) (Result, error) {
+	res := Result{Accounts: 1}
+	admin, err := m.Manager.AdminGroups(ctx, accountID)
+	if err != nil {
+		return res, err
+	}
+	groups, err := m.Store.ListGroups(accountID)
+	if err != nil {
+		return res, err
+	}
+	known := make(map[string]bool, len(groups))
+	for _, g := range groups {
+		known[g.ID] = true
+	}
+	for i, g := range admin {
+		// group_links merujuk groups: grup yang belum disinkron dilewati sampai refresh berikutnya.
+		if !known[g.JID] {
+			continue
+		}
+		if i > 0 && m.Delay > 0 {
+			select {
+			case <-ctx.Done():
+				return res, ctx.Err()
+			case <-time.After(m.Delay):
+			}
+		}
+		status, err := m.checkGroup(ctx, accountID, g)
+		if err != nil {
+			log.Printf("[linkhealth] group=%s: %v", g.JID, err)
+			res.Errors++
+			continue
+		}
+		res.Checked++
+		switch status {
+		case model.LinkOK:
+			res.OK++
+		case model.LinkRevoked:
+			res.Revoked++
+			log.Printf("[linkhealth] group=%s invite link no longer works", g.JID)
+		case model.LinkFull:
+			res.Full++
+		case model.LinkError:
+			res.Errors++
+		}
+	}
+	return res, nil
+}
+
+// checkGroup memverifikasi link tersimpan satu grup dan mencatat hasilnya. Error dikembalikan
+// hanya jika hasil tidak bisa dicatat (mis. link belum ada dan gagal diambil).
+func (m *Monitor) checkGroup(ctx context.Context, accountID string, g wa.AdminGroup) (string, error) {
+	stored, err := m.Store.GetGroupLink(g.JID)
+	if errors.Is(err, storage.ErrGroupLinkNotFound) {
+		link, lerr := m.Manager.GroupInviteLink(ctx, accountID, g.JID, false)
+		if lerr != nil {
+			return "", lerr
+		}
+		if err := m.Store.SaveGroupLink(g.JID, accountID, link); err != nil {
+			return "", err
+		}
+		stored.InviteLink = link
+	} else if err != nil {
+		return "", err
+	}
+
+	status, members, msg := model.LinkOK, g.Participants, ""
+	_, n, err := m.Manager.CheckInviteLink(ctx, accountID, stored.InviteLink)
+	switch {
+	case errors.Is(err, wa.ErrInviteRevoked), errors.Is(err, wa.ErrInviteInvalid):
+		status, msg = model.LinkRevoked, err.Error()
+	case err != nil:
+		status, msg = model.LinkError, err.Error()
+	default:
+		if n > members {
+			members = n
+		}
+		if m.MemberCap > 0 && members >= m.MemberCap {
+			status = model.LinkFull
+		}
+	}
+	return status, m.Store.RecordGroupLinkCheck(g.JID, status, members, msg, time.Now())
+}
@@ -0,0 +1,141 @@
+// Package lint memeriksa teks template untuk pola konten yang sering memicu laporan spam
+// (terlalu banyak link, HURUF BESAR, kata pemicu spam, emoji berlebihan, tanpa personalisasi)
+// dan menghasilkan skor risiko sebagai petunjuk bagi operator.
+package lint
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+
+	"promote/internal/model"
+)
+
+// Batas pemeriksaan; di atas batas ini issue dilaporkan.
+const (
+	maxLinks      = 2
+	maxEmoji      = 8
+	minCapsLetter = 20
+	maxCapsRatio  = 0.6
+)
+
+// spamWords frasa pemicu spam bawaan (huruf kecil); tambah lewat LINT_SPAM_WORDS (dipisah koma).
+var spamWords = []string{
+	"gratis", "100%", "dijamin", "klik di sini", "klik link", "buruan", "terbatas", "cuan",
+	"modal kecil", "untung besar", "penghasilan tambahan", "tanpa modal", "menang", "hadiah",
+	"slot", "gacor", "judi", "pinjol", "free", "click here", "limited offer", "guaranteed",
+	"winner", "act now", "make money",
+}
+
+var (
+	linkRe = regexp.MustCompile(`(?i)\b(?:https?://|www\.)\S+`)
+	// varRe placeholder personalisasi: {group_name}, {time_now}, {field:key}, {stock:sku}, dll.
+	varRe = regexp.MustCompile(`\{[a-z][a-z0-9_]*(?::[^{}\s]+)?\}`)
+)
+
+// Template memeriksa semua teks template (teks, caption, produk).
+func Template(t model.Template) model.LintReport {
+	parts := []string{t.TextOnly, t.ImageCaption, t.VideoCaption, t.DocCaption}
+	for _, p := range t.Products {
+		parts = append(parts, p.Title, p.Description)
+	}
+	return Text(strings.Join(parts, "\n"))
+}
+
+// Text memeriksa satu teks dan mengembalikan laporan risikonya.
+func Text(text string) model.LintReport {
+	rep := model.LintReport{Issues: []model.LintIssue{}, CheckedAt: time.Now().UTC()}
+	add := func(code string, points int, msg string) {
+		rep.Issues = append(rep.Issues, model.LintIssue{Code: code, Message: msg, Points: points})
+		rep.Score += points
+	}
+	if strings.TrimSpace(text) == "" {
+		rep.Risk = model.LintRiskLow
+		return rep
+	}
+
+	if n := len(linkRe.FindAllString(text, -1)); n > maxLinks {
+		add("too_many_links", 25, fmt.Sprintf("%d links; keep it to %d or fewer", n, maxLinks))
+	}
+
+	// Link dibuang dulu agar path/slug tidak ikut dihitung sebagai huruf.
+	plain := linkRe.ReplaceAllString(text, " ")
+	letters, upper, emoji := 0, 0, 0
+	for _, r := range plain {
+		switch {
+		case unicode.IsLetter(r):
+			letters++
+			if unicode.IsUpper(r) {
+				upper++
+			}
+		case isEmoji(r):
+			emoji++
+		}
+	}
+	if letters >= minCapsLetter && float64(upper)/float64(letters) > maxCapsRatio {
+		add("all_caps", 20, fmt.Sprintf("%d%% of letters are uppercase", upper*100/letters))
+	}
+	if emoji > maxEmoji {
+		add("excessive_emoji", 15, fmt.Sprintf("%d emoji; keep it to %d or fewer", emoji, maxEmoji))
+	}
+
+	if hits := spamHits(plain); len(hits) > 0 {
+		points := 10 * len(hits)
+		if points > 30 {
+			points = 30
+		}
+		add("spam_words", points, "spam trigger words: "+strings.Join(hits, ", "))
+	}
+
+	if !varRe.MatchString(text) {
+		add("no_personalization", 10, "no placeholder such as {group_name}; identical messages look like spam")
+	}
+
+	if rep.Score > 100 {
+		rep.Score = 100
+	}
+	switch {
+	case rep.Score >= 50:
+		rep.Risk = model.LintRiskHigh
+	case rep.Score >= 25:
+		rep.Risk = model.LintRiskMedium
+	default:
+		rep.Risk = model.LintRiskLow
+	}
+	return rep
+}
+
+// spamHits mengembalikan frasa pemicu spam yang muncul sebagai kata utuh.
+func spamHits(text string) []string {
+	words := append([]string{}, spamWords...)
+	for _, w := range strings.Split(os.Getenv("LINT_SPAM_WORDS"), ",") {
+		if w = strings.ToLower(strings.TrimSpace(w)); w != "" {
+			words = append(words, w)
+		}
+	}
+	lower := " " + normalize(text) + " "
+	var hits []string
+	seen := map[string]bool{}
+	for _, w := range words {
+		if !seen[w] && strings.Contains(lower, " "+normalize(w)+" ") {
+			hits = append(hits, w)
+		}
+		seen[w] = true
+	}
+	return hits
+}
+
+// normalize menurunkan huruf dan mengganti tanda baca (kecuali %) dengan spasi tunggal.
+func normalize(s string) string {
+	return strings.Join(strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '%'
+	}), " ")
+}
+
+// isEmoji perkiraan kasar rentang emoji Unicode (piktograf, simbol, bendera).
+func isEmoji(r rune) bool {
+	return (r >= 0x1F300 && r <= 0x1FAFF) || (r >= 0x2600 && r <= 0x27BF) || (r >= 0x1F1E6 && r <= 0x1F1FF)
+}
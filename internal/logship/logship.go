@@ -0,0 +1,242 @@
+package logship
+
+import (
+	"context"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Event kinds yang dikirim ke sink eksternal.
+const (
+	KindSend    = "send"
+	KindAccount = "account"
+)
+
+// Event adalah satu baris log terstruktur yang akan dikirim ke sink eksternal.
+type Event struct {
+	TS        time.Time         `json:"ts"`
+	Kind      string            `json:"kind"`
+	AccountID string            `json:"account_id,omitempty"`
+	GroupID   string            `json:"group_id,omitempty"`
+	Status    string            `json:"status,omitempty"`
+	Error     string            `json:"error,omitempty"`
+	Message   string            `json:"message,omitempty"`
+	Fields    map[string]string `json:"fields,omitempty"`
+}
+
+// Sink menerima batch event. Implementasi harus aman dipanggil berulang (retry).
+type Sink interface {
+	Name() string
+	Write(ctx context.Context, batch []Event) error
+}
+
+// SinkConfig mengatur batching & retry per sink.
+type SinkConfig struct {
+	BatchSize  int
+	FlushEvery time.Duration
+	MaxRetries int
+	BufferSize int
+}
+
+func defaultSinkConfig() SinkConfig {
+	return SinkConfig{
+		BatchSize:  100,
+		FlushEvery: 5 * time.Second,
+		MaxRetries: 3,
+		BufferSize: 5000,
+	}
+}
+
+type worker struct {
+	sink Sink
+	cfg  SinkConfig
+	ch   chan Event
+}
+
+// Shipper mendistribusikan event ke semua sink terkonfigurasi secara asinkron.
+// Nilai nil aman dipakai (Emit menjadi no-op), sehingga log shipping tetap opsional.
+type Shipper struct {
+	workers []*worker
+	wg      sync.WaitGroup
+	cancel  context.CancelFunc
+}
+
+// New membuat Shipper dan menjalankan satu goroutine batching per sink.
+func New(ctx context.Context, sinks map[Sink]SinkConfig) *Shipper {
+	if len(sinks) == 0 {
+		return nil
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	sh := &Shipper{cancel: cancel}
+	for sink, cfg := range sinks {
+		d := defaultSinkConfig()
+		if cfg.BatchSize <= 0 {
+			cfg.BatchSize = d.BatchSize
+		}
+		if cfg.FlushEvery <= 0 {
+			cfg.FlushEvery = d.FlushEvery
+		}
+		if cfg.MaxRetries < 0 {
+			cfg.MaxRetries = d.MaxRetries
+		}
+		if cfg.BufferSize <= 0 {
+			cfg.BufferSize = d.BufferSize
+		}
+		w := &worker{sink: sink, cfg: cfg, ch: make(chan Event, cfg.BufferSize)}
+		sh.workers = append(sh.workers, w)
+		sh.wg.Add(1)
+		go sh.run(ctx, w)
+		log.Printf("[logship] sink=%s batch=%d flush=%s retries=%d", sink.Name(), cfg.BatchSize, cfg.FlushEvery, cfg.MaxRetries)
+	}
+	return sh
+}
+
+// Emit mengantrikan event ke semua sink tanpa blocking. Jika buffer penuh, event di-drop.
+func (sh *Shipper) Emit(ev Event) {
+	if sh == nil {
+		return
+	}
+	if ev.TS.IsZero() {
+		ev.TS = time.Now()
+	}
+	for _, w := range sh.workers {
+		select {
+		case w.ch <- ev:
+		default:
+			log.Printf("[logship] sink=%s buffer full, dropping event kind=%s", w.sink.Name(), ev.Kind)
+		}
+	}
+}
+
+// Close menghentikan worker setelah mencoba flush sisa buffer.
+func (sh *Shipper) Close() {
+	if sh == nil {
+		return
+	}
+	sh.cancel()
+	sh.wg.Wait()
+}
+
+func (sh *Shipper) run(ctx context.Context, w *worker) {
+	defer sh.wg.Done()
+	tick := time.NewTicker(w.cfg.FlushEvery)
+	defer tick.Stop()
+	batch := make([]Event, 0, w.cfg.BatchSize)
+	flush := func(fctx context.Context) {
+		if len(batch) == 0 {
+			return
+		}
+		sh.deliver(fctx, w, batch)
+		batch = make([]Event, 0, w.cfg.BatchSize)
+	}
+	for {
+		select {
+		case ev := <-w.ch:
+			batch = append(batch, ev)
+			if len(batch) >= w.cfg.BatchSize {
+				flush(ctx)
+			}
+		case <-tick.C:
+			flush(ctx)
+		case <-ctx.Done():
+			// drain & flush terakhir dengan timeout pendek
+		drain:
+			for {
+				select {
+				case ev := <-w.ch:
+					batch = append(batch, ev)
+				default:
+					break drain
+				}
+			}
+			fctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			flush(fctx)
+			cancel()
+			return
+		}
+	}
+}
+
+func (sh *Shipper) deliver(ctx context.Context, w *worker, batch []Event) {
+	backoff := time.Second
+	for attempt := 0; ; attempt++ {
+		wctx, cancel := context.WithTimeout(ctx, 15*time.Second)
+		err := w.sink.Write(wctx, batch)
+		cancel()
+		if err == nil {
+			return
+		}
+		if attempt >= w.cfg.MaxRetries {
+			log.Printf("[logship] sink=%s dropping batch of %d after %d attempts: %v", w.sink.Name(), len(batch), attempt+1, err)
+			return
+		}
+		log.Printf("[logship] sink=%s write failed (attempt %d): %v", w.sink.Name(), attempt+1, err)
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return
+		}
+		backoff *= 2
+		if backoff > 30*time.Second {
+			backoff = 30 * time.Second
+		}
+	}
+}
+
+// FromEnv membangun Shipper dari environment. Tanpa konfigurasi apa pun, mengembalikan nil.
+//   - LOGSHIP_SYSLOG_ADDR=udp://host:514 | tcp://host:601
+//   - LOGSHIP_LOKI_URL=http://loki:3100
+//   - LOGSHIP_ES_URL=http://es:9200 (LOGSHIP_ES_INDEX, default "promotenews")
+//   - LOGSHIP_<SYSLOG|LOKI|ES>_BATCH, _FLUSH_SEC, _RETRIES untuk batching & retry per sink
+func FromEnv(ctx context.Context) *Shipper {
+	sinks := map[Sink]SinkConfig{}
+	if v := strings.TrimSpace(os.Getenv("LOGSHIP_SYSLOG_ADDR")); v != "" {
+		s, err := NewSyslogSink(v, "promotenews")
+		if err != nil {
+			log.Printf("[logship] syslog disabled: %v", err)
+		} else {
+			sinks[s] = sinkConfigFromEnv("SYSLOG")
+		}
+	}
+	if v := strings.TrimSpace(os.Getenv("LOGSHIP_LOKI_URL")); v != "" {
+		sinks[NewLokiSink(v, map[string]string{"job": "promotenews"})] = sinkConfigFromEnv("LOKI")
+	}
+	if v := strings.TrimSpace(os.Getenv("LOGSHIP_ES_URL")); v != "" {
+		index := strings.TrimSpace(os.Getenv("LOGSHIP_ES_INDEX"))
+		if index == "" {
+			index = "promotenews"
+		}
+		sinks[NewElasticSink(v, index)] = sinkConfigFromEnv("ES")
+	}
+	return New(ctx, sinks)
+}
+
+func sinkConfigFromEnv(prefix string) SinkConfig {
+	cfg := defaultSinkConfig()
+	if n, ok := envInt("LOGSHIP_" + prefix + "_BATCH"); ok && n > 0 {
+		cfg.BatchSize = n
+	}
+	if n, ok := envInt("LOGSHIP_" + prefix + "_FLUSH_SEC"); ok && n > 0 {
+		cfg.FlushEvery = time.Duration(n) * time.Second
+	}
+	if n, ok := envInt("LOGSHIP_" + prefix + "_RETRIES"); ok && n >= 0 {
+		cfg.MaxRetries = n
+	}
+	return cfg
+}
+
+func envInt(key string) (int, bool) {
+	v := strings.TrimSpace(os.Getenv(key))
+	if v == "" {
+		return 0, false
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return 0, false
+	}
+	return n, true
+}
@@ -0,0 +1,190 @@
+package logship
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SyslogSink mengirim event sebagai pesan RFC 5424 via UDP atau TCP.
+type SyslogSink struct {
+	network string
+	addr    string
+	app     string
+	host    string
+
+	mu   sync.Mutex
+	conn net.Conn
+}
+
+// NewSyslogSink menerima alamat seperti "udp://host:514" atau "tcp://host:601".
+func NewSyslogSink(addr, app string) (*SyslogSink, error) {
+	u, err := url.Parse(addr)
+	if err != nil || u.Host == "" {
+		return nil, fmt.Errorf("invalid syslog addr %q", addr)
+	}
+	if u.Scheme != "udp" && u.Scheme != "tcp" {
+		return nil, fmt.Errorf("unsupported syslog scheme %q", u.Scheme)
+	}
+	host, _ := os.Hostname()
+	if host == "" {
+		host = "-"
+	}
+	return &SyslogSink{network: u.Scheme, addr: u.Host, app: app, host: host}, nil
+}
+
+func (s *SyslogSink) Name() string { return "syslog" }
+
+func (s *SyslogSink) Write(ctx context.Context, batch []Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.conn == nil {
+		var d net.Dialer
+		c, err := d.DialContext(ctx, s.network, s.addr)
+		if err != nil {
+			return err
+		}
+		s.conn = c
+	}
+	if dl, ok := ctx.Deadline(); ok {
+		_ = s.conn.SetWriteDeadline(dl)
+	}
+	for _, ev := range batch {
+		if _, err := s.conn.Write(s.format(ev)); err != nil {
+			// reconnect pada percobaan berikutnya
+			s.conn.Close()
+			s.conn = nil
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *SyslogSink) format(ev Event) []byte {
+	// facility local0 (16), severity info(6) / warning(4)
+	sev := 6
+	if ev.Status == "failed" || ev.Error != "" {
+		sev = 4
+	}
+	pri := 16*8 + sev
+	body, _ := json.Marshal(ev)
+	msg := fmt.Sprintf("<%d>1 %s %s %s - %s - %s", pri, ev.TS.UTC().Format(time.RFC3339Nano), s.host, s.app, ev.Kind, body)
+	if s.network == "tcp" {
+		// octet counting framing (RFC 6587)
+		return []byte(strconv.Itoa(len(msg)) + " " + msg)
+	}
+	return []byte(msg)
+}
+
+// LokiSink mendorong event ke Loki push API (/loki/api/v1/push).
+type LokiSink struct {
+	url    string
+	labels map[string]string
+	client *http.Client
+}
+
+func NewLokiSink(baseURL string, labels map[string]string) *LokiSink {
+	u := strings.TrimRight(baseURL, "/")
+	if !strings.HasSuffix(u, "/loki/api/v1/push") {
+		u += "/loki/api/v1/push"
+	}
+	return &LokiSink{url: u, labels: labels, client: &http.Client{Timeout: 15 * time.Second}}
+}
+
+func (s *LokiSink) Name() string { return "loki" }
+
+func (s *LokiSink) Write(ctx context.Context, batch []Event) error {
+	type stream struct {
+		Stream map[string]string `json:"stream"`
+		Values [][2]string       `json:"values"`
+	}
+	// satu stream per kind agar label tetap berkardinalitas rendah
+	byKind := map[string]*stream{}
+	var order []string
+	for _, ev := range batch {
+		st := byKind[ev.Kind]
+		if st == nil {
+			lbl := map[string]string{"kind": ev.Kind}
+			for k, v := range s.labels {
+				lbl[k] = v
+			}
+			st = &stream{Stream: lbl}
+			byKind[ev.Kind] = st
+			order = append(order, ev.Kind)
+		}
+		line, _ := json.Marshal(ev)
+		st.Values = append(st.Values, [2]string{strconv.FormatInt(ev.TS.UnixNano(), 10), string(line)})
+	}
+	payload := struct {
+		Streams []*stream `json:"streams"`
+	}{}
+	for _, k := range order {
+		payload.Streams = append(payload.Streams, byKind[k])
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	return postJSON(ctx, s.client, s.url, "application/json", body)
+}
+
+// ElasticSink menulis event via Elasticsearch bulk API.
+type ElasticSink struct {
+	url    string
+	index  string
+	client *http.Client
+}
+
+func NewElasticSink(baseURL, index string) *ElasticSink {
+	return &ElasticSink{
+		url:    strings.TrimRight(baseURL, "/") + "/_bulk",
+		index:  index,
+		client: &http.Client{Timeout: 15 * time.Second},
+	}
+}
+
+func (s *ElasticSink) Name() string { return "elasticsearch" }
+
+func (s *ElasticSink) Write(ctx context.Context, batch []Event) error {
+	var buf bytes.Buffer
+	action, _ := json.Marshal(map[string]any{"index": map[string]string{"_index": s.index}})
+	for _, ev := range batch {
+		doc, err := json.Marshal(ev)
+		if err != nil {
+			continue
+		}
+		buf.Write(action)
+		buf.WriteByte('\n')
+		buf.Write(doc)
+		buf.WriteByte('\n')
+	}
+	return postJSON(ctx, s.client, s.url, "application/x-ndjson", buf.Bytes())
+}
+
+func postJSON(ctx context.Context, c *http.Client, u, contentType string, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", contentType)
+	res, err := c.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	msg, _ := io.ReadAll(io.LimitReader(res.Body, 512))
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return fmt.Errorf("POST %s: status %d: %s", u, res.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
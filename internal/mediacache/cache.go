@@ -0,0 +1,178 @@
+// Package mediacache menyimpan salinan lokal media remote template/campaign dan menyiapkannya
+// (prefetch + validasi) di luar jendela kirim, sehingga jendela malam yang pendek tidak habis
+// untuk mengunduh video besar.
+package mediacache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Cache menyimpan body media per URL di Dir (file <sha256>.bin + metadata <sha256>.json).
+type Cache struct {
+	Dir string
+	// TTL umur entri sebelum diunduh ulang (MEDIA_CACHE_TTL_HOURS, default 24).
+	TTL time.Duration
+	// MaxBytes batas total ukuran cache; entri tertua dibuang saat Prune (MEDIA_CACHE_MAX_MB, default 2048).
+	MaxBytes int64
+
+	mu sync.Mutex
+}
+
+type meta struct {
+	URL         string    `json:"url"`
+	ContentType string    `json:"content_type"`
+	Size        int64     `json:"size"`
+	FetchedAt   time.Time `json:"fetched_at"`
+}
+
+// FromEnv membuat cache di dataDir/media-cache dengan TTL dan batas ukuran dari env.
+func FromEnv(dataDir string) *Cache {
+	c := &Cache{
+		Dir:      filepath.Join(dataDir, "media-cache"),
+		TTL:      24 * time.Hour,
+		MaxBytes: 2048 << 20,
+	}
+	if n, err := strconv.Atoi(strings.TrimSpace(os.Getenv("MEDIA_CACHE_TTL_HOURS"))); err == nil && n > 0 {
+		c.TTL = time.Duration(n) * time.Hour
+	}
+	if n, err := strconv.Atoi(strings.TrimSpace(os.Getenv("MEDIA_CACHE_MAX_MB"))); err == nil && n > 0 {
+		c.MaxBytes = int64(n) << 20
+	}
+	return c
+}
+
+func (c *Cache) paths(url string) (bin, js string) {
+	sum := sha256.Sum256([]byte(url))
+	name := hex.EncodeToString(sum[:])
+	return filepath.Join(c.Dir, name+".bin"), filepath.Join(c.Dir, name+".json")
+}
+
+func (c *Cache) readMeta(path string) (meta, bool) {
+	var m meta
+	b, err := os.ReadFile(path)
+	if err != nil || json.Unmarshal(b, &m) != nil {
+		return m, false
+	}
+	return m, true
+}
+
+// Fresh true jika URL ada di cache dan belum melewati TTL.
+func (c *Cache) Fresh(url string) bool {
+	if c == nil {
+		return false
+	}
+	_, js := c.paths(url)
+	m, ok := c.readMeta(js)
+	return ok && time.Since(m.FetchedAt) < c.TTL
+}
+
+// Get mengembalikan body dan content-type dari cache jika masih segar.
+func (c *Cache) Get(url string) ([]byte, string, bool) {
+	if c == nil {
+		return nil, "", false
+	}
+	bin, js := c.paths(url)
+	m, ok := c.readMeta(js)
+	if !ok || time.Since(m.FetchedAt) >= c.TTL {
+		return nil, "", false
+	}
+	data, err := os.ReadFile(bin)
+	if err != nil || int64(len(data)) != m.Size {
+		return nil, "", false
+	}
+	return data, m.ContentType, true
+}
+
+// Put menyimpan body media; ditulis ke file sementara lalu di-rename agar pembaca tidak
+// pernah melihat file setengah jadi.
+func (c *Cache) Put(url string, data []byte, contentType string) error {
+	if c == nil {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := os.MkdirAll(c.Dir, 0o700); err != nil {
+		return err
+	}
+	bin, js := c.paths(url)
+	if err := writeAtomic(bin, data); err != nil {
+		return err
+	}
+	b, _ := json.Marshal(meta{URL: url, ContentType: contentType, Size: int64(len(data)), FetchedAt: time.Now().UTC()})
+	return writeAtomic(js, b)
+}
+
+func writeAtomic(path string, data []byte) error {
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// Stats jumlah entri dan total byte di cache.
+func (c *Cache) Stats() (entries int, bytes int64) {
+	if c == nil {
+		return 0, 0
+	}
+	for _, e := range c.entries() {
+		entries++
+		bytes += e.Size
+	}
+	return entries, bytes
+}
+
+type cacheEntry struct {
+	meta
+	base string
+}
+
+func (c *Cache) entries() []cacheEntry {
+	list, err := filepath.Glob(filepath.Join(c.Dir, "*.json"))
+	if err != nil {
+		return nil
+	}
+	var out []cacheEntry
+	for _, js := range list {
+		if m, ok := c.readMeta(js); ok {
+			out = append(out, cacheEntry{meta: m, base: strings.TrimSuffix(js, ".json")})
+		}
+	}
+	return out
+}
+
+// Prune menghapus entri kedaluwarsa, lalu entri tertua sampai total ukuran <= MaxBytes.
+func (c *Cache) Prune() (removed int, freed int64) {
+	if c == nil {
+		return 0, 0
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	list := c.entries()
+	sort.Slice(list, func(i, j int) bool { return list[i].FetchedAt.Before(list[j].FetchedAt) })
+	var total int64
+	for _, e := range list {
+		total += e.Size
+	}
+	for _, e := range list {
+		if time.Since(e.FetchedAt) < c.TTL && total <= c.MaxBytes {
+			continue
+		}
+		_ = os.Remove(e.base + ".bin")
+		if os.Remove(e.base+".json") == nil {
+			removed++
+			freed += e.Size
+			total -= e.Size
+		}
+	}
+	return removed, freed
+}
@@ -0,0 +1,218 @@
+package mediacache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"promote/internal/storage"
+)
+
+var ErrRunning = errors.New("media prefetch already running")
+
+// maxBytes batas ukuran per jenis media yang masih aman dikirim lewat WhatsApp.
+var maxBytes = map[string]int64{
+	"image":      16 << 20,
+	"video":      64 << 20,
+	"gif":        64 << 20,
+	"audio":      16 << 20,
+	"voice_note": 16 << 20,
+	"sticker":    1 << 20,
+	"document":   100 << 20,
+}
+
+// Problem adalah media yang gagal diunduh atau tidak lolos validasi.
+type Problem struct {
+	URL   string `json:"url"`
+	Kind  string `json:"kind"`
+	Error string `json:"error"`
+}
+
+// Report hasil prefetch terakhir.
+type Report struct {
+	Running    bool       `json:"running"`
+	Total      int        `json:"total"`
+	Cached     int        `json:"cached"`
+	Downloaded int        `json:"downloaded"`
+	Bytes      int64      `json:"bytes"`
+	Problems   []Problem  `json:"problems"`
+	Interrupt  string     `json:"interrupted,omitempty"`
+	StartedAt  *time.Time `json:"started_at,omitempty"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+}
+
+// Prefetcher mengunduh dan memvalidasi media template/campaign aktif ke Cache.
+type Prefetcher struct {
+	Store *storage.Store
+	Cache *Cache
+	// Fetch memuat media (lewat Cache bila segar, jika tidak unduh lalu simpan ke Cache).
+	Fetch func(ctx context.Context, url string) ([]byte, string, error)
+	// Delay jeda setelah setiap unduhan agar bandwidth/server asal tidak dibanjiri
+	// (MEDIA_PREFETCH_DELAY_SEC, default 3).
+	Delay time.Duration
+	// Every interval minimal antar prefetch otomatis di luar jendela kirim
+	// (MEDIA_PREFETCH_EVERY_MIN, default 30; 0 = hanya manual).
+	Every time.Duration
+
+	mu     sync.Mutex
+	report Report
+	last   time.Time
+}
+
+// NewPrefetcher membuat Prefetcher dengan konfigurasi dari env.
+func NewPrefetcher(store *storage.Store, cache *Cache, fetch func(ctx context.Context, url string) ([]byte, string, error)) *Prefetcher {
+	p := &Prefetcher{Store: store, Cache: cache, Fetch: fetch, Delay: 3 * time.Second, Every: 30 * time.Minute}
+	if n, err := strconv.Atoi(strings.TrimSpace(os.Getenv("MEDIA_PREFETCH_DELAY_SEC"))); err == nil && n >= 0 {
+		p.Delay = time.Duration(n) * time.Second
+	}
+	if n, err := strconv.Atoi(strings.TrimSpace(os.Getenv("MEDIA_PREFETCH_EVERY_MIN"))); err == nil && n >= 0 {
+		p.Every = time.Duration(n) * time.Minute
+	}
+	return p
+}
+
+// Due true jika prefetch otomatis boleh dijalankan lagi.
+func (p *Prefetcher) Due(now time.Time) bool {
+	if p == nil || p.Every <= 0 {
+		return false
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return !p.report.Running && now.Sub(p.last) >= p.Every
+}
+
+// Status mengembalikan salinan laporan terakhir.
+func (p *Prefetcher) Status() Report {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	r := p.report
+	r.Problems = append([]Problem{}, r.Problems...)
+	return r
+}
+
+// Run menjalankan prefetch secara sinkron. idle (opsional) dicek sebelum setiap unduhan;
+// begitu false (jendela kirim dimulai) prefetch berhenti agar tidak berebut bandwidth.
+func (p *Prefetcher) Run(ctx context.Context, idle func() bool) (Report, error) {
+	p.mu.Lock()
+	if p.report.Running {
+		p.mu.Unlock()
+		return Report{}, ErrRunning
+	}
+	now := time.Now()
+	p.last = now
+	p.report = Report{Running: true, StartedAt: &now}
+	p.mu.Unlock()
+
+	refs, err := p.Store.ActiveMediaRefs()
+	if err != nil {
+		p.finish("")
+		return p.Status(), err
+	}
+	p.update(func(r *Report) { r.Total = len(refs) })
+	interrupt := ""
+	for _, ref := range refs {
+		if ctx.Err() != nil {
+			interrupt = "cancelled"
+			break
+		}
+		if idle != nil && !idle() {
+			interrupt = "send window started"
+			break
+		}
+		remote := !strings.HasPrefix(ref.URL, "/uploads/") && !strings.HasPrefix(ref.URL, "uploads/")
+		cached := !remote || p.Cache.Fresh(ref.URL)
+		data, ct, err := p.Fetch(ctx, ref.URL)
+		if err == nil {
+			err = Validate(ref.Kind, ct, int64(len(data)))
+		}
+		p.update(func(r *Report) {
+			if err != nil {
+				r.Problems = append(r.Problems, Problem{URL: ref.URL, Kind: ref.Kind, Error: err.Error()})
+				return
+			}
+			r.Bytes += int64(len(data))
+			if cached {
+				r.Cached++
+			} else {
+				r.Downloaded++
+			}
+		})
+		if !cached && p.Delay > 0 {
+			select {
+			case <-ctx.Done():
+			case <-time.After(p.Delay):
+			}
+		}
+	}
+	p.finish(interrupt)
+	rep := p.Status()
+	log.Printf("[prefetch] total=%d cached=%d downloaded=%d problems=%d bytes=%d interrupted=%q",
+		rep.Total, rep.Cached, rep.Downloaded, len(rep.Problems), rep.Bytes, interrupt)
+	return rep, nil
+}
+
+// Start menjalankan prefetch manual di background (tanpa cek jendela kirim).
+func (p *Prefetcher) Start(ctx context.Context) error {
+	if p.Status().Running {
+		return ErrRunning
+	}
+	go func() {
+		if _, err := p.Run(ctx, nil); err != nil && err != ErrRunning {
+			log.Printf("[prefetch] manual run err=%v", err)
+		}
+	}()
+	return nil
+}
+
+func (p *Prefetcher) update(fn func(r *Report)) {
+	p.mu.Lock()
+	fn(&p.report)
+	p.mu.Unlock()
+}
+
+func (p *Prefetcher) finish(interrupt string) {
+	now := time.Now()
+	p.update(func(r *Report) {
+		r.Running = false
+		r.Interrupt = interrupt
+		r.FinishedAt = &now
+	})
+}
+
+// Validate memeriksa content-type dan ukuran media sesuai jenisnya (image, video, gif, audio,
+// voice_note, sticker, document).
+func Validate(kind, contentType string, size int64) error {
+	if size == 0 {
+		return errors.New("empty file")
+	}
+	if max := maxBytes[kind]; max > 0 && size > max {
+		return fmt.Errorf("%s too large: %d MB (max %d MB)", kind, size>>20, max>>20)
+	}
+	ct := strings.ToLower(contentType)
+	switch kind {
+	case "image", "video", "audio":
+		if !strings.HasPrefix(ct, kind+"/") && !strings.HasPrefix(ct, "application/octet-stream") {
+			return fmt.Errorf("unexpected content-type %q for %s", contentType, kind)
+		}
+	case "gif":
+		// GifPlayback WhatsApp memakai mp4, bukan image/gif.
+		if !strings.HasPrefix(ct, "video/") && !strings.HasPrefix(ct, "application/octet-stream") {
+			return fmt.Errorf("gif must be an mp4 video, got %q", contentType)
+		}
+	case "voice_note":
+		if !strings.HasPrefix(ct, "audio/") && !strings.HasPrefix(ct, "application/octet-stream") {
+			return fmt.Errorf("unexpected content-type %q for %s", contentType, kind)
+		}
+	case "sticker":
+		if !strings.HasPrefix(ct, "image/webp") && !strings.HasPrefix(ct, "application/octet-stream") {
+			return fmt.Errorf("sticker must be image/webp, got %q", contentType)
+		}
+	}
+	return nil
+}
@@ -1,6 +1,17 @@
 package model
 
-import "time"
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
 
 // Account status constants for lifecycle tracking.
 const (
@@ -14,15 +25,25 @@ const (
 
 // Account represents a WhatsApp device/account managed by the system.
 type Account struct {
-	ID         string    `json:"id" db:"id"`
-	Label      string    `json:"label" db:"label"`
-	Msisdn     string    `json:"msisdn" db:"msisdn"`
-	Enabled    bool      `json:"enabled" db:"enabled"`
-	DailyLimit int       `json:"daily_limit" db:"daily_limit"`
-	Status     string    `json:"status" db:"status"`
-	LastError  string    `json:"last_error,omitempty" db:"last_error"`
-	CreatedAt  time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt  time.Time `json:"updated_at" db:"updated_at"`
+	ID         string `json:"id" db:"id"`
+	Label      string `json:"label" db:"label"`
+	Msisdn     string `json:"msisdn" db:"msisdn"`
+	Enabled    bool   `json:"enabled" db:"enabled"`
+	DailyLimit int    `json:"daily_limit" db:"daily_limit"`
+	// DMDailyLimit batas DM campaign per hari (terpisah dari kiriman grup).
+	DMDailyLimit int `json:"dm_daily_limit" db:"dm_daily_limit"`
+	// StatusDailyLimit batas posting status (story) per hari; 0 = posting status nonaktif.
+	StatusDailyLimit int `json:"status_daily_limit" db:"status_daily_limit"`
+	// Observer: sesi hanya untuk monitoring (inbox, sync grup, analitik); semua jalur kirim menolak.
+	Observer  bool      `json:"observer" db:"observer"`
+	Status    string    `json:"status" db:"status"`
+	LastError string    `json:"last_error,omitempty" db:"last_error"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+	// ThrottledUntil akun dihentikan setelah WhatsApp menolak kiriman karena rate-limit/spam
+	// (nil = tidak sedang di-throttle).
+	ThrottledUntil *time.Time `json:"throttled_until,omitempty" db:"throttled_until"`
+	ThrottleReason string     `json:"throttle_reason,omitempty" db:"throttle_reason"`
 }
 
 // Group represents a WhatsApp group (chat) discovered via scanning for an account.
@@ -34,21 +55,67 @@ type Group struct {
 	LastSentAt *time.Time `json:"last_sent_at,omitempty" db:"last_sent_at"`
 	RiskScore  int        `json:"risk_score" db:"risk_score"`
 	CreatedAt  time.Time  `json:"created_at" db:"created_at"`
+	// ComplianceFlag berisi frasa aturan grup yang cocok di deskripsi (kosong = aman).
+	ComplianceFlag string `json:"compliance_flag,omitempty" db:"compliance_flag"`
+	// Atribut untuk targeting campaign.
+	ParticipantCount int    `json:"participant_count" db:"participant_count"`
+	Language         string `json:"language,omitempty" db:"language"`
+	// DetectedLanguage hasil deteksi otomatis dari nama/deskripsi saat sinkron; dipakai
+	// targeting jika Language (manual) kosong.
+	DetectedLanguage string   `json:"detected_language,omitempty" db:"language_detected"`
+	Tags             []string `json:"tags,omitempty"`
+	// TestGroup: grup uji akun; tujuan semua kiriman saat safe mode aktif.
+	TestGroup bool `json:"test_group,omitempty" db:"is_test"`
+	// CommunityRole posisi grup dalam komunitas WhatsApp (GroupRole*, kosong = grup biasa);
+	// ParentJID JID komunitas induk untuk sub-grup dan grup pengumuman.
+	CommunityRole string `json:"community_role,omitempty" db:"community_role"`
+	ParentJID     string `json:"parent_jid,omitempty" db:"parent_jid"`
+	// CommunityExcluded: grup tidak ikut broadcast karena perannya di komunitas (lihat
+	// setting community_announcements_eligible).
+	CommunityExcluded bool `json:"community_excluded,omitempty" db:"community_excluded"`
 }
 
+// Peran grup dalam komunitas WhatsApp.
+const (
+	// GroupRoleParent grup induk komunitas; tidak bisa dikirimi pesan, selalu dikecualikan.
+	GroupRoleParent = "parent"
+	// GroupRoleAnnouncement grup pengumuman default komunitas (hanya admin yang bisa posting).
+	GroupRoleAnnouncement = "announcement"
+	// GroupRoleSubgroup grup biasa yang tertaut ke komunitas.
+	GroupRoleSubgroup = "subgroup"
+)
+
 // Campaign defines flexible promotional content (text + media).
+// Media disimpan sebagai JSON array URL di kolom media_*.
 type Campaign struct {
-	ID                string    `json:"id" db:"id"`
-	Name              string    `json:"name" db:"name"`
-	Text              string    `json:"text" db:"text"`
-	MediaImagesJSON   string    `json:"media_images_json" db:"media_images"`
-	MediaVideosJSON   string    `json:"media_videos_json" db:"media_videos"`
-	MediaStickersJSON string    `json:"media_stickers_json" db:"media_stickers"`
-	MediaDocsJSON     string    `json:"media_docs_json" db:"media_docs"`
-	Enabled           bool      `json:"enabled" db:"enabled"`
-	PerGroupVariants  int       `json:"per_group_variants" db:"per_group_variants"`
-	CreatedAt         time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt         time.Time `json:"updated_at" db:"updated_at"`
+	ID               string     `json:"id" db:"id"`
+	Name             string     `json:"name" db:"name"`
+	Text             string     `json:"text" db:"text"`
+	ImageURLs        []string   `json:"image_urls" db:"media_images"`
+	VideoURLs        []string   `json:"video_urls" db:"media_videos"`
+	StickerURLs      []string   `json:"sticker_urls" db:"media_stickers"`
+	DocURLs          []string   `json:"doc_urls" db:"media_docs"`
+	Enabled          bool       `json:"enabled" db:"enabled"`
+	PerGroupVariants int        `json:"per_group_variants" db:"per_group_variants"`
+	Targeting        *Targeting `json:"targeting,omitempty" db:"targeting"`
+	// Mode: group (default, lewat jadwal/rotasi grup), dm (ke nomor di dm_targets) atau
+	// crosspromo (mempromosikan grup sendiri PromoteGroupID ke grup yang cocok targeting).
+	Mode           string    `json:"mode" db:"mode"`
+	PromoteGroupID string    `json:"promote_group_id,omitempty" db:"promote_group_id"`
+	CreatedAt      time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// Mode campaign.
+const (
+	CampaignModeGroup      = "group"
+	CampaignModeDM         = "dm"
+	CampaignModeCrossPromo = "crosspromo"
+)
+
+// HasContent true jika campaign punya teks atau media untuk dikirim.
+func (c Campaign) HasContent() bool {
+	return strings.TrimSpace(c.Text) != "" || len(c.ImageURLs)+len(c.VideoURLs)+len(c.StickerURLs)+len(c.DocURLs) > 0
 }
 
 // Schedule configures anti-spam safe scheduling for a campaign/account.
@@ -68,16 +135,2012 @@ type Schedule struct {
 	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
 }
 
+var weekdayNames = map[string]time.Weekday{
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+}
+
+// ParseDaysMask mengurai days_mask ("Mon,Wed,Fri", "Mon-Fri", "Sat-Sun"). Kosong = setiap hari.
+func ParseDaysMask(mask string) ([7]bool, error) {
+	var days [7]bool
+	if strings.TrimSpace(mask) == "" {
+		for i := range days {
+			days[i] = true
+		}
+		return days, nil
+	}
+	for _, part := range strings.Split(mask, ",") {
+		part = strings.ToLower(strings.TrimSpace(part))
+		if part == "" {
+			continue
+		}
+		from, to, isRange := strings.Cut(part, "-")
+		start, ok1 := weekdayNames[strings.TrimSpace(from)]
+		end := start
+		ok2 := true
+		if isRange {
+			end, ok2 = weekdayNames[strings.TrimSpace(to)]
+		}
+		if !ok1 || !ok2 {
+			return days, errors.New("invalid days_mask day: " + part)
+		}
+		for d := start; ; d = (d + 1) % 7 {
+			days[d] = true
+			if d == end {
+				break
+			}
+		}
+	}
+	return days, nil
+}
+
+// Validate memeriksa jam, jeda, batas, dan days_mask.
+func (s Schedule) Validate() error {
+	if s.CampaignID == "" || s.AccountID == "" {
+		return errors.New("campaign_id and account_id required")
+	}
+	if s.StartHour < 0 || s.StartHour > 23 || s.EndHour < 1 || s.EndHour > 24 || s.StartHour == s.EndHour {
+		return errors.New("start_hour must be 0-23, end_hour 1-24 and different")
+	}
+	if s.MinDelaySec < 0 || s.MaxDelaySec < s.MinDelaySec {
+		return errors.New("delays must satisfy 0 <= min_delay_sec <= max_delay_sec")
+	}
+	if s.BatchSize <= 0 || s.DailyLimit <= 0 {
+		return errors.New("batch_size and daily_limit must be > 0")
+	}
+	_, err := ParseDaysMask(s.DaysMask)
+	return err
+}
+
+// Active melaporkan apakah t (zona lokal jadwal) berada di hari dan jam jadwal. Jendela yang
+// melewati tengah malam (start_hour > end_hour) dihitung milik hari saat jendela dimulai.
+func (s Schedule) Active(t time.Time) bool {
+	days, err := ParseDaysMask(s.DaysMask)
+	if err != nil {
+		return false
+	}
+	h := t.Hour()
+	if s.StartHour < s.EndHour {
+		return days[t.Weekday()] && h >= s.StartHour && h < s.EndHour
+	}
+	if h >= s.StartHour {
+		return days[t.Weekday()]
+	}
+	return h < s.EndHour && days[(t.Weekday()+6)%7]
+}
+
+// HolidayLayout format tanggal hari libur (tanggal kalender WIB).
+const HolidayLayout = "2006-01-02"
+
+// Holiday tanggal libur (mis. Lebaran) yang dilewati semua broadcast otomatis.
+type Holiday struct {
+	Date      string    `json:"date"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Validate memeriksa format tanggal (YYYY-MM-DD).
+func (h Holiday) Validate() error {
+	if _, err := time.Parse(HolidayLayout, h.Date); err != nil {
+		return fmt.Errorf("invalid date %q (want YYYY-MM-DD)", h.Date)
+	}
+	return nil
+}
+
+// RecurringSchedule aturan kiriman berulang ala cron, mis. "setiap Mon,Thu jam 21:45 kirim
+// template X ke grup bertag jualan". Pada tiap jadwal, grup yang cocok dimasukkan ke outbox dan
+// dikirim scheduler dengan cek risiko dan cooldown seperti kiriman biasa.
+type RecurringSchedule struct {
+	ID   string `json:"id" db:"id"`
+	Name string `json:"name" db:"name"`
+	// AccountID membatasi ke grup milik satu akun; kosong = semua akun.
+	AccountID string `json:"account_id,omitempty" db:"account_id"`
+	DaysMask  string `json:"days_mask" db:"days_mask"`
+	// Times jam kirim "HH:MM" (zona WIB) pada hari yang cocok.
+	Times      []string  `json:"times" db:"times"`
+	TemplateID string    `json:"template_id,omitempty" db:"template_id"`
+	CampaignID string    `json:"campaign_id,omitempty" db:"campaign_id"`
+	Targeting  Targeting `json:"targeting" db:"targeting"`
+	Priority   int       `json:"priority" db:"priority"`
+	Enabled    bool      `json:"enabled" db:"enabled"`
+	// LastRunAt jadwal terakhir yang sudah dijalankan (atau dilewati karena terlambat).
+	LastRunAt *time.Time `json:"last_run_at,omitempty" db:"last_run_at"`
+	CreatedAt time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+// Validate memeriksa jam, days_mask, sumber konten (tepat satu template_id atau campaign_id),
+// dan sasaran (akun dan/atau targeting wajib diisi agar tidak terkirim ke semua grup).
+func (r RecurringSchedule) Validate() error {
+	if len(r.Times) == 0 {
+		return errors.New("times required")
+	}
+	for _, v := range r.Times {
+		if m, err := parseClock(v); err != nil || m >= 1440 {
+			return fmt.Errorf("invalid time %q (want HH:MM, 00:00-23:59)", v)
+		}
+	}
+	if _, err := ParseDaysMask(r.DaysMask); err != nil {
+		return err
+	}
+	if (r.TemplateID == "") == (r.CampaignID == "") {
+		return errors.New("exactly one of template_id or campaign_id required")
+	}
+	if r.AccountID == "" && r.Targeting.Empty() {
+		return errors.New("account_id or targeting required")
+	}
+	return r.Targeting.Validate()
+}
+
+// occurrences jadwal pada hari tanggal day (zona day), urut naik.
+func (r RecurringSchedule) occurrences(day time.Time, days [7]bool) []time.Time {
+	if !days[day.Weekday()] {
+		return nil
+	}
+	y, m, d := day.Date()
+	var out []time.Time
+	for _, v := range r.Times {
+		if min, err := parseClock(v); err == nil {
+			out = append(out, time.Date(y, m, d, min/60, min%60, 0, 0, day.Location()))
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Before(out[j]) })
+	return out
+}
+
+// NextRun jadwal pertama setelah t (zona t); zero jika aturan tidak valid.
+func (r RecurringSchedule) NextRun(t time.Time) time.Time {
+	days, err := ParseDaysMask(r.DaysMask)
+	if err != nil {
+		return time.Time{}
+	}
+	for i := 0; i <= 7; i++ {
+		for _, at := range r.occurrences(t.AddDate(0, 0, i), days) {
+			if at.After(t) {
+				return at
+			}
+		}
+	}
+	return time.Time{}
+}
+
+// PrevRun jadwal terakhir pada atau sebelum t (zona t); zero jika aturan tidak valid.
+func (r RecurringSchedule) PrevRun(t time.Time) time.Time {
+	days, err := ParseDaysMask(r.DaysMask)
+	if err != nil {
+		return time.Time{}
+	}
+	for i := 0; i <= 7; i++ {
+		occ := r.occurrences(t.AddDate(0, 0, -i), days)
+		for j := len(occ) - 1; j >= 0; j-- {
+			if !occ[j].After(t) {
+				return occ[j]
+			}
+		}
+	}
+	return time.Time{}
+}
+
+// WarmupStep batas kiriman grup harian pada hari ke-Day sejak akun pertama kali dipasangkan
+// (hari pertama = 1).
+type WarmupStep struct {
+	Day   int `json:"day"`
+	Limit int `json:"limit"`
+}
+
+// WarmupProfile menaikkan limit harian nomor baru bertahap; di antara dua langkah limit naik
+// linear, setelah langkah terakhir daily_limit akun berlaku penuh. Limit warmup tidak pernah
+// menaikkan daily_limit akun.
+type WarmupProfile struct {
+	Enabled bool         `json:"enabled"`
+	Steps   []WarmupStep `json:"steps"`
+}
+
+// DefaultWarmupProfile profil bawaan (nonaktif): hari 1: 5, hari 7: 30, hari 30: 100.
+func DefaultWarmupProfile() WarmupProfile {
+	return WarmupProfile{Steps: []WarmupStep{{Day: 1, Limit: 5}, {Day: 7, Limit: 30}, {Day: 30, Limit: 100}}}
+}
+
+// Validate memeriksa langkah: hari naik mulai dari 1 dan limit >= 1.
+func (p WarmupProfile) Validate() error {
+	if p.Enabled && len(p.Steps) == 0 {
+		return errors.New("steps required when enabled")
+	}
+	for i, st := range p.Steps {
+		if st.Day < 1 || st.Limit < 1 {
+			return errors.New("steps need day >= 1 and limit >= 1")
+		}
+		if i > 0 && st.Day <= p.Steps[i-1].Day {
+			return errors.New("step days must be increasing")
+		}
+	}
+	return nil
+}
+
+// WarmupDay hari warmup akun pada now (1 = 24 jam pertama sejak dipasangkan).
+func WarmupDay(pairedAt, now time.Time) int {
+	if now.Before(pairedAt) {
+		return 1
+	}
+	return int(now.Sub(pairedAt)/(24*time.Hour)) + 1
+}
+
+// LimitForDay batas harian warmup pada hari day; ok=false jika profil nonaktif atau warmup sudah
+// selesai (day >= hari langkah terakhir).
+func (p WarmupProfile) LimitForDay(day int) (int, bool) {
+	if !p.Enabled || len(p.Steps) == 0 || day >= p.Steps[len(p.Steps)-1].Day {
+		return 0, false
+	}
+	if day <= p.Steps[0].Day {
+		return p.Steps[0].Limit, true
+	}
+	for i := 1; i < len(p.Steps); i++ {
+		a, b := p.Steps[i-1], p.Steps[i]
+		if day < b.Day {
+			return a.Limit + (b.Limit-a.Limit)*(day-a.Day)/(b.Day-a.Day), true
+		}
+	}
+	return 0, false
+}
+
+// Apply limit harian efektif akun pada now: daily_limit dibatasi limit warmup bila masih berjalan.
+func (p WarmupProfile) Apply(dailyLimit int, pairedAt, now time.Time) int {
+	if w, ok := p.LimitForDay(WarmupDay(pairedAt, now)); ok && w < dailyLimit {
+		return w
+	}
+	return dailyLimit
+}
+
+// GroupSendCaps batas kiriman promo ke satu grup dari semua akun, di luar cooldown: PerDay per
+// hari kuota dan PerWeek per 7 hari kuota terakhir (termasuk hari ini). 0 = tanpa batas.
+type GroupSendCaps struct {
+	PerDay  int `json:"per_day"`
+	PerWeek int `json:"per_week"`
+}
+
+// Validate memeriksa batas tidak negatif.
+func (c GroupSendCaps) Validate() error {
+	if c.PerDay < 0 || c.PerWeek < 0 {
+		return errors.New("per_day and per_week must be >= 0")
+	}
+	return nil
+}
+
+// GroupCapUsage pemakaian batas kiriman grup. Custom* true jika grup punya batas sendiri
+// (menggantikan batas default).
+type GroupCapUsage struct {
+	GroupID       string `json:"group_id"`
+	SentToday     int    `json:"sent_today"`
+	SentWeek      int    `json:"sent_week"`
+	PerDay        int    `json:"per_day"`
+	PerWeek       int    `json:"per_week"`
+	CustomPerDay  bool   `json:"custom_per_day"`
+	CustomPerWeek bool   `json:"custom_per_week"`
+	Capped        bool   `json:"capped"`
+}
+
+// DefaultWindows jendela kirim bawaan (menit dari tengah malam WIB):
+// 00:45–02:30, 03:00–05:30, 21:30–23:30.
+var DefaultWindows = [][2]int{{45, 150}, {180, 330}, {1290, 1410}}
+
+// WeekWindows jendela kirim scheduler per hari (indeks time.Weekday, 0 = Minggu). Tiap jendela
+// [start, end] dalam menit dari tengah malam, inklusif, tidak melewati tengah malam. Hari tanpa
+// jendela berarti tidak ada kiriman grup di hari itu. JSON: {"mon":["00:45-02:30",...],...}.
+type WeekWindows [7][][2]int
+
+// DefaultWeekWindows DefaultWindows untuk setiap hari.
+func DefaultWeekWindows() WeekWindows {
+	var w WeekWindows
+	for i := range w {
+		w[i] = append([][2]int{}, DefaultWindows...)
+	}
+	return w
+}
+
+// ParseWindow mengurai "HH:MM-HH:MM" menjadi [start, end] menit.
+func ParseWindow(s string) ([2]int, error) {
+	a, b, ok := strings.Cut(strings.TrimSpace(s), "-")
+	if !ok {
+		return [2]int{}, fmt.Errorf("invalid window %q (want HH:MM-HH:MM)", s)
+	}
+	start, err := parseClock(a)
+	if err != nil {
+		return [2]int{}, err
+	}
+	end, err := parseClock(b)
+	if err != nil {
+		return [2]int{}, err
+	}
+	if start >= end {
+		return [2]int{}, fmt.Errorf("window %q must end after it starts (same day)", s)
+	}
+	return [2]int{start, end}, nil
+}
+
+func parseClock(s string) (int, error) {
+	h, m, ok := strings.Cut(strings.TrimSpace(s), ":")
+	hh, err1 := strconv.Atoi(h)
+	mm, err2 := strconv.Atoi(m)
+	if !ok || err1 != nil || err2 != nil || hh < 0 || mm < 0 || mm > 59 || hh*60+mm > 1440 {
+		return 0, fmt.Errorf("invalid time %q (want HH:MM)", s)
+	}
+	return hh*60 + mm, nil
+}
+
+// FormatWindow kebalikan ParseWindow.
+func FormatWindow(w [2]int) string {
+	return fmt.Sprintf("%02d:%02d-%02d:%02d", w[0]/60, w[0]%60, w[1]/60, w[1]%60)
+}
+
+// For jendela untuk hari t.
+func (w WeekWindows) For(t time.Time) [][2]int {
+	return w[t.Weekday()]
+}
+
+// Active true jika t berada di salah satu jendela harinya.
+func (w WeekWindows) Active(t time.Time) bool {
+	m := t.Hour()*60 + t.Minute()
+	for _, win := range w[t.Weekday()] {
+		if m >= win[0] && m <= win[1] {
+			return true
+		}
+	}
+	return false
+}
+
+// Occurrence satu jendela kirim pada tanggal tertentu.
+type Occurrence struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+}
+
+// Upcoming mengembalikan jendela yang sedang berjalan atau akan dimulai dalam days hari sejak t
+// (zona t), terurut waktu.
+func (w WeekWindows) Upcoming(t time.Time, days int) []Occurrence {
+	var out []Occurrence
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	for d := 0; d <= days; d++ {
+		date := day.AddDate(0, 0, d)
+		wins := append([][2]int{}, w[date.Weekday()]...)
+		sort.Slice(wins, func(i, j int) bool { return wins[i][0] < wins[j][0] })
+		for _, win := range wins {
+			start := date.Add(time.Duration(win[0]) * time.Minute)
+			end := date.Add(time.Duration(win[1]) * time.Minute)
+			if end.Before(t) || start.After(t.AddDate(0, 0, days)) {
+				continue
+			}
+			out = append(out, Occurrence{Start: start, End: end})
+		}
+	}
+	return out
+}
+
+// Next jendela berikutnya (atau yang sedang berjalan) dalam seminggu sejak t; ok=false jika
+// tidak ada jendela sama sekali.
+func (w WeekWindows) Next(t time.Time) (Occurrence, bool) {
+	list := w.Upcoming(t, 7)
+	if len(list) == 0 {
+		return Occurrence{}, false
+	}
+	return list[0], true
+}
+
+// OpenBetween total waktu jendela yang terbuka di antara from dan to (zona from, maks dua hari).
+func (w WeekWindows) OpenBetween(from, to time.Time) time.Duration {
+	var open time.Duration
+	for _, o := range w.Upcoming(from, 2) {
+		start, end := o.Start, o.End
+		if start.Before(from) {
+			start = from
+		}
+		if end.After(to) {
+			end = to
+		}
+		if end.After(start) {
+			open += end.Sub(start)
+		}
+	}
+	return open
+}
+
+// MarshalJSON menulis {"sun":["HH:MM-HH:MM",...],...}.
+func (w WeekWindows) MarshalJSON() ([]byte, error) {
+	out := map[string][]string{}
+	for name, wd := range weekdayNames {
+		list := []string{}
+		for _, win := range w[wd] {
+			list = append(list, FormatWindow(win))
+		}
+		out[name] = list
+	}
+	return json.Marshal(out)
+}
+
+// UnmarshalJSON membaca bentuk MarshalJSON; kunci boleh berupa days mask ("mon-fri").
+// Hari yang tidak disebut dibiarkan apa adanya.
+func (w *WeekWindows) UnmarshalJSON(b []byte) error {
+	var in map[string][]string
+	if err := json.Unmarshal(b, &in); err != nil {
+		return err
+	}
+	for key, list := range in {
+		days, err := ParseDaysMask(key)
+		if err != nil || strings.TrimSpace(key) == "" {
+			return fmt.Errorf("invalid day %q", key)
+		}
+		wins := [][2]int{}
+		for _, s := range list {
+			win, err := ParseWindow(s)
+			if err != nil {
+				return err
+			}
+			wins = append(wins, win)
+		}
+		for wd, on := range days {
+			if on {
+				w[wd] = wins
+			}
+		}
+	}
+	return nil
+}
+
 // LogEntry keeps audit/log for send attempts for monitoring & pause triggers.
 type LogEntry struct {
-	ID           int       `json:"id" db:"id"`
-	TS           time.Time `json:"ts" db:"ts"`
-	AccountID    string    `json:"account_id" db:"account_id"`
-	GroupID      string    `json:"group_id" db:"group_id"`
-	CampaignID   string    `json:"campaign_id" db:"campaign_id"`
-	Status       string    `json:"status" db:"status"` // sent|failed|paused|skipped
-	Error        string    `json:"error" db:"error"`
-	MessagePrev  string    `json:"message_preview" db:"message_preview"`
-	Attempt      int       `json:"attempt" db:"attempt"`
-	ScheduledFor time.Time `json:"scheduled_for" db:"scheduled_for"`
+	ID           int64      `json:"id" db:"id"`
+	TS           time.Time  `json:"ts" db:"ts"`
+	AccountID    string     `json:"account_id" db:"account_id"`
+	GroupID      string     `json:"group_id" db:"group_id"`
+	CampaignID   string     `json:"campaign_id" db:"campaign_id"`
+	SessionID    string     `json:"campaign_session_id" db:"campaign_session_id"`
+	Status       string     `json:"status" db:"status"` // sent|failed|paused|skipped
+	Error        string     `json:"error" db:"error"`
+	MessagePrev  string     `json:"message_preview" db:"message_preview"`
+	MessageID    string     `json:"message_id,omitempty" db:"message_id"` // ID pesan WhatsApp (status sent)
+	Attempt      int        `json:"attempt" db:"attempt"`
+	ScheduledFor time.Time  `json:"scheduled_for" db:"scheduled_for"`     // zero jika tidak dijadwalkan
+	RevokedAt    *time.Time `json:"revoked_at,omitempty" db:"revoked_at"` // dihapus untuk semua orang
+	// Versi template yang dipakai (kosong untuk konten campaign); isi lihat TemplateVersion.
+	TemplateID      string `json:"template_id,omitempty" db:"template_id"`
+	TemplateVersion int    `json:"template_version,omitempty" db:"template_version"`
+}
+
+// Template adalah konten promosi global yang dirotasi acak saat grup tidak punya campaign.
+// Media disimpan sebagai JSON array URL di kolom *_json.
+type Template struct {
+	ID           string   `json:"id" db:"id"`
+	Name         string   `json:"name" db:"name"`
+	TextOnly     string   `json:"text_only" db:"text_only"`
+	ImageURLs    []string `json:"image_urls" db:"images_json"`
+	ImageCaption string   `json:"image_caption" db:"images_caption"`
+	VideoURLs    []string `json:"video_urls" db:"videos_json"`
+	VideoCaption string   `json:"video_caption" db:"videos_caption"`
+	// Products item katalog yang dikirim sebagai product message (akun Business) atau
+	// gambar + caption (akun biasa).
+	Products []ProductItem `json:"products" db:"products_json"`
+	// GifURLs video mp4 pendek yang dikirim dengan GifPlayback (caption = VideoCaption).
+	GifURLs   []string `json:"gif_urls" db:"gifs_json"`
+	AudioURLs []string `json:"audio_urls" db:"audio_json"`
+	// VoiceURLs dikirim sebagai voice note (PTT, OGG/Opus).
+	VoiceURLs   []string `json:"voice_urls" db:"voice_json"`
+	StickerURLs []string `json:"sticker_urls" db:"stickers_json"`
+	DocURLs     []string `json:"doc_urls" db:"docs_json"`
+	DocCaption  string   `json:"doc_caption" db:"docs_caption"`
+	LinkPreview bool     `json:"link_preview" db:"link_preview"`
+	// Kartu kontak (vCard) opsional, dikirim terakhir agar penerima bisa tap-to-save.
+	ContactName  string `json:"contact_name" db:"contact_name"`
+	ContactPhone string `json:"contact_phone" db:"contact_phone"`
+	// Kebijakan jika satu media gagal: abort (default), skip, atau fallback ke FallbackImageURL.
+	MediaFailPolicy  string `json:"media_fail_policy" db:"media_fail_policy"`
+	FallbackImageURL string `json:"fallback_image_url" db:"fallback_image_url"`
+	// MentionAll: teks-only me-mention semua anggota grup (dari cache participant).
+	MentionAll  bool   `json:"mention_all" db:"mention_all"`
+	HealthError string `json:"health_error" db:"health_error"`
+	// Lint hasil pemeriksaan pola konten berisiko terakhir (diisi saat simpan / POST lint).
+	Lint *LintReport `json:"lint,omitempty" db:"lint_json"`
+	// Languages kode bahasa grup tujuan template (kosong = semua bahasa); template acak untuk
+	// grup hanya dipilih dari yang cocok dengan bahasa grup.
+	Languages []string `json:"languages" db:"languages"`
+	// CampaignID campaign asal template (mis. dibuat feed milik campaign); kosong = template umum.
+	CampaignID string `json:"campaign_id,omitempty" db:"campaign_id"`
+	// Version naik setiap isi template diubah; snapshot tiap versi ada di template_versions.
+	Version   int       `json:"version" db:"version"`
+	Enabled   bool      `json:"enabled" db:"enabled"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// TemplateVersion snapshot isi template yang tidak berubah setelah dibuat.
+type TemplateVersion struct {
+	TemplateID string    `json:"template_id"`
+	Version    int       `json:"version"`
+	Content    Template  `json:"content"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// Tingkat risiko hasil lint konten.
+const (
+	LintRiskLow    = "low"
+	LintRiskMedium = "medium"
+	LintRiskHigh   = "high"
+)
+
+// LintIssue satu pola berisiko yang ditemukan di teks template.
+type LintIssue struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	Points  int    `json:"points"`
+}
+
+// LintReport ringkasan risiko konten: Score 0–100 (jumlah poin issue) dan Risk low|medium|high.
+type LintReport struct {
+	Score     int         `json:"score"`
+	Risk      string      `json:"risk"`
+	Issues    []LintIssue `json:"issues"`
+	CheckedAt time.Time   `json:"checked_at"`
+}
+
+// ProductItem snapshot item katalog WhatsApp Business untuk product message. ProductID harus
+// ID produk di katalog akun pengirim agar penerima bisa membukanya dari katalog.
+type ProductItem struct {
+	ProductID   string  `json:"product_id"`
+	RetailerID  string  `json:"retailer_id,omitempty"`
+	Title       string  `json:"title"`
+	Description string  `json:"description,omitempty"`
+	Currency    string  `json:"currency,omitempty"` // ISO 4217, default IDR
+	Price       float64 `json:"price,omitempty"`
+	ImageURL    string  `json:"image_url"`
+	URL         string  `json:"url,omitempty"`
+}
+
+// Validate memeriksa field wajib item produk.
+func (p ProductItem) Validate() error {
+	switch {
+	case strings.TrimSpace(p.ProductID) == "":
+		return errors.New("product_id is required")
+	case strings.TrimSpace(p.Title) == "":
+		return errors.New("product title is required")
+	case strings.TrimSpace(p.ImageURL) == "":
+		return errors.New("product image_url is required")
+	case p.Price < 0:
+		return errors.New("product price must be >= 0")
+	case p.Currency != "" && len(p.Currency) != 3:
+		return errors.New("product currency must be a 3-letter ISO 4217 code")
+	}
+	return nil
+}
+
+// CurrencyCode mata uang item (default IDR).
+func (p ProductItem) CurrencyCode() string {
+	if p.Currency == "" {
+		return "IDR"
+	}
+	return strings.ToUpper(p.Currency)
+}
+
+// Caption teks pengganti product message untuk akun non-Business:
+// judul tebal, harga, deskripsi, lalu link.
+func (p ProductItem) Caption() string {
+	lines := []string{"*" + p.Title + "*"}
+	if p.Price > 0 {
+		lines = append(lines, p.CurrencyCode()+" "+strconv.FormatFloat(p.Price, 'f', -1, 64))
+	}
+	if p.Description != "" {
+		lines = append(lines, p.Description)
+	}
+	if p.URL != "" {
+		lines = append(lines, p.URL)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Participant adalah anggota grup WhatsApp (cache tabel group_participants).
+type Participant struct {
+	JID          string `json:"jid" db:"jid"`
+	Number       string `json:"number" db:"number"`
+	IsAdmin      bool   `json:"is_admin" db:"is_admin"`
+	IsSuperAdmin bool   `json:"is_superadmin" db:"is_superadmin"`
+}
+
+// AccountStatus is a dashboard-oriented snapshot of an account: connection state,
+// today's quota usage and the most recent successful send.
+type AccountStatus struct {
+	ID            string     `json:"id"`
+	Label         string     `json:"label"`
+	Msisdn        string     `json:"msisdn"`
+	Enabled       bool       `json:"enabled"`
+	Status        string     `json:"status"`
+	LastError     string     `json:"last_error,omitempty"`
+	Paired        bool       `json:"paired"`
+	Connected     bool       `json:"connected"`
+	DailyLimit    int        `json:"daily_limit"`
+	SentToday     int64      `json:"sent_today"`
+	FailedToday   int64      `json:"failed_today"`
+	QuotaLeft     int64      `json:"quota_left"`
+	LastSentAt    *time.Time `json:"last_sent_at,omitempty"`
+	GroupsTotal   int64      `json:"groups_total"`
+	GroupsEnabled int64      `json:"groups_enabled"`
+	// Soft bounce: terkirim ke server tapi tanpa receipt delivered sampai timeout.
+	SoftBouncedToday int64   `json:"soft_bounced_today"`
+	SoftBounceRate7d float64 `json:"soft_bounce_rate_7d"`
+}
+
+// AccountStreak kondisi kirim beruntun akun untuk metrik alert: jumlah kiriman gagal
+// (failed/throttled) berturut-turut sejak kiriman sukses terakhir.
+type AccountStreak struct {
+	AccountID     string
+	Label         string
+	Enabled       bool
+	Throttled     bool
+	FailureStreak int64
+	LastSuccessAt *time.Time
+	LastFailureAt *time.Time
+}
+
+// Feed modes: approval = template dibuat nonaktif menunggu persetujuan; auto = langsung masuk rotasi.
+const (
+	FeedModeApproval = "approval"
+	FeedModeAuto     = "auto"
+)
+
+// Feed is an RSS/Atom/JSON feed watched to auto-generate templates for a campaign.
+type Feed struct {
+	ID           string     `json:"id" db:"id"`
+	CampaignID   string     `json:"campaign_id,omitempty" db:"campaign_id"`
+	Name         string     `json:"name" db:"name"`
+	URL          string     `json:"url" db:"url"`
+	Mode         string     `json:"mode" db:"mode"`
+	Enabled      bool       `json:"enabled" db:"enabled"`
+	PollMinutes  int        `json:"poll_minutes" db:"poll_minutes"`
+	LastPolledAt *time.Time `json:"last_polled_at,omitempty" db:"last_polled_at"`
+	LastError    string     `json:"last_error,omitempty" db:"last_error"`
+	CreatedAt    time.Time  `json:"created_at" db:"created_at"`
+}
+
+// FeedItem records a feed entry that has been seen, and the template generated from it.
+type FeedItem struct {
+	ID         int64     `json:"id" db:"id"`
+	FeedID     string    `json:"feed_id" db:"feed_id"`
+	GUID       string    `json:"guid" db:"guid"`
+	Title      string    `json:"title" db:"title"`
+	Link       string    `json:"link" db:"link"`
+	Summary    string    `json:"summary" db:"summary"`
+	ImageURL   string    `json:"image_url,omitempty" db:"image_url"`
+	TemplateID string    `json:"template_id,omitempty" db:"template_id"`
+	Status     string    `json:"status" db:"status"` // pending|approved|rejected|skipped
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+}
+
+// ShortLink is a tracked redirect slug pointing at a promo target URL.
+type ShortLink struct {
+	Slug        string     `json:"slug" db:"slug"`
+	TargetURL   string     `json:"target_url" db:"target_url"`
+	CampaignID  string     `json:"campaign_id,omitempty" db:"campaign_id"`
+	ShortURL    string     `json:"short_url,omitempty" db:"-"`
+	Clicks      int64      `json:"clicks" db:"clicks"`
+	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty" db:"expires_at"`
+	LastClickAt *time.Time `json:"last_click_at,omitempty" db:"last_click_at"`
+}
+
+// Expired reports whether the link has passed its expiry at the given time.
+func (l ShortLink) Expired(now time.Time) bool {
+	return l.ExpiresAt != nil && !now.Before(*l.ExpiresAt)
+}
+
+// Outbox item statuses.
+const (
+	OutboxPending  = "pending"
+	OutboxClaimed  = "claimed"
+	OutboxSent     = "sent"
+	OutboxFailed   = "failed"
+	OutboxCanceled = "canceled"
+)
+
+// Asal item outbox. Hanya kiriman eksplisit (scheduled, recurring) yang dikirim di luar jendela;
+// pilihan scheduler yang tertunda tetap tunduk pada jendela, limit harian dan pacing akun.
+const (
+	OutboxOriginScheduler = "scheduler"
+	OutboxOriginScheduled = "scheduled"
+	OutboxOriginRecurring = "recurring"
+)
+
+// OutboxItem is one planned send (account -> group) waiting in the persistent queue.
+// Higher Priority is drained first among items that are due.
+type OutboxItem struct {
+	ID         int64  `json:"id" db:"id"`
+	AccountID  string `json:"account_id" db:"account_id"`
+	GroupID    string `json:"group_id" db:"group_id"`
+	CampaignID string `json:"campaign_id,omitempty" db:"campaign_id"`
+	TemplateID string `json:"template_id,omitempty" db:"template_id"`
+	// Content konten kustom (JSON sender.MessageContent) untuk kiriman terjadwal sekali jalan;
+	// kosong = campaign, template, atau template acak.
+	Content     json.RawMessage `json:"content,omitempty" db:"content"`
+	Priority    int             `json:"priority" db:"priority"`
+	Status      string          `json:"status" db:"status"`
+	Origin      string          `json:"origin" db:"origin"` // scheduler (default), scheduled atau recurring
+	ScheduledAt time.Time       `json:"scheduled_at" db:"scheduled_at"`
+	ClaimedAt   *time.Time      `json:"claimed_at,omitempty" db:"claimed_at"`
+	SentAt      *time.Time      `json:"sent_at,omitempty" db:"sent_at"`
+	Attempts    int             `json:"attempts" db:"attempts"`
+	LastError   string          `json:"last_error,omitempty" db:"last_error"`
+	CreatedAt   time.Time       `json:"created_at" db:"created_at"`
+}
+
+// OutboxFilter narrows queue listings; empty fields match everything.
+type OutboxFilter struct {
+	Statuses   []string
+	AccountID  string
+	GroupID    string
+	CampaignID string
+	Limit      int
+	Offset     int
+}
+
+// Compliance rule actions: flag = tandai saja; pause = tandai dan nonaktifkan grup.
+const (
+	ComplianceFlag  = "flag"
+	CompliancePause = "pause"
+)
+
+// ComplianceRule is a phrase that, when found in a group description, marks the group
+// as forbidding promos.
+type ComplianceRule struct {
+	ID        int64     `json:"id" db:"id"`
+	Phrase    string    `json:"phrase" db:"phrase"`
+	Action    string    `json:"action" db:"action"`
+	Enabled   bool      `json:"enabled" db:"enabled"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+// FlaggedGroup is a group whose description matched a compliance rule.
+type FlaggedGroup struct {
+	GroupID     string     `json:"group_id"`
+	AccountID   string     `json:"account_id"`
+	Name        string     `json:"name"`
+	Enabled     bool       `json:"enabled"`
+	Phrase      string     `json:"phrase"`
+	Description string     `json:"description"`
+	CheckedAt   *time.Time `json:"checked_at,omitempty"`
+}
+
+// Pool groups accounts that share groups. With Rotate on, consecutive promos to a shared
+// group alternate between member accounts instead of always using the group's owner.
+type Pool struct {
+	ID         string    `json:"id" db:"id"`
+	Name       string    `json:"name" db:"name"`
+	Rotate     bool      `json:"rotate" db:"rotate"`
+	AccountIDs []string  `json:"account_ids" db:"-"`
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+}
+
+// Digest adalah rekap harian per akun (hari dihitung dalam WIB).
+type Digest struct {
+	ID          int64     `json:"id"`
+	AccountID   string    `json:"account_id"`
+	Label       string    `json:"label,omitempty"`
+	Day         string    `json:"day"` // YYYY-MM-DD
+	Sent        int64     `json:"sent"`
+	Failed      int64     `json:"failed"`
+	SoftBounced int64     `json:"soft_bounced"`
+	DailyLimit  int       `json:"daily_limit"`
+	Utilization float64   `json:"utilization"` // sent / daily_limit
+	Incidents   int64     `json:"incidents"`   // logged_out / replaced
+	Joins       int64     `json:"joins"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// CronTask adalah status satu task housekeeping terjadwal.
+type CronTask struct {
+	Name           string     `json:"name"`
+	Every          string     `json:"every"`
+	Running        bool       `json:"running"`
+	LastStartedAt  *time.Time `json:"last_started_at,omitempty"`
+	LastFinishedAt *time.Time `json:"last_finished_at,omitempty"`
+	LastStatus     string     `json:"last_status,omitempty"` // ok|error
+	LastMessage    string     `json:"last_message,omitempty"`
+	LastError      string     `json:"last_error,omitempty"`
+	LastDurationMs int64      `json:"last_duration_ms"`
+	Runs           int64      `json:"runs"`
+	NextRunAt      *time.Time `json:"next_run_at,omitempty"`
+}
+
+// Role pengguna/API key: operator boleh melihat dan mengirim, admin boleh semuanya.
+const (
+	RoleAdmin    = "admin"
+	RoleOperator = "operator"
+)
+
+// ValidRole melaporkan apakah r role yang dikenal.
+func ValidRole(r string) bool { return r == RoleAdmin || r == RoleOperator }
+
+// User adalah pengguna dashboard/API.
+type User struct {
+	ID          string     `json:"id"`
+	Username    string     `json:"username"`
+	Role        string     `json:"role"`
+	Disabled    bool       `json:"disabled"`
+	CreatedAt   time.Time  `json:"created_at"`
+	LastLoginAt *time.Time `json:"last_login_at,omitempty"`
+}
+
+// APIKey adalah metadata API key; nilai key hanya ditampilkan sekali saat dibuat.
+type APIKey struct {
+	ID         string     `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Role       string     `json:"role"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+}
+
+// PairingLink link pairing swalayan: URL berbatas waktu yang hanya membuka alur QR pairing satu
+// akun, untuk klien jarak jauh yang memasangkan nomornya sendiri. Token mentah tidak disimpan.
+type PairingLink struct {
+	ID        string     `json:"id"`
+	AccountID string     `json:"account_id"`
+	CreatedBy string     `json:"created_by,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	PairedAt  *time.Time `json:"paired_at,omitempty"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+}
+
+// DM removal reasons dipakai preflight audiens DM.
+const (
+	DMRemovedInvalid       = "invalid"
+	DMRemovedSuppressed    = "suppressed"
+	DMRemovedPriorFailed   = "prior_failed"
+	DMRemovedNotOnWhatsApp = "not_on_whatsapp"
+)
+
+// Asal supresi nomor.
+const (
+	SuppressionManual    = "manual"
+	SuppressionStopReply = "stop_reply"
+)
+
+// DMSuppression adalah nomor yang tidak boleh dihubungi (DM maupun mention).
+type DMSuppression struct {
+	Number    string    `json:"number"`
+	Reason    string    `json:"reason,omitempty"`
+	Source    string    `json:"source"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// GroupSuppression adalah grup yang tidak boleh dikirimi promo sama sekali.
+type GroupSuppression struct {
+	GroupID   string    `json:"group_id"`
+	Reason    string    `json:"reason,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// DMRun adalah snapshot audiens DM campaign yang sudah dibersihkan oleh preflight.
+type DMRun struct {
+	ID         string              `json:"id"`
+	CampaignID string              `json:"campaign_id"`
+	AccountID  string              `json:"account_id"`
+	InputCount int                 `json:"input_count"`
+	Audience   []string            `json:"audience"`
+	Removed    map[string][]string `json:"removed"`
+	CreatedAt  time.Time           `json:"created_at"`
+}
+
+// Status DM follow-up opt-in balasan kata kunci.
+const (
+	OptInPending    = "pending"
+	OptInSent       = "sent"
+	OptInFailed     = "failed"
+	OptInSuppressed = "suppressed"
+	OptInSkipped    = "skipped"
+)
+
+// ReplyFlow adalah alur opt-in "balas KATA untuk dapat kode" per campaign: balasan grup yang
+// memuat Keyword dalam WindowMin menit setelah campaign terkirim ke grup itu dicatat sebagai
+// opt-in, lalu pengirimnya di-DM template TemplateID (maks MaxPerHour DM per akun per jam).
+type ReplyFlow struct {
+	CampaignID string    `json:"campaign_id"`
+	Keyword    string    `json:"keyword"`
+	TemplateID string    `json:"template_id"`
+	WindowMin  int       `json:"window_min"`
+	MaxPerHour int       `json:"max_per_hour"`
+	Enabled    bool      `json:"enabled"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// Matches true jika teks balasan memuat keyword sebagai kata utuh (tanpa beda huruf besar/kecil).
+func (f ReplyFlow) Matches(text string) bool {
+	kw := strings.ToLower(strings.TrimSpace(f.Keyword))
+	if kw == "" {
+		return false
+	}
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for _, w := range words {
+		if w == kw {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate memeriksa keyword (satu kata huruf/angka, maks 32 karakter), template, dan batas.
+func (f ReplyFlow) Validate() error {
+	kw := strings.TrimSpace(f.Keyword)
+	switch {
+	case kw == "":
+		return errors.New("keyword required")
+	case len(kw) > 32:
+		return errors.New("keyword too long (max 32)")
+	case strings.IndexFunc(kw, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) }) >= 0:
+		return errors.New("keyword must be a single word of letters/digits")
+	case f.TemplateID == "":
+		return errors.New("template_id required")
+	case f.WindowMin < 1 || f.WindowMin > 7*24*60:
+		return errors.New("window_min must be between 1 and 10080")
+	case f.MaxPerHour < 1 || f.MaxPerHour > 200:
+		return errors.New("max_per_hour must be between 1 and 200")
+	}
+	return nil
+}
+
+// ReplyOptIn adalah satu anggota grup yang membalas keyword campaign.
+type ReplyOptIn struct {
+	ID         int64      `json:"id"`
+	CampaignID string     `json:"campaign_id"`
+	AccountID  string     `json:"account_id"`
+	GroupID    string     `json:"group_id"`
+	Number     string     `json:"number"`
+	Reply      string     `json:"reply,omitempty"`
+	DMStatus   string     `json:"dm_status"`
+	DMError    string     `json:"dm_error,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	DMAt       *time.Time `json:"dm_at,omitempty"`
+}
+
+// Status target DM campaign.
+const (
+	DMTargetPending = "pending"
+	DMTargetSent    = "sent"
+	DMTargetFailed  = "failed"
+	DMTargetSkipped = "skipped"
+)
+
+// DMTarget adalah satu nomor tujuan campaign mode dm, diambil dari snapshot preflight (RunID).
+type DMTarget struct {
+	ID         int64      `json:"id"`
+	CampaignID string     `json:"campaign_id"`
+	AccountID  string     `json:"account_id"`
+	Number     string     `json:"number"`
+	RunID      string     `json:"run_id,omitempty"`
+	Status     string     `json:"status"`
+	Error      string     `json:"error,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	SentAt     *time.Time `json:"sent_at,omitempty"`
+}
+
+// FetchPolicy membatasi unduhan media remote oleh sender: daftar host izin/tolak, alamat
+// privat (SSRF), ukuran maksimum, jumlah redirect dan unduhan paralel per host.
+type FetchPolicy struct {
+	// AllowHosts jika diisi hanya host ini (dan subdomainnya) yang boleh diunduh.
+	AllowHosts []string `json:"allow_hosts"`
+	// DenyHosts selalu ditolak (termasuk subdomain), dicek sebelum AllowHosts.
+	DenyHosts []string `json:"deny_hosts"`
+	// AllowPrivate mengizinkan alamat loopback/privat/link-local (default ditolak).
+	AllowPrivate       bool  `json:"allow_private"`
+	MaxBytes           int64 `json:"max_bytes"`
+	MaxRedirects       int   `json:"max_redirects"`
+	PerHostConcurrency int   `json:"per_host_concurrency"`
+}
+
+// DefaultFetchPolicy: 100 MB (batas dokumen WhatsApp), 5 redirect, 4 unduhan paralel per host.
+func DefaultFetchPolicy() FetchPolicy {
+	return FetchPolicy{AllowHosts: []string{}, DenyHosts: []string{}, MaxBytes: 100 << 20, MaxRedirects: 5, PerHostConcurrency: 4}
+}
+
+// Normalize merapikan daftar host (huruf kecil, tanpa skema/port/path, tanpa duplikat).
+func (p *FetchPolicy) Normalize() {
+	clean := func(list []string) []string {
+		out, seen := []string{}, map[string]bool{}
+		for _, h := range list {
+			h = strings.ToLower(strings.TrimSpace(h))
+			if i := strings.Index(h, "://"); i >= 0 {
+				h = h[i+3:]
+			}
+			if i := strings.IndexAny(h, "/?#"); i >= 0 {
+				h = h[:i]
+			}
+			if host, _, err := net.SplitHostPort(h); err == nil {
+				h = host
+			}
+			h = strings.TrimPrefix(strings.Trim(h, "[]."), "*.")
+			if h != "" && !seen[h] {
+				seen[h] = true
+				out = append(out, h)
+			}
+		}
+		return out
+	}
+	p.AllowHosts = clean(p.AllowHosts)
+	p.DenyHosts = clean(p.DenyHosts)
+}
+
+// Validate memeriksa batas numerik policy.
+func (p FetchPolicy) Validate() error {
+	switch {
+	case p.MaxBytes < 1<<20 || p.MaxBytes > 2<<30:
+		return errors.New("max_bytes must be between 1 MB and 2 GB")
+	case p.MaxRedirects < 0 || p.MaxRedirects > 20:
+		return errors.New("max_redirects must be between 0 and 20")
+	case p.PerHostConcurrency < 1 || p.PerHostConcurrency > 64:
+		return errors.New("per_host_concurrency must be between 1 and 64")
+	}
+	return nil
+}
+
+// HostAllowed true jika host lolos DenyHosts dan (bila diisi) AllowHosts.
+func (p FetchPolicy) HostAllowed(host string) bool {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	match := func(list []string) bool {
+		for _, h := range list {
+			if host == h || strings.HasSuffix(host, "."+h) {
+				return true
+			}
+		}
+		return false
+	}
+	if match(p.DenyHosts) {
+		return false
+	}
+	return len(p.AllowHosts) == 0 || match(p.AllowHosts)
+}
+
+// TelegramMirror konfigurasi mirror promo sukses ke channel Telegram (feed read-only klien).
+type TelegramMirror struct {
+	Enabled bool `json:"enabled"`
+	// BotToken token bot dari @BotFather; tidak pernah dikembalikan API (lihat HasToken).
+	BotToken string `json:"bot_token,omitempty"`
+	HasToken bool   `json:"has_token"`
+	// ChatID channel tujuan, mis. "@channel" atau "-100...".
+	ChatID string `json:"chat_id"`
+}
+
+// Validate memastikan mirror yang aktif punya token dan chat tujuan.
+func (m TelegramMirror) Validate() error {
+	if m.Enabled && (m.BotToken == "" || m.ChatID == "") {
+		return errors.New("bot_token and chat_id are required when enabled")
+	}
+	return nil
+}
+
+// Sumber anggota audiens.
+const (
+	AudienceSourceManual = "manual"
+	AudienceSourceCSV    = "csv"
+	AudienceSourceGroup  = "group"
+	// AudienceSourceSegment anggota hasil refresh segmen; diganti seluruhnya tiap refresh.
+	AudienceSourceSegment = "segment"
+)
+
+// Audience adalah daftar kontak bernama yang bisa dijadikan sumber target campaign DM.
+type Audience struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	MemberCount int    `json:"member_count"`
+	// Segment aturan segmen dari cache anggota grup; nil = audiens statis.
+	Segment     *SegmentRule `json:"segment,omitempty"`
+	RefreshedAt *time.Time   `json:"refreshed_at,omitempty"`
+	CreatedAt   time.Time    `json:"created_at"`
+	UpdatedAt   time.Time    `json:"updated_at"`
+}
+
+// Peran anggota grup untuk SegmentRule.Role.
+const (
+	SegmentRoleAdmin      = "admin" // admin atau superadmin
+	SegmentRoleSuperAdmin = "superadmin"
+	SegmentRoleMember     = "member" // bukan admin
+)
+
+// SegmentRule memilih nomor dari cache anggota grup (group_participants), mis. "admin grup
+// bertag reseller" atau "nomor +62 yang ada di >= 3 grup". Semua kriteria yang diisi harus
+// terpenuhi; nomor akun sendiri tidak pernah ikut.
+type SegmentRule struct {
+	// Tags: hanya grup dengan minimal satu tag ini (kosong = semua grup).
+	Tags []string `json:"tags,omitempty"`
+	// AccountID: hanya grup milik akun ini.
+	AccountID string `json:"account_id,omitempty"`
+	Role      string `json:"role,omitempty"`
+	// Prefix awalan nomor setelah normalisasi (mis. "62").
+	Prefix string `json:"prefix,omitempty"`
+	// MinGroups nomor harus terlihat di minimal sekian grup yang cocok (default 1).
+	MinGroups int `json:"min_groups,omitempty"`
+}
+
+// Validate memeriksa nilai kriteria segmen.
+func (r SegmentRule) Validate() error {
+	switch r.Role {
+	case "", SegmentRoleAdmin, SegmentRoleSuperAdmin, SegmentRoleMember:
+	default:
+		return errors.New("role must be admin, superadmin or member")
+	}
+	for _, c := range strings.TrimPrefix(r.Prefix, "+") {
+		if c < '0' || c > '9' {
+			return errors.New("prefix must be digits")
+		}
+	}
+	if r.MinGroups < 0 {
+		return errors.New("min_groups must be >= 0")
+	}
+	return nil
+}
+
+// AudienceMember adalah satu nomor di audiens.
+type AudienceMember struct {
+	Number  string    `json:"number"`
+	Name    string    `json:"name,omitempty"`
+	Source  string    `json:"source"`
+	AddedAt time.Time `json:"added_at"`
+}
+
+// Targeting adalah filter grup per campaign yang dievaluasi saat scheduler memilih grup.
+// Semua kriteria yang diisi harus terpenuhi (AND); field kosong diabaikan.
+type Targeting struct {
+	// IncludeTags: grup harus punya minimal satu tag ini.
+	IncludeTags []string `json:"include_tags,omitempty"`
+	// ExcludeTags: grup tidak boleh punya tag ini.
+	ExcludeTags     []string `json:"exclude_tags,omitempty"`
+	MinParticipants int      `json:"min_participants,omitempty"`
+	// RiskBelow: risk_score grup harus < nilai ini.
+	RiskBelow *int `json:"risk_below,omitempty"`
+	// JoinedBefore (YYYY-MM-DD): grup sudah tercatat sebelum tanggal ini.
+	JoinedBefore string   `json:"joined_before,omitempty"`
+	Languages    []string `json:"languages,omitempty"`
+}
+
+// Empty true jika tidak ada kriteria sama sekali.
+func (t Targeting) Empty() bool {
+	return len(t.IncludeTags) == 0 && len(t.ExcludeTags) == 0 && t.MinParticipants == 0 &&
+		t.RiskBelow == nil && t.JoinedBefore == "" && len(t.Languages) == 0
+}
+
+// Validate memeriksa nilai kriteria.
+func (t Targeting) Validate() error {
+	if t.MinParticipants < 0 {
+		return errors.New("min_participants must be >= 0")
+	}
+	if t.JoinedBefore != "" {
+		if _, err := time.Parse("2006-01-02", t.JoinedBefore); err != nil {
+			return errors.New("joined_before must be YYYY-MM-DD")
+		}
+	}
+	return nil
+}
+
+// DynamicVar adalah variabel template yang nilainya diambil dari endpoint HTTP saat kirim.
+// Placeholder {name:key} memanggil URLTemplate dengan "{key}" diganti key (URL-escaped).
+type DynamicVar struct {
+	Name        string `json:"name"`
+	URLTemplate string `json:"url_template"`
+	// JSONPath (mis. "data.stock") mengambil nilai dari respons JSON; kosong = body mentah.
+	JSONPath    string `json:"json_path,omitempty"`
+	TimeoutMs   int    `json:"timeout_ms"`
+	CacheTTLSec int    `json:"cache_ttl_sec"`
+	// Fallback dipakai jika lookup gagal dan tidak ada nilai cache.
+	Fallback  string    `json:"fallback,omitempty"`
+	Enabled   bool      `json:"enabled"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Webhook adalah target URL yang menerima POST JSON untuk event tertentu.
+type Webhook struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	URL  string `json:"url"`
+	// Events yang dikirim (mis. "send.failed"); kosong = semua event.
+	Events []string `json:"events"`
+	// Secret untuk header X-Webhook-Signature (HMAC-SHA256 body); tidak pernah dikembalikan API.
+	Secret    string    `json:"-"`
+	HasSecret bool      `json:"has_secret"`
+	Enabled   bool      `json:"enabled"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Wants true jika webhook berlangganan event.
+func (w Webhook) Wants(event string) bool {
+	if len(w.Events) == 0 {
+		return true
+	}
+	for _, e := range w.Events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// Status pengiriman webhook.
+const (
+	DeliveryPending   = "pending"
+	DeliveryDelivered = "delivered"
+	DeliveryFailed    = "failed"
+)
+
+// WebhookDelivery adalah satu upaya kirim event ke webhook (beserta riwayat retry-nya).
+type WebhookDelivery struct {
+	ID            int64           `json:"id"`
+	WebhookID     string          `json:"webhook_id"`
+	Event         string          `json:"event"`
+	Payload       json.RawMessage `json:"payload"`
+	Status        string          `json:"status"`
+	Attempts      int             `json:"attempts"`
+	ResponseCode  int             `json:"response_code,omitempty"`
+	LastError     string          `json:"last_error,omitempty"`
+	NextAttemptAt time.Time       `json:"next_attempt_at"`
+	CreatedAt     time.Time       `json:"created_at"`
+	DeliveredAt   *time.Time      `json:"delivered_at,omitempty"`
+}
+
+// ErrorBudget batas rasio kirim gagal per akun dalam jendela bergulir.
+type ErrorBudget struct {
+	// MaxFailPct persentase gagal maksimum (mis. 5 = 5%); 0 = fitur nonaktif.
+	MaxFailPct  float64 `json:"max_fail_pct"`
+	WindowHours int     `json:"window_hours"`
+	// MinSends jumlah kiriman minimum di jendela sebelum budget dievaluasi.
+	MinSends int `json:"min_sends"`
+}
+
+// Validate memeriksa rentang nilai budget.
+func (b ErrorBudget) Validate() error {
+	if b.MaxFailPct < 0 || b.MaxFailPct > 100 {
+		return errors.New("max_fail_pct must be between 0 and 100")
+	}
+	if b.WindowHours < 1 || b.WindowHours > 24*7 {
+		return errors.New("window_hours must be between 1 and 168")
+	}
+	if b.MinSends < 1 {
+		return errors.New("min_sends must be >= 1")
+	}
+	return nil
+}
+
+// Exceeded true jika rasio gagal melewati budget (dengan jumlah kiriman minimum terpenuhi).
+func (b ErrorBudget) Exceeded(sent, failed int) bool {
+	total := sent + failed
+	return b.MaxFailPct > 0 && total >= b.MinSends && float64(failed)*100 > b.MaxFailPct*float64(total)
+}
+
+// SchedulerPause status jeda global scheduler: selama Paused tidak ada kiriman terjadwal
+// (grup, jadwal campaign, DM, channel, status) tanpa menghentikan proses.
+type SchedulerPause struct {
+	Paused   bool       `json:"paused"`
+	Reason   string     `json:"reason,omitempty"`
+	By       string     `json:"by,omitempty"`
+	PausedAt *time.Time `json:"paused_at,omitempty"`
+}
+
+// SchedulerTiming pengaturan ritme loop scheduler.
+type SchedulerTiming struct {
+	// TickSec interval cek scheduler selama ada pekerjaan (detik).
+	TickSec int `json:"tick_sec"`
+	// MinWindowMin jendela kirim yang lebih pendek dari ini (menit) diabaikan; 0 = semua dipakai.
+	MinWindowMin int `json:"min_window_min"`
+	// IdleSleep di luar jendela (tanpa jadwal/DM yang perlu jalan) scheduler tidur sampai jendela
+	// berikutnya, paling lama MaxIdleMin, alih-alih tick biasa.
+	IdleSleep  bool `json:"idle_sleep"`
+	MaxIdleMin int  `json:"max_idle_min"`
+	// Pacing strategi jarak antar kiriman grup per akun: even (sisa kuota disebar merata atas
+	// sisa waktu jendela) atau fast (secepat jeda antar grup mengizinkan).
+	Pacing string `json:"pacing"`
+}
+
+// Strategi pacing kiriman grup.
+const (
+	PacingEven = "even"
+	PacingFast = "fast"
+)
+
+// Validate memeriksa rentang nilai timing.
+func (t SchedulerTiming) Validate() error {
+	if t.TickSec < 5 || t.TickSec > 600 {
+		return errors.New("tick_sec must be between 5 and 600")
+	}
+	if t.MinWindowMin < 0 || t.MinWindowMin > 240 {
+		return errors.New("min_window_min must be between 0 and 240")
+	}
+	if t.MaxIdleMin < 1 || t.MaxIdleMin > 24*60 {
+		return errors.New("max_idle_min must be between 1 and 1440")
+	}
+	if t.Pacing != PacingEven && t.Pacing != PacingFast {
+		return fmt.Errorf("invalid pacing %q (want even or fast)", t.Pacing)
+	}
+	return nil
+}
+
+// Actionable salinan w tanpa jendela yang lebih pendek dari minMinutes.
+func (w WeekWindows) Actionable(minMinutes int) WeekWindows {
+	if minMinutes <= 0 {
+		return w
+	}
+	var out WeekWindows
+	for d, wins := range w {
+		out[d] = [][2]int{}
+		for _, win := range wins {
+			if win[1]-win[0] >= minMinutes {
+				out[d] = append(out[d], win)
+			}
+		}
+	}
+	return out
+}
+
+// Strategi rotasi akun pengirim.
+const (
+	RotationRandom     = "random"
+	RotationRoundRobin = "round_robin"
+	RotationLRU        = "lru"
+	RotationWeighted   = "weighted"
+)
+
+// AccountRotation strategi urutan akun yang mendapat giliran kirim, juga pemilihan pengirim
+// di antara akun pool yang sama-sama anggota grup target.
+type AccountRotation struct {
+	// Strategy random (urutan akun acak; pengirim pool = paling lama tidak kirim ke grup),
+	// round_robin (bergiliran menurut ID akun), lru (akun yang paling lama tidak mengirim dulu)
+	// atau weighted (acak berbobot sisa kuota harian).
+	Strategy string `json:"strategy"`
+}
+
+// Validate memeriksa nama strategi.
+func (r AccountRotation) Validate() error {
+	switch r.Strategy {
+	case RotationRandom, RotationRoundRobin, RotationLRU, RotationWeighted:
+		return nil
+	}
+	return fmt.Errorf("invalid strategy %q (want random, round_robin, lru or weighted)", r.Strategy)
+}
+
+// Mode pembagian limit harian akun ke jendela kirim.
+const (
+	WindowBudgetOff          = "off"
+	WindowBudgetProportional = "proportional"
+	WindowBudgetWeighted     = "weighted"
+)
+
+// WindowBudget membagi limit harian akun ke jendela kirim hari itu agar kiriman tidak habis di
+// jendela pertama. Porsi bersifat kumulatif: sisa jendela sebelumnya terbawa ke jendela berikutnya.
+type WindowBudget struct {
+	// Mode off (tanpa pembagian), proportional (sesuai durasi jendela) atau weighted (Weights).
+	Mode string `json:"mode"`
+	// Weights bobot per jendela, urut jam mulai dalam hari kuota; jendela tanpa bobot = 1.
+	Weights []int `json:"weights,omitempty"`
+}
+
+// Validate memeriksa mode dan bobot.
+func (b WindowBudget) Validate() error {
+	switch b.Mode {
+	case WindowBudgetOff, WindowBudgetProportional:
+	case WindowBudgetWeighted:
+		if len(b.Weights) == 0 {
+			return errors.New("weights required for weighted mode")
+		}
+		for _, w := range b.Weights {
+			if w < 0 || w > 1000 {
+				return errors.New("weights must be between 0 and 1000")
+			}
+		}
+	default:
+		return fmt.Errorf("invalid mode %q (want off, proportional or weighted)", b.Mode)
+	}
+	return nil
+}
+
+// Shares porsi (total 1) tiap jendela wins, diurutkan menurut jam mulai sejak resetHour (jam
+// pergantian hari kuota). Nil jika mode off atau tidak ada jendela/bobot.
+func (b WindowBudget) Shares(wins [][2]int, resetHour int) ([][2]int, []float64) {
+	if b.Mode == WindowBudgetOff || b.Mode == "" || len(wins) == 0 {
+		return nil, nil
+	}
+	ordered := append([][2]int{}, wins...)
+	sort.Slice(ordered, func(i, j int) bool {
+		return dayOffset(ordered[i][0], resetHour) < dayOffset(ordered[j][0], resetHour)
+	})
+	shares := make([]float64, len(ordered))
+	total := 0.0
+	for i, win := range ordered {
+		w := float64(win[1] - win[0])
+		if b.Mode == WindowBudgetWeighted {
+			w = 1
+			if i < len(b.Weights) {
+				w = float64(b.Weights[i])
+			}
+		}
+		shares[i] = w
+		total += w
+	}
+	if total <= 0 {
+		return nil, nil
+	}
+	for i := range shares {
+		shares[i] /= total
+	}
+	return ordered, shares
+}
+
+// Cap jumlah kiriman kumulatif yang boleh sudah terjadi hari ini pada t: limit dikali porsi
+// jendela yang sudah dimulai (termasuk jendela berjalan), dibulatkan ke atas. limit jika
+// pembagian tidak berlaku atau belum ada jendela yang dimulai.
+func (b WindowBudget) Cap(wins [][2]int, t time.Time, resetHour, limit int) int {
+	ordered, shares := b.Shares(wins, resetHour)
+	if shares == nil {
+		return limit
+	}
+	now := dayOffset(t.Hour()*60+t.Minute(), resetHour)
+	cum, started := 0.0, false
+	for i, win := range ordered {
+		if dayOffset(win[0], resetHour) > now {
+			break
+		}
+		cum += shares[i]
+		started = true
+	}
+	if !started {
+		return limit
+	}
+	n := int(math.Ceil(cum*float64(limit) - 1e-9))
+	if n > limit {
+		n = limit
+	}
+	return n
+}
+
+// dayOffset menit m dihitung sejak jam pergantian hari kuota.
+func dayOffset(m, resetHour int) int {
+	return (m - resetHour*60 + 1440) % 1440
+}
+
+// AccountBudget status error budget satu akun.
+type AccountBudget struct {
+	AccountID     string     `json:"account_id"`
+	Label         string     `json:"label"`
+	Sent          int        `json:"sent"`
+	Failed        int        `json:"failed"`
+	FailPct       float64    `json:"fail_pct"`
+	PausedAt      *time.Time `json:"paused_at,omitempty"`
+	PauseReason   string     `json:"pause_reason,omitempty"`
+	OverrideUntil *time.Time `json:"override_until,omitempty"`
+}
+
+// LatencySummary ringkasan persentil durasi (milidetik) untuk laporan SLO.
+type LatencySummary struct {
+	Count int   `json:"count"`
+	P50   int64 `json:"p50_ms"`
+	P90   int64 `json:"p90_ms"`
+	P95   int64 `json:"p95_ms"`
+	P99   int64 `json:"p99_ms"`
+	Max   int64 `json:"max_ms"`
+}
+
+// Summarize menghitung persentil (nearest-rank) dari sampel milidetik; sampel diurutkan in-place.
+func Summarize(samples []int64) LatencySummary {
+	s := LatencySummary{Count: len(samples)}
+	if len(samples) == 0 {
+		return s
+	}
+	sort.Slice(samples, func(i, j int) bool { return samples[i] < samples[j] })
+	rank := func(p float64) int64 {
+		i := int(math.Ceil(p/100*float64(len(samples)))) - 1
+		if i < 0 {
+			i = 0
+		}
+		return samples[i]
+	}
+	s.P50, s.P90, s.P95, s.P99 = rank(50), rank(90), rank(95), rank(99)
+	s.Max = samples[len(samples)-1]
+	return s
+}
+
+// AccountUptime porsi waktu akun berstatus online dalam jendela yang teramati
+// (dihitung dari account_events).
+type AccountUptime struct {
+	AccountID   string  `json:"account_id"`
+	Label       string  `json:"label"`
+	OnlineSec   int64   `json:"online_sec"`
+	ObservedSec int64   `json:"observed_sec"`
+	UptimePct   float64 `json:"uptime_pct"`
+	Status      string  `json:"status"` // status terakhir yang tercatat
+}
+
+// RampDay jumlah grup baru yang dijadwalkan menerima kiriman pertama pada satu hari.
+type RampDay struct {
+	Date   string `json:"date"` // YYYY-MM-DD (zona hari kuota)
+	Groups int    `json:"groups"`
+}
+
+// AccountRamp rencana cold-start satu akun: grup yang belum pernah dikirimi disebar per hari.
+type AccountRamp struct {
+	AccountID string `json:"account_id"`
+	Label     string `json:"label"`
+	// Due grup cold yang sudah boleh dikirimi (tanggal rencananya hari ini atau lewat).
+	Due int `json:"due"`
+	// Unplanned grup cold yang belum mendapat tanggal (planner belum berjalan).
+	Unplanned int       `json:"unplanned"`
+	Days      []RampDay `json:"days"`
+	LastDate  string    `json:"last_date,omitempty"`
+}
+
+// Verdict masa uji grup.
+const (
+	TrialPromoted = "promoted"
+	TrialDisabled = "disabled"
+)
+
+// GroupTrialSettings masa uji grup baru: N kiriman pertama ke grup yang baru diaktifkan dipantau
+// (gagal, dikeluarkan, balasan), lalu grup otomatis dinyatakan lolos atau dinonaktifkan.
+type GroupTrialSettings struct {
+	Enabled bool `json:"enabled"`
+	// Sends jumlah kiriman (sent+failed) sebelum grup dinilai.
+	Sends int `json:"sends"`
+	// MaxFailRate rasio gagal maksimum (0–1) agar grup lolos.
+	MaxFailRate float64 `json:"max_fail_rate"`
+	// MinReplyRate balasan per kiriman sukses minimum (0 = tidak dicek).
+	MinReplyRate float64 `json:"min_reply_rate"`
+}
+
+// DefaultGroupTrialSettings masa uji nonaktif; 5 kiriman, gagal maksimal 40%.
+func DefaultGroupTrialSettings() GroupTrialSettings {
+	return GroupTrialSettings{Sends: 5, MaxFailRate: 0.4}
+}
+
+// Validate memeriksa batas pengaturan masa uji.
+func (t GroupTrialSettings) Validate() error {
+	if t.Sends < 1 || t.Sends > 100 {
+		return errors.New("sends must be between 1 and 100")
+	}
+	if t.MaxFailRate < 0 || t.MaxFailRate > 1 {
+		return errors.New("max_fail_rate must be between 0 and 1")
+	}
+	if t.MinReplyRate < 0 || t.MinReplyRate > 10 {
+		return errors.New("min_reply_rate must be between 0 and 10")
+	}
+	return nil
+}
+
+// Evaluate memberi verdict masa uji beserta alasannya; verdict kosong = masa uji masih berjalan.
+// Grup yang mengeluarkan akun langsung dinonaktifkan tanpa menunggu N kiriman.
+func (t GroupTrialSettings) Evaluate(g GroupTrial) (verdict, reason string) {
+	if g.Kicked {
+		return TrialDisabled, "account removed from group"
+	}
+	if g.Sends < t.Sends {
+		return "", ""
+	}
+	if rate := float64(g.Failures) / float64(g.Sends); rate > t.MaxFailRate {
+		return TrialDisabled, fmt.Sprintf("fail rate %.0f%% over %d sends (max %.0f%%)", rate*100, g.Sends, t.MaxFailRate*100)
+	}
+	if t.MinReplyRate > 0 {
+		ok := g.Sends - g.Failures
+		if rate := float64(g.Replies) / float64(max(ok, 1)); rate < t.MinReplyRate {
+			return TrialDisabled, fmt.Sprintf("reply rate %.2f per send (min %.2f)", rate, t.MinReplyRate)
+		}
+	}
+	return TrialPromoted, fmt.Sprintf("%d/%d sends ok, %d replies", g.Sends-g.Failures, g.Sends, g.Replies)
+}
+
+// GroupTrial masa uji satu grup. Sends/Failures dihitung dari log sejak StartedAt; Replies dan
+// Kicked dicatat dari event WhatsApp selama masa uji.
+type GroupTrial struct {
+	GroupID   string     `json:"group_id"`
+	AccountID string     `json:"account_id"`
+	GroupName string     `json:"group_name"`
+	StartedAt time.Time  `json:"started_at"`
+	Sends     int        `json:"sends"`
+	Failures  int        `json:"failures"`
+	Replies   int        `json:"replies"`
+	Kicked    bool       `json:"kicked"`
+	Verdict   string     `json:"verdict,omitempty"`
+	Reason    string     `json:"reason,omitempty"`
+	DecidedAt *time.Time `json:"decided_at,omitempty"`
+}
+
+// AutoLeavePolicy kebijakan keluar otomatis dari grup bernilai rendah. Setiap kriteria bernilai 0
+// dimatikan; grup uji, grup komunitas, dan grup milik sendiri (punya link undangan) tidak pernah
+// ditinggalkan.
+type AutoLeavePolicy struct {
+	Enabled bool `json:"enabled"`
+	// DryRun hanya mencatat grup yang akan ditinggalkan tanpa benar-benar keluar.
+	DryRun bool `json:"dry_run"`
+	// FailureStreak kiriman gagal beruntun ke grup sejak kiriman sukses terakhir.
+	FailureStreak int `json:"failure_streak"`
+	// MinMembers grup dengan anggota (yang diketahui) kurang dari ini.
+	MinMembers int `json:"min_members"`
+	// InactiveDays grup tanpa kiriman sukses selama N hari (dihitung sejak grup dikenal).
+	InactiveDays int `json:"inactive_days"`
+	// MaxPerRun batas grup yang ditinggalkan per akun per putaran.
+	MaxPerRun int `json:"max_per_run"`
+}
+
+// DefaultAutoLeavePolicy kebijakan nonaktif; 5 gagal beruntun, < 5 anggota, 60 hari tanpa kiriman.
+func DefaultAutoLeavePolicy() AutoLeavePolicy {
+	return AutoLeavePolicy{FailureStreak: 5, MinMembers: 5, InactiveDays: 60, MaxPerRun: 5}
+}
+
+// Validate memeriksa batas kebijakan auto-leave.
+func (p AutoLeavePolicy) Validate() error {
+	if p.FailureStreak < 0 || p.FailureStreak > 1000 {
+		return errors.New("failure_streak must be between 0 and 1000")
+	}
+	if p.MinMembers < 0 || p.MinMembers > 1024 {
+		return errors.New("min_members must be between 0 and 1024")
+	}
+	if p.InactiveDays < 0 || p.InactiveDays > 3650 {
+		return errors.New("inactive_days must be between 0 and 3650")
+	}
+	if p.MaxPerRun < 1 || p.MaxPerRun > 100 {
+		return errors.New("max_per_run must be between 1 and 100")
+	}
+	return nil
+}
+
+// Reason alasan grup ditinggalkan menurut kebijakan; kosong = grup dipertahankan.
+func (p AutoLeavePolicy) Reason(c AutoLeaveCandidate, now time.Time) string {
+	if p.FailureStreak > 0 && c.FailureStreak >= p.FailureStreak {
+		return fmt.Sprintf("%d failed sends in a row", c.FailureStreak)
+	}
+	if p.MinMembers > 0 && c.Participants > 0 && c.Participants < p.MinMembers {
+		return fmt.Sprintf("only %d members (min %d)", c.Participants, p.MinMembers)
+	}
+	if p.InactiveDays > 0 {
+		last := c.KnownSince
+		if c.LastSentAt != nil {
+			last = *c.LastSentAt
+		}
+		if days := int(now.Sub(last).Hours() / 24); days >= p.InactiveDays {
+			return fmt.Sprintf("no successful send for %d days", days)
+		}
+	}
+	return ""
+}
+
+// AutoLeaveCandidate data grup yang dinilai kebijakan auto-leave.
+type AutoLeaveCandidate struct {
+	GroupID       string     `json:"group_id"`
+	AccountID     string     `json:"account_id"`
+	GroupName     string     `json:"group_name"`
+	Participants  int        `json:"participants"`
+	FailureStreak int        `json:"failure_streak"`
+	LastSentAt    *time.Time `json:"last_sent_at,omitempty"`
+	KnownSince    time.Time  `json:"known_since"`
+	Reason        string     `json:"reason,omitempty"`
+}
+
+// Status catatan auto-leave.
+const (
+	AutoLeaveLeft   = "left"
+	AutoLeaveFailed = "failed"
+	AutoLeaveDryRun = "dry_run"
+)
+
+// AutoLeaveLog satu keputusan auto-leave.
+type AutoLeaveLog struct {
+	ID        int64     `json:"id"`
+	AccountID string    `json:"account_id"`
+	GroupID   string    `json:"group_id"`
+	GroupName string    `json:"group_name"`
+	Status    string    `json:"status"`
+	Reason    string    `json:"reason"`
+	Error     string    `json:"error,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// BusinessProfile identitas WhatsApp Business satu nomor.
+type BusinessProfile struct {
+	Category   string `json:"category"`
+	Address    string `json:"address"`
+	Email      string `json:"email"`
+	CatalogURL string `json:"catalog_url"`
+	About      string `json:"about"`
+}
+
+// Drift mengembalikan field yang diisi di profil terkelola (p) tapi berbeda dengan live.
+// Field kosong di p dianggap tidak dikelola.
+func (p BusinessProfile) Drift(live BusinessProfile) []string {
+	drift := []string{}
+	for _, f := range []struct {
+		name         string
+		want, actual string
+	}{
+		{"category", p.Category, live.Category},
+		{"address", p.Address, live.Address},
+		{"email", p.Email, live.Email},
+		{"catalog_url", p.CatalogURL, live.CatalogURL},
+		{"about", p.About, live.About},
+	} {
+		if f.want != "" && strings.TrimSpace(f.want) != strings.TrimSpace(f.actual) {
+			drift = append(drift, f.name)
+		}
+	}
+	return drift
+}
+
+// AccountBusinessProfile profil bisnis live (hasil sync dari WhatsApp) dan terkelola (diatur
+// terpusat lewat API) untuk satu akun.
+type AccountBusinessProfile struct {
+	AccountID  string          `json:"account_id"`
+	Label      string          `json:"label"`
+	IsBusiness bool            `json:"is_business"`
+	Live       BusinessProfile `json:"live"`
+	Managed    BusinessProfile `json:"managed"`
+	Drift      []string        `json:"drift"`
+	SyncedAt   *time.Time      `json:"synced_at,omitempty"`
+	SyncError  string          `json:"sync_error,omitempty"`
+}
+
+// Channel WhatsApp Channel (newsletter) yang dimiliki/dikelola akun (role owner atau admin).
+type Channel struct {
+	ID           string     `json:"id"` // JID newsletter (...@newsletter)
+	AccountID    string     `json:"account_id"`
+	Name         string     `json:"name"`
+	Role         string     `json:"role"`
+	Subscribers  int        `json:"subscribers"`
+	InviteCode   string     `json:"invite_code,omitempty"`
+	Enabled      bool       `json:"enabled"`
+	LastPostedAt *time.Time `json:"last_posted_at,omitempty"`
+	PostsToday   int        `json:"posts_today"`
+	SyncedAt     time.Time  `json:"synced_at"`
+}
+
+// ChannelPost satu posting (sukses/gagal) ke channel.
+type ChannelPost struct {
+	ID              int64     `json:"id"`
+	ChannelID       string    `json:"channel_id"`
+	AccountID       string    `json:"account_id"`
+	TemplateID      string    `json:"template_id,omitempty"`
+	TemplateVersion int       `json:"template_version,omitempty"`
+	Status          string    `json:"status"`
+	Error           string    `json:"error,omitempty"`
+	MessageID       string    `json:"message_id,omitempty"`
+	Preview         string    `json:"preview,omitempty"`
+	TS              time.Time `json:"ts"`
+}
+
+// StatusPost satu posting status (story) ke status@broadcast.
+type StatusPost struct {
+	ID              int64  `json:"id"`
+	AccountID       string `json:"account_id"`
+	TemplateID      string `json:"template_id,omitempty"`
+	TemplateVersion int    `json:"template_version,omitempty"`
+	// Kind text, image atau video.
+	Kind      string    `json:"kind"`
+	Status    string    `json:"status"`
+	Error     string    `json:"error,omitempty"`
+	MessageID string    `json:"message_id,omitempty"`
+	Preview   string    `json:"preview,omitempty"`
+	TS        time.Time `json:"ts"`
+}
+
+// ChannelRules batas posting scheduler ke channel, terpisah dari limit/cooldown grup.
+type ChannelRules struct {
+	// DailyLimit posting maksimum per channel per hari; 0 = posting otomatis nonaktif.
+	DailyLimit int `json:"daily_limit"`
+	// MinIntervalMin jeda minimum antar posting ke channel yang sama (menit).
+	MinIntervalMin int `json:"min_interval_min"`
+}
+
+// Validate memeriksa rentang nilai aturan channel.
+func (r ChannelRules) Validate() error {
+	if r.DailyLimit < 0 || r.DailyLimit > 50 {
+		return errors.New("daily_limit must be between 0 and 50")
+	}
+	if r.MinIntervalMin < 1 || r.MinIntervalMin > 24*60 {
+		return errors.New("min_interval_min must be between 1 and 1440")
+	}
+	return nil
+}
+
+// Status percobaan auto-join di auto_join_logs (juga status akhir item auto_join_queue).
+const (
+	AutoJoinJoined  = "joined"
+	AutoJoinFailed  = "failed"
+	AutoJoinSkipped = "skipped"
+)
+
+// Status item antrean persetujuan auto-join sebelum diproses.
+const (
+	AutoJoinPending  = "pending"
+	AutoJoinApproved = "approved"
+	AutoJoinRejected = "rejected"
+	// AutoJoinRetrying join gagal sementara; dicoba ulang setelah NotBefore.
+	AutoJoinRetrying = "retrying"
+)
+
+// AutoJoinSettings pengaturan auto-join per akun.
+type AutoJoinSettings struct {
+	AccountID         string `json:"account_id"`
+	Enabled           bool   `json:"enabled"`
+	DailyLimit        int    `json:"daily_limit"`
+	PreviewBeforeJoin bool   `json:"preview_before_join"`
+	// RequireApproval: link terdeteksi masuk antrean persetujuan, bukan langsung di-join.
+	RequireApproval bool `json:"require_approval"`
+	// WhitelistContacts JID/nomor pengirim yang link-nya boleh di-join; kosong = semua.
+	WhitelistContacts []string `json:"whitelist_contacts"`
+	// BlacklistKeywords kata di nama grup yang membuat link di-skip.
+	BlacklistKeywords []string `json:"blacklist_keywords"`
+	// MinParticipants/MaxParticipants batas jumlah anggota grup (0 = tanpa batas); hanya
+	// berlaku saat PreviewBeforeJoin aktif karena jumlah anggota diketahui dari preview.
+	MinParticipants int `json:"min_participants"`
+	MaxParticipants int `json:"max_participants"`
+	// AutoEnableAfterJoin: grup hasil auto-join langsung diaktifkan untuk broadcast, setelah
+	// jeda AutoEnableSettleMin menit (0 = segera).
+	AutoEnableAfterJoin bool `json:"auto_enable_after_join"`
+	AutoEnableSettleMin int  `json:"auto_enable_settle_min"`
+	// IntroTemplateID template perkenalan yang dikirim ke grup IntroDelayMin menit (+ acak
+	// 0..IntroJitterMin) setelah join; kosong = tanpa intro.
+	IntroTemplateID string `json:"intro_template_id"`
+	IntroDelayMin   int    `json:"intro_delay_min"`
+	IntroJitterMin  int    `json:"intro_jitter_min"`
+	// JoinWindows jendela jam (WIB) per hari tempat join otomatis boleh berjalan, format sama
+	// dengan jendela kirim scheduler; nil = kapan saja. Link di luar jendela ditunda ke antrean.
+	JoinWindows *WeekWindows `json:"join_windows"`
+	// JoinJitterMinSec/JoinJitterMaxSec jeda acak antar join akun (detik).
+	JoinJitterMinSec int `json:"join_jitter_min_sec"`
+	JoinJitterMaxSec int `json:"join_jitter_max_sec"`
+}
+
+// DefaultAutoJoinSettings pengaturan untuk akun yang belum punya baris auto_join_settings.
+func DefaultAutoJoinSettings(accountID string) AutoJoinSettings {
+	return AutoJoinSettings{
+		AccountID:         accountID,
+		DailyLimit:        20,
+		PreviewBeforeJoin: true,
+		WhitelistContacts: []string{},
+		BlacklistKeywords: []string{},
+		IntroDelayMin:     30,
+		IntroJitterMin:    15,
+		JoinJitterMinSec:  3,
+		JoinJitterMaxSec:  3,
+	}
+}
+
+// Status intro pasca-join.
+const (
+	IntroPending  = "pending"
+	IntroSent     = "sent"
+	IntroFailed   = "failed"
+	IntroCanceled = "canceled"
+)
+
+// AutoJoinIntro pesan perkenalan terjadwal untuk grup hasil auto-join.
+type AutoJoinIntro struct {
+	ID         int64      `json:"id"`
+	AccountID  string     `json:"account_id"`
+	GroupID    string     `json:"group_id"`
+	GroupName  string     `json:"group_name"`
+	TemplateID string     `json:"template_id"`
+	DueAt      time.Time  `json:"due_at"`
+	Status     string     `json:"status"`
+	Error      string     `json:"error,omitempty"`
+	SentAt     *time.Time `json:"sent_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// AutoJoinLog satu percobaan join lewat link undangan (otomatis atau manual).
+type AutoJoinLog struct {
+	ID         int64     `json:"id"`
+	AccountID  string    `json:"account_id"`
+	GroupID    string    `json:"group_id"`
+	GroupName  string    `json:"group_name"`
+	InviteCode string    `json:"invite_code"`
+	SharedBy   string    `json:"shared_by"`
+	SharedIn   string    `json:"shared_in"`
+	Status     string    `json:"status"`
+	Reason     string    `json:"reason"`
+	JoinedAt   time.Time `json:"joined_at"`
+}
+
+// AutoJoinQueueItem link undangan yang menunggu (atau sudah mendapat) keputusan operator.
+type AutoJoinQueueItem struct {
+	ID         int64      `json:"id"`
+	AccountID  string     `json:"account_id"`
+	InviteCode string     `json:"invite_code"`
+	GroupName  string     `json:"group_name,omitempty"`
+	SharedBy   string     `json:"shared_by,omitempty"`
+	SharedIn   string     `json:"shared_in,omitempty"`
+	Status     string     `json:"status"`
+	Reason     string     `json:"reason,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	DecidedAt  *time.Time `json:"decided_at,omitempty"`
+	DoneAt     *time.Time `json:"done_at,omitempty"`
+	// NotBefore item yang disetujui baru di-join setelah waktu ini (jeda impor massal/backoff retry).
+	NotBefore *time.Time `json:"not_before,omitempty"`
+	// Attempts jumlah percobaan ulang setelah join gagal sementara.
+	Attempts int `json:"attempts,omitempty"`
+}
+
+// AutoJoinStats rekap auto_join_logs satu akun.
+type AutoJoinStats struct {
+	TotalJoined  int64 `json:"total_joined"`
+	TotalFailed  int64 `json:"total_failed"`
+	TotalSkipped int64 `json:"total_skipped"`
+	JoinedToday  int64 `json:"joined_today"`
+}
+
+// Status link undangan di group_links.
+const (
+	LinkOK      = "ok"
+	LinkRevoked = "revoked"
+	LinkFull    = "full"
+	LinkError   = "error"
+)
+
+// GroupLink link undangan grup milik sendiri (akun admin) beserta hasil cek kesehatan terakhir.
+type GroupLink struct {
+	GroupID    string `json:"group_id"`
+	AccountID  string `json:"account_id"`
+	GroupName  string `json:"group_name,omitempty"`
+	InviteLink string `json:"invite_link"`
+	// Status LinkOK/LinkRevoked/LinkFull/LinkError; kosong = belum pernah dicek.
+	Status      string     `json:"status"`
+	MemberCount int        `json:"member_count"`
+	Error       string     `json:"error,omitempty"`
+	CheckedAt   *time.Time `json:"checked_at,omitempty"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// CrossPromo satu pasangan cross-promo yang sudah terkirim: grup PromotedGroupID sudah
+// dipromosikan di TargetGroupID (tidak diulang).
+type CrossPromo struct {
+	PromotedGroupID string    `json:"promoted_group_id"`
+	TargetGroupID   string    `json:"target_group_id"`
+	TargetName      string    `json:"target_name,omitempty"`
+	CampaignID      string    `json:"campaign_id,omitempty"`
+	AccountID       string    `json:"account_id"`
+	SentAt          time.Time `json:"sent_at"`
+}
+
+// Severity anotasi operator.
+const (
+	AnnotationInfo     = "info"
+	AnnotationWarning  = "warning"
+	AnnotationCritical = "critical"
+)
+
+// Annotation catatan operator (catatan shift/insiden) pada satu titik waktu, opsional terikat
+// ke akun atau grup. Tanpa akun dan grup berarti catatan umum.
+type Annotation struct {
+	ID        int64     `json:"id"`
+	At        time.Time `json:"at"`
+	Severity  string    `json:"severity"`
+	Text      string    `json:"text"`
+	AccountID string    `json:"account_id,omitempty"`
+	GroupID   string    `json:"group_id,omitempty"`
+	Author    string    `json:"author,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// TimelineFilter mempersempit timeline/anotasi. AccountID/GroupID juga menyertakan catatan
+// umum; Before nol = sampai sekarang.
+type TimelineFilter struct {
+	AccountID string
+	GroupID   string
+	Before    time.Time
+	Limit     int
+}
+
+// Jenis entri timeline.
+const (
+	TimelineLog        = "log"
+	TimelineAnnotation = "annotation"
+)
+
+// TimelineEntry satu entri timeline: log kirim atau anotasi operator.
+type TimelineEntry struct {
+	Kind       string      `json:"kind"`
+	At         time.Time   `json:"at"`
+	Log        *LogEntry   `json:"log,omitempty"`
+	Annotation *Annotation `json:"annotation,omitempty"`
 }
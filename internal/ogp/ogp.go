@@ -0,0 +1,214 @@
+// Package ogp mengambil metadata Open Graph (title, description, image) dari halaman web
+// dan mengimpor gambar ke direktori uploads lokal.
+package ogp
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"html"
+	"image"
+	_ "image/gif"
+	"image/jpeg"
+	_ "image/png"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// Preview adalah metadata link preview hasil scraping.
+type Preview struct {
+	URL         string `json:"url"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	Image       string `json:"image,omitempty"`
+	SiteName    string `json:"site_name,omitempty"`
+}
+
+// maxHTMLBytes membatasi jumlah HTML yang dibaca; meta tag selalu berada di <head>.
+const maxHTMLBytes = 512 << 10
+
+var (
+	metaTagRe = regexp.MustCompile(`(?is)<meta\s+[^>]*>`)
+	attrRe    = regexp.MustCompile(`(?is)([a-z:_-]+)\s*=\s*("([^"]*)"|'([^']*)')`)
+	titleRe   = regexp.MustCompile(`(?is)<title[^>]*>(.*?)</title>`)
+)
+
+// Fetch mengunduh halaman dan mengurai meta tag Open Graph (dengan fallback ke twitter:* dan <title>).
+func Fetch(ctx context.Context, client *http.Client, pageURL string) (*Preview, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, pageURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", "Mozilla/5.0 (compatible; promotenews/1.0; +link-preview)")
+	req.Header.Set("Accept", "text/html,application/xhtml+xml")
+	res, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return nil, fmt.Errorf("fetch %s: status %d", pageURL, res.StatusCode)
+	}
+	body, err := io.ReadAll(io.LimitReader(res.Body, maxHTMLBytes))
+	if err != nil {
+		return nil, err
+	}
+	p := Parse(string(body))
+	p.URL = res.Request.URL.String()
+	if p.Image != "" {
+		p.Image = resolve(res.Request.URL, p.Image)
+	}
+	return p, nil
+}
+
+// Parse mengurai meta tag dari potongan HTML.
+func Parse(doc string) *Preview {
+	meta := map[string]string{}
+	for _, tag := range metaTagRe.FindAllString(doc, -1) {
+		attrs := map[string]string{}
+		for _, m := range attrRe.FindAllStringSubmatch(tag, -1) {
+			v := m[3]
+			if v == "" {
+				v = m[4]
+			}
+			attrs[strings.ToLower(m[1])] = html.UnescapeString(strings.TrimSpace(v))
+		}
+		key := attrs["property"]
+		if key == "" {
+			key = attrs["name"]
+		}
+		key = strings.ToLower(key)
+		if key == "" || attrs["content"] == "" {
+			continue
+		}
+		if _, seen := meta[key]; !seen {
+			meta[key] = attrs["content"]
+		}
+	}
+	first := func(keys ...string) string {
+		for _, k := range keys {
+			if v := meta[k]; v != "" {
+				return v
+			}
+		}
+		return ""
+	}
+	p := &Preview{
+		Title:       first("og:title", "twitter:title"),
+		Description: first("og:description", "twitter:description", "description"),
+		Image:       first("og:image", "og:image:url", "og:image:secure_url", "twitter:image"),
+		SiteName:    first("og:site_name"),
+	}
+	if p.Title == "" {
+		if m := titleRe.FindStringSubmatch(doc); len(m) > 1 {
+			p.Title = html.UnescapeString(strings.TrimSpace(m[1]))
+		}
+	}
+	return p
+}
+
+func resolve(base *url.URL, ref string) string {
+	u, err := url.Parse(ref)
+	if err != nil {
+		return ref
+	}
+	return base.ResolveReference(u).String()
+}
+
+// ImportImage mengunduh gambar remote ke dir dan mengembalikan URL lokal "/uploads/<file>".
+// Ukuran dibatasi maxBytes (0 = 10MB).
+func ImportImage(ctx context.Context, client *http.Client, imageURL, dir string, maxBytes int64) (string, error) {
+	if maxBytes <= 0 {
+		maxBytes = 10 << 20
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, imageURL, nil)
+	if err != nil {
+		return "", err
+	}
+	res, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return "", fmt.Errorf("fetch image %s: status %d", imageURL, res.StatusCode)
+	}
+	ct := strings.ToLower(res.Header.Get("Content-Type"))
+	var ext string
+	switch {
+	case strings.Contains(ct, "jpeg"), strings.Contains(ct, "jpg"):
+		ext = ".jpg"
+	case strings.Contains(ct, "png"):
+		ext = ".png"
+	case strings.Contains(ct, "webp"):
+		ext = ".webp"
+	default:
+		return "", fmt.Errorf("unsupported image type %q", ct)
+	}
+	data, err := io.ReadAll(io.LimitReader(res.Body, maxBytes+1))
+	if err != nil {
+		return "", err
+	}
+	if int64(len(data)) > maxBytes {
+		return "", fmt.Errorf("image too large (> %d bytes)", maxBytes)
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+	fname := uuid.NewString() + ext
+	if err := os.WriteFile(filepath.Join(dir, fname), data, 0o644); err != nil {
+		return "", err
+	}
+	return "/uploads/" + fname, nil
+}
+
+// Thumbnail membuat thumbnail JPEG kecil (sisi terpanjang maxSide px) dari data JPEG/PNG/GIF,
+// untuk dipakai sebagai JPEGThumbnail pada link preview WhatsApp.
+func Thumbnail(data []byte, maxSide int) ([]byte, int, int, error) {
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	b := src.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if w == 0 || h == 0 {
+		return nil, 0, 0, fmt.Errorf("empty image")
+	}
+	if maxSide <= 0 {
+		maxSide = 300
+	}
+	tw, th := w, h
+	if w > maxSide || h > maxSide {
+		if w >= h {
+			tw, th = maxSide, h*maxSide/w
+		} else {
+			tw, th = w*maxSide/h, maxSide
+		}
+	}
+	if tw < 1 {
+		tw = 1
+	}
+	if th < 1 {
+		th = 1
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, tw, th))
+	// nearest-neighbour cukup untuk thumbnail preview
+	for y := 0; y < th; y++ {
+		sy := b.Min.Y + y*h/th
+		for x := 0; x < tw; x++ {
+			dst.Set(x, y, src.At(b.Min.X+x*w/tw, sy))
+		}
+	}
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, dst, &jpeg.Options{Quality: 70}); err != nil {
+		return nil, 0, 0, err
+	}
+	return buf.Bytes(), tw, th, nil
+}
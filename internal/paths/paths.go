@@ -0,0 +1,84 @@
+// Package paths menentukan direktori kerja (data, sesi WhatsApp, upload) sebagai path absolut
+// sehingga proses tidak bergantung pada CWD (mis. saat dijalankan lewat systemd).
+package paths
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Paths berisi direktori kerja absolut.
+type Paths struct {
+	// DataDir lokasi DB utama (DATA_DIR, default CWD saat start).
+	DataDir string
+	// SessionDir lokasi DB sesi whatsmeow per akun (SESSION_DIR, default DataDir).
+	SessionDir string
+	// UploadDir lokasi media yang disajikan di /uploads/ (UPLOAD_DIR, default DataDir/uploads).
+	UploadDir string
+}
+
+// FromEnv membaca DATA_DIR, SESSION_DIR, UPLOAD_DIR dan mengubahnya menjadi path absolut.
+// Path relatif di env di-resolve terhadap DataDir (bukan CWD) kecuali DATA_DIR sendiri.
+func FromEnv() (Paths, error) {
+	data, err := filepath.Abs(envOr("DATA_DIR", "."))
+	if err != nil {
+		return Paths{}, fmt.Errorf("resolve DATA_DIR: %w", err)
+	}
+	p := Paths{
+		DataDir:    data,
+		SessionDir: resolve(data, envOr("SESSION_DIR", ".")),
+		UploadDir:  resolve(data, envOr("UPLOAD_DIR", "uploads")),
+	}
+	return p, nil
+}
+
+// Ensure membuat semua direktori. Data dan sesi hanya bisa dibaca pemilik (berisi kredensial
+// WhatsApp); uploads dapat dibaca umum karena disajikan lewat HTTP.
+func (p Paths) Ensure() error {
+	for _, d := range []struct {
+		path string
+		perm os.FileMode
+	}{{p.DataDir, 0o700}, {p.SessionDir, 0o700}, {p.UploadDir, 0o755}} {
+		if err := os.MkdirAll(d.path, d.perm); err != nil {
+			return fmt.Errorf("create %s: %w", d.path, err)
+		}
+	}
+	return nil
+}
+
+// DSN mengembalikan DSN SQLite default untuk DB utama di DataDir.
+func (p Paths) DSN() string {
+	return "file:" + filepath.Join(p.DataDir, "promote.db") + "?_foreign_keys=on"
+}
+
+// UploadFile memetakan URL lokal "/uploads/<name>" (atau "uploads/<name>") ke path file di dir.
+// Mengembalikan error jika path keluar dari dir.
+func UploadFile(dir, url string) (string, error) {
+	rest := strings.TrimPrefix(url, "/")
+	if !strings.HasPrefix(rest, "uploads/") {
+		return "", fmt.Errorf("invalid local upload path")
+	}
+	name := strings.TrimPrefix(rest, "uploads/")
+	full := filepath.Join(dir, filepath.FromSlash(name))
+	rel, err := filepath.Rel(dir, full)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("invalid local upload path")
+	}
+	return full, nil
+}
+
+func envOr(key, def string) string {
+	if v := strings.TrimSpace(os.Getenv(key)); v != "" {
+		return v
+	}
+	return def
+}
+
+func resolve(base, p string) string {
+	if filepath.IsAbs(p) {
+		return filepath.Clean(p)
+	}
+	return filepath.Join(base, p)
+}
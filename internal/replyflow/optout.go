@@ -0,0 +1,66 @@
+package replyflow
+
+import (
+	"log"
+	"os"
+	"strings"
+
+	"go.mau.fi/whatsmeow/types"
+	"go.mau.fi/whatsmeow/types/events"
+
+	"promote/internal/model"
+)
+
+// defaultStopWords balasan yang dianggap permintaan berhenti; ganti lewat OPT_OUT_KEYWORDS
+// (dipisah koma).
+var defaultStopWords = []string{"stop", "unsubscribe", "berhenti", "unreg"}
+
+func stopWords() []string {
+	env := strings.TrimSpace(os.Getenv("OPT_OUT_KEYWORDS"))
+	if env == "" {
+		return defaultStopWords
+	}
+	var out []string
+	for _, w := range strings.Split(env, ",") {
+		if w = strings.ToLower(strings.TrimSpace(w)); w != "" {
+			out = append(out, w)
+		}
+	}
+	return out
+}
+
+// isStop true jika seluruh pesan (tanpa tanda baca di ujung) adalah salah satu kata berhenti.
+func isStop(text string) bool {
+	t := strings.ToLower(strings.Trim(strings.TrimSpace(text), ".!?,;: "))
+	for _, w := range stopWords() {
+		if t == w {
+			return true
+		}
+	}
+	return false
+}
+
+// handleOptOut memasukkan pengirim yang membalas STOP ke daftar supresi global sehingga tidak
+// lagi menerima DM maupun mention. true jika pesan adalah permintaan berhenti.
+func (e *Engine) handleOptOut(accountID string, evt *events.Message, text string) bool {
+	switch evt.Info.Chat.Server {
+	case types.GroupServer, types.DefaultUserServer, types.HiddenUserServer:
+	default:
+		return false // status/broadcast/newsletter bukan balasan
+	}
+	if !isStop(text) {
+		return false
+	}
+	number := e.senderNumber(accountID, evt.Info.MessageSource)
+	if number == "" {
+		log.Printf("[replyflow] STOP from unresolvable sender=%s", evt.Info.Sender)
+		return true
+	}
+	reason := "replied " + truncate(strings.TrimSpace(text), 40) + " in " + evt.Info.Chat.String()
+	if err := e.Store.AddDMSuppression(number, reason, model.SuppressionStopReply); err != nil {
+		log.Printf("[replyflow] suppress number=%s err=%v", number, err)
+		return true
+	}
+	log.Printf("[replyflow] OPT_OUT account=%s chat=%s number=%s", accountID, evt.Info.Chat, number)
+	return true
+}
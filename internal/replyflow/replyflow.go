@@ -0,0 +1,249 @@
+// Package replyflow menjalankan campaign opt-in "balas PROMO untuk dapat kode". Balasan grup yang
+// memuat keyword campaign dalam jendela waktu setelah campaign terkirim ke grup itu dicatat sebagai
+// opt-in; pengirimnya lalu di-DM template follow-up secara bertahap (dibatasi per akun per jam).
+// Balasan "STOP" (grup maupun chat pribadi) memasukkan pengirimnya ke daftar supresi global.
+package replyflow
+
+import (
+	"context"
+	"errors"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	waProto "go.mau.fi/whatsmeow/binary/proto"
+	"go.mau.fi/whatsmeow/types"
+	"go.mau.fi/whatsmeow/types/events"
+
+	"promote/internal/model"
+	"promote/internal/sender"
+	"promote/internal/storage"
+	"promote/internal/wa"
+)
+
+// Engine menangkap balasan keyword dan mengirim DM follow-up.
+type Engine struct {
+	Store   *storage.Store
+	Manager *wa.Manager
+	Sender  *sender.Sender
+	// Gap jeda antar DM follow-up (REPLY_FLOW_DM_GAP_SEC, default 15).
+	Gap time.Duration
+
+	kick chan struct{}
+}
+
+// New membuat Engine dengan konfigurasi dari env.
+func New(store *storage.Store, manager *wa.Manager, snd *sender.Sender) *Engine {
+	e := &Engine{Store: store, Manager: manager, Sender: snd, Gap: 15 * time.Second, kick: make(chan struct{}, 1)}
+	if n, err := strconv.Atoi(strings.TrimSpace(os.Getenv("REPLY_FLOW_DM_GAP_SEC"))); err == nil && n >= 0 {
+		e.Gap = time.Duration(n) * time.Second
+	}
+	return e
+}
+
+// HandleMessage mencatat opt-out (balasan STOP) dan opt-in untuk balasan grup yang cocok dengan
+// alur yang sedang terbuka. Didaftarkan lewat Manager.AddMessageHandler.
+func (e *Engine) HandleMessage(accountID string, evt *events.Message) {
+	if evt == nil || evt.Message == nil || evt.Info.IsFromMe {
+		return
+	}
+	text := messageText(evt.Message)
+	if strings.TrimSpace(text) == "" {
+		return
+	}
+	if e.handleOptOut(accountID, evt, text) || !evt.Info.IsGroup {
+		return
+	}
+	groupID := evt.Info.Chat.String()
+	flows, err := e.Store.OpenReplyFlows(accountID, groupID, time.Now())
+	if err != nil {
+		log.Printf("[replyflow] open flows account=%s group=%s err=%v", accountID, groupID, err)
+		return
+	}
+	var number string
+	added := false
+	for _, f := range flows {
+		if !f.Matches(text) {
+			continue
+		}
+		if number == "" {
+			if number = e.senderNumber(accountID, evt.Info.MessageSource); number == "" {
+				log.Printf("[replyflow] cannot resolve number for sender=%s", evt.Info.Sender)
+				return
+			}
+		}
+		ok, err := e.Store.AddOptIn(model.ReplyOptIn{CampaignID: f.CampaignID, AccountID: accountID, GroupID: groupID,
+			Number: number, Reply: truncate(text, 200)})
+		if err != nil {
+			log.Printf("[replyflow] add opt-in campaign=%s number=%s err=%v", f.CampaignID, number, err)
+			continue
+		}
+		if ok {
+			added = true
+			log.Printf("[replyflow] OPT_IN campaign=%s account=%s group=%s number=%s", f.CampaignID, accountID, groupID, number)
+		}
+	}
+	if added {
+		e.Kick()
+	}
+}
+
+// Kick membangunkan worker DM tanpa menunggu tick berikutnya.
+func (e *Engine) Kick() {
+	select {
+	case e.kick <- struct{}{}:
+	default:
+	}
+}
+
+// Start menjalankan worker DM follow-up (tiap menit dan setiap ada opt-in baru).
+func (e *Engine) Start(ctx context.Context) {
+	go func() {
+		tick := time.NewTicker(time.Minute)
+		defer tick.Stop()
+		for {
+			e.drain(ctx)
+			select {
+			case <-ctx.Done():
+				return
+			case <-tick.C:
+			case <-e.kick:
+			}
+		}
+	}()
+}
+
+// drain mengirim DM untuk opt-in pending. Opt-in yang akunnya offline atau sudah mencapai
+// max_per_hour dibiarkan pending untuk putaran berikutnya.
+func (e *Engine) drain(ctx context.Context) {
+	pending, err := e.Store.PendingOptIns(100)
+	if err != nil {
+		log.Printf("[replyflow] pending opt-ins err=%v", err)
+		return
+	}
+	if len(pending) == 0 {
+		return
+	}
+	suppressed, err := e.Store.DMSuppressedSet()
+	if err != nil {
+		log.Printf("[replyflow] suppressions err=%v", err)
+		return
+	}
+	for _, o := range pending {
+		if ctx.Err() != nil {
+			return
+		}
+		f, err := e.Store.GetReplyFlow(o.CampaignID)
+		if errors.Is(err, storage.ErrReplyFlowNotFound) || (err == nil && !f.Enabled) {
+			e.finish(o, model.OptInSkipped, "reply flow disabled")
+			continue
+		}
+		if err != nil {
+			log.Printf("[replyflow] flow campaign=%s err=%v", o.CampaignID, err)
+			continue
+		}
+		if suppressed[o.Number] {
+			e.finish(o, model.OptInSuppressed, "")
+			continue
+		}
+		if !e.connected(o.AccountID) {
+			continue
+		}
+		sent, err := e.Store.CountDMsSince(o.AccountID, time.Now().Add(-time.Hour))
+		if err != nil {
+			log.Printf("[replyflow] count dms account=%s err=%v", o.AccountID, err)
+			continue
+		}
+		if sent >= f.MaxPerHour {
+			continue
+		}
+		t, err := e.Store.GetTemplate(f.TemplateID)
+		if errors.Is(err, storage.ErrTemplateNotFound) {
+			e.finish(o, model.OptInSkipped, "follow-up template not found")
+			continue
+		}
+		if err != nil {
+			log.Printf("[replyflow] template=%s err=%v", f.TemplateID, err)
+			continue
+		}
+		sendCtx, cancel := context.WithTimeout(ctx, 60*time.Second)
+		err = e.Sender.SendDirect(sendCtx, o.AccountID, o.Number, sender.TemplateContent(t))
+		cancel()
+		status, errMsg := model.OptInSent, ""
+		if err != nil {
+			status, errMsg = model.OptInFailed, err.Error()
+		}
+		if err := e.Store.RecordDMSend(o.AccountID, o.Number, o.CampaignID, status, errMsg); err != nil {
+			log.Printf("[replyflow] record dm number=%s err=%v", o.Number, err)
+		}
+		e.finish(o, status, errMsg)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(e.Gap):
+		}
+	}
+}
+
+func (e *Engine) finish(o model.ReplyOptIn, status, errMsg string) {
+	if err := e.Store.SetOptInDM(o.ID, status, errMsg); err != nil {
+		log.Printf("[replyflow] set opt-in=%d status=%s err=%v", o.ID, status, err)
+		return
+	}
+	log.Printf("[replyflow] DM campaign=%s account=%s number=%s status=%s err=%q", o.CampaignID, o.AccountID, o.Number, status, errMsg)
+}
+
+func (e *Engine) connected(accountID string) bool {
+	cli, err := e.Manager.GetClient(accountID)
+	return err == nil && cli.Store != nil && cli.Store.ID != nil && cli.IsConnected()
+}
+
+// senderNumber nomor telepon pengirim; pengirim ber-LID dipetakan lewat SenderAlt atau
+// tabel LID akun. Mengembalikan "" jika nomor tidak bisa diketahui.
+func (e *Engine) senderNumber(accountID string, src types.MessageSource) string {
+	jid := src.Sender
+	if jid.Server == types.HiddenUserServer {
+		switch {
+		case src.SenderAlt.Server == types.DefaultUserServer:
+			jid = src.SenderAlt
+		default:
+			cli, err := e.Manager.GetClient(accountID)
+			if err != nil || cli.Store == nil || cli.Store.LIDs == nil {
+				return ""
+			}
+			pn, err := cli.Store.LIDs.GetPNForLID(context.Background(), jid)
+			if err != nil || pn.IsEmpty() {
+				return ""
+			}
+			jid = pn
+		}
+	}
+	if jid.Server != types.DefaultUserServer {
+		return ""
+	}
+	return storage.NormalizeNumber(jid.User)
+}
+
+func messageText(msg *waProto.Message) string {
+	switch {
+	case msg.GetConversation() != "":
+		return msg.GetConversation()
+	case msg.GetExtendedTextMessage().GetText() != "":
+		return msg.GetExtendedTextMessage().GetText()
+	case msg.GetImageMessage().GetCaption() != "":
+		return msg.GetImageMessage().GetCaption()
+	case msg.GetVideoMessage().GetCaption() != "":
+		return msg.GetVideoMessage().GetCaption()
+	}
+	return ""
+}
+
+func truncate(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n])
+}
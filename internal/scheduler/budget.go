@@ -0,0 +1,57 @@
+package scheduler
+
+import (
+	"fmt"
+	"log"
+	"time"
+)
+
+// Status account_events/webhook untuk jeda error budget.
+const (
+	budgetPaused  = "budget_paused"
+	budgetResumed = "budget_resumed"
+)
+
+// checkErrorBudgets menjeda akun yang rasio gagalnya dalam jendela bergulir melewati error
+// budget, dan melanjutkan akun yang dijeda begitu rasionya kembali di bawah budget (kegagalan
+// lama keluar dari jendela). Akun dengan override manual aktif tidak dievaluasi.
+func (s *Scheduler) checkErrorBudgets(now time.Time) {
+	budget, err := s.Store.ErrorBudget()
+	if err != nil {
+		log.Printf("[scheduler] error budget settings err=%v", err)
+		return
+	}
+	if budget.MaxFailPct <= 0 {
+		return
+	}
+	list, err := s.Store.AccountBudgets(now.Add(-time.Duration(budget.WindowHours) * time.Hour))
+	if err != nil {
+		log.Printf("[scheduler] error budget query err=%v", err)
+		return
+	}
+	for _, b := range list {
+		if b.OverrideUntil != nil && now.Before(*b.OverrideUntil) {
+			continue
+		}
+		exceeded := budget.Exceeded(b.Sent, b.Failed)
+		switch {
+		case exceeded && b.PausedAt == nil:
+			reason := fmt.Sprintf("failed %d/%d (%.1f%%) in %dh > budget %.1f%%",
+				b.Failed, b.Sent+b.Failed, b.FailPct, budget.WindowHours, budget.MaxFailPct)
+			if err := s.Store.PauseAccountBudget(b.AccountID, reason); err != nil {
+				log.Printf("[scheduler] account=%s budget pause err=%v", b.AccountID, err)
+				continue
+			}
+			log.Printf("[scheduler] ERROR_BUDGET_EXCEEDED account=%s %s -> paused", b.AccountID, reason)
+			s.Manager.EmitAccountEvent(b.AccountID, budgetPaused, reason)
+		case !exceeded && b.PausedAt != nil:
+			if _, err := s.Store.ResumeAccountBudget(b.AccountID, nil); err != nil {
+				log.Printf("[scheduler] account=%s budget resume err=%v", b.AccountID, err)
+				continue
+			}
+			msg := fmt.Sprintf("failed %d/%d (%.1f%%) in %dh within budget", b.Failed, b.Sent+b.Failed, b.FailPct, budget.WindowHours)
+			log.Printf("[scheduler] ERROR_BUDGET_RECOVERED account=%s %s -> resumed", b.AccountID, msg)
+			s.Manager.EmitAccountEvent(b.AccountID, budgetResumed, msg)
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package scheduler
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// channelRetry jeda sebelum channel yang gagal diposting dicoba lagi.
+const channelRetry = 30 * time.Minute
+
+// runChannels memposting template acak ke paling banyak satu WhatsApp Channel per tick menurut
+// aturan channel (limit harian dan jeda per channel), terpisah dari jendela dan limit grup.
+func (s *Scheduler) runChannels(ctx context.Context, now time.Time) {
+	rules, err := s.Store.ChannelRules()
+	if err != nil {
+		log.Printf("[scheduler] channel rules err=%v", err)
+		return
+	}
+	due, err := s.Store.DueChannels(rules)
+	if err != nil {
+		log.Printf("[scheduler] due channels err=%v", err)
+		return
+	}
+	if s.channelNext == nil {
+		s.channelNext = map[string]time.Time{}
+	}
+	for _, ch := range due {
+		if now.Before(s.channelNext[ch.ID]) {
+			continue
+		}
+		sendCtx, cancel := context.WithTimeout(ctx, 90*time.Second)
+		err := s.Sender.PostRandomTemplateToChannel(sendCtx, ch.AccountID, ch.ID)
+		cancel()
+		if err != nil {
+			s.channelNext[ch.ID] = now.Add(channelRetry)
+			log.Printf("[scheduler] CHANNEL_POST failed account=%s channel=%s err=%v", ch.AccountID, ch.ID, err)
+			return
+		}
+		delete(s.channelNext, ch.ID)
+		log.Printf("[scheduler] CHANNEL_POST account=%s channel=%s (%d/%d today)", ch.AccountID, ch.ID, ch.PostsToday+1, rules.DailyLimit)
+		return
+	}
+}
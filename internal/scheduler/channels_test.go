@@ -0,0 +1,46 @@
+package scheduler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"promote/internal/model"
+	"promote/internal/storage/storagetest"
+)
+
+func TestScenarioChannelPostRespectsRules(t *testing.T) {
+	s, st, fake := scenario(t)
+	storagetest.SeedAccount(t, st, storagetest.Account{ID: "a"})
+	if _, err := st.SyncChannels("a", []model.Channel{{ID: "1203@newsletter", Name: "Promo", Role: "owner"}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := st.SetChannelEnabled("1203@newsletter", true); err != nil {
+		t.Fatal(err)
+	}
+	if err := st.SetChannelRules(model.ChannelRules{DailyLimit: 1, MinIntervalMin: 1}); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 3; i++ {
+		s.runChannels(context.Background(), time.Now())
+	}
+
+	sent := fake.SentTo("1203@newsletter")
+	if len(sent) != 1 {
+		t.Fatalf("channel posts = %d, want 1 (daily limit)", len(sent))
+	}
+	if sent[0].Text != "Halo grup!" {
+		t.Fatalf("post = %+v", sent[0])
+	}
+	posts, err := st.ListChannelPosts("1203@newsletter", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(posts) != 1 || posts[0].Status != "sent" {
+		t.Fatalf("recorded posts = %+v", posts)
+	}
+	if n := logCount(t, st, "sent"); n != 0 {
+		t.Fatalf("group logs = %d, want 0", n)
+	}
+}
@@ -0,0 +1,25 @@
+package scheduler
+
+import (
+	"log"
+	"time"
+)
+
+// planColdStart memberi tanggal kiriman pertama untuk grup baru yang belum direncanakan
+// (mis. setelah ratusan grup hasil import diaktifkan sekaligus) agar tidak langsung eligible
+// semua. Batas per hari per akun dari setting cold_start_per_day; 0 = nonaktif.
+func (s *Scheduler) planColdStart(now time.Time) {
+	perDay, err := s.Store.ColdStartPerDay()
+	if err != nil {
+		log.Printf("[scheduler] cold start settings err=%v", err)
+		return
+	}
+	n, err := s.Store.PlanColdStart(perDay, now)
+	if err != nil {
+		log.Printf("[scheduler] cold start plan err=%v", err)
+		return
+	}
+	if n > 0 {
+		log.Printf("[scheduler] COLD_START_PLANNED groups=%d per_day=%d", n, perDay)
+	}
+}
@@ -0,0 +1,135 @@
+package scheduler
+
+import (
+	"context"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"promote/internal/model"
+	"promote/internal/rng"
+)
+
+// dmConfig pengaturan campaign mode dm.
+type dmConfig struct {
+	// Jam kirim DM (WIB) [StartHour, EndHour) dari DM_HOURS ("9-20"); DM di jam tidur
+	// lebih cepat dilaporkan sebagai spam dibanding kiriman grup.
+	StartHour, EndHour int
+	// Nomor yang sudah menerima DM sukses (campaign mana pun) dalam jangka ini ditunda
+	// (DM_COOLDOWN_HOURS, default 168).
+	Cooldown time.Duration
+	// Jeda acak antar DM per akun (DM_MIN_DELAY_SEC 90, DM_MAX_DELAY_SEC 240).
+	MinDelaySec, MaxDelaySec int
+}
+
+func dmConfigFromEnv() dmConfig {
+	c := dmConfig{StartHour: 9, EndHour: 20, Cooldown: 168 * time.Hour, MinDelaySec: 90, MaxDelaySec: 240}
+	if v := strings.TrimSpace(os.Getenv("DM_HOURS")); v != "" {
+		if a, b, ok := strings.Cut(v, "-"); ok {
+			start, err1 := strconv.Atoi(strings.TrimSpace(a))
+			end, err2 := strconv.Atoi(strings.TrimSpace(b))
+			if err1 == nil && err2 == nil && start >= 0 && end <= 24 && start < end {
+				c.StartHour, c.EndHour = start, end
+			}
+		}
+	}
+	if n, err := strconv.Atoi(strings.TrimSpace(os.Getenv("DM_COOLDOWN_HOURS"))); err == nil && n >= 0 {
+		c.Cooldown = time.Duration(n) * time.Hour
+	}
+	if n, err := strconv.Atoi(strings.TrimSpace(os.Getenv("DM_MIN_DELAY_SEC"))); err == nil && n >= 0 {
+		c.MinDelaySec = n
+	}
+	if n, err := strconv.Atoi(strings.TrimSpace(os.Getenv("DM_MAX_DELAY_SEC"))); err == nil && n >= 0 {
+		c.MaxDelaySec = n
+	}
+	return c
+}
+
+func (c dmConfig) inWindow(t time.Time) bool {
+	return t.Hour() >= c.StartHour && t.Hour() < c.EndHour
+}
+
+func (c dmConfig) delay(r *rng.Rand) time.Duration {
+	return r.Between(c.MinDelaySec, c.MaxDelaySec)
+}
+
+// runDMCampaigns mengirim paling banyak satu DM per akun per siklus ke target pending campaign
+// mode dm, selama dalam jendela DM, di bawah dm_daily_limit akun, dan sudah lewat jeda akun.
+func (s *Scheduler) runDMCampaigns(ctx context.Context, now time.Time) {
+	if !s.alwaysOn && !s.dm.inWindow(now) {
+		return
+	}
+	accounts, err := s.Store.DMPendingAccounts()
+	if err != nil {
+		log.Printf("[scheduler] dm accounts err=%v", err)
+		return
+	}
+	if len(accounts) == 0 {
+		return
+	}
+	suppressed, err := s.Store.DMSuppressedSet()
+	if err != nil {
+		log.Printf("[scheduler] dm suppressions err=%v", err)
+		return
+	}
+	for accountID, limit := range accounts {
+		if ctx.Err() != nil {
+			return
+		}
+		if now.Before(s.dmNext[accountID]) {
+			continue
+		}
+		sent, err := s.Store.CountDMsToday(accountID)
+		if err != nil {
+			log.Printf("[scheduler] dm account=%s count err=%v", accountID, err)
+			continue
+		}
+		if sent >= limit {
+			continue
+		}
+		t, ok, err := s.Store.NextDMTarget(accountID, now.Add(-s.dm.Cooldown))
+		if err != nil {
+			log.Printf("[scheduler] dm account=%s next target err=%v", accountID, err)
+			continue
+		}
+		if !ok {
+			continue
+		}
+		if suppressed[t.Number] {
+			if err := s.Store.SetDMTargetStatus(t.ID, model.DMTargetSkipped, "suppressed"); err != nil {
+				log.Printf("[scheduler] dm target=%d status err=%v", t.ID, err)
+			}
+			continue
+		}
+		if err := s.Sender.EnsureConnected(accountID); err != nil {
+			log.Printf("[scheduler] dm account=%s connectIfPaired=skip err=%v", accountID, err)
+			continue
+		}
+		sendCtx, cancel := context.WithTimeout(ctx, 60*time.Second)
+		err = s.Sender.SendDMCampaign(sendCtx, accountID, t.Number, t.CampaignID)
+		cancel()
+		status, errMsg := model.DMTargetSent, ""
+		if err != nil {
+			status, errMsg = model.DMTargetFailed, err.Error()
+		} else {
+			sent++
+		}
+		if err := s.Store.SetDMTargetStatus(t.ID, status, errMsg); err != nil {
+			log.Printf("[scheduler] dm target=%d status err=%v", t.ID, err)
+		}
+		s.dmNext[accountID] = time.Now().In(s.loc).Add(s.dm.delay(s.Rand))
+		log.Printf("[scheduler] DM campaign=%s account=%s number=%s status=%s (%d/%d today) err=%q",
+			t.CampaignID, accountID, t.Number, status, sent, limit, errMsg)
+	}
+}
+
+// untilWindow jeda dari t sampai jam DM berikutnya dimulai.
+func (c dmConfig) untilWindow(t time.Time) time.Duration {
+	start := time.Date(t.Year(), t.Month(), t.Day(), c.StartHour, 0, 0, 0, t.Location())
+	if !start.After(t) {
+		start = start.AddDate(0, 0, 1)
+	}
+	return start.Sub(t)
+}
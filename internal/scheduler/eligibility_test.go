@@ -0,0 +1,106 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"promote/internal/model"
+	"promote/internal/storage/storagetest"
+)
+
+func TestEligibleGroupsRespectCooldownRiskAndSuppression(t *testing.T) {
+	st := storagetest.Open(t)
+	s := &Scheduler{Store: st}
+	storagetest.SeedAccount(t, st, storagetest.Account{ID: "a"})
+	future := time.Now().Add(time.Hour)
+	for _, g := range []storagetest.Group{
+		{ID: "fresh@g.us", Enabled: true},
+		{ID: "cooled@g.us", Enabled: true, LastSentAt: storagetest.Ago(49 * time.Hour)},
+		{ID: "cooldown@g.us", Enabled: true, LastSentAt: storagetest.Ago(time.Hour)},
+		{ID: "risky@g.us", Enabled: true, RiskScore: 3},
+		{ID: "disabled@g.us"},
+		{ID: "test@g.us", Enabled: true, TestGroup: true},
+		{ID: "ramp@g.us", Enabled: true, RampAt: &future},
+		{ID: "suppressed@g.us", Enabled: true},
+	} {
+		g.AccountID = "a"
+		storagetest.SeedGroup(t, st, g)
+	}
+	if err := st.AddGroupSuppression("suppressed@g.us", "test"); err != nil {
+		t.Fatal(err)
+	}
+
+	n, err := s.countEligibleGroups("a", 48, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 2 {
+		t.Fatalf("eligible = %d, want 2 (fresh, cooled)", n)
+	}
+
+	picked := map[string]bool{}
+	for i := 0; i < 2; i++ {
+		id, err := s.pickOneEligibleGroup("a", 48, 3)
+		if err != nil {
+			t.Fatal(err)
+		}
+		picked[id] = true
+	}
+	if !picked["fresh@g.us"] || !picked["cooled@g.us"] {
+		t.Fatalf("picked = %v, want fresh and cooled", picked)
+	}
+	// Grup yang dipilih langsung masuk cooldown.
+	id, err := s.pickOneEligibleGroup("a", 48, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id != "" {
+		t.Fatalf("third pick = %q, want none", id)
+	}
+}
+
+func TestListEnabledAccountsSkipsObserverAndDisabled(t *testing.T) {
+	st := storagetest.Open(t)
+	s := &Scheduler{Store: st}
+	storagetest.SeedAccount(t, st, storagetest.Account{ID: "active", DailyLimit: 40})
+	storagetest.SeedAccount(t, st, storagetest.Account{ID: "off", Disabled: true})
+	storagetest.SeedAccount(t, st, storagetest.Account{ID: "watch", Observer: true})
+
+	accs, err := s.listEnabledAccounts()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(accs) != 1 || accs[0].ID != "active" || accs[0].DailyLimit != 40 {
+		t.Fatalf("accounts = %+v, want only active with limit 40", accs)
+	}
+}
+
+func TestEligibleGroupsExcludeCommunityAnnouncements(t *testing.T) {
+	st := storagetest.Open(t)
+	s := &Scheduler{Store: st}
+	storagetest.SeedAccount(t, st, storagetest.Account{ID: "a"})
+	seedGroups(t, st, "a", "plain@g.us", "community@g.us", "announce@g.us", "sub@g.us")
+	for id, role := range map[string]string{
+		"community@g.us": model.GroupRoleParent,
+		"announce@g.us":  model.GroupRoleAnnouncement,
+		"sub@g.us":       model.GroupRoleSubgroup,
+	} {
+		parent := "community@g.us"
+		if role == model.GroupRoleParent {
+			parent = ""
+		}
+		if err := st.SetGroupCommunity(id, role, parent); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if n, err := s.countEligibleGroups("a", 48, 3); err != nil || n != 2 {
+		t.Fatalf("eligible = %d, %v; want 2 (plain, sub)", n, err)
+	}
+	if err := st.SetCommunityAnnouncementsEligible(true); err != nil {
+		t.Fatal(err)
+	}
+	if n, err := s.countEligibleGroups("a", 48, 3); err != nil || n != 3 {
+		t.Fatalf("eligible with announcements = %d, %v; want 3 (parent stays excluded)", n, err)
+	}
+}
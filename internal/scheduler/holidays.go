@@ -0,0 +1,57 @@
+package scheduler
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"promote/internal/model"
+	"promote/internal/sender"
+)
+
+// holiday hari libur pada tanggal kalender t (zona t; waktu scheduler sudah WIB). Jika tabel
+// gagal dibaca, hari dianggap bukan hari libur agar broadcast tidak berhenti diam-diam.
+func (s *Scheduler) holiday(t time.Time) (model.Holiday, bool) {
+	h, ok, err := s.Store.HolidayOn(t)
+	if err != nil {
+		log.Printf("[scheduler] holiday query err=%v", err)
+		return h, false
+	}
+	return h, ok
+}
+
+// runHolidayOutbox mengirim satu kiriman terjadwal sekali jalan yang jatuh tempo pada hari libur.
+// Item yang sudah pernah ditunda (batas grup, akun terputus, restart) tidak lagi tepat waktu
+// sehingga digeser ke hari berikutnya; item recurring dan pilihan scheduler tidak disentuh.
+func (s *Scheduler) runHolidayOutbox(ctx context.Context, now time.Time) bool {
+	s.processMutex.Lock()
+	defer s.processMutex.Unlock()
+	if sender.IsDryRun(ctx) {
+		return false
+	}
+	it, ok, err := s.Store.ClaimNextOutbox(time.Now(), model.OutboxOriginScheduled)
+	if err != nil || !ok {
+		if err != nil {
+			log.Printf("[scheduler] outbox claim err=%v", err)
+		}
+		return false
+	}
+	if it.LastError != "" {
+		next := time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, now.Location())
+		log.Printf("[scheduler] outbox=%d deferred earlier (%s), holiday -> deferring to %s", it.ID, it.LastError, next.Format("2006-01-02"))
+		if err := s.Store.DeferOutbox(it.ID, next, it.LastError); err != nil {
+			log.Printf("[scheduler] outbox=%d defer err=%v", it.ID, err)
+		}
+		return false
+	}
+	if ok, err = s.sendOutboxItem(ctx, it); !ok {
+		return false
+	}
+	if err != nil {
+		log.Printf("[scheduler] outbox=%d send failed account=%s group=%s err=%v", it.ID, it.AccountID, it.GroupID, err)
+	} else {
+		log.Printf("[scheduler] outbox=%d send success account=%s group=%s", it.ID, it.AccountID, it.GroupID)
+	}
+	s.sleepBetweenGroups(ctx)
+	return true
+}
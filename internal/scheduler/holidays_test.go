@@ -0,0 +1,103 @@
+package scheduler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"promote/internal/model"
+	"promote/internal/rng"
+	"promote/internal/storage/storagetest"
+)
+
+func TestScenarioHolidaySkipsBroadcasts(t *testing.T) {
+	s, st, fake := scenario(t)
+	s.loc, s.alwaysOn, s.Rand = time.UTC, true, rng.New(1)
+	storagetest.SeedAccount(t, st, storagetest.Account{ID: "a", DailyLimit: 10})
+	seedGroups(t, st, "a", "g1@g.us", "g2@g.us")
+	now := time.Now().UTC()
+	due := now.Add(-5 * time.Minute)
+	for _, d := range []time.Time{now, due} {
+		if err := st.SetHoliday(model.Holiday{Date: d.Format(model.HolidayLayout), Name: "Lebaran"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	tpl := storagetest.SeedTemplate(t, st, "rutin", "Promo rutin")
+	id, err := st.CreateRecurringSchedule(model.RecurringSchedule{Times: []string{due.Format("15:04")}, TemplateID: tpl, Enabled: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := st.DB.Exec(`UPDATE recurring_schedules SET created_at=? WHERE id=?`, now.AddDate(0, 0, -1), id); err != nil {
+		t.Fatal(err)
+	}
+
+	s.tick(context.Background())
+	if n := len(fake.Sent()); n != 0 {
+		t.Fatalf("sent on holiday = %d, want 0", n)
+	}
+	if items, err := st.ListOutbox(model.OutboxFilter{}); err != nil || len(items) != 0 {
+		t.Fatalf("outbox on holiday = %d err=%v, want 0", len(items), err)
+	}
+	if r, err := st.GetRecurringSchedule(id); err != nil || r.LastRunAt == nil {
+		t.Fatalf("recurring last_run_at = %v err=%v, want marked as skipped", r.LastRunAt, err)
+	}
+
+	for _, d := range []time.Time{now, due} {
+		_ = st.DeleteHoliday(d.Format(model.HolidayLayout))
+	}
+	s.tick(context.Background())
+	if n := len(fake.Sent()); n != 1 {
+		t.Fatalf("sent after holiday removed = %d, want 1", n)
+	}
+}
+
+func TestScenarioHolidaySendsOnlyOnTimeOneOffs(t *testing.T) {
+	s, st, fake := scenario(t)
+	s.loc, s.alwaysOn = time.UTC, true
+	storagetest.SeedAccount(t, st, storagetest.Account{ID: "a", DailyLimit: 10})
+	seedGroups(t, st, "a", "oneoff@g.us", "capped@g.us", "pick@g.us")
+	now := time.Now().UTC()
+	if err := st.SetHoliday(model.Holiday{Date: now.Format(model.HolidayLayout), Name: "Nyepi"}); err != nil {
+		t.Fatal(err)
+	}
+	content := []byte(`{"text_only":"Ucapan hari raya"}`)
+	due := now.Add(-time.Minute)
+	oneoff, err := st.EnqueueOutbox(model.OutboxItem{AccountID: "a", GroupID: "oneoff@g.us", Content: content,
+		Origin: model.OutboxOriginScheduled, ScheduledAt: due})
+	if err != nil {
+		t.Fatal(err)
+	}
+	pick, err := st.EnqueueOutbox(model.OutboxItem{AccountID: "a", GroupID: "pick@g.us", ScheduledAt: due})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Kiriman terjadwal yang kemarin ditunda karena batas grup tidak lagi tepat waktu.
+	capped, err := st.EnqueueOutbox(model.OutboxItem{AccountID: "a", GroupID: "capped@g.us", Content: content,
+		Origin: model.OutboxOriginScheduled, ScheduledAt: due.Add(-time.Hour)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := st.DB.Exec(`UPDATE outbox SET last_error='group send cap reached' WHERE id=?`, capped); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 3; i++ {
+		s.tick(context.Background())
+	}
+	if sent := fake.Sent(); len(sent) != 1 || sent[0].To != "oneoff@g.us" {
+		t.Fatalf("sent on holiday = %+v, want only the on-time one-off", sent)
+	}
+	if it, _ := st.GetOutboxItem(oneoff); it.Status != model.OutboxSent {
+		t.Fatalf("one-off status = %s, want sent", it.Status)
+	}
+	if it, _ := st.GetOutboxItem(pick); it.Status != model.OutboxPending || it.Attempts != 0 {
+		t.Fatalf("scheduler pick = %s attempts=%d, want untouched", it.Status, it.Attempts)
+	}
+	it, err := st.GetOutboxItem(capped)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tomorrow := time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, time.UTC); it.Status != model.OutboxPending || it.ScheduledAt.Before(tomorrow) {
+		t.Fatalf("deferred item = %s at %v, want pending past the holiday", it.Status, it.ScheduledAt)
+	}
+}
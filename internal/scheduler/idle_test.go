@@ -0,0 +1,102 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"promote/internal/model"
+	"promote/internal/storage"
+	"promote/internal/storage/storagetest"
+)
+
+func TestIdleWaitSleepsUntilNextWindow(t *testing.T) {
+	st := storagetest.Open(t)
+	loc := time.FixedZone("WIB", 7*3600)
+	var wins model.WeekWindows
+	for d := range wins {
+		wins[d] = [][2]int{{21*60 + 30, 23*60 + 30}, {23*60 + 40, 23*60 + 45}}
+	}
+	if err := st.SetSendWindows(wins); err != nil {
+		t.Fatal(err)
+	}
+	s := &Scheduler{Store: st, loc: loc, dm: dmConfig{StartHour: 9, EndHour: 20}}
+	s.refreshWindows()
+	tick := time.Duration(storage.DefaultSchedulerTiming.TickSec) * time.Second
+
+	day := time.Date(2026, 3, 2, 0, 0, 0, 0, loc)
+	if got := s.idleWait(day.Add(10 * time.Hour)); got != 15*time.Minute {
+		t.Fatalf("far from window: wait = %s, want max idle 15m", got)
+	}
+	if got := s.idleWait(day.Add(21*time.Hour + 25*time.Minute)); got != 5*time.Minute {
+		t.Fatalf("5m before window: wait = %s, want 5m", got)
+	}
+
+	s.scheduled = map[string]bool{"a": true}
+	if got := s.idleWait(day.Add(10 * time.Hour)); got != tick {
+		t.Fatalf("with schedules: wait = %s, want tick %s", got, tick)
+	}
+	s.scheduled = nil
+
+	// Jendela 5 menit dibuang jika min_window_min 15: jendela berikutnya setelah 23:30 adalah besok.
+	timing := storage.DefaultSchedulerTiming
+	timing.MinWindowMin, timing.MaxIdleMin = 15, 600
+	if err := st.SetSchedulerTiming(timing); err != nil {
+		t.Fatal(err)
+	}
+	s.refreshWindows()
+	if got := s.idleWait(day.Add(23*time.Hour + 35*time.Minute)); got != 600*time.Minute {
+		t.Fatalf("short window skipped: wait = %s, want 600m", got)
+	}
+
+	timing.IdleSleep = false
+	if err := st.SetSchedulerTiming(timing); err != nil {
+		t.Fatal(err)
+	}
+	s.refreshWindows()
+	if got := s.idleWait(day.Add(10 * time.Hour)); got != tick {
+		t.Fatalf("idle sleep off: wait = %s, want tick %s", got, tick)
+	}
+}
+
+func TestAccountWindowsOverrideGlobal(t *testing.T) {
+	st := storagetest.Open(t)
+	storagetest.SeedAccount(t, st, storagetest.Account{ID: "a"})
+	storagetest.SeedAccount(t, st, storagetest.Account{ID: "b"})
+	loc := time.FixedZone("WIB", 7*3600)
+	var global, custom model.WeekWindows
+	for d := range global {
+		global[d] = [][2]int{{21 * 60, 23 * 60}}
+		custom[d] = [][2]int{{10 * 60, 12 * 60}}
+	}
+	if err := st.SetSendWindows(global); err != nil {
+		t.Fatal(err)
+	}
+	if err := st.SetAccountSendWindow("b", custom); err != nil {
+		t.Fatal(err)
+	}
+	s := &Scheduler{Store: st, loc: loc, dm: dmConfig{StartHour: 9, EndHour: 20}}
+	s.refreshWindows()
+
+	day := time.Date(2026, 3, 2, 0, 0, 0, 0, loc)
+	morning, night := day.Add(11*time.Hour), day.Add(22*time.Hour)
+	if !s.inWindow(morning) || !s.inWindow(night) {
+		t.Fatal("scheduler must be open during global and per-account windows")
+	}
+	if !s.outsideAccountWindow("a", morning) || s.outsideAccountWindow("a", night) {
+		t.Fatal("account a must follow the global windows")
+	}
+	if s.outsideAccountWindow("b", morning) || !s.outsideAccountWindow("b", night) {
+		t.Fatal("account b must follow its own windows")
+	}
+	if next, ok := s.nextWindow(day.Add(8 * time.Hour)); !ok || !next.Start.Equal(day.Add(10*time.Hour)) {
+		t.Fatalf("next window = %v, %v; want account b at 10:00", next.Start, ok)
+	}
+
+	if _, err := st.DeleteAccountSendWindow("b"); err != nil {
+		t.Fatal(err)
+	}
+	s.refreshWindows()
+	if s.inWindow(morning) || !s.inWindow(night) {
+		t.Fatal("without overrides the scheduler must follow only the global windows")
+	}
+}
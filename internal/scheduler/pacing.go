@@ -0,0 +1,82 @@
+package scheduler
+
+import (
+	"time"
+
+	"promote/internal/model"
+)
+
+// paceJitter variasi acak jarak pacing (±25%) agar kiriman tidak berirama tetap.
+const paceJitter = 0.25
+
+// paceWait sisa tunggu akun sebelum kiriman grup berikutnya menurut pacing even; 0 jika boleh
+// kirim sekarang atau pacing fast.
+func (s *Scheduler) paceWait(accountID string, now time.Time) time.Duration {
+	if s.timing.Pacing != model.PacingEven {
+		return 0
+	}
+	if d := s.paceNext[accountID].Sub(now); d > 0 {
+		return d
+	}
+	return 0
+}
+
+// pace menjadwalkan kiriman grup berikutnya akun setelah satu kiriman sukses: sisa kuota
+// (remaining, termasuk kiriman ini) disebar merata atas sisa waktu jendela yang terbuka, dengan
+// jitter. Saat porsi jendela (window budget) yang membatasi, hanya sisa jendela berjalan yang
+// dihitung. Catatan hanya di memori; setelah restart kiriman pertama tiap akun langsung jalan.
+func (s *Scheduler) pace(accountID string, now time.Time, remaining int, windowOnly bool) {
+	if s.timing.Pacing != model.PacingEven || remaining <= 0 {
+		return
+	}
+	open := s.openAhead(accountID, now, windowOnly)
+	if open <= 0 {
+		return
+	}
+	gap := float64(open) / float64(remaining)
+	gap *= 1 - paceJitter + 2*paceJitter*s.Rand.Float64()
+	if s.paceNext == nil {
+		s.paceNext = map[string]time.Time{}
+	}
+	s.paceNext[accountID] = now.Add(time.Duration(gap))
+}
+
+// quotaLeft sisa kuota akun sebelum kiriman berikutnya (untuk pace) dan apakah porsi jendela
+// yang membatasi; dipakai untuk pengirim hasil rotasi pool yang bukan akun pemilik.
+func (s *Scheduler) quotaLeft(now time.Time, accountID string) (remaining int, windowOnly bool) {
+	limits, err := s.dailyLimits()
+	if err != nil {
+		return 0, false
+	}
+	limit := limits[accountID]
+	if limit <= 0 {
+		limit = 100
+	}
+	sent, err := s.countSentTodayForAccount(accountID)
+	if err != nil {
+		return 0, false
+	}
+	wcap := s.windowCap(now, accountID, limit)
+	return wcap - int(sent), wcap < limit
+}
+
+// openAhead sisa waktu kirim akun dari now sampai pergantian hari kuota (atau akhir jendela
+// berjalan jika windowOnly). Dengan alwaysOn seluruh sisa hari kuota dihitung terbuka.
+func (s *Scheduler) openAhead(accountID string, now time.Time, windowOnly bool) time.Duration {
+	_, dayEnd := s.Store.Day.Today()
+	if s.alwaysOn {
+		return dayEnd.Sub(now)
+	}
+	s.windowsMu.RLock()
+	defer s.windowsMu.RUnlock()
+	w := s.windowsFor(accountID)
+	if windowOnly {
+		if o, ok := w.Next(now); ok && !o.Start.After(now) {
+			if o.End.Before(dayEnd) {
+				return o.End.Sub(now)
+			}
+			return dayEnd.Sub(now)
+		}
+	}
+	return w.OpenBetween(now, dayEnd)
+}
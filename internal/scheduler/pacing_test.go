@@ -0,0 +1,64 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"promote/internal/model"
+	"promote/internal/storage/storagetest"
+)
+
+func TestScenarioEvenPacingSpreadsSends(t *testing.T) {
+	s, st, _ := scenario(t)
+	s.alwaysOn = true
+	s.timing.Pacing = model.PacingEven
+	storagetest.SeedAccount(t, st, storagetest.Account{ID: "a", DailyLimit: 10})
+	seedGroups(t, st, "a", "g1@g.us", "g2@g.us", "g3@g.us", "g4@g.us")
+
+	before := time.Now()
+	runCycles(t, s, 3)
+	if n := logCount(t, st, "sent"); n != 1 {
+		t.Fatalf("sent = %d, want 1 (next send paced)", n)
+	}
+	_, dayEnd := st.Day.Today()
+	gap := float64(dayEnd.Sub(before)) / 10
+	if d := float64(s.paceNext["a"].Sub(before)); d < gap*(1-paceJitter)-float64(time.Second) || d > gap*(1+paceJitter)+float64(time.Second) {
+		t.Fatalf("next paced send in %s, want about %s", time.Duration(d), time.Duration(gap))
+	}
+
+	s.paceNext["a"] = time.Now().Add(-time.Second)
+	runCycles(t, s, 1)
+	if n := logCount(t, st, "sent"); n != 2 {
+		t.Fatalf("sent = %d, want 2 once pace is due", n)
+	}
+
+	s.timing.Pacing = model.PacingFast
+	runCycles(t, s, 2)
+	if n := logCount(t, st, "sent"); n != 4 {
+		t.Fatalf("fast pacing: sent = %d, want 4", n)
+	}
+}
+
+func TestOpenAheadCountsRemainingWindows(t *testing.T) {
+	st := storagetest.Open(t)
+	loc := time.FixedZone("WIB", 7*3600)
+	var wins model.WeekWindows
+	for d := range wins {
+		wins[d] = [][2]int{{60, 120}, {21 * 60, 23 * 60}}
+	}
+	if err := st.SetSendWindows(wins); err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2026, 3, 2, 1, 30, 0, 0, loc)
+	st.Day.Loc, st.Day.ResetHour = loc, 0
+	st.Day.Now = func() time.Time { return now }
+	s := &Scheduler{Store: st, loc: loc}
+	s.refreshWindows()
+	// Sisa jendela 01:00-02:00 ditambah 21:00-23:00 sebelum hari kuota berganti.
+	if got := s.openAhead("a", now, false); got != 150*time.Minute {
+		t.Fatalf("open ahead = %s, want 2h30m", got)
+	}
+	if got := s.openAhead("a", now, true); got != 30*time.Minute {
+		t.Fatalf("open ahead (current window) = %s, want 30m", got)
+	}
+}
@@ -0,0 +1,67 @@
+package scheduler
+
+import (
+	"log"
+	"time"
+)
+
+// recurringGrace batas keterlambatan jadwal berulang; jadwal yang terlewat lebih lama (mis.
+// selama scheduler mati atau dijeda) dilewati, bukan dikirim telat.
+const recurringGrace = 30 * time.Minute
+
+// runRecurring mengantrekan kiriman jadwal berulang yang jatuh tempo ke outbox; pengiriman
+// dilakukan drain outbox dengan cek risiko dan cooldown biasa. Jadwal sebelum aturan dibuat
+// atau yang sudah dijalankan tidak diulang; jadwal pada hari libur dicatat jalan tanpa kiriman.
+func (s *Scheduler) runRecurring(now time.Time) {
+	list, err := s.Store.ListRecurringSchedules(true)
+	if err != nil {
+		log.Printf("[scheduler] recurring query err=%v", err)
+		return
+	}
+	for _, r := range list {
+		prev := r.PrevRun(now)
+		since := r.CreatedAt
+		if r.LastRunAt != nil {
+			since = *r.LastRunAt
+		}
+		if prev.IsZero() || !prev.After(since) {
+			continue
+		}
+		if now.Sub(prev) > recurringGrace {
+			log.Printf("[scheduler] recurring=%s MISSED run=%s (late %s)", r.ID, prev.Format("Mon 15:04"),
+				now.Sub(prev).Round(time.Second))
+			if err := s.Store.MarkRecurringRun(r.ID, prev); err != nil {
+				log.Printf("[scheduler] recurring=%s mark err=%v", r.ID, err)
+			}
+			continue
+		}
+		if h, ok := s.holiday(prev); ok {
+			log.Printf("[scheduler] recurring=%s HOLIDAY run=%s date=%s name=%q -> skipped", r.ID, prev.Format("Mon 15:04"), h.Date, h.Name)
+			if err := s.Store.MarkRecurringRun(r.ID, prev); err != nil {
+				log.Printf("[scheduler] recurring=%s mark err=%v", r.ID, err)
+			}
+			continue
+		}
+		n, err := s.Store.EnqueueRecurring(r, prev)
+		if err != nil {
+			log.Printf("[scheduler] recurring=%s enqueue err=%v", r.ID, err)
+			continue
+		}
+		log.Printf("[scheduler] recurring=%s name=%q run=%s queued=%d", r.ID, r.Name, prev.Format("Mon 15:04"), n)
+	}
+}
+
+// nextRecurring waktu jadwal berulang aktif berikutnya setelah now; ok=false jika tidak ada.
+func (s *Scheduler) nextRecurring(now time.Time) (time.Time, bool) {
+	list, err := s.Store.ListRecurringSchedules(true)
+	if err != nil {
+		return time.Time{}, false
+	}
+	var next time.Time
+	for _, r := range list {
+		if at := r.NextRun(now); !at.IsZero() && (next.IsZero() || at.Before(next)) {
+			next = at
+		}
+	}
+	return next, !next.IsZero()
+}
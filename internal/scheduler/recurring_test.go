@@ -0,0 +1,69 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"promote/internal/model"
+	"promote/internal/storage/storagetest"
+)
+
+func TestRecurringQueuesTaggedGroupsOnce(t *testing.T) {
+	s, st, _ := scenario(t)
+	s.loc = time.UTC
+	storagetest.SeedAccount(t, st, storagetest.Account{ID: "a"})
+	storagetest.SeedAccount(t, st, storagetest.Account{ID: "b"})
+	seedGroups(t, st, "a", "jualan-a@g.us", "lain@g.us")
+	seedGroups(t, st, "b", "jualan-b@g.us")
+	for _, g := range []string{"jualan-a@g.us", "jualan-b@g.us"} {
+		if err := st.SetGroupTags(g, []string{"jualan"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	tpl := storagetest.SeedTemplate(t, st, "rutin", "Promo rutin")
+	now := time.Now().UTC()
+	rule := func(ago time.Duration) string {
+		id, err := st.CreateRecurringSchedule(model.RecurringSchedule{
+			Times:      []string{now.Add(-ago).Format("15:04")},
+			TemplateID: tpl,
+			Targeting:  model.Targeting{IncludeTags: []string{"jualan"}},
+			Enabled:    true,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := st.DB.Exec(`UPDATE recurring_schedules SET created_at=? WHERE id=?`, now.AddDate(0, 0, -1), id); err != nil {
+			t.Fatal(err)
+		}
+		return id
+	}
+	due := rule(5 * time.Minute)
+	late := rule(2 * time.Hour)
+
+	s.runRecurring(now)
+	s.runRecurring(now)
+
+	items, err := st.ListOutbox(model.OutboxFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 2 {
+		t.Fatalf("outbox items = %d, want 2 (tagged groups, queued once)", len(items))
+	}
+	for _, it := range items {
+		if it.TemplateID != tpl || (it.GroupID != "jualan-a@g.us" && it.GroupID != "jualan-b@g.us") {
+			t.Fatalf("unexpected outbox item %+v", it)
+		}
+	}
+	r, err := st.GetRecurringSchedule(due)
+	if err != nil || r.LastRunAt == nil {
+		t.Fatalf("due rule last_run_at = %v err=%v", r.LastRunAt, err)
+	}
+	if next := r.NextRun(now); !next.Equal(r.LastRunAt.UTC().AddDate(0, 0, 1)) {
+		t.Fatalf("next run = %v, want a day after %v", next, r.LastRunAt)
+	}
+	// Jadwal yang terlewat jauh dilewati, tidak dikirim telat.
+	if r, err := st.GetRecurringSchedule(late); err != nil || r.LastRunAt == nil {
+		t.Fatalf("late rule last_run_at = %v err=%v, want marked as missed", r.LastRunAt, err)
+	}
+}
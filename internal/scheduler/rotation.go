@@ -0,0 +1,176 @@
+package scheduler
+
+import (
+	"log"
+	"math"
+	"sort"
+	"time"
+
+	"promote/internal/model"
+)
+
+// rotationStrategy membaca setting account_rotation; jika gagal dibaca, strategi terakhir dipakai.
+func (s *Scheduler) rotationStrategy() string {
+	r, err := s.Store.AccountRotation()
+	if err != nil {
+		log.Printf("[scheduler] account rotation err=%v (keeping previous)", err)
+	} else {
+		s.rotation = r
+	}
+	if s.rotation.Strategy == "" {
+		return model.RotationRandom
+	}
+	return s.rotation.Strategy
+}
+
+// orderAccounts urutan giliran akun menurut strategi rotasi. Akun diacak dulu sehingga akun
+// dengan kunci urut yang sama (mis. belum pernah kirim) tetap bergantian.
+func (s *Scheduler) orderAccounts(accs []accountLite, strategy string) []accountLite {
+	s.Rand.Shuffle(len(accs), func(i, j int) { accs[i], accs[j] = accs[j], accs[i] })
+	switch strategy {
+	case model.RotationRoundRobin:
+		// Mulai dari akun sesudah akun yang terakhir mendapat giliran kirim
+		sort.Slice(accs, func(i, j int) bool { return accs[i].ID < accs[j].ID })
+		k := sort.Search(len(accs), func(i int) bool { return accs[i].ID > s.rrLast })
+		return append(accs[k:len(accs):len(accs)], accs[:k]...)
+	case model.RotationLRU:
+		last, err := s.Store.LastSentByAccount()
+		if err != nil {
+			log.Printf("[scheduler] rotation last sent err=%v (random order)", err)
+			return accs
+		}
+		sort.SliceStable(accs, func(i, j int) bool { return last[accs[i].ID].Before(last[accs[j].ID]) })
+	case model.RotationWeighted:
+		sent, err := s.Store.SentTodayByAccount()
+		if err != nil {
+			log.Printf("[scheduler] rotation sent today err=%v (random order)", err)
+			return accs
+		}
+		keys := make(map[string]float64, len(accs))
+		for _, a := range accs {
+			limit := a.DailyLimit
+			if limit <= 0 {
+				limit = 100
+			}
+			keys[a.ID] = s.weightedKey(limit - sent[a.ID])
+		}
+		sort.SliceStable(accs, func(i, j int) bool { return keys[accs[i].ID] > keys[accs[j].ID] })
+	}
+	return accs
+}
+
+// weightedKey kunci urut acak berbobot (u^(1/w), makin besar makin dulu); bobot <= 0 selalu
+// paling akhir.
+func (s *Scheduler) weightedKey(weight int) float64 {
+	if weight <= 0 {
+		return -1
+	}
+	return math.Pow(s.Rand.Float64(), 1/float64(weight))
+}
+
+// rotateSender memilih akun pengirim untuk grup yang dipilih akun owner. Jika owner anggota pool
+// dengan rotasi aktif, dipilih akun se-pool (yang juga anggota grup) yang terhubung dan belum
+// mencapai limit hariannya (atau porsi jendela berjalan bila limit dibagi per jendela), menurut
+// strategi: round_robin bergiliran menurut ID sesudah pengirim terakhir ke grup, weighted acak
+// berbobot sisa kuota, selain itu yang paling lama tidak mengirim ke grup ini.
+// Fallback ke owner jika tidak ada kandidat lain.
+func (s *Scheduler) rotateSender(now time.Time, ownerID string, ownerLimit int, groupID, strategy string) string {
+	cands, err := s.Store.PoolRotationCandidates(ownerID, groupID)
+	if err != nil {
+		log.Printf("[scheduler] rotation candidates account=%s group=%s err=%v", ownerID, groupID, err)
+		return ownerID
+	}
+	if len(cands) < 2 {
+		return ownerID
+	}
+	last, err := s.Store.LastSendersForGroup(groupID)
+	if err != nil {
+		log.Printf("[scheduler] rotation last senders group=%s err=%v", groupID, err)
+		return ownerID
+	}
+	limits, err := s.dailyLimits()
+	if err != nil {
+		return ownerID
+	}
+	limits[ownerID] = ownerLimit
+
+	type candidate struct {
+		id   string
+		at   time.Time
+		left int
+	}
+	var elig []candidate
+	lastID, lastAt := "", time.Time{}
+	for _, id := range cands {
+		if at, ok := last[id]; ok && at.After(lastAt) {
+			lastID, lastAt = id, at
+		}
+		limit := limits[id]
+		if limit <= 0 {
+			limit = 100
+		}
+		if id != ownerID {
+			if err := s.Sender.EnsureConnected(id); err != nil {
+				continue
+			}
+			if s.outsideAccountWindow(id, now) {
+				continue
+			}
+		}
+		sent, err := s.countSentTodayForAccount(id)
+		if err != nil && id != ownerID {
+			continue
+		}
+		left := s.windowCap(now, id, limit) - int(sent)
+		if left <= 0 && id != ownerID {
+			continue
+		}
+		// Belum pernah kirim ke grup ini: waktu nol, prioritas tertinggi
+		elig = append(elig, candidate{id: id, at: last[id], left: left})
+	}
+	if len(elig) == 0 {
+		return ownerID
+	}
+
+	best := elig[0]
+	switch strategy {
+	case model.RotationRoundRobin:
+		sort.Slice(elig, func(i, j int) bool { return elig[i].id < elig[j].id })
+		best = elig[0]
+		for _, c := range elig {
+			if c.id > lastID {
+				best = c
+				break
+			}
+		}
+	case model.RotationWeighted:
+		bestKey := -2.0
+		for _, c := range elig {
+			if k := s.weightedKey(c.left); k > bestKey {
+				best, bestKey = c, k
+			}
+		}
+	default:
+		for _, c := range elig[1:] {
+			if c.at.Before(best.at) {
+				best = c
+			}
+		}
+	}
+	if best.id != ownerID {
+		log.Printf("[scheduler] ROTATE_SENDER group=%s owner=%s sender=%s strategy=%s", groupID, ownerID, best.id, strategy)
+	}
+	return best.id
+}
+
+func (s *Scheduler) dailyLimits() (map[string]int, error) {
+	accs, err := s.listEnabledAccounts()
+	if err != nil {
+		return nil, err
+	}
+	out := make(map[string]int, len(accs))
+	for _, a := range accs {
+		out[a.ID] = a.DailyLimit
+	}
+	return out, nil
+}
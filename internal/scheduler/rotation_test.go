@@ -0,0 +1,113 @@
+package scheduler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"promote/internal/model"
+	"promote/internal/rng"
+	"promote/internal/sender"
+	"promote/internal/storage"
+	"promote/internal/storage/storagetest"
+)
+
+func lastSender(t *testing.T, st *storage.Store) string {
+	t.Helper()
+	var acc string
+	if err := st.DB.QueryRow(`SELECT account_id FROM logs WHERE status='sent' ORDER BY id DESC LIMIT 1`).Scan(&acc); err != nil {
+		t.Fatal(err)
+	}
+	return acc
+}
+
+func TestScenarioRoundRobinRotation(t *testing.T) {
+	s, st, _ := scenario(t)
+	s.Rand = rng.New(1)
+	for _, id := range []string{"b", "c", "a"} {
+		storagetest.SeedAccount(t, st, storagetest.Account{ID: id, DailyLimit: 10})
+		seedGroups(t, st, id, id+"1@g.us", id+"2@g.us")
+	}
+	if err := st.SetAccountRotation(model.AccountRotation{Strategy: model.RotationRoundRobin}); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"a", "b", "c", "a"} {
+		runCycles(t, s, 1)
+		if got := lastSender(t, st); got != want {
+			t.Fatalf("round robin sender = %s, want %s", got, want)
+		}
+	}
+}
+
+func TestScenarioLRURotation(t *testing.T) {
+	s, st, _ := scenario(t)
+	s.Rand = rng.New(1)
+	for _, id := range []string{"a", "b", "c"} {
+		storagetest.SeedAccount(t, st, storagetest.Account{ID: id, DailyLimit: 10})
+		seedGroups(t, st, id, id+"1@g.us", id+"2@g.us")
+	}
+	storagetest.SeedLog(t, st, "a", "a1@g.us", "sent", time.Now().Add(-1*time.Hour))
+	storagetest.SeedLog(t, st, "b", "b1@g.us", "sent", time.Now().Add(-3*time.Hour))
+	storagetest.SeedLog(t, st, "c", "c1@g.us", "sent", time.Now().Add(-2*time.Hour))
+	if err := st.SetAccountRotation(model.AccountRotation{Strategy: model.RotationLRU}); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"b", "c", "a"} {
+		runCycles(t, s, 1)
+		if got := lastSender(t, st); got != want {
+			t.Fatalf("lru sender = %s, want %s", got, want)
+		}
+	}
+}
+
+func TestWeightedRotationFavorsRemainingQuota(t *testing.T) {
+	st := storagetest.Open(t)
+	s := &Scheduler{Store: st, Rand: rng.New(1)}
+	first := map[string]int{}
+	for i := 0; i < 200; i++ {
+		accs := []accountLite{{ID: "low", DailyLimit: 2}, {ID: "high", DailyLimit: 50}}
+		first[s.orderAccounts(accs, model.RotationWeighted)[0].ID]++
+	}
+	if first["high"] < 170 || first["low"] == 0 {
+		t.Fatalf("weighted first picks = %v, want mostly high but low still chosen", first)
+	}
+}
+
+func TestScenarioPoolSenderCarriesPacingAndTurn(t *testing.T) {
+	s, st, _ := scenario(t)
+	s.alwaysOn, s.Rand = true, rng.New(1)
+	s.timing.Pacing = model.PacingEven
+	for _, id := range []string{"a", "b"} {
+		storagetest.SeedAccount(t, st, storagetest.Account{ID: id, DailyLimit: 10})
+	}
+	seedGroups(t, st, "a", "g1@g.us")
+	pool, err := st.CreatePool("tim", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []string{"a", "b"} {
+		if _, err := st.SetAccountPool(id, pool); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := st.AddGroupMember("b", "g1@g.us"); err != nil {
+		t.Fatal(err)
+	}
+	storagetest.SeedLog(t, st, "a", "g1@g.us", "sent", time.Now().Add(-72*time.Hour))
+
+	// Dry-run tidak memakai kuota: giliran rotasi dan pacing tidak bergeser.
+	if err := s.processOneSend(sender.WithDryRun(context.Background()), time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	if s.rrLast != "" || len(s.paceNext) != 0 {
+		t.Fatalf("dry-run moved rrLast=%q paceNext=%v", s.rrLast, s.paceNext)
+	}
+
+	runCycles(t, s, 1)
+	if got := lastSender(t, st); got != "b" {
+		t.Fatalf("pool sender = %s, want b", got)
+	}
+	if _, ok := s.paceNext["a"]; ok || s.paceNext["b"].IsZero() || s.rrLast != "b" {
+		t.Fatalf("paceNext=%v rrLast=%q, want both recorded for sender b", s.paceNext, s.rrLast)
+	}
+}
@@ -0,0 +1,415 @@
+package scheduler
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"promote/internal/model"
+	"promote/internal/rng"
+	"promote/internal/sender"
+	"promote/internal/sender/sendertest"
+	"promote/internal/storage"
+	"promote/internal/storage/storagetest"
+)
+
+// scenario menyiapkan scheduler+sender nyata di atas database sementara dan transport palsu.
+func scenario(t *testing.T) (*Scheduler, *storage.Store, *sendertest.Fake) {
+	t.Helper()
+	st := storagetest.Open(t)
+	fake := sendertest.New()
+	snd := sender.New(st, nil)
+	fake.Install(snd)
+	storagetest.SeedTemplate(t, st, "promo", "Halo grup!")
+	return &Scheduler{Store: st, Sender: snd, cooldownHr: 48, riskThreshold: 3}, st, fake
+}
+
+func runCycles(t *testing.T, s *Scheduler, n int) {
+	t.Helper()
+	for i := 0; i < n; i++ {
+		if err := s.processOneSend(context.Background(), time.Now()); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func seedGroups(t *testing.T, st *storage.Store, accountID string, ids ...string) {
+	t.Helper()
+	for _, id := range ids {
+		storagetest.SeedGroup(t, st, storagetest.Group{ID: id, AccountID: accountID, Enabled: true})
+	}
+}
+
+func logCount(t *testing.T, st *storage.Store, status string) int {
+	t.Helper()
+	var n int
+	if err := st.DB.QueryRow(`SELECT COUNT(*) FROM logs WHERE status=?`, status).Scan(&n); err != nil {
+		t.Fatal(err)
+	}
+	return n
+}
+
+func TestScenarioDailyLimitStopsAccount(t *testing.T) {
+	s, st, fake := scenario(t)
+	storagetest.SeedAccount(t, st, storagetest.Account{ID: "a", DailyLimit: 2})
+	seedGroups(t, st, "a", "g1@g.us", "g2@g.us", "g3@g.us", "g4@g.us")
+
+	runCycles(t, s, 4)
+
+	sent := fake.Sent()
+	if len(sent) != 2 {
+		t.Fatalf("sent = %d, want 2 (daily limit)", len(sent))
+	}
+	if sent[0].To == sent[1].To {
+		t.Fatalf("both sends went to %s", sent[0].To)
+	}
+	if sent[0].Kind != "text" || sent[0].Text != "Halo grup!" {
+		t.Fatalf("sent[0] = %+v", sent[0])
+	}
+	if n := logCount(t, st, "sent"); n != 2 {
+		t.Fatalf("sent logs = %d, want 2", n)
+	}
+}
+
+func TestScenarioCooldownBlocksResend(t *testing.T) {
+	s, st, fake := scenario(t)
+	storagetest.SeedAccount(t, st, storagetest.Account{ID: "a"})
+	seedGroups(t, st, "a", "g1@g.us")
+
+	runCycles(t, s, 3)
+	if n := len(fake.SentTo("g1@g.us")); n != 1 {
+		t.Fatalf("sends during cooldown = %d, want 1", n)
+	}
+
+	if _, err := st.DB.Exec(`UPDATE groups SET last_sent_at=? WHERE id='g1@g.us'`,
+		storagetest.Ago(49*time.Hour).UTC().Format(storagetest.SQLTime)); err != nil {
+		t.Fatal(err)
+	}
+	runCycles(t, s, 1)
+	if n := len(fake.SentTo("g1@g.us")); n != 2 {
+		t.Fatalf("sends after cooldown = %d, want 2", n)
+	}
+}
+
+func TestScenarioTransientFailureIsRetried(t *testing.T) {
+	s, st, fake := scenario(t)
+	storagetest.SeedAccount(t, st, storagetest.Account{ID: "a"})
+	seedGroups(t, st, "a", "g1@g.us")
+	acc := fake.Account("a").FailNext(sendertest.ErrTimeout)
+
+	runCycles(t, s, 1)
+
+	if n := len(fake.Sent()); n != 1 {
+		t.Fatalf("sent = %d, want 1", n)
+	}
+	if n := acc.Attempts(); n != 2 {
+		t.Fatalf("attempts = %d, want 2", n)
+	}
+	if fake.Slept() == 0 {
+		t.Fatal("retry did not back off")
+	}
+	if n := logCount(t, st, "sent"); n != 1 {
+		t.Fatalf("sent logs = %d, want 1", n)
+	}
+}
+
+func TestScenarioRateLimitThrottlesAccount(t *testing.T) {
+	s, st, fake := scenario(t)
+	storagetest.SeedAccount(t, st, storagetest.Account{ID: "a"})
+	seedGroups(t, st, "a", "g1@g.us", "g2@g.us", "g3@g.us")
+	acc := fake.Account("a").SetRateLimit(1, time.Hour)
+
+	runCycles(t, s, 3)
+
+	if n := len(fake.Sent()); n != 1 {
+		t.Fatalf("sent = %d, want 1", n)
+	}
+	// tanpa retry: satu kiriman sukses + satu percobaan yang kena throttle
+	if n := acc.Attempts(); n != 2 {
+		t.Fatalf("attempts = %d, want 2", n)
+	}
+	if n := logCount(t, st, "throttled"); n != 1 {
+		t.Fatalf("throttled logs = %d, want 1", n)
+	}
+	var risky int
+	if err := st.DB.QueryRow(`SELECT COUNT(*) FROM groups WHERE risk_score > 0`).Scan(&risky); err != nil {
+		t.Fatal(err)
+	}
+	if risky != 0 {
+		t.Fatalf("groups with risk = %d, want 0", risky)
+	}
+	until, err := st.AccountThrottledUntil("a")
+	if err != nil || until == nil {
+		t.Fatalf("throttled_until = %v, %v; want set", until, err)
+	}
+}
+
+func TestScenarioSkipsObserverAndUnpairedAccounts(t *testing.T) {
+	s, st, fake := scenario(t)
+	storagetest.SeedAccount(t, st, storagetest.Account{ID: "obs", Observer: true})
+	storagetest.SeedAccount(t, st, storagetest.Account{ID: "new"})
+	seedGroups(t, st, "obs", "g1@g.us")
+	seedGroups(t, st, "new", "g2@g.us")
+	fake.Account("new").SetPaired(false)
+
+	runCycles(t, s, 2)
+
+	if n := len(fake.Sent()); n != 0 {
+		t.Fatalf("sent = %d, want 0", n)
+	}
+	if n := fake.Account("obs").Attempts() + fake.Account("new").Attempts(); n != 0 {
+		t.Fatalf("attempts = %d, want 0", n)
+	}
+}
+
+func TestScenarioSameSeedSamePlan(t *testing.T) {
+	plan := func(seed int64) []string {
+		s, st, fake := scenario(t)
+		s.Rand, s.Sender.Rand = rng.New(seed), rng.New(seed)
+		storagetest.SeedTemplate(t, st, "promo 2", "Diskon hari ini")
+		storagetest.SeedTemplate(t, st, "promo 3", "Stok baru datang")
+		storagetest.SeedAccount(t, st, storagetest.Account{ID: "a"})
+		storagetest.SeedAccount(t, st, storagetest.Account{ID: "b"})
+		seedGroups(t, st, "a", "a1@g.us", "a2@g.us", "a3@g.us", "a4@g.us")
+		seedGroups(t, st, "b", "b1@g.us", "b2@g.us", "b3@g.us", "b4@g.us")
+		runCycles(t, s, 6)
+		var out []string
+		for _, m := range fake.Sent() {
+			out = append(out, m.To+" "+m.Text)
+		}
+		return out
+	}
+	first, again := plan(42), plan(42)
+	if len(first) != 6 {
+		t.Fatalf("sent = %d, want 6", len(first))
+	}
+	if strings.Join(first, "\n") != strings.Join(again, "\n") {
+		t.Fatalf("same seed, different plans:\n%v\n%v", first, again)
+	}
+}
+
+func TestScenarioPauseHaltsSends(t *testing.T) {
+	s, st, fake := scenario(t)
+	s.loc, s.alwaysOn, s.Rand = time.UTC, true, rng.New(1)
+	storagetest.SeedAccount(t, st, storagetest.Account{ID: "a", DailyLimit: 10})
+	seedGroups(t, st, "a", "g1@g.us", "g2@g.us")
+
+	if err := st.SetSchedulerPause(model.SchedulerPause{Paused: true, Reason: "incident"}); err != nil {
+		t.Fatal(err)
+	}
+	s.tick(context.Background())
+	if n := len(fake.Sent()); n != 0 {
+		t.Fatalf("sent while paused = %d, want 0", n)
+	}
+
+	if err := st.SetSchedulerPause(model.SchedulerPause{}); err != nil {
+		t.Fatal(err)
+	}
+	s.tick(context.Background())
+	if n := len(fake.Sent()); n != 1 {
+		t.Fatalf("sent after resume = %d, want 1", n)
+	}
+}
+
+func TestScenarioDryRunLogsWithoutSending(t *testing.T) {
+	s, st, fake := scenario(t)
+	s.loc, s.alwaysOn, s.Rand = time.UTC, true, rng.New(1)
+	storagetest.SeedAccount(t, st, storagetest.Account{ID: "a", DailyLimit: 10})
+	seedGroups(t, st, "a", "g1@g.us")
+	if err := st.SetSchedulerDryRun(true); err != nil {
+		t.Fatal(err)
+	}
+
+	s.tick(context.Background())
+	if n := len(fake.Sent()); n != 0 {
+		t.Fatalf("sent in dry-run = %d, want 0", n)
+	}
+	if n := logCount(t, st, sender.StatusWouldSend); n != 1 {
+		t.Fatalf("would_send logs = %d, want 1", n)
+	}
+	var tpl, preview string
+	if err := st.DB.QueryRow(`SELECT COALESCE(template_id,''), message_preview FROM logs WHERE status=?`,
+		sender.StatusWouldSend).Scan(&tpl, &preview); err != nil {
+		t.Fatal(err)
+	}
+	if tpl == "" || !strings.Contains(preview, "Halo grup!") {
+		t.Fatalf("would_send template=%q preview=%q, want template and text", tpl, preview)
+	}
+	var reserved int
+	if err := st.DB.QueryRow(`SELECT COUNT(*) FROM groups WHERE last_sent_at IS NOT NULL`).Scan(&reserved); err != nil {
+		t.Fatal(err)
+	}
+	if reserved != 0 {
+		t.Fatal("dry-run must not leave the group cooldown reserved")
+	}
+
+	if err := st.SetSchedulerDryRun(false); err != nil {
+		t.Fatal(err)
+	}
+	s.tick(context.Background())
+	if n := len(fake.Sent()); n != 1 {
+		t.Fatalf("sent after dry-run off = %d, want 1", n)
+	}
+}
+
+func TestScenarioSendsGoThroughOutbox(t *testing.T) {
+	s, st, fake := scenario(t)
+	storagetest.SeedAccount(t, st, storagetest.Account{ID: "a", DailyLimit: 10})
+	seedGroups(t, st, "a", "g1@g.us", "queued@g.us")
+	if _, err := st.DB.Exec(`UPDATE groups SET last_sent_at=CURRENT_TIMESTAMP WHERE id='queued@g.us'`); err != nil {
+		t.Fatal(err)
+	}
+	// Item tertinggal dari sebelum restart dikirim dulu, walau grupnya sedang cooldown.
+	if _, err := st.EnqueueOutbox(model.OutboxItem{AccountID: "a", GroupID: "queued@g.us"}); err != nil {
+		t.Fatal(err)
+	}
+
+	runCycles(t, s, 2)
+
+	sent := fake.Sent()
+	if len(sent) != 2 || sent[0].To != "queued@g.us" || sent[1].To != "g1@g.us" {
+		t.Fatalf("sent = %+v, want queued item then g1", sent)
+	}
+	items, err := st.ListOutbox(model.OutboxFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 2 {
+		t.Fatalf("outbox items = %d, want 2", len(items))
+	}
+	for _, it := range items {
+		if it.Status != model.OutboxSent {
+			t.Fatalf("outbox item %d status = %s, want sent", it.ID, it.Status)
+		}
+	}
+}
+
+func TestSchedulerSendClaimsItsOwnOutboxItem(t *testing.T) {
+	s, st, fake := scenario(t)
+	storagetest.SeedAccount(t, st, storagetest.Account{ID: "a", DailyLimit: 10})
+	seedGroups(t, st, "a", "g1@g.us", "urgent@g.us")
+	// Item prioritas tinggi yang masuk setelah drain awal tidak boleh menyerobot pilihan scheduler.
+	urgent, err := st.EnqueueOutbox(model.OutboxItem{AccountID: "a", GroupID: "urgent@g.us", Priority: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.send(context.Background(), "a", "g1@g.us", ""); err != nil {
+		t.Fatal(err)
+	}
+	if sent := fake.Sent(); len(sent) != 1 || sent[0].To != "g1@g.us" {
+		t.Fatalf("sent = %+v, want only the scheduler pick g1", sent)
+	}
+	if it, _ := st.GetOutboxItem(urgent); it.Status != model.OutboxPending {
+		t.Fatalf("urgent item status = %s, want pending", it.Status)
+	}
+}
+
+func TestScenarioScheduledSendRunsWhenDue(t *testing.T) {
+	s, st, fake := scenario(t)
+	storagetest.SeedAccount(t, st, storagetest.Account{ID: "a", DailyLimit: 10})
+	seedGroups(t, st, "a", "now@g.us", "later@g.us")
+	content := []byte(`{"text_only":"Promo kilat"}`)
+	if _, err := st.EnqueueOutbox(model.OutboxItem{AccountID: "a", GroupID: "now@g.us", Content: content,
+		Origin: model.OutboxOriginScheduled, ScheduledAt: time.Now().Add(-time.Minute)}); err != nil {
+		t.Fatal(err)
+	}
+	later := time.Now().Add(time.Hour)
+	if _, err := st.EnqueueOutbox(model.OutboxItem{AccountID: "a", GroupID: "later@g.us", Content: content,
+		Origin: model.OutboxOriginScheduled, ScheduledAt: later}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Di luar jendela kirim hanya item jatuh tempo yang dikirim, dengan konten kustomnya.
+	if !s.runDueOutbox(context.Background(), time.Now(), explicitOrigins...) {
+		t.Fatal("due item not sent")
+	}
+	if s.runDueOutbox(context.Background(), time.Now(), explicitOrigins...) {
+		t.Fatal("future item sent before its time")
+	}
+	sent := fake.Sent()
+	if len(sent) != 1 || sent[0].To != "now@g.us" || sent[0].Text != "Promo kilat" {
+		t.Fatalf("sent = %+v, want custom text to now@g.us", sent)
+	}
+	due, ok, err := st.NextOutboxDue()
+	if err != nil || !ok || due.Sub(later).Abs() > time.Second {
+		t.Fatalf("next due = %v ok=%v err=%v, want %v", due, ok, err, later)
+	}
+}
+
+func TestScenarioGroupWeeklyCapAcrossAccounts(t *testing.T) {
+	s, st, fake := scenario(t)
+	storagetest.SeedAccount(t, st, storagetest.Account{ID: "a", DailyLimit: 10})
+	storagetest.SeedAccount(t, st, storagetest.Account{ID: "b", DailyLimit: 10})
+	seedGroups(t, st, "a", "busy@g.us", "quiet@g.us")
+	// Dua promo minggu ini dari dua akun berbeda; cooldown grup sendiri sudah lewat.
+	storagetest.SeedLog(t, st, "a", "busy@g.us", "sent", time.Now().Add(-4*24*time.Hour))
+	storagetest.SeedLog(t, st, "b", "busy@g.us", "sent", time.Now().Add(-3*24*time.Hour))
+	if err := st.SetGroupSendCaps(model.GroupSendCaps{PerWeek: 2}); err != nil {
+		t.Fatal(err)
+	}
+
+	runCycles(t, s, 2)
+
+	sent := fake.Sent()
+	if len(sent) != 1 || sent[0].To != "quiet@g.us" {
+		t.Fatalf("sent = %+v, want only quiet@g.us", sent)
+	}
+	// Kiriman manual lewat outbox ke grup yang sudah mencapai batas ditunda, tidak dikirim.
+	id, err := st.EnqueueOutbox(model.OutboxItem{AccountID: "a", GroupID: "busy@g.us", Origin: model.OutboxOriginScheduled})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.runDueOutbox(context.Background(), time.Now(), explicitOrigins...) {
+		t.Fatal("capped group item sent")
+	}
+	it, err := st.GetOutboxItem(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if it.Status != model.OutboxPending || !it.ScheduledAt.After(time.Now()) {
+		t.Fatalf("capped item = %s at %v, want pending until next day", it.Status, it.ScheduledAt)
+	}
+	// Batas khusus grup menggantikan default.
+	unlimited := 0
+	if err := st.SetGroupCaps("busy@g.us", nil, &unlimited); err != nil {
+		t.Fatal(err)
+	}
+	if u, err := st.GroupCapUsage("busy@g.us"); err != nil || u.Capped || u.SentWeek != 2 {
+		t.Fatalf("usage = %+v err=%v, want uncapped with 2 sends this week", u, err)
+	}
+}
+
+func TestScenarioDeferredSchedulerPickRespectsLimits(t *testing.T) {
+	s, st, fake := scenario(t)
+	storagetest.SeedAccount(t, st, storagetest.Account{ID: "a", DailyLimit: 1})
+	seedGroups(t, st, "a", "done@g.us", "held@g.us")
+	storagetest.SeedLog(t, st, "a", "done@g.us", "sent", time.Now())
+	// Pilihan scheduler yang tertunda (mis. akun sempat terputus) bukan kiriman eksplisit.
+	id, err := st.EnqueueOutbox(model.OutboxItem{AccountID: "a", GroupID: "held@g.us"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Di luar jendela kirim item ini tidak disentuh sama sekali.
+	if s.runDueOutbox(context.Background(), time.Now(), explicitOrigins...) {
+		t.Fatal("scheduler pick sent outside the send window")
+	}
+	if it, _ := st.GetOutboxItem(id); it.Status != model.OutboxPending || it.Attempts != 0 {
+		t.Fatalf("item = %s attempts=%d, want untouched", it.Status, it.Attempts)
+	}
+	// Di dalam jendela limit harian akun tetap berlaku: item ditunda, tidak dikirim.
+	s.processMutex.Lock()
+	sent := s.drainOutbox(context.Background(), time.Now())
+	s.processMutex.Unlock()
+	if sent || len(fake.Sent()) != 0 {
+		t.Fatalf("sent = %v %+v, want held by the daily limit", sent, fake.Sent())
+	}
+	it, err := st.GetOutboxItem(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if it.Status != model.OutboxPending || !it.ScheduledAt.After(time.Now()) {
+		t.Fatalf("item = %s at %v, want deferred", it.Status, it.ScheduledAt)
+	}
+}
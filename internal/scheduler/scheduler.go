@@ -3,38 +3,69 @@ package scheduler
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
+	"fmt"
 	"log"
-	"math/rand"
 	"os"
 	"strconv"
 	"strings"
 	"sync"
 	"time"
 
+	"promote/internal/mediacache"
+	"promote/internal/model"
+	"promote/internal/rng"
 	"promote/internal/sender"
 	"promote/internal/storage"
 	"promote/internal/wa"
 )
 
 // Scheduler menjalankan broadcast terjadwal anti-spam:
-// - Jendela waktu aman (WIB): 00:45–02:30, 03:00–05:30, 21:30–23:30 (opsional kecil)
-// - Limit harian per akun: memakai accounts.daily_limit
-// - Cooldown per grup: minimal 48 jam
+// - Jendela waktu aman (WIB) per hari dari setting send_windows; default setiap hari
+//   00:45–02:30, 03:00–05:30, 21:30–23:30; akun dengan jendela khusus (account_send_windows)
+//   memakai jendelanya sendiri
+// - Limit harian per akun: memakai accounts.daily_limit, opsional dibagi per jendela (window_budget)
+// - Warmup: limit harian nomor baru naik bertahap menurut umur akun (profil account_warmup)
+// - Cooldown per grup: minimal 48 jam, plus batas harian/mingguan per grup dari semua akun (group_send_caps)
 // - Jitter antar grup: 45–120 detik random
 // - Variasi konten: pilih template aktif secara acak via Sender
 // - Risk: sender.bumpRiskAndMaybePause akan auto-disable grup berisiko
+// - Jadwal dari tabel schedules (per campaign/akun) menggantikan jendela default untuk akun tsb
+// - Outbox: kiriman grup dicatat di tabel outbox lalu di-claim dan dikirim (tahan restart, tanpa kirim ganda)
+// - Jadwal berulang (recurring_schedules): hari + jam WIB; grup sasaran diantrekan ke outbox saat jatuh tempo
+// - Error budget: akun dengan rasio gagal di atas budget dijeda sampai pulih atau di-override
+// - Throttle: akun yang ditolak WhatsApp karena rate-limit/spam dilewati sampai cooldown-nya habis
+// - Cold-start: grup baru mendapat tanggal kiriman pertama bertahap (cold_start_per_day)
+// - Masa uji grup: N kiriman pertama grup baru dinilai lalu grup lolos atau dinonaktifkan (group_trial)
+// - Campaign DM: satu DM per akun per siklus dalam jendela DM, limit accounts.dm_daily_limit
+// - WhatsApp Channels: satu posting per siklus, limit harian & jeda per channel (channel rules)
+// - Status (story): satu posting per siklus dalam jendela kirim, limit accounts.status_daily_limit
 type Scheduler struct {
 	Store   *storage.Store
 	Manager *wa.Manager
 	Sender  *sender.Sender
+	// Prefetch (opsional) menyiapkan media template/campaign aktif ke cache lokal di luar jendela.
+	Prefetch *mediacache.Prefetcher
+	// Rand sumber acak urutan akun, pemilihan grup dan jitter (seed lewat SCHEDULER_SEED).
+	Rand *rng.Rand
 
 	loc        *time.Location
 	running    bool
 	stop       chan struct{}
 	cooldownHr int
-	// Jendela waktu dalam menit dari tengah malam (WIB)
-	// Format: [startMin, endMin]
-	windows [][2]int
+	// Jendela waktu per hari (WIB), dimuat ulang dari setting send_windows setiap tick
+	windows   model.WeekWindows
+	windowsMu sync.RWMutex
+	// Jendela khusus per akun (tabel account_send_windows) yang menggantikan windows
+	accountWindows map[string]model.WeekWindows
+	// Status jeda terakhir (setting scheduler_pause), dipakai jika setting gagal dibaca
+	wasPaused bool
+	// Mode dry-run terakhir (setting scheduler_dry_run), dipakai jika setting gagal dibaca
+	wasDryRun bool
+	// Pembagian limit harian ke jendela (setting window_budget), dimuat ulang setiap tick
+	budget model.WindowBudget
+	// Ritme loop (interval tick, jendela minimum, tidur di luar jendela), dimuat ulang setiap tick
+	timing model.SchedulerTiming
 	// Jitter antar kirim (detik)
 	minDelaySec int
 	maxDelaySec int
@@ -44,6 +75,22 @@ type Scheduler struct {
 	alwaysOn bool
 	// Mutex untuk mencegah race condition
 	processMutex sync.Mutex
+	// Jadwal dari tabel schedules: waktu batch berikutnya per jadwal dan akun yang dikelolanya
+	scheduleNext map[string]time.Time
+	scheduled    map[string]bool
+	// Campaign mode dm: jendela, cooldown per nomor, dan jeda per akun sendiri (DM_*)
+	dm     dmConfig
+	dmNext map[string]time.Time
+	// Channel yang gagal diposting ditunda sampai waktu ini
+	channelNext map[string]time.Time
+	// Akun yang gagal posting status ditunda sampai waktu ini
+	statusNext map[string]time.Time
+	// Pacing even: kiriman grup berikutnya per akun paling cepat pada waktu ini
+	paceNext map[string]time.Time
+	// Strategi rotasi akun terakhir (setting account_rotation), dipakai jika setting gagal dibaca
+	rotation model.AccountRotation
+	// Round robin: akun terakhir yang mengirim pada gilirannya
+	rrLast string
 }
 
 // New membuat instance Scheduler dengan konfigurasi default konservatif.
@@ -55,18 +102,24 @@ func New(store *storage.Store, manager *wa.Manager, snd *sender.Sender) *Schedul
 		loc = time.FixedZone("WIB", 7*3600)
 	}
 
+	// Cooldown dan ambang risk dibaca lewat storage agar sama dengan diagnosa/trigger di API.
+	cooldownHr, riskThreshold := storage.EligibilityFromEnv()
 	s := &Scheduler{
 		Store:         store,
 		Manager:       manager,
 		Sender:        snd,
 		loc:           loc,
 		stop:          make(chan struct{}),
-		cooldownHr:    48,
-		windows:       [][2]int{{45, 150}, {180, 330}, {1290, 1410}}, // 00:45–02:30, 03:00–05:30, 21:30–23:30 WIB
+		cooldownHr:    cooldownHr,
+		windows:       model.DefaultWeekWindows(), // 00:45–02:30, 03:00–05:30, 21:30–23:30 WIB
 		minDelaySec:   45,
 		maxDelaySec:   120,
-		riskThreshold: 3,
+		riskThreshold: riskThreshold,
 		alwaysOn:      false,
+		timing:        storage.DefaultSchedulerTiming,
+		dm:            dmConfigFromEnv(),
+		dmNext:        map[string]time.Time{},
+		Rand:          rng.FromEnv("SCHEDULER_SEED"),
 	}
 
 	// ENV overrides (ops):
@@ -75,17 +128,13 @@ func New(store *storage.Store, manager *wa.Manager, snd *sender.Sender) *Schedul
 	// - SCHEDULER_MIN_DELAY_SEC=int     -> delay min antar grup
 	// - SCHEDULER_MAX_DELAY_SEC=int     -> delay max antar grup
 	// - SCHEDULER_RISK_THRESHOLD=int    -> ambang risk_score untuk filter/auto-disable
+	// - SCHEDULER_SEED=int64            -> seed acak tetap agar rencana kirim bisa diulang
 	if v := os.Getenv("SCHEDULER_ALWAYS_ON"); v != "" {
 		vv := strings.ToLower(strings.TrimSpace(v))
 		if vv == "1" || vv == "true" || vv == "yes" {
 			s.alwaysOn = true
 		}
 	}
-	if v := os.Getenv("SCHEDULER_COOLDOWN_HOURS"); v != "" {
-		if n, err := strconv.Atoi(strings.TrimSpace(v)); err == nil && n >= 0 {
-			s.cooldownHr = n
-		}
-	}
 	if v := os.Getenv("SCHEDULER_MIN_DELAY_SEC"); v != "" {
 		if n, err := strconv.Atoi(strings.TrimSpace(v)); err == nil && n >= 0 {
 			s.minDelaySec = n
@@ -96,11 +145,6 @@ func New(store *storage.Store, manager *wa.Manager, snd *sender.Sender) *Schedul
 			s.maxDelaySec = n
 		}
 	}
-	if v := os.Getenv("SCHEDULER_RISK_THRESHOLD"); v != "" {
-		if n, err := strconv.Atoi(strings.TrimSpace(v)); err == nil && n >= 0 {
-			s.riskThreshold = n
-		}
-	}
 
 	return s
 }
@@ -112,16 +156,25 @@ func (s *Scheduler) Start(ctx context.Context) {
 		return
 	}
 	s.running = true
+	s.refreshWindows()
+	// Item outbox yang tertinggal claimed saat proses berhenti dibereskan sebelum loop berjalan.
+	if sent, requeued, err := s.Store.RecoverOutbox(); err != nil {
+		log.Printf("[scheduler] outbox recover err=%v", err)
+	} else if sent+requeued > 0 {
+		log.Printf("[scheduler] outbox recovered: sent=%d requeued=%d", sent, requeued)
+	}
 	// Log awal untuk diagnosis: pastikan timezone & jendela waktu terbaca benar
-	log.Printf("[scheduler] start: tz=%s now=%s windows=%v alwaysOn=%v cooldownHr=%d minDelay=%ds maxDelay=%ds riskThreshold=%d",
+	log.Printf("[scheduler] start: tz=%s now=%s windows=%v timing=%+v alwaysOn=%v cooldownHr=%d minDelay=%ds maxDelay=%ds riskThreshold=%d seed=%d",
 		s.loc.String(),
 		time.Now().In(s.loc).Format(time.RFC3339),
 		s.windows,
+		s.timing,
 		s.alwaysOn,
 		s.cooldownHr,
 		s.minDelaySec,
 		s.maxDelaySec,
 		s.riskThreshold,
+		s.Rand.Seed(),
 	)
 	go s.loop(ctx)
 }
@@ -139,9 +192,10 @@ func (s *Scheduler) loop(ctx context.Context) {
 	defer func() {
 		s.running = false
 	}()
-	// Ticker utama: cek setiap 30 detik
-	tick := time.NewTicker(30 * time.Second)
-	defer tick.Stop()
+	// Timer utama: interval dari setting scheduler_tick_sec (default 30 detik); di luar jendela
+	// bisa tidur lebih lama (lihat idleWait)
+	timer := time.NewTimer(time.Duration(s.timing.TickSec) * time.Second)
+	defer timer.Stop()
 
 	for {
 		select {
@@ -149,32 +203,204 @@ func (s *Scheduler) loop(ctx context.Context) {
 			return
 		case <-ctx.Done():
 			return
-		case <-tick.C:
-			// Jalankan satu siklus jika dalam jendela waktu aman
-			now := time.Now().In(s.loc)
-			inWindow := s.inWindow(now)
-			if !inWindow {
-				ns, ne, dur := s.nextWindow(now)
-				log.Printf("[scheduler] tick: now=%s in_window=%v next_window=%02d:%02d-%02d:%02d in=%s alwaysOn=%v",
-					now.Format("2006-01-02 15:04:05"),
-					inWindow,
-					ns/60, ns%60, ne/60, ne%60,
-					dur.String(),
-					s.alwaysOn,
-				)
-				if !s.alwaysOn {
-					continue
-				}
-			} else {
-				log.Printf("[scheduler] tick: now=%s in_window=%v alwaysOn=%v", now.Format("2006-01-02 15:04:05"), inWindow, s.alwaysOn)
+		case <-timer.C:
+			timer.Reset(s.tick(ctx))
+		}
+	}
+}
+
+// tick menjalankan satu siklus scheduler dan mengembalikan jeda sampai siklus berikutnya.
+func (s *Scheduler) tick(ctx context.Context) time.Duration {
+	// Jadwal per campaign/akun punya jendela sendiri; akun tersebut dikecualikan dari
+	// jendela default di bawah.
+	now := time.Now().In(s.loc)
+	s.refreshWindows()
+	// Akun yang melewati error budget dijeda sebelum jadwal/antrian diproses.
+	s.checkErrorBudgets(now)
+	// Grup baru yang belum pernah dikirimi disebar dulu ke ramp harian.
+	s.planColdStart(now)
+	// Grup baru menjalani masa uji; verdict otomatis setelah N kiriman pertama.
+	s.evaluateTrials()
+	// Dijeda lewat API (insiden): semua kiriman terjadwal berhenti sampai dilanjutkan.
+	if s.paused() {
+		s.scheduled = nil
+		return time.Duration(s.timing.TickSec) * time.Second
+	}
+	// Dry-run: pemilihan kiriman grup berjalan penuh dan dicatat sebagai would_send; DM, channel
+	// dan status dilewati karena tidak punya jalur dry-run.
+	dryRun := s.dryRun()
+	if dryRun {
+		ctx = sender.WithDryRun(ctx)
+	}
+	// Hari libur (tabel holidays): semua broadcast otomatis dilewati seharian; jadwal berulang
+	// dicatat terlewat dan hanya kiriman terjadwal sekali jalan (outbox) yang dikirim tepat waktu.
+	if h, ok := s.holiday(now); ok {
+		log.Printf("[scheduler] tick: now=%s HOLIDAY date=%s name=%q -> broadcasts skipped", now.Format("2006-01-02 15:04:05"), h.Date, h.Name)
+		s.scheduled = nil
+		if !dryRun {
+			s.runRecurring(now)
+			if s.runHolidayOutbox(ctx, now) {
+				return time.Duration(s.timing.TickSec) * time.Second
 			}
-			// Proses: satu kirim maksimum setiap siklus (menghindari burst)
-			if err := s.processOneSend(ctx, now); err != nil {
-				// Log saja dan lanjut; kesalahan akan ditangani risk handler sender
-				log.Printf("[scheduler] process error: %v", err)
+		}
+		return s.idleWait(now)
+	}
+	s.scheduled = s.runSchedules(ctx, now)
+	if !dryRun {
+		// Jadwal berulang (hari + jam) masuk outbox tepat waktu, di dalam maupun di luar jendela.
+		s.runRecurring(now)
+		// DM campaign punya jendela jam sendiri (siang), terpisah dari jendela grup.
+		s.runDMCampaigns(ctx, now)
+		// WhatsApp Channels punya aturan posting sendiri (limit harian & jeda per channel).
+		s.runChannels(ctx, now)
+	}
+	// Jalankan satu siklus jika dalam jendela waktu aman
+	inWindow := s.inWindow(now)
+	if !inWindow {
+		next, ok := s.nextWindow(now)
+		if ok {
+			log.Printf("[scheduler] tick: now=%s in_window=%v next_window=%s-%s in=%s alwaysOn=%v",
+				now.Format("2006-01-02 15:04:05"),
+				inWindow,
+				next.Start.Format("Mon 15:04"), next.End.Format("15:04"),
+				next.Start.Sub(now).Round(time.Second).String(),
+				s.alwaysOn,
+			)
+		} else {
+			log.Printf("[scheduler] tick: now=%s in_window=%v next_window=none alwaysOn=%v",
+				now.Format("2006-01-02 15:04:05"), inWindow, s.alwaysOn)
+		}
+		if !s.alwaysOn {
+			// Kiriman terjadwal sekali jalan (outbox) dieksekusi tepat waktu tanpa menunggu jendela.
+			if s.runDueOutbox(ctx, now, explicitOrigins...) {
+				return time.Duration(s.timing.TickSec) * time.Second
 			}
+			s.maybePrefetch(ctx, now)
+			return s.idleWait(now)
+		}
+	} else {
+		log.Printf("[scheduler] tick: now=%s in_window=%v alwaysOn=%v", now.Format("2006-01-02 15:04:05"), inWindow, s.alwaysOn)
+	}
+	// Status (story) hanya diposting di dalam jendela kirim, dengan limit harian per akun sendiri.
+	if !dryRun {
+		s.runStatuses(ctx, now)
+	}
+	// Proses: satu kirim maksimum setiap siklus (menghindari burst)
+	if err := s.processOneSend(ctx, now); err != nil {
+		// Log saja dan lanjut; kesalahan akan ditangani risk handler sender
+		log.Printf("[scheduler] process error: %v", err)
+	}
+	return time.Duration(s.timing.TickSec) * time.Second
+}
+
+// paused membaca setting scheduler_pause dan mencatat perubahan status; jika gagal dibaca, status
+// terakhir dipakai.
+func (s *Scheduler) paused() bool {
+	p, err := s.Store.SchedulerPause()
+	if err != nil {
+		log.Printf("[scheduler] pause state err=%v (keeping previous)", err)
+		return s.wasPaused
+	}
+	if p.Paused != s.wasPaused {
+		if p.Paused {
+			log.Printf("[scheduler] PAUSED by=%s reason=%q", p.By, p.Reason)
+		} else {
+			log.Printf("[scheduler] RESUMED")
 		}
+		s.wasPaused = p.Paused
+	}
+	return p.Paused
+}
+
+// dryRun membaca setting scheduler_dry_run dan mencatat perubahan mode; jika gagal dibaca, mode
+// terakhir dipakai.
+func (s *Scheduler) dryRun() bool {
+	on, err := s.Store.SchedulerDryRun()
+	if err != nil {
+		log.Printf("[scheduler] dry-run state err=%v (keeping previous)", err)
+		return s.wasDryRun
 	}
+	if on != s.wasDryRun {
+		log.Printf("[scheduler] DRY_RUN=%v", on)
+		s.wasDryRun = on
+	}
+	return on
+}
+
+// releaseDryRun membatalkan reservasi cooldown grup setelah kiriman dry-run agar mode uji tidak
+// menggeser cooldown kiriman sungguhan.
+func (s *Scheduler) releaseDryRun(ctx context.Context, groupID string) {
+	if !sender.IsDryRun(ctx) {
+		return
+	}
+	if err := s.Store.ReleaseGroupReservation(groupID); err != nil {
+		log.Printf("[scheduler] dry-run release group=%s err=%v", groupID, err)
+	}
+}
+
+// idleWait jeda sampai tick berikutnya saat jendela grup tertutup. Dengan idle_sleep, scheduler
+// tidur sampai jendela berikutnya (paling lama max_idle_min) selama tidak ada jadwal per akun dan
+// DM campaign pending tidak sedang dalam jam DM; DM pending membangunkan di awal jam DM.
+func (s *Scheduler) idleWait(now time.Time) time.Duration {
+	tick := time.Duration(s.timing.TickSec) * time.Second
+	if !s.timing.IdleSleep || len(s.scheduled) > 0 {
+		return tick
+	}
+	wait := time.Duration(s.timing.MaxIdleMin) * time.Minute
+	next, ok := s.nextWindow(now)
+	if ok && next.Start.Sub(now) < wait {
+		wait = next.Start.Sub(now)
+	}
+	// Bangun tepat saat item outbox berikutnya jatuh tempo.
+	if due, ok, err := s.Store.NextOutboxDue(explicitOrigins...); err == nil && ok && due.Sub(now) < wait {
+		wait = due.Sub(now)
+	}
+	if at, ok := s.nextRecurring(now); ok && at.Sub(now) < wait {
+		wait = at.Sub(now)
+	}
+	dm, err := s.Store.DMPendingAccounts()
+	if err != nil {
+		return tick
+	}
+	if len(dm) > 0 {
+		if s.dm.inWindow(now) {
+			return tick
+		}
+		if d := s.dm.untilWindow(now); d < wait {
+			wait = d
+		}
+	}
+	if wait <= 0 {
+		return tick
+	}
+	if wait > tick {
+		log.Printf("[scheduler] idle: sleeping %s", wait.Round(time.Second))
+	}
+	return wait
+}
+
+// explicitOrigins asal item outbox yang dikirim tepat waktu walau jendela kirim tertutup.
+var explicitOrigins = []string{model.OutboxOriginScheduled, model.OutboxOriginRecurring}
+
+// runDueOutbox mengirim satu item outbox jatuh tempo dari origins di luar jendela kirim.
+func (s *Scheduler) runDueOutbox(ctx context.Context, now time.Time, origins ...string) bool {
+	s.processMutex.Lock()
+	defer s.processMutex.Unlock()
+	return s.drainOutbox(ctx, now, origins...)
+}
+
+// maybePrefetch menjalankan prefetch media di background selama masih di luar jendela kirim;
+// prefetch berhenti sendiri begitu jendela berikutnya dimulai.
+func (s *Scheduler) maybePrefetch(ctx context.Context, now time.Time) {
+	if !s.Prefetch.Due(now) {
+		return
+	}
+	go func() {
+		idle := func() bool { return !s.inWindow(time.Now().In(s.loc)) }
+		if _, err := s.Prefetch.Run(ctx, idle); err != nil && err != mediacache.ErrRunning {
+			log.Printf("[scheduler] media prefetch err=%v", err)
+		}
+	}()
 }
 
 // processOneSend memilih satu akun yang masih di bawah limit harian,
@@ -184,6 +410,11 @@ func (s *Scheduler) processOneSend(ctx context.Context, now time.Time) error {
 	// Lock untuk mencegah multiple execution bersamaan
 	s.processMutex.Lock()
 	defer s.processMutex.Unlock()
+	// 0) Item outbox yang sudah jatuh tempo (sisa sebelum restart atau antrean manual) dikirim dulu
+	if s.drainOutbox(ctx, now) {
+		return nil
+	}
+
 	// 1) Ambil akun aktif (enabled)
 	accs, err := s.listEnabledAccounts()
 	if err != nil {
@@ -194,12 +425,25 @@ func (s *Scheduler) processOneSend(ctx context.Context, now time.Time) error {
 		return nil
 	}
 
-	// Randomisasi urutan akun untuk pemerataan
-	rand.Shuffle(len(accs), func(i, j int) { accs[i], accs[j] = accs[j], accs[i] })
+	// Campaign aktif dengan targeting membatasi grup yang boleh dipilih
+	campaigns, err := s.Store.ListTargetedCampaigns()
+	if err != nil {
+		log.Printf("[scheduler] targeted campaigns query err=%v", err)
+	}
+
+	// Urutan giliran akun menurut strategi rotasi (default acak untuk pemerataan)
+	strategy := s.rotationStrategy()
+	accs = s.orderAccounts(accs, strategy)
 
 	for _, a := range accs {
+		if s.scheduled[a.ID] {
+			continue
+		}
+		if s.outsideAccountWindow(a.ID, now) {
+			continue
+		}
 		// Pastikan akun paired & siap connect (best-effort)
-		if err := s.Manager.ConnectIfPaired(a.ID); err != nil {
+		if err := s.Sender.EnsureConnected(a.ID); err != nil {
 			// skip akun yang belum paired
 			log.Printf("[scheduler] account=%s connectIfPaired=skip err=%v", a.ID, err)
 			continue
@@ -218,6 +462,17 @@ func (s *Scheduler) processOneSend(ctx context.Context, now time.Time) error {
 			log.Printf("[scheduler] account=%s sentToday=%d dailyLimit=%d -> skip (limit reached)", a.ID, sentToday, a.DailyLimit)
 			continue
 		}
+		wcap := s.windowCap(now, a.ID, a.DailyLimit)
+		if int(sentToday) >= wcap {
+			// porsi jendela ini habis; sisa limit menunggu jendela berikutnya
+			log.Printf("[scheduler] account=%s sentToday=%d windowCap=%d -> skip (window budget reached)", a.ID, sentToday, wcap)
+			continue
+		}
+		if wait := s.paceWait(a.ID, now); wait > 0 {
+			// pacing even: sisa kuota disebar merata sepanjang jendela
+			log.Printf("[scheduler] account=%s next_paced_in=%s -> skip (pacing)", a.ID, wait.Round(time.Second))
+			continue
+		}
 
 		// Logging eligible groups count
 		eligibleCnt, err := s.countEligibleGroups(a.ID, s.cooldownHr, s.riskThreshold)
@@ -229,7 +484,7 @@ func (s *Scheduler) processOneSend(ctx context.Context, now time.Time) error {
 
 		// 3) Pilih grup satu yang eligible untuk dikirim sekarang
 		log.Printf("[scheduler] SELECTING_GROUP account=%s cooldown=%dh risk_threshold=%d", a.ID, s.cooldownHr, s.riskThreshold)
-		groupID, err := s.pickOneEligibleGroup(a.ID, s.cooldownHr, s.riskThreshold)
+		groupID, campaignID, err := s.pickGroup(a.ID, campaigns)
 		if err != nil {
 			log.Printf("[scheduler] PICK_GROUP_ERROR account=%s err=%v", a.ID, err)
 			continue
@@ -241,19 +496,30 @@ func (s *Scheduler) processOneSend(ctx context.Context, now time.Time) error {
 		}
 		log.Printf("[scheduler] SELECTED_GROUP account=%s group=%s -> sending with random template...", a.ID, groupID)
 
-		// 4) Kirim menggunakan template acak (sender sudah tangani pacing antar bagian)
-		sendCtx, cancel := context.WithTimeout(ctx, 90*time.Second)
-		err = s.Sender.SendToGroupUsingRandomTemplate(sendCtx, a.ID, groupID)
-		cancel()
+		// Rotasi pengirim dalam pool (jika akun anggota pool dengan rotate=1)
+		senderID := s.rotateSender(now, a.ID, a.DailyLimit, groupID, strategy)
+		remaining, windowOnly := wcap-int(sentToday), wcap < a.DailyLimit
+		if senderID != a.ID {
+			remaining, windowOnly = s.quotaLeft(now, senderID)
+		}
+
+		// 4) Kirim lewat outbox: campaign atau template acak (sender sudah tangani pacing antar bagian)
+		err = s.send(ctx, senderID, groupID, campaignID)
 		// Jika gagal, sender akan bump risk dan mungkin auto-disable grup
 		if err != nil {
-			log.Printf("[scheduler] send failed account=%s group=%s err=%v", a.ID, groupID, err)
+			log.Printf("[scheduler] send failed account=%s group=%s err=%v", senderID, groupID, err)
 			// Setelah gagal, tetap jeda sebentar untuk naturalness
 			s.sleepBetweenGroups(ctx)
 			// lanjut akun lain setelah jeda
 			continue
 		}
-		log.Printf("[scheduler] send success account=%s group=%s", a.ID, groupID)
+		log.Printf("[scheduler] send success account=%s group=%s", senderID, groupID)
+		if !sender.IsDryRun(ctx) {
+			// would_send tidak memakai kuota, jadi tidak menggeser pacing maupun giliran rotasi;
+			// keduanya dicatat untuk akun yang benar-benar mengirim
+			s.rrLast = senderID
+			s.pace(senderID, now, remaining, windowOnly)
+		}
 
 		// 5) Jeda antar grup (jitter 45–120 detik)
 		s.sleepBetweenGroups(ctx)
@@ -264,6 +530,169 @@ func (s *Scheduler) processOneSend(ctx context.Context, now time.Time) error {
 	return nil
 }
 
+// drainOutbox mengirim satu item outbox yang jatuh tempo (dari origins, jika diisi; diikuti jeda
+// antar grup); false jika tidak ada yang dikirim. Dalam dry-run outbox tidak disentuh. Pemanggil
+// memegang processMutex.
+func (s *Scheduler) drainOutbox(ctx context.Context, now time.Time, origins ...string) bool {
+	if sender.IsDryRun(ctx) {
+		return false
+	}
+	it, ok, err := s.sendNextOutbox(ctx, now, origins...)
+	if !ok {
+		if err != nil {
+			log.Printf("[scheduler] outbox claim err=%v", err)
+		}
+		return false
+	}
+	if err != nil {
+		log.Printf("[scheduler] outbox=%d send failed account=%s group=%s err=%v", it.ID, it.AccountID, it.GroupID, err)
+	} else {
+		log.Printf("[scheduler] outbox=%d send success account=%s group=%s", it.ID, it.AccountID, it.GroupID)
+	}
+	s.sleepBetweenGroups(ctx)
+	return true
+}
+
+// outboxRetryDelay jeda sebelum item outbox yang akunnya belum tersambung dicoba lagi.
+const outboxRetryDelay = 5 * time.Minute
+
+// send mengirim pilihan scheduler: masuk outbox lalu langsung di-claim dan dikirim, sehingga
+// kiriman tercatat, tahan restart, dan tidak terkirim dua kali. Dry-run mengirim langsung tanpa
+// outbox (hanya log would_send) lalu melepas reservasi cooldown grup.
+func (s *Scheduler) send(ctx context.Context, accountID, groupID, campaignID string) error {
+	if sender.IsDryRun(ctx) {
+		sendCtx, cancel := context.WithTimeout(ctx, 90*time.Second)
+		defer cancel()
+		var err error
+		if campaignID != "" {
+			err = s.Sender.SendCampaign(sendCtx, accountID, groupID, campaignID)
+		} else {
+			err = s.Sender.SendToGroupUsingRandomTemplate(sendCtx, accountID, groupID)
+		}
+		s.releaseDryRun(ctx, groupID)
+		return err
+	}
+	id, err := s.Store.EnqueueOutbox(model.OutboxItem{AccountID: accountID, GroupID: groupID, CampaignID: campaignID})
+	if err != nil {
+		return fmt.Errorf("enqueue outbox: %w", err)
+	}
+	// Claim item ini sendiri, bukan item teratas antrean: item lain (API, jadwal berulang) tidak
+	// boleh terkirim atas jatah dan pacing akun ini.
+	it, ok, err := s.Store.ClaimOutboxItem(id, time.Now())
+	if err == nil && ok {
+		ok, err = s.sendOutboxItem(ctx, it)
+	}
+	if !ok && err == nil {
+		return fmt.Errorf("outbox item %d not sent (deferred or claimed elsewhere)", id)
+	}
+	return err
+}
+
+// sendNextOutbox meng-claim item outbox berikutnya yang jatuh tempo (dari origins, jika diisi) lalu
+// mengirimnya. Pilihan scheduler yang tertunda (akun belum tersambung, dipulihkan setelah restart)
+// dicek ulang terhadap jendela akun, limit harian, porsi jendela dan pacing. ok=false jika tidak
+// ada item atau item ditunda; err berisi error kirim.
+func (s *Scheduler) sendNextOutbox(ctx context.Context, now time.Time, origins ...string) (model.OutboxItem, bool, error) {
+	it, ok, err := s.Store.ClaimNextOutbox(time.Now(), origins...)
+	if err != nil || !ok {
+		return it, false, err
+	}
+	if it.Origin != model.OutboxOriginScheduler {
+		ok, err = s.sendOutboxItem(ctx, it)
+		return it, ok, err
+	}
+	remaining, windowOnly, hold := s.schedulerItemReady(it.AccountID, now)
+	if hold != "" {
+		log.Printf("[scheduler] outbox=%d account=%s %s, deferring", it.ID, it.AccountID, hold)
+		if err := s.Store.DeferOutbox(it.ID, time.Now().Add(outboxRetryDelay), hold); err != nil {
+			log.Printf("[scheduler] outbox=%d defer err=%v", it.ID, err)
+		}
+		return it, false, nil
+	}
+	ok, err = s.sendOutboxItem(ctx, it)
+	if ok && err == nil {
+		s.pace(it.AccountID, now, remaining, windowOnly)
+	}
+	return it, ok, err
+}
+
+// schedulerItemReady memeriksa akun pilihan scheduler yang tertunda seperti processOneSend: hold
+// berisi alasan jika belum boleh kirim sekarang; selain itu sisa kuota (untuk pacing) dan apakah
+// porsi jendela yang membatasi.
+func (s *Scheduler) schedulerItemReady(accountID string, now time.Time) (remaining int, windowOnly bool, hold string) {
+	if s.outsideAccountWindow(accountID, now) {
+		return 0, false, "outside account send window"
+	}
+	limits, err := s.dailyLimits()
+	if err != nil {
+		return 0, false, "daily limit query failed: " + err.Error()
+	}
+	limit, ok := limits[accountID]
+	if !ok {
+		return 0, false, "account not enabled"
+	}
+	if limit <= 0 {
+		limit = 100
+	}
+	sent, err := s.countSentTodayForAccount(accountID)
+	if err != nil {
+		return 0, false, "sent today query failed: " + err.Error()
+	}
+	if int(sent) >= limit {
+		return 0, false, "daily limit reached"
+	}
+	wcap := s.windowCap(now, accountID, limit)
+	if int(sent) >= wcap {
+		return 0, false, "window budget reached"
+	}
+	if s.paceWait(accountID, now) > 0 {
+		return 0, false, "waiting for pacing"
+	}
+	return wcap - int(sent), wcap < limit, ""
+}
+
+// sendOutboxItem mengirim item yang sudah di-claim; ok=false jika item ditunda (akun belum
+// tersambung, grup mencapai batas kirim).
+func (s *Scheduler) sendOutboxItem(ctx context.Context, it model.OutboxItem) (bool, error) {
+	if err := s.Sender.EnsureConnected(it.AccountID); err != nil {
+		log.Printf("[scheduler] outbox=%d account=%s not connected, deferring: %v", it.ID, it.AccountID, err)
+		if err := s.Store.DeferOutbox(it.ID, time.Now().Add(outboxRetryDelay), err.Error()); err != nil {
+			log.Printf("[scheduler] outbox=%d defer err=%v", it.ID, err)
+		}
+		return false, nil
+	}
+	// Grup yang sudah mencapai batas harian/mingguannya menunggu hari kuota berikutnya
+	if u, err := s.Store.GroupCapUsage(it.GroupID); err == nil && u.Capped {
+		_, next := s.Store.Day.Today()
+		log.Printf("[scheduler] outbox=%d group=%s capped (today %d/%d, week %d/%d), deferring to %s",
+			it.ID, it.GroupID, u.SentToday, u.PerDay, u.SentWeek, u.PerWeek, next.Format("2006-01-02 15:04"))
+		if err := s.Store.DeferOutbox(it.ID, next, "group send cap reached"); err != nil {
+			log.Printf("[scheduler] outbox=%d defer err=%v", it.ID, err)
+		}
+		return false, nil
+	}
+	var err error
+	sendCtx, cancel := context.WithTimeout(sender.WithPlannedAt(ctx, it.ScheduledAt), 90*time.Second)
+	switch {
+	case len(it.Content) > 0:
+		var content sender.MessageContent
+		if err = json.Unmarshal(it.Content, &content); err == nil {
+			err = s.Sender.SendToGroup(sendCtx, it.AccountID, it.GroupID, content)
+		}
+	case it.CampaignID != "":
+		err = s.Sender.SendCampaign(sendCtx, it.AccountID, it.GroupID, it.CampaignID)
+	case it.TemplateID != "":
+		err = s.Sender.SendTemplateToGroup(sendCtx, it.AccountID, it.GroupID, it.TemplateID)
+	default:
+		err = s.Sender.SendToGroupUsingRandomTemplate(sendCtx, it.AccountID, it.GroupID)
+	}
+	cancel()
+	if ferr := s.Store.FinishOutbox(it.ID, err); ferr != nil {
+		log.Printf("[scheduler] outbox=%d finish err=%v", it.ID, ferr)
+	}
+	return true, err
+}
+
 func (s *Scheduler) sleepBetweenGroups(ctx context.Context) {
 	delay := s.randDelay()
 	select {
@@ -273,66 +702,109 @@ func (s *Scheduler) sleepBetweenGroups(ctx context.Context) {
 }
 
 func (s *Scheduler) randDelay() time.Duration {
-	min := s.minDelaySec
-	max := s.maxDelaySec
-	if max < min {
-		max, min = min, max
-	}
 	// 45–120 detik random
-	return time.Duration(min+rand.Intn(max-min+1)) * time.Second
+	return s.Rand.Between(s.minDelaySec, s.maxDelaySec)
 }
 
+// inWindow true jika jendela global atau jendela khusus salah satu akun sedang terbuka.
 func (s *Scheduler) inWindow(t time.Time) bool {
 	// Ops override: jalankan kapan saja jika diaktifkan
 	if s.alwaysOn {
 		return true
 	}
-	// menit dari tengah malam (WIB)
-	m := t.Hour()*60 + t.Minute()
-	for _, w := range s.windows {
-		if m >= w[0] && m <= w[1] {
+	s.windowsMu.RLock()
+	defer s.windowsMu.RUnlock()
+	if s.windows.Active(t) {
+		return true
+	}
+	for _, w := range s.accountWindows {
+		if w.Active(t) {
 			return true
 		}
 	}
 	return false
 }
 
-// nextWindow mengembalikan pasangan [startMin,endMin] untuk window berikutnya
-// relatif terhadap waktu t (WIB) dan durasi menuju start window tersebut.
-func (s *Scheduler) nextWindow(t time.Time) (startMin int, endMin int, until time.Duration) {
-	m := t.Hour()*60 + t.Minute()
-	var nextStart, nextEnd int
-	found := false
-	for _, w := range s.windows {
-		if m <= w[0] {
-			nextStart = w[0]
-			nextEnd = w[1]
-			found = true
-			break
+// outsideAccountWindow true jika jendela yang berlaku untuk akun (khusus atau global) sedang
+// tertutup. Tanpa jendela khusus sama sekali, tick hanya berjalan saat jendela global terbuka
+// sehingga pengecekan ini dilewati.
+func (s *Scheduler) outsideAccountWindow(accountID string, t time.Time) bool {
+	if s.alwaysOn {
+		return false
+	}
+	s.windowsMu.RLock()
+	defer s.windowsMu.RUnlock()
+	if len(s.accountWindows) == 0 {
+		return false
+	}
+	return !s.windowsFor(accountID).Active(t)
+}
+
+// windowsFor jendela yang berlaku untuk akun; pemanggil memegang windowsMu.
+func (s *Scheduler) windowsFor(accountID string) model.WeekWindows {
+	if w, ok := s.accountWindows[accountID]; ok {
+		return w
+	}
+	return s.windows
+}
+
+// nextWindow jendela berikutnya paling awal di antara jendela global dan jendela khusus akun.
+func (s *Scheduler) nextWindow(now time.Time) (model.Occurrence, bool) {
+	s.windowsMu.RLock()
+	defer s.windowsMu.RUnlock()
+	next, ok := s.windows.Next(now)
+	for _, w := range s.accountWindows {
+		if o, found := w.Next(now); found && (!ok || o.Start.Before(next.Start)) {
+			next, ok = o, true
 		}
 	}
-	if !found {
-		// gunakan window pertama pada hari berikutnya
-		nextStart = s.windows[0][0]
-		nextEnd = s.windows[0][1]
+	return next, ok
+}
+
+// refreshWindows memuat ritme scheduler dan jendela per hari dari setting (jendela lebih pendek
+// dari min_window_min dibuang); jika gagal, nilai terakhir dipakai.
+func (s *Scheduler) refreshWindows() {
+	if t, err := s.Store.SchedulerTiming(); err != nil {
+		log.Printf("[scheduler] timing err=%v (keeping previous)", err)
+	} else {
+		s.timing = t
+	}
+	w, err := s.Store.SendWindows()
+	if err != nil {
+		log.Printf("[scheduler] send windows err=%v (keeping previous)", err)
+		return
 	}
-	// hitung durasi menuju nextStart
-	// konversi menit dari tengah malam ke time.Time untuk hari ini/tomorrow
-	dayStart := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, s.loc)
-	nextStartTime := dayStart.Add(time.Duration(nextStart) * time.Minute)
-	if nextStartTime.Before(t) {
-		nextStartTime = nextStartTime.Add(24 * time.Hour)
+	b, err := s.Store.WindowBudget()
+	if err != nil {
+		log.Printf("[scheduler] window budget err=%v (keeping previous)", err)
+		b = s.budget
 	}
-	return nextStart, nextEnd, nextStartTime.Sub(t)
+	perAccount, err := s.Store.AccountSendWindows()
+	if err != nil {
+		log.Printf("[scheduler] account send windows err=%v (keeping previous)", err)
+		perAccount = s.accountWindows
+	} else {
+		for id, aw := range perAccount {
+			perAccount[id] = aw.Actionable(s.timing.MinWindowMin)
+		}
+	}
+	s.windowsMu.Lock()
+	s.windows = w.Actionable(s.timing.MinWindowMin)
+	s.accountWindows = perAccount
+	s.budget = b
+	s.windowsMu.Unlock()
 }
 
 type accountLite struct {
 	ID         string
 	DailyLimit int
+	// PairedAt awal warmup (first_paired_at, atau created_at jika belum pernah terhubung).
+	PairedAt time.Time
 }
 
 func (s *Scheduler) listEnabledAccounts() ([]accountLite, error) {
-	rows, err := s.Store.DB.Query(`SELECT id, daily_limit FROM accounts WHERE enabled=1`)
+	rows, err := s.Store.DB.Query(`SELECT id, daily_limit, first_paired_at, created_at FROM accounts WHERE enabled=1 AND observer=0 AND budget_paused_at IS NULL
+		AND (throttled_until IS NULL OR throttled_until <= CURRENT_TIMESTAMP)`)
 	if err != nil {
 		return nil, err
 	}
@@ -340,87 +812,36 @@ func (s *Scheduler) listEnabledAccounts() ([]accountLite, error) {
 	var out []accountLite
 	for rows.Next() {
 		var a accountLite
-		if err := rows.Scan(&a.ID, &a.DailyLimit); err != nil {
+		var paired sql.NullTime
+		if err := rows.Scan(&a.ID, &a.DailyLimit, &paired, &a.PairedAt); err != nil {
 			return nil, err
 		}
+		if paired.Valid {
+			a.PairedAt = paired.Time
+		}
 		out = append(out, a)
 	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	// Nomor baru: daily_limit dibatasi profil warmup menurut umur akun
+	s.applyWarmup(out, time.Now())
 	return out, nil
 }
 
 func (s *Scheduler) countSentTodayForAccount(accountID string) (int64, error) {
-	var n int64
-	err := s.Store.DB.QueryRow(`
-		SELECT COALESCE(SUM(CASE WHEN status='sent' THEN 1 ELSE 0 END), 0)
-		FROM logs
-		WHERE account_id=? AND ts >= datetime('now','start of day') AND ts < datetime('now','start of day','+1 day')
-	`, accountID).Scan(&n)
-	if err != nil {
-		return 0, err
-	}
-	return n, nil
+	n, err := s.Store.CountAccountSentToday(accountID)
+	return int64(n), err
 }
 
 func (s *Scheduler) countEligibleGroups(accountID string, cooldownHours int, riskThreshold int) (int64, error) {
-	var n int64
-	err := s.Store.DB.QueryRow(`
-		SELECT COUNT(*)
-		FROM groups
-		WHERE account_id=? AND enabled=1 AND (last_sent_at IS NULL OR last_sent_at < datetime('now', ?)) AND risk_score < ?
-	`, accountID, "-"+itoa(cooldownHours)+" hours", riskThreshold).Scan(&n)
-	if err != nil {
-		return 0, err
-	}
-	return n, nil
+	return s.Store.CountEligibleGroups(accountID, cooldownHours, riskThreshold)
 }
 
+// pickOneEligibleGroup memilih satu grup eligible lewat s.Rand dan langsung me-reserve-nya
+// (last_sent_at) dalam satu transaksi agar tidak dipilih bersamaan; kosong jika tidak ada.
 func (s *Scheduler) pickOneEligibleGroup(accountID string, cooldownHours int, riskThreshold int) (string, error) {
-	// Atomic selection: Update last_sent_at dan return ID dalam satu query
-	// untuk mencegah grup yang sama dipilih bersamaan
-	var (
-		id sql.NullString
-	)
-	
-	// Gunakan transaction untuk atomic operation
-	tx, err := s.Store.DB.Begin()
-	if err != nil {
-		return "", err
-	}
-	defer tx.Rollback()
-	
-	// Ambil grup eligible dengan lock
-	err = tx.QueryRow(`
-		SELECT id
-		FROM groups
-		WHERE account_id=? AND enabled=1 AND (last_sent_at IS NULL OR last_sent_at < datetime('now', ?)) AND risk_score < ?
-		ORDER BY RANDOM()
-		LIMIT 1
-	`, accountID, "-"+itoa(cooldownHours)+" hours", riskThreshold).Scan(&id)
-	
-	if err != nil {
-		if err == sql.ErrNoRows {
-			return "", nil
-		}
-		return "", err
-	}
-	
-	if !id.Valid {
-		return "", nil
-	}
-	
-	// Update last_sent_at secara atomic untuk reserve grup ini
-	_, err = tx.Exec(`UPDATE groups SET last_sent_at=CURRENT_TIMESTAMP WHERE id=?`, id.String)
-	if err != nil {
-		return "", err
-	}
-	
-	// Commit transaction
-	err = tx.Commit()
-	if err != nil {
-		return "", err
-	}
-	
-	return id.String, nil
+	return s.Store.PickTargetedGroup(accountID, cooldownHours, riskThreshold, model.Targeting{}, s.Rand)
 }
 
 func itoa(i int) string {
@@ -0,0 +1,143 @@
+package scheduler
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"promote/internal/model"
+	"promote/internal/rng"
+	"promote/internal/sender"
+)
+
+// runSchedules menjalankan jadwal per campaign/akun dari tabel schedules. Setiap jadwal yang
+// sedang aktif (hari & jam WIB) mengirim satu batch (maks batch_size) dengan jeda
+// min_delay_sec–max_delay_sec, dibatasi daily_limit jadwal dan limit harian akun.
+// Akun yang punya jadwal aktif dikembalikan agar tidak ikut jendela waktu default.
+func (s *Scheduler) runSchedules(ctx context.Context, now time.Time) map[string]bool {
+	managed := map[string]bool{}
+	list, err := s.Store.ActiveSchedules()
+	if err != nil {
+		log.Printf("[scheduler] schedules query err=%v", err)
+		return managed
+	}
+	if len(list) == 0 {
+		return managed
+	}
+	limits, err := s.dailyLimits()
+	if err != nil {
+		log.Printf("[scheduler] schedules daily limits err=%v", err)
+		return managed
+	}
+	if s.scheduleNext == nil {
+		s.scheduleNext = map[string]time.Time{}
+	}
+	for _, sch := range list {
+		managed[sch.AccountID] = true
+		if !sch.Active(now) || now.Before(s.scheduleNext[sch.ID]) {
+			continue
+		}
+		// Batch jatuh tempo pada scheduleNext; run pertama sejak start dianggap tepat waktu.
+		planned := s.scheduleNext[sch.ID]
+		if planned.IsZero() {
+			planned = now
+		}
+		s.runScheduleBatch(ctx, sch, limits[sch.AccountID], planned)
+		s.scheduleNext[sch.ID] = time.Now().In(s.loc).Add(scheduleDelay(sch, s.Rand))
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	return managed
+}
+
+// runScheduleBatch mengirim campaign jadwal ke grup-grup eligible milik akunnya. planned adalah
+// waktu rencana kirim pertama; kirim berikutnya direncanakan setelah jeda acak.
+func (s *Scheduler) runScheduleBatch(ctx context.Context, sch model.Schedule, accountLimit int, planned time.Time) {
+	if err := s.Sender.EnsureConnected(sch.AccountID); err != nil {
+		log.Printf("[scheduler] schedule=%s account=%s connectIfPaired=skip err=%v", sch.ID, sch.AccountID, err)
+		return
+	}
+	sent, err := s.Store.CountSentToday(sch.AccountID, sch.CampaignID)
+	if err != nil {
+		log.Printf("[scheduler] schedule=%s sentToday-query-err=%v", sch.ID, err)
+		return
+	}
+	if accountLimit <= 0 {
+		accountLimit = 100
+	}
+	accSent, err := s.countSentTodayForAccount(sch.AccountID)
+	if err != nil {
+		log.Printf("[scheduler] schedule=%s account sentToday-query-err=%v", sch.ID, err)
+		return
+	}
+	n := sch.BatchSize
+	if left := sch.DailyLimit - sent; left < n {
+		n = left
+	}
+	if left := accountLimit - int(accSent); left < n {
+		n = left
+	}
+	if n <= 0 {
+		log.Printf("[scheduler] schedule=%s account=%s limit reached (schedule %d/%d, account %d/%d)",
+			sch.ID, sch.AccountID, sent, sch.DailyLimit, accSent, accountLimit)
+		return
+	}
+
+	targeting, _, err := s.Store.GetCampaignTargeting(sch.CampaignID)
+	if err != nil {
+		log.Printf("[scheduler] schedule=%s targeting err=%v", sch.ID, err)
+		return
+	}
+	promoted, err := s.Store.CampaignPromoteGroup(sch.CampaignID)
+	if err != nil {
+		log.Printf("[scheduler] schedule=%s cross-promo err=%v", sch.ID, err)
+		return
+	}
+	log.Printf("[scheduler] SCHEDULE_BATCH schedule=%s campaign=%s account=%s batch=%d", sch.ID, sch.CampaignID, sch.AccountID, n)
+	for i := 0; i < n; i++ {
+		if i > 0 {
+			delay := scheduleDelay(sch, s.Rand)
+			planned = time.Now().Add(delay)
+			select {
+			case <-time.After(delay):
+			case <-ctx.Done():
+				return
+			}
+			// Jeda scheduler berlaku di tengah batch, tidak menunggu batch selesai.
+			if s.paused() {
+				return
+			}
+		}
+		var groupID string
+		switch {
+		case promoted != "":
+			groupID, err = s.Store.PickCrossPromoTarget(sch.AccountID, s.cooldownHr, s.riskThreshold, promoted, targeting, s.Rand)
+		case targeting.Empty():
+			groupID, err = s.pickOneEligibleGroup(sch.AccountID, s.cooldownHr, s.riskThreshold)
+		default:
+			groupID, err = s.Store.PickTargetedGroup(sch.AccountID, s.cooldownHr, s.riskThreshold, targeting, s.Rand)
+		}
+		if err != nil {
+			log.Printf("[scheduler] schedule=%s PICK_GROUP_ERROR err=%v", sch.ID, err)
+			return
+		}
+		if groupID == "" {
+			log.Printf("[scheduler] schedule=%s NO_ELIGIBLE_GROUPS account=%s", sch.ID, sch.AccountID)
+			return
+		}
+		sendCtx, cancel := context.WithTimeout(sender.WithPlannedAt(ctx, planned), 90*time.Second)
+		err = s.Sender.SendCampaign(sendCtx, sch.AccountID, groupID, sch.CampaignID)
+		cancel()
+		s.releaseDryRun(ctx, groupID)
+		if err != nil {
+			log.Printf("[scheduler] schedule=%s send failed group=%s err=%v", sch.ID, groupID, err)
+			continue
+		}
+		log.Printf("[scheduler] schedule=%s send success group=%s (%d/%d)", sch.ID, groupID, i+1, n)
+	}
+}
+
+func scheduleDelay(sch model.Schedule, r *rng.Rand) time.Duration {
+	return r.Between(sch.MinDelaySec, sch.MaxDelaySec)
+}
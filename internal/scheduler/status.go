@@ -0,0 +1,51 @@
+package scheduler
+
+import (
+	"context"
+	"log"
+	"sort"
+	"time"
+)
+
+// statusRetry jeda sebelum akun yang gagal posting status dicoba lagi.
+const statusRetry = 30 * time.Minute
+
+// runStatuses memposting template acak sebagai status (story) untuk paling banyak satu akun per
+// tick: akun dengan status_daily_limit > 0 yang kuota hari ininya belum habis dan status terakhirnya
+// lebih lama dari status_min_interval_min. Dipanggil hanya di dalam jendela kirim.
+func (s *Scheduler) runStatuses(ctx context.Context, now time.Time) {
+	minInterval, err := s.Store.StatusMinInterval()
+	if err != nil {
+		log.Printf("[scheduler] status interval err=%v", err)
+		return
+	}
+	due, err := s.Store.DueStatusAccounts(time.Duration(minInterval) * time.Minute)
+	if err != nil {
+		log.Printf("[scheduler] due status accounts err=%v", err)
+		return
+	}
+	if s.statusNext == nil {
+		s.statusNext = map[string]time.Time{}
+	}
+	ids := make([]string, 0, len(due))
+	for id := range due {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		if now.Before(s.statusNext[id]) {
+			continue
+		}
+		sendCtx, cancel := context.WithTimeout(ctx, 2*time.Minute)
+		err := s.Sender.PostRandomTemplateStatus(sendCtx, id)
+		cancel()
+		if err != nil {
+			s.statusNext[id] = now.Add(statusRetry)
+			log.Printf("[scheduler] STATUS_POST failed account=%s err=%v", id, err)
+			return
+		}
+		delete(s.statusNext, id)
+		log.Printf("[scheduler] STATUS_POST account=%s (daily limit %d)", id, due[id])
+		return
+	}
+}
@@ -0,0 +1,59 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"promote/internal/sender"
+	"promote/internal/storage"
+	"promote/internal/storage/storagetest"
+)
+
+func TestScenarioStatusPostRespectsDailyLimit(t *testing.T) {
+	s, st, fake := scenario(t)
+	storagetest.SeedAccount(t, st, storagetest.Account{ID: "a"})
+	storagetest.SeedAccount(t, st, storagetest.Account{ID: "off"})
+	if err := st.SetStatusDailyLimit("a", 1); err != nil {
+		t.Fatal(err)
+	}
+	if err := st.SetStatusMinInterval(1); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 3; i++ {
+		s.runStatuses(context.Background(), time.Now())
+	}
+
+	sent := fake.SentTo("status@broadcast")
+	if len(sent) != 1 {
+		t.Fatalf("status posts = %d, want 1 (daily limit)", len(sent))
+	}
+	if sent[0].AccountID != "a" || sent[0].Kind != "text" || sent[0].Text != "Halo grup!" {
+		t.Fatalf("status = %+v", sent[0])
+	}
+	if n, err := st.CountStatusPostsToday("a"); err != nil || n != 1 {
+		t.Fatalf("status posts today = %d, %v", n, err)
+	}
+}
+
+func TestScenarioStatusPostRefusedInSafeMode(t *testing.T) {
+	s, st, fake := scenario(t)
+	storagetest.SeedAccount(t, st, storagetest.Account{ID: "a"})
+	if err := st.SetStatusMinInterval(1); err != nil {
+		t.Fatal(err)
+	}
+	if err := st.SetSetting(storage.SettingSafeMode, "1"); err != nil {
+		t.Fatal(err)
+	}
+
+	s.runStatuses(context.Background(), time.Now())
+	if sent := fake.SentTo("status@broadcast"); len(sent) != 0 {
+		t.Fatalf("status posts in safe mode = %d, want 0", len(sent))
+	}
+	err := s.Sender.PostStatus(context.Background(), "a", sender.MessageContent{TextOnly: "halo"})
+	if !errors.Is(err, sender.ErrSafeModeBroadcast) {
+		t.Fatalf("PostStatus err = %v, want ErrSafeModeBroadcast", err)
+	}
+}
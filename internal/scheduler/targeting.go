@@ -0,0 +1,36 @@
+package scheduler
+
+import (
+	"log"
+
+	"promote/internal/storage"
+)
+
+// pickGroup memilih grup eligible untuk akun. Jika ada campaign aktif dengan targeting, grup
+// harus cocok dengan targeting salah satu campaign (dicoba dalam urutan acak) dan ID campaign
+// tersebut dikembalikan; tanpa campaign bertarget, perilaku lama (semua grup aktif) dipakai.
+// Campaign cross-promo melewati grup yang sudah pernah menerima promo grup yang sama.
+func (s *Scheduler) pickGroup(accountID string, campaigns []storage.CampaignTargeting) (groupID, campaignID string, err error) {
+	if len(campaigns) == 0 {
+		groupID, err = s.pickOneEligibleGroup(accountID, s.cooldownHr, s.riskThreshold)
+		return groupID, "", err
+	}
+	order := s.Rand.Perm(len(campaigns))
+	for _, i := range order {
+		c := campaigns[i]
+		if c.PromoteGroupID != "" {
+			groupID, err = s.Store.PickCrossPromoTarget(accountID, s.cooldownHr, s.riskThreshold, c.PromoteGroupID, c.Targeting, s.Rand)
+		} else {
+			groupID, err = s.Store.PickTargetedGroup(accountID, s.cooldownHr, s.riskThreshold, c.Targeting, s.Rand)
+		}
+		if err != nil {
+			log.Printf("[scheduler] TARGETING_ERROR account=%s campaign=%s err=%v", accountID, c.ID, err)
+			continue
+		}
+		if groupID != "" {
+			log.Printf("[scheduler] TARGETED account=%s campaign=%s(%s) group=%s", accountID, c.ID, c.Name, groupID)
+			return groupID, c.ID, nil
+		}
+	}
+	return "", "", nil
+}
@@ -0,0 +1,41 @@
+package scheduler
+
+import (
+	"log"
+	"strings"
+)
+
+// evaluateTrials memulai masa uji untuk grup yang baru diaktifkan lalu memberi verdict untuk masa
+// uji yang sudah mencapai N kiriman (atau akun dikeluarkan dari grup): lolos tetap aktif, gagal
+// dinonaktifkan. Tidak melakukan apa-apa selama setting group_trial nonaktif.
+func (s *Scheduler) evaluateTrials() {
+	cfg, err := s.Store.GroupTrialSettings()
+	if err != nil {
+		log.Printf("[scheduler] group trial settings err=%v", err)
+		return
+	}
+	if !cfg.Enabled {
+		return
+	}
+	if n, err := s.Store.StartGroupTrials(); err != nil {
+		log.Printf("[scheduler] start group trials err=%v", err)
+	} else if n > 0 {
+		log.Printf("[scheduler] GROUP_TRIAL_STARTED groups=%d sends=%d", n, cfg.Sends)
+	}
+	open, err := s.Store.ListGroupTrials("", "open", 500)
+	if err != nil {
+		log.Printf("[scheduler] list group trials err=%v", err)
+		return
+	}
+	for _, t := range open {
+		verdict, reason := cfg.Evaluate(t)
+		if verdict == "" {
+			continue
+		}
+		if _, err := s.Store.DecideGroupTrial(t.GroupID, verdict, reason); err != nil {
+			log.Printf("[scheduler] decide group trial group=%s err=%v", t.GroupID, err)
+			continue
+		}
+		log.Printf("[scheduler] GROUP_TRIAL_%s account=%s group=%s reason=%s", strings.ToUpper(verdict), t.AccountID, t.GroupID, reason)
+	}
+}
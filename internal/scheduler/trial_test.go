@@ -0,0 +1,52 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"promote/internal/model"
+	"promote/internal/storage/storagetest"
+)
+
+func TestGroupTrialVerdicts(t *testing.T) {
+	s, st, _ := scenario(t)
+	storagetest.SeedAccount(t, st, storagetest.Account{ID: "a"})
+	seedGroups(t, st, "a", "good@g.us", "bad@g.us", "kick@g.us", "slow@g.us")
+	if err := st.SetGroupTrialSettings(model.GroupTrialSettings{Enabled: true, Sends: 2, MaxFailRate: 0.4}); err != nil {
+		t.Fatal(err)
+	}
+
+	s.evaluateTrials()
+	if open, _ := st.ListGroupTrials("a", "open", 10); len(open) != 4 {
+		t.Fatalf("open trials = %d, want 4", len(open))
+	}
+
+	at := time.Now().Add(time.Second)
+	storagetest.SeedLog(t, st, "a", "good@g.us", "sent", at)
+	storagetest.SeedLog(t, st, "a", "good@g.us", "sent", at)
+	storagetest.SeedLog(t, st, "a", "bad@g.us", "failed", at)
+	storagetest.SeedLog(t, st, "a", "bad@g.us", "sent", at)
+	storagetest.SeedLog(t, st, "a", "slow@g.us", "sent", at)
+	if err := st.RecordTrialKick("a", "kick@g.us"); err != nil {
+		t.Fatal(err)
+	}
+
+	s.evaluateTrials()
+	want := map[string]string{"good@g.us": model.TrialPromoted, "bad@g.us": model.TrialDisabled,
+		"kick@g.us": model.TrialDisabled, "slow@g.us": ""}
+	trials, err := st.ListGroupTrials("a", "", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tr := range trials {
+		if tr.Verdict != want[tr.GroupID] {
+			t.Fatalf("%s verdict = %q (%s), want %q", tr.GroupID, tr.Verdict, tr.Reason, want[tr.GroupID])
+		}
+	}
+	groups, _ := st.ListGroups("a")
+	for _, g := range groups {
+		if g.Enabled != (want[g.ID] != model.TrialDisabled) {
+			t.Fatalf("%s enabled = %v after verdict %q", g.ID, g.Enabled, want[g.ID])
+		}
+	}
+}
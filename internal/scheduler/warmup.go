@@ -0,0 +1,34 @@
+package scheduler
+
+import (
+	"log"
+	"time"
+)
+
+// applyWarmup membatasi DailyLimit akun dengan profil warmup (profil khusus akun, atau profil
+// default) menurut umur akun pada now. Jika profil gagal dibaca, limit dibiarkan.
+func (s *Scheduler) applyWarmup(accs []accountLite, now time.Time) {
+	def, err := s.Store.WarmupDefault()
+	if err != nil {
+		log.Printf("[scheduler] warmup profile err=%v", err)
+		return
+	}
+	custom, err := s.Store.AccountWarmups()
+	if err != nil {
+		log.Printf("[scheduler] account warmup err=%v", err)
+		return
+	}
+	for i, a := range accs {
+		p, ok := custom[a.ID]
+		if !ok {
+			p = def
+		}
+		limit := a.DailyLimit
+		if limit <= 0 {
+			limit = 100
+		}
+		if w := p.Apply(limit, a.PairedAt, now); w < limit {
+			accs[i].DailyLimit = w
+		}
+	}
+}
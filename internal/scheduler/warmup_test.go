@@ -0,0 +1,45 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"promote/internal/model"
+	"promote/internal/storage/storagetest"
+)
+
+func TestWarmupCapsNewAccounts(t *testing.T) {
+	s, st, _ := scenario(t)
+	storagetest.SeedAccount(t, st, storagetest.Account{ID: "new", DailyLimit: 100})
+	storagetest.SeedAccount(t, st, storagetest.Account{ID: "old", DailyLimit: 80})
+	storagetest.SeedAccount(t, st, storagetest.Account{ID: "optout", DailyLimit: 50})
+	for id, age := range map[string]time.Duration{"new": 50 * time.Hour, "old": 60 * 24 * time.Hour, "optout": time.Hour} {
+		if _, err := st.DB.Exec(`UPDATE accounts SET first_paired_at=? WHERE id=?`, time.Now().Add(-age).UTC(), id); err != nil {
+			t.Fatal(err)
+		}
+	}
+	p := model.DefaultWarmupProfile()
+	p.Enabled = true
+	if err := st.SetWarmupDefault(p); err != nil {
+		t.Fatal(err)
+	}
+	if err := st.SetAccountWarmup("optout", model.WarmupProfile{}); err != nil {
+		t.Fatal(err)
+	}
+
+	accs, err := s.listEnabledAccounts()
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := map[string]int{}
+	for _, a := range accs {
+		got[a.ID] = a.DailyLimit
+	}
+	// Hari ke-3: 5 + (30-5)*(3-1)/(7-1) = 13; akun lama dan akun tanpa warmup memakai daily_limit.
+	want := map[string]int{"new": 13, "old": 80, "optout": 50}
+	for id, n := range want {
+		if got[id] != n {
+			t.Fatalf("daily limit %s = %d, want %d (all: %v)", id, got[id], n, got)
+		}
+	}
+}
@@ -0,0 +1,15 @@
+package scheduler
+
+import "time"
+
+// windowCap batas kiriman kumulatif akun hari ini pada now menurut pembagian limit per jendela
+// (setting window_budget) atas jendela yang berlaku untuk akun. Tanpa pembagian atau saat
+// alwaysOn, batasnya limit harian penuh.
+func (s *Scheduler) windowCap(now time.Time, accountID string, limit int) int {
+	if s.alwaysOn {
+		return limit
+	}
+	s.windowsMu.RLock()
+	defer s.windowsMu.RUnlock()
+	return s.budget.Cap(s.windowsFor(accountID).For(now), now, s.Store.Day.ResetHour, limit)
+}
@@ -0,0 +1,59 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"promote/internal/model"
+	"promote/internal/storage/storagetest"
+)
+
+func TestWindowCapSpreadsDailyLimit(t *testing.T) {
+	st := storagetest.Open(t)
+	loc := time.FixedZone("WIB", 7*3600)
+	var wins model.WeekWindows
+	for d := range wins {
+		// 60, 120 dan 120 menit: porsi 20%, 40%, 40%.
+		wins[d] = [][2]int{{21 * 60, 23 * 60}, {60, 120}, {180, 300}}
+	}
+	if err := st.SetSendWindows(wins); err != nil {
+		t.Fatal(err)
+	}
+	s := &Scheduler{Store: st, loc: loc}
+	s.refreshWindows()
+	day := time.Date(2026, 3, 2, 0, 0, 0, 0, loc)
+	if got := s.windowCap(day.Add(90*time.Minute), "a", 100); got != 100 {
+		t.Fatalf("budget off: cap = %d, want 100", got)
+	}
+
+	if err := st.SetWindowBudget(model.WindowBudget{Mode: model.WindowBudgetProportional}); err != nil {
+		t.Fatal(err)
+	}
+	s.refreshWindows()
+	for _, c := range []struct {
+		at   time.Duration
+		want int
+	}{
+		{30 * time.Minute, 100}, // belum ada jendela dimulai
+		{90 * time.Minute, 20},
+		{150 * time.Minute, 20}, // di antara jendela: sisa porsi menunggu jendela berikutnya
+		{4 * time.Hour, 60},
+		{22 * time.Hour, 100},
+	} {
+		if got := s.windowCap(day.Add(c.at), "a", 100); got != c.want {
+			t.Fatalf("proportional at %s: cap = %d, want %d", c.at, got, c.want)
+		}
+	}
+
+	if err := st.SetWindowBudget(model.WindowBudget{Mode: model.WindowBudgetWeighted, Weights: []int{1, 1}}); err != nil {
+		t.Fatal(err)
+	}
+	s.refreshWindows()
+	if got := s.windowCap(day.Add(90*time.Minute), "a", 10); got != 4 {
+		t.Fatalf("weighted first window: cap = %d, want 4 (ceil 10/3)", got)
+	}
+	s.alwaysOn = true
+	if got := s.windowCap(day.Add(90*time.Minute), "a", 10); got != 10 {
+		t.Fatalf("always on: cap = %d, want 10", got)
+	}
+}
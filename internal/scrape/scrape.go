@@ -0,0 +1,274 @@
+// Package scrape menyegarkan cache anggota grup secara bertahap di latar belakang. Grup diambil
+// satu per satu dengan jeda (plus jitter) agar tidak memicu rate limit WhatsApp; grup yang cache-nya
+// masih segar dilewati sehingga job bisa dihentikan dan dilanjutkan kapan saja.
+package scrape
+
+import (
+	"context"
+	"errors"
+	"log"
+	"math/rand"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"promote/internal/storage"
+	"promote/internal/wa"
+)
+
+var ErrRunning = errors.New("scrape job already running")
+
+// Progress status job scraping yang sedang/terakhir berjalan.
+type Progress struct {
+	Running    bool       `json:"running"`
+	AccountID  string     `json:"account_id,omitempty"`
+	Force      bool       `json:"force"`
+	Total      int        `json:"total"`
+	Done       int        `json:"done"`
+	Refreshed  int        `json:"refreshed"`
+	Failed     int        `json:"failed"`
+	Current    string     `json:"current,omitempty"`
+	Delay      string     `json:"delay"`
+	LastError  string     `json:"last_error,omitempty"`
+	Cancelled  bool       `json:"cancelled,omitempty"`
+	StartedAt  *time.Time `json:"started_at,omitempty"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+}
+
+// Scraper menjalankan paling banyak satu job scraping dalam satu waktu.
+type Scraper struct {
+	Store   *storage.Store
+	Manager *wa.Manager
+	// Jeda dasar antar grup (SCRAPE_DELAY_SEC, default 10 detik) ditambah jitter acak
+	// (SCRAPE_JITTER_SEC, default 5 detik).
+	Delay  time.Duration
+	Jitter time.Duration
+	// Cache lebih muda dari MaxAge dilewati (SCRAPE_MAX_AGE_HOURS, default 24 jam).
+	MaxAge time.Duration
+	// Batas atas jeda saat backoff karena rate limit.
+	MaxDelay time.Duration
+	// Interval job otomatis untuk semua akun (SCRAPE_EVERY_HOURS, 0 = nonaktif).
+	Every time.Duration
+
+	mu       sync.Mutex
+	ctx      context.Context
+	cancel   context.CancelFunc
+	progress Progress
+}
+
+// New membuat Scraper dengan konfigurasi dari env.
+func New(store *storage.Store, manager *wa.Manager) *Scraper {
+	return &Scraper{
+		Store:    store,
+		Manager:  manager,
+		Delay:    envDuration("SCRAPE_DELAY_SEC", 10, time.Second),
+		Jitter:   envDuration("SCRAPE_JITTER_SEC", 5, time.Second),
+		MaxAge:   envDuration("SCRAPE_MAX_AGE_HOURS", 24, time.Hour),
+		MaxDelay: 10 * time.Minute,
+		Every:    envDuration("SCRAPE_EVERY_HOURS", 0, time.Hour),
+		ctx:      context.Background(),
+	}
+}
+
+func envDuration(key string, def int, unit time.Duration) time.Duration {
+	if n, err := strconv.Atoi(strings.TrimSpace(os.Getenv(key))); err == nil && n >= 0 {
+		return time.Duration(n) * unit
+	}
+	return time.Duration(def) * unit
+}
+
+// Start mengikat job ke ctx aplikasi dan, bila Every > 0, menjalankan job semua akun berkala.
+func (s *Scraper) Start(ctx context.Context) {
+	s.mu.Lock()
+	s.ctx = ctx
+	s.mu.Unlock()
+	if s.Every <= 0 {
+		return
+	}
+	go func() {
+		tick := time.NewTicker(s.Every)
+		defer tick.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-tick.C:
+				if err := s.Run("", false); err != nil && !errors.Is(err, ErrRunning) {
+					log.Printf("[scrape] scheduled run: %v", err)
+				}
+			}
+		}
+	}()
+}
+
+// Run memulai job di background. accountID kosong = semua akun aktif yang terhubung;
+// force = abaikan MaxAge dan segarkan semua grup aktif.
+func (s *Scraper) Run(accountID string, force bool) error {
+	s.mu.Lock()
+	if s.progress.Running {
+		s.mu.Unlock()
+		return ErrRunning
+	}
+	ctx, cancel := context.WithCancel(s.ctx)
+	now := time.Now()
+	s.cancel = cancel
+	s.progress = Progress{Running: true, AccountID: accountID, Force: force, StartedAt: &now, Delay: s.Delay.String()}
+	s.mu.Unlock()
+
+	go func() {
+		defer cancel()
+		s.run(ctx, accountID, force)
+	}()
+	return nil
+}
+
+// Stop membatalkan job yang sedang berjalan; false jika tidak ada job.
+func (s *Scraper) Stop() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.progress.Running || s.cancel == nil {
+		return false
+	}
+	s.cancel()
+	return true
+}
+
+// Status mengembalikan salinan progress terkini.
+func (s *Scraper) Status() Progress {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.progress
+}
+
+type job struct {
+	accountID string
+	groupID   string
+}
+
+func (s *Scraper) run(ctx context.Context, accountID string, force bool) {
+	jobs, err := s.collect(accountID, force)
+	if err != nil {
+		s.finish(err, false)
+		return
+	}
+	s.update(func(p *Progress) { p.Total = len(jobs) })
+	log.Printf("[scrape] start account=%q groups=%d force=%v", accountID, len(jobs), force)
+
+	delay := s.Delay
+	for i, j := range jobs {
+		if i > 0 {
+			select {
+			case <-ctx.Done():
+				s.finish(nil, true)
+				return
+			case <-time.After(delay + jitter(s.Jitter)):
+			}
+		}
+		s.update(func(p *Progress) { p.Current = j.groupID })
+
+		fctx, cancel := context.WithTimeout(ctx, 45*time.Second)
+		parts, err := s.Manager.RefreshGroupParticipants(fctx, j.accountID, j.groupID)
+		cancel()
+		if ctx.Err() != nil {
+			s.finish(nil, true)
+			return
+		}
+		if err != nil && rateLimited(err) {
+			// Mundur: gandakan jeda untuk sisa job agar WhatsApp tidak memblokir akun.
+			delay *= 2
+			if delay > s.MaxDelay {
+				delay = s.MaxDelay
+			}
+			log.Printf("[scrape] rate limited on group=%s, delay now %s", j.groupID, delay)
+		}
+		s.update(func(p *Progress) {
+			p.Done++
+			p.Delay = delay.String()
+			if err != nil {
+				p.Failed++
+				p.LastError = j.groupID + ": " + err.Error()
+				return
+			}
+			p.Refreshed++
+		})
+		if err == nil {
+			log.Printf("[scrape] group=%s members=%d (%d/%d)", j.groupID, len(parts), i+1, len(jobs))
+		}
+	}
+	s.finish(nil, false)
+}
+
+// collect menyusun daftar grup yang perlu di-scrape, dikelompokkan per akun.
+func (s *Scraper) collect(accountID string, force bool) ([]job, error) {
+	var ids []string
+	if accountID != "" {
+		ids = []string{accountID}
+	} else {
+		accs, err := s.Store.ListAccounts()
+		if err != nil {
+			return nil, err
+		}
+		for _, a := range accs {
+			if a.Enabled {
+				ids = append(ids, a.ID)
+			}
+		}
+	}
+	cutoff := time.Now().Add(-s.MaxAge)
+	if force {
+		cutoff = time.Now()
+	}
+	var jobs []job
+	for _, id := range ids {
+		if _, connected := s.Manager.ConnectionState(id); !connected {
+			if accountID != "" {
+				return nil, errors.New("account not connected")
+			}
+			continue
+		}
+		groups, err := s.Store.StaleParticipantGroups(id, cutoff)
+		if err != nil {
+			return nil, err
+		}
+		for _, gid := range groups {
+			jobs = append(jobs, job{accountID: id, groupID: gid})
+		}
+	}
+	return jobs, nil
+}
+
+func (s *Scraper) update(fn func(p *Progress)) {
+	s.mu.Lock()
+	fn(&s.progress)
+	s.mu.Unlock()
+}
+
+func (s *Scraper) finish(err error, cancelled bool) {
+	now := time.Now()
+	s.update(func(p *Progress) {
+		p.Running = false
+		p.Current = ""
+		p.Cancelled = cancelled
+		p.FinishedAt = &now
+		if err != nil {
+			p.LastError = err.Error()
+		}
+	})
+	p := s.Status()
+	log.Printf("[scrape] finished refreshed=%d failed=%d total=%d cancelled=%v", p.Refreshed, p.Failed, p.Total, cancelled)
+}
+
+// rateLimited mendeteksi error rate limit dari server WhatsApp.
+func rateLimited(err error) bool {
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "rate") || strings.Contains(msg, "429") || strings.Contains(msg, "timeout")
+}
+
+func jitter(max time.Duration) time.Duration {
+	if max <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int63n(int64(max)))
+}
@@ -0,0 +1,128 @@
+package sender
+
+import (
+	"context"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.mau.fi/whatsmeow/binary/proto"
+	"go.mau.fi/whatsmeow/types"
+	"go.mau.fi/whatsmeow/types/events"
+)
+
+// defaultAckTimeout: pesan tanpa receipt delivered selama ini dianggap soft bounce.
+const defaultAckTimeout = 15 * time.Minute
+
+type sendMetaKey struct{}
+
+type sendMeta struct {
+	accountID string
+	sessionID string
+	// lastID ID pesan terakhir yang terkirim, diambil (dan dikosongkan) oleh logResult.
+	lastID string
+	// templateID/templateVersion versi template yang sedang dikirim (kosong untuk konten non-template).
+	templateID      string
+	templateVersion int
+	// throttled diset throttle: bagian yang gagal dicatat dengan status throttled.
+	throttled bool
+}
+
+func withSendMeta(ctx context.Context, accountID, sessionID string) context.Context {
+	return context.WithValue(ctx, sendMetaKey{}, &sendMeta{accountID: accountID, sessionID: sessionID})
+}
+
+// sendMetaFrom metadata kirim di ctx; nil jika tidak ada.
+func sendMetaFrom(ctx context.Context) *sendMeta {
+	meta, _ := ctx.Value(sendMetaKey{}).(*sendMeta)
+	return meta
+}
+
+// takeMessageID mengembalikan ID pesan terakhir yang terkirim dalam sesi ctx lalu
+// mengosongkannya agar tidak tercatat dua kali.
+func takeMessageID(ctx context.Context) string {
+	meta := sendMetaFrom(ctx)
+	if meta == nil {
+		return ""
+	}
+	id := meta.lastID
+	meta.lastID = ""
+	return id
+}
+
+type plannedKey struct{}
+
+// WithPlannedAt menandai waktu rencana kirim (jadwal campaign atau scheduled_at outbox); selisihnya
+// dengan waktu kirim aktual dilaporkan sebagai keterlambatan di /api/slo.
+func WithPlannedAt(ctx context.Context, t time.Time) context.Context {
+	return context.WithValue(ctx, plannedKey{}, t)
+}
+
+// sendMessage mengirim pesan dan, jika ada metadata akun di ctx, mencatatnya untuk pelacakan ack.
+func (s *Sender) sendMessage(ctx context.Context, c Transport, jid types.JID, msg *proto.Message) error {
+	start := time.Now()
+	resp, err := c.SendMessage(ctx, jid, msg)
+	if err != nil {
+		return err
+	}
+	rtt := time.Since(start)
+	if meta := sendMetaFrom(ctx); meta != nil && resp.ID != "" {
+		meta.lastID = resp.ID
+		sentAt := resp.Timestamp
+		if sentAt.IsZero() {
+			sentAt = time.Now()
+		}
+		planned, _ := ctx.Value(plannedKey{}).(time.Time)
+		if err := s.Store.InsertMessageAck(resp.ID, meta.accountID, jid.String(), meta.sessionID, sentAt, rtt, planned); err != nil {
+			log.Printf("[sender] track ack msg=%s err=%v", resp.ID, err)
+		}
+	}
+	return nil
+}
+
+// HandleReceipt menandai pesan delivered saat receipt dari penerima masuk.
+// Didaftarkan lewat Manager.AddReceiptHandler.
+func (s *Sender) HandleReceipt(accountID string, evt *events.Receipt) {
+	switch evt.Type {
+	case types.ReceiptTypeDelivered, types.ReceiptTypeRead, types.ReceiptTypePlayed:
+	default:
+		return
+	}
+	if !evt.IsFromMe && len(evt.MessageIDs) > 0 {
+		ids := make([]string, len(evt.MessageIDs))
+		for i, id := range evt.MessageIDs {
+			ids[i] = string(id)
+		}
+		if _, err := s.Store.MarkMessagesDelivered(accountID, ids, evt.Timestamp); err != nil {
+			log.Printf("[sender] mark delivered account=%s err=%v", accountID, err)
+		}
+	}
+}
+
+// StartAckWatcher menandai pesan pending yang melewati timeout sebagai soft bounce (cek tiap menit).
+// Timeout dari SEND_ACK_TIMEOUT_MIN (default 15 menit).
+func (s *Sender) StartAckWatcher(ctx context.Context) {
+	timeout := defaultAckTimeout
+	if n, err := strconv.Atoi(strings.TrimSpace(os.Getenv("SEND_ACK_TIMEOUT_MIN"))); err == nil && n > 0 {
+		timeout = time.Duration(n) * time.Minute
+	}
+	go func() {
+		tick := time.NewTicker(time.Minute)
+		defer tick.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-tick.C:
+				n, err := s.Store.MarkSoftBounces(time.Now().Add(-timeout))
+				if err != nil {
+					log.Printf("[sender] soft bounce sweep err=%v", err)
+				} else if n > 0 {
+					log.Printf("[sender] SOFT_BOUNCE marked=%d timeout=%s", n, timeout)
+				}
+			}
+		}
+	}()
+}
@@ -0,0 +1,130 @@
+package sender
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"go.mau.fi/whatsmeow"
+	"go.mau.fi/whatsmeow/binary/proto"
+	"go.mau.fi/whatsmeow/types"
+
+	"promote/internal/model"
+)
+
+// ErrEmptyChannelPost dikembalikan jika konten posting channel tidak punya teks maupun gambar.
+var ErrEmptyChannelPost = errors.New("channel post has no text or image")
+
+// PostToChannel memposting konten ke WhatsApp Channel milik akun: teks lalu gambar pertama
+// beserta caption (media channel tidak dienkripsi dan diunggah lewat UploadNewsletter). Setiap
+// bagian dicatat di channel_posts; {group_name} diganti nama channel. Akun observer ditolak,
+// begitu pula selama safe mode aktif.
+func (s *Sender) PostToChannel(ctx context.Context, accountID, channelID string, content MessageContent) error {
+	if strings.TrimSpace(content.TextOnly) == "" && len(content.ImageURLs) == 0 {
+		return ErrEmptyChannelPost
+	}
+	if err := s.checkObserver(accountID); err != nil {
+		return err
+	}
+	if err := s.checkThrottled(accountID); err != nil {
+		return err
+	}
+	if err := s.checkSafeModeBroadcast(); err != nil {
+		return err
+	}
+	ctx = withSendMeta(ctx, accountID, "")
+	ch, err := s.Store.GetChannel(channelID)
+	if err != nil {
+		return err
+	}
+	jid, err := types.ParseJID(channelID)
+	if err != nil || jid.Server != types.NewsletterServer {
+		return fmt.Errorf("invalid channel jid %q", channelID)
+	}
+	if err := s.EnsureConnected(accountID); err != nil {
+		return err
+	}
+	cli, err := s.transport(accountID)
+	if err != nil {
+		return err
+	}
+	content = s.resolveVars(ctx, content)
+	content = s.rewriteLinks(content)
+
+	record := func(preview, msgID string, err error) {
+		p := model.ChannelPost{ChannelID: channelID, AccountID: accountID, TemplateID: content.TemplateID,
+			TemplateVersion: content.TemplateVersion, Status: "sent", MessageID: msgID, Preview: preview}
+		if err != nil {
+			p.Status, p.Error = "failed", err.Error()
+		}
+		if errors.Is(err, ErrThrottled) {
+			p.Status = StatusThrottled
+		}
+		if rerr := s.Store.InsertChannelPost(p); rerr != nil {
+			log.Printf("[sender] record channel post channel=%s err=%v", channelID, rerr)
+		}
+	}
+
+	if text := personalize(strings.TrimSpace(content.TextOnly), ch.Name, nil); text != "" {
+		var id string
+		err := s.withRetry(ctx, func() error {
+			resp, err := cli.SendMessage(ctx, jid, &proto.Message{Conversation: strptr(text)})
+			id = resp.ID
+			return err
+		})
+		record(short(text), id, err)
+		if err != nil {
+			return fmt.Errorf("post text: %w", err)
+		}
+	}
+	if len(content.ImageURLs) > 0 {
+		u := content.ImageURLs[0]
+		caption := personalize(content.ImageCaption, ch.Name, nil)
+		var id string
+		err := s.withRetry(ctx, func() error {
+			var err error
+			id, err = s.postImage(ctx, cli, jid, u, caption)
+			return err
+		})
+		record("image:"+u, id, err)
+		if err != nil {
+			return fmt.Errorf("post image: %w", err)
+		}
+	}
+	log.Printf("[sender] CHANNEL_POST account=%s channel=%s", accountID, channelID)
+	return nil
+}
+
+// postImage mengunggah gambar sebagai media newsletter lalu mempostingnya dengan handle upload.
+func (s *Sender) postImage(ctx context.Context, c Transport, jid types.JID, url, caption string) (string, error) {
+	data, mime, err := s.fetch(ctx, url)
+	if err != nil {
+		return "", err
+	}
+	up, err := c.UploadNewsletter(ctx, data, whatsmeow.MediaImage)
+	if err != nil {
+		return "", fmt.Errorf("upload image: %w", err)
+	}
+	length := uint64(len(data))
+	msg := &proto.Message{ImageMessage: &proto.ImageMessage{
+		Caption:    optstr(caption),
+		Mimetype:   optstr(mime),
+		URL:        optstr(up.URL),
+		DirectPath: optstr(up.DirectPath),
+		FileSHA256: up.FileSHA256,
+		FileLength: &length,
+	}}
+	resp, err := c.SendMessage(ctx, jid, msg, whatsmeow.SendRequestExtra{MediaHandle: up.Handle})
+	return resp.ID, err
+}
+
+// PostRandomTemplateToChannel memposting template aktif acak ke channel.
+func (s *Sender) PostRandomTemplateToChannel(ctx context.Context, accountID, channelID string) error {
+	content, err := s.RandomTemplateContent(ctx, "")
+	if err != nil {
+		return fmt.Errorf("no active template or query failed: %w", err)
+	}
+	return s.PostToChannel(ctx, accountID, channelID, content)
+}
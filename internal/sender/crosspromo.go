@@ -0,0 +1,65 @@
+package sender
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"promote/internal/model"
+	"promote/internal/storage"
+)
+
+// ErrCrossPromoLink dikembalikan jika grup yang dipromosikan belum punya link undangan yang
+// bisa dipakai (belum tersimpan, dicabut atau grup penuh).
+var ErrCrossPromoLink = errors.New("cross-promo invite link unavailable")
+
+// sendCrossPromo mengirim campaign cross-promo ke groupJID. Placeholder {promo_name},
+// {promo_link} dan {promo_members} diisi dari link undangan grup yang dipromosikan, foto grup
+// dipakai sebagai gambar jika campaign tidak punya media, lalu pasangan grup dicatat agar
+// promo yang sama tidak diulang.
+func (s *Sender) sendCrossPromo(ctx context.Context, accountID, groupJID string, c model.Campaign) error {
+	if c.PromoteGroupID == "" {
+		return fmt.Errorf("campaign %s has no promoted group", c.ID)
+	}
+	if groupJID == c.PromoteGroupID {
+		return fmt.Errorf("cross-promo target %s is the promoted group", groupJID)
+	}
+	src, err := s.Store.CrossPromoSource(c.PromoteGroupID)
+	if errors.Is(err, storage.ErrGroupLinkNotFound) {
+		return fmt.Errorf("%w: group %s has no stored invite link", ErrCrossPromoLink, c.PromoteGroupID)
+	}
+	if err != nil {
+		return err
+	}
+	if src.Status == model.LinkRevoked || src.Status == model.LinkFull {
+		return fmt.Errorf("%w: group %s link is %s", ErrCrossPromoLink, c.PromoteGroupID, src.Status)
+	}
+	c.Text = strings.NewReplacer(
+		"{promo_name}", src.GroupName,
+		"{promo_link}", src.InviteLink,
+		"{promo_members}", strconv.Itoa(src.MemberCount),
+	).Replace(c.Text)
+	if len(c.ImageURLs)+len(c.VideoURLs)+len(c.StickerURLs)+len(c.DocURLs) == 0 && s.Manager != nil && !IsDryRun(ctx) {
+		// Foto diambil lewat akun admin grup yang dipromosikan; gagal = kirim teks saja.
+		if url, err := s.Manager.GroupAvatarURL(ctx, src.AccountID, c.PromoteGroupID); err != nil {
+			log.Printf("[sender] cross-promo avatar group=%s err=%v", c.PromoteGroupID, err)
+		} else if url != "" {
+			c.ImageURLs = []string{url}
+		}
+	}
+	if err := s.SendToGroupWithSession(ctx, accountID, groupJID, CampaignContent(c), uuid.NewString()); err != nil {
+		return err
+	}
+	if IsDryRun(ctx) {
+		return nil
+	}
+	if err := s.Store.RecordCrossPromo(c.PromoteGroupID, groupJID, c.ID, accountID); err != nil {
+		log.Printf("[sender] record cross-promo %s->%s err=%v", c.PromoteGroupID, groupJID, err)
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+package sender
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"go.mau.fi/whatsmeow/types"
+)
+
+// ErrEmptyDM dikembalikan jika konten DM tidak punya teks maupun gambar.
+var ErrEmptyDM = errors.New("dm content has no text or image")
+
+// SendDirect mengirim DM ke nomor (62812...): teks lalu gambar pertama beserta caption-nya.
+// Akun observer ditolak dengan ErrObserverAccount, nomor di daftar supresi dengan ErrSuppressed. Variabel dinamis dan short link diproses seperti kiriman grup; saat safe mode aktif DM
+// dialihkan ke grup uji akun. DM tidak dicatat di logs maupun pelacakan ack grup; catat
+// hasilnya lewat Store.RecordDMSend.
+func (s *Sender) SendDirect(ctx context.Context, accountID, number string, content MessageContent) error {
+	if strings.TrimSpace(content.TextOnly) == "" && len(content.ImageURLs) == 0 {
+		return ErrEmptyDM
+	}
+	if err := s.checkObserver(accountID); err != nil {
+		return err
+	}
+	if err := s.checkThrottled(accountID); err != nil {
+		return err
+	}
+	if err := s.checkSuppressed(number); err != nil {
+		return err
+	}
+	cli, err := s.transport(accountID)
+	if err != nil {
+		return err
+	}
+	if !cli.Paired() || !cli.IsConnected() {
+		return fmt.Errorf("account %s not connected", accountID)
+	}
+	content = s.resolveVars(ctx, content)
+	content = s.rewriteLinks(content)
+
+	jid := types.NewJID(number, types.DefaultUserServer)
+	target, note, err := s.safeModeTarget(accountID, jid.String(), number)
+	if err != nil {
+		return err
+	}
+	if target != jid.String() {
+		log.Printf("[sender] SAFE_MODE account=%s intended=%s redirect=%s", accountID, jid, target)
+		content = annotateSafeMode(content, note)
+		if jid, err = types.ParseJID(target); err != nil {
+			return fmt.Errorf("parse JID: %w", err)
+		}
+	}
+
+	ctx = withSendMeta(ctx, accountID, "")
+	if text := strings.TrimSpace(content.TextOnly); text != "" {
+		if err := s.sendText(ctx, cli, jid, text); err != nil {
+			if isThrottled(err) {
+				err = s.throttle(ctx, err)
+			}
+			return fmt.Errorf("send text: %w", err)
+		}
+	}
+	if len(content.ImageURLs) > 0 {
+		if err := s.sendImageByURL(ctx, cli, jid, content.ImageURLs[0], content.ImageCaption); err != nil {
+			if isThrottled(err) {
+				err = s.throttle(ctx, err)
+			}
+			return fmt.Errorf("send image: %w", err)
+		}
+	}
+	return nil
+}
+
+// SendDMCampaign mengirim campaign mode dm ke satu nomor (teks campaign sebagai caption gambar
+// pertama, atau teks saja) dan mencatat hasilnya di dm_sends.
+func (s *Sender) SendDMCampaign(ctx context.Context, accountID, number, campaignID string) error {
+	c, err := s.Store.GetCampaign(campaignID)
+	if err != nil {
+		return err
+	}
+	err = s.SendDirect(WithCampaign(ctx, campaignID), accountID, number, CampaignContent(c))
+	if errors.Is(err, ErrSuppressed) || errors.Is(err, ErrObserverAccount) {
+		return err
+	}
+	status, errMsg := "sent", ""
+	switch {
+	case errors.Is(err, ErrThrottled):
+		status, errMsg = StatusThrottled, err.Error()
+	case err != nil:
+		status, errMsg = "failed", err.Error()
+	}
+	if rerr := s.Store.RecordDMSend(accountID, number, campaignID, status, errMsg); rerr != nil {
+		log.Printf("[sender] record dm account=%s number=%s err=%v", accountID, number, rerr)
+	}
+	return err
+}
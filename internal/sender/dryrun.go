@@ -0,0 +1,76 @@
+package sender
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"promote/internal/model"
+)
+
+// StatusWouldSend status logs untuk kiriman mode dry-run: pemilihan akun, grup dan konten
+// berjalan penuh tetapi WhatsApp tidak dipanggil.
+const StatusWouldSend = "would_send"
+
+type dryRunKey struct{}
+
+// WithDryRun menandai kiriman di ctx sebagai dry-run.
+func WithDryRun(ctx context.Context) context.Context {
+	return context.WithValue(ctx, dryRunKey{}, true)
+}
+
+// IsDryRun true jika ctx ditandai WithDryRun.
+func IsDryRun(ctx context.Context) bool {
+	v, _ := ctx.Value(dryRunKey{}).(bool)
+	return v
+}
+
+// logWouldSend mencatat satu entri would_send berisi ringkasan konten yang akan dikirim.
+func (s *Sender) logWouldSend(ctx context.Context, accountID, groupJID, campaignID, sessionID string, content MessageContent, groupName string, fields map[string]string) error {
+	return s.Store.InsertLog(model.LogEntry{
+		AccountID:       accountID,
+		GroupID:         groupJID,
+		CampaignID:      campaignID,
+		SessionID:       sessionID,
+		Status:          StatusWouldSend,
+		MessagePrev:     dryRunPreview(content, groupName, fields),
+		Attempt:         1,
+		ScheduledFor:    time.Now(),
+		TemplateID:      content.TemplateID,
+		TemplateVersion: content.TemplateVersion,
+	})
+}
+
+// dryRunPreview ringkasan konten: teks terpersonalisasi (atau caption pertama) dan jumlah bagian
+// per jenis, mis. "text-only:Halo Grup A [image:2 contact:1]".
+func dryRunPreview(c MessageContent, groupName string, fields map[string]string) string {
+	text := c.TextOnly
+	for _, alt := range []string{c.ImageCaption, c.VideoCaption, c.DocCaption} {
+		if strings.TrimSpace(text) != "" {
+			break
+		}
+		text = alt
+	}
+	preview := TextPreview(personalize(text, groupName, fields))
+	var parts []string
+	for _, p := range []struct {
+		kind string
+		n    int
+	}{
+		{"image", len(c.ImageURLs)}, {"product", len(c.Products)}, {"video", len(c.VideoURLs)},
+		{"gif", len(c.GifURLs)}, {"audio", len(c.AudioURLs)}, {"voice", len(c.VoiceURLs)},
+		{"sticker", len(c.StickerURLs)}, {"doc", len(c.DocURLs)},
+	} {
+		if p.n > 0 {
+			parts = append(parts, fmt.Sprintf("%s:%d", p.kind, p.n))
+		}
+	}
+	if c.ContactPhone != "" {
+		parts = append(parts, "contact:1")
+	}
+	if len(parts) > 0 {
+		preview += " [" + strings.Join(parts, " ") + "]"
+	}
+	return preview
+}
@@ -0,0 +1,51 @@
+package sender
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"go.mau.fi/whatsmeow"
+	"go.mau.fi/whatsmeow/binary/proto"
+	"go.mau.fi/whatsmeow/types"
+)
+
+// EditWindow batas waktu WhatsApp untuk mengedit pesan setelah terkirim.
+const EditWindow = whatsmeow.EditWindow
+
+// ErrNotEditable dikembalikan jika log bukan pesan teks terkirim yang punya ID pesan.
+var ErrNotEditable = errors.New("message is not an editable sent text")
+
+// TextPreview preview log untuk pesan teks ("text-only:" + potongan teks).
+func TextPreview(text string) string {
+	return "text-only:" + short(text)
+}
+
+// EditText mengganti isi pesan teks yang sudah terkirim ke grup. Placeholder {group_name} dan
+// field grup dipersonalisasi seperti saat kirim. Mengembalikan teks akhir yang dikirim.
+func (s *Sender) EditText(ctx context.Context, accountID, groupJID, messageID, text string) (string, error) {
+	if messageID == "" {
+		return "", ErrNotEditable
+	}
+	if err := s.checkObserver(accountID); err != nil {
+		return "", err
+	}
+	cli, err := s.transport(accountID)
+	if err != nil {
+		return "", err
+	}
+	if !cli.Paired() || !cli.IsConnected() {
+		return "", fmt.Errorf("account %s not connected", accountID)
+	}
+	jid, err := types.ParseJID(groupJID)
+	if err != nil {
+		return "", fmt.Errorf("parse JID: %w", err)
+	}
+	final := strings.TrimSpace(personalize(text, s.lookupGroupName(groupJID), s.lookupGroupFields(groupJID)))
+	edit := cli.BuildEdit(jid, types.MessageID(messageID), &proto.Message{Conversation: &final})
+	if _, err := cli.SendMessage(ctx, jid, edit); err != nil {
+		return "", fmt.Errorf("edit message: %w", err)
+	}
+	return final, nil
+}
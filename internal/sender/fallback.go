@@ -0,0 +1,60 @@
+package sender
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"go.mau.fi/whatsmeow/types"
+)
+
+// Kebijakan saat satu bagian media template gagal dikirim (templates.media_fail_policy).
+const (
+	MediaFailAbort    = "abort"    // hentikan sesi (default, perilaku lama)
+	MediaFailSkip     = "skip"     // lewati bagian yang gagal dan lanjut
+	MediaFailFallback = "fallback" // kirim FallbackImageURL sebagai pengganti lalu lanjut
+)
+
+// ValidMediaFailPolicy true untuk kebijakan yang dikenal (kosong = abort).
+func ValidMediaFailPolicy(p string) bool {
+	switch p {
+	case "", MediaFailAbort, MediaFailSkip, MediaFailFallback:
+		return true
+	}
+	return false
+}
+
+// mediaFailed dipanggil setelah satu bagian media gagal (dan sudah dicatat). Mengembalikan nil
+// jika pengiriman boleh lanjut ke bagian berikutnya, atau error yang menghentikan sesi.
+func (s *Sender) mediaFailed(ctx context.Context, c Transport, jid types.JID, content MessageContent, caption string, attempt int, cause error) error {
+	meta := sendMetaFrom(ctx)
+	if meta == nil {
+		meta = &sendMeta{}
+	}
+	// Akun di-throttle: sisa bagian tidak boleh dikirim apa pun kebijakannya.
+	if errors.Is(cause, ErrThrottled) {
+		return cause
+	}
+	switch content.MediaFailPolicy {
+	case MediaFailSkip:
+		log.Printf("[sender] media skipped account=%s group=%s session=%s err=%v", meta.accountID, jid, meta.sessionID, cause)
+		return nil
+	case MediaFailFallback:
+		if content.FallbackImageURL == "" {
+			return cause
+		}
+		u := content.FallbackImageURL
+		err := s.withRetry(ctx, func() error {
+			return s.sendImageByURL(ctx, c, jid, u, caption)
+		})
+		if err != nil {
+			_ = s.logResult(ctx, meta.accountID, jid.String(), campaignFromContext(ctx), meta.sessionID, "failed", "fallback-image:"+u, err.Error(), attempt, time.Now())
+			return fmt.Errorf("fallback image: %w (original: %v)", err, cause)
+		}
+		_ = s.logResult(ctx, meta.accountID, jid.String(), campaignFromContext(ctx), meta.sessionID, "sent", "fallback-image:"+u, "", attempt, time.Now())
+		return s.sleepRange(ctx, 1200*time.Millisecond, 2500*time.Millisecond)
+	}
+	return cause
+}
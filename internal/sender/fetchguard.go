@@ -0,0 +1,167 @@
+package sender
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+	"syscall"
+	"time"
+
+	"promote/internal/model"
+)
+
+// ErrFetchBlocked dikembalikan jika unduhan media ditolak FetchPolicy (host, alamat privat,
+// ukuran, redirect). Tidak di-retry.
+var ErrFetchBlocked = errors.New("fetch blocked by policy")
+
+// fetchPolicyTTL lama policy dari settings di-cache sebelum dibaca ulang.
+const fetchPolicyTTL = time.Minute
+
+// fetchGuard menyimpan FetchPolicy aktif dan slot unduhan paralel per host.
+type fetchGuard struct {
+	mu     sync.Mutex
+	policy model.FetchPolicy
+	loaded time.Time
+	slots  map[string]chan struct{}
+}
+
+// fetchPolicy mengembalikan policy aktif; dibaca ulang dari settings setiap fetchPolicyTTL.
+func (s *Sender) fetchPolicy() model.FetchPolicy {
+	g := &s.guard
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if !g.loaded.IsZero() && time.Since(g.loaded) < fetchPolicyTTL {
+		return g.policy
+	}
+	p := model.DefaultFetchPolicy()
+	if s.Store != nil {
+		stored, err := s.Store.FetchPolicy()
+		if err != nil {
+			log.Printf("[sender] load fetch policy err=%v", err)
+			if !g.loaded.IsZero() {
+				stored = g.policy
+			}
+		}
+		if stored.Validate() == nil {
+			p = stored
+		}
+	}
+	g.setLocked(p)
+	return p
+}
+
+// SetFetchPolicy langsung memakai policy baru (dipanggil setelah settings diubah lewat API).
+func (s *Sender) SetFetchPolicy(p model.FetchPolicy) {
+	s.guard.mu.Lock()
+	s.guard.setLocked(p)
+	s.guard.mu.Unlock()
+}
+
+func (g *fetchGuard) setLocked(p model.FetchPolicy) {
+	if g.policy.PerHostConcurrency != p.PerHostConcurrency {
+		// Kapasitas berubah: slot lama dibiarkan habis sendiri, unduhan baru memakai slot baru.
+		g.slots = nil
+	}
+	g.policy = p
+	g.loaded = time.Now()
+}
+
+// acquireHost menunggu slot unduhan untuk host; release wajib dipanggil setelah selesai.
+func (s *Sender) acquireHost(ctx context.Context, host string) (func(), error) {
+	p := s.fetchPolicy()
+	g := &s.guard
+	g.mu.Lock()
+	if g.slots == nil {
+		g.slots = map[string]chan struct{}{}
+	}
+	slot, ok := g.slots[host]
+	if !ok {
+		slot = make(chan struct{}, p.PerHostConcurrency)
+		g.slots[host] = slot
+	}
+	g.mu.Unlock()
+	select {
+	case slot <- struct{}{}:
+		return func() { <-slot }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// checkFetchURL memeriksa skema dan host URL terhadap policy (sebelum koneksi dan tiap redirect).
+func checkFetchURL(p model.FetchPolicy, u *url.URL) error {
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("%w: scheme %q not allowed", ErrFetchBlocked, u.Scheme)
+	}
+	host := u.Hostname()
+	if host == "" {
+		return fmt.Errorf("%w: missing host", ErrFetchBlocked)
+	}
+	if !p.HostAllowed(host) {
+		return fmt.Errorf("%w: host %s not allowed", ErrFetchBlocked, host)
+	}
+	if ip := net.ParseIP(host); ip != nil && !p.AllowPrivate && privateIP(ip) {
+		return fmt.Errorf("%w: private address %s", ErrFetchBlocked, ip)
+	}
+	return nil
+}
+
+// cgnat 100.64.0.0/10 (shared address space) tidak tercakup net.IP.IsPrivate.
+var cgnat = &net.IPNet{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)}
+
+func privateIP(ip net.IP) bool {
+	return ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() || ip.IsUnspecified() || cgnat.Contains(ip)
+}
+
+// newFetchClient membuat HTTP client yang menerapkan FetchPolicy: URL setiap request dicek
+// skema dan host-nya, alamat IP tujuan dicek saat dial (setelah DNS, sehingga nama host yang
+// mengarah ke jaringan internal tetap ditolak) dan setiap redirect dicek jumlahnya. Client ini
+// juga dipakai di luar sender (feed watcher, draft og) untuk URL dari pengguna.
+func (s *Sender) newFetchClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip != nil && privateIP(ip) && !s.fetchPolicy().AllowPrivate {
+				return fmt.Errorf("%w: private address %s", ErrFetchBlocked, ip)
+			}
+			return nil
+		},
+	}
+	tr := http.DefaultTransport.(*http.Transport).Clone()
+	tr.DialContext = dialer.DialContext
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: policyTransport{s: s, next: tr},
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			p := s.fetchPolicy()
+			if len(via) > p.MaxRedirects {
+				return fmt.Errorf("%w: more than %d redirects", ErrFetchBlocked, p.MaxRedirects)
+			}
+			return checkFetchURL(p, req.URL)
+		},
+	}
+}
+
+// policyTransport menolak request yang URL-nya melanggar FetchPolicy sebelum koneksi dibuat.
+type policyTransport struct {
+	s    *Sender
+	next http.RoundTripper
+}
+
+func (t policyTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := checkFetchURL(t.s.fetchPolicy(), req.URL); err != nil {
+		return nil, err
+	}
+	return t.next.RoundTrip(req)
+}
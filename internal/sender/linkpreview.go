@@ -0,0 +1,150 @@
+package sender
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"go.mau.fi/whatsmeow/binary/proto"
+	"go.mau.fi/whatsmeow/types"
+
+	"promote/internal/ogp"
+)
+
+var urlRe = regexp.MustCompile(`https?://[^\s<>"']+`)
+
+// firstURL mengembalikan URL pertama dalam teks (tanpa tanda baca penutup).
+func firstURL(text string) string {
+	u := urlRe.FindString(text)
+	return strings.TrimRight(u, ".,;:!?)]}")
+}
+
+// Preview sukses di-cache lebih lama; kegagalan di-cache singkat agar satu halaman yang down
+// tidak di-scrape ulang untuk setiap grup dalam satu putaran kirim.
+const (
+	previewCacheTTL    = 6 * time.Hour
+	previewNegativeTTL = 10 * time.Minute
+	previewCacheMax    = 500
+)
+
+// linkPreview hasil scraping yang siap dipasang ke ExtendedTextMessage.
+type linkPreview struct {
+	title, description string
+	thumb              []byte
+	width, height      uint32
+}
+
+type previewEntry struct {
+	p  *linkPreview // nil = scraping gagal / tanpa metadata
+	at time.Time
+}
+
+// previewCache cache link preview per URL; zero value siap dipakai.
+type previewCache struct {
+	mu      sync.Mutex
+	entries map[string]previewEntry
+}
+
+func (c *previewCache) get(link string) (*linkPreview, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[link]
+	if !ok {
+		return nil, false
+	}
+	ttl := previewCacheTTL
+	if e.p == nil {
+		ttl = previewNegativeTTL
+	}
+	if time.Since(e.at) > ttl {
+		delete(c.entries, link)
+		return nil, false
+	}
+	return e.p, true
+}
+
+func (c *previewCache) put(link string, p *linkPreview) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.entries == nil {
+		c.entries = map[string]previewEntry{}
+	}
+	if len(c.entries) >= previewCacheMax {
+		// buang entri tertua; cache kecil jadi scan linear cukup
+		oldest := ""
+		for k, e := range c.entries {
+			if oldest == "" || e.at.Before(c.entries[oldest].at) {
+				oldest = k
+			}
+		}
+		delete(c.entries, oldest)
+	}
+	c.entries[link] = previewEntry{p: p, at: time.Now()}
+}
+
+// scrapeLinkPreview mengambil metadata OG dan thumbnail untuk link (hasil di-cache).
+func (s *Sender) scrapeLinkPreview(ctx context.Context, link string) *linkPreview {
+	if p, ok := s.previews.get(link); ok {
+		return p
+	}
+	pctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	og, err := ogp.Fetch(pctx, s.Client, link)
+	if err != nil || (og.Title == "" && og.Description == "") {
+		if ctx.Err() == nil {
+			s.previews.put(link, nil)
+		}
+		return nil
+	}
+	p := &linkPreview{title: og.Title, description: og.Description}
+	if og.Image != "" {
+		if data, _, err := s.fetch(pctx, og.Image); err == nil {
+			if thumb, w, h, err := ogp.Thumbnail(data, 300); err == nil {
+				p.thumb = thumb
+				p.width, p.height = uint32(w), uint32(h)
+			}
+		}
+	}
+	s.previews.put(link, p)
+	return p
+}
+
+// buildLinkPreview menyusun ExtendedTextMessage dengan title, description, dan thumbnail dari
+// URL pertama di teks. Mengembalikan nil jika teks tanpa URL atau scraping gagal.
+func (s *Sender) buildLinkPreview(ctx context.Context, text string) *proto.ExtendedTextMessage {
+	link := firstURL(text)
+	if link == "" {
+		return nil
+	}
+	p := s.scrapeLinkPreview(ctx, link)
+	if p == nil {
+		return nil
+	}
+	ext := &proto.ExtendedTextMessage{
+		Text:        strptr(text),
+		MatchedText: strptr(link),
+		Title:       optstr(p.title),
+		Description: optstr(p.description),
+		PreviewType: proto.ExtendedTextMessage_NONE.Enum(),
+	}
+	if len(p.thumb) > 0 {
+		tw, th := p.width, p.height
+		ext.JPEGThumbnail = p.thumb
+		ext.ThumbnailWidth = &tw
+		ext.ThumbnailHeight = &th
+	}
+	return ext
+}
+
+// sendTextWithPreview mengirim teks sebagai ExtendedTextMessage ber-link preview;
+// fallback ke Conversation biasa jika preview tidak tersedia.
+func (s *Sender) sendTextWithPreview(ctx context.Context, c Transport, jid types.JID, text string) error {
+	ext := s.buildLinkPreview(ctx, text)
+	if ext == nil {
+		return s.sendText(ctx, c, jid, text)
+	}
+	err := s.sendMessage(ctx, c, jid, &proto.Message{ExtendedTextMessage: ext})
+	return err
+}
@@ -0,0 +1,74 @@
+package sender
+
+import (
+	"context"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+
+	"go.mau.fi/whatsmeow/binary/proto"
+	"go.mau.fi/whatsmeow/types"
+
+	"promote/internal/storage"
+)
+
+// defaultMentionAllMax batas anggota grup untuk mention-all; grup lebih besar dikirimi teks
+// tanpa mention. Bisa diubah lewat MENTION_ALL_MAX.
+const defaultMentionAllMax = 256
+
+func mentionAllMax() int {
+	if n, err := strconv.Atoi(strings.TrimSpace(os.Getenv("MENTION_ALL_MAX"))); err == nil && n > 0 {
+		return n
+	}
+	return defaultMentionAllMax
+}
+
+// mentionAllJIDs daftar JID anggota grup (dari cache participant, refresh jika belum ada),
+// tanpa akun pengirim dan nomor yang disupresi. Mengembalikan nil jika daftar tidak tersedia atau grup melebihi batas.
+func (s *Sender) mentionAllJIDs(ctx context.Context, c Transport, accountID, groupJID string) []string {
+	participants, found, err := s.Store.GetCachedGroupParticipants(groupJID, 1440)
+	if (err != nil || !found) && s.Manager != nil {
+		participants, err = s.Manager.RefreshGroupParticipants(ctx, accountID, groupJID)
+	}
+	if err != nil {
+		log.Printf("[sender] mention-all skipped group=%s err=%v", groupJID, err)
+		return nil
+	}
+	if max := mentionAllMax(); len(participants) > max {
+		log.Printf("[sender] mention-all skipped group=%s participants=%d max=%d", groupJID, len(participants), max)
+		return nil
+	}
+	self := ""
+	if c.Paired() {
+		self = c.OwnJID().String()
+	}
+	// Nomor di daftar supresi tidak ikut di-mention (mention memicu notifikasi)
+	suppressed, err := s.Store.DMSuppressedSet()
+	if err != nil {
+		log.Printf("[sender] mention-all skipped group=%s suppressions err=%v", groupJID, err)
+		return nil
+	}
+	jids := make([]string, 0, len(participants))
+	for _, p := range participants {
+		if p.JID == "" || p.JID == self || suppressed[storage.NormalizeNumber(p.Number)] {
+			continue
+		}
+		jids = append(jids, p.JID)
+	}
+	return jids
+}
+
+// sendTextMentions mengirim teks sebagai ExtendedTextMessage dengan ContextInfo.MentionedJID
+// (mention tersembunyi, gaya @everyone). Preview link dipertahankan jika diminta.
+func (s *Sender) sendTextMentions(ctx context.Context, c Transport, jid types.JID, text string, preview bool, mentions []string) error {
+	var ext *proto.ExtendedTextMessage
+	if preview {
+		ext = s.buildLinkPreview(ctx, text)
+	}
+	if ext == nil {
+		ext = &proto.ExtendedTextMessage{Text: strptr(text)}
+	}
+	ext.ContextInfo = &proto.ContextInfo{MentionedJID: mentions}
+	return s.sendMessage(ctx, c, jid, &proto.Message{ExtendedTextMessage: ext})
+}
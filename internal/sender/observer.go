@@ -0,0 +1,22 @@
+package sender
+
+import (
+	"fmt"
+
+	"promote/internal/wa"
+)
+
+// ErrObserverAccount dikembalikan jika akun pengirim dalam mode observer.
+var ErrObserverAccount = wa.ErrObserverAccount
+
+// checkObserver menolak semua kiriman (grup, DM, edit, revoke) dari akun observer.
+func (s *Sender) checkObserver(accountID string) error {
+	observer, err := s.Store.IsObserverAccount(accountID)
+	if err != nil {
+		return fmt.Errorf("check observer: %w", err)
+	}
+	if observer {
+		return fmt.Errorf("%w: %s", ErrObserverAccount, accountID)
+	}
+	return nil
+}
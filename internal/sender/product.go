@@ -0,0 +1,56 @@
+package sender
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"go.mau.fi/whatsmeow"
+	"go.mau.fi/whatsmeow/binary/proto"
+	"go.mau.fi/whatsmeow/types"
+
+	"promote/internal/model"
+)
+
+// sendProduct mengirim item katalog sebagai product message. Hanya untuk akun WhatsApp
+// Business: BusinessOwnerJID diisi nomor pengirim sehingga penerima membuka katalognya.
+func (s *Sender) sendProduct(ctx context.Context, c Transport, jid types.JID, p model.ProductItem) error {
+	if !c.Paired() {
+		return fmt.Errorf("product: account not paired")
+	}
+	data, mime, err := s.fetch(ctx, p.ImageURL)
+	if err != nil {
+		return err
+	}
+	up, err := c.Upload(ctx, data, whatsmeow.MediaImage)
+	if err != nil {
+		return fmt.Errorf("upload product image: %w", err)
+	}
+	length := uint64(len(data))
+	imageCount := uint32(1)
+	snap := &proto.ProductMessage_ProductSnapshot{
+		ProductImage: &proto.ImageMessage{
+			Mimetype:      optstr(mime),
+			URL:           optstr(up.URL),
+			DirectPath:    optstr(up.DirectPath),
+			MediaKey:      up.MediaKey,
+			FileEncSHA256: up.FileEncSHA256,
+			FileSHA256:    up.FileSHA256,
+			FileLength:    &length,
+		},
+		ProductID:         optstr(p.ProductID),
+		Title:             optstr(p.Title),
+		Description:       optstr(p.Description),
+		CurrencyCode:      optstr(p.CurrencyCode()),
+		RetailerID:        optstr(p.RetailerID),
+		URL:               optstr(p.URL),
+		ProductImageCount: &imageCount,
+	}
+	if p.Price > 0 {
+		amount := int64(math.Round(p.Price * 1000))
+		snap.PriceAmount1000 = &amount
+	}
+	owner := c.OwnJID().String()
+	msg := &proto.Message{ProductMessage: &proto.ProductMessage{Product: snap, BusinessOwnerJID: &owner}}
+	return s.sendMessage(ctx, c, jid, msg)
+}
@@ -0,0 +1,41 @@
+package sender
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.mau.fi/whatsmeow/types"
+)
+
+// RevokeWindow batas waktu WhatsApp untuk "hapus untuk semua orang" (sekitar 2 hari 12 jam).
+const RevokeWindow = 60 * time.Hour
+
+// ErrNotRevocable dikembalikan jika log bukan pesan terkirim yang punya ID pesan atau sudah dihapus.
+var ErrNotRevocable = errors.New("message is not a revocable sent message")
+
+// Revoke menghapus pesan terkirim di grup untuk semua orang.
+func (s *Sender) Revoke(ctx context.Context, accountID, groupJID, messageID string) error {
+	if messageID == "" {
+		return ErrNotRevocable
+	}
+	if err := s.checkObserver(accountID); err != nil {
+		return err
+	}
+	cli, err := s.transport(accountID)
+	if err != nil {
+		return err
+	}
+	if !cli.Paired() || !cli.IsConnected() {
+		return fmt.Errorf("account %s not connected", accountID)
+	}
+	jid, err := types.ParseJID(groupJID)
+	if err != nil {
+		return fmt.Errorf("parse JID: %w", err)
+	}
+	if _, err := cli.SendMessage(ctx, jid, cli.BuildRevoke(jid, types.EmptyJID, types.MessageID(messageID))); err != nil {
+		return fmt.Errorf("revoke message: %w", err)
+	}
+	return nil
+}
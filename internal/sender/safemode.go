@@ -0,0 +1,67 @@
+package sender
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"promote/internal/storage"
+)
+
+// ErrNoTestGroup: safe mode aktif tetapi akun belum punya grup uji, sehingga kiriman dibatalkan
+// (tidak pernah jatuh ke grup produksi).
+var ErrNoTestGroup = errors.New("safe mode: account has no test group")
+
+// ErrSafeModeBroadcast: safe mode aktif sehingga posting Status dan Channel ditolak; keduanya
+// terlihat publik dan tidak bisa dialihkan ke grup uji.
+var ErrSafeModeBroadcast = errors.New("safe mode: status and channel posts are disabled")
+
+// checkSafeModeBroadcast menolak posting Status/Channel selama safe mode aktif.
+func (s *Sender) checkSafeModeBroadcast() error {
+	on, err := s.Store.SettingBool(storage.SettingSafeMode)
+	if err != nil {
+		return err
+	}
+	if on {
+		return ErrSafeModeBroadcast
+	}
+	return nil
+}
+
+// safeModeTarget mengembalikan tujuan kirim sebenarnya. Saat safe mode aktif semua kiriman
+// dialihkan ke grup uji akun beserta catatan tujuan asli; jika tidak aktif, groupJID dikembalikan apa adanya.
+func (s *Sender) safeModeTarget(accountID, groupJID, groupName string) (target, note string, err error) {
+	on, err := s.Store.SettingBool(storage.SettingSafeMode)
+	if err != nil {
+		return "", "", err
+	}
+	if !on {
+		return groupJID, "", nil
+	}
+	testJID, err := s.Store.TestGroupForAccount(accountID)
+	if err != nil {
+		return "", "", err
+	}
+	if testJID == "" {
+		return "", "", ErrNoTestGroup
+	}
+	if testJID == groupJID {
+		return groupJID, "", nil
+	}
+	name := groupName
+	if name == "" {
+		name = "-"
+	}
+	return testJID, fmt.Sprintf("🧪 [SAFE MODE] tujuan asli: %s (%s)", name, groupJID), nil
+}
+
+// annotateSafeMode menaruh catatan tujuan asli di awal teks; jika tidak ada teks, catatan
+// dikirim sebagai pesan teks tersendiri sebelum media.
+func annotateSafeMode(content MessageContent, note string) MessageContent {
+	if strings.TrimSpace(content.TextOnly) != "" {
+		content.TextOnly = note + "\n\n" + content.TextOnly
+	} else {
+		content.TextOnly = note
+	}
+	return content
+}
@@ -2,14 +2,17 @@ package sender
 
 import (
 	"context"
-	"database/sql"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
-	"math/rand"
 	"net/http"
 	"os"
+	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
@@ -18,36 +21,92 @@ import (
 	"go.mau.fi/whatsmeow/binary/proto"
 	"go.mau.fi/whatsmeow/types"
 
+	"promote/internal/dynvar"
+	"promote/internal/logship"
+	"promote/internal/mediacache"
+	"promote/internal/model"
+	"promote/internal/paths"
+	"promote/internal/rng"
+	"promote/internal/shortlink"
 	"promote/internal/storage"
+	"promote/internal/telegram"
 	"promote/internal/wa"
+	"promote/internal/webhook"
 )
 
 type MessageContent struct {
-	TextOnly      string   `json:"text_only"`
-	ImageURLs     []string `json:"image_urls"`
-	ImageCaption  string   `json:"image_caption"`
-	VideoURLs     []string `json:"video_urls"`
-	VideoCaption  string   `json:"video_caption"`
-	AudioURLs     []string `json:"audio_urls"`
-	StickerURLs   []string `json:"sticker_urls"`
-	DocURLs       []string `json:"doc_urls"`
-	DocCaption    string   `json:"doc_caption"`
+	TextOnly         string              `json:"text_only"`
+	ImageURLs        []string            `json:"image_urls"`
+	ImageCaption     string              `json:"image_caption"`
+	VideoURLs        []string            `json:"video_urls"`
+	VideoCaption     string              `json:"video_caption"`
+	// Products dikirim sebagai product message (akun Business) atau gambar + caption.
+	Products         []model.ProductItem `json:"products"`
+	// GifURLs video pendek (mp4) yang diputar berulang seperti GIF; caption = VideoCaption.
+	GifURLs          []string            `json:"gif_urls"`
+	AudioURLs        []string            `json:"audio_urls"`
+	// VoiceURLs dikirim sebagai voice note (PTT); dikonversi ke OGG/Opus bila perlu.
+	VoiceURLs        []string            `json:"voice_urls"`
+	StickerURLs      []string            `json:"sticker_urls"`
+	DocURLs          []string            `json:"doc_urls"`
+	DocCaption       string              `json:"doc_caption"`
+	// LinkPreview: kirim teks sebagai ExtendedTextMessage dengan preview link pertama.
+	LinkPreview      bool                `json:"link_preview"`
+	// Kartu kontak (vCard) dikirim terakhir; ContactPhone kosong = tidak dikirim.
+	ContactName      string              `json:"contact_name"`
+	ContactPhone     string              `json:"contact_phone"`
+	// MediaFailPolicy: abort (default), skip, atau fallback (lihat MediaFail*).
+	MediaFailPolicy  string              `json:"media_fail_policy"`
+	FallbackImageURL string              `json:"fallback_image_url"`
+	// MentionAll: teks-only me-mention semua anggota grup (dibatasi MENTION_ALL_MAX).
+	MentionAll       bool                `json:"mention_all"`
+	// TemplateID/TemplateVersion sumber konten (diisi TemplateContent) dicatat di log kirim.
+	TemplateID       string              `json:"-"`
+	TemplateVersion  int                 `json:"-"`
 }
 
 type Sender struct {
-	Store   *storage.Store
+	Store   storage.Storage
 	Manager *wa.Manager
 	Client  *http.Client
+	// Ship (opsional) meneruskan hasil kirim ke sink log eksternal.
+	Ship *logship.Shipper
+	// Mirror (opsional) mencerminkan promo yang sukses ke channel Telegram.
+	Mirror *telegram.Mirror
+	// Links (opsional) mengganti URL di teks/caption dengan short link bermerek.
+	Links *shortlink.Service
+	// Hooks (opsional) mengirim event send.sent/send.failed ke webhook.
+	Hooks *webhook.Dispatcher
+	// Vars (opsional) me-resolve variabel dinamis {name:key} lewat HTTP lookup.
+	Vars *dynvar.Resolver
+	// Media (opsional) cache lokal media remote; diisi prefetch di luar jendela kirim.
+	Media *mediacache.Cache
+	// UploadDir lokasi file untuk URL lokal "/uploads/..." (default "uploads").
+	UploadDir string
+	// Transports (opsional) sumber Transport per akun; nil = client whatsmeow dari Manager.
+	Transports func(accountID string) (Transport, error)
+	// Sleep (opsional) pengganti jeda pacing/backoff; nil = menunggu sungguhan.
+	Sleep func(ctx context.Context, d time.Duration) error
+	// Rand sumber acak pemilihan template, jitter pacing dan backoff (seed lewat SENDER_SEED).
+	Rand *rng.Rand
+
+	previews previewCache
+	voices   voiceCache
+	guard    fetchGuard
 }
 
-func New(store *storage.Store, manager *wa.Manager) *Sender {
-	return &Sender{
+func New(store storage.Storage, manager *wa.Manager) *Sender {
+	s := &Sender{
 		Store:   store,
 		Manager: manager,
-		Client: &http.Client{
-			Timeout: 60 * time.Second,
-		},
+		Rand:    rng.FromEnv("SENDER_SEED"),
+	}
+	s.Client = s.newFetchClient(60 * time.Second)
+	if manager != nil {
+		s.Ship = manager.Ship
+		s.Hooks = manager.Hooks
 	}
+	return s
 }
 
 // Retry/backoff & risk configuration
@@ -70,6 +129,9 @@ func isRetryable(err error) bool {
 	if err == nil {
 		return false
 	}
+	if errors.Is(err, ErrFetchBlocked) {
+		return false
+	}
 	if e, ok := err.(*httpStatusError); ok {
 		if e.code == 429 || (e.code >= 500 && e.code <= 599) {
 			return true
@@ -89,7 +151,7 @@ func isRetryable(err error) bool {
 	}
 }
 
-func withRetry(ctx context.Context, fn func() error) error {
+func (s *Sender) withRetry(ctx context.Context, fn func() error) error {
 	attempt := 0
 	backoff := baseBackoff
 	for {
@@ -97,20 +159,22 @@ func withRetry(ctx context.Context, fn func() error) error {
 		if err == nil {
 			return nil
 		}
+		// Rate-limit/spam: mengulang justru memperburuk; hentikan akun dan jangan retry.
+		if isThrottled(err) {
+			return s.throttle(ctx, err)
+		}
 		attempt++
 		if attempt >= maxAttempts || !isRetryable(err) {
 			return err
 		}
 		// exponential backoff with jitter
-		jit := time.Duration(rand.Int63n(int64(float64(backoff) * jitterPct)))
+		jit := time.Duration(s.Rand.Int63n(int64(float64(backoff) * jitterPct)))
 		wait := backoff + jit
 		if wait > maxBackoff {
 			wait = maxBackoff
 		}
-		select {
-		case <-time.After(wait):
-		case <-ctx.Done():
-			return ctx.Err()
+		if err := s.sleep(ctx, wait); err != nil {
+			return err
 		}
 		backoff *= 2
 		if backoff > maxBackoff {
@@ -119,28 +183,34 @@ func withRetry(ctx context.Context, fn func() error) error {
 	}
 }
 
-func sleepRange(ctx context.Context, min, max time.Duration) error {
+func (s *Sender) sleepRange(ctx context.Context, min, max time.Duration) error {
 	if max <= min {
-		select {
-		case <-time.After(min):
-			return nil
-		case <-ctx.Done():
-			return ctx.Err()
-		}
+		return s.sleep(ctx, min)
 	}
 	delta := max - min
-	wait := min + time.Duration(rand.Int63n(int64(delta)))
-	select {
-	case <-time.After(wait):
-		return nil
-	case <-ctx.Done():
-		return ctx.Err()
+	wait := min + time.Duration(s.Rand.Int63n(int64(delta)))
+	return s.sleep(ctx, wait)
+}
+
+// bumpRiskAndMaybePause menaikkan risk grup setelah kiriman gagal; throttle adalah masalah akun,
+// bukan grup, jadi tidak dihitung.
+func (s *Sender) bumpRiskAndMaybePause(groupID string, cause error) {
+	if errors.Is(cause, ErrThrottled) {
+		return
 	}
+	_ = s.Store.BumpGroupRisk(groupID, riskThreshold)
 }
 
-func (s *Sender) bumpRiskAndMaybePause(groupID string) {
-	_, _ = s.Store.DB.Exec(`UPDATE groups SET risk_score = risk_score + 1 WHERE id=?`, groupID)
-	_, _ = s.Store.DB.Exec(`UPDATE groups SET enabled=0 WHERE id=? AND risk_score >= ?`, groupID, riskThreshold)
+type campaignKey struct{}
+
+// WithCampaign menandai pengiriman sebagai bagian dari campaign (dicatat di logs.campaign_id).
+func WithCampaign(ctx context.Context, campaignID string) context.Context {
+	return context.WithValue(ctx, campaignKey{}, campaignID)
+}
+
+func campaignFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(campaignKey{}).(string)
+	return id
 }
 
 // SendToGroup sends content to a group JID string like "12345-67890@g.us" via a specific account.
@@ -151,19 +221,34 @@ func (s *Sender) SendToGroup(ctx context.Context, accountID, groupJID string, co
 
 // SendToGroupWithSession sends content with a specific session ID for grouping logs
 func (s *Sender) SendToGroupWithSession(ctx context.Context, accountID, groupJID string, content MessageContent, sessionID string) error {
-	cli, err := s.Manager.GetClient(accountID)
-	if err != nil {
+	if err := s.checkObserver(accountID); err != nil {
 		return err
 	}
-	if cli.Store == nil || cli.Store.ID == nil {
-		return fmt.Errorf("account %s not paired/connected", accountID)
+	if err := s.checkThrottled(accountID); err != nil {
+		return err
+	}
+	if err := s.checkSuppressed(groupJID); err != nil {
+		return err
 	}
-	// Pastikan koneksi aktif sebelum mengirim. Toleransi error "already connected".
-	if err := cli.Connect(); err != nil {
-		ls := strings.ToLower(err.Error())
-		if !(strings.Contains(ls, "already") || strings.Contains(ls, "connected")) {
-			return fmt.Errorf("connect: %w", err)
+	// Dry-run: konten dipilih dan dipersonalisasi seperti biasa, tetapi WhatsApp tidak disentuh.
+	dryRun := IsDryRun(ctx)
+	var cli Transport
+	if !dryRun {
+		c, err := s.transport(accountID)
+		if err != nil {
+			return err
 		}
+		if !c.Paired() {
+			return fmt.Errorf("account %s not paired/connected", accountID)
+		}
+		// Pastikan koneksi aktif sebelum mengirim. Toleransi error "already connected".
+		if err := c.Connect(); err != nil {
+			ls := strings.ToLower(err.Error())
+			if !(strings.Contains(ls, "already") || strings.Contains(ls, "connected")) {
+				return fmt.Errorf("connect: %w", err)
+			}
+		}
+		cli = c
 	}
 
 	// Parse JID
@@ -176,14 +261,42 @@ func (s *Sender) SendToGroupWithSession(ctx context.Context, accountID, groupJID
 	if sessionID == "" {
 		sessionID = uuid.NewString()
 	}
+	ctx = withSendMeta(ctx, accountID, sessionID)
+	if meta := sendMetaFrom(ctx); meta != nil {
+		meta.templateID, meta.templateVersion = content.TemplateID, content.TemplateVersion
+	}
+	campaignID := campaignFromContext(ctx)
 
 	// Load group name for personalization
 	groupName := s.lookupGroupName(groupJID)
+	fields := s.lookupGroupFields(groupJID)
+	content = s.resolveVars(ctx, content)
+	content = s.rewriteLinks(content)
+
+	// Safe mode: alihkan ke grup uji akun (personalisasi tetap memakai grup tujuan asli)
+	target, note, err := s.safeModeTarget(accountID, groupJID, groupName)
+	if err != nil {
+		return err
+	}
+	redirected := target != groupJID
+	if redirected {
+		log.Printf("[sender] SAFE_MODE account=%s intended=%s redirect=%s", accountID, groupJID, target)
+		content = annotateSafeMode(content, note)
+		groupJID = target
+		if jid, err = types.ParseJID(groupJID); err != nil {
+			return fmt.Errorf("parse JID: %w", err)
+		}
+	}
+	if dryRun {
+		log.Printf("[sender] WOULD_SEND account=%s group=%s session=%s template=%s", accountID, groupJID, sessionID, content.TemplateID)
+		return s.logWouldSend(ctx, accountID, groupJID, campaignID, sessionID, content, groupName, fields)
+	}
 	
 	// Calculate component count for logging
 	componentCount := 0
 	if strings.TrimSpace(content.TextOnly) != "" { componentCount++ }
-	componentCount += len(content.ImageURLs) + len(content.VideoURLs) + len(content.AudioURLs) + len(content.StickerURLs) + len(content.DocURLs)
+	componentCount += len(content.ImageURLs) + len(content.Products) + len(content.VideoURLs) + len(content.GifURLs) + len(content.AudioURLs) + len(content.VoiceURLs) + len(content.StickerURLs) + len(content.DocURLs)
+	if content.ContactPhone != "" { componentCount++ }
 	
 	start := time.Now()
 	log.Printf("[sender] START_CAMPAIGN account=%s group=%s session=%s components=%d timestamp=%s", 
@@ -191,124 +304,241 @@ func (s *Sender) SendToGroupWithSession(ctx context.Context, accountID, groupJID
 	
 	// 1) Send text-only message if provided
 	if strings.TrimSpace(content.TextOnly) != "" {
-		text := personalize(content.TextOnly, groupName)
-		err := withRetry(ctx, func() error {
+		text := personalize(content.TextOnly, groupName, fields)
+		var mentions []string
+		if content.MentionAll {
+			mentions = s.mentionAllJIDs(ctx, cli, accountID, groupJID)
+		}
+		err := s.withRetry(ctx, func() error {
+			if len(mentions) > 0 {
+				return s.sendTextMentions(ctx, cli, jid, text, content.LinkPreview, mentions)
+			}
+			if content.LinkPreview {
+				return s.sendTextWithPreview(ctx, cli, jid, text)
+			}
 			return s.sendText(ctx, cli, jid, text)
 		})
 		if err != nil {
-			_ = s.logResult(accountID, groupJID, "", sessionID, "failed", short(text), err.Error(), maxAttempts, time.Now())
-			s.bumpRiskAndMaybePause(groupJID)
+			_ = s.logResult(ctx, accountID, groupJID, campaignID, sessionID, "failed", short(text), err.Error(), maxAttempts, time.Now())
+			s.bumpRiskAndMaybePause(groupJID, err)
 			log.Printf("[sender] text-only failed account=%s group=%s session=%s err=%v", accountID, groupJID, sessionID, err)
 			return err
 		}
-		_ = s.logResult(accountID, groupJID, "", sessionID, "sent", "text-only:"+short(content.TextOnly), "", 1, time.Now())
+		_ = s.logResult(ctx, accountID, groupJID, campaignID, sessionID, "sent", TextPreview(content.TextOnly), "", 1, time.Now())
 		// small human-like pause between parts
-		if err := sleepRange(ctx, 1*time.Second, 2*time.Second); err != nil {
+		if err := s.sleepRange(ctx, 1*time.Second, 2*time.Second); err != nil {
 			return err
 		}
 	}
 
 	// 2) Send images with custom captions
 	for idx, u := range content.ImageURLs {
-		caption := personalize(content.ImageCaption, groupName)
-		err := withRetry(ctx, func() error {
+		caption := personalize(content.ImageCaption, groupName, fields)
+		err := s.withRetry(ctx, func() error {
 			return s.sendImageByURL(ctx, cli, jid, u, caption)
 		})
 		if err != nil {
-			_ = s.logResult(accountID, groupJID, "", sessionID, "failed", "image:"+u, err.Error(), idx+1, time.Now())
-			s.bumpRiskAndMaybePause(groupJID)
+			_ = s.logResult(ctx, accountID, groupJID, campaignID, sessionID, "failed", "image:"+u, err.Error(), idx+1, time.Now())
+			s.bumpRiskAndMaybePause(groupJID, err)
 			log.Printf("[sender] image failed account=%s group=%s session=%s url=%s err=%v", accountID, groupJID, sessionID, u, err)
-			return err
+			if err := s.mediaFailed(ctx, cli, jid, content, caption, idx+1, err); err != nil {
+				return err
+			}
+			continue
 		}
 		preview := "image:" + u
 		if caption != "" {
 			preview += " (caption:" + short(caption) + ")"
 		}
-		_ = s.logResult(accountID, groupJID, "", sessionID, "sent", preview, "", idx+1, time.Now())
+		_ = s.logResult(ctx, accountID, groupJID, campaignID, sessionID, "sent", preview, "", idx+1, time.Now())
 		// pacing
-		if err := sleepRange(ctx, 1200*time.Millisecond, 2500*time.Millisecond); err != nil {
+		if err := s.sleepRange(ctx, 1200*time.Millisecond, 2500*time.Millisecond); err != nil {
+			return err
+		}
+	}
+
+	// 2b) Send products (product message untuk akun Business, selain itu gambar + caption)
+	business := false
+	if len(content.Products) > 0 {
+		if business, err = s.Store.IsBusinessAccount(accountID); err != nil {
+			log.Printf("[sender] business lookup account=%s err=%v", accountID, err)
+		}
+	}
+	for idx, p := range content.Products {
+		err := s.withRetry(ctx, func() error {
+			if business {
+				return s.sendProduct(ctx, cli, jid, p)
+			}
+			return s.sendImageByURL(ctx, cli, jid, p.ImageURL, p.Caption())
+		})
+		if err != nil {
+			_ = s.logResult(ctx, accountID, groupJID, campaignID, sessionID, "failed", "product:"+p.ProductID, err.Error(), idx+1, time.Now())
+			s.bumpRiskAndMaybePause(groupJID, err)
+			log.Printf("[sender] product failed account=%s group=%s session=%s product=%s err=%v", accountID, groupJID, sessionID, p.ProductID, err)
+			if err := s.mediaFailed(ctx, cli, jid, content, p.Caption(), idx+1, err); err != nil {
+				return err
+			}
+			continue
+		}
+		_ = s.logResult(ctx, accountID, groupJID, campaignID, sessionID, "sent", "product:"+p.ProductID+" ("+short(p.Title)+")", "", idx+1, time.Now())
+		if err := s.sleepRange(ctx, 1200*time.Millisecond, 2500*time.Millisecond); err != nil {
 			return err
 		}
 	}
 
 	// 3) Send videos with custom captions
 	for idx, u := range content.VideoURLs {
-		caption := personalize(content.VideoCaption, groupName)
-		err := withRetry(ctx, func() error {
-			return s.sendVideoByURL(ctx, cli, jid, u, caption)
+		caption := personalize(content.VideoCaption, groupName, fields)
+		err := s.withRetry(ctx, func() error {
+			return s.sendVideoByURL(ctx, cli, jid, u, caption, false)
 		})
 		if err != nil {
-			_ = s.logResult(accountID, groupJID, "", sessionID, "failed", "video:"+u, err.Error(), idx+1, time.Now())
-			s.bumpRiskAndMaybePause(groupJID)
+			_ = s.logResult(ctx, accountID, groupJID, campaignID, sessionID, "failed", "video:"+u, err.Error(), idx+1, time.Now())
+			s.bumpRiskAndMaybePause(groupJID, err)
 			log.Printf("[sender] video failed account=%s group=%s session=%s url=%s err=%v", accountID, groupJID, sessionID, u, err)
-			return err
+			if err := s.mediaFailed(ctx, cli, jid, content, caption, idx+1, err); err != nil {
+				return err
+			}
+			continue
 		}
 		preview := "video:" + u
 		if caption != "" {
 			preview += " (caption:" + short(caption) + ")"
 		}
-		_ = s.logResult(accountID, groupJID, "", sessionID, "sent", preview, "", idx+1, time.Now())
-		if err := sleepRange(ctx, 1500*time.Millisecond, 3000*time.Millisecond); err != nil {
+		_ = s.logResult(ctx, accountID, groupJID, campaignID, sessionID, "sent", preview, "", idx+1, time.Now())
+		if err := s.sleepRange(ctx, 1500*time.Millisecond, 3000*time.Millisecond); err != nil {
+			return err
+		}
+	}
+
+	// 3b) Send GIFs (video dengan GifPlayback, autoplay berulang)
+	for idx, u := range content.GifURLs {
+		caption := personalize(content.VideoCaption, groupName, fields)
+		err := s.withRetry(ctx, func() error {
+			return s.sendVideoByURL(ctx, cli, jid, u, caption, true)
+		})
+		if err != nil {
+			_ = s.logResult(ctx, accountID, groupJID, campaignID, sessionID, "failed", "gif:"+u, err.Error(), idx+1, time.Now())
+			s.bumpRiskAndMaybePause(groupJID, err)
+			log.Printf("[sender] gif failed account=%s group=%s session=%s url=%s err=%v", accountID, groupJID, sessionID, u, err)
+			if err := s.mediaFailed(ctx, cli, jid, content, caption, idx+1, err); err != nil {
+				return err
+			}
+			continue
+		}
+		preview := "gif:" + u
+		if caption != "" {
+			preview += " (caption:" + short(caption) + ")"
+		}
+		_ = s.logResult(ctx, accountID, groupJID, campaignID, sessionID, "sent", preview, "", idx+1, time.Now())
+		if err := s.sleepRange(ctx, 1500*time.Millisecond, 3000*time.Millisecond); err != nil {
 			return err
 		}
 	}
 
 	// 4) Send audios (audio cannot have captions)
 	for idx, u := range content.AudioURLs {
-		err := withRetry(ctx, func() error {
+		err := s.withRetry(ctx, func() error {
 			return s.sendAudioByURL(ctx, cli, jid, u)
 		})
 		if err != nil {
-			_ = s.logResult(accountID, groupJID, "", sessionID, "failed", "audio:"+u, err.Error(), idx+1, time.Now())
-			s.bumpRiskAndMaybePause(groupJID)
+			_ = s.logResult(ctx, accountID, groupJID, campaignID, sessionID, "failed", "audio:"+u, err.Error(), idx+1, time.Now())
+			s.bumpRiskAndMaybePause(groupJID, err)
 			log.Printf("[sender] audio failed account=%s group=%s session=%s url=%s err=%v", accountID, groupJID, sessionID, u, err)
-			return err
+			if err := s.mediaFailed(ctx, cli, jid, content, "", idx+1, err); err != nil {
+				return err
+			}
+			continue
 		}
-		_ = s.logResult(accountID, groupJID, "", sessionID, "sent", "audio:"+u, "", idx+1, time.Now())
+		_ = s.logResult(ctx, accountID, groupJID, campaignID, sessionID, "sent", "audio:"+u, "", idx+1, time.Now())
 		// pacing
-		if err := sleepRange(ctx, 1200*time.Millisecond, 2500*time.Millisecond); err != nil {
+		if err := s.sleepRange(ctx, 1200*time.Millisecond, 2500*time.Millisecond); err != nil {
+			return err
+		}
+	}
+
+	// 4b) Send voice notes (PTT)
+	for idx, u := range content.VoiceURLs {
+		err := s.withRetry(ctx, func() error {
+			return s.sendVoiceNoteByURL(ctx, cli, jid, u)
+		})
+		if err != nil {
+			_ = s.logResult(ctx, accountID, groupJID, campaignID, sessionID, "failed", "voice:"+u, err.Error(), idx+1, time.Now())
+			s.bumpRiskAndMaybePause(groupJID, err)
+			log.Printf("[sender] voice note failed account=%s group=%s session=%s url=%s err=%v", accountID, groupJID, sessionID, u, err)
+			if err := s.mediaFailed(ctx, cli, jid, content, "", idx+1, err); err != nil {
+				return err
+			}
+			continue
+		}
+		_ = s.logResult(ctx, accountID, groupJID, campaignID, sessionID, "sent", "voice:"+u, "", idx+1, time.Now())
+		if err := s.sleepRange(ctx, 1200*time.Millisecond, 2500*time.Millisecond); err != nil {
 			return err
 		}
 	}
 
 	// 5) Send stickers (stickers cannot have captions)
 	for idx, u := range content.StickerURLs {
-		err := withRetry(ctx, func() error {
+		err := s.withRetry(ctx, func() error {
 			return s.sendStickerByURL(ctx, cli, jid, u)
 		})
 		if err != nil {
-			_ = s.logResult(accountID, groupJID, "", sessionID, "failed", "sticker:"+u, err.Error(), idx+1, time.Now())
-			s.bumpRiskAndMaybePause(groupJID)
+			_ = s.logResult(ctx, accountID, groupJID, campaignID, sessionID, "failed", "sticker:"+u, err.Error(), idx+1, time.Now())
+			s.bumpRiskAndMaybePause(groupJID, err)
 			log.Printf("[sender] sticker failed account=%s group=%s session=%s url=%s err=%v", accountID, groupJID, sessionID, u, err)
-			return err
+			if err := s.mediaFailed(ctx, cli, jid, content, "", idx+1, err); err != nil {
+				return err
+			}
+			continue
 		}
-		_ = s.logResult(accountID, groupJID, "", sessionID, "sent", "sticker:"+u, "", idx+1, time.Now())
+		_ = s.logResult(ctx, accountID, groupJID, campaignID, sessionID, "sent", "sticker:"+u, "", idx+1, time.Now())
 		// pacing
-		if err := sleepRange(ctx, 1200*time.Millisecond, 2500*time.Millisecond); err != nil {
+		if err := s.sleepRange(ctx, 1200*time.Millisecond, 2500*time.Millisecond); err != nil {
 			return err
 		}
 	}
 
 	// 6) Send documents with custom captions
 	for idx, u := range content.DocURLs {
-		caption := personalize(content.DocCaption, groupName)
-		err := withRetry(ctx, func() error {
+		caption := personalize(content.DocCaption, groupName, fields)
+		err := s.withRetry(ctx, func() error {
 			return s.sendDocumentByURL(ctx, cli, jid, u, caption)
 		})
 		if err != nil {
-			_ = s.logResult(accountID, groupJID, "", sessionID, "failed", "doc:"+u, err.Error(), idx+1, time.Now())
-			s.bumpRiskAndMaybePause(groupJID)
+			_ = s.logResult(ctx, accountID, groupJID, campaignID, sessionID, "failed", "doc:"+u, err.Error(), idx+1, time.Now())
+			s.bumpRiskAndMaybePause(groupJID, err)
 			log.Printf("[sender] document failed account=%s group=%s session=%s url=%s err=%v", accountID, groupJID, sessionID, u, err)
-			return err
+			if err := s.mediaFailed(ctx, cli, jid, content, caption, idx+1, err); err != nil {
+				return err
+			}
+			continue
 		}
 		preview := "doc:" + u
 		if caption != "" {
 			preview += " (caption:" + short(caption) + ")"
 		}
-		_ = s.logResult(accountID, groupJID, "", sessionID, "sent", preview, "", idx+1, time.Now())
-		if err := sleepRange(ctx, 1500*time.Millisecond, 3000*time.Millisecond); err != nil {
+		_ = s.logResult(ctx, accountID, groupJID, campaignID, sessionID, "sent", preview, "", idx+1, time.Now())
+		if err := s.sleepRange(ctx, 1500*time.Millisecond, 3000*time.Millisecond); err != nil {
+			return err
+		}
+	}
+
+	// 7) Send contact card (vCard) so recipients can tap-to-save
+	if content.ContactPhone != "" {
+		err := s.withRetry(ctx, func() error {
+			return s.sendContact(ctx, cli, jid, content.ContactName, content.ContactPhone)
+		})
+		if err != nil {
+			_ = s.logResult(ctx, accountID, groupJID, campaignID, sessionID, "failed", "contact:"+content.ContactPhone, err.Error(), 1, time.Now())
+			s.bumpRiskAndMaybePause(groupJID, err)
+			log.Printf("[sender] contact failed account=%s group=%s session=%s phone=%s err=%v", accountID, groupJID, sessionID, content.ContactPhone, err)
 			return err
 		}
+		_ = s.logResult(ctx, accountID, groupJID, campaignID, sessionID, "sent", "contact:"+contactDisplayName(content.ContactName, content.ContactPhone), "", 1, time.Now())
+	}
+
+	if !redirected {
+		s.mirrorSent(accountID, campaignID, groupName, fields, content)
 	}
 
 	// Log campaign completion
@@ -319,13 +549,13 @@ func (s *Sender) SendToGroupWithSession(ctx context.Context, accountID, groupJID
 	return nil
 }
 
-func (s *Sender) sendText(ctx context.Context, c *whatsmeow.Client, jid types.JID, text string) error {
+func (s *Sender) sendText(ctx context.Context, c Transport, jid types.JID, text string) error {
 	msg := &proto.Message{Conversation: strptr(text)}
-	_, err := c.SendMessage(ctx, jid, msg)
+	err := s.sendMessage(ctx, c, jid, msg)
 	return err
 }
 
-func (s *Sender) sendImageByURL(ctx context.Context, c *whatsmeow.Client, jid types.JID, url, caption string) error {
+func (s *Sender) sendImageByURL(ctx context.Context, c Transport, jid types.JID, url, caption string) error {
 	data, mime, err := s.fetch(ctx, url)
 	if err != nil {
 		return err
@@ -346,11 +576,12 @@ func (s *Sender) sendImageByURL(ctx context.Context, c *whatsmeow.Client, jid ty
 		FileLength:    &length,
 	}
 	msg := &proto.Message{ImageMessage: img}
-	_, err = c.SendMessage(ctx, jid, msg)
+	err = s.sendMessage(ctx, c, jid, msg)
 	return err
 }
 
-func (s *Sender) sendVideoByURL(ctx context.Context, c *whatsmeow.Client, jid types.JID, url, caption string) error {
+// sendVideoByURL mengirim video; gif=true menandai GifPlayback (mp4 diputar berulang tanpa suara).
+func (s *Sender) sendVideoByURL(ctx context.Context, c Transport, jid types.JID, url, caption string, gif bool) error {
 	data, mime, err := s.fetch(ctx, url)
 	if err != nil {
 		return err
@@ -370,12 +601,15 @@ func (s *Sender) sendVideoByURL(ctx context.Context, c *whatsmeow.Client, jid ty
 		FileSHA256:    up.FileSHA256,
 		FileLength:    &length,
 	}
+	if gif {
+		vid.GifPlayback = &gif
+	}
 	msg := &proto.Message{VideoMessage: vid}
-	_, err = c.SendMessage(ctx, jid, msg)
+	err = s.sendMessage(ctx, c, jid, msg)
 	return err
 }
 
-func (s *Sender) sendAudioByURL(ctx context.Context, c *whatsmeow.Client, jid types.JID, url string) error {
+func (s *Sender) sendAudioByURL(ctx context.Context, c Transport, jid types.JID, url string) error {
 	data, mime, err := s.fetch(ctx, url)
 	if err != nil {
 		return err
@@ -396,11 +630,11 @@ func (s *Sender) sendAudioByURL(ctx context.Context, c *whatsmeow.Client, jid ty
 		// Ptt: proto.Bool(true), // uncomment if you want voice note style
 	}
 	msg := &proto.Message{AudioMessage: am}
-	_, err = c.SendMessage(ctx, jid, msg)
+	err = s.sendMessage(ctx, c, jid, msg)
 	return err
 }
 
-func (s *Sender) sendStickerByURL(ctx context.Context, c *whatsmeow.Client, jid types.JID, url string) error {
+func (s *Sender) sendStickerByURL(ctx context.Context, c Transport, jid types.JID, url string) error {
 	data, mime, err := s.fetch(ctx, url)
 	if err != nil {
 		return err
@@ -420,11 +654,11 @@ func (s *Sender) sendStickerByURL(ctx context.Context, c *whatsmeow.Client, jid
 		FileLength:    &length,
 	}
 	msg := &proto.Message{StickerMessage: st}
-	_, err = c.SendMessage(ctx, jid, msg)
+	err = s.sendMessage(ctx, c, jid, msg)
 	return err
 }
 
-func (s *Sender) sendDocumentByURL(ctx context.Context, c *whatsmeow.Client, jid types.JID, url, caption string) error {
+func (s *Sender) sendDocumentByURL(ctx context.Context, c Transport, jid types.JID, url, caption string) error {
 	data, mime, err := s.fetch(ctx, url)
 	if err != nil {
 		return err
@@ -447,10 +681,124 @@ func (s *Sender) sendDocumentByURL(ctx context.Context, c *whatsmeow.Client, jid
 		FileLength:    &length,
 	}
 	msg := &proto.Message{DocumentMessage: doc}
-	_, err = c.SendMessage(ctx, jid, msg)
+	err = s.sendMessage(ctx, c, jid, msg)
 	return err
 }
 
+// sendContact mengirim ContactMessage berisi vCard satu nomor.
+func (s *Sender) sendContact(ctx context.Context, c Transport, jid types.JID, name, phone string) error {
+	name = contactDisplayName(name, phone)
+	msg := &proto.Message{ContactMessage: &proto.ContactMessage{
+		DisplayName: optstr(name),
+		Vcard:       optstr(buildVCard(name, phone)),
+	}}
+	return s.sendMessage(ctx, c, jid, msg)
+}
+
+// buildVCard membuat vCard 3.0 minimal; waid membuat WhatsApp menampilkan tombol chat/simpan.
+func buildVCard(name, phone string) string {
+	digits := strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' {
+			return r
+		}
+		return -1
+	}, phone)
+	esc := strings.NewReplacer(`\`, `\\`, ",", `\,`, ";", `\;`, "\n", `\n`).Replace(name)
+	return "BEGIN:VCARD\nVERSION:3.0\nFN:" + esc + "\nTEL;type=CELL;type=VOICE;waid=" + digits + ":+" + digits + "\nEND:VCARD"
+}
+
+func contactDisplayName(name, phone string) string {
+	if name = strings.TrimSpace(name); name != "" {
+		return name
+	}
+	return phone
+}
+
+// mirrorSent mengantrikan salinan promo yang sukses terkirim ke mirror Telegram (jika aktif).
+// Promo yang sama ke banyak grup hanya dicerminkan sekali (lihat mirrorKey).
+func (s *Sender) mirrorSent(accountID, campaignID, groupName string, fields map[string]string, content MessageContent) {
+	if s.Mirror == nil {
+		return
+	}
+	label, _ := s.Store.AccountLabel(accountID)
+	header := "📣 " + label
+	var parts []telegram.Part
+	if strings.TrimSpace(content.TextOnly) != "" {
+		parts = append(parts, telegram.Part{Kind: telegram.PartText, Text: personalize(content.TextOnly, groupName, fields)})
+	}
+	for _, u := range content.ImageURLs {
+		parts = append(parts, telegram.Part{Kind: telegram.PartPhoto, URL: u, Caption: personalize(content.ImageCaption, groupName, fields)})
+	}
+	for _, p := range content.Products {
+		parts = append(parts, telegram.Part{Kind: telegram.PartPhoto, URL: p.ImageURL, Caption: p.Caption()})
+	}
+	for _, u := range append(append([]string{}, content.VideoURLs...), content.GifURLs...) {
+		parts = append(parts, telegram.Part{Kind: telegram.PartVideo, URL: u, Caption: personalize(content.VideoCaption, groupName, fields)})
+	}
+	for _, u := range append(append([]string{}, content.AudioURLs...), content.VoiceURLs...) {
+		parts = append(parts, telegram.Part{Kind: telegram.PartAudio, URL: u})
+	}
+	for _, u := range content.StickerURLs {
+		parts = append(parts, telegram.Part{Kind: telegram.PartSticker, URL: u})
+	}
+	for _, u := range content.DocURLs {
+		parts = append(parts, telegram.Part{Kind: telegram.PartDocument, URL: u, Caption: personalize(content.DocCaption, groupName, fields)})
+	}
+	if content.ContactPhone != "" {
+		parts = append(parts, telegram.Part{Kind: telegram.PartText, Text: "👤 " + contactDisplayName(content.ContactName, content.ContactPhone) + " (" + content.ContactPhone + ")"})
+	}
+	s.Mirror.Enqueue(telegram.Post{Key: mirrorKey(campaignID, content), Header: header, Parts: parts})
+}
+
+// SetTelegramMirror menerapkan konfigurasi mirror Telegram (saat start dan setelah diubah lewat API).
+func (s *Sender) SetTelegramMirror(cfg model.TelegramMirror) {
+	if s.Mirror == nil {
+		return
+	}
+	if !cfg.Enabled {
+		cfg.BotToken, cfg.ChatID = "", ""
+	}
+	s.Mirror.SetConfig(cfg.BotToken, cfg.ChatID)
+}
+
+// mirrorKey identitas promo untuk mirror: campaign, versi template, atau hash isi kustom.
+func mirrorKey(campaignID string, content MessageContent) string {
+	switch {
+	case campaignID != "":
+		return "campaign:" + campaignID
+	case content.TemplateID != "":
+		return fmt.Sprintf("template:%s:%d", content.TemplateID, content.TemplateVersion)
+	}
+	raw, _ := json.Marshal(content)
+	sum := sha256.Sum256(raw)
+	return "content:" + hex.EncodeToString(sum[:])
+}
+
+// resolveVars mengisi variabel dinamis (stok, harga, dst.) di teks dan caption sekali per kirim,
+// sebelum short link agar URL hasil lookup ikut di-rewrite.
+func (s *Sender) resolveVars(ctx context.Context, content MessageContent) MessageContent {
+	if s.Vars == nil {
+		return content
+	}
+	content.TextOnly = s.Vars.Render(ctx, content.TextOnly)
+	content.ImageCaption = s.Vars.Render(ctx, content.ImageCaption)
+	content.VideoCaption = s.Vars.Render(ctx, content.VideoCaption)
+	content.DocCaption = s.Vars.Render(ctx, content.DocCaption)
+	return content
+}
+
+// rewriteLinks mengganti URL di teks dan caption dengan short link (jika Links aktif).
+func (s *Sender) rewriteLinks(content MessageContent) MessageContent {
+	if s.Links == nil || !s.Links.Rewrite {
+		return content
+	}
+	content.TextOnly = s.Links.RewriteText(content.TextOnly, "")
+	content.ImageCaption = s.Links.RewriteText(content.ImageCaption, "")
+	content.VideoCaption = s.Links.RewriteText(content.VideoCaption, "")
+	content.DocCaption = s.Links.RewriteText(content.DocCaption, "")
+	return content
+}
+
 func fileNameFromURL(u string) string {
 	s := u
 	if i := strings.Index(s, "?"); i >= 0 {
@@ -462,17 +810,25 @@ func fileNameFromURL(u string) string {
 	return "file"
 }
 
+func (s *Sender) uploadDir() string {
+	if s.UploadDir == "" {
+		return "uploads"
+	}
+	return s.UploadDir
+}
+
+// Fetch memuat media (upload lokal atau URL remote) beserta content-type-nya.
+func (s *Sender) Fetch(ctx context.Context, url string) ([]byte, string, error) {
+	return s.fetch(ctx, url)
+}
+
 func (s *Sender) fetch(ctx context.Context, url string) ([]byte, string, error) {
 	// Handle local uploads served by our app: "/uploads/..." or "uploads/..."
 	if strings.HasPrefix(url, "/uploads/") || strings.HasPrefix(url, "uploads/") {
-		path := url
-		// normalize leading slash
-		if strings.HasPrefix(path, "/") {
-			path = path[1:]
-		}
-		// security: must stay under uploads/
-		if !strings.HasPrefix(path, "uploads/") {
-			return nil, "", fmt.Errorf("invalid local upload path")
+		// security: must stay under UploadDir
+		path, err := paths.UploadFile(s.uploadDir(), url)
+		if err != nil {
+			return nil, "", err
 		}
 		f, err := os.Open(path)
 		if err != nil {
@@ -507,11 +863,24 @@ func (s *Sender) fetch(ctx context.Context, url string) ([]byte, string, error)
 		return body, ct, nil
 	}
 
-	// Remote URLs: fetch via HTTP client
+	// Remote URLs: pakai cache lokal jika masih segar, jika tidak fetch via HTTP client
+	if body, ct, ok := s.Media.Get(url); ok {
+		return body, ct, nil
+	}
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
 		return nil, "", err
 	}
+	// Policy unduhan (settings fetch_policy): host, alamat privat, ukuran, redirect, konkurensi
+	policy := s.fetchPolicy()
+	if err := checkFetchURL(policy, req.URL); err != nil {
+		return nil, "", err
+	}
+	release, err := s.acquireHost(ctx, req.URL.Hostname())
+	if err != nil {
+		return nil, "", err
+	}
+	defer release()
 	res, err := s.Client.Do(req)
 	if err != nil {
 		return nil, "", err
@@ -519,13 +888,19 @@ func (s *Sender) fetch(ctx context.Context, url string) ([]byte, string, error)
 	defer res.Body.Close()
 	if res.StatusCode < 200 || res.StatusCode >= 300 {
 		// return typed error for retry classification
-		_, _ = io.Copy(io.Discard, res.Body)
+		_, _ = io.Copy(io.Discard, io.LimitReader(res.Body, 1<<20))
 		return nil, "", &httpStatusError{code: res.StatusCode, url: url}
 	}
-	body, err := io.ReadAll(res.Body)
+	if res.ContentLength > policy.MaxBytes {
+		return nil, "", fmt.Errorf("%w: %s is %d bytes (max %d)", ErrFetchBlocked, url, res.ContentLength, policy.MaxBytes)
+	}
+	body, err := io.ReadAll(io.LimitReader(res.Body, policy.MaxBytes+1))
 	if err != nil {
 		return nil, "", err
 	}
+	if int64(len(body)) > policy.MaxBytes {
+		return nil, "", fmt.Errorf("%w: %s exceeds %d bytes", ErrFetchBlocked, url, policy.MaxBytes)
+	}
 	ct := res.Header.Get("Content-Type")
 	if ct == "" {
 		// naive fallback using URL extension
@@ -551,26 +926,85 @@ func (s *Sender) fetch(ctx context.Context, url string) ([]byte, string, error)
 			ct = "application/octet-stream"
 		}
 	}
+	if err := s.Media.Put(url, body, ct); err != nil {
+		log.Printf("[sender] media cache put url=%s err=%v", url, err)
+	}
 	return body, ct, nil
 }
 
-func (s *Sender) logResult(accountID, groupID, campaignID, sessionID, status, preview, errMsg string, attempt int, scheduled time.Time) error {
-	_, err := s.Store.DB.Exec(`INSERT INTO logs (account_id,group_id,campaign_id,campaign_session_id,status,error,message_preview,attempt,scheduled_for) 
-	VALUES (?,?,?,?,?,?,?,?,?)`,
-		accountID, groupID, nullIfEmpty(campaignID), nullIfEmpty(sessionID), status, errMsg, preview, attempt, scheduled)
+// logResult mencatat hasil satu bagian kiriman; untuk status sent, ID pesan WhatsApp terakhir
+// di sesi ctx ikut disimpan agar pesan bisa diedit nanti.
+func (s *Sender) logResult(ctx context.Context, accountID, groupID, campaignID, sessionID, status, preview, errMsg string, attempt int, scheduled time.Time) error {
+	msgID := takeMessageID(ctx)
+	if status != "sent" {
+		msgID = ""
+	}
+	if meta := sendMetaFrom(ctx); meta != nil && meta.throttled && status == "failed" {
+		status = StatusThrottled
+	}
+	var tplID string
+	var tplVersion int
+	if meta := sendMetaFrom(ctx); meta != nil {
+		tplID, tplVersion = meta.templateID, meta.templateVersion
+	}
+	err := s.Store.InsertLog(model.LogEntry{
+		AccountID:       accountID,
+		GroupID:         groupID,
+		CampaignID:      campaignID,
+		SessionID:       sessionID,
+		Status:          status,
+		Error:           errMsg,
+		MessagePrev:     preview,
+		MessageID:       msgID,
+		Attempt:         attempt,
+		ScheduledFor:    scheduled,
+		TemplateID:      tplID,
+		TemplateVersion: tplVersion,
+	})
+	s.Ship.Emit(logship.Event{
+		Kind:      logship.KindSend,
+		AccountID: accountID,
+		GroupID:   groupID,
+		Status:    status,
+		Error:     errMsg,
+		Message:   preview,
+		Fields: map[string]string{
+			"campaign_id": campaignID,
+			"session_id":  sessionID,
+			"attempt":     strconv.Itoa(attempt),
+		},
+	})
+	s.Hooks.Emit("send."+status, map[string]any{
+		"account_id":  accountID,
+		"group_id":    groupID,
+		"campaign_id": campaignID,
+		"session_id":  sessionID,
+		"preview":     preview,
+		"error":       errMsg,
+		"attempt":     attempt,
+	})
 	return err
 }
 
 func (s *Sender) lookupGroupName(groupID string) string {
-	var name sql.NullString
-	_ = s.Store.DB.QueryRow(`SELECT name FROM groups WHERE id=?`, groupID).Scan(&name)
-	if name.Valid {
-		return name.String
+	name, _ := s.Store.GroupName(groupID)
+	return name
+}
+
+// lookupGroupFields memuat custom field grup untuk placeholder {field:key}.
+func (s *Sender) lookupGroupFields(groupJID string) map[string]string {
+	fields, err := s.Store.GroupFields(groupJID)
+	if err != nil {
+		log.Printf("[sender] load group fields group=%s err=%v", groupJID, err)
+		return nil
 	}
-	return ""
+	return fields
 }
 
-func personalize(text, groupName string) string {
+// fieldPlaceholderRe mencocokkan {field:key}; field yang tidak diisi untuk grup diganti string kosong.
+var fieldPlaceholderRe = regexp.MustCompile(`\{field:([A-Za-z0-9_.-]+)\}`)
+
+func personalize(text, groupName string, fields map[string]string) string {
 	if text == "" {
 		return text
 	}
@@ -586,7 +1020,14 @@ func personalize(text, groupName string) string {
 		"{group_name}", groupName,
 		"{time_now}", timeNow,
 	)
-	return r.Replace(text)
+	text = r.Replace(text)
+	if strings.Contains(text, "{field:") {
+		text = fieldPlaceholderRe.ReplaceAllStringFunc(text, func(m string) string {
+			key := strings.ToLower(fieldPlaceholderRe.FindStringSubmatch(m)[1])
+			return fields[key]
+		})
+	}
+	return text
 }
 
 func short(s string) string {
@@ -604,52 +1045,100 @@ func optstr(s string) *string {
 	return &s
 }
 
-func nullIfEmpty(s string) any {
-	if strings.TrimSpace(s) == "" {
-		return sql.NullString{}
-	}
-	return sql.NullString{String: s, Valid: true}
-}
-
 // Build MessageContent from a random enabled template (DB-level rotation).
-func (s *Sender) RandomTemplateContent(ctx context.Context) (MessageContent, error) {
-	var textOnly, imgJSON, imgCaption, vidJSON, vidCaption, stJSON, docJSON, docCaption, audioJSON string
-	err := s.Store.DB.QueryRowContext(ctx, `
-		SELECT
-			COALESCE(text_only,''),
-			COALESCE(images_json,''),
-			COALESCE(images_caption,''),
-			COALESCE(videos_json,''),
-			COALESCE(videos_caption,''),
-			COALESCE(stickers_json,''),
-			COALESCE(docs_json,''),
-			COALESCE(docs_caption,''),
-			COALESCE(audio_json,'')
-		FROM templates
-		WHERE enabled=1
-		ORDER BY RANDOM()
-		LIMIT 1
-	`).Scan(&textOnly, &imgJSON, &imgCaption, &vidJSON, &vidCaption, &stJSON, &docJSON, &docCaption, &audioJSON)
+// lang membatasi ke template untuk bahasa tersebut (kosong = semua template).
+func (s *Sender) RandomTemplateContent(ctx context.Context, lang string) (MessageContent, error) {
+	t, err := s.Store.RandomTemplate(ctx, lang, s.Rand)
 	if err != nil {
 		return MessageContent{}, err
 	}
+	return TemplateContent(t), nil
+}
+
+// TemplateContent mengubah template menjadi MessageContent.
+func TemplateContent(t model.Template) MessageContent {
+	return MessageContent{
+		TextOnly:         t.TextOnly,
+		ImageURLs:        t.ImageURLs,
+		ImageCaption:     t.ImageCaption,
+		VideoURLs:        t.VideoURLs,
+		VideoCaption:     t.VideoCaption,
+		Products:         t.Products,
+		GifURLs:          t.GifURLs,
+		StickerURLs:      t.StickerURLs,
+		DocURLs:          t.DocURLs,
+		DocCaption:       t.DocCaption,
+		AudioURLs:        t.AudioURLs,
+		VoiceURLs:        t.VoiceURLs,
+		LinkPreview:      t.LinkPreview,
+		ContactName:      t.ContactName,
+		ContactPhone:     t.ContactPhone,
+		MediaFailPolicy:  t.MediaFailPolicy,
+		FallbackImageURL: t.FallbackImageURL,
+		MentionAll:       t.MentionAll,
+		TemplateID:       t.ID,
+		TemplateVersion:  t.Version,
+	}
+}
+
+// CampaignContent mengubah campaign menjadi MessageContent. Teks campaign menjadi caption media
+// pertama (gambar, lalu video, lalu dokumen) atau dikirim sebagai teks saja jika tanpa media.
+func CampaignContent(c model.Campaign) MessageContent {
 	content := MessageContent{
-		TextOnly:      textOnly,
-		ImageURLs:     parseJSONArr(imgJSON),
-		ImageCaption:  imgCaption,
-		VideoURLs:     parseJSONArr(vidJSON),
-		VideoCaption:  vidCaption,
-		StickerURLs:   parseJSONArr(stJSON),
-		DocURLs:       parseJSONArr(docJSON),
-		DocCaption:    docCaption,
-		AudioURLs:     parseJSONArr(audioJSON),
+		ImageURLs:   c.ImageURLs,
+		VideoURLs:   c.VideoURLs,
+		StickerURLs: c.StickerURLs,
+		DocURLs:     c.DocURLs,
+	}
+	switch {
+	case len(c.ImageURLs) > 0:
+		content.ImageCaption = c.Text
+	case len(c.VideoURLs) > 0:
+		content.VideoCaption = c.Text
+	case len(c.DocURLs) > 0:
+		content.DocCaption = c.Text
+	default:
+		content.TextOnly = c.Text
+	}
+	return content
+}
+
+// SendCampaign mengirim konten campaign ke grup (log dicatat dengan campaign_id).
+// Jika campaign tidak punya konten, dipakai template acak seperti biasa; campaign
+// cross-promo dikirim lewat sendCrossPromo.
+func (s *Sender) SendCampaign(ctx context.Context, accountID, groupJID, campaignID string) error {
+	ctx = WithCampaign(ctx, campaignID)
+	c, err := s.Store.GetCampaign(campaignID)
+	if err != nil {
+		return err
+	}
+	if c.Mode == model.CampaignModeCrossPromo {
+		return s.sendCrossPromo(ctx, accountID, groupJID, c)
 	}
-	return content, nil
+	if !c.HasContent() {
+		return s.SendToGroupUsingRandomTemplate(ctx, accountID, groupJID)
+	}
+	return s.SendToGroupWithSession(ctx, accountID, groupJID, CampaignContent(c), uuid.NewString())
+}
+
+// SendTemplateToGroup mengirim satu template tertentu ke grup (mis. intro pasca-join).
+func (s *Sender) SendTemplateToGroup(ctx context.Context, accountID, groupJID, templateID string) error {
+	t, err := s.Store.GetTemplate(templateID)
+	if err != nil {
+		return fmt.Errorf("load template %s: %w", templateID, err)
+	}
+	return s.SendToGroupWithSession(ctx, accountID, groupJID, TemplateContent(t), uuid.NewString())
 }
 
 // Convenience wrapper to send using a random active template.
 func (s *Sender) SendToGroupUsingRandomTemplate(ctx context.Context, accountID, groupJID string) error {
-	content, err := s.RandomTemplateContent(ctx)
+	// Template dipilih sesuai bahasa grup (manual atau hasil deteksi) agar materi berbahasa
+	// lain tidak terkirim ke grup ini.
+	lang, err := s.Store.GroupLanguage(groupJID)
+	if err != nil {
+		log.Printf("[sender] load group language group=%s err=%v", groupJID, err)
+	}
+	content, err := s.RandomTemplateContent(ctx, lang)
 	if err != nil {
 		return fmt.Errorf("no active template or query failed: %w", err)
 	}
@@ -659,11 +1148,3 @@ func (s *Sender) SendToGroupUsingRandomTemplate(ctx context.Context, accountID,
 	return s.SendToGroupWithSession(ctx, accountID, groupJID, content, sessionID)
 }
 
-func parseJSONArr(s string) []string {
-	var arr []string
-	if strings.TrimSpace(s) == "" {
-		return arr
-	}
-	_ = json.Unmarshal([]byte(s), &arr)
-	return arr
-}
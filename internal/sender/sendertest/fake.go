@@ -0,0 +1,369 @@
+// Package sendertest menyediakan transport WhatsApp palsu di memori untuk test skenario
+// scheduler+sender: mencatat pesan "terkirim" dan bisa mensimulasikan kegagalan, latensi,
+// rate limit, akun belum paired/terputus, tanpa koneksi WhatsApp sungguhan.
+package sendertest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.mau.fi/whatsmeow"
+	"go.mau.fi/whatsmeow/binary/proto"
+	"go.mau.fi/whatsmeow/types"
+
+	"promote/internal/sender"
+)
+
+var (
+	// ErrRateLimited disimulasikan saat kirim melewati RateLimit (meniru error 429 server).
+	ErrRateLimited = errors.New("server returned error 429: rate-overlimit")
+	// ErrTimeout kegagalan sementara yang dianggap retryable oleh sender.
+	ErrTimeout = errors.New("fake: send timeout")
+	// ErrNotConnected dikembalikan saat kirim lewat akun yang tidak terhubung.
+	ErrNotConnected = errors.New("fake: websocket not connected")
+)
+
+// Message satu pesan yang "terkirim" lewat transport palsu.
+type Message struct {
+	AccountID string
+	To        string
+	ID        string
+	// Kind text, image, video, audio, sticker, document, contact, product, edit, revoke atau other.
+	Kind string
+	// Text teks pesan atau caption media.
+	Text string
+	At   time.Time
+}
+
+// Fake kumpulan transport palsu per akun dengan log kiriman bersama. Akun dibuat otomatis
+// (paired, belum terhubung) saat pertama diminta.
+type Fake struct {
+	// Now sumber waktu untuk timestamp dan jendela rate limit (nil = time.Now).
+	Now func() time.Time
+	// OnSleep (opsional) dipanggil untuk setiap jeda sender, mis. memajukan jam palsu.
+	OnSleep func(d time.Duration)
+
+	mu       sync.Mutex
+	accounts map[string]*Transport
+	sent     []Message
+	slept    time.Duration
+	seq      int
+}
+
+// New membuat Fake kosong.
+func New() *Fake {
+	return &Fake{accounts: map[string]*Transport{}}
+}
+
+func (f *Fake) now() time.Time {
+	if f.Now != nil {
+		return f.Now()
+	}
+	return time.Now()
+}
+
+// Transports memenuhi Sender.Transports.
+func (f *Fake) Transports(accountID string) (sender.Transport, error) {
+	return f.Account(accountID), nil
+}
+
+// Sleep memenuhi Sender.Sleep: tidak menunggu sungguhan, hanya mencatat total jeda.
+func (f *Fake) Sleep(ctx context.Context, d time.Duration) error {
+	f.mu.Lock()
+	f.slept += d
+	hook := f.OnSleep
+	f.mu.Unlock()
+	if hook != nil {
+		hook(d)
+	}
+	return ctx.Err()
+}
+
+// Install memasang Fake sebagai transport dan sumber jeda s.
+func (f *Fake) Install(s *sender.Sender) {
+	s.Transports = f.Transports
+	s.Sleep = f.Sleep
+}
+
+// Account mengembalikan (atau membuat) transport akun id.
+func (f *Fake) Account(id string) *Transport {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	t, ok := f.accounts[id]
+	if !ok {
+		t = &Transport{fake: f, accountID: id, paired: true,
+			jid: types.NewJID("62800"+fmt.Sprint(len(f.accounts)+1), types.DefaultUserServer)}
+		f.accounts[id] = t
+	}
+	return t
+}
+
+// Sent salinan semua pesan terkirim, urut waktu kirim.
+func (f *Fake) Sent() []Message {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]Message(nil), f.sent...)
+}
+
+// SentTo pesan terkirim ke JID to.
+func (f *Fake) SentTo(to string) []Message {
+	var out []Message
+	for _, m := range f.Sent() {
+		if m.To == to {
+			out = append(out, m)
+		}
+	}
+	return out
+}
+
+// Slept total jeda yang diminta sender (pacing + backoff).
+func (f *Fake) Slept() time.Duration {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.slept
+}
+
+// Reset mengosongkan log kiriman dan total jeda (konfigurasi akun tetap).
+func (f *Fake) Reset() {
+	f.mu.Lock()
+	f.sent, f.slept = nil, 0
+	f.mu.Unlock()
+}
+
+func (f *Fake) record(m Message) string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.seq++
+	m.ID = fmt.Sprintf("FAKE%06d", f.seq)
+	f.sent = append(f.sent, m)
+	return m.ID
+}
+
+// Transport transport palsu satu akun; memenuhi sender.Transport.
+type Transport struct {
+	fake      *Fake
+	accountID string
+	jid       types.JID
+
+	mu        sync.Mutex
+	paired    bool
+	connected bool
+	latency   time.Duration
+	failures  []error
+	failWhen  func(to types.JID, msg *proto.Message) error
+	rateLimit int
+	rateWin   time.Duration
+	window    []time.Time
+	attempts  int
+}
+
+var _ sender.Transport = (*Transport)(nil)
+
+// SetPaired mengatur apakah akun dianggap sudah paired (belum paired = dilewati scheduler).
+func (t *Transport) SetPaired(paired bool) *Transport {
+	t.mu.Lock()
+	t.paired = paired
+	if !paired {
+		t.connected = false
+	}
+	t.mu.Unlock()
+	return t
+}
+
+// Disconnect memutus koneksi; Connect berikutnya menyambung lagi.
+func (t *Transport) Disconnect() *Transport {
+	t.mu.Lock()
+	t.connected = false
+	t.mu.Unlock()
+	return t
+}
+
+// SetLatency menambah jeda nyata sebelum setiap kirim (tetap menghormati ctx).
+func (t *Transport) SetLatency(d time.Duration) *Transport {
+	t.mu.Lock()
+	t.latency = d
+	t.mu.Unlock()
+	return t
+}
+
+// FailNext membuat kirim berikutnya gagal berurutan dengan errs (satu error per percobaan).
+func (t *Transport) FailNext(errs ...error) *Transport {
+	t.mu.Lock()
+	t.failures = append(t.failures, errs...)
+	t.mu.Unlock()
+	return t
+}
+
+// FailWhen memasang fungsi yang bisa menggagalkan kirim tertentu (nil = tidak ada).
+func (t *Transport) FailWhen(fn func(to types.JID, msg *proto.Message) error) *Transport {
+	t.mu.Lock()
+	t.failWhen = fn
+	t.mu.Unlock()
+	return t
+}
+
+// SetRateLimit membatasi n kirim per window; kelebihannya gagal dengan ErrRateLimited.
+func (t *Transport) SetRateLimit(n int, window time.Duration) *Transport {
+	t.mu.Lock()
+	t.rateLimit, t.rateWin, t.window = n, window, nil
+	t.mu.Unlock()
+	return t
+}
+
+// Attempts jumlah percobaan SendMessage (termasuk yang gagal).
+func (t *Transport) Attempts() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.attempts
+}
+
+func (t *Transport) Paired() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.paired
+}
+
+func (t *Transport) IsConnected() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.connected
+}
+
+func (t *Transport) Connect() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if !t.paired {
+		return errors.New("fake: not paired")
+	}
+	t.connected = true
+	return nil
+}
+
+func (t *Transport) OwnJID() types.JID {
+	if !t.Paired() {
+		return types.EmptyJID
+	}
+	return t.jid
+}
+
+func (t *Transport) Upload(ctx context.Context, data []byte, mediaType whatsmeow.MediaType) (whatsmeow.UploadResponse, error) {
+	if !t.IsConnected() {
+		return whatsmeow.UploadResponse{}, ErrNotConnected
+	}
+	return whatsmeow.UploadResponse{
+		URL:           "https://mmg.fake.local/" + string(mediaType),
+		DirectPath:    "/fake/" + string(mediaType),
+		MediaKey:      []byte("fake-media-key"),
+		FileEncSHA256: []byte("fake-enc-sha256"),
+		FileSHA256:    []byte("fake-sha256"),
+		FileLength:    uint64(len(data)),
+	}, nil
+}
+
+func (t *Transport) UploadNewsletter(ctx context.Context, data []byte, mediaType whatsmeow.MediaType) (whatsmeow.UploadResponse, error) {
+	up, err := t.Upload(ctx, data, mediaType)
+	up.MediaKey, up.FileEncSHA256, up.Handle = nil, nil, "fake-handle-"+string(mediaType)
+	return up, err
+}
+
+func (t *Transport) SendMessage(ctx context.Context, to types.JID, msg *proto.Message, extra ...whatsmeow.SendRequestExtra) (whatsmeow.SendResponse, error) {
+	t.mu.Lock()
+	t.attempts++
+	latency := t.latency
+	t.mu.Unlock()
+	if latency > 0 {
+		select {
+		case <-time.After(latency):
+		case <-ctx.Done():
+			return whatsmeow.SendResponse{}, ctx.Err()
+		}
+	}
+	if err := t.check(to, msg); err != nil {
+		return whatsmeow.SendResponse{}, err
+	}
+	now := t.fake.now()
+	kind, text := describe(msg)
+	id := t.fake.record(Message{AccountID: t.accountID, To: to.String(), Kind: kind, Text: text, At: now})
+	return whatsmeow.SendResponse{ID: types.MessageID(id), Timestamp: now, Sender: t.jid}, nil
+}
+
+// check menerapkan status koneksi, antrean kegagalan, FailWhen dan rate limit.
+func (t *Transport) check(to types.JID, msg *proto.Message) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if !t.connected {
+		return ErrNotConnected
+	}
+	if len(t.failures) > 0 {
+		err := t.failures[0]
+		t.failures = t.failures[1:]
+		if err != nil {
+			return err
+		}
+	}
+	if t.failWhen != nil {
+		if err := t.failWhen(to, msg); err != nil {
+			return err
+		}
+	}
+	if t.rateLimit > 0 {
+		now := t.fake.now()
+		kept := t.window[:0]
+		for _, at := range t.window {
+			if now.Sub(at) < t.rateWin {
+				kept = append(kept, at)
+			}
+		}
+		t.window = kept
+		if len(t.window) >= t.rateLimit {
+			return ErrRateLimited
+		}
+		t.window = append(t.window, now)
+	}
+	return nil
+}
+
+func (t *Transport) BuildEdit(chat types.JID, id types.MessageID, newContent *proto.Message) *proto.Message {
+	return (&whatsmeow.Client{}).BuildEdit(chat, id, newContent)
+}
+
+func (t *Transport) BuildRevoke(chat, _ types.JID, id types.MessageID) *proto.Message {
+	fromMe := true
+	remote, msgID := chat.String(), string(id)
+	return &proto.Message{ProtocolMessage: &proto.ProtocolMessage{
+		Type: proto.ProtocolMessage_REVOKE.Enum(),
+		Key:  &proto.MessageKey{RemoteJID: &remote, FromMe: &fromMe, ID: &msgID},
+	}}
+}
+
+// describe jenis dan teks/caption pesan untuk dicatat.
+func describe(m *proto.Message) (kind, text string) {
+	switch {
+	case m.GetConversation() != "":
+		return "text", m.GetConversation()
+	case m.GetExtendedTextMessage() != nil:
+		return "text", m.GetExtendedTextMessage().GetText()
+	case m.GetImageMessage() != nil:
+		return "image", m.GetImageMessage().GetCaption()
+	case m.GetVideoMessage() != nil:
+		return "video", m.GetVideoMessage().GetCaption()
+	case m.GetAudioMessage() != nil:
+		return "audio", ""
+	case m.GetStickerMessage() != nil:
+		return "sticker", ""
+	case m.GetDocumentMessage() != nil:
+		return "document", m.GetDocumentMessage().GetCaption()
+	case m.GetContactMessage() != nil:
+		return "contact", m.GetContactMessage().GetDisplayName()
+	case m.GetProductMessage() != nil:
+		return "product", m.GetProductMessage().GetBody()
+	case m.GetEditedMessage() != nil:
+		return "edit", m.GetEditedMessage().GetMessage().GetProtocolMessage().GetEditedMessage().GetConversation()
+	case m.GetProtocolMessage().GetType() == proto.ProtocolMessage_REVOKE:
+		return "revoke", ""
+	}
+	return "other", ""
+}
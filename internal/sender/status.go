@@ -0,0 +1,165 @@
+package sender
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"go.mau.fi/whatsmeow"
+	"go.mau.fi/whatsmeow/binary/proto"
+	"go.mau.fi/whatsmeow/types"
+
+	"promote/internal/model"
+)
+
+// ErrEmptyStatus dikembalikan jika konten tidak punya teks, gambar maupun video untuk status.
+var ErrEmptyStatus = errors.New("status has no text, image or video")
+
+// Warna status teks (ARGB): latar hijau tua WhatsApp, teks putih.
+const (
+	statusTextBackground uint32 = 0xFF128C7E
+	statusTextColor      uint32 = 0xFFFFFFFF
+)
+
+// PostStatus memposting konten sebagai satu status (story) akun ke status@broadcast: gambar
+// pertama (caption = caption gambar atau teks), jika tidak ada video pertama, selain itu status
+// teks berlatar warna. Hasilnya dicatat di status_posts. Akun observer ditolak, begitu pula
+// selama safe mode aktif.
+func (s *Sender) PostStatus(ctx context.Context, accountID string, content MessageContent) error {
+	if err := s.checkObserver(accountID); err != nil {
+		return err
+	}
+	if err := s.checkThrottled(accountID); err != nil {
+		return err
+	}
+	if err := s.checkSafeModeBroadcast(); err != nil {
+		return err
+	}
+	ctx = withSendMeta(ctx, accountID, "")
+	text := strings.TrimSpace(content.TextOnly)
+	if text == "" && len(content.ImageURLs) == 0 && len(content.VideoURLs) == 0 {
+		return ErrEmptyStatus
+	}
+	if err := s.EnsureConnected(accountID); err != nil {
+		return err
+	}
+	cli, err := s.transport(accountID)
+	if err != nil {
+		return err
+	}
+	content = s.resolveVars(ctx, content)
+	content = s.rewriteLinks(content)
+	text = personalize(strings.TrimSpace(content.TextOnly), "", nil)
+
+	p := model.StatusPost{AccountID: accountID, TemplateID: content.TemplateID, TemplateVersion: content.TemplateVersion}
+	var build func() (*proto.Message, error)
+	switch {
+	case len(content.ImageURLs) > 0:
+		u, caption := content.ImageURLs[0], firstNonEmpty(content.ImageCaption, text)
+		p.Kind, p.Preview = "image", "image:"+u
+		build = func() (*proto.Message, error) { return s.statusImage(ctx, cli, u, personalize(caption, "", nil)) }
+	case len(content.VideoURLs) > 0:
+		u, caption := content.VideoURLs[0], firstNonEmpty(content.VideoCaption, text)
+		p.Kind, p.Preview = "video", "video:"+u
+		build = func() (*proto.Message, error) { return s.statusVideo(ctx, cli, u, personalize(caption, "", nil)) }
+	default:
+		bg, fg, font := statusTextBackground, statusTextColor, proto.ExtendedTextMessage_SYSTEM
+		p.Kind, p.Preview = "text", short(text)
+		build = func() (*proto.Message, error) {
+			return &proto.Message{ExtendedTextMessage: &proto.ExtendedTextMessage{
+				Text: strptr(text), BackgroundArgb: &bg, TextArgb: &fg, Font: &font,
+			}}, nil
+		}
+	}
+
+	err = s.withRetry(ctx, func() error {
+		msg, err := build()
+		if err != nil {
+			return err
+		}
+		resp, err := cli.SendMessage(ctx, types.StatusBroadcastJID, msg)
+		p.MessageID = resp.ID
+		return err
+	})
+	p.Status = "sent"
+	if err != nil {
+		p.Status, p.Error = "failed", err.Error()
+	}
+	if errors.Is(err, ErrThrottled) {
+		p.Status = StatusThrottled
+	}
+	if rerr := s.Store.InsertStatusPost(p); rerr != nil {
+		log.Printf("[sender] record status post account=%s err=%v", accountID, rerr)
+	}
+	if err != nil {
+		return fmt.Errorf("post %s status: %w", p.Kind, err)
+	}
+	log.Printf("[sender] STATUS_POST account=%s kind=%s", accountID, p.Kind)
+	return nil
+}
+
+// statusImage mengunggah gambar (terenkripsi seperti kiriman biasa) untuk status.
+func (s *Sender) statusImage(ctx context.Context, c Transport, url, caption string) (*proto.Message, error) {
+	data, mime, err := s.fetch(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+	up, err := c.Upload(ctx, data, whatsmeow.MediaImage)
+	if err != nil {
+		return nil, fmt.Errorf("upload image: %w", err)
+	}
+	length := uint64(len(data))
+	return &proto.Message{ImageMessage: &proto.ImageMessage{
+		Caption:       optstr(caption),
+		Mimetype:      optstr(mime),
+		URL:           optstr(up.URL),
+		DirectPath:    optstr(up.DirectPath),
+		MediaKey:      up.MediaKey,
+		FileEncSHA256: up.FileEncSHA256,
+		FileSHA256:    up.FileSHA256,
+		FileLength:    &length,
+	}}, nil
+}
+
+// statusVideo mengunggah video untuk status.
+func (s *Sender) statusVideo(ctx context.Context, c Transport, url, caption string) (*proto.Message, error) {
+	data, mime, err := s.fetch(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+	up, err := c.Upload(ctx, data, whatsmeow.MediaVideo)
+	if err != nil {
+		return nil, fmt.Errorf("upload video: %w", err)
+	}
+	length := uint64(len(data))
+	return &proto.Message{VideoMessage: &proto.VideoMessage{
+		Caption:       optstr(caption),
+		Mimetype:      optstr(mime),
+		URL:           optstr(up.URL),
+		DirectPath:    optstr(up.DirectPath),
+		MediaKey:      up.MediaKey,
+		FileEncSHA256: up.FileEncSHA256,
+		FileSHA256:    up.FileSHA256,
+		FileLength:    &length,
+	}}, nil
+}
+
+// PostRandomTemplateStatus memposting template aktif acak sebagai status akun.
+func (s *Sender) PostRandomTemplateStatus(ctx context.Context, accountID string) error {
+	content, err := s.RandomTemplateContent(ctx, "")
+	if err != nil {
+		return fmt.Errorf("no active template or query failed: %w", err)
+	}
+	return s.PostStatus(ctx, accountID, content)
+}
+
+func firstNonEmpty(vals ...string) string {
+	for _, v := range vals {
+		if strings.TrimSpace(v) != "" {
+			return v
+		}
+	}
+	return ""
+}
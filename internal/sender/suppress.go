@@ -0,0 +1,21 @@
+package sender
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrSuppressed dikembalikan jika grup atau nomor tujuan ada di daftar supresi global.
+var ErrSuppressed = errors.New("target is on the suppression list")
+
+// checkSuppressed menolak kiriman ke grup/nomor yang disupresi (dicek sebelum safe mode).
+func (s *Sender) checkSuppressed(target string) error {
+	suppressed, err := s.Store.IsSuppressed(target)
+	if err != nil {
+		return fmt.Errorf("check suppression: %w", err)
+	}
+	if suppressed {
+		return fmt.Errorf("%w: %s", ErrSuppressed, target)
+	}
+	return nil
+}
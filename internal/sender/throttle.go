@@ -0,0 +1,92 @@
+package sender
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.mau.fi/whatsmeow"
+)
+
+// ErrThrottled WhatsApp menolak kiriman dengan kode rate-limit/anti-spam, atau akun masih dalam
+// cooldown throttle. Kiriman seperti ini tidak pernah dicoba ulang.
+var ErrThrottled = errors.New("account throttled by WhatsApp")
+
+// StatusThrottled status logs (dan posting status/channel, DM) untuk kiriman yang ditolak throttle.
+const StatusThrottled = "throttled"
+
+// throttleCodes kode error server WhatsApp untuk rate-limit (429) dan anti-spam (463, 479).
+var throttleCodes = map[int]bool{429: true, 463: true, 479: true}
+
+var serverErrCodeRe = regexp.MustCompile(`server returned error (\d+)`)
+
+// throttleCooldown lama akun dihentikan setelah throttle (SENDER_THROTTLE_COOLDOWN_HOURS, default 24 jam).
+var throttleCooldown = func() time.Duration {
+	if n, err := strconv.Atoi(strings.TrimSpace(os.Getenv("SENDER_THROTTLE_COOLDOWN_HOURS"))); err == nil && n > 0 {
+		return time.Duration(n) * time.Hour
+	}
+	return 24 * time.Hour
+}()
+
+// isThrottled true jika err adalah penolakan rate-limit/spam dari WhatsApp.
+func isThrottled(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, ErrThrottled) || errors.Is(err, whatsmeow.ErrIQRateOverLimit) {
+		return true
+	}
+	var iqe *whatsmeow.IQError
+	if errors.As(err, &iqe) && throttleCodes[iqe.Code] {
+		return true
+	}
+	if m := serverErrCodeRe.FindStringSubmatch(err.Error()); m != nil {
+		code, _ := strconv.Atoi(m[1])
+		return throttleCodes[code]
+	}
+	s := strings.ToLower(err.Error())
+	return strings.Contains(s, "rate-overlimit") || strings.Contains(s, "spam")
+}
+
+// throttle menghentikan akun pengirim (dari metadata kirim di ctx) selama throttleCooldown dan
+// mencatat event akun throttled (diteruskan ke webhook account.throttled sebagai alert).
+// Mengembalikan cause yang dibungkus ErrThrottled.
+func (s *Sender) throttle(ctx context.Context, cause error) error {
+	if errors.Is(cause, ErrThrottled) {
+		return cause
+	}
+	err := fmt.Errorf("%w: %v", ErrThrottled, cause)
+	meta := sendMetaFrom(ctx)
+	if meta == nil || meta.accountID == "" {
+		return err
+	}
+	meta.throttled = true
+	until := time.Now().Add(throttleCooldown)
+	if serr := s.Store.ThrottleAccount(meta.accountID, until, cause.Error()); serr != nil {
+		log.Printf("[sender] throttle account=%s store err=%v", meta.accountID, serr)
+	}
+	msg := fmt.Sprintf("%v; sending stopped until %s", cause, until.Format(time.RFC3339))
+	log.Printf("[sender] THROTTLED account=%s %s", meta.accountID, msg)
+	if s.Manager != nil {
+		s.Manager.EmitAccountEvent(meta.accountID, StatusThrottled, msg)
+	}
+	return err
+}
+
+// checkThrottled menolak kiriman dari akun yang masih dalam cooldown throttle.
+func (s *Sender) checkThrottled(accountID string) error {
+	until, err := s.Store.AccountThrottledUntil(accountID)
+	if err != nil {
+		return fmt.Errorf("check throttle: %w", err)
+	}
+	if until != nil {
+		return fmt.Errorf("%w: %s until %s", ErrThrottled, accountID, until.Format(time.RFC3339))
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package sender
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.mau.fi/whatsmeow"
+	"go.mau.fi/whatsmeow/binary/proto"
+	"go.mau.fi/whatsmeow/types"
+)
+
+// Transport lapisan WhatsApp yang dipakai Sender untuk upload media dan kirim pesan. Produksi
+// memakai client whatsmeow dari Manager; test memakai transport palsu (lihat sendertest).
+type Transport interface {
+	// Paired true jika sesi akun sudah tertaut (punya device ID).
+	Paired() bool
+	IsConnected() bool
+	Connect() error
+	// OwnJID JID akun sendiri tanpa device (kosong jika belum paired).
+	OwnJID() types.JID
+	Upload(ctx context.Context, data []byte, mediaType whatsmeow.MediaType) (whatsmeow.UploadResponse, error)
+	// UploadNewsletter mengunggah media tanpa enkripsi untuk posting channel.
+	UploadNewsletter(ctx context.Context, data []byte, mediaType whatsmeow.MediaType) (whatsmeow.UploadResponse, error)
+	SendMessage(ctx context.Context, to types.JID, msg *proto.Message, extra ...whatsmeow.SendRequestExtra) (whatsmeow.SendResponse, error)
+	BuildEdit(chat types.JID, id types.MessageID, newContent *proto.Message) *proto.Message
+	BuildRevoke(chat, sender types.JID, id types.MessageID) *proto.Message
+}
+
+// waTransport membungkus *whatsmeow.Client sebagai Transport.
+type waTransport struct {
+	*whatsmeow.Client
+}
+
+func (c waTransport) Paired() bool {
+	return c.Store != nil && c.Store.ID != nil
+}
+
+func (c waTransport) OwnJID() types.JID {
+	if !c.Paired() {
+		return types.EmptyJID
+	}
+	return c.Store.ID.ToNonAD()
+}
+
+// transport mengembalikan Transport akun: Transports jika diisi, selain itu client Manager.
+func (s *Sender) transport(accountID string) (Transport, error) {
+	if s.Transports != nil {
+		return s.Transports(accountID)
+	}
+	c, err := s.Manager.GetClient(accountID)
+	if err != nil {
+		return nil, err
+	}
+	return waTransport{c}, nil
+}
+
+// EnsureConnected memastikan akun sudah paired lalu menyambungkan transport-nya; error
+// "already connected" dianggap sukses.
+func (s *Sender) EnsureConnected(accountID string) error {
+	cli, err := s.transport(accountID)
+	if err != nil {
+		return err
+	}
+	if !cli.Paired() {
+		return fmt.Errorf("account %s not paired/connected", accountID)
+	}
+	if err := cli.Connect(); err != nil {
+		ls := strings.ToLower(err.Error())
+		if !(strings.Contains(ls, "already") || strings.Contains(ls, "connected")) {
+			return fmt.Errorf("connect: %w", err)
+		}
+	}
+	return nil
+}
+
+// sleep menunggu d atau sampai ctx selesai; memakai Sleep jika diisi (test tanpa jeda nyata).
+func (s *Sender) sleep(ctx context.Context, d time.Duration) error {
+	if s.Sleep != nil {
+		return s.Sleep(ctx, d)
+	}
+	select {
+	case <-time.After(d):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
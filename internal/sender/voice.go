@@ -0,0 +1,126 @@
+package sender
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"go.mau.fi/whatsmeow"
+	"go.mau.fi/whatsmeow/binary/proto"
+	"go.mau.fi/whatsmeow/types"
+
+	"promote/internal/voicenote"
+)
+
+// voiceCacheMax jumlah voice note siap kirim yang disimpan di memori.
+const voiceCacheMax = 64
+
+// preparedVoice voice note OGG/Opus siap kirim beserta durasi dan waveform-nya.
+type preparedVoice struct {
+	data    []byte
+	seconds uint32
+	wave    []byte
+	at      time.Time
+}
+
+// voiceCache hasil konversi/analisis voice note per isi file sumber (sha256) agar ffmpeg hanya
+// dijalankan sekali per file, bukan pada setiap kiriman; zero value siap dipakai.
+type voiceCache struct {
+	mu      sync.Mutex
+	entries map[string]preparedVoice
+}
+
+func (c *voiceCache) get(key string) (preparedVoice, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	v, ok := c.entries[key]
+	return v, ok
+}
+
+func (c *voiceCache) put(key string, v preparedVoice) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.entries == nil {
+		c.entries = map[string]preparedVoice{}
+	}
+	if len(c.entries) >= voiceCacheMax {
+		// buang entri tertua; cache kecil jadi scan linear cukup
+		oldest := ""
+		for k, e := range c.entries {
+			if oldest == "" || e.at.Before(c.entries[oldest].at) {
+				oldest = k
+			}
+		}
+		delete(c.entries, oldest)
+	}
+	v.at = time.Now()
+	c.entries[key] = v
+}
+
+// prepareVoiceNote mengonversi audio ke OGG/Opus (jika belum) dan menghitung durasi & waveform
+// sekali per isi file; kiriman berikutnya memakai hasil dari cache.
+func (s *Sender) prepareVoiceNote(ctx context.Context, url string, src []byte) (preparedVoice, error) {
+	sum := sha256.Sum256(src)
+	key := hex.EncodeToString(sum[:])
+	if v, ok := s.voices.get(key); ok {
+		return v, nil
+	}
+	v := preparedVoice{data: src}
+	if !voicenote.IsOggOpus(src) {
+		data, err := voicenote.Convert(ctx, src)
+		if err != nil {
+			return v, fmt.Errorf("convert voice note: %w", err)
+		}
+		v.data = data
+	}
+	seconds, wave, err := voicenote.Analyze(ctx, v.data)
+	if err != nil {
+		// Tanpa waveform voice note tetap bisa diputar; cukup dicatat.
+		log.Printf("[sender] voice note analyze url=%s err=%v", url, err)
+	}
+	v.seconds, v.wave = seconds, wave
+	s.voices.put(key, v)
+	return v, nil
+}
+
+// sendVoiceNoteByURL mengirim audio sebagai voice note (PTT). File non-OGG/Opus dikonversi
+// dulu lewat ffmpeg; durasi & waveform diisi bila analisis berhasil. Hasilnya di-cache per isi
+// file (lihat prepareVoiceNote).
+func (s *Sender) sendVoiceNoteByURL(ctx context.Context, c Transport, jid types.JID, url string) error {
+	src, _, err := s.fetch(ctx, url)
+	if err != nil {
+		return err
+	}
+	v, err := s.prepareVoiceNote(ctx, url, src)
+	if err != nil {
+		return err
+	}
+	data, seconds, wave := v.data, v.seconds, v.wave
+	up, err := c.Upload(ctx, data, whatsmeow.MediaAudio)
+	if err != nil {
+		return fmt.Errorf("upload voice note: %w", err)
+	}
+	length := uint64(len(data))
+	ptt := true
+	am := &proto.AudioMessage{
+		Mimetype:      optstr(voicenote.Mimetype),
+		URL:           optstr(up.URL),
+		DirectPath:    optstr(up.DirectPath),
+		MediaKey:      up.MediaKey,
+		FileEncSHA256: up.FileEncSHA256,
+		FileSHA256:    up.FileSHA256,
+		FileLength:    &length,
+		PTT:           &ptt,
+	}
+	if seconds > 0 {
+		am.Seconds = &seconds
+	}
+	if len(wave) > 0 {
+		am.Waveform = wave
+	}
+	return s.sendMessage(ctx, c, jid, &proto.Message{AudioMessage: am})
+}
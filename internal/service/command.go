@@ -0,0 +1,140 @@
+package service
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+
+	"promote/internal/paths"
+)
+
+const usage = `usage:
+  promote service install   [-name promote] [-user USER] [-env-file PATH] [-overwrite-env] [-dry-run]
+  promote service uninstall [-name promote] [-purge-env] [-env-file PATH]
+
+install membuat env file dari environment saat ini (DATA_DIR dst. dijadikan absolut), memasang
+unit systemd (Linux) atau Windows service dengan restart otomatis, lalu menjalankannya.
+Jalankan dari direktori deploy (DATA_DIR default = direktori saat ini).`
+
+// Command menjalankan "promote service <install|uninstall>" dan mengembalikan exit code.
+func Command(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprintln(stderr, usage)
+		return 2
+	}
+	var err error
+	switch args[0] {
+	case "install":
+		err = installCmd(args[1:], stdout, stderr)
+	case "uninstall":
+		err = uninstallCmd(args[1:], stdout, stderr)
+	case "-h", "-help", "--help", "help":
+		fmt.Fprintln(stdout, usage)
+		return 0
+	default:
+		fmt.Fprintf(stderr, "unknown service command %q\n%s\n", args[0], usage)
+		return 2
+	}
+	if err == flag.ErrHelp {
+		return 0
+	}
+	if err != nil {
+		fmt.Fprintln(stderr, "service:", err)
+		return 1
+	}
+	return 0
+}
+
+func installCmd(args []string, stdout, stderr io.Writer) error {
+	dirs, err := paths.FromEnv()
+	if err != nil {
+		return err
+	}
+	cfg, err := DefaultConfig(dirs)
+	if err != nil {
+		return err
+	}
+	fs := flag.NewFlagSet("service install", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.StringVar(&cfg.Name, "name", cfg.Name, "nama service/unit")
+	fs.StringVar(&cfg.User, "user", "", "user yang menjalankan service (systemd)")
+	fs.StringVar(&cfg.EnvFile, "env-file", cfg.EnvFile, "lokasi env file")
+	overwrite := fs.Bool("overwrite-env", false, "timpa env file yang sudah ada")
+	dryRun := fs.Bool("dry-run", false, "tampilkan unit/env tanpa memasang")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	env := EnvFromConfig(os.Environ(), dirs)
+
+	if *dryRun {
+		fmt.Fprintf(stdout, "# %s\n%s\n", cfg.EnvFile, formatEnv(env))
+		fmt.Fprintln(stdout, Describe(cfg))
+		return nil
+	}
+	if err := dirs.Ensure(); err != nil {
+		return err
+	}
+	if _, err := os.Stat(cfg.EnvFile); err == nil && !*overwrite {
+		fmt.Fprintf(stdout, "env file %s sudah ada, dipakai apa adanya (-overwrite-env untuk menimpa)\n", cfg.EnvFile)
+	} else {
+		if err := WriteEnvFile(cfg.EnvFile, env); err != nil {
+			return fmt.Errorf("write env file: %w", err)
+		}
+		fmt.Fprintf(stdout, "env file ditulis: %s\n", cfg.EnvFile)
+	}
+	if err := install(cfg, dirs); err != nil {
+		return err
+	}
+	fmt.Fprintf(stdout, "service %s terpasang dan dijalankan (binary %s, workdir %s)\n", cfg.Name, cfg.Exec, cfg.WorkDir)
+	return nil
+}
+
+func uninstallCmd(args []string, stdout, stderr io.Writer) error {
+	fs := flag.NewFlagSet("service uninstall", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	name := fs.String("name", DefaultName, "nama service/unit")
+	purge := fs.Bool("purge-env", false, "hapus juga env file")
+	envFile := fs.String("env-file", "", "lokasi env file untuk -purge-env (default <DATA_DIR>/promote.env)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := uninstall(*name); err != nil {
+		return err
+	}
+	fmt.Fprintf(stdout, "service %s dihapus\n", *name)
+	if !*purge {
+		return nil
+	}
+	if *envFile == "" {
+		dirs, err := paths.FromEnv()
+		if err != nil {
+			return err
+		}
+		cfg, err := DefaultConfig(dirs)
+		if err != nil {
+			return err
+		}
+		*envFile = cfg.EnvFile
+	}
+	if err := os.Remove(*envFile); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	fmt.Fprintf(stdout, "env file %s dihapus\n", *envFile)
+	return nil
+}
+
+func formatEnv(env map[string]string) string {
+	keys := make([]string, 0, len(env))
+	for k := range env {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	out := ""
+	for _, k := range keys {
+		out += k + "=" + strconv.Quote(env[k]) + "\n"
+	}
+	return out
+}
@@ -0,0 +1,135 @@
+// Package service memasang promote sebagai service OS (unit systemd di Linux, Windows service)
+// dengan working directory, env file dan restart policy yang dihasilkan dari konfigurasi saat
+// install, sehingga deploy di VPS klien bisa diulang tanpa menulis unit manual.
+package service
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"promote/internal/paths"
+)
+
+// DefaultName nama service/unit bawaan.
+const DefaultName = "promote"
+
+// Config parameter service hasil install.
+type Config struct {
+	// Name nama unit systemd / Windows service.
+	Name        string
+	Description string
+	// Exec path absolut binary promote.
+	Exec string
+	// WorkDir working directory service (DATA_DIR).
+	WorkDir string
+	// EnvFile file KEY=VALUE yang dimuat service (default <DataDir>/promote.env).
+	EnvFile string
+	// User akun yang menjalankan service (systemd User=; kosong = root).
+	User string
+	// RestartDelay jeda sebelum service di-restart setelah crash.
+	RestartDelay time.Duration
+}
+
+// DefaultConfig mengisi Config dari binary yang sedang berjalan dan direktori kerja (paths.FromEnv).
+func DefaultConfig(dirs paths.Paths) (Config, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return Config{}, fmt.Errorf("locate binary: %w", err)
+	}
+	if resolved, err := filepath.EvalSymlinks(exe); err == nil {
+		exe = resolved
+	}
+	return Config{
+		Name:         DefaultName,
+		Description:  "Promote WhatsApp group promotion service",
+		Exec:         exe,
+		WorkDir:      dirs.DataDir,
+		EnvFile:      filepath.Join(dirs.DataDir, "promote.env"),
+		RestartDelay: 5 * time.Second,
+	}, nil
+}
+
+// envPrefixes env yang dibaca aplikasi; hanya ini yang disalin ke env file (bukan PATH, HOME, dst).
+var envPrefixes = []string{
+	"ADMIN_API_KEY", "COMPLIANCE_", "DATA_DIR", "DAY_RESET_", "DB_DSN", "DIGEST_", "DM_",
+	"LOGSHIP_", "LOG_RETENTION_", "MEDIA_", "MENTION_ALL_", "PORT", "SCHEDULER_", "SCRAPE_",
+	"SEND_", "SESSION_", "SHORTLINK_", "UPLOAD_", "WEBHOOK_",
+}
+
+func appEnvKey(key string) bool {
+	for _, p := range envPrefixes {
+		if strings.HasPrefix(key, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// EnvFromConfig menyusun isi env file dari environment saat ini (hanya variabel aplikasi),
+// dengan DATA_DIR/SESSION_DIR/UPLOAD_DIR diganti path absolut agar tidak bergantung CWD.
+func EnvFromConfig(environ []string, dirs paths.Paths) map[string]string {
+	env := map[string]string{}
+	for _, kv := range environ {
+		k, v, ok := strings.Cut(kv, "=")
+		if ok && appEnvKey(k) {
+			env[k] = v
+		}
+	}
+	env["DATA_DIR"] = dirs.DataDir
+	env["SESSION_DIR"] = dirs.SessionDir
+	env["UPLOAD_DIR"] = dirs.UploadDir
+	return env
+}
+
+// WriteEnvFile menulis env sebagai KEY="VALUE" (format yang dibaca systemd EnvironmentFile dan
+// LoadEnvFile). File hanya bisa dibaca pemilik karena bisa berisi token.
+func WriteEnvFile(path string, env map[string]string) error {
+	content := "# Dibuat oleh `promote service install`; edit lalu restart service.\n" + formatEnv(env)
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	return os.WriteFile(path, []byte(content), 0o600)
+}
+
+// LoadEnvFile memuat KEY=VALUE dari file ke environment proses. Variabel yang sudah di-set
+// tidak ditimpa. Baris kosong dan komentar (#) diabaikan; nilai boleh diberi kutip.
+func LoadEnvFile(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	sc := bufio.NewScanner(f)
+	for n := 1; sc.Scan(); n++ {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		k, v, ok := strings.Cut(strings.TrimPrefix(line, "export "), "=")
+		k = strings.TrimSpace(k)
+		if !ok || k == "" {
+			return fmt.Errorf("%s:%d: expected KEY=VALUE", path, n)
+		}
+		v = strings.TrimSpace(v)
+		if len(v) >= 2 && (v[0] == '"' || v[0] == '\'') && v[len(v)-1] == v[0] {
+			if v[0] == '"' {
+				if uq, err := strconv.Unquote(v); err == nil {
+					v = uq
+				} else {
+					v = v[1 : len(v)-1]
+				}
+			} else {
+				v = v[1 : len(v)-1]
+			}
+		}
+		if _, set := os.LookupEnv(k); !set {
+			os.Setenv(k, v)
+		}
+	}
+	return sc.Err()
+}
@@ -0,0 +1,114 @@
+package service
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"promote/internal/paths"
+)
+
+const unitDir = "/etc/systemd/system"
+
+// SystemdUnit menyusun unit systemd untuk cfg.
+func SystemdUnit(cfg Config) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "[Unit]\nDescription=%s\nAfter=network-online.target\nWants=network-online.target\n\n", cfg.Description)
+	b.WriteString("[Service]\nType=simple\n")
+	if cfg.User != "" {
+		fmt.Fprintf(&b, "User=%s\n", cfg.User)
+	}
+	fmt.Fprintf(&b, "WorkingDirectory=%s\n", systemdQuote(cfg.WorkDir))
+	fmt.Fprintf(&b, "EnvironmentFile=%s\n", systemdQuote(cfg.EnvFile))
+	fmt.Fprintf(&b, "ExecStart=%s\n", systemdQuote(cfg.Exec))
+	b.WriteString("Restart=always\n")
+	fmt.Fprintf(&b, "RestartSec=%d\n", int(cfg.RestartDelay.Seconds()))
+	b.WriteString("LimitNOFILE=65536\n\n")
+	b.WriteString("[Install]\nWantedBy=multi-user.target\n")
+	return b.String()
+}
+
+// systemdQuote mengutip path yang mengandung spasi.
+func systemdQuote(s string) string {
+	if strings.ContainsAny(s, " \t") {
+		return strconv.Quote(s)
+	}
+	return s
+}
+
+// Describe unit yang akan dipasang (untuk -dry-run).
+func Describe(cfg Config) string {
+	return "# " + filepath.Join(unitDir, cfg.Name+".service") + "\n" + SystemdUnit(cfg)
+}
+
+func install(cfg Config, dirs paths.Paths) error {
+	if os.Geteuid() != 0 {
+		return fmt.Errorf("install systemd unit requires root (sudo)")
+	}
+	if cfg.User != "" {
+		if err := chownTo(cfg.User, dirs.DataDir, dirs.SessionDir, dirs.UploadDir, cfg.EnvFile); err != nil {
+			return err
+		}
+	}
+	unit := filepath.Join(unitDir, cfg.Name+".service")
+	if err := os.WriteFile(unit, []byte(SystemdUnit(cfg)), 0o644); err != nil {
+		return fmt.Errorf("write unit: %w", err)
+	}
+	if err := systemctl("daemon-reload"); err != nil {
+		return err
+	}
+	return systemctl("enable", "--now", cfg.Name+".service")
+}
+
+func uninstall(name string) error {
+	if os.Geteuid() != 0 {
+		return fmt.Errorf("uninstall systemd unit requires root (sudo)")
+	}
+	unit := filepath.Join(unitDir, name+".service")
+	if _, err := os.Stat(unit); os.IsNotExist(err) {
+		return fmt.Errorf("unit %s not found", unit)
+	}
+	// Unit yang sudah berhenti/disabled bukan error.
+	_ = systemctl("disable", "--now", name+".service")
+	if err := os.Remove(unit); err != nil {
+		return err
+	}
+	return systemctl("daemon-reload")
+}
+
+func systemctl(args ...string) error {
+	out, err := exec.Command("systemctl", args...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("systemctl %s: %v: %s", strings.Join(args, " "), err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// chownTo menyerahkan direktori kerja (rekursif) dan env file ke user service.
+func chownTo(username string, targets ...string) error {
+	u, err := user.Lookup(username)
+	if err != nil {
+		return fmt.Errorf("lookup user %s: %w", username, err)
+	}
+	uid, _ := strconv.Atoi(u.Uid)
+	gid, _ := strconv.Atoi(u.Gid)
+	for _, t := range targets {
+		err := filepath.Walk(t, func(p string, _ os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			return os.Lchown(p, uid, gid)
+		})
+		if err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("chown %s: %w", t, err)
+		}
+	}
+	return nil
+}
+
+// RunAsService di Linux selalu false; systemd menjalankan binary seperti proses biasa.
+func RunAsService(run func()) bool { return false }
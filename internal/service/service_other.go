@@ -0,0 +1,26 @@
+//go:build !linux && !windows
+
+package service
+
+import (
+	"fmt"
+	"runtime"
+
+	"promote/internal/paths"
+)
+
+// Describe untuk OS tanpa installer service.
+func Describe(cfg Config) string {
+	return fmt.Sprintf("# service install tidak didukung di %s; jalankan %s dengan env file %s", runtime.GOOS, cfg.Exec, cfg.EnvFile)
+}
+
+func install(cfg Config, dirs paths.Paths) error {
+	return fmt.Errorf("service install not supported on %s", runtime.GOOS)
+}
+
+func uninstall(name string) error {
+	return fmt.Errorf("service uninstall not supported on %s", runtime.GOOS)
+}
+
+// RunAsService selalu false di OS tanpa service manager yang didukung.
+func RunAsService(run func()) bool { return false }
@@ -0,0 +1,112 @@
+package service
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"golang.org/x/sys/windows/svc"
+	"golang.org/x/sys/windows/svc/mgr"
+
+	"promote/internal/paths"
+)
+
+// Describe perintah service yang akan dipasang (untuk -dry-run).
+func Describe(cfg Config) string {
+	return fmt.Sprintf("# Windows service %q\n# binPath: %q --env-file %q\n# start: auto, recovery: restart setelah %s",
+		cfg.Name, cfg.Exec, cfg.EnvFile, cfg.RestartDelay)
+}
+
+func install(cfg Config, dirs paths.Paths) error {
+	m, err := mgr.Connect()
+	if err != nil {
+		return fmt.Errorf("connect service manager (run as Administrator): %w", err)
+	}
+	defer m.Disconnect()
+	if s, err := m.OpenService(cfg.Name); err == nil {
+		s.Close()
+		return fmt.Errorf("service %s already exists; uninstall first", cfg.Name)
+	}
+	c := mgr.Config{
+		DisplayName: cfg.Description,
+		Description: cfg.Description,
+		StartType:   mgr.StartAutomatic,
+	}
+	if cfg.User != "" {
+		c.ServiceStartName = cfg.User
+	}
+	// Windows service tidak punya EnvironmentFile/WorkingDirectory; keduanya lewat --env-file
+	// (DATA_DIR di dalamnya sudah absolut).
+	s, err := m.CreateService(cfg.Name, cfg.Exec, c, "--env-file", cfg.EnvFile)
+	if err != nil {
+		return fmt.Errorf("create service: %w", err)
+	}
+	defer s.Close()
+	// Restart otomatis setelah crash; hitungan kegagalan di-reset setiap hari.
+	actions := []mgr.RecoveryAction{
+		{Type: mgr.ServiceRestart, Delay: cfg.RestartDelay},
+		{Type: mgr.ServiceRestart, Delay: cfg.RestartDelay},
+		{Type: mgr.ServiceRestart, Delay: time.Minute},
+	}
+	if err := s.SetRecoveryActions(actions, uint32((24 * time.Hour).Seconds())); err != nil {
+		return fmt.Errorf("set recovery actions: %w", err)
+	}
+	return s.Start()
+}
+
+func uninstall(name string) error {
+	m, err := mgr.Connect()
+	if err != nil {
+		return fmt.Errorf("connect service manager (run as Administrator): %w", err)
+	}
+	defer m.Disconnect()
+	s, err := m.OpenService(name)
+	if err != nil {
+		return fmt.Errorf("service %s not found", name)
+	}
+	defer s.Close()
+	if st, err := s.Control(svc.Stop); err == nil {
+		for i := 0; i < 30 && st.State != svc.Stopped; i++ {
+			time.Sleep(time.Second)
+			if st, err = s.Query(); err != nil {
+				break
+			}
+		}
+	} else if !strings.Contains(err.Error(), "not been started") {
+		log.Printf("service: stop %s: %v", name, err)
+	}
+	return s.Delete()
+}
+
+// RunAsService menjalankan run di bawah Service Control Manager jika proses dijalankan sebagai
+// Windows service; false jika proses dijalankan dari konsol.
+func RunAsService(run func()) bool {
+	isService, err := svc.IsWindowsService()
+	if err != nil || !isService {
+		return false
+	}
+	if err := svc.Run(DefaultName, handler{run: run}); err != nil {
+		log.Printf("service: run: %v", err)
+	}
+	return true
+}
+
+type handler struct{ run func() }
+
+// Execute menjalankan aplikasi di goroutine dan berhenti saat SCM meminta Stop/Shutdown.
+func (h handler) Execute(_ []string, req <-chan svc.ChangeRequest, status chan<- svc.Status) (bool, uint32) {
+	status <- svc.Status{State: svc.StartPending}
+	go h.run()
+	status <- svc.Status{State: svc.Running, Accepts: svc.AcceptStop | svc.AcceptShutdown}
+	for c := range req {
+		switch c.Cmd {
+		case svc.Interrogate:
+			status <- c.CurrentStatus
+		case svc.Stop, svc.Shutdown:
+			status <- svc.Status{State: svc.StopPending}
+			return false, 0
+		}
+	}
+	return false, 0
+}
@@ -0,0 +1,204 @@
+// Package shortlink membuat slug redirect ter-tracking untuk link di promo, dengan base URL
+// kustom (domain bermerek) agar link tidak tampil sebagai IP:port mentah.
+package shortlink
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/url"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"promote/internal/model"
+	"promote/internal/storage"
+)
+
+const slugAlphabet = "abcdefghijkmnpqrstuvwxyzABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+
+// attemptsPerLength: jumlah slug acak yang dicoba sebelum panjang slug ditambah satu.
+const attemptsPerLength = 5
+
+var (
+	customSlugRe = regexp.MustCompile(`^[A-Za-z0-9_-]{3,64}$`)
+	urlRe        = regexp.MustCompile(`https?://[^\s<>"']+`)
+)
+
+// Service membuat dan me-resolve short link.
+type Service struct {
+	Store *storage.Store
+	// BaseURL publik redirect, mis. "https://go.brand.id". Kosong = path relatif "/r/<slug>".
+	BaseURL string
+	// SlugLen panjang awal slug acak.
+	SlugLen int
+	// DefaultTTL masa berlaku link baru (0 = tanpa kedaluwarsa).
+	DefaultTTL time.Duration
+	// Rewrite: sender mengganti URL di teks/caption promo dengan short link.
+	Rewrite bool
+}
+
+// FromEnv membaca SHORTLINK_BASE_URL, SHORTLINK_SLUG_LEN, SHORTLINK_TTL_DAYS dan SHORTLINK_REWRITE.
+func FromEnv(store *storage.Store) *Service {
+	s := &Service{
+		Store:   store,
+		BaseURL: strings.TrimRight(strings.TrimSpace(os.Getenv("SHORTLINK_BASE_URL")), "/"),
+		SlugLen: 6,
+		Rewrite: os.Getenv("SHORTLINK_REWRITE") == "1" || os.Getenv("SHORTLINK_REWRITE") == "true",
+	}
+	if n, err := strconv.Atoi(os.Getenv("SHORTLINK_SLUG_LEN")); err == nil && n >= 4 && n <= 32 {
+		s.SlugLen = n
+	}
+	if d, err := strconv.Atoi(os.Getenv("SHORTLINK_TTL_DAYS")); err == nil && d > 0 {
+		s.DefaultTTL = time.Duration(d) * 24 * time.Hour
+	}
+	return s
+}
+
+// Host mengembalikan host dari BaseURL (kosong jika BaseURL tidak diset).
+func (s *Service) Host() string {
+	if s == nil || s.BaseURL == "" {
+		return ""
+	}
+	u, err := url.Parse(s.BaseURL)
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(u.Host)
+}
+
+// URL menyusun short URL publik untuk slug.
+func (s *Service) URL(slug string) string {
+	if s.BaseURL == "" {
+		return "/r/" + slug
+	}
+	return s.BaseURL + "/" + slug
+}
+
+// CreateOptions parameter opsional pembuatan link.
+type CreateOptions struct {
+	Slug       string // slug kustom; kosong = acak
+	CampaignID string
+	TTL        time.Duration // 0 = DefaultTTL; negatif = tanpa kedaluwarsa
+}
+
+// Create membuat short link baru. Slug kustom yang bentrok menghasilkan storage.ErrSlugTaken;
+// slug acak yang bentrok dicoba ulang dan diperpanjang otomatis.
+func (s *Service) Create(target string, opt CreateOptions) (model.ShortLink, error) {
+	u, err := url.Parse(strings.TrimSpace(target))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return model.ShortLink{}, errors.New("valid http(s) target url required")
+	}
+	now := time.Now()
+	l := model.ShortLink{TargetURL: u.String(), CampaignID: opt.CampaignID, CreatedAt: now}
+	ttl := opt.TTL
+	if ttl == 0 {
+		ttl = s.DefaultTTL
+	}
+	if ttl > 0 {
+		exp := now.Add(ttl)
+		l.ExpiresAt = &exp
+	}
+
+	if opt.Slug != "" {
+		if !customSlugRe.MatchString(opt.Slug) {
+			return model.ShortLink{}, errors.New("slug must be 3-64 chars of letters, digits, - or _")
+		}
+		l.Slug = opt.Slug
+		if err := s.Store.InsertShortLink(l); err != nil {
+			return model.ShortLink{}, err
+		}
+		l.ShortURL = s.URL(l.Slug)
+		return l, nil
+	}
+
+	n := s.SlugLen
+	if n <= 0 {
+		n = 6
+	}
+	for length := n; length < n+4; length++ {
+		for i := 0; i < attemptsPerLength; i++ {
+			l.Slug = randomSlug(length)
+			err := s.Store.InsertShortLink(l)
+			if err == nil {
+				l.ShortURL = s.URL(l.Slug)
+				return l, nil
+			}
+			if !errors.Is(err, storage.ErrSlugTaken) {
+				return model.ShortLink{}, err
+			}
+		}
+	}
+	return model.ShortLink{}, fmt.Errorf("could not allocate a free slug")
+}
+
+// Ensure mengembalikan short link aktif untuk target+campaign, membuat baru jika belum ada.
+func (s *Service) Ensure(target, campaignID string) (model.ShortLink, error) {
+	l, ok, err := s.Store.FindActiveShortLink(target, campaignID, time.Now())
+	if err != nil {
+		return model.ShortLink{}, err
+	}
+	if ok {
+		l.ShortURL = s.URL(l.Slug)
+		return l, nil
+	}
+	return s.Create(target, CreateOptions{CampaignID: campaignID})
+}
+
+// Resolve mencari target untuk slug dan mencatat klik (jika count). ok=false jika tidak ada atau kedaluwarsa.
+func (s *Service) Resolve(slug string, count bool) (string, bool, error) {
+	l, err := s.Store.GetShortLink(slug)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", false, nil
+		}
+		return "", false, err
+	}
+	now := time.Now()
+	if l.Expired(now) {
+		return "", false, nil
+	}
+	if count {
+		if err := s.Store.RecordShortLinkClick(slug, now); err != nil {
+			return "", false, err
+		}
+	}
+	return l.TargetURL, true, nil
+}
+
+// RewriteText mengganti setiap URL http(s) di teks dengan short link (kecuali yang sudah short link).
+// Jika pembuatan link gagal, URL asli dipertahankan.
+func (s *Service) RewriteText(text, campaignID string) string {
+	if s == nil || !s.Rewrite || s.BaseURL == "" {
+		return text
+	}
+	return urlRe.ReplaceAllStringFunc(text, func(raw string) string {
+		link := strings.TrimRight(raw, ".,;:!?)]}")
+		tail := raw[len(link):]
+		if strings.HasPrefix(link, s.BaseURL+"/") {
+			return raw
+		}
+		l, err := s.Ensure(link, campaignID)
+		if err != nil {
+			return raw
+		}
+		return l.ShortURL + tail
+	})
+}
+
+func randomSlug(n int) string {
+	b := make([]byte, n)
+	max := big.NewInt(int64(len(slugAlphabet)))
+	for i := range b {
+		v, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			panic(err)
+		}
+		b[i] = slugAlphabet[v.Int64()]
+	}
+	return string(b)
+}
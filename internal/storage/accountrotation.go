@@ -0,0 +1,77 @@
+package storage
+
+import (
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"promote/internal/model"
+)
+
+// SettingAccountRotation strategi rotasi akun pengirim (JSON model.AccountRotation).
+const SettingAccountRotation = "account_rotation"
+
+// AccountRotation membaca strategi rotasi akun; random (perilaku lama) jika belum diset.
+func (s *Store) AccountRotation() (model.AccountRotation, error) {
+	r := model.AccountRotation{Strategy: model.RotationRandom}
+	v, err := s.GetSetting(SettingAccountRotation)
+	if err != nil || v == "" {
+		return r, err
+	}
+	if err := json.Unmarshal([]byte(v), &r); err != nil || r.Validate() != nil {
+		return model.AccountRotation{Strategy: model.RotationRandom}, err
+	}
+	return r, nil
+}
+
+// SetAccountRotation menyimpan strategi rotasi akun.
+func (s *Store) SetAccountRotation(r model.AccountRotation) error {
+	raw, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	return s.SetSetting(SettingAccountRotation, string(raw))
+}
+
+// LastSentByAccount waktu kiriman sukses terakhir tiap akun (ke grup mana pun); akun yang belum
+// pernah mengirim tidak ada di map.
+func (s *Store) LastSentByAccount() (map[string]time.Time, error) {
+	rows, err := s.DB.Query(`SELECT account_id, MAX(ts) FROM logs WHERE status='sent' AND account_id IS NOT NULL GROUP BY account_id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := map[string]time.Time{}
+	for rows.Next() {
+		var acc string
+		var ts sql.NullString
+		if err := rows.Scan(&acc, &ts); err != nil {
+			return nil, err
+		}
+		if t, ok := parseDBTime(ts); ok {
+			out[acc] = t
+		}
+	}
+	return out, rows.Err()
+}
+
+// SentTodayByAccount jumlah kiriman sukses hari kuota ini per akun.
+func (s *Store) SentTodayByAccount() (map[string]int, error) {
+	from, to := s.TodayArgs()
+	rows, err := s.DB.Query(`SELECT account_id, COUNT(*) FROM logs WHERE status='sent' AND account_id IS NOT NULL
+		AND ts >= ? AND ts < ? GROUP BY account_id`, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := map[string]int{}
+	for rows.Next() {
+		var acc string
+		var n int
+		if err := rows.Scan(&acc, &n); err != nil {
+			return nil, err
+		}
+		out[acc] = n
+	}
+	return out, rows.Err()
+}
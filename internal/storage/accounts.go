@@ -0,0 +1,142 @@
+package storage
+
+import (
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"promote/internal/model"
+)
+
+var (
+	// ErrAccountNotFound dikembalikan jika referensi akun tidak cocok dengan akun mana pun.
+	ErrAccountNotFound = errors.New("account not found")
+	// ErrAccountAmbiguous dikembalikan jika msisdn: cocok dengan lebih dari satu akun.
+	ErrAccountAmbiguous = errors.New("account reference matches more than one account")
+	// ErrAccountLabelTaken dikembalikan jika label sudah dipakai akun lain.
+	ErrAccountLabelTaken = errors.New("account label already in use")
+)
+
+// Prefix referensi akun pada path param selain UUID.
+const (
+	AccountRefLabel  = "label:"
+	AccountRefMsisdn = "msisdn:"
+)
+
+// IsAccountRef true jika ref memakai prefix label: atau msisdn:.
+func IsAccountRef(ref string) bool {
+	return strings.HasPrefix(ref, AccountRefLabel) || strings.HasPrefix(ref, AccountRefMsisdn)
+}
+
+// ResolveAccountRef mengubah referensi "label:<label>" (case-insensitive) atau
+// "msisdn:<nomor>" (hanya digit yang dibandingkan, jadi +62 812-... cocok dengan 62812...)
+// menjadi ID akun. Referensi tanpa prefix dikembalikan apa adanya.
+func (s *Store) ResolveAccountRef(ref string) (string, error) {
+	var query, arg string
+	switch {
+	case strings.HasPrefix(ref, AccountRefLabel):
+		query = `SELECT id FROM accounts WHERE label = ? COLLATE NOCASE`
+		arg = strings.TrimSpace(strings.TrimPrefix(ref, AccountRefLabel))
+	case strings.HasPrefix(ref, AccountRefMsisdn):
+		query = `SELECT id FROM accounts
+			WHERE REPLACE(REPLACE(REPLACE(REPLACE(msisdn,'+',''),' ',''),'-',''),'@s.whatsapp.net','') = ?`
+		arg = digitsOnly(strings.TrimPrefix(ref, AccountRefMsisdn))
+	default:
+		return ref, nil
+	}
+	if arg == "" {
+		return "", ErrAccountNotFound
+	}
+	rows, err := s.DB.Query(query, arg)
+	if err != nil {
+		return "", err
+	}
+	defer rows.Close()
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return "", err
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return "", err
+	}
+	switch len(ids) {
+	case 0:
+		return "", ErrAccountNotFound
+	case 1:
+		return ids[0], nil
+	default:
+		return "", ErrAccountAmbiguous
+	}
+}
+
+func digitsOnly(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if r >= '0' && r <= '9' {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// isUniqueViolation true untuk error constraint UNIQUE dari SQLite.
+func isUniqueViolation(err error) bool {
+	return err != nil && strings.Contains(err.Error(), "UNIQUE constraint failed")
+}
+
+// SearchAccounts mencari akun yang msisdn atau label-nya memuat teks (sebagian); keduanya kosong
+// mengembalikan semua akun. Urut created_at terbaru.
+func (s *Store) SearchAccounts(msisdn, label string) ([]model.Account, error) {
+	switch {
+	case msisdn != "" && label != "":
+		return s.queryAccounts(`msisdn LIKE ? OR label LIKE ?`, "%"+msisdn+"%", "%"+label+"%")
+	case msisdn != "":
+		return s.queryAccounts(`msisdn LIKE ?`, "%"+msisdn+"%")
+	case label != "":
+		return s.queryAccounts(`label LIKE ?`, "%"+label+"%")
+	}
+	return s.ListAccounts()
+}
+
+// AccountIDByMsisdn ID akun dengan msisdn persis; kosong jika tidak ada.
+func (s *Store) AccountIDByMsisdn(msisdn string) (string, error) {
+	var id string
+	err := s.DB.QueryRow(`SELECT id FROM accounts WHERE msisdn=? LIMIT 1`, msisdn).Scan(&id)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	return id, err
+}
+
+// queryAccounts membaca akun yang cocok dengan kondisi where, urut created_at terbaru.
+func (s *Store) queryAccounts(where string, args ...any) ([]model.Account, error) {
+	rows, err := s.DB.Query(`SELECT id,label,msisdn,enabled,daily_limit,dm_daily_limit,status_daily_limit,observer,status,COALESCE(last_error,''),created_at,updated_at,
+		throttled_until,COALESCE(throttle_reason,'') FROM accounts WHERE `+where+` ORDER BY created_at DESC`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var list []model.Account
+	for rows.Next() {
+		var a model.Account
+		var enabledInt, observerInt int
+		var throttled sql.NullTime
+		if err := rows.Scan(&a.ID, &a.Label, &a.Msisdn, &enabledInt, &a.DailyLimit, &a.DMDailyLimit, &a.StatusDailyLimit, &observerInt, &a.Status, &a.LastError, &a.CreatedAt, &a.UpdatedAt,
+			&throttled, &a.ThrottleReason); err != nil {
+			return nil, err
+		}
+		if throttled.Valid && throttled.Time.After(time.Now()) {
+			t := throttled.Time
+			a.ThrottledUntil = &t
+		}
+		a.Enabled = enabledInt == 1
+		a.Observer = observerInt == 1
+		list = append(list, a)
+	}
+	return list, rows.Err()
+}
@@ -0,0 +1,90 @@
+package storage
+
+import (
+	"strings"
+	"time"
+)
+
+// Status ack pesan terkirim.
+const (
+	AckPending    = "pending"
+	AckDelivered  = "delivered"
+	AckSoftBounce = "soft_bounce"
+)
+
+// InsertMessageAck mencatat pesan yang sudah diterima server dan menunggu receipt delivered,
+// beserta round-trip SendMessage dan waktu rencana kirim (zero = tidak dijadwalkan) untuk SLO.
+func (s *Store) InsertMessageAck(messageID, accountID, groupID, sessionID string, sentAt time.Time, rtt time.Duration, plannedAt time.Time) error {
+	var planned any
+	if !plannedAt.IsZero() {
+		planned = plannedAt.UTC()
+	}
+	_, err := s.DB.Exec(`INSERT OR IGNORE INTO message_acks (message_id, account_id, group_id, session_id, status, sent_at, rtt_ms, planned_at)
+		VALUES (?,?,?,?,'pending',?,?,?)`, messageID, accountID, groupID, nullStr(sessionID), sentAt.UTC(), rtt.Milliseconds(), planned)
+	return err
+}
+
+// MarkMessagesDelivered menandai pesan delivered. Receipt yang terlambat juga mengubah soft_bounce
+// kembali menjadi delivered.
+func (s *Store) MarkMessagesDelivered(accountID string, messageIDs []string, at time.Time) (int64, error) {
+	if len(messageIDs) == 0 {
+		return 0, nil
+	}
+	args := []any{at.UTC(), accountID}
+	for _, id := range messageIDs {
+		args = append(args, id)
+	}
+	res, err := s.DB.Exec(`UPDATE message_acks SET status='delivered', delivered_at=?
+		WHERE account_id=? AND status IN ('pending','soft_bounce') AND message_id IN (?`+strings.Repeat(`,?`, len(messageIDs)-1)+`)`, args...)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// MarkSoftBounces menandai pesan pending yang dikirim sebelum cutoff sebagai soft_bounce.
+func (s *Store) MarkSoftBounces(cutoff time.Time) (int64, error) {
+	res, err := s.DB.Exec(`UPDATE message_acks SET status='soft_bounce' WHERE status='pending' AND sent_at < ?`, cutoff.UTC())
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// CountSoftBouncesSince jumlah pesan berstatus soft_bounce yang dikirim sejak waktu tertentu.
+func (s *Store) CountSoftBouncesSince(since time.Time) (int64, error) {
+	var n int64
+	err := s.DB.QueryRow(`SELECT COUNT(*) FROM message_acks WHERE status='soft_bounce' AND sent_at >= ?`, since.UTC()).Scan(&n)
+	return n, err
+}
+
+// AckCounts mengembalikan jumlah pesan per akun per status sejak waktu tertentu.
+func (s *Store) AckCounts(since time.Time) (map[string]map[string]int64, error) {
+	rows, err := s.DB.Query(`SELECT account_id, status, COUNT(*) FROM message_acks WHERE sent_at >= ? GROUP BY account_id, status`, since.UTC())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := map[string]map[string]int64{}
+	for rows.Next() {
+		var acc, st string
+		var n int64
+		if err := rows.Scan(&acc, &st, &n); err != nil {
+			return nil, err
+		}
+		if out[acc] == nil {
+			out[acc] = map[string]int64{}
+		}
+		out[acc][st] = n
+	}
+	return out, rows.Err()
+}
+
+// PruneMessageAcks menghapus catatan ack lebih lama dari cutoff.
+func (s *Store) PruneMessageAcks(cutoff time.Time) (int64, error) {
+	res, err := s.DB.Exec(`DELETE FROM message_acks WHERE sent_at < ?`, cutoff.UTC())
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
@@ -0,0 +1,142 @@
+package storage
+
+import (
+	"database/sql"
+	"errors"
+	"sort"
+	"time"
+
+	"promote/internal/model"
+)
+
+// ErrAnnotationNotFound dikembalikan jika anotasi tidak ada.
+var ErrAnnotationNotFound = errors.New("annotation not found")
+
+const annotationCols = `id, at, severity, text, COALESCE(account_id,''), COALESCE(group_id,''), COALESCE(author,''), created_at`
+
+func scanAnnotation(sc interface{ Scan(...any) error }) (model.Annotation, error) {
+	var a model.Annotation
+	err := sc.Scan(&a.ID, &a.At, &a.Severity, &a.Text, &a.AccountID, &a.GroupID, &a.Author, &a.CreatedAt)
+	return a, err
+}
+
+func (s *Store) queryAnnotations(query string, args ...any) ([]model.Annotation, error) {
+	rows, err := s.DB.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var list []model.Annotation
+	for rows.Next() {
+		a, err := scanAnnotation(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, a)
+	}
+	return list, rows.Err()
+}
+
+// CreateAnnotation menyimpan anotasi operator; At nol = sekarang.
+func (s *Store) CreateAnnotation(a model.Annotation) (int64, error) {
+	if a.At.IsZero() {
+		a.At = time.Now()
+	}
+	if a.Severity == "" {
+		a.Severity = model.AnnotationInfo
+	}
+	res, err := s.DB.Exec(`INSERT INTO annotations (at, severity, text, account_id, group_id, author) VALUES (?,?,?,?,?,?)`,
+		a.At.UTC().Format(ctsLayout), a.Severity, a.Text, nullStr(a.AccountID), nullStr(a.GroupID), nullStr(a.Author))
+	if err != nil {
+		return 0, err
+	}
+	return res.LastInsertId()
+}
+
+// GetAnnotation membaca satu anotasi; ErrAnnotationNotFound jika tidak ada.
+func (s *Store) GetAnnotation(id int64) (model.Annotation, error) {
+	a, err := scanAnnotation(s.DB.QueryRow(`SELECT `+annotationCols+` FROM annotations WHERE id=?`, id))
+	if err == sql.ErrNoRows {
+		return a, ErrAnnotationNotFound
+	}
+	return a, err
+}
+
+// DeleteAnnotation menghapus anotasi; ErrAnnotationNotFound jika tidak ada.
+func (s *Store) DeleteAnnotation(id int64) error {
+	res, err := s.DB.Exec(`DELETE FROM annotations WHERE id=?`, id)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrAnnotationNotFound
+	}
+	return nil
+}
+
+// ListAnnotations anotasi yang cocok filter, terbaru (menurut At) dulu.
+func (s *Store) ListAnnotations(f model.TimelineFilter) ([]model.Annotation, error) {
+	q := `SELECT ` + annotationCols + ` FROM annotations WHERE 1=1`
+	var args []any
+	// Catatan umum (tanpa akun dan grup) selalu ikut agar konteks shift tidak hilang.
+	if f.AccountID != "" {
+		q += ` AND (account_id=? OR (account_id IS NULL AND group_id IS NULL))`
+		args = append(args, f.AccountID)
+	}
+	if f.GroupID != "" {
+		q += ` AND (group_id=? OR (account_id IS NULL AND group_id IS NULL))`
+		args = append(args, f.GroupID)
+	}
+	if !f.Before.IsZero() {
+		q += ` AND at < ?`
+		args = append(args, f.Before.UTC().Format(ctsLayout))
+	}
+	q += ` ORDER BY at DESC, id DESC LIMIT ?`
+	args = append(args, f.Limit)
+	return s.queryAnnotations(q, args...)
+}
+
+// AnnotationsAfter anotasi dengan id > afterID, terlama dulu (untuk stream log).
+func (s *Store) AnnotationsAfter(afterID int64, limit int) ([]model.Annotation, error) {
+	return s.queryAnnotations(`SELECT `+annotationCols+` FROM annotations WHERE id > ? ORDER BY id ASC LIMIT ?`, afterID, limit)
+}
+
+// Timeline log kirim dan anotasi operator yang cocok filter, disisipkan urut waktu (terbaru dulu).
+func (s *Store) Timeline(f model.TimelineFilter) ([]model.TimelineEntry, error) {
+	q := `SELECT ` + logCols + ` FROM logs WHERE 1=1`
+	var args []any
+	if f.AccountID != "" {
+		q += ` AND account_id=?`
+		args = append(args, f.AccountID)
+	}
+	if f.GroupID != "" {
+		q += ` AND group_id=?`
+		args = append(args, f.GroupID)
+	}
+	if !f.Before.IsZero() {
+		q += ` AND ts < ?`
+		args = append(args, f.Before.UTC().Format(ctsLayout))
+	}
+	q += ` ORDER BY ts DESC, id DESC LIMIT ?`
+	args = append(args, f.Limit)
+	logs, err := s.queryLogs(q, args...)
+	if err != nil {
+		return nil, err
+	}
+	notes, err := s.ListAnnotations(f)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]model.TimelineEntry, 0, len(logs)+len(notes))
+	for i := range logs {
+		out = append(out, model.TimelineEntry{Kind: model.TimelineLog, At: logs[i].TS, Log: &logs[i]})
+	}
+	for i := range notes {
+		out = append(out, model.TimelineEntry{Kind: model.TimelineAnnotation, At: notes[i].At, Annotation: &notes[i]})
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].At.After(out[j].At) })
+	if len(out) > f.Limit {
+		out = out[:f.Limit]
+	}
+	return out, nil
+}
@@ -0,0 +1,111 @@
+package storage
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+
+	"promote/internal/model"
+)
+
+// ErrAPIKeyNotFound dikembalikan jika API key tidak ada atau sudah dicabut.
+var ErrAPIKeyNotFound = errors.New("api key not found")
+
+// HashAPIKey mengembalikan hash SHA-256 (hex) dari nilai key.
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// randomToken menghasilkan token acak ber-prefix (mis. "pk_", "ps_").
+func randomToken(prefix string) (string, error) {
+	buf := make([]byte, 24)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return prefix + hex.EncodeToString(buf), nil
+}
+
+// CreateAPIKey membuat key acak baru dengan role tertentu; nilai key mentah dikembalikan sekali
+// dan tidak disimpan.
+func (s *Store) CreateAPIKey(name, role string) (model.APIKey, string, error) {
+	key, err := randomToken("pk_")
+	if err != nil {
+		return model.APIKey{}, "", err
+	}
+	k := model.APIKey{
+		ID:        uuid.NewString(),
+		Name:      name,
+		Prefix:    key[:10],
+		Role:      role,
+		CreatedAt: time.Now().UTC(),
+	}
+	_, err = s.DB.Exec(`INSERT INTO api_keys (id, name, prefix, role, key_hash, created_at) VALUES (?,?,?,?,?,?)`,
+		k.ID, k.Name, k.Prefix, k.Role, HashAPIKey(key), k.CreatedAt)
+	if err != nil {
+		return model.APIKey{}, "", err
+	}
+	return k, key, nil
+}
+
+// ListAPIKeys mengembalikan semua key (termasuk yang dicabut), terbaru dulu.
+func (s *Store) ListAPIKeys() ([]model.APIKey, error) {
+	rows, err := s.DB.Query(`SELECT id, name, prefix, role, created_at, last_used_at, revoked_at FROM api_keys ORDER BY created_at DESC`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var list []model.APIKey
+	for rows.Next() {
+		var k model.APIKey
+		var used, revoked sql.NullTime
+		if err := rows.Scan(&k.ID, &k.Name, &k.Prefix, &k.Role, &k.CreatedAt, &used, &revoked); err != nil {
+			return nil, err
+		}
+		k.LastUsedAt = nullTimePtr(used)
+		k.RevokedAt = nullTimePtr(revoked)
+		list = append(list, k)
+	}
+	return list, rows.Err()
+}
+
+// RevokeAPIKey mencabut key; ErrAPIKeyNotFound jika tidak ada atau sudah dicabut.
+func (s *Store) RevokeAPIKey(id string) error {
+	res, err := s.DB.Exec(`UPDATE api_keys SET revoked_at=? WHERE id=? AND revoked_at IS NULL`, time.Now().UTC(), id)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrAPIKeyNotFound
+	}
+	return nil
+}
+
+// CountActiveAPIKeys menghitung key yang belum dicabut.
+func (s *Store) CountActiveAPIKeys() (int, error) {
+	var n int
+	err := s.DB.QueryRow(`SELECT COUNT(*) FROM api_keys WHERE revoked_at IS NULL`).Scan(&n)
+	return n, err
+}
+
+// VerifyAPIKey mencocokkan nilai key dengan key aktif dan mencatat last_used_at.
+func (s *Store) VerifyAPIKey(key string) (model.APIKey, error) {
+	var k model.APIKey
+	err := s.DB.QueryRow(`SELECT id, name, prefix, role, created_at FROM api_keys WHERE key_hash=? AND revoked_at IS NULL`,
+		HashAPIKey(key)).Scan(&k.ID, &k.Name, &k.Prefix, &k.Role, &k.CreatedAt)
+	if err == sql.ErrNoRows {
+		return k, ErrAPIKeyNotFound
+	}
+	if err != nil {
+		return k, err
+	}
+	now := time.Now().UTC()
+	_, _ = s.DB.Exec(`UPDATE api_keys SET last_used_at=? WHERE id=?`, now, k.ID)
+	k.LastUsedAt = &now
+	return k, nil
+}
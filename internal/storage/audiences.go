@@ -0,0 +1,185 @@
+package storage
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+
+	"promote/internal/model"
+)
+
+// ErrAudienceNotFound dikembalikan jika audiens tidak ada.
+var ErrAudienceNotFound = errors.New("audience not found")
+
+const audienceCols = `a.id, a.name, COALESCE(a.description,''),
+	(SELECT COUNT(*) FROM audience_members m WHERE m.audience_id=a.id), COALESCE(a.segment_json,''), a.refreshed_at,
+	a.created_at, a.updated_at`
+
+func scanAudience(sc interface{ Scan(...any) error }) (model.Audience, error) {
+	var a model.Audience
+	var segment string
+	var refreshed sql.NullTime
+	err := sc.Scan(&a.ID, &a.Name, &a.Description, &a.MemberCount, &segment, &refreshed, &a.CreatedAt, &a.UpdatedAt)
+	if segment != "" {
+		a.Segment = &model.SegmentRule{}
+		_ = json.Unmarshal([]byte(segment), a.Segment)
+	}
+	if refreshed.Valid {
+		a.RefreshedAt = &refreshed.Time
+	}
+	return a, err
+}
+
+// ListAudiences mengembalikan semua audiens beserta jumlah anggotanya, urut nama.
+func (s *Store) ListAudiences() ([]model.Audience, error) {
+	rows, err := s.DB.Query(`SELECT ` + audienceCols + ` FROM audiences a ORDER BY a.name`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var list []model.Audience
+	for rows.Next() {
+		a, err := scanAudience(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, a)
+	}
+	return list, rows.Err()
+}
+
+// GetAudience mengambil satu audiens; ErrAudienceNotFound jika tidak ada.
+func (s *Store) GetAudience(id string) (model.Audience, error) {
+	a, err := scanAudience(s.DB.QueryRow(`SELECT `+audienceCols+` FROM audiences a WHERE a.id=?`, id))
+	if err == sql.ErrNoRows {
+		return a, ErrAudienceNotFound
+	}
+	return a, err
+}
+
+// CreateAudience membuat audiens kosong (opsional dengan aturan segmen) dan mengisi ID serta timestamp.
+func (s *Store) CreateAudience(a *model.Audience) error {
+	a.ID = uuid.NewString()
+	a.CreatedAt = time.Now().UTC()
+	a.UpdatedAt = a.CreatedAt
+	_, err := s.DB.Exec(`INSERT INTO audiences (id, name, description, segment_json, created_at, updated_at) VALUES (?,?,?,?,?,?)`,
+		a.ID, a.Name, nullStr(a.Description), segmentArg(a.Segment), a.CreatedAt, a.UpdatedAt)
+	return err
+}
+
+// UpdateAudience mengubah nama/deskripsi audiens; ErrAudienceNotFound jika tidak ada.
+func (s *Store) UpdateAudience(id, name, description string) error {
+	res, err := s.DB.Exec(`UPDATE audiences SET name=?, description=?, updated_at=? WHERE id=?`,
+		name, nullStr(description), time.Now().UTC(), id)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrAudienceNotFound
+	}
+	return nil
+}
+
+// DeleteAudience menghapus audiens beserta anggotanya (target DM yang sudah diantrekan tetap ada).
+func (s *Store) DeleteAudience(id string) error {
+	res, err := s.DB.Exec(`DELETE FROM audiences WHERE id=?`, id)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrAudienceNotFound
+	}
+	return nil
+}
+
+// AddAudienceMembers menambahkan nomor (sudah dinormalisasi) ke audiens; nomor yang sudah ada
+// dilewati. Mengembalikan jumlah nomor yang benar-benar ditambahkan.
+func (s *Store) AddAudienceMembers(audienceID string, members []model.AudienceMember) (int, error) {
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+	now := time.Now().UTC()
+	added := 0
+	for _, m := range members {
+		res, err := tx.Exec(`INSERT OR IGNORE INTO audience_members (audience_id, number, name, source, added_at) VALUES (?,?,?,?,?)`,
+			audienceID, m.Number, nullStr(m.Name), m.Source, now)
+		if err != nil {
+			return 0, err
+		}
+		if k, _ := res.RowsAffected(); k > 0 {
+			added++
+		}
+	}
+	if added > 0 {
+		if _, err := tx.Exec(`UPDATE audiences SET updated_at=? WHERE id=?`, now, audienceID); err != nil {
+			return 0, err
+		}
+	}
+	return added, tx.Commit()
+}
+
+// ListAudienceMembers mengembalikan anggota audiens urut waktu ditambahkan.
+func (s *Store) ListAudienceMembers(audienceID string, limit, offset int) ([]model.AudienceMember, error) {
+	rows, err := s.DB.Query(`SELECT number, COALESCE(name,''), source, added_at FROM audience_members
+		WHERE audience_id=? ORDER BY added_at, number LIMIT ? OFFSET ?`, audienceID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var list []model.AudienceMember
+	for rows.Next() {
+		var m model.AudienceMember
+		if err := rows.Scan(&m.Number, &m.Name, &m.Source, &m.AddedAt); err != nil {
+			return nil, err
+		}
+		list = append(list, m)
+	}
+	return list, rows.Err()
+}
+
+// RemoveAudienceMember menghapus satu nomor dari audiens; false jika nomor bukan anggota.
+func (s *Store) RemoveAudienceMember(audienceID, number string) (bool, error) {
+	res, err := s.DB.Exec(`DELETE FROM audience_members WHERE audience_id=? AND number=?`, audienceID, number)
+	if err != nil {
+		return false, err
+	}
+	n, _ := res.RowsAffected()
+	if n > 0 {
+		_, _ = s.DB.Exec(`UPDATE audiences SET updated_at=? WHERE id=?`, time.Now().UTC(), audienceID)
+	}
+	return n > 0, nil
+}
+
+// AudienceNumbers mengembalikan nomor anggota audiens-audiens yang diberikan;
+// ErrAudienceNotFound jika salah satunya tidak ada.
+func (s *Store) AudienceNumbers(audienceIDs []string) ([]string, error) {
+	var out []string
+	for _, id := range audienceIDs {
+		if _, err := s.GetAudience(id); err != nil {
+			return nil, err
+		}
+		rows, err := s.DB.Query(`SELECT number FROM audience_members WHERE audience_id=? ORDER BY added_at, number`, id)
+		if err != nil {
+			return nil, err
+		}
+		for rows.Next() {
+			var n string
+			if err := rows.Scan(&n); err != nil {
+				rows.Close()
+				return nil, err
+			}
+			out = append(out, n)
+		}
+		err = rows.Err()
+		rows.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,218 @@
+package storage
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"promote/internal/model"
+)
+
+// ErrAutoJoinLogNotFound percobaan auto-join tidak ditemukan.
+var ErrAutoJoinLogNotFound = errors.New("auto-join log not found")
+
+// LoadAutoJoinSettings pengaturan auto-join akun; default bila akun belum pernah diatur.
+func (s *Store) LoadAutoJoinSettings(accountID string) (model.AutoJoinSettings, error) {
+	st := model.DefaultAutoJoinSettings(accountID)
+	var whitelist, blacklist, windows string
+	err := s.DB.QueryRow(`SELECT enabled, daily_limit, preview_before_join, require_approval,
+		COALESCE(whitelist_contacts,''), COALESCE(blacklist_keywords,''), min_participants, max_participants,
+		auto_enable_after_join, auto_enable_settle_min, COALESCE(intro_template_id,''), intro_delay_min, intro_jitter_min,
+		COALESCE(join_windows,''), join_jitter_min_sec, join_jitter_max_sec
+		FROM auto_join_settings WHERE account_id=?`, accountID).
+		Scan(&st.Enabled, &st.DailyLimit, &st.PreviewBeforeJoin, &st.RequireApproval, &whitelist, &blacklist,
+			&st.MinParticipants, &st.MaxParticipants, &st.AutoEnableAfterJoin, &st.AutoEnableSettleMin,
+			&st.IntroTemplateID, &st.IntroDelayMin, &st.IntroJitterMin,
+			&windows, &st.JoinJitterMinSec, &st.JoinJitterMaxSec)
+	if err == sql.ErrNoRows {
+		return st, nil
+	}
+	if err != nil {
+		return st, err
+	}
+	st.WhitelistContacts = jsonList(whitelist)
+	st.BlacklistKeywords = jsonList(blacklist)
+	if windows != "" {
+		var w model.WeekWindows
+		if err := json.Unmarshal([]byte(windows), &w); err != nil {
+			return st, err
+		}
+		st.JoinWindows = &w
+	}
+	return st, nil
+}
+
+// SaveAutoJoinSettings menyimpan (upsert) seluruh pengaturan auto-join akun.
+func (s *Store) SaveAutoJoinSettings(st model.AutoJoinSettings) error {
+	var windows any
+	if st.JoinWindows != nil {
+		b, err := json.Marshal(st.JoinWindows)
+		if err != nil {
+			return err
+		}
+		windows = string(b)
+	}
+	_, err := s.DB.Exec(`INSERT INTO auto_join_settings
+		(account_id, enabled, daily_limit, preview_before_join, require_approval, whitelist_contacts, blacklist_keywords,
+			min_participants, max_participants, auto_enable_after_join, auto_enable_settle_min,
+			intro_template_id, intro_delay_min, intro_jitter_min, join_windows, join_jitter_min_sec, join_jitter_max_sec)
+		VALUES (?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?)
+		ON CONFLICT(account_id) DO UPDATE SET
+			enabled=excluded.enabled,
+			daily_limit=excluded.daily_limit,
+			preview_before_join=excluded.preview_before_join,
+			require_approval=excluded.require_approval,
+			whitelist_contacts=excluded.whitelist_contacts,
+			blacklist_keywords=excluded.blacklist_keywords,
+			min_participants=excluded.min_participants,
+			max_participants=excluded.max_participants,
+			auto_enable_after_join=excluded.auto_enable_after_join,
+			auto_enable_settle_min=excluded.auto_enable_settle_min,
+			intro_template_id=excluded.intro_template_id,
+			intro_delay_min=excluded.intro_delay_min,
+			intro_jitter_min=excluded.intro_jitter_min,
+			join_windows=excluded.join_windows,
+			join_jitter_min_sec=excluded.join_jitter_min_sec,
+			join_jitter_max_sec=excluded.join_jitter_max_sec`,
+		st.AccountID, btoi(st.Enabled), st.DailyLimit, btoi(st.PreviewBeforeJoin), btoi(st.RequireApproval),
+		jsonListArg(st.WhitelistContacts), jsonListArg(st.BlacklistKeywords), st.MinParticipants, st.MaxParticipants,
+		btoi(st.AutoEnableAfterJoin), st.AutoEnableSettleMin,
+		nullStr(st.IntroTemplateID), st.IntroDelayMin, st.IntroJitterMin,
+		windows, st.JoinJitterMinSec, st.JoinJitterMaxSec)
+	return err
+}
+
+// SetAutoJoinEnabled hanya mengubah flag enabled; akun tanpa baris dibuat dengan nilai default.
+func (s *Store) SetAutoJoinEnabled(accountID string, enabled bool) error {
+	def := model.DefaultAutoJoinSettings(accountID)
+	_, err := s.DB.Exec(`INSERT INTO auto_join_settings (account_id, enabled, daily_limit, preview_before_join)
+		VALUES (?,?,?,?)
+		ON CONFLICT(account_id) DO UPDATE SET enabled=excluded.enabled`,
+		accountID, btoi(enabled), def.DailyLimit, btoi(def.PreviewBeforeJoin))
+	return err
+}
+
+// LogAutoJoinAttempt mencatat satu percobaan join ke auto_join_logs.
+func (s *Store) LogAutoJoinAttempt(l model.AutoJoinLog) error {
+	_, err := s.DB.Exec(`INSERT INTO auto_join_logs
+		(account_id, group_id, group_name, invite_code, shared_by, shared_in, status, reason, joined_at)
+		VALUES (?,?,?,?,?,?,?,?,CURRENT_TIMESTAMP)`,
+		l.AccountID, nullStr(l.GroupID), nullStr(l.GroupName), l.InviteCode,
+		nullStr(l.SharedBy), nullStr(l.SharedIn), l.Status, nullStr(l.Reason))
+	return err
+}
+
+// GetAutoJoinLog satu percobaan auto-join; ErrAutoJoinLogNotFound jika tidak ada.
+func (s *Store) GetAutoJoinLog(id int64) (model.AutoJoinLog, error) {
+	var l model.AutoJoinLog
+	err := s.DB.QueryRow(`SELECT id, account_id, COALESCE(group_id,''), COALESCE(group_name,''), invite_code,
+		COALESCE(shared_by,''), COALESCE(shared_in,''), status, COALESCE(reason,''), joined_at
+		FROM auto_join_logs WHERE id=?`, id).
+		Scan(&l.ID, &l.AccountID, &l.GroupID, &l.GroupName, &l.InviteCode,
+			&l.SharedBy, &l.SharedIn, &l.Status, &l.Reason, &l.JoinedAt)
+	if err == sql.ErrNoRows {
+		return l, ErrAutoJoinLogNotFound
+	}
+	return l, err
+}
+
+// CountJoinsToday jumlah join sukses akun pada hari berjalan (mengikuti batas hari Store).
+func (s *Store) CountJoinsToday(accountID string) (int, error) {
+	from, to := s.TodayArgs()
+	var n int
+	err := s.DB.QueryRow(`SELECT COUNT(*) FROM auto_join_logs
+		WHERE account_id=? AND status=? AND joined_at >= ? AND joined_at < ?`,
+		accountID, model.AutoJoinJoined, from, to).Scan(&n)
+	return n, err
+}
+
+// HasJoinedInvite true jika akun pernah sukses join lewat kode undangan ini.
+func (s *Store) HasJoinedInvite(accountID, inviteCode string) (bool, error) {
+	var n int
+	err := s.DB.QueryRow(`SELECT COUNT(1) FROM auto_join_logs WHERE account_id=? AND invite_code=? AND status=?`,
+		accountID, inviteCode, model.AutoJoinJoined).Scan(&n)
+	return n > 0, err
+}
+
+// ListAutoJoinLogs riwayat auto-join akun, terbaru dulu; status kosong = semua status.
+func (s *Store) ListAutoJoinLogs(accountID, status string, limit int) ([]model.AutoJoinLog, error) {
+	q := `SELECT id, account_id, COALESCE(group_id,''), COALESCE(group_name,''), invite_code,
+		COALESCE(shared_by,''), COALESCE(shared_in,''), status, COALESCE(reason,''), joined_at
+		FROM auto_join_logs WHERE account_id=?`
+	args := []any{accountID}
+	if status = strings.TrimSpace(status); status != "" {
+		q += ` AND status=?`
+		args = append(args, status)
+	}
+	q += ` ORDER BY joined_at DESC, id DESC LIMIT ?`
+	args = append(args, limit)
+	rows, err := s.DB.Query(q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var list []model.AutoJoinLog
+	for rows.Next() {
+		var l model.AutoJoinLog
+		if err := rows.Scan(&l.ID, &l.AccountID, &l.GroupID, &l.GroupName, &l.InviteCode,
+			&l.SharedBy, &l.SharedIn, &l.Status, &l.Reason, &l.JoinedAt); err != nil {
+			return nil, err
+		}
+		list = append(list, l)
+	}
+	return list, rows.Err()
+}
+
+// AutoJoinStats rekap total joined/failed/skipped akun plus join sukses hari ini.
+func (s *Store) AutoJoinStats(accountID string) (model.AutoJoinStats, error) {
+	var st model.AutoJoinStats
+	err := s.DB.QueryRow(`SELECT
+			COALESCE(SUM(CASE WHEN status='joined' THEN 1 ELSE 0 END), 0),
+			COALESCE(SUM(CASE WHEN status='failed' THEN 1 ELSE 0 END), 0),
+			COALESCE(SUM(CASE WHEN status='skipped' THEN 1 ELSE 0 END), 0)
+		FROM auto_join_logs WHERE account_id=?`, accountID).
+		Scan(&st.TotalJoined, &st.TotalFailed, &st.TotalSkipped)
+	if err != nil {
+		return st, err
+	}
+	today, err := s.CountJoinsToday(accountID)
+	st.JoinedToday = int64(today)
+	return st, err
+}
+
+// ScheduleGroupEnable menjadwalkan grup hasil auto-join diaktifkan pada at (grup dibuat bila
+// belum tersinkron). Grup yang sudah aktif tidak diubah.
+func (s *Store) ScheduleGroupEnable(accountID, groupID, name string, at time.Time) error {
+	if err := s.UpsertGroup(accountID, groupID, name); err != nil {
+		return err
+	}
+	_, err := s.DB.Exec(`UPDATE groups SET enable_at=? WHERE id=? AND enabled=0`,
+		at.UTC().Format(ctsLayout), groupID)
+	return err
+}
+
+// EnableSettledGroups mengaktifkan grup yang jadwal enable_at-nya sudah lewat dan mengembalikan
+// ID-nya. Grup yang sudah ditinggalkan hanya dibersihkan jadwalnya.
+func (s *Store) EnableSettledGroups() ([]string, error) {
+	now := time.Now().UTC().Format(ctsLayout)
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	rows, err := tx.Query(`SELECT id FROM groups WHERE enable_at IS NOT NULL AND enable_at <= ? AND left_at IS NULL`, now)
+	if err != nil {
+		return nil, err
+	}
+	ids, err := scanIDs(rows)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := tx.Exec(`UPDATE groups SET enabled=CASE WHEN left_at IS NULL THEN 1 ELSE enabled END,
+		enable_at=NULL WHERE enable_at IS NOT NULL AND enable_at <= ?`, now); err != nil {
+		return nil, err
+	}
+	return ids, tx.Commit()
+}
@@ -0,0 +1,72 @@
+package storage
+
+import (
+	"database/sql"
+	"time"
+
+	"promote/internal/model"
+)
+
+const autoJoinIntroCols = `i.id, i.account_id, i.group_id, COALESCE(g.name,''), i.template_id, i.due_at, i.status,
+	COALESCE(i.error,''), i.sent_at, i.created_at`
+
+func scanAutoJoinIntro(sc interface{ Scan(...any) error }) (model.AutoJoinIntro, error) {
+	var it model.AutoJoinIntro
+	var sent sql.NullTime
+	err := sc.Scan(&it.ID, &it.AccountID, &it.GroupID, &it.GroupName, &it.TemplateID, &it.DueAt, &it.Status,
+		&it.Error, &sent, &it.CreatedAt)
+	it.SentAt = nullTimePtr(sent)
+	return it, err
+}
+
+// ScheduleAutoJoinIntro menjadwalkan intro untuk grup hasil join; grup yang sudah pernah
+// dijadwalkan untuk akun yang sama dilewati (false).
+func (s *Store) ScheduleAutoJoinIntro(accountID, groupID, templateID string, due time.Time) (bool, error) {
+	res, err := s.DB.Exec(`INSERT INTO auto_join_intros (account_id, group_id, template_id, due_at, status)
+		VALUES (?,?,?,?,?) ON CONFLICT(account_id, group_id) DO NOTHING`,
+		accountID, groupID, templateID, due.UTC().Format(ctsLayout), model.IntroPending)
+	if err != nil {
+		return false, err
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
+
+// DueAutoJoinIntros intro pending yang sudah jatuh tempo, terlama dulu.
+func (s *Store) DueAutoJoinIntros(limit int) ([]model.AutoJoinIntro, error) {
+	return s.queryAutoJoinIntros(`WHERE i.status=? AND i.due_at <= ? ORDER BY i.due_at, i.id LIMIT ?`,
+		model.IntroPending, time.Now().UTC().Format(ctsLayout), limit)
+}
+
+// ListAutoJoinIntros riwayat intro akun, terbaru dulu.
+func (s *Store) ListAutoJoinIntros(accountID string, limit int) ([]model.AutoJoinIntro, error) {
+	return s.queryAutoJoinIntros(`WHERE i.account_id=? ORDER BY i.due_at DESC, i.id DESC LIMIT ?`, accountID, limit)
+}
+
+func (s *Store) queryAutoJoinIntros(where string, args ...any) ([]model.AutoJoinIntro, error) {
+	rows, err := s.DB.Query(`SELECT `+autoJoinIntroCols+` FROM auto_join_intros i
+		LEFT JOIN groups g ON g.id=i.group_id `+where, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []model.AutoJoinIntro
+	for rows.Next() {
+		it, err := scanAutoJoinIntro(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, it)
+	}
+	return out, rows.Err()
+}
+
+// FinishAutoJoinIntro mencatat hasil intro (sent/failed/canceled).
+func (s *Store) FinishAutoJoinIntro(id int64, status, errMsg string) error {
+	q := `UPDATE auto_join_intros SET status=?, error=? WHERE id=?`
+	if status == model.IntroSent {
+		q = `UPDATE auto_join_intros SET status=?, error=?, sent_at=CURRENT_TIMESTAMP WHERE id=?`
+	}
+	_, err := s.DB.Exec(q, status, nullStr(errMsg), id)
+	return err
+}
@@ -0,0 +1,224 @@
+package storage
+
+import (
+	"database/sql"
+	"strings"
+	"time"
+
+	"promote/internal/model"
+)
+
+const autoJoinQueueCols = `id, account_id, invite_code, COALESCE(group_name,''), COALESCE(shared_by,''),
+	COALESCE(shared_in,''), status, COALESCE(reason,''), created_at, decided_at, done_at, not_before, attempts`
+
+func scanAutoJoinQueueItem(sc interface{ Scan(...any) error }) (model.AutoJoinQueueItem, error) {
+	var it model.AutoJoinQueueItem
+	var decided, done, notBefore sql.NullTime
+	err := sc.Scan(&it.ID, &it.AccountID, &it.InviteCode, &it.GroupName, &it.SharedBy,
+		&it.SharedIn, &it.Status, &it.Reason, &it.CreatedAt, &decided, &done, &notBefore, &it.Attempts)
+	if decided.Valid {
+		t := decided.Time
+		it.DecidedAt = &t
+	}
+	if done.Valid {
+		t := done.Time
+		it.DoneAt = &t
+	}
+	it.NotBefore = nullTimePtr(notBefore)
+	return it, err
+}
+
+// EnqueueAutoJoin memasukkan link ke antrean persetujuan. Kode yang sudah pernah diantrekan
+// untuk akun yang sama (apa pun statusnya) tidak diantrekan ulang; created=false.
+func (s *Store) EnqueueAutoJoin(it model.AutoJoinQueueItem) (id int64, created bool, err error) {
+	res, err := s.DB.Exec(`INSERT INTO auto_join_queue (account_id, invite_code, group_name, shared_by, shared_in, status)
+		VALUES (?,?,?,?,?,?) ON CONFLICT(account_id, invite_code) DO NOTHING`,
+		it.AccountID, it.InviteCode, nullStr(it.GroupName), nullStr(it.SharedBy), nullStr(it.SharedIn), model.AutoJoinPending)
+	if err != nil {
+		return 0, false, err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		err = s.DB.QueryRow(`SELECT id FROM auto_join_queue WHERE account_id=? AND invite_code=?`,
+			it.AccountID, it.InviteCode).Scan(&id)
+		return id, false, err
+	}
+	id, err = res.LastInsertId()
+	return id, true, err
+}
+
+// ImportAutoJoins memasukkan link hasil impor massal langsung sebagai item yang sudah disetujui,
+// masing-masing dengan NotBefore-nya sendiri. Kode yang sudah ada di antrean akun yang sama
+// dilewati; mengembalikan jumlah item baru.
+func (s *Store) ImportAutoJoins(items []model.AutoJoinQueueItem) (int, error) {
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+	stmt, err := tx.Prepare(`INSERT INTO auto_join_queue
+		(account_id, invite_code, group_name, shared_by, shared_in, status, decided_at, not_before)
+		VALUES (?,?,?,?,?,?,CURRENT_TIMESTAMP,?) ON CONFLICT(account_id, invite_code) DO NOTHING`)
+	if err != nil {
+		return 0, err
+	}
+	defer stmt.Close()
+	created := 0
+	for _, it := range items {
+		var notBefore any
+		if it.NotBefore != nil {
+			notBefore = it.NotBefore.UTC().Format(ctsLayout)
+		}
+		res, err := stmt.Exec(it.AccountID, it.InviteCode, nullStr(it.GroupName), nullStr(it.SharedBy),
+			nullStr(it.SharedIn), model.AutoJoinApproved, notBefore)
+		if err != nil {
+			return 0, err
+		}
+		if n, _ := res.RowsAffected(); n > 0 {
+			created++
+		}
+	}
+	return created, tx.Commit()
+}
+
+// ListAutoJoinQueue isi antrean, terbaru dulu; accountID/status kosong = semua.
+func (s *Store) ListAutoJoinQueue(accountID, status string, limit int) ([]model.AutoJoinQueueItem, error) {
+	q := `SELECT ` + autoJoinQueueCols + ` FROM auto_join_queue WHERE 1=1`
+	var args []any
+	if accountID != "" {
+		q += ` AND account_id=?`
+		args = append(args, accountID)
+	}
+	if status = strings.TrimSpace(status); status != "" {
+		q += ` AND status=?`
+		args = append(args, status)
+	}
+	q += ` ORDER BY created_at DESC, id DESC LIMIT ?`
+	args = append(args, limit)
+	rows, err := s.DB.Query(q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []model.AutoJoinQueueItem
+	for rows.Next() {
+		it, err := scanAutoJoinQueueItem(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, it)
+	}
+	return out, rows.Err()
+}
+
+// DecideAutoJoinQueue menyetujui (approve=true) atau menolak item pending; item yang sudah
+// diputuskan tidak berubah. Mengembalikan jumlah item yang diubah.
+func (s *Store) DecideAutoJoinQueue(ids []int64, approve bool) (int64, error) {
+	if len(ids) == 0 {
+		return 0, nil
+	}
+	status := model.AutoJoinRejected
+	if approve {
+		status = model.AutoJoinApproved
+	}
+	args := []any{status}
+	for _, id := range ids {
+		args = append(args, id)
+	}
+	args = append(args, model.AutoJoinPending)
+	res, err := s.DB.Exec(`UPDATE auto_join_queue SET status=?, decided_at=CURRENT_TIMESTAMP
+		WHERE id IN (?`+strings.Repeat(`,?`, len(ids)-1)+`) AND status=?`, args...)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// ApprovedAutoJoins item yang sudah disetujui (atau menunggu retry), belum diproses dan sudah
+// jatuh tempo (not_before), urut waktu jatuh tempo lalu waktu persetujuan.
+func (s *Store) ApprovedAutoJoins(limit int) ([]model.AutoJoinQueueItem, error) {
+	rows, err := s.DB.Query(`SELECT `+autoJoinQueueCols+` FROM auto_join_queue
+		WHERE status IN (?,?) AND (not_before IS NULL OR not_before <= ?)
+		ORDER BY COALESCE(not_before, decided_at), decided_at, id LIMIT ?`,
+		model.AutoJoinApproved, model.AutoJoinRetrying, time.Now().UTC().Format(ctsLayout), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []model.AutoJoinQueueItem
+	for rows.Next() {
+		it, err := scanAutoJoinQueueItem(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, it)
+	}
+	return out, rows.Err()
+}
+
+// FinishAutoJoinQueue mencatat hasil akhir (joined/failed/skipped) item yang disetujui.
+func (s *Store) FinishAutoJoinQueue(id int64, status, reason string) error {
+	_, err := s.DB.Exec(`UPDATE auto_join_queue SET status=?, reason=?, done_at=CURRENT_TIMESTAMP WHERE id=?`,
+		status, nullStr(reason), id)
+	return err
+}
+
+// AutoJoinAttempts jumlah percobaan ulang yang tercatat untuk kode undangan akun (0 jika belum ada).
+func (s *Store) AutoJoinAttempts(accountID, inviteCode string) (int, error) {
+	var n int
+	err := s.DB.QueryRow(`SELECT attempts FROM auto_join_queue WHERE account_id=? AND invite_code=?`,
+		accountID, inviteCode).Scan(&n)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	return n, err
+}
+
+// ScheduleAutoJoinRetry menandai kode undangan akun untuk dicoba ulang pada it.NotBefore dengan
+// it.Attempts percobaan; item antrean dibuat bila belum ada.
+func (s *Store) ScheduleAutoJoinRetry(it model.AutoJoinQueueItem, reason string) error {
+	return s.upsertAutoJoinQueue(it, model.AutoJoinRetrying, reason)
+}
+
+// RequeueAutoJoin memasukkan ulang kode undangan akun ke antrean sebagai item yang disetujui,
+// dengan hitungan percobaan direset (requeue manual setelah gagal).
+func (s *Store) RequeueAutoJoin(it model.AutoJoinQueueItem) (int64, error) {
+	it.Attempts, it.NotBefore = 0, nil
+	if err := s.upsertAutoJoinQueue(it, model.AutoJoinApproved, ""); err != nil {
+		return 0, err
+	}
+	var id int64
+	err := s.DB.QueryRow(`SELECT id FROM auto_join_queue WHERE account_id=? AND invite_code=?`,
+		it.AccountID, it.InviteCode).Scan(&id)
+	return id, err
+}
+
+// DeferAutoJoin menunda kode undangan akun sampai it.NotBefore (mis. jendela join berikutnya)
+// sebagai item yang disetujui; status retrying dan hitungan percobaan dipertahankan.
+func (s *Store) DeferAutoJoin(it model.AutoJoinQueueItem, reason string) error {
+	status := model.AutoJoinApproved
+	if it.Status == model.AutoJoinRetrying {
+		status = it.Status
+	}
+	return s.upsertAutoJoinQueue(it, status, reason)
+}
+
+func (s *Store) upsertAutoJoinQueue(it model.AutoJoinQueueItem, status, reason string) error {
+	var notBefore any
+	if it.NotBefore != nil {
+		notBefore = it.NotBefore.UTC().Format(ctsLayout)
+	}
+	_, err := s.DB.Exec(`INSERT INTO auto_join_queue
+		(account_id, invite_code, group_name, shared_by, shared_in, status, reason, decided_at, not_before, attempts)
+		VALUES (?,?,?,?,?,?,?,CURRENT_TIMESTAMP,?,?)
+		ON CONFLICT(account_id, invite_code) DO UPDATE SET
+			group_name=COALESCE(excluded.group_name, group_name),
+			status=excluded.status,
+			reason=excluded.reason,
+			decided_at=COALESCE(decided_at, excluded.decided_at),
+			done_at=NULL,
+			not_before=excluded.not_before,
+			attempts=excluded.attempts`,
+		it.AccountID, it.InviteCode, nullStr(it.GroupName), nullStr(it.SharedBy), nullStr(it.SharedIn),
+		status, nullStr(reason), notBefore, it.Attempts)
+	return err
+}
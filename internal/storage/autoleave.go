@@ -0,0 +1,103 @@
+package storage
+
+import (
+	"database/sql"
+	"encoding/json"
+
+	"promote/internal/model"
+)
+
+// SettingAutoLeave kebijakan keluar otomatis dari grup bernilai rendah (JSON model.AutoLeavePolicy).
+const SettingAutoLeave = "auto_leave"
+
+// AutoLeavePolicy membaca kebijakan auto-leave; default (nonaktif) jika belum diset.
+func (s *Store) AutoLeavePolicy() (model.AutoLeavePolicy, error) {
+	p := model.DefaultAutoLeavePolicy()
+	v, err := s.GetSetting(SettingAutoLeave)
+	if err != nil || v == "" {
+		return p, err
+	}
+	if err := json.Unmarshal([]byte(v), &p); err != nil || p.Validate() != nil {
+		return model.DefaultAutoLeavePolicy(), err
+	}
+	return p, nil
+}
+
+// SetAutoLeavePolicy menyimpan kebijakan auto-leave.
+func (s *Store) SetAutoLeavePolicy(p model.AutoLeavePolicy) error {
+	raw, err := json.Marshal(p)
+	if err != nil {
+		return err
+	}
+	return s.SetSetting(SettingAutoLeave, string(raw))
+}
+
+// AutoLeaveCandidates grup akun yang boleh dinilai kebijakan auto-leave beserta streak gagal
+// (sejak kiriman sukses terakhir akun ke grup) dan waktu kiriman sukses terakhir, yang paling
+// lama tanpa kiriman dulu. Grup uji, grup komunitas, grup milik sendiri (tercatat di group_links),
+// dan grup yang sudah ditinggalkan tidak ikut.
+func (s *Store) AutoLeaveCandidates(accountID string) ([]model.AutoLeaveCandidate, error) {
+	rows, err := s.DB.Query(`
+		SELECT g.id, g.account_id, COALESCE(g.name,''), g.participant_count, g.created_at,
+			(SELECT COUNT(*) FROM logs f WHERE f.group_id=g.id AND f.account_id=g.account_id AND f.status='failed'
+				AND f.id > COALESCE((SELECT MAX(id) FROM logs WHERE group_id=g.id AND account_id=g.account_id AND status='sent'), 0)),
+			(SELECT MAX(ts) FROM logs WHERE group_id=g.id AND account_id=g.account_id AND status='sent') AS last_ok
+		FROM groups g
+		WHERE g.account_id=? AND g.left_at IS NULL AND g.is_test=0 AND g.community_role=''
+			AND g.id NOT IN (SELECT group_id FROM group_links)
+		ORDER BY COALESCE(last_ok, g.created_at), g.id`, accountID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []model.AutoLeaveCandidate
+	for rows.Next() {
+		var c model.AutoLeaveCandidate
+		var lastOK sql.NullString
+		if err := rows.Scan(&c.GroupID, &c.AccountID, &c.GroupName, &c.Participants, &c.KnownSince,
+			&c.FailureStreak, &lastOK); err != nil {
+			return nil, err
+		}
+		if t, ok := parseDBTime(lastOK); ok {
+			c.LastSentAt = &t
+		}
+		out = append(out, c)
+	}
+	return out, rows.Err()
+}
+
+// LogAutoLeave mencatat satu keputusan auto-leave.
+func (s *Store) LogAutoLeave(l model.AutoLeaveLog) error {
+	_, err := s.DB.Exec(`INSERT INTO auto_leave_logs (account_id, group_id, group_name, status, reason, error)
+		VALUES (?,?,?,?,?,?)`,
+		l.AccountID, l.GroupID, nullStr(l.GroupName), l.Status, l.Reason, nullStr(l.Error))
+	return err
+}
+
+// ListAutoLeaveLogs riwayat auto-leave terbaru dulu; accountID kosong = semua akun.
+func (s *Store) ListAutoLeaveLogs(accountID string, limit int) ([]model.AutoLeaveLog, error) {
+	q := `SELECT id, account_id, group_id, COALESCE(group_name,''), status, reason, COALESCE(error,''), created_at
+		FROM auto_leave_logs`
+	var args []any
+	if accountID != "" {
+		q += ` WHERE account_id=?`
+		args = append(args, accountID)
+	}
+	q += ` ORDER BY id DESC LIMIT ?`
+	args = append(args, limit)
+	rows, err := s.DB.Query(q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []model.AutoLeaveLog
+	for rows.Next() {
+		var l model.AutoLeaveLog
+		if err := rows.Scan(&l.ID, &l.AccountID, &l.GroupID, &l.GroupName, &l.Status, &l.Reason,
+			&l.Error, &l.CreatedAt); err != nil {
+			return nil, err
+		}
+		out = append(out, l)
+	}
+	return out, rows.Err()
+}
@@ -0,0 +1,113 @@
+package storage
+
+import (
+	"database/sql"
+	"strconv"
+	"time"
+
+	"promote/internal/model"
+)
+
+// Kunci settings error budget.
+const (
+	SettingBudgetFailPct     = "error_budget_pct"
+	SettingBudgetWindowHours = "error_budget_window_hours"
+	SettingBudgetMinSends    = "error_budget_min_sends"
+)
+
+// DefaultErrorBudget: maks 5% gagal per 24 jam, dievaluasi setelah minimal 20 kiriman.
+var DefaultErrorBudget = model.ErrorBudget{MaxFailPct: 5, WindowHours: 24, MinSends: 20}
+
+// ErrorBudget membaca konfigurasi error budget dari settings (default jika belum diset).
+func (s *Store) ErrorBudget() (model.ErrorBudget, error) {
+	b := DefaultErrorBudget
+	for key, set := range map[string]func(string){
+		SettingBudgetFailPct: func(v string) {
+			if f, err := strconv.ParseFloat(v, 64); err == nil {
+				b.MaxFailPct = f
+			}
+		},
+		SettingBudgetWindowHours: func(v string) {
+			if n, err := strconv.Atoi(v); err == nil {
+				b.WindowHours = n
+			}
+		},
+		SettingBudgetMinSends: func(v string) {
+			if n, err := strconv.Atoi(v); err == nil {
+				b.MinSends = n
+			}
+		},
+	} {
+		v, err := s.GetSetting(key)
+		if err != nil {
+			return b, err
+		}
+		if v != "" {
+			set(v)
+		}
+	}
+	return b, nil
+}
+
+// SetErrorBudget menyimpan konfigurasi error budget.
+func (s *Store) SetErrorBudget(b model.ErrorBudget) error {
+	for key, v := range map[string]string{
+		SettingBudgetFailPct:     strconv.FormatFloat(b.MaxFailPct, 'f', -1, 64),
+		SettingBudgetWindowHours: strconv.Itoa(b.WindowHours),
+		SettingBudgetMinSends:    strconv.Itoa(b.MinSends),
+	} {
+		if err := s.SetSetting(key, v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// AccountBudgets menghitung kiriman sukses/gagal per akun sejak since, beserta status jeda budget.
+func (s *Store) AccountBudgets(since time.Time) ([]model.AccountBudget, error) {
+	rows, err := s.DB.Query(`
+		SELECT a.id, a.label,
+			(SELECT COUNT(*) FROM logs WHERE account_id=a.id AND status='sent' AND ts >= ?),
+			(SELECT COUNT(*) FROM logs WHERE account_id=a.id AND status='failed' AND ts >= ?),
+			a.budget_paused_at, COALESCE(a.budget_pause_reason,''), a.budget_override_until
+		FROM accounts a WHERE a.enabled=1 ORDER BY a.created_at`,
+		since.UTC().Format(ctsLayout), since.UTC().Format(ctsLayout))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var list []model.AccountBudget
+	for rows.Next() {
+		var b model.AccountBudget
+		var paused, override sql.NullTime
+		if err := rows.Scan(&b.AccountID, &b.Label, &b.Sent, &b.Failed, &paused, &b.PauseReason, &override); err != nil {
+			return nil, err
+		}
+		if total := b.Sent + b.Failed; total > 0 {
+			b.FailPct = float64(b.Failed) * 100 / float64(total)
+		}
+		b.PausedAt = nullTimePtr(paused)
+		b.OverrideUntil = nullTimePtr(override)
+		list = append(list, b)
+	}
+	return list, rows.Err()
+}
+
+// PauseAccountBudget menjeda penjadwalan akun karena error budget terlampaui.
+func (s *Store) PauseAccountBudget(accountID, reason string) error {
+	_, err := s.DB.Exec(`UPDATE accounts SET budget_paused_at=?, budget_pause_reason=? WHERE id=?`,
+		time.Now().UTC(), reason, accountID)
+	return err
+}
+
+// ResumeAccountBudget melepas jeda budget; overrideUntil (opsional) menonaktifkan evaluasi
+// budget akun sampai waktu tersebut (override manual).
+func (s *Store) ResumeAccountBudget(accountID string, overrideUntil *time.Time) (bool, error) {
+	res, err := s.DB.Exec(`UPDATE accounts SET budget_paused_at=NULL, budget_pause_reason=NULL, budget_override_until=? WHERE id=?`,
+		timePtrArg(overrideUntil), accountID)
+	if err != nil {
+		return false, err
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
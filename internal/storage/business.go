@@ -0,0 +1,107 @@
+package storage
+
+import (
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"promote/internal/model"
+)
+
+const businessCols = `a.id, a.label, COALESCE(b.is_business,0), COALESCE(b.live_json,''), COALESCE(b.managed_json,''),
+	b.synced_at, COALESCE(b.sync_error,'')`
+
+func scanBusinessProfile(sc interface{ Scan(...any) error }) (model.AccountBusinessProfile, error) {
+	var p model.AccountBusinessProfile
+	var isBusiness int
+	var live, managed string
+	var synced sql.NullTime
+	if err := sc.Scan(&p.AccountID, &p.Label, &isBusiness, &live, &managed, &synced, &p.SyncError); err != nil {
+		return p, err
+	}
+	p.IsBusiness = isBusiness == 1
+	if live != "" {
+		_ = json.Unmarshal([]byte(live), &p.Live)
+	}
+	if managed != "" {
+		_ = json.Unmarshal([]byte(managed), &p.Managed)
+	}
+	if synced.Valid {
+		p.SyncedAt = &synced.Time
+	}
+	p.Drift = p.Managed.Drift(p.Live)
+	return p, nil
+}
+
+// GetBusinessProfile membaca profil bisnis akun; akun yang belum pernah di-sync mengembalikan
+// profil kosong. ErrAccountNotFound jika akun tidak ada.
+func (s *Store) GetBusinessProfile(accountID string) (model.AccountBusinessProfile, error) {
+	p, err := scanBusinessProfile(s.DB.QueryRow(`SELECT `+businessCols+`
+		FROM accounts a LEFT JOIN business_profiles b ON b.account_id=a.id WHERE a.id=?`, accountID))
+	if err == sql.ErrNoRows {
+		return p, ErrAccountNotFound
+	}
+	return p, err
+}
+
+// ListBusinessProfiles mengembalikan profil bisnis semua akun.
+func (s *Store) ListBusinessProfiles() ([]model.AccountBusinessProfile, error) {
+	rows, err := s.DB.Query(`SELECT ` + businessCols + `
+		FROM accounts a LEFT JOIN business_profiles b ON b.account_id=a.id ORDER BY a.created_at`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var list []model.AccountBusinessProfile
+	for rows.Next() {
+		p, err := scanBusinessProfile(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, p)
+	}
+	return list, rows.Err()
+}
+
+// SaveBusinessSync menyimpan hasil sync profil dari WhatsApp. syncErr tidak kosong = sync gagal;
+// profil live sebelumnya dipertahankan.
+func (s *Store) SaveBusinessSync(accountID string, isBusiness bool, live model.BusinessProfile, syncErr string) error {
+	now := time.Now().UTC()
+	if syncErr != "" {
+		_, err := s.DB.Exec(`INSERT INTO business_profiles (account_id, synced_at, sync_error) VALUES (?,?,?)
+			ON CONFLICT(account_id) DO UPDATE SET synced_at=excluded.synced_at, sync_error=excluded.sync_error,
+			updated_at=CURRENT_TIMESTAMP`, accountID, now, syncErr)
+		return err
+	}
+	b, err := json.Marshal(live)
+	if err != nil {
+		return err
+	}
+	_, err = s.DB.Exec(`INSERT INTO business_profiles (account_id, is_business, live_json, synced_at) VALUES (?,?,?,?)
+		ON CONFLICT(account_id) DO UPDATE SET is_business=excluded.is_business, live_json=excluded.live_json,
+		synced_at=excluded.synced_at, sync_error=NULL, updated_at=CURRENT_TIMESTAMP`,
+		accountID, btoi(isBusiness), string(b), now)
+	return err
+}
+
+// SetManagedBusinessProfile menyimpan profil bisnis yang dikelola terpusat untuk akun.
+func (s *Store) SetManagedBusinessProfile(accountID string, p model.BusinessProfile) error {
+	b, err := json.Marshal(p)
+	if err != nil {
+		return err
+	}
+	_, err = s.DB.Exec(`INSERT INTO business_profiles (account_id, managed_json) VALUES (?,?)
+		ON CONFLICT(account_id) DO UPDATE SET managed_json=excluded.managed_json, updated_at=CURRENT_TIMESTAMP`,
+		accountID, string(b))
+	return err
+}
+
+// IsBusinessAccount true jika sync terakhir mendeteksi akun sebagai WhatsApp Business.
+func (s *Store) IsBusinessAccount(accountID string) (bool, error) {
+	var n int
+	err := s.DB.QueryRow(`SELECT is_business FROM business_profiles WHERE account_id=?`, accountID).Scan(&n)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	return n == 1, err
+}
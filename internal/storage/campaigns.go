@@ -0,0 +1,155 @@
+package storage
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+
+	"promote/internal/model"
+)
+
+// ErrCampaignNotFound dikembalikan jika campaign tidak ada.
+var ErrCampaignNotFound = errors.New("campaign not found")
+
+const campaignCols = `id, name, COALESCE(text,''), COALESCE(media_images,''), COALESCE(media_videos,''),
+	COALESCE(media_stickers,''), COALESCE(media_docs,''), enabled, per_group_variants, COALESCE(targeting,''),
+	COALESCE(mode,'group'), COALESCE(promote_group_id,''), created_at, updated_at`
+
+func scanCampaign(sc interface{ Scan(...any) error }) (model.Campaign, error) {
+	var c model.Campaign
+	var imgs, vids, stickers, docs, targeting string
+	var enabled int
+	if err := sc.Scan(&c.ID, &c.Name, &c.Text, &imgs, &vids, &stickers, &docs, &enabled, &c.PerGroupVariants, &targeting,
+		&c.Mode, &c.PromoteGroupID, &c.CreatedAt, &c.UpdatedAt); err != nil {
+		return c, err
+	}
+	c.Enabled = enabled == 1
+	c.ImageURLs = jsonList(imgs)
+	c.VideoURLs = jsonList(vids)
+	c.StickerURLs = jsonList(stickers)
+	c.DocURLs = jsonList(docs)
+	if targeting != "" {
+		var t model.Targeting
+		if json.Unmarshal([]byte(targeting), &t) == nil {
+			c.Targeting = &t
+		}
+	}
+	return c, nil
+}
+
+// jsonList mengurai JSON array string; selalu non-nil agar di-encode sebagai [].
+func jsonList(s string) []string {
+	out := []string{}
+	if s != "" {
+		_ = json.Unmarshal([]byte(s), &out)
+	}
+	if out == nil {
+		out = []string{}
+	}
+	return out
+}
+
+func jsonListArg(list []string) any {
+	if len(list) == 0 {
+		return nil
+	}
+	b, _ := json.Marshal(list)
+	return string(b)
+}
+
+func targetingArg(t *model.Targeting) any {
+	if t == nil || t.Empty() {
+		return nil
+	}
+	b, _ := json.Marshal(t)
+	return string(b)
+}
+
+// ListCampaigns mengembalikan semua campaign, terbaru dulu.
+func (s *Store) ListCampaigns() ([]model.Campaign, error) {
+	rows, err := s.DB.Query(`SELECT ` + campaignCols + ` FROM campaigns ORDER BY created_at DESC`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var list []model.Campaign
+	for rows.Next() {
+		c, err := scanCampaign(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, c)
+	}
+	return list, rows.Err()
+}
+
+// GetCampaign mengambil satu campaign; ErrCampaignNotFound jika tidak ada.
+func (s *Store) GetCampaign(id string) (model.Campaign, error) {
+	c, err := scanCampaign(s.DB.QueryRow(`SELECT `+campaignCols+` FROM campaigns WHERE id=?`, id))
+	if err == sql.ErrNoRows {
+		return c, ErrCampaignNotFound
+	}
+	return c, err
+}
+
+// CreateCampaign menyimpan campaign baru dan mengembalikan ID-nya.
+func (s *Store) CreateCampaign(c model.Campaign) (string, error) {
+	id := uuid.NewString()
+	if c.PerGroupVariants <= 0 {
+		c.PerGroupVariants = 1
+	}
+	if c.Mode == "" {
+		c.Mode = model.CampaignModeGroup
+	}
+	if c.Mode != model.CampaignModeCrossPromo {
+		c.PromoteGroupID = ""
+	}
+	now := time.Now().UTC()
+	_, err := s.DB.Exec(`INSERT INTO campaigns (id, name, text, media_images, media_videos, media_stickers, media_docs, enabled, per_group_variants, targeting, mode, promote_group_id, created_at, updated_at)
+		VALUES (?,?,?,?,?,?,?,?,?,?,?,?,?,?)`,
+		id, c.Name, nullStr(c.Text), jsonListArg(c.ImageURLs), jsonListArg(c.VideoURLs), jsonListArg(c.StickerURLs), jsonListArg(c.DocURLs),
+		btoi(c.Enabled), c.PerGroupVariants, targetingArg(c.Targeting), c.Mode, nullStr(c.PromoteGroupID), now, now)
+	if err != nil {
+		return "", err
+	}
+	return id, nil
+}
+
+// UpdateCampaign mengganti seluruh isi campaign.
+func (s *Store) UpdateCampaign(c model.Campaign) error {
+	if c.PerGroupVariants <= 0 {
+		c.PerGroupVariants = 1
+	}
+	if c.Mode == "" {
+		c.Mode = model.CampaignModeGroup
+	}
+	if c.Mode != model.CampaignModeCrossPromo {
+		c.PromoteGroupID = ""
+	}
+	res, err := s.DB.Exec(`UPDATE campaigns SET name=?, text=?, media_images=?, media_videos=?, media_stickers=?, media_docs=?,
+		enabled=?, per_group_variants=?, targeting=?, mode=?, promote_group_id=?, updated_at=? WHERE id=?`,
+		c.Name, nullStr(c.Text), jsonListArg(c.ImageURLs), jsonListArg(c.VideoURLs), jsonListArg(c.StickerURLs), jsonListArg(c.DocURLs),
+		btoi(c.Enabled), c.PerGroupVariants, targetingArg(c.Targeting), c.Mode, nullStr(c.PromoteGroupID), time.Now().UTC(), c.ID)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrCampaignNotFound
+	}
+	return nil
+}
+
+// DeleteCampaign menghapus campaign (jadwal ikut terhapus lewat ON DELETE CASCADE).
+func (s *Store) DeleteCampaign(id string) error {
+	res, err := s.DB.Exec(`DELETE FROM campaigns WHERE id=?`, id)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrCampaignNotFound
+	}
+	return nil
+}
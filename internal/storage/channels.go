@@ -0,0 +1,219 @@
+package storage
+
+import (
+	"database/sql"
+	"errors"
+	"strconv"
+	"strings"
+
+	"promote/internal/model"
+)
+
+// ErrChannelNotFound dikembalikan jika channel tidak ada.
+var ErrChannelNotFound = errors.New("channel not found")
+
+// Kunci settings aturan posting channel.
+const (
+	SettingChannelDailyLimit  = "channel_daily_limit"
+	SettingChannelMinInterval = "channel_min_interval_min"
+)
+
+// DefaultChannelRules: maks 2 posting per channel per hari, berjarak minimal 6 jam.
+var DefaultChannelRules = model.ChannelRules{DailyLimit: 2, MinIntervalMin: 360}
+
+// channelCols kolom channel; posts_today dihitung dari channel_posts sukses sejak ? (awal hari).
+const channelCols = `c.id, c.account_id, c.name, c.role, c.subscribers, COALESCE(c.invite_code,''), c.enabled,
+	c.last_posted_at, c.synced_at,
+	(SELECT COUNT(*) FROM channel_posts p WHERE p.channel_id=c.id AND p.status='sent' AND p.ts >= ?)`
+
+func scanChannel(sc interface{ Scan(...any) error }) (model.Channel, error) {
+	var c model.Channel
+	var last sql.NullTime
+	err := sc.Scan(&c.ID, &c.AccountID, &c.Name, &c.Role, &c.Subscribers, &c.InviteCode, &c.Enabled,
+		&last, &c.SyncedAt, &c.PostsToday)
+	if last.Valid {
+		c.LastPostedAt = &last.Time
+	}
+	return c, err
+}
+
+func (s *Store) queryChannels(where string, args ...any) ([]model.Channel, error) {
+	from, _ := s.TodayArgs()
+	rows, err := s.DB.Query(`SELECT `+channelCols+` FROM channels c `+where, append([]any{from}, args...)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var list []model.Channel
+	for rows.Next() {
+		c, err := scanChannel(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, c)
+	}
+	return list, rows.Err()
+}
+
+// ListChannels daftar channel (opsional per akun), urut nama.
+func (s *Store) ListChannels(accountID string) ([]model.Channel, error) {
+	if accountID != "" {
+		return s.queryChannels(`WHERE c.account_id=? ORDER BY c.name`, accountID)
+	}
+	return s.queryChannels(`ORDER BY c.name`)
+}
+
+// GetChannel mengambil satu channel; ErrChannelNotFound jika tidak ada.
+func (s *Store) GetChannel(id string) (model.Channel, error) {
+	list, err := s.queryChannels(`WHERE c.id=?`, id)
+	if err != nil {
+		return model.Channel{}, err
+	}
+	if len(list) == 0 {
+		return model.Channel{}, ErrChannelNotFound
+	}
+	return list[0], nil
+}
+
+// SyncChannels menyimpan channel hasil sync akun (status enabled dan riwayat dipertahankan);
+// channel akun yang tidak lagi ada di daftar (bukan owner/admin lagi) dihapus.
+func (s *Store) SyncChannels(accountID string, list []model.Channel) (removed int, err error) {
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+	keep := make([]any, 0, len(list)+1)
+	keep = append(keep, accountID)
+	for _, c := range list {
+		if _, err := tx.Exec(`INSERT INTO channels (id, account_id, name, role, subscribers, invite_code, synced_at)
+			VALUES (?,?,?,?,?,?,CURRENT_TIMESTAMP)
+			ON CONFLICT(id) DO UPDATE SET account_id=excluded.account_id, name=excluded.name, role=excluded.role,
+				subscribers=excluded.subscribers, invite_code=excluded.invite_code, synced_at=CURRENT_TIMESTAMP`,
+			c.ID, accountID, c.Name, c.Role, c.Subscribers, nullStr(c.InviteCode)); err != nil {
+			return 0, err
+		}
+		keep = append(keep, c.ID)
+	}
+	q := `DELETE FROM channels WHERE account_id=?`
+	if len(list) > 0 {
+		q += ` AND id NOT IN (?` + strings.Repeat(",?", len(list)-1) + `)`
+	}
+	res, err := tx.Exec(q, keep...)
+	if err != nil {
+		return 0, err
+	}
+	n, _ := res.RowsAffected()
+	return int(n), tx.Commit()
+}
+
+// SetChannelEnabled mengaktifkan/menonaktifkan posting otomatis ke channel.
+func (s *Store) SetChannelEnabled(id string, enabled bool) error {
+	res, err := s.DB.Exec(`UPDATE channels SET enabled=? WHERE id=?`, btoi(enabled), id)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrChannelNotFound
+	}
+	return nil
+}
+
+// InsertChannelPost mencatat satu posting; posting sukses memperbarui last_posted_at channel.
+func (s *Store) InsertChannelPost(p model.ChannelPost) error {
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	var tplVersion any
+	if p.TemplateVersion > 0 {
+		tplVersion = p.TemplateVersion
+	}
+	if _, err := tx.Exec(`INSERT INTO channel_posts (channel_id, account_id, template_id, template_version, status, error, message_id, preview)
+		VALUES (?,?,?,?,?,?,?,?)`,
+		p.ChannelID, p.AccountID, nullStr(p.TemplateID), tplVersion, p.Status, nullStr(p.Error), nullStr(p.MessageID), nullStr(p.Preview)); err != nil {
+		return err
+	}
+	if p.Status == "sent" {
+		if _, err := tx.Exec(`UPDATE channels SET last_posted_at=CURRENT_TIMESTAMP WHERE id=?`, p.ChannelID); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// ListChannelPosts riwayat posting channel, terbaru dulu.
+func (s *Store) ListChannelPosts(channelID string, limit int) ([]model.ChannelPost, error) {
+	rows, err := s.DB.Query(`SELECT id, channel_id, account_id, COALESCE(template_id,''), COALESCE(template_version,0),
+		status, COALESCE(error,''), COALESCE(message_id,''), COALESCE(preview,''), ts
+		FROM channel_posts WHERE channel_id=? ORDER BY id DESC LIMIT ?`, channelID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var list []model.ChannelPost
+	for rows.Next() {
+		var p model.ChannelPost
+		if err := rows.Scan(&p.ID, &p.ChannelID, &p.AccountID, &p.TemplateID, &p.TemplateVersion,
+			&p.Status, &p.Error, &p.MessageID, &p.Preview, &p.TS); err != nil {
+			return nil, err
+		}
+		list = append(list, p)
+	}
+	return list, rows.Err()
+}
+
+// DueChannels channel aktif yang boleh diposting sekarang menurut aturan: akun aktif (bukan
+// observer/dijeda budget), posting hari ini di bawah DailyLimit, dan posting terakhir lebih lama
+// dari MinIntervalMin. Urut posting terlama dulu.
+func (s *Store) DueChannels(r model.ChannelRules) ([]model.Channel, error) {
+	if r.DailyLimit <= 0 {
+		return nil, nil
+	}
+	list, err := s.queryChannels(`JOIN accounts a ON a.id=c.account_id
+		WHERE c.enabled=1 AND a.enabled=1 AND a.observer=0 AND a.budget_paused_at IS NULL
+			AND (c.last_posted_at IS NULL OR c.last_posted_at < datetime('now', ?))
+		ORDER BY COALESCE(c.last_posted_at, ''), c.id`, "-"+strconv.Itoa(r.MinIntervalMin)+" minutes")
+	if err != nil {
+		return nil, err
+	}
+	due := list[:0]
+	for _, c := range list {
+		if c.PostsToday < r.DailyLimit {
+			due = append(due, c)
+		}
+	}
+	return due, nil
+}
+
+// ChannelRules membaca aturan posting channel dari settings (default jika belum diset).
+func (s *Store) ChannelRules() (model.ChannelRules, error) {
+	r := DefaultChannelRules
+	for key, dst := range map[string]*int{
+		SettingChannelDailyLimit:  &r.DailyLimit,
+		SettingChannelMinInterval: &r.MinIntervalMin,
+	} {
+		v, err := s.GetSetting(key)
+		if err != nil {
+			return r, err
+		}
+		if n, err := strconv.Atoi(strings.TrimSpace(v)); err == nil {
+			*dst = n
+		}
+	}
+	return r, nil
+}
+
+// SetChannelRules menyimpan aturan posting channel.
+func (s *Store) SetChannelRules(r model.ChannelRules) error {
+	for key, v := range map[string]int{
+		SettingChannelDailyLimit:  r.DailyLimit,
+		SettingChannelMinInterval: r.MinIntervalMin,
+	} {
+		if err := s.SetSetting(key, strconv.Itoa(v)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,169 @@
+package storage
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"promote/internal/model"
+)
+
+// SettingColdStartPerDay jumlah maksimum grup baru (belum pernah dikirimi) per akun per hari
+// yang boleh menerima kiriman pertama; 0 = ramp nonaktif.
+const SettingColdStartPerDay = "cold_start_per_day"
+
+// ColdStartPerDay membaca batas ramp cold-start (0 jika belum diset).
+func (s *Store) ColdStartPerDay() (int, error) {
+	v, err := s.GetSetting(SettingColdStartPerDay)
+	if err != nil {
+		return 0, err
+	}
+	n, _ := strconv.Atoi(strings.TrimSpace(v))
+	if n < 0 {
+		n = 0
+	}
+	return n, nil
+}
+
+// SetColdStartPerDay menyimpan batas ramp. Rencana yang belum jatuh tempo dihapus agar
+// dijadwal ulang dengan batas baru pada PlanColdStart berikutnya.
+func (s *Store) SetColdStartPerDay(n int) error {
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if _, err := tx.Exec(`INSERT INTO settings (key, value) VALUES (?,?)
+		ON CONFLICT(key) DO UPDATE SET value=excluded.value, updated_at=CURRENT_TIMESTAMP`,
+		SettingColdStartPerDay, strconv.Itoa(n)); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`UPDATE groups SET ramp_at=NULL WHERE last_sent_at IS NULL AND ramp_at > ?`,
+		time.Now().UTC().Format(ctsLayout)); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// PlanColdStart memberi tanggal kiriman pertama (ramp_at) untuk grup aktif yang belum pernah
+// dikirimi dan belum direncanakan, per akun maksimal perDay grup per hari kuota mulai hari ini
+// (slot yang sudah terisi rencana sebelumnya ikut dihitung). Grup dipilih urut waktu import.
+// Mengembalikan jumlah grup yang baru direncanakan; perDay <= 0 tidak melakukan apa-apa.
+func (s *Store) PlanColdStart(perDay int, now time.Time) (int, error) {
+	if perDay <= 0 {
+		return 0, nil
+	}
+	today := s.Day.Start(now)
+	rows, err := s.DB.Query(`SELECT id, account_id FROM groups
+		WHERE enabled=1 AND is_test=0 AND community_excluded=0 AND last_sent_at IS NULL AND ramp_at IS NULL
+		ORDER BY created_at, id`)
+	if err != nil {
+		return 0, err
+	}
+	pending := map[string][]string{}
+	var accounts []string
+	for rows.Next() {
+		var id, acc string
+		if err := rows.Scan(&id, &acc); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		if _, ok := pending[acc]; !ok {
+			accounts = append(accounts, acc)
+		}
+		pending[acc] = append(pending[acc], id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+	if len(accounts) == 0 {
+		return 0, nil
+	}
+
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+	planned := 0
+	for _, acc := range accounts {
+		// Slot terpakai per hari dari rencana yang sudah ada (hari ini ke depan).
+		used := map[string]int{}
+		rrows, err := tx.Query(`SELECT ramp_at FROM groups WHERE account_id=? AND last_sent_at IS NULL AND ramp_at >= ?`,
+			acc, today.UTC().Format(ctsLayout))
+		if err != nil {
+			return 0, err
+		}
+		for rrows.Next() {
+			var at time.Time
+			if err := rrows.Scan(&at); err != nil {
+				rrows.Close()
+				return 0, err
+			}
+			used[s.Day.Start(at).UTC().Format(ctsLayout)]++
+		}
+		rrows.Close()
+		if err := rrows.Err(); err != nil {
+			return 0, err
+		}
+		day := today
+		for _, id := range pending[acc] {
+			for used[day.UTC().Format(ctsLayout)] >= perDay {
+				day = day.AddDate(0, 0, 1)
+			}
+			key := day.UTC().Format(ctsLayout)
+			if _, err := tx.Exec(`UPDATE groups SET ramp_at=? WHERE id=?`, key, id); err != nil {
+				return 0, err
+			}
+			used[key]++
+			planned++
+		}
+	}
+	return planned, tx.Commit()
+}
+
+// ColdStartPlan merangkum rencana ramp per akun: grup cold yang sudah jatuh tempo, yang
+// belum direncanakan, dan jumlah per hari ke depan. Akun tanpa grup cold tidak disertakan.
+func (s *Store) ColdStartPlan(now time.Time) ([]model.AccountRamp, error) {
+	rows, err := s.DB.Query(`SELECT g.account_id, COALESCE(a.label,''), g.ramp_at
+		FROM groups g JOIN accounts a ON a.id=g.account_id
+		WHERE g.enabled=1 AND g.is_test=0 AND g.community_excluded=0 AND g.last_sent_at IS NULL
+		ORDER BY a.created_at, g.ramp_at`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	today := s.Day.Start(now)
+	var list []model.AccountRamp
+	idx := map[string]int{}
+	for rows.Next() {
+		var acc, label string
+		var at *time.Time
+		if err := rows.Scan(&acc, &label, &at); err != nil {
+			return nil, err
+		}
+		i, ok := idx[acc]
+		if !ok {
+			i = len(list)
+			idx[acc] = i
+			list = append(list, model.AccountRamp{AccountID: acc, Label: label, Days: []model.RampDay{}})
+		}
+		r := &list[i]
+		switch {
+		case at == nil:
+			r.Unplanned++
+		case !at.After(now):
+			r.Due++
+		default:
+			date := s.Day.Start(*at).In(today.Location()).Format("2006-01-02")
+			if n := len(r.Days); n > 0 && r.Days[n-1].Date == date {
+				r.Days[n-1].Groups++
+			} else {
+				r.Days = append(r.Days, model.RampDay{Date: date, Groups: 1})
+			}
+			r.LastDate = date
+		}
+	}
+	return list, rows.Err()
+}
@@ -0,0 +1,73 @@
+package storage
+
+import (
+	"strconv"
+
+	"promote/internal/model"
+)
+
+// SettingCommunityAnnouncementsEligible: jika aktif, grup pengumuman komunitas ikut broadcast
+// (default dikecualikan). Grup induk komunitas selalu dikecualikan.
+const SettingCommunityAnnouncementsEligible = "community_announcements_eligible"
+
+// communityExcludedExpr ekspresi SQL nilai community_excluded untuk peran role (kolom atau "?");
+// parameter berikutnya = 1 jika grup pengumuman dikecualikan.
+func communityExcludedExpr(role string) string {
+	return `CASE ` + role + ` WHEN '` + model.GroupRoleParent + `' THEN 1 WHEN '` +
+		model.GroupRoleAnnouncement + `' THEN ? ELSE 0 END`
+}
+
+// SetGroupCommunity menyimpan peran komunitas grup (hasil sync) dan memperbarui pengecualian broadcast-nya.
+func (s *Store) SetGroupCommunity(groupID, role, parentJID string) error {
+	excludeAnnounce, err := s.communityExcludeAnnouncements()
+	if err != nil {
+		return err
+	}
+	_, err = s.DB.Exec(`UPDATE groups SET community_role=?, parent_jid=?, community_excluded=`+communityExcludedExpr("?")+` WHERE id=?`,
+		role, nullStr(parentJID), role, btoi(excludeAnnounce), groupID)
+	return err
+}
+
+func (s *Store) communityExcludeAnnouncements() (bool, error) {
+	on, err := s.SettingBool(SettingCommunityAnnouncementsEligible)
+	return !on, err
+}
+
+// SetCommunityAnnouncementsEligible mengubah setting lalu menghitung ulang pengecualian semua grup.
+func (s *Store) SetCommunityAnnouncementsEligible(on bool) error {
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if _, err := tx.Exec(`INSERT INTO settings (key, value) VALUES (?,?)
+		ON CONFLICT(key) DO UPDATE SET value=excluded.value, updated_at=CURRENT_TIMESTAMP`,
+		SettingCommunityAnnouncementsEligible, strconv.Itoa(btoi(on))); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`UPDATE groups SET community_excluded=`+communityExcludedExpr("community_role"), btoi(!on)); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// CommunityCounts jumlah grup per peran komunitas (tanpa grup biasa) dan yang dikecualikan.
+func (s *Store) CommunityCounts() (roles map[string]int, excluded int, err error) {
+	rows, err := s.DB.Query(`SELECT community_role, COUNT(*), SUM(community_excluded) FROM groups
+		WHERE left_at IS NULL AND community_role <> '' GROUP BY community_role`)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+	roles = map[string]int{}
+	for rows.Next() {
+		var role string
+		var n, ex int
+		if err := rows.Scan(&role, &n, &ex); err != nil {
+			return nil, 0, err
+		}
+		roles[role] = n
+		excluded += ex
+	}
+	return roles, excluded, rows.Err()
+}
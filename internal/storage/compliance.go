@@ -0,0 +1,91 @@
+package storage
+
+import (
+	"database/sql"
+	"time"
+
+	"promote/internal/model"
+)
+
+func (s *Store) ListComplianceRules() ([]model.ComplianceRule, error) {
+	rows, err := s.DB.Query(`SELECT id, phrase, action, enabled, created_at FROM compliance_rules ORDER BY phrase`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []model.ComplianceRule
+	for rows.Next() {
+		var r model.ComplianceRule
+		var enabled int
+		if err := rows.Scan(&r.ID, &r.Phrase, &r.Action, &enabled, &r.CreatedAt); err != nil {
+			return nil, err
+		}
+		r.Enabled = enabled == 1
+		out = append(out, r)
+	}
+	return out, rows.Err()
+}
+
+// UpsertComplianceRule menambah aturan atau memperbarui action/enabled jika frasa sudah ada.
+func (s *Store) UpsertComplianceRule(phrase, action string, enabled bool) (int64, error) {
+	_, err := s.DB.Exec(`INSERT INTO compliance_rules (phrase, action, enabled) VALUES (?,?,?)
+		ON CONFLICT(phrase) DO UPDATE SET action=excluded.action, enabled=excluded.enabled`,
+		phrase, action, btoi(enabled))
+	if err != nil {
+		return 0, err
+	}
+	var id int64
+	err = s.DB.QueryRow(`SELECT id FROM compliance_rules WHERE phrase=?`, phrase).Scan(&id)
+	return id, err
+}
+
+func (s *Store) DeleteComplianceRule(id int64) (int64, error) {
+	res, err := s.DB.Exec(`DELETE FROM compliance_rules WHERE id=?`, id)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// SaveGroupCompliance menyimpan deskripsi terbaru dan hasil scan. flag kosong = bersih.
+// pause=true sekaligus menonaktifkan grup.
+func (s *Store) SaveGroupCompliance(groupID, description, flag string, pause bool, at time.Time) error {
+	q := `UPDATE groups SET description=?, compliance_flag=?, compliance_checked_at=? WHERE id=?`
+	if pause {
+		q = `UPDATE groups SET description=?, compliance_flag=?, compliance_checked_at=?, enabled=0 WHERE id=?`
+	}
+	_, err := s.DB.Exec(q, description, nullStr(flag), at.UTC(), groupID)
+	return err
+}
+
+// ListFlaggedGroups mengembalikan grup yang deskripsinya cocok dengan aturan compliance.
+func (s *Store) ListFlaggedGroups(accountID string) ([]model.FlaggedGroup, error) {
+	q := `SELECT id, account_id, COALESCE(name,''), enabled, compliance_flag, COALESCE(description,''), compliance_checked_at
+		FROM groups WHERE COALESCE(compliance_flag,'') <> ''`
+	args := []any{}
+	if accountID != "" {
+		q += ` AND account_id=?`
+		args = append(args, accountID)
+	}
+	rows, err := s.DB.Query(q+` ORDER BY name`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []model.FlaggedGroup
+	for rows.Next() {
+		var g model.FlaggedGroup
+		var enabled int
+		var checked sql.NullTime
+		if err := rows.Scan(&g.GroupID, &g.AccountID, &g.Name, &enabled, &g.Phrase, &g.Description, &checked); err != nil {
+			return nil, err
+		}
+		g.Enabled = enabled == 1
+		if checked.Valid {
+			t := checked.Time
+			g.CheckedAt = &t
+		}
+		out = append(out, g)
+	}
+	return out, rows.Err()
+}
@@ -0,0 +1,548 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"promote/internal/model"
+)
+
+// ErrInvalidConfig dikembalikan jika spec konfigurasi tidak bisa diterapkan (nama ganda, campaign
+// atau akun yang dirujuk tidak ada di instance ini).
+var ErrInvalidConfig = errors.New("invalid config spec")
+
+// ConfigSpec konfigurasi deklaratif yang bisa dipindah antar instance (satu VPS per klien).
+// Bagian nil tidak disentuh; bagian yang diisi (termasuk list kosong) otoritatif: objek yang
+// tidak disebut dihapus. Template dicocokkan dengan nama, tag dengan nama, jadwal dengan
+// pasangan campaign + akun. Settings hanya mengubah kunci yang disebut; nilai nil menghapus
+// kunci (kembali ke default).
+type ConfigSpec struct {
+	Templates []model.Template
+	Tags      []string
+	Schedules []ScheduleSpec
+	Settings  map[string]*string
+}
+
+// ScheduleSpec jadwal dalam ConfigSpec. Campaign dirujuk dengan nama dan akun dengan ID,
+// "label:<label>" atau "msisdn:<nomor>" agar spec portabel; CampaignID/AccountID diabaikan.
+type ScheduleSpec struct {
+	Campaign string
+	Account  string
+	model.Schedule
+}
+
+// ConfigChanges nama objek yang dibuat, diubah, dan dihapus untuk satu jenis objek.
+type ConfigChanges struct {
+	Created []string `json:"created"`
+	Updated []string `json:"updated"`
+	Removed []string `json:"removed"`
+}
+
+func newConfigChanges() ConfigChanges {
+	return ConfigChanges{Created: []string{}, Updated: []string{}, Removed: []string{}}
+}
+
+// ConfigReport hasil ApplyConfig per jenis objek.
+type ConfigReport struct {
+	DryRun    bool          `json:"dry_run"`
+	Templates ConfigChanges `json:"templates"`
+	Tags      ConfigChanges `json:"tags"`
+	Schedules ConfigChanges `json:"schedules"`
+	Settings  ConfigChanges `json:"settings"`
+}
+
+// settingHooks efek samping setting yang biasanya dijalankan oleh setter khususnya, agar nilai
+// yang diterapkan lewat spec berlaku sama seperti diubah dari API.
+var settingHooks = map[string]func(tx *sql.Tx, value string) error{
+	SettingCommunityAnnouncementsEligible: func(tx *sql.Tx, value string) error {
+		_, err := tx.Exec(`UPDATE groups SET community_excluded=`+communityExcludedExpr("community_role"), btoi(!truthy(value)))
+		return err
+	},
+	SettingColdStartPerDay: func(tx *sql.Tx, _ string) error {
+		_, err := tx.Exec(`UPDATE groups SET ramp_at=NULL WHERE last_sent_at IS NULL AND ramp_at > ?`, time.Now().UTC().Format(ctsLayout))
+		return err
+	},
+}
+
+// secretSettings setting berisi rahasia yang tidak ikut ExportConfig; apply yang tidak
+// menyebutnya membiarkan nilai di instance tujuan.
+var secretSettings = map[string]bool{SettingTelegramMirror: true}
+
+// ExportConfig membaca konfigurasi saat ini dalam bentuk ConfigSpec (semua bagian terisi,
+// kecuali secretSettings).
+func (s *Store) ExportConfig(ctx context.Context) (*ConfigSpec, error) {
+	tx, err := s.DB.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	spec := &ConfigSpec{Templates: []model.Template{}, Tags: []string{}, Schedules: []ScheduleSpec{}, Settings: map[string]*string{}}
+	rows, err := tx.QueryContext(ctx, `SELECT `+templateCols+` FROM templates ORDER BY name, created_at, id`)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		t, err := scanTemplate(rows)
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+		spec.Templates = append(spec.Templates, t)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if spec.Tags, err = configTags(tx); err != nil {
+		return nil, err
+	}
+
+	refs, err := configAccountRefs(tx)
+	if err != nil {
+		return nil, err
+	}
+	campaigns, err := configCampaignNames(tx)
+	if err != nil {
+		return nil, err
+	}
+	scheds, err := configSchedules(tx)
+	if err != nil {
+		return nil, err
+	}
+	for _, sch := range scheds {
+		spec.Schedules = append(spec.Schedules, ScheduleSpec{Campaign: campaigns[sch.CampaignID], Account: refs[sch.AccountID], Schedule: sch})
+	}
+
+	rows, err = tx.QueryContext(ctx, `SELECT key, value FROM settings ORDER BY key`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var k, v string
+		if err := rows.Scan(&k, &v); err != nil {
+			return nil, err
+		}
+		if secretSettings[k] {
+			continue
+		}
+		spec.Settings[k] = &v
+	}
+	return spec, rows.Err()
+}
+
+// ApplyConfig merekonsiliasi DB agar cocok dengan spec dalam satu transaksi dan melaporkan
+// perubahannya. dryRun menjalankan hal yang sama lalu membatalkan transaksi. Referensi yang
+// tidak valid menghasilkan ErrInvalidConfig sebelum ada yang diubah.
+func (s *Store) ApplyConfig(ctx context.Context, spec ConfigSpec, dryRun bool) (ConfigReport, error) {
+	rep := ConfigReport{DryRun: dryRun, Templates: newConfigChanges(), Tags: newConfigChanges(),
+		Schedules: newConfigChanges(), Settings: newConfigChanges()}
+	if err := checkTemplateNames(spec.Templates); err != nil {
+		return rep, err
+	}
+	scheds, err := s.resolveScheduleSpecs(spec.Schedules)
+	if err != nil {
+		return rep, err
+	}
+
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return rep, err
+	}
+	defer tx.Rollback()
+	if spec.Tags != nil {
+		if err := applyTags(tx, normalizeTags(spec.Tags), &rep.Tags); err != nil {
+			return rep, fmt.Errorf("tags: %w", err)
+		}
+	}
+	if spec.Templates != nil {
+		if err := applyTemplates(tx, spec.Templates, &rep.Templates); err != nil {
+			return rep, fmt.Errorf("templates: %w", err)
+		}
+	}
+	if spec.Schedules != nil {
+		if err := applySchedules(tx, scheds, &rep.Schedules); err != nil {
+			return rep, fmt.Errorf("schedules: %w", err)
+		}
+	}
+	if err := applySettings(tx, spec.Settings, &rep.Settings); err != nil {
+		return rep, fmt.Errorf("settings: %w", err)
+	}
+	if dryRun {
+		return rep, nil
+	}
+	return rep, tx.Commit()
+}
+
+func checkTemplateNames(list []model.Template) error {
+	seen := map[string]bool{}
+	for i, t := range list {
+		name := strings.TrimSpace(t.Name)
+		if name == "" {
+			return fmt.Errorf("%w: templates[%d] has no name", ErrInvalidConfig, i)
+		}
+		if seen[name] {
+			return fmt.Errorf("%w: duplicate template %q", ErrInvalidConfig, name)
+		}
+		seen[name] = true
+	}
+	return nil
+}
+
+// resolveScheduleSpecs mengisi CampaignID/AccountID dari referensi nama campaign dan akun.
+func (s *Store) resolveScheduleSpecs(list []ScheduleSpec) ([]ScheduleSpec, error) {
+	out := make([]ScheduleSpec, 0, len(list))
+	seen := map[string]bool{}
+	for _, sp := range list {
+		label := sp.Campaign + " @ " + sp.Account
+		var ids []string
+		rows, err := s.DB.Query(`SELECT id FROM campaigns WHERE name=?`, strings.TrimSpace(sp.Campaign))
+		if err == nil {
+			ids, err = scanIDs(rows)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(ids) != 1 {
+			return nil, fmt.Errorf("%w: schedule %s: campaign matches %d campaigns", ErrInvalidConfig, label, len(ids))
+		}
+		accountID, err := s.ResolveAccountRef(strings.TrimSpace(sp.Account))
+		if errors.Is(err, ErrAccountNotFound) || errors.Is(err, ErrAccountAmbiguous) {
+			return nil, fmt.Errorf("%w: schedule %s: %v", ErrInvalidConfig, label, err)
+		}
+		if err != nil {
+			return nil, err
+		}
+		ok, err := s.AccountExists(accountID)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, fmt.Errorf("%w: schedule %s: %v", ErrInvalidConfig, label, ErrAccountNotFound)
+		}
+		sp.CampaignID, sp.AccountID = ids[0], accountID
+		key := sp.CampaignID + "|" + sp.AccountID
+		if seen[key] {
+			return nil, fmt.Errorf("%w: duplicate schedule %s", ErrInvalidConfig, label)
+		}
+		seen[key] = true
+		out = append(out, sp)
+	}
+	return out, nil
+}
+
+func configTags(tx *sql.Tx) ([]string, error) {
+	rows, err := tx.Query(`SELECT name FROM tags ORDER BY name`)
+	if err != nil {
+		return nil, err
+	}
+	names, err := scanIDs(rows)
+	if names == nil {
+		names = []string{}
+	}
+	return names, err
+}
+
+func applyTags(tx *sql.Tx, want []string, ch *ConfigChanges) error {
+	have, err := configTags(tx)
+	if err != nil {
+		return err
+	}
+	keep := map[string]bool{}
+	for _, t := range have {
+		keep[t] = false
+	}
+	for _, t := range want {
+		if _, ok := keep[t]; ok {
+			keep[t] = true
+			continue
+		}
+		if _, err := tx.Exec(`INSERT INTO tags (name) VALUES (?)`, t); err != nil {
+			return err
+		}
+		ch.Created = append(ch.Created, t)
+	}
+	for _, t := range have {
+		if keep[t] {
+			continue
+		}
+		if _, err := tx.Exec(`DELETE FROM group_tags WHERE tag=?`, t); err != nil {
+			return err
+		}
+		if _, err := tx.Exec(`DELETE FROM tags WHERE name=?`, t); err != nil {
+			return err
+		}
+		ch.Removed = append(ch.Removed, t)
+	}
+	return nil
+}
+
+// applyTemplates mencocokkan template dengan nama; template bernama sama di DB selain yang
+// tertua dianggap tidak disebut spec dan ikut dihapus.
+func applyTemplates(tx *sql.Tx, want []model.Template, ch *ConfigChanges) error {
+	rows, err := tx.Query(`SELECT ` + templateCols + ` FROM templates ORDER BY created_at, id`)
+	if err != nil {
+		return err
+	}
+	byName := map[string]model.Template{}
+	var extra []model.Template
+	for rows.Next() {
+		t, err := scanTemplate(rows)
+		if err != nil {
+			rows.Close()
+			return err
+		}
+		if _, dup := byName[t.Name]; dup {
+			extra = append(extra, t)
+			continue
+		}
+		byName[t.Name] = t
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, t := range want {
+		t.Name = strings.TrimSpace(t.Name)
+		cur, ok := byName[t.Name]
+		delete(byName, t.Name)
+		switch {
+		case !ok:
+			if err := insertTemplate(tx, uuid.NewString(), t); err != nil {
+				return err
+			}
+			ch.Created = append(ch.Created, t.Name)
+		case !sameTemplateContent(cur, t):
+			t.ID = cur.ID
+			if err := updateTemplate(tx, t); err != nil {
+				return err
+			}
+			ch.Updated = append(ch.Updated, t.Name)
+		}
+	}
+	for _, t := range byName {
+		extra = append(extra, t)
+	}
+	sort.Slice(extra, func(i, j int) bool { return extra[i].Name < extra[j].Name })
+	for _, t := range extra {
+		if _, err := tx.Exec(`DELETE FROM templates WHERE id=?`, t.ID); err != nil {
+			return err
+		}
+		ch.Removed = append(ch.Removed, t.Name)
+	}
+	return nil
+}
+
+// sameTemplateContent membandingkan isi yang bisa diatur lewat spec (tanpa ID, versi, health, lint).
+func sameTemplateContent(a, b model.Template) bool {
+	return reflect.DeepEqual(templateContent(a), templateContent(b))
+}
+
+func templateContent(t model.Template) model.Template {
+	t.ID, t.Version, t.HealthError, t.Lint = "", 0, "", nil
+	t.CreatedAt, t.UpdatedAt = time.Time{}, time.Time{}
+	if t.MediaFailPolicy == "" {
+		t.MediaFailPolicy = "abort"
+	}
+	for _, l := range []*[]string{&t.ImageURLs, &t.VideoURLs, &t.GifURLs, &t.AudioURLs, &t.VoiceURLs, &t.StickerURLs, &t.DocURLs} {
+		if len(*l) == 0 {
+			*l = nil
+		}
+	}
+	if len(t.Products) == 0 {
+		t.Products = nil
+	}
+	t.Languages = normalizeTags(t.Languages)
+	if len(t.Languages) == 0 {
+		t.Languages = nil
+	}
+	return t
+}
+
+func configSchedules(tx *sql.Tx) ([]model.Schedule, error) {
+	rows, err := tx.Query(`SELECT ` + scheduleCols + ` FROM schedules ORDER BY created_at, id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var list []model.Schedule
+	for rows.Next() {
+		sch, err := scanSchedule(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, sch)
+	}
+	return list, rows.Err()
+}
+
+// configAccountRefs referensi portabel tiap akun: label, nomor, atau ID jika keduanya kosong.
+func configAccountRefs(tx *sql.Tx) (map[string]string, error) {
+	rows, err := tx.Query(`SELECT id, COALESCE(label,''), COALESCE(msisdn,'') FROM accounts`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := map[string]string{}
+	for rows.Next() {
+		var id, label, msisdn string
+		if err := rows.Scan(&id, &label, &msisdn); err != nil {
+			return nil, err
+		}
+		switch {
+		case strings.TrimSpace(label) != "":
+			out[id] = AccountRefLabel + label
+		case digitsOnly(msisdn) != "":
+			out[id] = AccountRefMsisdn + digitsOnly(msisdn)
+		default:
+			out[id] = id
+		}
+	}
+	return out, rows.Err()
+}
+
+func configCampaignNames(tx *sql.Tx) (map[string]string, error) {
+	rows, err := tx.Query(`SELECT id, name FROM campaigns`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := map[string]string{}
+	for rows.Next() {
+		var id, name string
+		if err := rows.Scan(&id, &name); err != nil {
+			return nil, err
+		}
+		out[id] = name
+	}
+	return out, rows.Err()
+}
+
+// applySchedules mencocokkan jadwal dengan pasangan campaign + akun; duplikat di DB dihapus.
+func applySchedules(tx *sql.Tx, want []ScheduleSpec, ch *ConfigChanges) error {
+	have, err := configSchedules(tx)
+	if err != nil {
+		return err
+	}
+	refs, err := configAccountRefs(tx)
+	if err != nil {
+		return err
+	}
+	campaigns, err := configCampaignNames(tx)
+	if err != nil {
+		return err
+	}
+	byKey := map[string]model.Schedule{}
+	var extra []model.Schedule
+	for _, sch := range have {
+		key := sch.CampaignID + "|" + sch.AccountID
+		if _, dup := byKey[key]; dup {
+			extra = append(extra, sch)
+			continue
+		}
+		byKey[key] = sch
+	}
+
+	now := time.Now().UTC()
+	for _, sp := range want {
+		sch := sp.Schedule
+		sch.CampaignID, sch.AccountID = sp.CampaignID, sp.AccountID
+		name := campaigns[sch.CampaignID] + " @ " + refs[sch.AccountID]
+		key := sch.CampaignID + "|" + sch.AccountID
+		cur, ok := byKey[key]
+		delete(byKey, key)
+		switch {
+		case !ok:
+			if _, err := tx.Exec(`INSERT INTO schedules (id, campaign_id, account_id, batch_size, start_hour, end_hour,
+				min_delay_sec, max_delay_sec, days_mask, daily_limit, enabled, created_at, updated_at)
+				VALUES (?,?,?,?,?,?,?,?,?,?,?,?,?)`,
+				uuid.NewString(), sch.CampaignID, sch.AccountID, sch.BatchSize, sch.StartHour, sch.EndHour, sch.MinDelaySec, sch.MaxDelaySec,
+				nullStr(sch.DaysMask), sch.DailyLimit, btoi(sch.Enabled), now, now); err != nil {
+				return err
+			}
+			ch.Created = append(ch.Created, name)
+		case !sameSchedule(cur, sch):
+			if _, err := tx.Exec(`UPDATE schedules SET batch_size=?, start_hour=?, end_hour=?, min_delay_sec=?, max_delay_sec=?,
+				days_mask=?, daily_limit=?, enabled=?, updated_at=? WHERE id=?`,
+				sch.BatchSize, sch.StartHour, sch.EndHour, sch.MinDelaySec, sch.MaxDelaySec,
+				nullStr(sch.DaysMask), sch.DailyLimit, btoi(sch.Enabled), now, cur.ID); err != nil {
+				return err
+			}
+			ch.Updated = append(ch.Updated, name)
+		}
+	}
+	for _, sch := range byKey {
+		extra = append(extra, sch)
+	}
+	for _, sch := range extra {
+		if _, err := tx.Exec(`DELETE FROM schedules WHERE id=?`, sch.ID); err != nil {
+			return err
+		}
+		ch.Removed = append(ch.Removed, campaigns[sch.CampaignID]+" @ "+refs[sch.AccountID])
+	}
+	sort.Strings(ch.Removed)
+	return nil
+}
+
+func sameSchedule(a, b model.Schedule) bool {
+	return a.BatchSize == b.BatchSize && a.StartHour == b.StartHour && a.EndHour == b.EndHour &&
+		a.MinDelaySec == b.MinDelaySec && a.MaxDelaySec == b.MaxDelaySec && a.DaysMask == b.DaysMask &&
+		a.DailyLimit == b.DailyLimit && a.Enabled == b.Enabled
+}
+
+func applySettings(tx *sql.Tx, want map[string]*string, ch *ConfigChanges) error {
+	keys := make([]string, 0, len(want))
+	for k := range want {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		var cur string
+		err := tx.QueryRow(`SELECT value FROM settings WHERE key=?`, k).Scan(&cur)
+		exists := err == nil
+		if err != nil && err != sql.ErrNoRows {
+			return err
+		}
+		v := want[k]
+		switch {
+		case v == nil && !exists, v != nil && exists && *v == cur:
+			continue
+		case v == nil:
+			if _, err := tx.Exec(`DELETE FROM settings WHERE key=?`, k); err != nil {
+				return err
+			}
+			ch.Removed = append(ch.Removed, k)
+		default:
+			if _, err := tx.Exec(`INSERT INTO settings (key, value) VALUES (?,?)
+				ON CONFLICT(key) DO UPDATE SET value=excluded.value, updated_at=CURRENT_TIMESTAMP`, k, *v); err != nil {
+				return err
+			}
+			if exists {
+				ch.Updated = append(ch.Updated, k)
+			} else {
+				ch.Created = append(ch.Created, k)
+			}
+		}
+		if hook := settingHooks[k]; hook != nil {
+			val := ""
+			if v != nil {
+				val = *v
+			}
+			if err := hook(tx, val); err != nil {
+				return fmt.Errorf("%s: %w", k, err)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+package storage
+
+import "promote/internal/model"
+
+// CrossPromoSource link undangan grup yang dipromosikan beserta nama dan jumlah anggotanya.
+// Bila link belum pernah dicek (member_count 0), jumlah anggota diambil dari data grup.
+func (s *Store) CrossPromoSource(groupID string) (model.GroupLink, error) {
+	l, err := s.GetGroupLink(groupID)
+	if err != nil || l.MemberCount > 0 {
+		return l, err
+	}
+	err = s.DB.QueryRow(`SELECT COALESCE(participant_count,0) FROM groups WHERE id=?`, groupID).Scan(&l.MemberCount)
+	return l, err
+}
+
+// RecordCrossPromo mencatat bahwa promotedGroupID sudah dipromosikan di targetGroupID;
+// pasangan yang sudah tercatat tidak diubah.
+func (s *Store) RecordCrossPromo(promotedGroupID, targetGroupID, campaignID, accountID string) error {
+	_, err := s.DB.Exec(`INSERT OR IGNORE INTO cross_promos (promoted_group_id, target_group_id, campaign_id, account_id)
+		VALUES (?,?,?,?)`, promotedGroupID, targetGroupID, nullStr(campaignID), accountID)
+	return err
+}
+
+// ListCrossPromos pasangan cross-promo yang sudah terkirim untuk grup yang dipromosikan, terbaru dulu.
+func (s *Store) ListCrossPromos(promotedGroupID string, limit int) ([]model.CrossPromo, error) {
+	rows, err := s.DB.Query(`SELECT c.promoted_group_id, c.target_group_id, COALESCE(g.name,''), COALESCE(c.campaign_id,''),
+		c.account_id, c.sent_at
+		FROM cross_promos c LEFT JOIN groups g ON g.id=c.target_group_id
+		WHERE c.promoted_group_id=? ORDER BY c.sent_at DESC LIMIT ?`, promotedGroupID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []model.CrossPromo
+	for rows.Next() {
+		var p model.CrossPromo
+		if err := rows.Scan(&p.PromotedGroupID, &p.TargetGroupID, &p.TargetName, &p.CampaignID,
+			&p.AccountID, &p.SentAt); err != nil {
+			return nil, err
+		}
+		out = append(out, p)
+	}
+	return out, rows.Err()
+}
@@ -0,0 +1,68 @@
+package storage
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DayBoundary menentukan kapan "hari" berganti untuk limit harian, statistik hari ini, dan
+// hitungan kuota. Default tengah malam WIB (bukan tengah malam UTC = 07:00 WIB) agar satu
+// jendela malam tidak terbelah ke dua hari.
+type DayBoundary struct {
+	Loc *time.Location
+	// ResetHour jam lokal saat hari berganti (0–23).
+	ResetHour int
+	// Now sumber waktu "sekarang" (nil = time.Now); diganti jam palsu di test.
+	Now func() time.Time
+}
+
+// DayBoundaryFromEnv membaca DAY_RESET_TZ (default Asia/Jakarta) dan DAY_RESET_HOUR (default 0).
+func DayBoundaryFromEnv() DayBoundary {
+	d := DayBoundary{}
+	name := strings.TrimSpace(os.Getenv("DAY_RESET_TZ"))
+	if name == "" {
+		name = "Asia/Jakarta"
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil || loc == nil {
+		loc = time.FixedZone("WIB", 7*3600)
+	}
+	d.Loc = loc
+	if n, err := strconv.Atoi(strings.TrimSpace(os.Getenv("DAY_RESET_HOUR"))); err == nil && n >= 0 && n < 24 {
+		d.ResetHour = n
+	}
+	return d
+}
+
+// Start mengembalikan awal hari kuota yang memuat t.
+func (d DayBoundary) Start(t time.Time) time.Time {
+	loc := d.Loc
+	if loc == nil {
+		loc = time.UTC
+	}
+	lt := t.In(loc)
+	start := time.Date(lt.Year(), lt.Month(), lt.Day(), d.ResetHour, 0, 0, 0, loc)
+	if lt.Before(start) {
+		start = start.AddDate(0, 0, -1)
+	}
+	return start
+}
+
+// Today mengembalikan rentang [from, to) hari kuota saat ini.
+func (d DayBoundary) Today() (from, to time.Time) {
+	now := time.Now
+	if d.Now != nil {
+		now = d.Now
+	}
+	from = d.Start(now())
+	return from, from.AddDate(0, 0, 1)
+}
+
+// TodayArgs mengembalikan rentang hari kuota saat ini dalam format CURRENT_TIMESTAMP (UTC),
+// untuk dibandingkan dengan kolom default-timestamp seperti logs.ts: ts >= ? AND ts < ?.
+func (s *Store) TodayArgs() (from, to string) {
+	f, t := s.Day.Today()
+	return f.UTC().Format(ctsLayout), t.UTC().Format(ctsLayout)
+}
@@ -0,0 +1,115 @@
+package storage
+
+import (
+	"time"
+
+	"promote/internal/model"
+)
+
+// InsertAccountEvent mencatat perubahan status akun (dipakai untuk hitung insiden).
+func (s *Store) InsertAccountEvent(accountID, status, msg string) error {
+	_, err := s.DB.Exec(`INSERT INTO account_events (account_id, status, message) VALUES (?,?,?)`, accountID, status, nullStr(msg))
+	return err
+}
+
+// BuildDigests menghitung rekap per akun untuk rentang [from, to) tanpa menyimpannya.
+// day adalah label hari (YYYY-MM-DD) yang dipasang di hasil.
+func (s *Store) BuildDigests(day string, from, to time.Time) ([]model.Digest, error) {
+	// logs/auto_join_logs/account_events memakai CURRENT_TIMESTAMP (UTC, "YYYY-MM-DD HH:MM:SS")
+	const layout = "2006-01-02 15:04:05"
+	f, t := from.UTC().Format(layout), to.UTC().Format(layout)
+	rows, err := s.DB.Query(`
+		SELECT a.id, a.label, a.daily_limit,
+			(SELECT COUNT(*) FROM logs WHERE account_id=a.id AND status='sent' AND ts >= ? AND ts < ?),
+			(SELECT COUNT(*) FROM logs WHERE account_id=a.id AND status='failed' AND ts >= ? AND ts < ?),
+			(SELECT COUNT(*) FROM account_events WHERE account_id=a.id AND status IN ('logged_out','replaced') AND ts >= ? AND ts < ?),
+			(SELECT COUNT(*) FROM auto_join_logs WHERE account_id=a.id AND status='joined' AND joined_at >= ? AND joined_at < ?)
+		FROM accounts a ORDER BY a.created_at`, f, t, f, t, f, t, f, t)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var list []model.Digest
+	for rows.Next() {
+		d := model.Digest{Day: day}
+		if err := rows.Scan(&d.AccountID, &d.Label, &d.DailyLimit, &d.Sent, &d.Failed, &d.Incidents, &d.Joins); err != nil {
+			return nil, err
+		}
+		if d.DailyLimit <= 0 {
+			d.DailyLimit = 100
+		}
+		d.Utilization = float64(d.Sent) / float64(d.DailyLimit)
+		list = append(list, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	// message_acks.sent_at disimpan sebagai time.Time Go, jadi dibandingkan dengan parameter time.Time.
+	bounced := map[string]int64{}
+	brows, err := s.DB.Query(`SELECT account_id, COUNT(*) FROM message_acks WHERE status=? AND sent_at >= ? AND sent_at < ? GROUP BY account_id`,
+		AckSoftBounce, from.UTC(), to.UTC())
+	if err != nil {
+		return nil, err
+	}
+	defer brows.Close()
+	for brows.Next() {
+		var acc string
+		var n int64
+		if err := brows.Scan(&acc, &n); err != nil {
+			return nil, err
+		}
+		bounced[acc] = n
+	}
+	if err := brows.Err(); err != nil {
+		return nil, err
+	}
+	for i := range list {
+		list[i].SoftBounced = bounced[list[i].AccountID]
+	}
+	return list, nil
+}
+
+// SaveDigest menyimpan digest (menimpa digest akun+hari yang sama).
+func (s *Store) SaveDigest(d model.Digest) error {
+	_, err := s.DB.Exec(`INSERT INTO digests (account_id, day, sent, failed, soft_bounced, daily_limit, utilization, incidents, joins)
+		VALUES (?,?,?,?,?,?,?,?,?)
+		ON CONFLICT(account_id, day) DO UPDATE SET sent=excluded.sent, failed=excluded.failed, soft_bounced=excluded.soft_bounced,
+			daily_limit=excluded.daily_limit, utilization=excluded.utilization, incidents=excluded.incidents, joins=excluded.joins,
+			created_at=CURRENT_TIMESTAMP`,
+		d.AccountID, d.Day, d.Sent, d.Failed, d.SoftBounced, d.DailyLimit, d.Utilization, d.Incidents, d.Joins)
+	return err
+}
+
+// HasDigest true jika digest untuk hari tsb sudah pernah dibuat (akun mana pun).
+func (s *Store) HasDigest(day string) (bool, error) {
+	var n int
+	err := s.DB.QueryRow(`SELECT COUNT(*) FROM digests WHERE day=?`, day).Scan(&n)
+	return n > 0, err
+}
+
+// ListDigests mengembalikan digest terbaru dulu; filter opsional per hari dan akun.
+func (s *Store) ListDigests(day, accountID string, limit int) ([]model.Digest, error) {
+	if limit <= 0 {
+		limit = 100
+	}
+	rows, err := s.DB.Query(`
+		SELECT d.id, d.account_id, COALESCE(a.label,''), d.day, d.sent, d.failed, d.soft_bounced, d.daily_limit,
+			d.utilization, d.incidents, d.joins, d.created_at
+		FROM digests d LEFT JOIN accounts a ON a.id = d.account_id
+		WHERE (?='' OR d.day=?) AND (?='' OR d.account_id=?)
+		ORDER BY d.day DESC, d.account_id LIMIT ?`, day, day, accountID, accountID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var list []model.Digest
+	for rows.Next() {
+		var d model.Digest
+		if err := rows.Scan(&d.ID, &d.AccountID, &d.Label, &d.Day, &d.Sent, &d.Failed, &d.SoftBounced, &d.DailyLimit,
+			&d.Utilization, &d.Incidents, &d.Joins, &d.CreatedAt); err != nil {
+			return nil, err
+		}
+		list = append(list, d)
+	}
+	return list, rows.Err()
+}
@@ -0,0 +1,187 @@
+package storage
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"promote/internal/model"
+)
+
+// ErrDMRunNotFound dikembalikan jika snapshot preflight tidak ada.
+var ErrDMRunNotFound = errors.New("dm run not found")
+
+// NormalizeNumber mengubah nomor/JID (0812..., +62 812-..., 62812@s.whatsapp.net) menjadi
+// digit internasional (62812...). Mengembalikan "" jika bukan nomor valid.
+func NormalizeNumber(s string) string {
+	s = strings.TrimSpace(s)
+	if i := strings.IndexAny(s, "@:"); i >= 0 {
+		s = s[:i]
+	}
+	s = strings.NewReplacer("+", "", " ", "", "-", "", "(", "", ")", "").Replace(s)
+	if strings.HasPrefix(s, "0") {
+		s = "62" + s[1:]
+	}
+	if len(s) < 8 || len(s) > 15 {
+		return ""
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return ""
+		}
+	}
+	return s
+}
+
+// ListDMSuppressions mengembalikan semua nomor yang disupresi, terbaru dulu.
+func (s *Store) ListDMSuppressions() ([]model.DMSuppression, error) {
+	rows, err := s.DB.Query(`SELECT number, COALESCE(reason,''), source, created_at FROM dm_suppressions ORDER BY created_at DESC`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var list []model.DMSuppression
+	for rows.Next() {
+		var d model.DMSuppression
+		if err := rows.Scan(&d.Number, &d.Reason, &d.Source, &d.CreatedAt); err != nil {
+			return nil, err
+		}
+		list = append(list, d)
+	}
+	return list, rows.Err()
+}
+
+// AddDMSuppression menambah/memperbarui nomor di daftar supresi; source manual|stop_reply.
+func (s *Store) AddDMSuppression(number, reason, source string) error {
+	_, err := s.DB.Exec(`INSERT INTO dm_suppressions (number, reason, source, created_at) VALUES (?,?,?,?)
+		ON CONFLICT(number) DO UPDATE SET reason=excluded.reason, source=excluded.source`,
+		number, nullStr(reason), source, time.Now().UTC())
+	return err
+}
+
+// RemoveDMSuppression menghapus nomor dari daftar supresi; false jika tidak ada.
+func (s *Store) RemoveDMSuppression(number string) (bool, error) {
+	res, err := s.DB.Exec(`DELETE FROM dm_suppressions WHERE number=?`, number)
+	if err != nil {
+		return false, err
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
+
+// DMSuppressedSet mengembalikan nomor-nomor yang ada di daftar supresi.
+func (s *Store) DMSuppressedSet() (map[string]bool, error) {
+	return s.numberSet(`SELECT number FROM dm_suppressions`)
+}
+
+// RecordDMSend mencatat hasil satu kiriman DM (status sent|failed) untuk riwayat preflight.
+func (s *Store) RecordDMSend(accountID, number, campaignID, status, errMsg string) error {
+	_, err := s.DB.Exec(`INSERT INTO dm_sends (account_id, number, campaign_id, status, error) VALUES (?,?,?,?,?)`,
+		nullStr(accountID), number, nullStr(campaignID), status, nullStr(errMsg))
+	return err
+}
+
+// RecentlyFailedDMs mengembalikan nomor yang DM terakhirnya sejak since gagal.
+func (s *Store) RecentlyFailedDMs(since time.Time) (map[string]bool, error) {
+	return s.numberSet(`SELECT d.number FROM dm_sends d
+		WHERE d.ts >= ? AND d.status='failed'
+		  AND d.id = (SELECT MAX(id) FROM dm_sends x WHERE x.number=d.number)`, since.UTC().Format(ctsLayout))
+}
+
+// GroupParticipantNumbers mengembalikan nomor anggota (dari cache) grup-grup yang diberikan.
+func (s *Store) GroupParticipantNumbers(groupIDs []string) ([]string, error) {
+	var out []string
+	for _, gid := range groupIDs {
+		rows, err := s.DB.Query(`SELECT number FROM group_participants WHERE group_id=?`, gid)
+		if err != nil {
+			return nil, err
+		}
+		for rows.Next() {
+			var n string
+			if err := rows.Scan(&n); err != nil {
+				rows.Close()
+				return nil, err
+			}
+			out = append(out, n)
+		}
+		err = rows.Err()
+		rows.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+func (s *Store) numberSet(q string, args ...any) (map[string]bool, error) {
+	rows, err := s.DB.Query(q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	set := map[string]bool{}
+	for rows.Next() {
+		var n string
+		if err := rows.Scan(&n); err != nil {
+			return nil, err
+		}
+		set[n] = true
+	}
+	return set, rows.Err()
+}
+
+// SaveDMRun menyimpan snapshot audiens hasil preflight dan mengisi ID serta CreatedAt.
+func (s *Store) SaveDMRun(run *model.DMRun) error {
+	run.ID = uuid.NewString()
+	run.CreatedAt = time.Now().UTC()
+	audience, _ := json.Marshal(run.Audience)
+	removed, _ := json.Marshal(run.Removed)
+	_, err := s.DB.Exec(`INSERT INTO dm_runs (id, campaign_id, account_id, input_count, audience, removed, created_at)
+		VALUES (?,?,?,?,?,?,?)`, run.ID, run.CampaignID, run.AccountID, run.InputCount, string(audience), string(removed), run.CreatedAt)
+	return err
+}
+
+const dmRunCols = `id, campaign_id, account_id, input_count, audience, removed, created_at`
+
+func scanDMRun(sc interface{ Scan(...any) error }) (model.DMRun, error) {
+	var r model.DMRun
+	var audience, removed string
+	if err := sc.Scan(&r.ID, &r.CampaignID, &r.AccountID, &r.InputCount, &audience, &removed, &r.CreatedAt); err != nil {
+		return r, err
+	}
+	r.Audience = jsonList(audience)
+	r.Removed = map[string][]string{}
+	_ = json.Unmarshal([]byte(removed), &r.Removed)
+	return r, nil
+}
+
+// ListDMRuns mengembalikan snapshot preflight sebuah campaign, terbaru dulu.
+func (s *Store) ListDMRuns(campaignID string) ([]model.DMRun, error) {
+	rows, err := s.DB.Query(`SELECT `+dmRunCols+` FROM dm_runs WHERE campaign_id=? ORDER BY created_at DESC`, campaignID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var list []model.DMRun
+	for rows.Next() {
+		r, err := scanDMRun(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, r)
+	}
+	return list, rows.Err()
+}
+
+// GetDMRun mengambil satu snapshot; ErrDMRunNotFound jika tidak ada.
+func (s *Store) GetDMRun(id string) (model.DMRun, error) {
+	r, err := scanDMRun(s.DB.QueryRow(`SELECT `+dmRunCols+` FROM dm_runs WHERE id=?`, id))
+	if err == sql.ErrNoRows {
+		return r, ErrDMRunNotFound
+	}
+	return r, err
+}
@@ -0,0 +1,155 @@
+package storage
+
+import (
+	"database/sql"
+	"time"
+
+	"promote/internal/model"
+)
+
+const dmTargetCols = `id, campaign_id, account_id, number, COALESCE(run_id,''), status, COALESCE(error,''), created_at, sent_at`
+
+func scanDMTarget(sc interface{ Scan(...any) error }) (model.DMTarget, error) {
+	var t model.DMTarget
+	var sentAt sql.NullTime
+	err := sc.Scan(&t.ID, &t.CampaignID, &t.AccountID, &t.Number, &t.RunID, &t.Status, &t.Error, &t.CreatedAt, &sentAt)
+	if sentAt.Valid {
+		t.SentAt = &sentAt.Time
+	}
+	return t, err
+}
+
+// AddDMTargets mengantrekan nomor untuk campaign DM lewat akun; nomor yang sudah ada di campaign
+// dilewati. Mengembalikan jumlah nomor yang benar-benar ditambahkan.
+func (s *Store) AddDMTargets(campaignID, accountID, runID string, numbers []string) (int, error) {
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+	added := 0
+	for _, n := range numbers {
+		res, err := tx.Exec(`INSERT OR IGNORE INTO dm_targets (campaign_id, account_id, number, run_id) VALUES (?,?,?,?)`,
+			campaignID, accountID, n, nullStr(runID))
+		if err != nil {
+			return 0, err
+		}
+		if k, _ := res.RowsAffected(); k > 0 {
+			added++
+		}
+	}
+	return added, tx.Commit()
+}
+
+// ListDMTargets mengembalikan target DM campaign (opsional difilter status), terlama dulu.
+func (s *Store) ListDMTargets(campaignID, status string, limit int) ([]model.DMTarget, error) {
+	rows, err := s.DB.Query(`SELECT `+dmTargetCols+` FROM dm_targets
+		WHERE campaign_id=? AND (?='' OR status=?) ORDER BY id LIMIT ?`, campaignID, status, status, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var list []model.DMTarget
+	for rows.Next() {
+		t, err := scanDMTarget(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, t)
+	}
+	return list, rows.Err()
+}
+
+// DMTargetCounts jumlah target DM campaign per status.
+func (s *Store) DMTargetCounts(campaignID string) (map[string]int, error) {
+	rows, err := s.DB.Query(`SELECT status, COUNT(*) FROM dm_targets WHERE campaign_id=? GROUP BY status`, campaignID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := map[string]int{}
+	for rows.Next() {
+		var st string
+		var n int
+		if err := rows.Scan(&st, &n); err != nil {
+			return nil, err
+		}
+		out[st] = n
+	}
+	return out, rows.Err()
+}
+
+// CancelDMTargets menandai semua target pending campaign sebagai skipped (dibatalkan).
+func (s *Store) CancelDMTargets(campaignID string) (int64, error) {
+	res, err := s.DB.Exec(`UPDATE dm_targets SET status=?, error='cancelled' WHERE campaign_id=? AND status=?`,
+		model.DMTargetSkipped, campaignID, model.DMTargetPending)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// DMPendingAccounts mengembalikan akun aktif (bukan observer, tidak dijeda error budget) beserta limit DM
+// hariannya yang masih punya target pending di campaign DM aktif.
+func (s *Store) DMPendingAccounts() (map[string]int, error) {
+	rows, err := s.DB.Query(`SELECT a.id, a.dm_daily_limit FROM accounts a
+		WHERE a.enabled=1 AND a.observer=0 AND a.budget_paused_at IS NULL
+		  AND EXISTS (SELECT 1 FROM dm_targets t JOIN campaigns c ON c.id=t.campaign_id
+			WHERE t.account_id=a.id AND t.status='pending' AND c.enabled=1 AND c.mode='dm')`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := map[string]int{}
+	for rows.Next() {
+		var id string
+		var limit int
+		if err := rows.Scan(&id, &limit); err != nil {
+			return nil, err
+		}
+		out[id] = limit
+	}
+	return out, rows.Err()
+}
+
+// NextDMTarget memilih target pending tertua milik akun di campaign DM aktif yang nomornya
+// belum menerima DM sukses sejak cooldownSince; ok=false jika tidak ada.
+func (s *Store) NextDMTarget(accountID string, cooldownSince time.Time) (model.DMTarget, bool, error) {
+	t, err := scanDMTarget(s.DB.QueryRow(`SELECT `+dmTargetCols+` FROM dm_targets t
+		WHERE t.account_id=? AND t.status='pending'
+		  AND t.campaign_id IN (SELECT id FROM campaigns WHERE enabled=1 AND mode='dm')
+		  AND NOT EXISTS (SELECT 1 FROM dm_sends d WHERE d.number=t.number AND d.status='sent' AND d.ts >= ?)
+		ORDER BY t.id LIMIT 1`, accountID, cooldownSince.UTC().Format(ctsLayout)))
+	if err == sql.ErrNoRows {
+		return t, false, nil
+	}
+	return t, err == nil, err
+}
+
+// SetDMTargetStatus mencatat hasil kirim satu target DM.
+func (s *Store) SetDMTargetStatus(id int64, status, errMsg string) error {
+	_, err := s.DB.Exec(`UPDATE dm_targets SET status=?, error=?, sent_at=? WHERE id=?`,
+		status, nullStr(errMsg), time.Now().UTC(), id)
+	return err
+}
+
+// CountDMsToday jumlah DM sukses akun pada hari kuota ini (semua campaign dan follow-up).
+func (s *Store) CountDMsToday(accountID string) (int, error) {
+	var n int
+	from, to := s.TodayArgs()
+	err := s.DB.QueryRow(`SELECT COUNT(*) FROM dm_sends WHERE account_id=? AND status='sent' AND ts >= ? AND ts < ?`,
+		accountID, from, to).Scan(&n)
+	return n, err
+}
+
+// SetDMDailyLimit mengubah limit DM harian akun; ErrAccountNotFound jika akun tidak ada.
+func (s *Store) SetDMDailyLimit(accountID string, limit int) error {
+	res, err := s.DB.Exec(`UPDATE accounts SET dm_daily_limit=?, updated_at=CURRENT_TIMESTAMP WHERE id=?`, limit, accountID)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrAccountNotFound
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+package storage
+
+import (
+	"database/sql"
+	"errors"
+	"regexp"
+	"strings"
+	"time"
+
+	"promote/internal/model"
+)
+
+// DynamicVarNameRe membatasi nama variabel dinamis ({name:key} di template).
+var DynamicVarNameRe = regexp.MustCompile(`^[a-z][a-z0-9_]{0,31}$`)
+
+var (
+	// ErrDynamicVarNotFound dikembalikan jika variabel dinamis tidak ada.
+	ErrDynamicVarNotFound = errors.New("dynamic variable not found")
+	// ErrInvalidDynamicVarName dikembalikan jika nama tidak valid atau bentrok dengan placeholder bawaan.
+	ErrInvalidDynamicVarName = errors.New("invalid variable name (lowercase letters, digits, _; max 32; not \"field\")")
+)
+
+// NormalizeDynamicVarName merapikan nama variabel dan memvalidasinya. "field" dicadangkan untuk
+// custom field grup ({field:key}).
+func NormalizeDynamicVarName(name string) (string, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if !DynamicVarNameRe.MatchString(name) || name == "field" {
+		return "", ErrInvalidDynamicVarName
+	}
+	return name, nil
+}
+
+const dynamicVarCols = `name, url_template, COALESCE(json_path,''), timeout_ms, cache_ttl_sec,
+	COALESCE(fallback,''), enabled, created_at, updated_at`
+
+func scanDynamicVar(sc interface{ Scan(...any) error }) (model.DynamicVar, error) {
+	var v model.DynamicVar
+	var enabled int
+	err := sc.Scan(&v.Name, &v.URLTemplate, &v.JSONPath, &v.TimeoutMs, &v.CacheTTLSec,
+		&v.Fallback, &enabled, &v.CreatedAt, &v.UpdatedAt)
+	v.Enabled = enabled == 1
+	return v, err
+}
+
+// ListDynamicVars mengembalikan semua variabel dinamis urut nama.
+func (s *Store) ListDynamicVars() ([]model.DynamicVar, error) {
+	rows, err := s.DB.Query(`SELECT ` + dynamicVarCols + ` FROM dynamic_vars ORDER BY name`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var list []model.DynamicVar
+	for rows.Next() {
+		v, err := scanDynamicVar(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, v)
+	}
+	return list, rows.Err()
+}
+
+// GetDynamicVar mengambil satu variabel; ErrDynamicVarNotFound jika tidak ada.
+func (s *Store) GetDynamicVar(name string) (model.DynamicVar, error) {
+	v, err := scanDynamicVar(s.DB.QueryRow(`SELECT `+dynamicVarCols+` FROM dynamic_vars WHERE name=?`, name))
+	if err == sql.ErrNoRows {
+		return v, ErrDynamicVarNotFound
+	}
+	return v, err
+}
+
+// UpsertDynamicVar membuat atau mengganti variabel berdasarkan nama.
+func (s *Store) UpsertDynamicVar(v model.DynamicVar) error {
+	now := time.Now().UTC()
+	_, err := s.DB.Exec(`INSERT INTO dynamic_vars (name, url_template, json_path, timeout_ms, cache_ttl_sec, fallback, enabled, created_at, updated_at)
+		VALUES (?,?,?,?,?,?,?,?,?)
+		ON CONFLICT(name) DO UPDATE SET url_template=excluded.url_template, json_path=excluded.json_path,
+			timeout_ms=excluded.timeout_ms, cache_ttl_sec=excluded.cache_ttl_sec, fallback=excluded.fallback,
+			enabled=excluded.enabled, updated_at=excluded.updated_at`,
+		v.Name, v.URLTemplate, nullStr(v.JSONPath), v.TimeoutMs, v.CacheTTLSec, nullStr(v.Fallback), btoi(v.Enabled), now, now)
+	return err
+}
+
+// DeleteDynamicVar menghapus variabel.
+func (s *Store) DeleteDynamicVar(name string) error {
+	res, err := s.DB.Exec(`DELETE FROM dynamic_vars WHERE name=?`, name)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrDynamicVarNotFound
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+package storage
+
+import (
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Default aturan eligibility grup: cooldown sejak kiriman terakhir dan ambang risk_score.
+const (
+	DefaultCooldownHours = 48
+	DefaultRiskThreshold = 3
+)
+
+// EligibilityFromEnv membaca SCHEDULER_COOLDOWN_HOURS dan SCHEDULER_RISK_THRESHOLD (default 48
+// dan 3) sehingga scheduler, diagnosa dan trigger manual memakai aturan yang sama.
+func EligibilityFromEnv() (cooldownHours, riskThreshold int) {
+	cooldownHours, riskThreshold = DefaultCooldownHours, DefaultRiskThreshold
+	if n, err := strconv.Atoi(strings.TrimSpace(os.Getenv("SCHEDULER_COOLDOWN_HOURS"))); err == nil && n >= 0 {
+		cooldownHours = n
+	}
+	if n, err := strconv.Atoi(strings.TrimSpace(os.Getenv("SCHEDULER_RISK_THRESHOLD"))); err == nil && n >= 0 {
+		riskThreshold = n
+	}
+	return cooldownHours, riskThreshold
+}
+
+// eligibleWhere kondisi grup eligible (alias g) untuk akun: aktif, bukan grup uji atau grup
+// komunitas yang dikecualikan, lewat cooldown, di bawah ambang risk, lewat ramp_at dan tidak
+// disupresi. Argumen: account_id, modifier cooldown ("-N hours"), ambang risk.
+const eligibleWhere = `g.account_id=? AND g.enabled=1 AND g.is_test=0 AND g.community_excluded=0 AND (g.last_sent_at IS NULL OR g.last_sent_at < datetime('now', ?)) AND g.risk_score < ?
+			AND (g.ramp_at IS NULL OR g.ramp_at <= CURRENT_TIMESTAMP)
+			AND g.id NOT IN (SELECT group_id FROM group_suppressions)`
+
+func eligibleArgs(accountID string, cooldownHours, riskThreshold int) []any {
+	return []any{accountID, "-" + strconv.Itoa(cooldownHours) + " hours", riskThreshold}
+}
+
+// CountEligibleGroups jumlah grup akun yang saat ini boleh dikirimi scheduler (termasuk batas
+// harian/mingguan per grup).
+func (s *Store) CountEligibleGroups(accountID string, cooldownHours, riskThreshold int) (int64, error) {
+	capWhere, capArgs, err := s.GroupCapFilter("g")
+	if err != nil {
+		return 0, err
+	}
+	var n int64
+	err = s.DB.QueryRow(`SELECT COUNT(*) FROM groups g WHERE `+eligibleWhere+` AND `+capWhere,
+		append(eligibleArgs(accountID, cooldownHours, riskThreshold), capArgs...)...).Scan(&n)
+	return n, err
+}
@@ -0,0 +1,234 @@
+package storage
+
+import (
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+
+	"promote/internal/model"
+)
+
+const feedColumns = `id, COALESCE(campaign_id,''), name, url, mode, enabled, poll_minutes, last_polled_at, COALESCE(last_error,''), created_at`
+
+func scanFeed(sc interface{ Scan(...any) error }) (model.Feed, error) {
+	var f model.Feed
+	var enabled int
+	var polled sql.NullTime
+	if err := sc.Scan(&f.ID, &f.CampaignID, &f.Name, &f.URL, &f.Mode, &enabled, &f.PollMinutes, &polled, &f.LastError, &f.CreatedAt); err != nil {
+		return f, err
+	}
+	f.Enabled = enabled == 1
+	if polled.Valid {
+		t := polled.Time
+		f.LastPolledAt = &t
+	}
+	return f, nil
+}
+
+// CreateFeed menyimpan feed baru dan mengembalikan ID-nya.
+func (s *Store) CreateFeed(f model.Feed) (string, error) {
+	if f.Mode != model.FeedModeAuto {
+		f.Mode = model.FeedModeApproval
+	}
+	if f.PollMinutes <= 0 {
+		f.PollMinutes = 15
+	}
+	id := uuid.NewString()
+	_, err := s.DB.Exec(`INSERT INTO feeds (id,campaign_id,name,url,mode,enabled,poll_minutes,created_at)
+		VALUES (?,?,?,?,?,?,?,CURRENT_TIMESTAMP)`,
+		id, nullStr(f.CampaignID), f.Name, f.URL, f.Mode, btoi(f.Enabled), f.PollMinutes)
+	if err != nil {
+		return "", err
+	}
+	return id, nil
+}
+
+// UpdateFeed memperbarui konfigurasi feed. Mengembalikan jumlah baris terpengaruh.
+func (s *Store) UpdateFeed(f model.Feed) (int64, error) {
+	if f.Mode != model.FeedModeAuto {
+		f.Mode = model.FeedModeApproval
+	}
+	if f.PollMinutes <= 0 {
+		f.PollMinutes = 15
+	}
+	res, err := s.DB.Exec(`UPDATE feeds SET campaign_id=?, name=?, url=?, mode=?, enabled=?, poll_minutes=? WHERE id=?`,
+		nullStr(f.CampaignID), f.Name, f.URL, f.Mode, btoi(f.Enabled), f.PollMinutes, f.ID)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+func (s *Store) DeleteFeed(id string) (int64, error) {
+	res, err := s.DB.Exec(`DELETE FROM feeds WHERE id=?`, id)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// GetFeed mengembalikan feed berdasarkan ID; sql.ErrNoRows jika tidak ada.
+func (s *Store) GetFeed(id string) (model.Feed, error) {
+	return scanFeed(s.DB.QueryRow(`SELECT `+feedColumns+` FROM feeds WHERE id=?`, id))
+}
+
+func (s *Store) ListFeeds() ([]model.Feed, error) {
+	rows, err := s.DB.Query(`SELECT ` + feedColumns + ` FROM feeds ORDER BY created_at DESC`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []model.Feed
+	for rows.Next() {
+		f, err := scanFeed(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, f)
+	}
+	return out, rows.Err()
+}
+
+// DueFeeds mengembalikan feed aktif yang sudah waktunya dipoll ulang.
+func (s *Store) DueFeeds(now time.Time) ([]model.Feed, error) {
+	all, err := s.ListFeeds()
+	if err != nil {
+		return nil, err
+	}
+	var due []model.Feed
+	for _, f := range all {
+		if !f.Enabled {
+			continue
+		}
+		if f.LastPolledAt == nil || now.Sub(*f.LastPolledAt) >= time.Duration(f.PollMinutes)*time.Minute {
+			due = append(due, f)
+		}
+	}
+	return due, nil
+}
+
+// MarkFeedPolled mencatat waktu poll terakhir dan error (kosong jika sukses).
+func (s *Store) MarkFeedPolled(id string, at time.Time, lastErr string) error {
+	_, err := s.DB.Exec(`UPDATE feeds SET last_polled_at=?, last_error=? WHERE id=?`, at, lastErr, id)
+	return err
+}
+
+// FeedItemExists cek apakah GUID item sudah pernah diproses untuk feed ini.
+func (s *Store) FeedItemExists(feedID, guid string) (bool, error) {
+	var n int
+	if err := s.DB.QueryRow(`SELECT COUNT(1) FROM feed_items WHERE feed_id=? AND guid=?`, feedID, guid).Scan(&n); err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
+// SaveFeedItem mencatat item feed. Jika tpl tidak nil, template dibuat dalam transaksi yang sama
+// (enabled sesuai parameter) dan ditautkan ke item.
+func (s *Store) SaveFeedItem(item model.FeedItem, tpl *FeedTemplate, enabled bool) (int64, error) {
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+	var tplID string
+	if tpl != nil {
+		tplID = uuid.NewString()
+		images, _ := json.Marshal(tpl.ImageURLs)
+		if _, err := tx.Exec(`INSERT INTO templates (id,name,text_only,images_json,images_caption,videos_json,videos_caption,audio_json,stickers_json,docs_json,docs_caption,campaign_id,enabled,created_at,updated_at)
+			VALUES (?,?,?,?,?,'[]','','[]','[]','[]','',?,?,CURRENT_TIMESTAMP,CURRENT_TIMESTAMP)`,
+			tplID, tpl.Name, tpl.TextOnly, string(images), tpl.ImageCaption, nullStr(tpl.CampaignID), btoi(enabled)); err != nil {
+			return 0, err
+		}
+		if err := snapshotTemplate(tx, tplID); err != nil {
+			return 0, err
+		}
+	}
+	res, err := tx.Exec(`INSERT INTO feed_items (feed_id,guid,title,link,summary,image_url,template_id,status,created_at)
+		VALUES (?,?,?,?,?,?,?,?,CURRENT_TIMESTAMP)`,
+		item.FeedID, item.GUID, item.Title, item.Link, item.Summary, item.ImageURL, nullStr(tplID), item.Status)
+	if err != nil {
+		return 0, err
+	}
+	id, _ := res.LastInsertId()
+	return id, tx.Commit()
+}
+
+// FeedTemplate adalah isi template yang dihasilkan dari satu item feed.
+type FeedTemplate struct {
+	Name         string
+	TextOnly     string
+	ImageURLs    []string
+	ImageCaption string
+	// CampaignID campaign pemilik feed; kosong jika feed tidak terikat campaign.
+	CampaignID string
+}
+
+// ListFeedItems mengembalikan item terbaru sebuah feed, opsional difilter status.
+func (s *Store) ListFeedItems(feedID, status string, limit int) ([]model.FeedItem, error) {
+	if limit <= 0 || limit > 500 {
+		limit = 100
+	}
+	q := `SELECT id, feed_id, guid, COALESCE(title,''), COALESCE(link,''), COALESCE(summary,''), COALESCE(image_url,''), COALESCE(template_id,''), status, created_at
+		FROM feed_items WHERE feed_id=?`
+	args := []any{feedID}
+	if status != "" {
+		q += ` AND status=?`
+		args = append(args, status)
+	}
+	q += ` ORDER BY id DESC LIMIT ?`
+	args = append(args, limit)
+	rows, err := s.DB.Query(q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []model.FeedItem
+	for rows.Next() {
+		var it model.FeedItem
+		if err := rows.Scan(&it.ID, &it.FeedID, &it.GUID, &it.Title, &it.Link, &it.Summary, &it.ImageURL, &it.TemplateID, &it.Status, &it.CreatedAt); err != nil {
+			return nil, err
+		}
+		out = append(out, it)
+	}
+	return out, rows.Err()
+}
+
+// ReviewFeedItem menyetujui/menolak item pending: approve mengaktifkan template, reject menonaktifkannya.
+func (s *Store) ReviewFeedItem(itemID int64, approve bool) (int64, error) {
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+	var tplID sql.NullString
+	if err := tx.QueryRow(`SELECT template_id FROM feed_items WHERE id=?`, itemID).Scan(&tplID); err != nil {
+		if err == sql.ErrNoRows {
+			return 0, nil
+		}
+		return 0, err
+	}
+	status := "rejected"
+	if approve {
+		status = "approved"
+	}
+	res, err := tx.Exec(`UPDATE feed_items SET status=? WHERE id=?`, status, itemID)
+	if err != nil {
+		return 0, err
+	}
+	if tplID.Valid {
+		if _, err := tx.Exec(`UPDATE templates SET enabled=?, updated_at=CURRENT_TIMESTAMP WHERE id=?`, btoi(approve), tplID.String); err != nil {
+			return 0, err
+		}
+	}
+	n, _ := res.RowsAffected()
+	return n, tx.Commit()
+}
+
+func nullStr(s string) any {
+	if s == "" {
+		return nil
+	}
+	return s
+}
@@ -0,0 +1,30 @@
+package storage
+
+import (
+	"encoding/json"
+
+	"promote/internal/model"
+)
+
+// SettingFetchPolicy batasan unduhan media remote sender (JSON model.FetchPolicy).
+const SettingFetchPolicy = "fetch_policy"
+
+// FetchPolicy membaca policy unduhan media; model.DefaultFetchPolicy jika belum diset.
+func (s *Store) FetchPolicy() (model.FetchPolicy, error) {
+	p := model.DefaultFetchPolicy()
+	v, err := s.GetSetting(SettingFetchPolicy)
+	if err != nil || v == "" {
+		return p, err
+	}
+	err = json.Unmarshal([]byte(v), &p)
+	return p, err
+}
+
+// SetFetchPolicy menyimpan policy unduhan media.
+func (s *Store) SetFetchPolicy(p model.FetchPolicy) error {
+	b, err := json.Marshal(p)
+	if err != nil {
+		return err
+	}
+	return s.SetSetting(SettingFetchPolicy, string(b))
+}
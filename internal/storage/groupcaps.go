@@ -0,0 +1,96 @@
+package storage
+
+import (
+	"database/sql"
+	"encoding/json"
+
+	"promote/internal/model"
+)
+
+// SettingGroupSendCaps batas kiriman default per grup (JSON model.GroupSendCaps).
+const SettingGroupSendCaps = "group_send_caps"
+
+// GroupSendCaps membaca batas default per grup; tanpa batas jika belum diset.
+func (s *Store) GroupSendCaps() (model.GroupSendCaps, error) {
+	var c model.GroupSendCaps
+	v, err := s.GetSetting(SettingGroupSendCaps)
+	if err != nil || v == "" {
+		return c, err
+	}
+	if err := json.Unmarshal([]byte(v), &c); err != nil || c.Validate() != nil {
+		return model.GroupSendCaps{}, err
+	}
+	return c, nil
+}
+
+// SetGroupSendCaps menyimpan batas default per grup.
+func (s *Store) SetGroupSendCaps(c model.GroupSendCaps) error {
+	raw, err := json.Marshal(c)
+	if err != nil {
+		return err
+	}
+	return s.SetSetting(SettingGroupSendCaps, string(raw))
+}
+
+// SetGroupCaps menyimpan batas khusus grup; nil = memakai batas default, 0 = tanpa batas.
+func (s *Store) SetGroupCaps(groupID string, perDay, perWeek *int) error {
+	_, err := s.DB.Exec(`UPDATE groups SET max_per_day=?, max_per_week=? WHERE id=?`, nullIntPtr(perDay), nullIntPtr(perWeek), groupID)
+	return err
+}
+
+func nullIntPtr(p *int) any {
+	if p == nil {
+		return nil
+	}
+	return *p
+}
+
+// capRange awal hari kuota ini dan awal jendela 7 hari kuota (format CURRENT_TIMESTAMP).
+func (s *Store) capRange() (day, week string) {
+	f, _ := s.Day.Today()
+	return f.UTC().Format(ctsLayout), f.AddDate(0, 0, -6).UTC().Format(ctsLayout)
+}
+
+// GroupCapFilter kondisi SQL atas tabel groups (alias) yang hanya meloloskan grup yang belum
+// mencapai batas harian/mingguannya. Kiriman sukses semua akun ke grup ikut dihitung.
+func (s *Store) GroupCapFilter(alias string) (string, []any, error) {
+	c, err := s.GroupSendCaps()
+	if err != nil {
+		return "", nil, err
+	}
+	day, week := s.capRange()
+	cond := `(COALESCE(` + alias + `.max_per_day, ?) <= 0 OR (SELECT COUNT(*) FROM logs cl
+			WHERE cl.group_id=` + alias + `.id AND cl.status='sent' AND cl.ts >= ?) < COALESCE(` + alias + `.max_per_day, ?))
+		AND (COALESCE(` + alias + `.max_per_week, ?) <= 0 OR (SELECT COUNT(*) FROM logs cl
+			WHERE cl.group_id=` + alias + `.id AND cl.status='sent' AND cl.ts >= ?) < COALESCE(` + alias + `.max_per_week, ?))`
+	return cond, []any{c.PerDay, day, c.PerDay, c.PerWeek, week, c.PerWeek}, nil
+}
+
+// GroupCapUsage pemakaian batas kiriman grup hari ini dan 7 hari kuota terakhir;
+// sql.ErrNoRows jika grup tidak ada.
+func (s *Store) GroupCapUsage(groupID string) (model.GroupCapUsage, error) {
+	u := model.GroupCapUsage{GroupID: groupID}
+	c, err := s.GroupSendCaps()
+	if err != nil {
+		return u, err
+	}
+	day, week := s.capRange()
+	var perDay, perWeek sql.NullInt64
+	err = s.DB.QueryRow(`SELECT max_per_day, max_per_week,
+			(SELECT COUNT(*) FROM logs WHERE group_id=g.id AND status='sent' AND ts >= ?),
+			(SELECT COUNT(*) FROM logs WHERE group_id=g.id AND status='sent' AND ts >= ?)
+		FROM groups g WHERE id=?`, day, week, groupID).Scan(&perDay, &perWeek, &u.SentToday, &u.SentWeek)
+	if err != nil {
+		return u, err
+	}
+	u.PerDay, u.CustomPerDay = c.PerDay, perDay.Valid
+	if perDay.Valid {
+		u.PerDay = int(perDay.Int64)
+	}
+	u.PerWeek, u.CustomPerWeek = c.PerWeek, perWeek.Valid
+	if perWeek.Valid {
+		u.PerWeek = int(perWeek.Int64)
+	}
+	u.Capped = (u.PerDay > 0 && u.SentToday >= u.PerDay) || (u.PerWeek > 0 && u.SentWeek >= u.PerWeek)
+	return u, nil
+}
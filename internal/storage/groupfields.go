@@ -0,0 +1,117 @@
+package storage
+
+import (
+	"database/sql"
+	"errors"
+	"regexp"
+	"strings"
+)
+
+// GroupFieldKeyRe membatasi nama field yang bisa dipakai sebagai {field:key} di template.
+var GroupFieldKeyRe = regexp.MustCompile(`^[A-Za-z0-9_.-]{1,64}$`)
+
+// ErrInvalidFieldKey dikembalikan jika nama field tidak valid.
+var ErrInvalidFieldKey = errors.New("invalid field key (allowed: letters, digits, _ . -; max 64)")
+
+// NormalizeFieldKey merapikan nama field (trim, huruf kecil) dan memvalidasinya.
+func NormalizeFieldKey(key string) (string, error) {
+	key = strings.ToLower(strings.TrimSpace(key))
+	if !GroupFieldKeyRe.MatchString(key) {
+		return "", ErrInvalidFieldKey
+	}
+	return key, nil
+}
+
+// GroupFields mengembalikan semua custom field grup sebagai map key->value.
+func (s *Store) GroupFields(groupID string) (map[string]string, error) {
+	rows, err := s.DB.Query(`SELECT key, value FROM group_fields WHERE group_id=?`, groupID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := map[string]string{}
+	for rows.Next() {
+		var k, v string
+		if err := rows.Scan(&k, &v); err != nil {
+			return nil, err
+		}
+		out[k] = v
+	}
+	return out, rows.Err()
+}
+
+// SetGroupFields menulis field (upsert) untuk satu grup; nilai kosong menghapus field.
+// Key harus sudah dinormalisasi.
+func (s *Store) SetGroupFields(groupID string, fields map[string]string) error {
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if err := setGroupFieldsTx(tx, groupID, fields); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func setGroupFieldsTx(tx *sql.Tx, groupID string, fields map[string]string) error {
+	for k, v := range fields {
+		if v == "" {
+			if _, err := tx.Exec(`DELETE FROM group_fields WHERE group_id=? AND key=?`, groupID, k); err != nil {
+				return err
+			}
+			continue
+		}
+		if _, err := tx.Exec(`INSERT INTO group_fields (group_id, key, value) VALUES (?,?,?)
+			ON CONFLICT(group_id, key) DO UPDATE SET value=excluded.value, updated_at=CURRENT_TIMESTAMP`, groupID, k, v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DeleteGroupField menghapus satu field grup.
+func (s *Store) DeleteGroupField(groupID, key string) (int64, error) {
+	res, err := s.DB.Exec(`DELETE FROM group_fields WHERE group_id=? AND key=?`, groupID, key)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// ImportGroupFields menulis field untuk banyak grup dalam satu transaksi. Grup yang tidak
+// dikenal dilewati dan dikembalikan di unknown.
+func (s *Store) ImportGroupFields(byGroup map[string]map[string]string) (updated int, unknown []string, err error) {
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return 0, nil, err
+	}
+	defer tx.Rollback()
+	for gid, fields := range byGroup {
+		var n int
+		if err := tx.QueryRow(`SELECT COUNT(*) FROM groups WHERE id=?`, gid).Scan(&n); err != nil {
+			return 0, nil, err
+		}
+		if n == 0 {
+			unknown = append(unknown, gid)
+			continue
+		}
+		if err := setGroupFieldsTx(tx, gid, fields); err != nil {
+			return 0, nil, err
+		}
+		updated++
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, nil, err
+	}
+	return updated, unknown, nil
+}
+
+// GroupExists true jika grup dengan JID tsb tersimpan.
+func (s *Store) GroupExists(id string) (bool, error) {
+	var n int
+	if err := s.DB.QueryRow(`SELECT COUNT(1) FROM groups WHERE id=?`, id).Scan(&n); err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
@@ -0,0 +1,94 @@
+package storage
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"promote/internal/model"
+)
+
+// ErrGroupLinkNotFound dikembalikan jika grup belum punya link undangan tersimpan.
+var ErrGroupLinkNotFound = errors.New("group invite link not found")
+
+const groupLinkCols = `l.group_id, l.account_id, COALESCE(g.name,''), l.invite_link, l.status,
+	l.member_count, COALESCE(l.error,''), l.checked_at, l.updated_at`
+
+func scanGroupLink(sc interface{ Scan(...any) error }) (model.GroupLink, error) {
+	var l model.GroupLink
+	var checked sql.NullTime
+	err := sc.Scan(&l.GroupID, &l.AccountID, &l.GroupName, &l.InviteLink, &l.Status,
+		&l.MemberCount, &l.Error, &checked, &l.UpdatedAt)
+	if checked.Valid {
+		t := checked.Time
+		l.CheckedAt = &t
+	}
+	return l, err
+}
+
+// GetGroupLink link undangan tersimpan untuk grup; ErrGroupLinkNotFound jika belum ada.
+func (s *Store) GetGroupLink(groupID string) (model.GroupLink, error) {
+	l, err := scanGroupLink(s.DB.QueryRow(`SELECT `+groupLinkCols+`
+		FROM group_links l LEFT JOIN groups g ON g.id=l.group_id WHERE l.group_id=?`, groupID))
+	if err == sql.ErrNoRows {
+		return l, ErrGroupLinkNotFound
+	}
+	return l, err
+}
+
+// ListGroupLinks link undangan tersimpan, opsional difilter akun dan status (kosong = semua).
+func (s *Store) ListGroupLinks(accountID, status string) ([]model.GroupLink, error) {
+	q := `SELECT ` + groupLinkCols + ` FROM group_links l LEFT JOIN groups g ON g.id=l.group_id WHERE 1=1`
+	var args []any
+	if accountID != "" {
+		q += ` AND l.account_id=?`
+		args = append(args, accountID)
+	}
+	if status != "" {
+		q += ` AND l.status=?`
+		args = append(args, status)
+	}
+	rows, err := s.DB.Query(q+` ORDER BY g.name`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []model.GroupLink
+	for rows.Next() {
+		l, err := scanGroupLink(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, l)
+	}
+	return out, rows.Err()
+}
+
+// SaveGroupLink menyimpan link undangan grup. Link yang berubah mereset status cek
+// (belum dicek) karena hasil sebelumnya milik link lama.
+func (s *Store) SaveGroupLink(groupID, accountID, link string) error {
+	_, err := s.DB.Exec(`INSERT INTO group_links (group_id, account_id, invite_link, updated_at)
+		VALUES (?,?,?,CURRENT_TIMESTAMP)
+		ON CONFLICT(group_id) DO UPDATE SET
+			account_id=excluded.account_id,
+			status=CASE WHEN invite_link=excluded.invite_link THEN status ELSE '' END,
+			error=CASE WHEN invite_link=excluded.invite_link THEN error ELSE NULL END,
+			checked_at=CASE WHEN invite_link=excluded.invite_link THEN checked_at ELSE NULL END,
+			invite_link=excluded.invite_link,
+			updated_at=CURRENT_TIMESTAMP`,
+		groupID, accountID, link)
+	return err
+}
+
+// RecordGroupLinkCheck menyimpan hasil cek kesehatan link grup.
+func (s *Store) RecordGroupLinkCheck(groupID, status string, members int, errMsg string, at time.Time) error {
+	res, err := s.DB.Exec(`UPDATE group_links SET status=?, member_count=?, error=?, checked_at=? WHERE group_id=?`,
+		status, members, nullStr(errMsg), at.UTC(), groupID)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrGroupLinkNotFound
+	}
+	return nil
+}
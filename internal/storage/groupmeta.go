@@ -0,0 +1,13 @@
+package storage
+
+// SetGroupName menyimpan nama grup setelah diubah lewat akun.
+func (s *Store) SetGroupName(groupID, name string) error {
+	_, err := s.DB.Exec(`UPDATE groups SET name=? WHERE id=?`, name, groupID)
+	return err
+}
+
+// SetGroupDescription menyimpan deskripsi grup setelah diubah lewat akun (dipakai cek compliance).
+func (s *Store) SetGroupDescription(groupID, description string) error {
+	_, err := s.DB.Exec(`UPDATE groups SET description=? WHERE id=?`, nullStr(description), groupID)
+	return err
+}
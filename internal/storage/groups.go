@@ -0,0 +1,81 @@
+package storage
+
+import (
+	"strings"
+
+	"promote/internal/model"
+)
+
+// FindGroupsByName mencari grup milik akun berdasarkan nama. Kecocokan persis (case-insensitive)
+// diutamakan; jika tidak ada, dipakai kecocokan sebagian. Hanya ID, akun dan nama yang diisi.
+func (s *Store) FindGroupsByName(accountID, name string) ([]model.Group, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, nil
+	}
+	list, err := s.queryGroupNames(`SELECT id, account_id, COALESCE(name,'') FROM groups
+		WHERE account_id=? AND name = ? COLLATE NOCASE ORDER BY name`, accountID, name)
+	if err != nil || len(list) > 0 {
+		return list, err
+	}
+	like := "%" + strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(name) + "%"
+	return s.queryGroupNames(`SELECT id, account_id, COALESCE(name,'') FROM groups
+		WHERE account_id=? AND name LIKE ? ESCAPE '\' ORDER BY name`, accountID, like)
+}
+
+func (s *Store) queryGroupNames(query string, args ...any) ([]model.Group, error) {
+	rows, err := s.DB.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var list []model.Group
+	for rows.Next() {
+		var g model.Group
+		if err := rows.Scan(&g.ID, &g.AccountID, &g.Name); err != nil {
+			return nil, err
+		}
+		list = append(list, g)
+	}
+	return list, rows.Err()
+}
+
+// EnableAllGroups mengaktifkan semua grup akun; mengembalikan jumlah baris yang diubah.
+func (s *Store) EnableAllGroups(accountID string) (int64, error) {
+	res, err := s.DB.Exec(`UPDATE groups SET enabled=1 WHERE account_id=?`, accountID)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// ResetGroupRisk mengosongkan risk_score dan cooldown (last_sent_at) grup akun: hanya groupIDs
+// jika diisi (ID kosong dilewati), selain itu semua grup akun.
+func (s *Store) ResetGroupRisk(accountID string, groupIDs []string) (int64, error) {
+	if len(groupIDs) == 0 {
+		res, err := s.DB.Exec(`UPDATE groups SET risk_score=0, last_sent_at=NULL WHERE account_id=?`, accountID)
+		if err != nil {
+			return 0, err
+		}
+		return res.RowsAffected()
+	}
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+	var updated int64
+	for _, gid := range groupIDs {
+		g := strings.TrimSpace(gid)
+		if g == "" {
+			continue
+		}
+		res, err := tx.Exec(`UPDATE groups SET risk_score=0, last_sent_at=NULL WHERE id=? AND account_id=?`, g, accountID)
+		if err != nil {
+			return 0, err
+		}
+		n, _ := res.RowsAffected()
+		updated += n
+	}
+	return updated, tx.Commit()
+}
@@ -0,0 +1,128 @@
+package storage
+
+import (
+	"database/sql"
+	"encoding/json"
+	"strings"
+
+	"promote/internal/model"
+)
+
+// SettingGroupTrial pengaturan masa uji grup baru (JSON model.GroupTrialSettings).
+const SettingGroupTrial = "group_trial"
+
+// GroupTrialSettings membaca pengaturan masa uji; default (nonaktif) jika belum diset.
+func (s *Store) GroupTrialSettings() (model.GroupTrialSettings, error) {
+	t := model.DefaultGroupTrialSettings()
+	v, err := s.GetSetting(SettingGroupTrial)
+	if err != nil || v == "" {
+		return t, err
+	}
+	if err := json.Unmarshal([]byte(v), &t); err != nil || t.Validate() != nil {
+		return model.DefaultGroupTrialSettings(), err
+	}
+	return t, nil
+}
+
+// SetGroupTrialSettings menyimpan pengaturan masa uji.
+func (s *Store) SetGroupTrialSettings(t model.GroupTrialSettings) error {
+	raw, err := json.Marshal(t)
+	if err != nil {
+		return err
+	}
+	return s.SetSetting(SettingGroupTrial, string(raw))
+}
+
+// StartGroupTrials memulai masa uji untuk grup aktif yang belum pernah dikirimi dan belum pernah
+// menjalani masa uji (grup uji dan grup yang dikecualikan komunitas dilewati). Mengembalikan
+// jumlah masa uji baru.
+func (s *Store) StartGroupTrials() (int64, error) {
+	res, err := s.DB.Exec(`INSERT INTO group_trials (group_id, account_id, started_at)
+		SELECT id, account_id, CURRENT_TIMESTAMP FROM groups
+		WHERE enabled=1 AND is_test=0 AND community_excluded=0 AND left_at IS NULL AND last_sent_at IS NULL
+			AND id NOT IN (SELECT group_id FROM group_trials)`)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+const groupTrialCols = `t.group_id, t.account_id, COALESCE(g.name,''), t.started_at,
+	(SELECT COUNT(*) FROM logs l WHERE l.group_id=t.group_id AND l.status IN ('sent','failed') AND l.ts >= t.started_at),
+	(SELECT COUNT(*) FROM logs l WHERE l.group_id=t.group_id AND l.status='failed' AND l.ts >= t.started_at),
+	t.replies, t.kicked, COALESCE(t.verdict,''), COALESCE(t.reason,''), t.decided_at`
+
+// ListGroupTrials masa uji terbaru dulu. verdict "open" = masih berjalan, kosong = semua;
+// accountID kosong = semua akun.
+func (s *Store) ListGroupTrials(accountID, verdict string, limit int) ([]model.GroupTrial, error) {
+	q := `SELECT ` + groupTrialCols + ` FROM group_trials t LEFT JOIN groups g ON g.id=t.group_id WHERE 1=1`
+	var args []any
+	if accountID != "" {
+		q += ` AND t.account_id=?`
+		args = append(args, accountID)
+	}
+	switch verdict = strings.TrimSpace(verdict); verdict {
+	case "":
+	case "open":
+		q += ` AND t.verdict IS NULL`
+	default:
+		q += ` AND t.verdict=?`
+		args = append(args, verdict)
+	}
+	q += ` ORDER BY t.started_at DESC, t.group_id LIMIT ?`
+	args = append(args, limit)
+	rows, err := s.DB.Query(q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []model.GroupTrial
+	for rows.Next() {
+		var t model.GroupTrial
+		var decided sql.NullTime
+		if err := rows.Scan(&t.GroupID, &t.AccountID, &t.GroupName, &t.StartedAt, &t.Sends, &t.Failures,
+			&t.Replies, &t.Kicked, &t.Verdict, &t.Reason, &decided); err != nil {
+			return nil, err
+		}
+		t.DecidedAt = nullTimePtr(decided)
+		out = append(out, t)
+	}
+	return out, rows.Err()
+}
+
+// DecideGroupTrial mencatat verdict masa uji; verdict disabled sekaligus menonaktifkan grup.
+// false jika grup tidak sedang dalam masa uji.
+func (s *Store) DecideGroupTrial(groupID, verdict, reason string) (bool, error) {
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+	res, err := tx.Exec(`UPDATE group_trials SET verdict=?, reason=?, decided_at=CURRENT_TIMESTAMP
+		WHERE group_id=? AND verdict IS NULL`, verdict, nullStr(reason), groupID)
+	if err != nil {
+		return false, err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return false, nil
+	}
+	if verdict == model.TrialDisabled {
+		if _, err := tx.Exec(`UPDATE groups SET enabled=0, enable_at=NULL WHERE id=?`, groupID); err != nil {
+			return false, err
+		}
+	}
+	return true, tx.Commit()
+}
+
+// RecordTrialReply menambah hitungan balasan grup yang sedang dalam masa uji.
+func (s *Store) RecordTrialReply(groupID string) error {
+	_, err := s.DB.Exec(`UPDATE group_trials SET replies=replies+1 WHERE group_id=? AND verdict IS NULL`, groupID)
+	return err
+}
+
+// RecordTrialKick menandai akun dikeluarkan dari grup yang sedang dalam masa uji.
+func (s *Store) RecordTrialKick(accountID, groupID string) error {
+	_, err := s.DB.Exec(`UPDATE group_trials SET kicked=1 WHERE group_id=? AND account_id=? AND verdict IS NULL`,
+		groupID, accountID)
+	return err
+}
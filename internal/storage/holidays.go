@@ -0,0 +1,60 @@
+package storage
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"promote/internal/model"
+)
+
+// ErrHolidayNotFound dikembalikan jika tanggal tidak ada di daftar hari libur.
+var ErrHolidayNotFound = errors.New("holiday not found")
+
+// ListHolidays daftar hari libur terurut tanggal; from (YYYY-MM-DD) non-kosong hanya
+// mengembalikan tanggal sejak from.
+func (s *Store) ListHolidays(from string) ([]model.Holiday, error) {
+	rows, err := s.DB.Query(`SELECT date, name, created_at FROM holidays WHERE date >= ? ORDER BY date`, from)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := []model.Holiday{}
+	for rows.Next() {
+		var h model.Holiday
+		if err := rows.Scan(&h.Date, &h.Name, &h.CreatedAt); err != nil {
+			return nil, err
+		}
+		out = append(out, h)
+	}
+	return out, rows.Err()
+}
+
+// SetHoliday menambah hari libur atau mengganti namanya jika tanggal sudah ada.
+func (s *Store) SetHoliday(h model.Holiday) error {
+	_, err := s.DB.Exec(`INSERT INTO holidays (date, name) VALUES (?,?)
+		ON CONFLICT(date) DO UPDATE SET name=excluded.name`, h.Date, h.Name)
+	return err
+}
+
+// DeleteHoliday menghapus hari libur; ErrHolidayNotFound jika tanggal tidak terdaftar.
+func (s *Store) DeleteHoliday(date string) error {
+	res, err := s.DB.Exec(`DELETE FROM holidays WHERE date=?`, date)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrHolidayNotFound
+	}
+	return nil
+}
+
+// HolidayOn hari libur pada tanggal kalender t (zona t); ok=false jika bukan hari libur.
+func (s *Store) HolidayOn(t time.Time) (h model.Holiday, ok bool, err error) {
+	err = s.DB.QueryRow(`SELECT date, name, created_at FROM holidays WHERE date=?`, t.Format(model.HolidayLayout)).
+		Scan(&h.Date, &h.Name, &h.CreatedAt)
+	if err == sql.ErrNoRows {
+		return h, false, nil
+	}
+	return h, err == nil, err
+}
@@ -0,0 +1,207 @@
+package storage
+
+import (
+	"database/sql"
+	"encoding/json"
+	"regexp"
+	"strings"
+	"time"
+
+	"promote/internal/model"
+)
+
+// UploadRefRe mencocokkan referensi media lokal "/uploads/<file>" di kolom teks/JSON.
+var UploadRefRe = regexp.MustCompile(`/uploads/([A-Za-z0-9._-]+)`)
+
+// ctsLayout format CURRENT_TIMESTAMP SQLite (UTC), untuk membandingkan kolom default-timestamp.
+const ctsLayout = "2006-01-02 15:04:05"
+
+// PruneLogs menghapus log kirim lebih lama dari cutoff.
+func (s *Store) PruneLogs(cutoff time.Time) (int64, error) {
+	res, err := s.DB.Exec(`DELETE FROM logs WHERE ts < ?`, cutoff.UTC().Format(ctsLayout))
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// PruneAccountEvents menghapus event status akun lebih lama dari cutoff.
+func (s *Store) PruneAccountEvents(cutoff time.Time) (int64, error) {
+	res, err := s.DB.Exec(`DELETE FROM account_events WHERE ts < ?`, cutoff.UTC().Format(ctsLayout))
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// ReferencedUploads mengembalikan nama file upload yang masih dipakai template, campaign,
+// item feed, atau antrean.
+func (s *Store) ReferencedUploads() (map[string]bool, error) {
+	rows, err := s.DB.Query(`
+		SELECT COALESCE(text_only,'') || ' ' || COALESCE(images_json,'') || ' ' || COALESCE(videos_json,'') || ' ' ||
+			COALESCE(gifs_json,'') || ' ' || COALESCE(audio_json,'') || ' ' || COALESCE(voice_json,'') || ' ' || COALESCE(stickers_json,'') || ' ' ||
+			COALESCE(docs_json,'') || ' ' || COALESCE(fallback_image_url,'') || ' ' || COALESCE(products_json,'') FROM templates
+		UNION ALL SELECT COALESCE(text,'') || ' ' || COALESCE(media_images,'') || ' ' || COALESCE(media_videos,'') || ' ' ||
+			COALESCE(media_stickers,'') || ' ' || COALESCE(media_docs,'') FROM campaigns
+		UNION ALL SELECT COALESCE(image_url,'') FROM feed_items`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	refs := map[string]bool{}
+	for rows.Next() {
+		var blob string
+		if err := rows.Scan(&blob); err != nil {
+			return nil, err
+		}
+		for _, m := range UploadRefRe.FindAllStringSubmatch(blob, -1) {
+			refs[m[1]] = true
+		}
+	}
+	return refs, rows.Err()
+}
+
+// TemplateMedia adalah daftar URL media milik satu template.
+type TemplateMedia struct {
+	ID   string
+	Name string
+	URLs []string
+}
+
+// ListTemplateMedia mengembalikan URL media semua template aktif.
+func (s *Store) ListTemplateMedia() ([]TemplateMedia, error) {
+	rows, err := s.DB.Query(`SELECT id, name, COALESCE(images_json,''), COALESCE(videos_json,''), COALESCE(gifs_json,''),
+		COALESCE(audio_json,''), COALESCE(voice_json,''), COALESCE(stickers_json,''), COALESCE(docs_json,''),
+		COALESCE((SELECT json_group_array(json_extract(value,'$.image_url')) FROM json_each(products_json)),'') FROM templates WHERE enabled=1`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var list []TemplateMedia
+	for rows.Next() {
+		var t TemplateMedia
+		cols := make([]string, 8)
+		if err := rows.Scan(&t.ID, &t.Name, &cols[0], &cols[1], &cols[2], &cols[3], &cols[4], &cols[5], &cols[6], &cols[7]); err != nil {
+			return nil, err
+		}
+		for _, c := range cols {
+			var urls []string
+			if c == "" || json.Unmarshal([]byte(c), &urls) != nil {
+				continue
+			}
+			for _, u := range urls {
+				if u = strings.TrimSpace(u); u != "" {
+					t.URLs = append(t.URLs, u)
+				}
+			}
+		}
+		list = append(list, t)
+	}
+	return list, rows.Err()
+}
+
+// SetTemplateHealth menyimpan hasil cek media template (problem kosong = sehat).
+func (s *Store) SetTemplateHealth(id, problem string, at time.Time) error {
+	_, err := s.DB.Exec(`UPDATE templates SET health_error=?, health_checked_at=? WHERE id=?`, nullStr(problem), at.UTC(), id)
+	return err
+}
+
+// WALCheckpoint menjalankan PRAGMA wal_checkpoint(TRUNCATE); mengembalikan jumlah frame
+// di WAL dan yang berhasil di-checkpoint.
+func (s *Store) WALCheckpoint() (busy bool, logFrames, checkpointed int, err error) {
+	var b int
+	err = s.DB.QueryRow(`PRAGMA wal_checkpoint(TRUNCATE)`).Scan(&b, &logFrames, &checkpointed)
+	return b != 0, logFrames, checkpointed, err
+}
+
+// LoadCronTasks membaca status task cron yang tersimpan, per nama.
+func (s *Store) LoadCronTasks() (map[string]model.CronTask, error) {
+	rows, err := s.DB.Query(`SELECT name, last_started_at, last_finished_at, COALESCE(last_status,''), COALESCE(last_message,''),
+		COALESCE(last_error,''), last_duration_ms, runs, next_run_at FROM cron_tasks`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := map[string]model.CronTask{}
+	for rows.Next() {
+		var t model.CronTask
+		var started, finished, next sql.NullTime
+		if err := rows.Scan(&t.Name, &started, &finished, &t.LastStatus, &t.LastMessage, &t.LastError,
+			&t.LastDurationMs, &t.Runs, &next); err != nil {
+			return nil, err
+		}
+		t.LastStartedAt = nullTimePtr(started)
+		t.LastFinishedAt = nullTimePtr(finished)
+		t.NextRunAt = nullTimePtr(next)
+		out[t.Name] = t
+	}
+	return out, rows.Err()
+}
+
+// SaveCronTask menyimpan status task cron (upsert per nama).
+func (s *Store) SaveCronTask(t model.CronTask) error {
+	_, err := s.DB.Exec(`INSERT INTO cron_tasks (name, last_started_at, last_finished_at, last_status, last_message, last_error, last_duration_ms, runs, next_run_at)
+		VALUES (?,?,?,?,?,?,?,?,?)
+		ON CONFLICT(name) DO UPDATE SET last_started_at=excluded.last_started_at, last_finished_at=excluded.last_finished_at,
+			last_status=excluded.last_status, last_message=excluded.last_message, last_error=excluded.last_error,
+			last_duration_ms=excluded.last_duration_ms, runs=excluded.runs, next_run_at=excluded.next_run_at`,
+		t.Name, timePtrArg(t.LastStartedAt), timePtrArg(t.LastFinishedAt), nullStr(t.LastStatus), nullStr(t.LastMessage),
+		nullStr(t.LastError), t.LastDurationMs, t.Runs, timePtrArg(t.NextRunAt))
+	return err
+}
+
+func nullTimePtr(v sql.NullTime) *time.Time {
+	if !v.Valid {
+		return nil
+	}
+	t := v.Time
+	return &t
+}
+
+func timePtrArg(t *time.Time) any {
+	if t == nil {
+		return nil
+	}
+	return t.UTC()
+}
+
+// MediaRef adalah satu URL media yang dipakai template/campaign aktif.
+type MediaRef struct {
+	URL string
+	// Kind: image, video, gif, audio, voice_note, sticker, document.
+	Kind string
+}
+
+// ActiveMediaRefs mengembalikan URL media unik dari template aktif dan campaign aktif.
+func (s *Store) ActiveMediaRefs() ([]MediaRef, error) {
+	rows, err := s.DB.Query(`
+		SELECT COALESCE(images_json,''), COALESCE(videos_json,''), COALESCE(gifs_json,''), COALESCE(audio_json,''),
+			COALESCE(voice_json,''), COALESCE(stickers_json,''), COALESCE(docs_json,''),
+			COALESCE((SELECT json_group_array(json_extract(value,'$.image_url')) FROM json_each(products_json)),'')
+		FROM templates WHERE enabled=1
+		UNION ALL
+		SELECT COALESCE(media_images,''), COALESCE(media_videos,''), '', '', '', COALESCE(media_stickers,''), COALESCE(media_docs,''), ''
+		FROM campaigns WHERE enabled=1`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	kinds := []string{"image", "video", "gif", "audio", "voice_note", "sticker", "document", "image"}
+	seen := map[string]bool{}
+	var list []MediaRef
+	for rows.Next() {
+		cols := make([]string, 8)
+		if err := rows.Scan(&cols[0], &cols[1], &cols[2], &cols[3], &cols[4], &cols[5], &cols[6], &cols[7]); err != nil {
+			return nil, err
+		}
+		for i, c := range cols {
+			for _, u := range jsonList(c) {
+				if u = strings.TrimSpace(u); u != "" && !seen[u] {
+					seen[u] = true
+					list = append(list, MediaRef{URL: u, Kind: kinds[i]})
+				}
+			}
+		}
+	}
+	return list, rows.Err()
+}
@@ -0,0 +1,178 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// FKViolation sekelompok baris yang melanggar satu foreign key (hasil PRAGMA foreign_key_check),
+// mis. log yang menunjuk grup yang sudah tidak ada.
+type FKViolation struct {
+	Table  string `json:"table"`
+	Column string `json:"column"`
+	Parent string `json:"parent"`
+	// OnDelete aksi foreign key ("SET NULL", "CASCADE", ...) yang menentukan cara perbaikan.
+	OnDelete string  `json:"on_delete"`
+	RowIDs   []int64 `json:"-"`
+	// Keys contoh nilai kolom yang tidak punya induk (maks. 5).
+	Keys []string `json:"keys"`
+}
+
+// Repair aksi perbaikan: kolom di-NULL-kan untuk ON DELETE SET NULL, selain itu baris dihapus.
+func (v FKViolation) Repair() string {
+	if v.nullable() {
+		return "set " + v.Table + "." + v.Column + " to NULL"
+	}
+	return "delete rows from " + v.Table
+}
+
+func (v FKViolation) nullable() bool {
+	return strings.EqualFold(v.OnDelete, "SET NULL") && !strings.Contains(v.Column, ",")
+}
+
+// ForeignKeyViolations menjalankan PRAGMA foreign_key_check di DB utama. Pelanggaran bisa muncul
+// jika DB pernah dibuka tanpa foreign key aktif (DB_DSN tanpa _foreign_keys=on) atau diedit manual.
+func (s *Store) ForeignKeyViolations() ([]FKViolation, error) {
+	rows, err := s.DB.Query(`PRAGMA foreign_key_check`)
+	if err != nil {
+		return nil, err
+	}
+	type key struct {
+		table string
+		fkid  int
+	}
+	byKey := map[key]*FKViolation{}
+	var order []key
+	for rows.Next() {
+		var (
+			table, parent string
+			rowid         sql.NullInt64
+			fkid          int
+		)
+		if err := rows.Scan(&table, &rowid, &parent, &fkid); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		k := key{table, fkid}
+		v := byKey[k]
+		if v == nil {
+			v = &FKViolation{Table: table, Parent: parent}
+			byKey[k] = v
+			order = append(order, k)
+		}
+		if rowid.Valid {
+			v.RowIDs = append(v.RowIDs, rowid.Int64)
+		}
+	}
+	err = rows.Err()
+	rows.Close()
+	if err != nil {
+		return nil, err
+	}
+	out := make([]FKViolation, 0, len(order))
+	for _, k := range order {
+		v := byKey[k]
+		if err := s.describeFK(v, k.fkid); err != nil {
+			return nil, err
+		}
+		out = append(out, *v)
+	}
+	return out, nil
+}
+
+// describeFK mengisi kolom, aksi ON DELETE dan contoh nilai untuk pelanggaran fkid.
+func (s *Store) describeFK(v *FKViolation, fkid int) error {
+	rows, err := s.DB.Query(`SELECT "from", on_delete FROM pragma_foreign_key_list(?) WHERE id=? ORDER BY seq`, v.Table, fkid)
+	if err != nil {
+		return err
+	}
+	var cols []string
+	for rows.Next() {
+		var col string
+		if err := rows.Scan(&col, &v.OnDelete); err != nil {
+			rows.Close()
+			return err
+		}
+		cols = append(cols, col)
+	}
+	err = rows.Err()
+	rows.Close()
+	if err != nil {
+		return err
+	}
+	v.Column = strings.Join(cols, ",")
+	if len(cols) != 1 {
+		return nil
+	}
+	seen := map[string]bool{}
+	for _, id := range v.RowIDs {
+		if len(v.Keys) == 5 {
+			break
+		}
+		var k sql.NullString
+		if err := s.DB.QueryRow(fmt.Sprintf(`SELECT CAST(%q AS TEXT) FROM %q WHERE rowid=?`, cols[0], v.Table), id).Scan(&k); err != nil {
+			return err
+		}
+		if k.Valid && !seen[k.String] {
+			seen[k.String] = true
+			v.Keys = append(v.Keys, k.String)
+		}
+	}
+	return nil
+}
+
+// RepairForeignKeys memperbaiki pelanggaran sesuai Repair; mengembalikan jumlah baris yang diubah.
+func (s *Store) RepairForeignKeys(list []FKViolation) (int64, error) {
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+	var n int64
+	for _, v := range list {
+		q := fmt.Sprintf(`DELETE FROM %q WHERE rowid=?`, v.Table)
+		if v.nullable() {
+			q = fmt.Sprintf(`UPDATE %q SET %q=NULL WHERE rowid=?`, v.Table, v.Column)
+		}
+		for _, id := range v.RowIDs {
+			res, err := tx.Exec(q, id)
+			if err != nil {
+				return 0, fmt.Errorf("%s rowid %d: %w", v.Table, id, err)
+			}
+			k, _ := res.RowsAffected()
+			n += k
+		}
+	}
+	return n, tx.Commit()
+}
+
+// TemplateUploads mengembalikan nama file upload lokal ("/uploads/<file>") yang dirujuk tiap
+// template, per ID template; nama template di names.
+func (s *Store) TemplateUploads() (refs map[string][]string, names map[string]string, err error) {
+	rows, err := s.DB.Query(`SELECT id, name,
+		COALESCE(text_only,'') || ' ' || COALESCE(images_json,'') || ' ' || COALESCE(videos_json,'') || ' ' ||
+		COALESCE(gifs_json,'') || ' ' || COALESCE(audio_json,'') || ' ' || COALESCE(voice_json,'') || ' ' || COALESCE(stickers_json,'') || ' ' ||
+		COALESCE(docs_json,'') || ' ' || COALESCE(fallback_image_url,'') || ' ' || COALESCE(products_json,'')
+		FROM templates ORDER BY name, id`)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+	refs, names = map[string][]string{}, map[string]string{}
+	for rows.Next() {
+		var id, name, blob string
+		if err := rows.Scan(&id, &name, &blob); err != nil {
+			return nil, nil, err
+		}
+		names[id] = name
+		seen := map[string]bool{}
+		for _, m := range UploadRefRe.FindAllStringSubmatch(blob, -1) {
+			if !seen[m[1]] {
+				seen[m[1]] = true
+				refs[id] = append(refs[id], m[1])
+			}
+		}
+	}
+	return refs, names, rows.Err()
+}
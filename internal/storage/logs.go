@@ -0,0 +1,153 @@
+package storage
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"promote/internal/model"
+)
+
+const logCols = `id, ts, account_id, group_id, COALESCE(campaign_id,''), COALESCE(campaign_session_id,''),
+	status, COALESCE(error,''), COALESCE(message_preview,''), COALESCE(message_id,''), attempt, scheduled_for, revoked_at,
+	COALESCE(template_id,''), COALESCE(template_version,0)`
+
+func scanLog(sc interface{ Scan(...any) error }) (model.LogEntry, error) {
+	var e model.LogEntry
+	var scheduled, revoked sql.NullTime
+	if err := sc.Scan(&e.ID, &e.TS, &e.AccountID, &e.GroupID, &e.CampaignID, &e.SessionID,
+		&e.Status, &e.Error, &e.MessagePrev, &e.MessageID, &e.Attempt, &scheduled, &revoked,
+		&e.TemplateID, &e.TemplateVersion); err != nil {
+		return e, err
+	}
+	if scheduled.Valid {
+		e.ScheduledFor = scheduled.Time
+	}
+	if revoked.Valid {
+		e.RevokedAt = &revoked.Time
+	}
+	return e, nil
+}
+
+func (s *Store) queryLogs(query string, args ...any) ([]model.LogEntry, error) {
+	rows, err := s.DB.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var list []model.LogEntry
+	for rows.Next() {
+		e, err := scanLog(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, e)
+	}
+	return list, rows.Err()
+}
+
+// InsertLog mencatat satu percobaan kirim.
+func (s *Store) InsertLog(e model.LogEntry) error {
+	var scheduled, tplVersion any
+	if !e.ScheduledFor.IsZero() {
+		scheduled = e.ScheduledFor
+	}
+	if e.TemplateVersion > 0 {
+		tplVersion = e.TemplateVersion
+	}
+	_, err := s.DB.Exec(`INSERT INTO logs (account_id,group_id,campaign_id,campaign_session_id,status,error,message_preview,message_id,attempt,scheduled_for,template_id,template_version)
+		VALUES (?,?,?,?,?,?,?,?,?,?,?,?)`,
+		e.AccountID, e.GroupID, nullStr(e.CampaignID), nullStr(e.SessionID), e.Status, e.Error, e.MessagePrev, nullStr(e.MessageID), e.Attempt, scheduled,
+		nullStr(e.TemplateID), tplVersion)
+	return err
+}
+
+// RecentLogs mengembalikan limit log terakhir, terbaru dulu.
+func (s *Store) RecentLogs(limit int) ([]model.LogEntry, error) {
+	return s.queryLogs(`SELECT `+logCols+` FROM logs ORDER BY id DESC LIMIT ?`, limit)
+}
+
+// LogsAfter mengembalikan log dengan id > afterID, terlama dulu (untuk tail/stream).
+func (s *Store) LogsAfter(afterID int64, limit int) ([]model.LogEntry, error) {
+	return s.queryLogs(`SELECT `+logCols+` FROM logs WHERE id > ? ORDER BY id ASC LIMIT ?`, afterID, limit)
+}
+
+// ErrLogNotFound dikembalikan jika log kirim tidak ada.
+var ErrLogNotFound = errors.New("log not found")
+
+// GetLog membaca satu log kirim.
+func (s *Store) GetLog(id int64) (model.LogEntry, error) {
+	e, err := scanLog(s.DB.QueryRow(`SELECT `+logCols+` FROM logs WHERE id=?`, id))
+	if err == sql.ErrNoRows {
+		return e, ErrLogNotFound
+	}
+	return e, err
+}
+
+// EditableTextLogs mengembalikan log teks terkirim (punya ID pesan) milik campaign sejak since,
+// untuk edit massal.
+func (s *Store) EditableTextLogs(campaignID string, since time.Time) ([]model.LogEntry, error) {
+	return s.queryLogs(`SELECT `+logCols+` FROM logs WHERE campaign_id=? AND status='sent' AND ts >= ?
+		AND message_id IS NOT NULL AND message_preview LIKE 'text-only:%' ORDER BY id`,
+		campaignID, since.UTC().Format(ctsLayout))
+}
+
+// RevocableLogs mengembalikan log terkirim (punya ID pesan, belum dihapus) sejak since milik
+// campaign atau sesi kirim tertentu, untuk hapus massal.
+func (s *Store) RevocableLogs(campaignID, sessionID string, since time.Time) ([]model.LogEntry, error) {
+	return s.queryLogs(`SELECT `+logCols+` FROM logs WHERE status='sent' AND ts >= ?
+		AND message_id IS NOT NULL AND revoked_at IS NULL
+		AND (?='' OR campaign_id=?) AND (?='' OR campaign_session_id=?) ORDER BY id`,
+		since.UTC().Format(ctsLayout), campaignID, campaignID, sessionID, sessionID)
+}
+
+// MarkLogRevoked menandai pesan log sudah dihapus untuk semua orang.
+func (s *Store) MarkLogRevoked(id int64) error {
+	_, err := s.DB.Exec(`UPDATE logs SET revoked_at=? WHERE id=?`, time.Now().UTC(), id)
+	return err
+}
+
+// SetLogPreview mengganti preview log (mis. setelah pesan diedit).
+func (s *Store) SetLogPreview(id int64, preview string) error {
+	_, err := s.DB.Exec(`UPDATE logs SET message_preview=? WHERE id=?`, preview, id)
+	return err
+}
+
+// CountAccountSentToday jumlah kiriman sukses akun hari ini (semua campaign).
+func (s *Store) CountAccountSentToday(accountID string) (int, error) {
+	var n int
+	from, to := s.TodayArgs()
+	err := s.DB.QueryRow(`SELECT COUNT(*) FROM logs WHERE account_id=? AND status='sent' AND ts >= ? AND ts < ?`,
+		accountID, from, to).Scan(&n)
+	return n, err
+}
+
+// BumpGroupRisk menaikkan risk_score grup setelah kegagalan kirim dan menonaktifkan grup
+// begitu skornya mencapai threshold.
+func (s *Store) BumpGroupRisk(groupID string, threshold int) error {
+	if _, err := s.DB.Exec(`UPDATE groups SET risk_score = risk_score + 1 WHERE id=?`, groupID); err != nil {
+		return err
+	}
+	_, err := s.DB.Exec(`UPDATE groups SET enabled=0 WHERE id=? AND risk_score >= ?`, groupID, threshold)
+	return err
+}
+
+// AccountLabel label akun; kosong jika akun tidak ada.
+func (s *Store) AccountLabel(accountID string) (string, error) {
+	var label sql.NullString
+	err := s.DB.QueryRow(`SELECT label FROM accounts WHERE id=?`, accountID).Scan(&label)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	return label.String, err
+}
+
+// GroupName nama grup; kosong jika grup tidak ada.
+func (s *Store) GroupName(groupID string) (string, error) {
+	var name sql.NullString
+	err := s.DB.QueryRow(`SELECT name FROM groups WHERE id=?`, groupID).Scan(&name)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	return name.String, err
+}
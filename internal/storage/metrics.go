@@ -0,0 +1,40 @@
+package storage
+
+import (
+	"database/sql"
+
+	"promote/internal/model"
+)
+
+// AccountStreaks streak gagal beruntun dan waktu kiriman sukses/gagal terakhir tiap akun.
+// Status log selain sent/failed/throttled (skip, supresi, dsb.) tidak memutus streak.
+func (s *Store) AccountStreaks() ([]model.AccountStreak, error) {
+	rows, err := s.DB.Query(`
+		SELECT a.id, a.label, a.enabled, COALESCE(a.throttled_until > CURRENT_TIMESTAMP, 0),
+			(SELECT COUNT(*) FROM logs f WHERE f.account_id=a.id AND f.status IN ('failed','throttled')
+				AND f.id > COALESCE((SELECT MAX(id) FROM logs WHERE account_id=a.id AND status='sent'), 0)),
+			(SELECT MAX(ts) FROM logs WHERE account_id=a.id AND status='sent'),
+			(SELECT MAX(ts) FROM logs WHERE account_id=a.id AND status IN ('failed','throttled'))
+		FROM accounts a ORDER BY a.created_at`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []model.AccountStreak
+	for rows.Next() {
+		var st model.AccountStreak
+		var lastOK, lastFail sql.NullString
+		if err := rows.Scan(&st.AccountID, &st.Label, &st.Enabled, &st.Throttled, &st.FailureStreak,
+			&lastOK, &lastFail); err != nil {
+			return nil, err
+		}
+		if t, ok := parseDBTime(lastOK); ok {
+			st.LastSuccessAt = &t
+		}
+		if t, ok := parseDBTime(lastFail); ok {
+			st.LastFailureAt = &t
+		}
+		out = append(out, st)
+	}
+	return out, rows.Err()
+}
@@ -0,0 +1,20 @@
+package storage
+
+// SetAccountObserver mengaktifkan/menonaktifkan mode observer akun; ErrAccountNotFound jika akun tidak ada.
+func (s *Store) SetAccountObserver(accountID string, observer bool) error {
+	res, err := s.DB.Exec(`UPDATE accounts SET observer=?, updated_at=CURRENT_TIMESTAMP WHERE id=?`, btoi(observer), accountID)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrAccountNotFound
+	}
+	return nil
+}
+
+// IsObserverAccount true jika akun dalam mode observer (tidak boleh dipakai mengirim).
+func (s *Store) IsObserverAccount(accountID string) (bool, error) {
+	var n int
+	err := s.DB.QueryRow(`SELECT COUNT(1) FROM accounts WHERE id=? AND observer=1`, accountID).Scan(&n)
+	return n > 0, err
+}
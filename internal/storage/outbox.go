@@ -0,0 +1,264 @@
+package storage
+
+import (
+	"database/sql"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"promote/internal/model"
+)
+
+const outboxColumns = `id, account_id, group_id, COALESCE(campaign_id,''), COALESCE(template_id,''), COALESCE(content,''), priority, status,
+	origin, scheduled_at, claimed_at, sent_at, attempts, COALESCE(last_error,''), created_at`
+
+func scanOutbox(sc interface{ Scan(...any) error }) (model.OutboxItem, error) {
+	var it model.OutboxItem
+	var claimed, sent sql.NullTime
+	var content string
+	if err := sc.Scan(&it.ID, &it.AccountID, &it.GroupID, &it.CampaignID, &it.TemplateID, &content, &it.Priority, &it.Status,
+		&it.Origin, &it.ScheduledAt, &claimed, &sent, &it.Attempts, &it.LastError, &it.CreatedAt); err != nil {
+		return it, err
+	}
+	if content != "" {
+		it.Content = json.RawMessage(content)
+	}
+	if claimed.Valid {
+		t := claimed.Time
+		it.ClaimedAt = &t
+	}
+	if sent.Valid {
+		t := sent.Time
+		it.SentAt = &t
+	}
+	return it, nil
+}
+
+// ListOutbox mengembalikan item antrean sesuai filter, urut seperti urutan drain
+// (prioritas tertinggi lalu scheduled_at terlama).
+func (s *Store) ListOutbox(f model.OutboxFilter) ([]model.OutboxItem, error) {
+	var where []string
+	var args []any
+	if len(f.Statuses) > 0 {
+		where = append(where, `status IN (?`+strings.Repeat(`,?`, len(f.Statuses)-1)+`)`)
+		for _, st := range f.Statuses {
+			args = append(args, st)
+		}
+	}
+	if f.AccountID != "" {
+		where = append(where, `account_id=?`)
+		args = append(args, f.AccountID)
+	}
+	if f.GroupID != "" {
+		where = append(where, `group_id=?`)
+		args = append(args, f.GroupID)
+	}
+	if f.CampaignID != "" {
+		where = append(where, `campaign_id=?`)
+		args = append(args, f.CampaignID)
+	}
+	q := `SELECT ` + outboxColumns + ` FROM outbox`
+	if len(where) > 0 {
+		q += ` WHERE ` + strings.Join(where, ` AND `)
+	}
+	if f.Limit <= 0 || f.Limit > 1000 {
+		f.Limit = 200
+	}
+	q += ` ORDER BY priority DESC, scheduled_at ASC, id ASC LIMIT ? OFFSET ?`
+	args = append(args, f.Limit, f.Offset)
+	rows, err := s.DB.Query(q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []model.OutboxItem
+	for rows.Next() {
+		it, err := scanOutbox(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, it)
+	}
+	return out, rows.Err()
+}
+
+// GetOutboxItem mengembalikan satu item; sql.ErrNoRows jika tidak ada.
+func (s *Store) GetOutboxItem(id int64) (model.OutboxItem, error) {
+	return scanOutbox(s.DB.QueryRow(`SELECT `+outboxColumns+` FROM outbox WHERE id=?`, id))
+}
+
+// Operasi di bawah hanya berlaku untuk item yang masih pending (belum di-claim worker).
+// Mengembalikan jumlah baris terpengaruh (0 = tidak ada atau bukan pending).
+
+func (s *Store) SetOutboxPriority(id int64, priority int) (int64, error) {
+	return s.execOutbox(`UPDATE outbox SET priority=? WHERE id=? AND status='pending'`, priority, id)
+}
+
+func (s *Store) PostponeOutbox(id int64, until time.Time) (int64, error) {
+	return s.execOutbox(`UPDATE outbox SET scheduled_at=? WHERE id=? AND status='pending'`, until.UTC(), id)
+}
+
+func (s *Store) CancelOutbox(id int64) (int64, error) {
+	return s.execOutbox(`UPDATE outbox SET status='canceled' WHERE id=? AND status='pending'`, id)
+}
+
+// PurgeCampaignOutbox membatalkan semua item pending milik campaign.
+func (s *Store) PurgeCampaignOutbox(campaignID string) (int64, error) {
+	return s.execOutbox(`UPDATE outbox SET status='canceled' WHERE campaign_id=? AND status='pending'`, campaignID)
+}
+
+// CountOutboxByStatus mengembalikan jumlah item per status.
+func (s *Store) CountOutboxByStatus() (map[string]int64, error) {
+	rows, err := s.DB.Query(`SELECT status, COUNT(*) FROM outbox GROUP BY status`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := map[string]int64{}
+	for rows.Next() {
+		var st string
+		var n int64
+		if err := rows.Scan(&st, &n); err != nil {
+			return nil, err
+		}
+		out[st] = n
+	}
+	return out, rows.Err()
+}
+
+func (s *Store) execOutbox(q string, args ...any) (int64, error) {
+	res, err := s.DB.Exec(q, args...)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// EnqueueOutbox menambahkan item pending ke antrean; ScheduledAt kosong = sekarang, Origin kosong =
+// pilihan scheduler.
+func (s *Store) EnqueueOutbox(it model.OutboxItem) (int64, error) {
+	if it.ScheduledAt.IsZero() {
+		it.ScheduledAt = time.Now()
+	}
+	if it.Origin == "" {
+		it.Origin = model.OutboxOriginScheduler
+	}
+	res, err := s.DB.Exec(`INSERT INTO outbox (account_id, group_id, campaign_id, template_id, content, priority, status, origin, scheduled_at)
+		VALUES (?,?,?,?,?,?,'pending',?,?)`,
+		it.AccountID, it.GroupID, nullStr(it.CampaignID), nullStr(it.TemplateID), nullStr(string(it.Content)), it.Priority,
+		it.Origin, it.ScheduledAt.UTC())
+	if err != nil {
+		return 0, err
+	}
+	return res.LastInsertId()
+}
+
+// ClaimNextOutbox mengambil item pending yang sudah jatuh tempo pada now (prioritas tertinggi lalu
+// scheduled_at terlama) dan menandainya claimed secara atomik, sehingga satu item tidak pernah
+// dikirim dua worker. origins non-kosong membatasi asal item. ok=false jika tidak ada item jatuh tempo.
+func (s *Store) ClaimNextOutbox(now time.Time, origins ...string) (model.OutboxItem, bool, error) {
+	cond, args := originFilter(origins)
+	return s.claimOutbox(now, `SELECT id FROM outbox WHERE status='pending' AND scheduled_at <= ?`+cond+`
+		ORDER BY priority DESC, scheduled_at ASC, id ASC LIMIT 1`, append([]any{now.UTC()}, args...)...)
+}
+
+// originFilter kondisi " AND origin IN (...)" untuk origins; kosong jika semua asal.
+func originFilter(origins []string) (string, []any) {
+	if len(origins) == 0 {
+		return "", nil
+	}
+	args := make([]any, len(origins))
+	for i, o := range origins {
+		args[i] = o
+	}
+	return ` AND origin IN (?` + strings.Repeat(`,?`, len(origins)-1) + `)`, args
+}
+
+// ClaimOutboxItem meng-claim item pending tertentu (kiriman pilihan scheduler yang baru di-enqueue)
+// tanpa mendahulukan item lain di antrean; ok=false jika item sudah tidak pending.
+func (s *Store) ClaimOutboxItem(id int64, now time.Time) (model.OutboxItem, bool, error) {
+	return s.claimOutbox(now, `SELECT id FROM outbox WHERE id=? AND status='pending'`, id)
+}
+
+// claimOutbox menandai claimed item hasil query pick (satu id) dalam satu transaksi.
+func (s *Store) claimOutbox(now time.Time, pick string, args ...any) (model.OutboxItem, bool, error) {
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return model.OutboxItem{}, false, err
+	}
+	defer tx.Rollback()
+	var id int64
+	err = tx.QueryRow(pick, args...).Scan(&id)
+	if err == sql.ErrNoRows {
+		return model.OutboxItem{}, false, nil
+	}
+	if err != nil {
+		return model.OutboxItem{}, false, err
+	}
+	res, err := tx.Exec(`UPDATE outbox SET status='claimed', claimed_at=?, attempts=attempts+1 WHERE id=? AND status='pending'`,
+		now.UTC().Format(ctsLayout), id)
+	if err != nil {
+		return model.OutboxItem{}, false, err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return model.OutboxItem{}, false, nil
+	}
+	it, err := scanOutbox(tx.QueryRow(`SELECT `+outboxColumns+` FROM outbox WHERE id=?`, id))
+	if err != nil {
+		return it, false, err
+	}
+	return it, true, tx.Commit()
+}
+
+// NextOutboxDue waktu scheduled_at item pending paling awal (dari origins, jika diisi); ok=false
+// jika antrean kosong.
+func (s *Store) NextOutboxDue(origins ...string) (time.Time, bool, error) {
+	var t time.Time
+	cond, args := originFilter(origins)
+	err := s.DB.QueryRow(`SELECT scheduled_at FROM outbox WHERE status='pending'`+cond+` ORDER BY scheduled_at LIMIT 1`, args...).Scan(&t)
+	if err == sql.ErrNoRows {
+		return t, false, nil
+	}
+	return t, err == nil, err
+}
+
+// FinishOutbox menutup item claimed: sent jika sendErr nil, selain itu failed dengan last_error.
+func (s *Store) FinishOutbox(id int64, sendErr error) error {
+	if sendErr == nil {
+		_, err := s.DB.Exec(`UPDATE outbox SET status='sent', sent_at=?, last_error=NULL WHERE id=? AND status='claimed'`,
+			time.Now().UTC().Format(ctsLayout), id)
+		return err
+	}
+	_, err := s.DB.Exec(`UPDATE outbox SET status='failed', last_error=? WHERE id=? AND status='claimed'`, sendErr.Error(), id)
+	return err
+}
+
+// DeferOutbox mengembalikan item claimed ke pending pada until (mis. akun belum tersambung).
+func (s *Store) DeferOutbox(id int64, until time.Time, reason string) error {
+	_, err := s.DB.Exec(`UPDATE outbox SET status='pending', scheduled_at=?, claimed_at=NULL, last_error=? WHERE id=? AND status='claimed'`,
+		until.UTC(), nullStr(reason), id)
+	return err
+}
+
+// RecoverOutbox membereskan item yang masih claimed saat proses berhenti: item yang kirimannya
+// sudah tercatat sukses di logs sejak di-claim ditandai sent (mencegah kirim ganda), sisanya
+// dikembalikan ke pending.
+func (s *Store) RecoverOutbox() (sent, requeued int64, err error) {
+	res, err := s.DB.Exec(`UPDATE outbox SET status='sent', sent_at=(
+			SELECT MIN(l.ts) FROM logs l WHERE l.account_id=outbox.account_id AND l.group_id=outbox.group_id
+				AND l.status='sent' AND l.ts >= outbox.claimed_at)
+		WHERE status='claimed' AND EXISTS (
+			SELECT 1 FROM logs l WHERE l.account_id=outbox.account_id AND l.group_id=outbox.group_id
+				AND l.status='sent' AND l.ts >= outbox.claimed_at)`)
+	if err != nil {
+		return 0, 0, err
+	}
+	sent, _ = res.RowsAffected()
+	res, err = s.DB.Exec(`UPDATE outbox SET status='pending', claimed_at=NULL, last_error='interrupted before send'
+		WHERE status='claimed'`)
+	if err != nil {
+		return sent, 0, err
+	}
+	requeued, _ = res.RowsAffected()
+	return sent, requeued, nil
+}
@@ -0,0 +1,99 @@
+package storage
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+
+	"promote/internal/model"
+)
+
+// ErrPairingLinkNotFound link pairing tidak ada, kedaluwarsa, dicabut, atau sudah dipakai.
+var ErrPairingLinkNotFound = errors.New("pairing link not found or expired")
+
+const pairingLinkCols = `id, account_id, COALESCE(created_by,''), created_at, expires_at, paired_at, revoked_at`
+
+func scanPairingLink(sc interface{ Scan(...any) error }) (model.PairingLink, error) {
+	var l model.PairingLink
+	var paired, revoked sql.NullTime
+	err := sc.Scan(&l.ID, &l.AccountID, &l.CreatedBy, &l.CreatedAt, &l.ExpiresAt, &paired, &revoked)
+	l.PairedAt = nullTimePtr(paired)
+	l.RevokedAt = nullTimePtr(revoked)
+	return l, err
+}
+
+// CreatePairingLink membuat link pairing untuk akun yang berlaku selama ttl; token mentah
+// dikembalikan sekali dan hanya hash-nya yang disimpan.
+func (s *Store) CreatePairingLink(accountID, createdBy string, ttl time.Duration) (model.PairingLink, string, error) {
+	token, err := randomToken("pl_")
+	if err != nil {
+		return model.PairingLink{}, "", err
+	}
+	now := time.Now().UTC()
+	l := model.PairingLink{
+		ID:        uuid.NewString(),
+		AccountID: accountID,
+		CreatedBy: createdBy,
+		CreatedAt: now,
+		ExpiresAt: now.Add(ttl),
+	}
+	_, err = s.DB.Exec(`INSERT INTO pairing_links (id, account_id, token_hash, created_by, created_at, expires_at)
+		VALUES (?,?,?,?,?,?)`, l.ID, l.AccountID, HashAPIKey(token), nullStr(createdBy), l.CreatedAt, l.ExpiresAt)
+	if err != nil {
+		return model.PairingLink{}, "", err
+	}
+	return l, token, nil
+}
+
+// PairingLinkByToken link pairing yang masih bisa dipakai untuk token; ErrPairingLinkNotFound
+// jika tidak ada, kedaluwarsa, dicabut, atau akun sudah dipasangkan lewat link ini.
+func (s *Store) PairingLinkByToken(token string) (model.PairingLink, error) {
+	l, err := scanPairingLink(s.DB.QueryRow(`SELECT `+pairingLinkCols+` FROM pairing_links
+		WHERE token_hash=? AND expires_at > ? AND revoked_at IS NULL AND paired_at IS NULL`,
+		HashAPIKey(token), time.Now().UTC()))
+	if err == sql.ErrNoRows {
+		return l, ErrPairingLinkNotFound
+	}
+	return l, err
+}
+
+// ListPairingLinks link pairing akun, terbaru dulu.
+func (s *Store) ListPairingLinks(accountID string) ([]model.PairingLink, error) {
+	rows, err := s.DB.Query(`SELECT `+pairingLinkCols+` FROM pairing_links WHERE account_id=? ORDER BY created_at DESC`, accountID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []model.PairingLink
+	for rows.Next() {
+		l, err := scanPairingLink(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, l)
+	}
+	return out, rows.Err()
+}
+
+// RevokePairingLink mencabut link pairing akun; ErrPairingLinkNotFound jika tidak ada atau sudah
+// dicabut.
+func (s *Store) RevokePairingLink(accountID, id string) error {
+	res, err := s.DB.Exec(`UPDATE pairing_links SET revoked_at=? WHERE id=? AND account_id=? AND revoked_at IS NULL`,
+		time.Now().UTC(), id, accountID)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrPairingLinkNotFound
+	}
+	return nil
+}
+
+// MarkPairingLinkUsed menandai link sudah dipakai (akun berhasil dipasangkan) sehingga tidak bisa
+// dibuka lagi.
+func (s *Store) MarkPairingLinkUsed(id string) error {
+	_, err := s.DB.Exec(`UPDATE pairing_links SET paired_at=? WHERE id=? AND paired_at IS NULL`, time.Now().UTC(), id)
+	return err
+}
@@ -0,0 +1,168 @@
+package storage
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+
+	"promote/internal/model"
+)
+
+func (s *Store) CreatePool(name string, rotate bool) (string, error) {
+	id := uuid.NewString()
+	_, err := s.DB.Exec(`INSERT INTO pools (id, name, rotate, created_at) VALUES (?,?,?,CURRENT_TIMESTAMP)`, id, name, btoi(rotate))
+	return id, err
+}
+
+func (s *Store) UpdatePool(id, name string, rotate bool) (int64, error) {
+	res, err := s.DB.Exec(`UPDATE pools SET name=?, rotate=? WHERE id=?`, name, btoi(rotate), id)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// DeletePool menghapus pool; akun anggotanya kembali tanpa pool.
+func (s *Store) DeletePool(id string) (int64, error) {
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+	if _, err := tx.Exec(`UPDATE accounts SET pool_id=NULL WHERE pool_id=?`, id); err != nil {
+		return 0, err
+	}
+	res, err := tx.Exec(`DELETE FROM pools WHERE id=?`, id)
+	if err != nil {
+		return 0, err
+	}
+	n, _ := res.RowsAffected()
+	return n, tx.Commit()
+}
+
+// ListPools mengembalikan semua pool beserta ID akun anggotanya.
+func (s *Store) ListPools() ([]model.Pool, error) {
+	rows, err := s.DB.Query(`SELECT id, name, rotate, created_at FROM pools ORDER BY name`)
+	if err != nil {
+		return nil, err
+	}
+	var out []model.Pool
+	idx := map[string]int{}
+	for rows.Next() {
+		var p model.Pool
+		var rotate int
+		if err := rows.Scan(&p.ID, &p.Name, &rotate, &p.CreatedAt); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		p.Rotate = rotate == 1
+		p.AccountIDs = []string{}
+		idx[p.ID] = len(out)
+		out = append(out, p)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	mrows, err := s.DB.Query(`SELECT id, pool_id FROM accounts WHERE pool_id IS NOT NULL ORDER BY label`)
+	if err != nil {
+		return nil, err
+	}
+	defer mrows.Close()
+	for mrows.Next() {
+		var accID, poolID string
+		if err := mrows.Scan(&accID, &poolID); err != nil {
+			return nil, err
+		}
+		if i, ok := idx[poolID]; ok {
+			out[i].AccountIDs = append(out[i].AccountIDs, accID)
+		}
+	}
+	return out, mrows.Err()
+}
+
+// SetAccountPool memasukkan akun ke pool (poolID kosong = keluarkan dari pool).
+func (s *Store) SetAccountPool(accountID, poolID string) (int64, error) {
+	res, err := s.DB.Exec(`UPDATE accounts SET pool_id=?, updated_at=CURRENT_TIMESTAMP WHERE id=?`, nullStr(poolID), accountID)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// ReplaceGroupMembers mencatat ulang daftar grup yang diikuti akun (hasil sync terbaru).
+func (s *Store) ReplaceGroupMembers(accountID string, groupIDs []string) error {
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if _, err := tx.Exec(`DELETE FROM group_members WHERE account_id=?`, accountID); err != nil {
+		return err
+	}
+	for _, gid := range groupIDs {
+		if _, err := tx.Exec(`INSERT OR IGNORE INTO group_members (group_id, account_id, updated_at) VALUES (?,?,CURRENT_TIMESTAMP)`, gid, accountID); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// AddGroupMember mencatat accountID sebagai anggota groupID (mis. setelah membuat grup).
+func (s *Store) AddGroupMember(accountID, groupID string) error {
+	_, err := s.DB.Exec(`INSERT OR IGNORE INTO group_members (group_id, account_id, updated_at) VALUES (?,?,CURRENT_TIMESTAMP)`, groupID, accountID)
+	return err
+}
+
+// PoolRotationCandidates mengembalikan akun aktif se-pool (pool dengan rotate=1) dengan accountID
+// yang juga anggota groupID, termasuk accountID sendiri. Kosong jika akun tidak di pool rotasi.
+func (s *Store) PoolRotationCandidates(accountID, groupID string) ([]string, error) {
+	var poolID sql.NullString
+	err := s.DB.QueryRow(`SELECT a.pool_id FROM accounts a JOIN pools p ON p.id=a.pool_id AND p.rotate=1 WHERE a.id=?`, accountID).Scan(&poolID)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && !poolID.Valid) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	rows, err := s.DB.Query(`SELECT a.id FROM accounts a
+		WHERE a.pool_id=? AND a.enabled=1 AND a.observer=0 AND a.budget_paused_at IS NULL
+		  AND (a.id=? OR EXISTS (SELECT 1 FROM group_members m WHERE m.group_id=? AND m.account_id=a.id))`,
+		poolID.String, accountID, groupID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		out = append(out, id)
+	}
+	return out, rows.Err()
+}
+
+// LastSendersForGroup mengembalikan waktu kirim sukses terakhir ke grup, per akun.
+func (s *Store) LastSendersForGroup(groupID string) (map[string]time.Time, error) {
+	rows, err := s.DB.Query(`SELECT account_id, MAX(ts) FROM logs WHERE group_id=? AND status='sent' AND account_id IS NOT NULL GROUP BY account_id`, groupID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := map[string]time.Time{}
+	for rows.Next() {
+		var acc string
+		var ts sql.NullString
+		if err := rows.Scan(&acc, &ts); err != nil {
+			return nil, err
+		}
+		if t, ok := parseDBTime(ts); ok {
+			out[acc] = t
+		}
+	}
+	return out, rows.Err()
+}
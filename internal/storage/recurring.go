@@ -0,0 +1,191 @@
+package storage
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"promote/internal/model"
+)
+
+// ErrRecurringNotFound dikembalikan jika jadwal berulang tidak ada.
+var ErrRecurringNotFound = errors.New("recurring schedule not found")
+
+const recurringCols = `id, name, COALESCE(account_id,''), COALESCE(days_mask,''), times, COALESCE(template_id,''),
+	COALESCE(campaign_id,''), COALESCE(targeting,''), priority, enabled, last_run_at, created_at, updated_at`
+
+func scanRecurring(sc interface{ Scan(...any) error }) (model.RecurringSchedule, error) {
+	var r model.RecurringSchedule
+	var times, targeting string
+	var enabled int
+	var lastRun sql.NullTime
+	err := sc.Scan(&r.ID, &r.Name, &r.AccountID, &r.DaysMask, &times, &r.TemplateID, &r.CampaignID, &targeting,
+		&r.Priority, &enabled, &lastRun, &r.CreatedAt, &r.UpdatedAt)
+	if err != nil {
+		return r, err
+	}
+	r.Times = strings.Split(times, ",")
+	r.Enabled = enabled == 1
+	r.LastRunAt = nullTimePtr(lastRun)
+	if targeting != "" {
+		err = json.Unmarshal([]byte(targeting), &r.Targeting)
+	}
+	return r, err
+}
+
+// recurringArgs kolom yang bisa diubah: name, account_id, days_mask, times, template_id,
+// campaign_id, targeting, priority, enabled.
+func recurringArgs(r model.RecurringSchedule) ([]any, error) {
+	var targeting any
+	if !r.Targeting.Empty() {
+		b, err := json.Marshal(r.Targeting)
+		if err != nil {
+			return nil, err
+		}
+		targeting = string(b)
+	}
+	times := make([]string, len(r.Times))
+	for i, v := range r.Times {
+		times[i] = strings.TrimSpace(v)
+	}
+	return []any{r.Name, nullStr(r.AccountID), nullStr(r.DaysMask), strings.Join(times, ","), nullStr(r.TemplateID),
+		nullStr(r.CampaignID), targeting, r.Priority, btoi(r.Enabled)}, nil
+}
+
+// ListRecurringSchedules semua jadwal berulang, terlama dulu; enabledOnly hanya yang aktif.
+func (s *Store) ListRecurringSchedules(enabledOnly bool) ([]model.RecurringSchedule, error) {
+	q := `SELECT ` + recurringCols + ` FROM recurring_schedules`
+	if enabledOnly {
+		q += ` WHERE enabled=1`
+	}
+	rows, err := s.DB.Query(q + ` ORDER BY created_at, id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []model.RecurringSchedule
+	for rows.Next() {
+		r, err := scanRecurring(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, r)
+	}
+	return out, rows.Err()
+}
+
+// GetRecurringSchedule mengambil satu jadwal berulang; ErrRecurringNotFound jika tidak ada.
+func (s *Store) GetRecurringSchedule(id string) (model.RecurringSchedule, error) {
+	r, err := scanRecurring(s.DB.QueryRow(`SELECT `+recurringCols+` FROM recurring_schedules WHERE id=?`, id))
+	if err == sql.ErrNoRows {
+		return r, ErrRecurringNotFound
+	}
+	return r, err
+}
+
+// CreateRecurringSchedule menyimpan jadwal berulang baru dan mengembalikan ID-nya. Jadwal yang
+// waktunya sudah lewat sebelum dibuat tidak dijalankan.
+func (s *Store) CreateRecurringSchedule(r model.RecurringSchedule) (string, error) {
+	args, err := recurringArgs(r)
+	if err != nil {
+		return "", err
+	}
+	id := uuid.NewString()
+	now := time.Now().UTC()
+	_, err = s.DB.Exec(`INSERT INTO recurring_schedules (name, account_id, days_mask, times, template_id, campaign_id,
+		targeting, priority, enabled, id, created_at, updated_at) VALUES (?,?,?,?,?,?,?,?,?,?,?,?)`,
+		append(args, id, now, now)...)
+	if err != nil {
+		return "", err
+	}
+	return id, nil
+}
+
+// UpdateRecurringSchedule mengganti isi jadwal berulang (last_run_at tidak berubah).
+func (s *Store) UpdateRecurringSchedule(r model.RecurringSchedule) error {
+	args, err := recurringArgs(r)
+	if err != nil {
+		return err
+	}
+	res, err := s.DB.Exec(`UPDATE recurring_schedules SET name=?, account_id=?, days_mask=?, times=?, template_id=?,
+		campaign_id=?, targeting=?, priority=?, enabled=?, updated_at=? WHERE id=?`,
+		append(args, time.Now().UTC(), r.ID)...)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrRecurringNotFound
+	}
+	return nil
+}
+
+// DeleteRecurringSchedule menghapus jadwal berulang; item outbox yang sudah dibuat tetap ada.
+func (s *Store) DeleteRecurringSchedule(id string) error {
+	res, err := s.DB.Exec(`DELETE FROM recurring_schedules WHERE id=?`, id)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrRecurringNotFound
+	}
+	return nil
+}
+
+// MarkRecurringRun mencatat jadwal at sudah ditangani tanpa mengantrekan kiriman (mis. terlambat).
+func (s *Store) MarkRecurringRun(id string, at time.Time) error {
+	_, err := s.DB.Exec(`UPDATE recurring_schedules SET last_run_at=? WHERE id=?`, at.UTC(), id)
+	return err
+}
+
+// EnqueueRecurring mengantrekan satu item outbox per grup sasaran jadwal berulang pada at dan
+// mencatat last_run_at dalam satu transaksi, sehingga satu jadwal tidak pernah diantrekan dua
+// kali. Sasaran: grup aktif yang cocok targeting (dan akun jika diisi) milik akun aktif yang
+// bukan observer dan tidak dijeda error budget; grup uji dan grup yang ditinggalkan dilewati.
+func (s *Store) EnqueueRecurring(r model.RecurringSchedule, at time.Time) (int, error) {
+	where, args := targetingWhere(r.Targeting)
+	q := `SELECT g.id, g.account_id FROM groups g
+		WHERE g.enabled=1 AND g.is_test=0 AND g.community_excluded=0 AND g.left_at IS NULL
+			AND g.account_id IN (SELECT id FROM accounts WHERE enabled=1 AND observer=0 AND budget_paused_at IS NULL)
+			AND ` + where
+	if r.AccountID != "" {
+		q += ` AND g.account_id=?`
+		args = append(args, r.AccountID)
+	}
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+	rows, err := tx.Query(q+` ORDER BY g.account_id, g.id`, args...)
+	if err != nil {
+		return 0, err
+	}
+	var targets [][2]string
+	for rows.Next() {
+		var t [2]string
+		if err := rows.Scan(&t[0], &t[1]); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		targets = append(targets, t)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+	for _, t := range targets {
+		if _, err := tx.Exec(`INSERT INTO outbox (account_id, group_id, campaign_id, template_id, priority, status, origin, scheduled_at)
+			VALUES (?,?,?,?,?,'pending',?,?)`,
+			t[1], t[0], nullStr(r.CampaignID), nullStr(r.TemplateID), r.Priority, model.OutboxOriginRecurring, at.UTC()); err != nil {
+			return 0, err
+		}
+	}
+	if _, err := tx.Exec(`UPDATE recurring_schedules SET last_run_at=? WHERE id=?`, at.UTC(), r.ID); err != nil {
+		return 0, err
+	}
+	return len(targets), tx.Commit()
+}
@@ -0,0 +1,173 @@
+package storage
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"promote/internal/model"
+)
+
+// ErrReplyFlowNotFound dikembalikan jika campaign tidak punya alur balasan.
+var ErrReplyFlowNotFound = errors.New("reply flow not found")
+
+const replyFlowCols = `campaign_id, keyword, COALESCE(template_id,''), window_min, max_per_hour, enabled, created_at, updated_at`
+
+func scanReplyFlow(sc interface{ Scan(...any) error }) (model.ReplyFlow, error) {
+	var f model.ReplyFlow
+	var enabled int
+	err := sc.Scan(&f.CampaignID, &f.Keyword, &f.TemplateID, &f.WindowMin, &f.MaxPerHour, &enabled, &f.CreatedAt, &f.UpdatedAt)
+	f.Enabled = enabled == 1
+	return f, err
+}
+
+func (s *Store) queryReplyFlows(q string, args ...any) ([]model.ReplyFlow, error) {
+	rows, err := s.DB.Query(q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var list []model.ReplyFlow
+	for rows.Next() {
+		f, err := scanReplyFlow(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, f)
+	}
+	return list, rows.Err()
+}
+
+// ListReplyFlows mengembalikan semua alur balasan.
+func (s *Store) ListReplyFlows() ([]model.ReplyFlow, error) {
+	return s.queryReplyFlows(`SELECT ` + replyFlowCols + ` FROM reply_flows ORDER BY created_at`)
+}
+
+// GetReplyFlow mengambil alur balasan campaign; ErrReplyFlowNotFound jika belum ada.
+func (s *Store) GetReplyFlow(campaignID string) (model.ReplyFlow, error) {
+	f, err := scanReplyFlow(s.DB.QueryRow(`SELECT `+replyFlowCols+` FROM reply_flows WHERE campaign_id=?`, campaignID))
+	if err == sql.ErrNoRows {
+		return f, ErrReplyFlowNotFound
+	}
+	return f, err
+}
+
+// SaveReplyFlow membuat atau mengganti alur balasan campaign.
+func (s *Store) SaveReplyFlow(f model.ReplyFlow) error {
+	now := time.Now().UTC()
+	_, err := s.DB.Exec(`INSERT INTO reply_flows (campaign_id, keyword, template_id, window_min, max_per_hour, enabled, created_at, updated_at)
+		VALUES (?,?,?,?,?,?,?,?)
+		ON CONFLICT(campaign_id) DO UPDATE SET keyword=excluded.keyword, template_id=excluded.template_id,
+			window_min=excluded.window_min, max_per_hour=excluded.max_per_hour, enabled=excluded.enabled,
+			updated_at=excluded.updated_at`,
+		f.CampaignID, f.Keyword, nullStr(f.TemplateID), f.WindowMin, f.MaxPerHour, btoi(f.Enabled), now, now)
+	return err
+}
+
+// DeleteReplyFlow menghapus alur balasan campaign (opt-in yang sudah tercatat tetap disimpan).
+func (s *Store) DeleteReplyFlow(campaignID string) error {
+	res, err := s.DB.Exec(`DELETE FROM reply_flows WHERE campaign_id=?`, campaignID)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrReplyFlowNotFound
+	}
+	return nil
+}
+
+// OpenReplyFlows mengembalikan alur aktif yang campaign-nya terkirim sukses oleh akun ke grup
+// dalam jendela window_min menit sebelum now.
+func (s *Store) OpenReplyFlows(accountID, groupID string, now time.Time) ([]model.ReplyFlow, error) {
+	return s.queryReplyFlows(`SELECT `+replyFlowCols+` FROM reply_flows f
+		WHERE f.enabled=1 AND f.template_id IS NOT NULL
+		  AND EXISTS (SELECT 1 FROM logs l WHERE l.campaign_id=f.campaign_id AND l.account_id=? AND l.group_id=?
+			AND l.status='sent' AND l.ts >= datetime(?, '-' || f.window_min || ' minutes'))`,
+		accountID, groupID, now.UTC().Format(ctsLayout))
+}
+
+const optInCols = `id, campaign_id, account_id, group_id, number, COALESCE(reply,''), dm_status, COALESCE(dm_error,''), created_at, dm_at`
+
+func scanOptIn(sc interface{ Scan(...any) error }) (model.ReplyOptIn, error) {
+	var o model.ReplyOptIn
+	var dmAt sql.NullTime
+	err := sc.Scan(&o.ID, &o.CampaignID, &o.AccountID, &o.GroupID, &o.Number, &o.Reply, &o.DMStatus, &o.DMError, &o.CreatedAt, &dmAt)
+	if dmAt.Valid {
+		o.DMAt = &dmAt.Time
+	}
+	return o, err
+}
+
+func (s *Store) queryOptIns(q string, args ...any) ([]model.ReplyOptIn, error) {
+	rows, err := s.DB.Query(q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var list []model.ReplyOptIn
+	for rows.Next() {
+		o, err := scanOptIn(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, o)
+	}
+	return list, rows.Err()
+}
+
+// AddOptIn mencatat opt-in berstatus pending; false jika nomor sudah opt-in di campaign ini.
+func (s *Store) AddOptIn(o model.ReplyOptIn) (bool, error) {
+	res, err := s.DB.Exec(`INSERT OR IGNORE INTO reply_optins (campaign_id, account_id, group_id, number, reply, dm_status)
+		VALUES (?,?,?,?,?,?)`, o.CampaignID, o.AccountID, o.GroupID, o.Number, nullStr(o.Reply), model.OptInPending)
+	if err != nil {
+		return false, err
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
+
+// PendingOptIns mengembalikan opt-in yang DM follow-up-nya belum diproses, terlama dulu.
+func (s *Store) PendingOptIns(limit int) ([]model.ReplyOptIn, error) {
+	return s.queryOptIns(`SELECT `+optInCols+` FROM reply_optins WHERE dm_status=? ORDER BY id LIMIT ?`,
+		model.OptInPending, limit)
+}
+
+// SetOptInDM mencatat hasil DM follow-up sebuah opt-in.
+func (s *Store) SetOptInDM(id int64, status, errMsg string) error {
+	_, err := s.DB.Exec(`UPDATE reply_optins SET dm_status=?, dm_error=?, dm_at=? WHERE id=?`,
+		status, nullStr(errMsg), time.Now().UTC(), id)
+	return err
+}
+
+// ListOptIns mengembalikan opt-in campaign (opsional difilter status DM), terbaru dulu.
+func (s *Store) ListOptIns(campaignID, status string) ([]model.ReplyOptIn, error) {
+	return s.queryOptIns(`SELECT `+optInCols+` FROM reply_optins
+		WHERE campaign_id=? AND (?='' OR dm_status=?) ORDER BY id DESC`, campaignID, status, status)
+}
+
+// OptInCounts jumlah opt-in campaign per status DM.
+func (s *Store) OptInCounts(campaignID string) (map[string]int, error) {
+	rows, err := s.DB.Query(`SELECT dm_status, COUNT(*) FROM reply_optins WHERE campaign_id=? GROUP BY dm_status`, campaignID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := map[string]int{}
+	for rows.Next() {
+		var st string
+		var n int
+		if err := rows.Scan(&st, &n); err != nil {
+			return nil, err
+		}
+		out[st] = n
+	}
+	return out, rows.Err()
+}
+
+// CountDMsSince jumlah DM sukses akun sejak since (dm_sends), untuk rate limit DM follow-up.
+func (s *Store) CountDMsSince(accountID string, since time.Time) (int, error) {
+	var n int
+	err := s.DB.QueryRow(`SELECT COUNT(*) FROM dm_sends WHERE account_id=? AND status='sent' AND ts >= ?`,
+		accountID, since.UTC().Format(ctsLayout)).Scan(&n)
+	return n, err
+}
@@ -0,0 +1,33 @@
+package storage
+
+import (
+	"strconv"
+	"strings"
+)
+
+// SettingSchedulerDryRun mode dry-run scheduler ("true"/"false"): kiriman terjadwal hanya dicatat
+// sebagai would_send tanpa memanggil WhatsApp.
+const SettingSchedulerDryRun = "scheduler_dry_run"
+
+// SchedulerDryRun true jika mode dry-run scheduler aktif.
+func (s *Store) SchedulerDryRun() (bool, error) {
+	v, err := s.GetSetting(SettingSchedulerDryRun)
+	if err != nil {
+		return false, err
+	}
+	on, _ := strconv.ParseBool(strings.TrimSpace(v))
+	return on, nil
+}
+
+// SetSchedulerDryRun mengaktifkan/mematikan mode dry-run scheduler.
+func (s *Store) SetSchedulerDryRun(on bool) error {
+	return s.SetSetting(SettingSchedulerDryRun, strconv.FormatBool(on))
+}
+
+// ReleaseGroupReservation mengembalikan last_sent_at grup ke kiriman sukses terakhirnya (NULL jika
+// belum pernah), membatalkan reservasi pemilihan grup yang tidak berujung kiriman (dry-run).
+func (s *Store) ReleaseGroupReservation(groupID string) error {
+	_, err := s.DB.Exec(`UPDATE groups SET last_sent_at=(SELECT MAX(ts) FROM logs WHERE group_id=? AND status='sent') WHERE id=?`,
+		groupID, groupID)
+	return err
+}
@@ -0,0 +1,30 @@
+package storage
+
+import (
+	"encoding/json"
+
+	"promote/internal/model"
+)
+
+// SettingSchedulerPause jeda global scheduler (JSON model.SchedulerPause).
+const SettingSchedulerPause = "scheduler_pause"
+
+// SchedulerPause membaca status jeda scheduler; tidak dijeda jika belum diset.
+func (s *Store) SchedulerPause() (model.SchedulerPause, error) {
+	var p model.SchedulerPause
+	v, err := s.GetSetting(SettingSchedulerPause)
+	if err != nil || v == "" {
+		return p, err
+	}
+	err = json.Unmarshal([]byte(v), &p)
+	return p, err
+}
+
+// SetSchedulerPause menyimpan status jeda scheduler.
+func (s *Store) SetSchedulerPause(p model.SchedulerPause) error {
+	raw, err := json.Marshal(p)
+	if err != nil {
+		return err
+	}
+	return s.SetSetting(SettingSchedulerPause, string(raw))
+}
@@ -0,0 +1,77 @@
+package storage
+
+import (
+	"strconv"
+	"strings"
+
+	"promote/internal/model"
+)
+
+// Kunci settings ritme scheduler.
+const (
+	SettingTickSec      = "scheduler_tick_sec"
+	SettingMinWindowMin = "scheduler_min_window_min"
+	SettingIdleSleep    = "scheduler_idle_sleep"
+	SettingMaxIdleMin   = "scheduler_max_idle_min"
+	SettingPacing       = "scheduler_pacing"
+)
+
+// DefaultSchedulerTiming: tick 30 detik, semua jendela dipakai, tidur di luar jendela maks 15 menit,
+// kiriman disebar merata.
+var DefaultSchedulerTiming = model.SchedulerTiming{TickSec: 30, IdleSleep: true, MaxIdleMin: 15, Pacing: model.PacingEven}
+
+// SchedulerTiming membaca ritme scheduler dari settings (default jika belum diset).
+func (s *Store) SchedulerTiming() (model.SchedulerTiming, error) {
+	t := DefaultSchedulerTiming
+	for key, set := range map[string]func(string){
+		SettingTickSec: func(v string) {
+			if n, err := strconv.Atoi(v); err == nil {
+				t.TickSec = n
+			}
+		},
+		SettingMinWindowMin: func(v string) {
+			if n, err := strconv.Atoi(v); err == nil {
+				t.MinWindowMin = n
+			}
+		},
+		SettingIdleSleep: func(v string) {
+			if b, err := strconv.ParseBool(v); err == nil {
+				t.IdleSleep = b
+			}
+		},
+		SettingMaxIdleMin: func(v string) {
+			if n, err := strconv.Atoi(v); err == nil {
+				t.MaxIdleMin = n
+			}
+		},
+		SettingPacing: func(v string) { t.Pacing = strings.ToLower(v) },
+	} {
+		v, err := s.GetSetting(key)
+		if err != nil {
+			return t, err
+		}
+		if v = strings.TrimSpace(v); v != "" {
+			set(v)
+		}
+	}
+	if t.Validate() != nil {
+		return DefaultSchedulerTiming, nil
+	}
+	return t, nil
+}
+
+// SetSchedulerTiming menyimpan ritme scheduler.
+func (s *Store) SetSchedulerTiming(t model.SchedulerTiming) error {
+	for key, v := range map[string]string{
+		SettingTickSec:      strconv.Itoa(t.TickSec),
+		SettingMinWindowMin: strconv.Itoa(t.MinWindowMin),
+		SettingIdleSleep:    strconv.FormatBool(t.IdleSleep),
+		SettingMaxIdleMin:   strconv.Itoa(t.MaxIdleMin),
+		SettingPacing:       t.Pacing,
+	} {
+		if err := s.SetSetting(key, v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
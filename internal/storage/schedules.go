@@ -0,0 +1,121 @@
+package storage
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+
+	"promote/internal/model"
+)
+
+// ErrScheduleNotFound dikembalikan jika jadwal tidak ada.
+var ErrScheduleNotFound = errors.New("schedule not found")
+
+const scheduleCols = `id, campaign_id, account_id, batch_size, start_hour, end_hour, min_delay_sec, max_delay_sec,
+	COALESCE(days_mask,''), daily_limit, enabled, created_at, updated_at`
+
+func scanSchedule(sc interface{ Scan(...any) error }) (model.Schedule, error) {
+	var sch model.Schedule
+	var enabled int
+	err := sc.Scan(&sch.ID, &sch.CampaignID, &sch.AccountID, &sch.BatchSize, &sch.StartHour, &sch.EndHour,
+		&sch.MinDelaySec, &sch.MaxDelaySec, &sch.DaysMask, &sch.DailyLimit, &enabled, &sch.CreatedAt, &sch.UpdatedAt)
+	sch.Enabled = enabled == 1
+	return sch, err
+}
+
+func (s *Store) querySchedules(q string, args ...any) ([]model.Schedule, error) {
+	rows, err := s.DB.Query(q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var list []model.Schedule
+	for rows.Next() {
+		sch, err := scanSchedule(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, sch)
+	}
+	return list, rows.Err()
+}
+
+// ListSchedules mengembalikan jadwal, opsional difilter campaign dan/atau akun.
+func (s *Store) ListSchedules(campaignID, accountID string) ([]model.Schedule, error) {
+	return s.querySchedules(`SELECT `+scheduleCols+` FROM schedules
+		WHERE (?='' OR campaign_id=?) AND (?='' OR account_id=?)
+		ORDER BY created_at`, campaignID, campaignID, accountID, accountID)
+}
+
+// ActiveSchedules mengembalikan jadwal aktif yang campaign (mode group) dan akunnya juga aktif
+// (akun bukan observer dan tidak sedang dijeda error budget).
+func (s *Store) ActiveSchedules() ([]model.Schedule, error) {
+	return s.querySchedules(`SELECT ` + scheduleCols + ` FROM schedules
+		WHERE enabled=1
+		  AND campaign_id IN (SELECT id FROM campaigns WHERE enabled=1 AND mode='group')
+		  AND account_id IN (SELECT id FROM accounts WHERE enabled=1 AND observer=0 AND budget_paused_at IS NULL)
+		ORDER BY created_at`)
+}
+
+// GetSchedule mengambil satu jadwal; ErrScheduleNotFound jika tidak ada.
+func (s *Store) GetSchedule(id string) (model.Schedule, error) {
+	sch, err := scanSchedule(s.DB.QueryRow(`SELECT `+scheduleCols+` FROM schedules WHERE id=?`, id))
+	if err == sql.ErrNoRows {
+		return sch, ErrScheduleNotFound
+	}
+	return sch, err
+}
+
+// CreateSchedule menyimpan jadwal baru dan mengembalikan ID-nya.
+func (s *Store) CreateSchedule(sch model.Schedule) (string, error) {
+	id := uuid.NewString()
+	now := time.Now().UTC()
+	_, err := s.DB.Exec(`INSERT INTO schedules (id, campaign_id, account_id, batch_size, start_hour, end_hour,
+		min_delay_sec, max_delay_sec, days_mask, daily_limit, enabled, created_at, updated_at)
+		VALUES (?,?,?,?,?,?,?,?,?,?,?,?,?)`,
+		id, sch.CampaignID, sch.AccountID, sch.BatchSize, sch.StartHour, sch.EndHour, sch.MinDelaySec, sch.MaxDelaySec,
+		nullStr(sch.DaysMask), sch.DailyLimit, btoi(sch.Enabled), now, now)
+	if err != nil {
+		return "", err
+	}
+	return id, nil
+}
+
+// UpdateSchedule mengganti seluruh isi jadwal.
+func (s *Store) UpdateSchedule(sch model.Schedule) error {
+	res, err := s.DB.Exec(`UPDATE schedules SET campaign_id=?, account_id=?, batch_size=?, start_hour=?, end_hour=?,
+		min_delay_sec=?, max_delay_sec=?, days_mask=?, daily_limit=?, enabled=?, updated_at=? WHERE id=?`,
+		sch.CampaignID, sch.AccountID, sch.BatchSize, sch.StartHour, sch.EndHour, sch.MinDelaySec, sch.MaxDelaySec,
+		nullStr(sch.DaysMask), sch.DailyLimit, btoi(sch.Enabled), time.Now().UTC(), sch.ID)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrScheduleNotFound
+	}
+	return nil
+}
+
+// DeleteSchedule menghapus jadwal.
+func (s *Store) DeleteSchedule(id string) error {
+	res, err := s.DB.Exec(`DELETE FROM schedules WHERE id=?`, id)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrScheduleNotFound
+	}
+	return nil
+}
+
+// CountSentToday menghitung kiriman sukses hari kuota ini untuk pasangan akun dan campaign.
+func (s *Store) CountSentToday(accountID, campaignID string) (int, error) {
+	var n int
+	from, to := s.TodayArgs()
+	err := s.DB.QueryRow(`SELECT COUNT(*) FROM logs
+		WHERE account_id=? AND campaign_id=? AND status='sent' AND ts >= ? AND ts < ?`,
+		accountID, campaignID, from, to).Scan(&n)
+	return n, err
+}
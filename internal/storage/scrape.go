@@ -0,0 +1,25 @@
+package storage
+
+import "time"
+
+// StaleParticipantGroups mengembalikan ID grup aktif milik akun yang anggotanya belum pernah
+// di-scrape atau terakhir di-scrape sebelum cutoff; yang belum pernah didahulukan.
+func (s *Store) StaleParticipantGroups(accountID string, cutoff time.Time) ([]string, error) {
+	rows, err := s.DB.Query(`SELECT id FROM groups
+		WHERE account_id=? AND enabled=1
+		  AND (participants_synced_at IS NULL OR participants_synced_at < ?)
+		ORDER BY participants_synced_at ASC, id`, accountID, cutoff.UTC().Format(ctsLayout))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
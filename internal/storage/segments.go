@@ -0,0 +1,186 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	"promote/internal/model"
+)
+
+// ErrNotSegment dikembalikan saat me-refresh audiens yang tidak punya aturan segmen.
+var ErrNotSegment = errors.New("audience is not a segment")
+
+// SegmentMatch satu nomor hasil evaluasi segmen beserta jumlah grup cocok tempat nomor itu terlihat.
+type SegmentMatch struct {
+	Number string `json:"number"`
+	Groups int    `json:"groups"`
+}
+
+func segmentArg(rule *model.SegmentRule) any {
+	if rule == nil {
+		return nil
+	}
+	b, _ := json.Marshal(rule)
+	return string(b)
+}
+
+// SegmentNumbers mengevaluasi aturan terhadap cache anggota grup yang masih diikuti. Nomor
+// dinormalisasi (nomor tidak valid dilewati) dan nomor akun sendiri dikecualikan.
+// Hasil urut jumlah grup terbanyak lalu nomor.
+func (s *Store) SegmentNumbers(rule model.SegmentRule) ([]SegmentMatch, error) {
+	q := `SELECT p.number, p.group_id FROM group_participants p JOIN groups g ON g.id=p.group_id
+		WHERE g.left_at IS NULL AND (?='' OR g.account_id=?)`
+	args := []any{rule.AccountID, rule.AccountID}
+	if tags := normalizeTags(rule.Tags); len(tags) > 0 {
+		q += ` AND EXISTS (SELECT 1 FROM group_tags gt WHERE gt.group_id=g.id AND gt.tag IN (?` + strings.Repeat(`,?`, len(tags)-1) + `))`
+		for _, t := range tags {
+			args = append(args, t)
+		}
+	}
+	switch rule.Role {
+	case model.SegmentRoleAdmin:
+		q += ` AND (p.is_admin=1 OR p.is_superadmin=1)`
+	case model.SegmentRoleSuperAdmin:
+		q += ` AND p.is_superadmin=1`
+	case model.SegmentRoleMember:
+		q += ` AND p.is_admin=0 AND p.is_superadmin=0`
+	}
+	own, err := s.accountNumbers()
+	if err != nil {
+		return nil, err
+	}
+	rows, err := s.DB.Query(q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	prefix := strings.TrimPrefix(rule.Prefix, "+")
+	groups := map[string]map[string]bool{}
+	for rows.Next() {
+		var number, groupID string
+		if err := rows.Scan(&number, &groupID); err != nil {
+			return nil, err
+		}
+		n := NormalizeNumber(number)
+		if n == "" || own[n] || !strings.HasPrefix(n, prefix) {
+			continue
+		}
+		if groups[n] == nil {
+			groups[n] = map[string]bool{}
+		}
+		groups[n][groupID] = true
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	min := rule.MinGroups
+	if min < 1 {
+		min = 1
+	}
+	out := []SegmentMatch{}
+	for n, gs := range groups {
+		if len(gs) >= min {
+			out = append(out, SegmentMatch{Number: n, Groups: len(gs)})
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Groups != out[j].Groups {
+			return out[i].Groups > out[j].Groups
+		}
+		return out[i].Number < out[j].Number
+	})
+	return out, nil
+}
+
+// accountNumbers nomor (ternormalisasi) semua akun.
+func (s *Store) accountNumbers() (map[string]bool, error) {
+	rows, err := s.DB.Query(`SELECT COALESCE(msisdn,'') FROM accounts`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := map[string]bool{}
+	for rows.Next() {
+		var m string
+		if err := rows.Scan(&m); err != nil {
+			return nil, err
+		}
+		if n := NormalizeNumber(m); n != "" {
+			out[n] = true
+		}
+	}
+	return out, rows.Err()
+}
+
+// SetAudienceSegment menyimpan (atau melepas, rule nil) aturan segmen audiens. Anggota hasil
+// segmen yang sudah ada tetap tersimpan sampai refresh berikutnya.
+func (s *Store) SetAudienceSegment(id string, rule *model.SegmentRule) error {
+	res, err := s.DB.Exec(`UPDATE audiences SET segment_json=?, updated_at=? WHERE id=?`, segmentArg(rule), time.Now().UTC(), id)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrAudienceNotFound
+	}
+	return nil
+}
+
+// RefreshSegment mengganti anggota bersumber segmen dengan hasil evaluasi aturan saat ini;
+// anggota manual/CSV/grup tidak disentuh. Mengembalikan jumlah nomor yang cocok.
+func (s *Store) RefreshSegment(id string) (int, error) {
+	aud, err := s.GetAudience(id)
+	if err != nil {
+		return 0, err
+	}
+	if aud.Segment == nil {
+		return 0, ErrNotSegment
+	}
+	matches, err := s.SegmentNumbers(*aud.Segment)
+	if err != nil {
+		return 0, err
+	}
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+	if _, err := tx.Exec(`DELETE FROM audience_members WHERE audience_id=? AND source=?`, id, model.AudienceSourceSegment); err != nil {
+		return 0, err
+	}
+	now := time.Now().UTC()
+	for _, m := range matches {
+		if _, err := tx.Exec(`INSERT OR IGNORE INTO audience_members (audience_id, number, source, added_at) VALUES (?,?,?,?)`,
+			id, m.Number, model.AudienceSourceSegment, now); err != nil {
+			return 0, err
+		}
+	}
+	if _, err := tx.Exec(`UPDATE audiences SET refreshed_at=?, updated_at=? WHERE id=?`, now, now, id); err != nil {
+		return 0, err
+	}
+	return len(matches), tx.Commit()
+}
+
+// RefreshSegments me-refresh semua audiens bersegmen (job berkala); mengembalikan jumlah segmen
+// dan total nomor yang cocok.
+func (s *Store) RefreshSegments() (segments, members int, err error) {
+	rows, err := s.DB.Query(`SELECT id FROM audiences WHERE segment_json IS NOT NULL ORDER BY name`)
+	if err != nil {
+		return 0, 0, err
+	}
+	ids, err := scanIDs(rows)
+	if err != nil {
+		return 0, 0, err
+	}
+	for _, id := range ids {
+		n, err := s.RefreshSegment(id)
+		if err != nil {
+			return segments, members, err
+		}
+		segments++
+		members += n
+	}
+	return segments, members, nil
+}
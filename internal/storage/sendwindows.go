@@ -0,0 +1,117 @@
+package storage
+
+import (
+	"database/sql"
+	"encoding/json"
+
+	"promote/internal/model"
+)
+
+// Kunci settings jendela kirim.
+const (
+	// SettingSendWindows jendela kirim scheduler per hari (JSON model.WeekWindows).
+	SettingSendWindows = "send_windows"
+	// SettingWindowBudget pembagian limit harian ke jendela kirim (JSON model.WindowBudget).
+	SettingWindowBudget = "window_budget"
+)
+
+// SendWindows membaca jendela kirim per hari; model.DefaultWeekWindows jika belum diset.
+func (s *Store) SendWindows() (model.WeekWindows, error) {
+	w := model.DefaultWeekWindows()
+	v, err := s.GetSetting(SettingSendWindows)
+	if err != nil || v == "" {
+		return w, err
+	}
+	err = json.Unmarshal([]byte(v), &w)
+	return w, err
+}
+
+// SetSendWindows menyimpan jendela kirim per hari.
+func (s *Store) SetSendWindows(w model.WeekWindows) error {
+	b, err := json.Marshal(w)
+	if err != nil {
+		return err
+	}
+	return s.SetSetting(SettingSendWindows, string(b))
+}
+
+// WindowBudget membaca pembagian limit harian per jendela; mode off jika belum diset.
+func (s *Store) WindowBudget() (model.WindowBudget, error) {
+	b := model.WindowBudget{Mode: model.WindowBudgetOff}
+	v, err := s.GetSetting(SettingWindowBudget)
+	if err != nil || v == "" {
+		return b, err
+	}
+	if err := json.Unmarshal([]byte(v), &b); err != nil || b.Validate() != nil {
+		return model.WindowBudget{Mode: model.WindowBudgetOff}, err
+	}
+	return b, nil
+}
+
+// SetWindowBudget menyimpan pembagian limit harian per jendela.
+func (s *Store) SetWindowBudget(b model.WindowBudget) error {
+	raw, err := json.Marshal(b)
+	if err != nil {
+		return err
+	}
+	return s.SetSetting(SettingWindowBudget, string(raw))
+}
+
+// AccountSendWindows jendela kirim khusus per akun (menggantikan jendela global untuk akun itu).
+func (s *Store) AccountSendWindows() (map[string]model.WeekWindows, error) {
+	rows, err := s.DB.Query(`SELECT account_id, windows FROM account_send_windows`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := map[string]model.WeekWindows{}
+	for rows.Next() {
+		var id, raw string
+		if err := rows.Scan(&id, &raw); err != nil {
+			return nil, err
+		}
+		var w model.WeekWindows
+		if err := json.Unmarshal([]byte(raw), &w); err != nil {
+			return nil, err
+		}
+		out[id] = w
+	}
+	return out, rows.Err()
+}
+
+// AccountSendWindow jendela kirim khusus akun; ok=false jika akun memakai jendela global.
+func (s *Store) AccountSendWindow(accountID string) (model.WeekWindows, bool, error) {
+	var w model.WeekWindows
+	var raw string
+	err := s.DB.QueryRow(`SELECT windows FROM account_send_windows WHERE account_id=?`, accountID).Scan(&raw)
+	if err == sql.ErrNoRows {
+		return w, false, nil
+	}
+	if err != nil {
+		return w, false, err
+	}
+	err = json.Unmarshal([]byte(raw), &w)
+	return w, err == nil, err
+}
+
+// SetAccountSendWindow menyimpan jendela kirim khusus akun.
+func (s *Store) SetAccountSendWindow(accountID string, w model.WeekWindows) error {
+	raw, err := json.Marshal(w)
+	if err != nil {
+		return err
+	}
+	_, err = s.DB.Exec(`INSERT INTO account_send_windows (account_id, windows, updated_at) VALUES (?,?,CURRENT_TIMESTAMP)
+		ON CONFLICT(account_id) DO UPDATE SET windows=excluded.windows, updated_at=excluded.updated_at`, accountID, string(raw))
+	return err
+}
+
+// DeleteAccountSendWindow mengembalikan akun ke jendela global; false jika akun tidak punya
+// jendela khusus.
+func (s *Store) DeleteAccountSendWindow(accountID string) (bool, error) {
+	res, err := s.DB.Exec(`DELETE FROM account_send_windows WHERE account_id=?`, accountID)
+	if err != nil {
+		return false, err
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
@@ -0,0 +1,80 @@
+package storage
+
+import (
+	"database/sql"
+	"strings"
+)
+
+// Kunci tabel settings.
+const (
+	// SettingSafeMode "1" = semua kiriman dialihkan ke grup uji akun.
+	SettingSafeMode = "safe_mode"
+)
+
+// GetSetting membaca nilai setting; "" jika belum diset.
+func (s *Store) GetSetting(key string) (string, error) {
+	var v string
+	err := s.DB.QueryRow(`SELECT value FROM settings WHERE key=?`, key).Scan(&v)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	return v, err
+}
+
+// SetSetting menyimpan nilai setting (upsert).
+func (s *Store) SetSetting(key, value string) error {
+	_, err := s.DB.Exec(`INSERT INTO settings (key, value) VALUES (?,?)
+		ON CONFLICT(key) DO UPDATE SET value=excluded.value, updated_at=CURRENT_TIMESTAMP`, key, value)
+	return err
+}
+
+// SettingBool membaca setting boolean ("1", "true", "yes", "on").
+func (s *Store) SettingBool(key string) (bool, error) {
+	v, err := s.GetSetting(key)
+	if err != nil {
+		return false, err
+	}
+	return truthy(v), nil
+}
+
+// truthy true untuk nilai boolean setting "1", "true", "yes", "on".
+func truthy(v string) bool {
+	switch strings.ToLower(strings.TrimSpace(v)) {
+	case "1", "true", "yes", "on":
+		return true
+	}
+	return false
+}
+
+// SetTestGroup menandai (atau melepas) grup sebagai grup uji. Satu akun hanya punya satu
+// grup uji; menandai grup baru melepas tanda di grup lain milik akun yang sama.
+func (s *Store) SetTestGroup(groupID string, on bool) error {
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if on {
+		if _, err := tx.Exec(`UPDATE groups SET is_test=0 WHERE is_test=1 AND id != ?
+			AND account_id=(SELECT account_id FROM groups WHERE id=?)`, groupID, groupID); err != nil {
+			return err
+		}
+	}
+	if _, err := tx.Exec(`UPDATE groups SET is_test=? WHERE id=?`, btoi(on), groupID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// TestGroupForAccount mengembalikan grup uji yang bisa dikirimi akun: milik akun itu sendiri,
+// atau grup uji lain yang juga diikuti akun (anggota pool). "" jika tidak ada.
+func (s *Store) TestGroupForAccount(accountID string) (string, error) {
+	var id string
+	err := s.DB.QueryRow(`SELECT id FROM groups WHERE is_test=1
+		AND (account_id=? OR id IN (SELECT group_id FROM group_members WHERE account_id=?))
+		ORDER BY CASE WHEN account_id=? THEN 0 ELSE 1 END LIMIT 1`, accountID, accountID, accountID).Scan(&id)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	return id, err
+}
@@ -0,0 +1,137 @@
+package storage
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/mattn/go-sqlite3"
+
+	"promote/internal/model"
+)
+
+// ErrSlugTaken dikembalikan jika slug short link sudah dipakai link lain yang masih aktif.
+var ErrSlugTaken = errors.New("slug already taken")
+
+const shortLinkColumns = `slug, target_url, COALESCE(campaign_id,''), clicks, created_at, expires_at, last_click_at`
+
+func scanShortLink(sc interface{ Scan(...any) error }) (model.ShortLink, error) {
+	var l model.ShortLink
+	var expires, clicked sql.NullTime
+	if err := sc.Scan(&l.Slug, &l.TargetURL, &l.CampaignID, &l.Clicks, &l.CreatedAt, &expires, &clicked); err != nil {
+		return l, err
+	}
+	if expires.Valid {
+		t := expires.Time
+		l.ExpiresAt = &t
+	}
+	if clicked.Valid {
+		t := clicked.Time
+		l.LastClickAt = &t
+	}
+	return l, nil
+}
+
+// InsertShortLink menyimpan short link baru. Slug milik link yang sudah kedaluwarsa boleh dipakai ulang;
+// selain itu bentrok slug menghasilkan ErrSlugTaken.
+func (s *Store) InsertShortLink(l model.ShortLink) error {
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	old, err := scanShortLink(tx.QueryRow(`SELECT `+shortLinkColumns+` FROM short_links WHERE slug=?`, l.Slug))
+	switch {
+	case err == nil:
+		if !old.Expired(time.Now()) {
+			return ErrSlugTaken
+		}
+		if _, err := tx.Exec(`DELETE FROM short_links WHERE slug=?`, l.Slug); err != nil {
+			return err
+		}
+	case !errors.Is(err, sql.ErrNoRows):
+		return err
+	}
+	var expires any
+	if l.ExpiresAt != nil {
+		expires = l.ExpiresAt.UTC()
+	}
+	_, err = tx.Exec(`INSERT INTO short_links (slug,target_url,campaign_id,clicks,created_at,expires_at) VALUES (?,?,?,0,?,?)`,
+		l.Slug, l.TargetURL, nullStr(l.CampaignID), l.CreatedAt.UTC(), expires)
+	if err != nil {
+		var se sqlite3.Error
+		if errors.As(err, &se) && se.Code == sqlite3.ErrConstraint {
+			return ErrSlugTaken
+		}
+		return err
+	}
+	return tx.Commit()
+}
+
+// GetShortLink mengembalikan short link berdasarkan slug; sql.ErrNoRows jika tidak ada.
+func (s *Store) GetShortLink(slug string) (model.ShortLink, error) {
+	return scanShortLink(s.DB.QueryRow(`SELECT `+shortLinkColumns+` FROM short_links WHERE slug=?`, slug))
+}
+
+// FindActiveShortLink mencari link yang belum kedaluwarsa untuk target+campaign yang sama,
+// agar promo berulang memakai slug yang sama. ok=false jika tidak ada.
+func (s *Store) FindActiveShortLink(targetURL, campaignID string, now time.Time) (model.ShortLink, bool, error) {
+	rows, err := s.DB.Query(`SELECT `+shortLinkColumns+` FROM short_links
+		WHERE target_url=? AND COALESCE(campaign_id,'')=? ORDER BY created_at DESC`, targetURL, campaignID)
+	if err != nil {
+		return model.ShortLink{}, false, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		l, err := scanShortLink(rows)
+		if err != nil {
+			return model.ShortLink{}, false, err
+		}
+		if !l.Expired(now) {
+			return l, true, nil
+		}
+	}
+	return model.ShortLink{}, false, rows.Err()
+}
+
+// RecordShortLinkClick menambah counter klik.
+func (s *Store) RecordShortLinkClick(slug string, at time.Time) error {
+	_, err := s.DB.Exec(`UPDATE short_links SET clicks=clicks+1, last_click_at=? WHERE slug=?`, at.UTC(), slug)
+	return err
+}
+
+func (s *Store) ListShortLinks(campaignID string, limit int) ([]model.ShortLink, error) {
+	if limit <= 0 || limit > 1000 {
+		limit = 200
+	}
+	q := `SELECT ` + shortLinkColumns + ` FROM short_links`
+	args := []any{}
+	if campaignID != "" {
+		q += ` WHERE campaign_id=?`
+		args = append(args, campaignID)
+	}
+	q += ` ORDER BY created_at DESC LIMIT ?`
+	args = append(args, limit)
+	rows, err := s.DB.Query(q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []model.ShortLink
+	for rows.Next() {
+		l, err := scanShortLink(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, l)
+	}
+	return out, rows.Err()
+}
+
+func (s *Store) DeleteShortLink(slug string) (int64, error) {
+	res, err := s.DB.Exec(`DELETE FROM short_links WHERE slug=?`, slug)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
@@ -0,0 +1,168 @@
+package storage
+
+import (
+	"database/sql"
+	"time"
+
+	"promote/internal/model"
+)
+
+// SLOSamples mengembalikan sampel round-trip SendMessage (ms) per pesan dan keterlambatan
+// kirim terhadap rencana (ms, per sesi: pesan pertama dikurangi planned_at) sejak since.
+// accountID kosong = semua akun.
+func (s *Store) SLOSamples(since time.Time, accountID string) (rtt, lag []int64, err error) {
+	q := `SELECT message_id, COALESCE(session_id,''), sent_at, rtt_ms, planned_at FROM message_acks WHERE sent_at >= ?`
+	args := []any{since.UTC()}
+	if accountID != "" {
+		q += ` AND account_id=?`
+		args = append(args, accountID)
+	}
+	rows, err := s.DB.Query(q, args...)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+	type session struct{ first, planned time.Time }
+	sessions := map[string]*session{}
+	for rows.Next() {
+		var msgID, sessionID string
+		var sentAt time.Time
+		var rttMs sql.NullInt64
+		var planned sql.NullTime
+		if err := rows.Scan(&msgID, &sessionID, &sentAt, &rttMs, &planned); err != nil {
+			return nil, nil, err
+		}
+		if rttMs.Valid {
+			rtt = append(rtt, rttMs.Int64)
+		}
+		if !planned.Valid {
+			continue
+		}
+		key := sessionID
+		if key == "" {
+			key = msgID
+		}
+		if ss, ok := sessions[key]; !ok {
+			sessions[key] = &session{first: sentAt, planned: planned.Time}
+		} else if sentAt.Before(ss.first) {
+			ss.first = sentAt
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+	for _, ss := range sessions {
+		d := ss.first.Sub(ss.planned).Milliseconds()
+		if d < 0 {
+			d = 0
+		}
+		lag = append(lag, d)
+	}
+	return rtt, lag, nil
+}
+
+// uptimeStatus memetakan status account_events ke online/offline; status lain (mis. jeda
+// budget) tidak mengubah konektivitas.
+var uptimeStatus = map[string]bool{
+	"online":       true,
+	"logged_out":   false,
+	"replaced":     false,
+	"disconnected": false,
+}
+
+// AccountUptimes menghitung porsi waktu online per akun di [since, until) dari account_events.
+// Waktu sebelum event konektivitas pertama yang diketahui tidak dihitung; akun tanpa event sama
+// sekali memakai status akun saat ini untuk seluruh jendela.
+func (s *Store) AccountUptimes(since, until time.Time, accountID string) ([]model.AccountUptime, error) {
+	q := `SELECT id, COALESCE(label,''), COALESCE(status,'') FROM accounts`
+	var args []any
+	if accountID != "" {
+		q += ` WHERE id=?`
+		args = append(args, accountID)
+	}
+	rows, err := s.DB.Query(q+` ORDER BY label`, args...)
+	if err != nil {
+		return nil, err
+	}
+	var list []model.AccountUptime
+	for rows.Next() {
+		var u model.AccountUptime
+		if err := rows.Scan(&u.AccountID, &u.Label, &u.Status); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		list = append(list, u)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	out := make([]model.AccountUptime, 0, len(list))
+	for _, u := range list {
+		if err := s.accountUptime(&u, since, until); err != nil {
+			return nil, err
+		}
+		out = append(out, u)
+	}
+	return out, nil
+}
+
+func (s *Store) accountUptime(u *model.AccountUptime, since, until time.Time) error {
+	from, to := since.UTC().Format(ctsLayout), until.UTC().Format(ctsLayout)
+	state, known := "", false
+	var prior string
+	err := s.DB.QueryRow(`SELECT status FROM account_events
+		WHERE account_id=? AND ts < ? AND status IN ('online','logged_out','replaced','disconnected')
+		ORDER BY ts DESC, id DESC LIMIT 1`, u.AccountID, from).Scan(&prior)
+	if err != nil && err != sql.ErrNoRows {
+		return err
+	}
+	if err == nil {
+		state, known = prior, true
+	}
+	rows, err := s.DB.Query(`SELECT status, ts FROM account_events
+		WHERE account_id=? AND ts >= ? AND ts < ? AND status IN ('online','logged_out','replaced','disconnected')
+		ORDER BY ts, id`, u.AccountID, from, to)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	var online, observed time.Duration
+	cur := since
+	events := 0
+	for rows.Next() {
+		var st string
+		var ts time.Time
+		if err := rows.Scan(&st, &ts); err != nil {
+			return err
+		}
+		events++
+		if known {
+			observed += ts.Sub(cur)
+			if uptimeStatus[state] {
+				online += ts.Sub(cur)
+			}
+		}
+		state, known, cur = st, true, ts
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	if !known && events == 0 {
+		// Tidak ada riwayat: anggap status saat ini berlaku sepanjang jendela.
+		state, known = u.Status, true
+	}
+	if known {
+		observed += until.Sub(cur)
+		if uptimeStatus[state] {
+			online += until.Sub(cur)
+		}
+		u.Status = state
+	}
+	u.OnlineSec = int64(online.Seconds())
+	u.ObservedSec = int64(observed.Seconds())
+	if observed > 0 {
+		u.UptimePct = float64(online) * 100 / float64(observed)
+	}
+	return nil
+}
@@ -3,6 +3,7 @@ package storage
 import (
 	"database/sql"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -13,6 +14,8 @@ import (
 
 type Store struct {
 	DB *sql.DB
+	// Day batas hari untuk limit harian dan statistik "hari ini" (DAY_RESET_TZ, DAY_RESET_HOUR).
+	Day DayBoundary
 }
 
 // Open opens/initializes SQLite database with WAL and foreign keys, then migrates schema.
@@ -31,7 +34,7 @@ func Open(dsn string) (*Store, error) {
 		db.Close()
 		return nil, fmt.Errorf("migrate: %w", err)
 	}
-	return &Store{DB: db}, nil
+	return &Store{DB: db, Day: DayBoundaryFromEnv()}, nil
 }
 
 // Close closes underlying DB.
@@ -198,6 +201,654 @@ func migrate(db *sql.DB) error {
 	_, _ = tx.Exec(`CREATE INDEX IF NOT EXISTS idx_auto_join_logs_status ON auto_join_logs(account_id, status, joined_at);`)
 	_, _ = tx.Exec(`CREATE INDEX IF NOT EXISTS idx_auto_join_logs_code ON auto_join_logs(account_id, invite_code);`)
 	
+	// Feed watcher: sumber RSS/Atom/JSON per campaign dan item yang sudah diproses
+	_, _ = tx.Exec(`CREATE TABLE IF NOT EXISTS feeds (
+		id TEXT PRIMARY KEY,
+		campaign_id TEXT,
+		name TEXT NOT NULL,
+		url TEXT NOT NULL,
+		mode TEXT NOT NULL DEFAULT 'approval',
+		enabled INTEGER NOT NULL DEFAULT 1,
+		poll_minutes INTEGER NOT NULL DEFAULT 15,
+		last_polled_at TIMESTAMP,
+		last_error TEXT,
+		created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY(campaign_id) REFERENCES campaigns(id) ON DELETE SET NULL
+	)`)
+	_, _ = tx.Exec(`CREATE TABLE IF NOT EXISTS feed_items (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		feed_id TEXT NOT NULL,
+		guid TEXT NOT NULL,
+		title TEXT,
+		link TEXT,
+		summary TEXT,
+		image_url TEXT,
+		template_id TEXT,
+		status TEXT NOT NULL,
+		created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		UNIQUE(feed_id, guid),
+		FOREIGN KEY(feed_id) REFERENCES feeds(id) ON DELETE CASCADE,
+		FOREIGN KEY(template_id) REFERENCES templates(id) ON DELETE SET NULL
+	)`)
+	_, _ = tx.Exec(`CREATE INDEX IF NOT EXISTS idx_feed_items_status ON feed_items(feed_id, status);`)
+
+	// Template: kirim teks dengan link preview (ExtendedTextMessage)
+	_, _ = tx.Exec(`ALTER TABLE templates ADD COLUMN link_preview INTEGER NOT NULL DEFAULT 0;`)
+
+	// Short link (redirect ter-tracking) untuk link di promo
+	_, _ = tx.Exec(`CREATE TABLE IF NOT EXISTS short_links (
+		slug TEXT PRIMARY KEY,
+		target_url TEXT NOT NULL,
+		campaign_id TEXT,
+		clicks INTEGER NOT NULL DEFAULT 0,
+		created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		expires_at TIMESTAMP,
+		last_click_at TIMESTAMP
+	)`)
+	_, _ = tx.Exec(`CREATE INDEX IF NOT EXISTS idx_short_links_target ON short_links(target_url, campaign_id);`)
+
+	// Outbox: antrean kirim persisten (pending -> claimed -> sent/failed, atau canceled)
+	_, _ = tx.Exec(`CREATE TABLE IF NOT EXISTS outbox (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		account_id TEXT NOT NULL,
+		group_id TEXT NOT NULL,
+		campaign_id TEXT,
+		template_id TEXT,
+		priority INTEGER NOT NULL DEFAULT 0,
+		status TEXT NOT NULL DEFAULT 'pending',
+		scheduled_at TIMESTAMP NOT NULL,
+		claimed_at TIMESTAMP,
+		sent_at TIMESTAMP,
+		attempts INTEGER NOT NULL DEFAULT 0,
+		last_error TEXT,
+		created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY(account_id) REFERENCES accounts(id) ON DELETE CASCADE
+	)`)
+	_, _ = tx.Exec(`CREATE INDEX IF NOT EXISTS idx_outbox_status_sched ON outbox(status, scheduled_at);`)
+	_, _ = tx.Exec(`CREATE INDEX IF NOT EXISTS idx_outbox_campaign ON outbox(campaign_id, status);`)
+
+	// Compliance: deskripsi grup + aturan frasa "dilarang promo"
+	_, _ = tx.Exec(`ALTER TABLE groups ADD COLUMN description TEXT;`)
+	_, _ = tx.Exec(`ALTER TABLE groups ADD COLUMN compliance_flag TEXT;`)
+	_, _ = tx.Exec(`ALTER TABLE groups ADD COLUMN compliance_checked_at TIMESTAMP;`)
+	_, _ = tx.Exec(`CREATE TABLE IF NOT EXISTS compliance_rules (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		phrase TEXT NOT NULL UNIQUE,
+		action TEXT NOT NULL DEFAULT 'flag',
+		enabled INTEGER NOT NULL DEFAULT 1,
+		created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+	)`)
+	// Seed aturan default saat tabel masih kosong (instalasi baru); nonaktifkan aturan lewat API, jangan hapus semua.
+	var ruleCount int
+	_ = tx.QueryRow(`SELECT COUNT(*) FROM compliance_rules`).Scan(&ruleCount)
+	if ruleCount == 0 {
+		for _, p := range []string{
+			"no promo", "dilarang promo", "dilarang promosi", "anti promo", "no jualan", "dilarang jualan",
+			"dilarang jual", "no ads", "dilarang iklan", "no spam", "dilarang spam", "dilarang share link",
+			"no link", "promo kick", "jualan kick",
+		} {
+			_, _ = tx.Exec(`INSERT OR IGNORE INTO compliance_rules (phrase, action) VALUES (?, 'flag')`, p)
+		}
+	}
+
+	// Pool akun + keanggotaan grup per akun (satu grup bisa diikuti beberapa akun)
+	_, _ = tx.Exec(`CREATE TABLE IF NOT EXISTS pools (
+		id TEXT PRIMARY KEY,
+		name TEXT NOT NULL,
+		rotate INTEGER NOT NULL DEFAULT 1,
+		created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+	)`)
+	_, _ = tx.Exec(`ALTER TABLE accounts ADD COLUMN pool_id TEXT REFERENCES pools(id) ON DELETE SET NULL;`)
+	_, _ = tx.Exec(`CREATE TABLE IF NOT EXISTS group_members (
+		group_id TEXT NOT NULL,
+		account_id TEXT NOT NULL,
+		updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY(group_id, account_id),
+		FOREIGN KEY(account_id) REFERENCES accounts(id) ON DELETE CASCADE
+	)`)
+	_, _ = tx.Exec(`CREATE INDEX IF NOT EXISTS idx_group_members_account ON group_members(account_id);`)
+
+	// Ack pesan terkirim: delivered vs soft_bounce (tidak ada receipt sampai timeout)
+	_, _ = tx.Exec(`CREATE TABLE IF NOT EXISTS message_acks (
+		message_id TEXT NOT NULL,
+		account_id TEXT NOT NULL,
+		group_id TEXT NOT NULL,
+		session_id TEXT,
+		status TEXT NOT NULL DEFAULT 'pending',
+		sent_at TIMESTAMP NOT NULL,
+		delivered_at TIMESTAMP,
+		PRIMARY KEY(account_id, message_id)
+	)`)
+	_, _ = tx.Exec(`CREATE INDEX IF NOT EXISTS idx_message_acks_status ON message_acks(status, sent_at);`)
+
+	// Event status akun (online/logged_out/replaced) untuk hitung insiden di digest harian
+	_, _ = tx.Exec(`CREATE TABLE IF NOT EXISTS account_events (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		account_id TEXT NOT NULL,
+		status TEXT NOT NULL,
+		message TEXT,
+		ts TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+	)`)
+	_, _ = tx.Exec(`CREATE INDEX IF NOT EXISTS idx_account_events_account ON account_events(account_id, ts);`)
+
+	// Digest harian per akun
+	_, _ = tx.Exec(`CREATE TABLE IF NOT EXISTS digests (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		account_id TEXT NOT NULL,
+		day TEXT NOT NULL,
+		sent INTEGER NOT NULL DEFAULT 0,
+		failed INTEGER NOT NULL DEFAULT 0,
+		soft_bounced INTEGER NOT NULL DEFAULT 0,
+		daily_limit INTEGER NOT NULL DEFAULT 0,
+		utilization REAL NOT NULL DEFAULT 0,
+		incidents INTEGER NOT NULL DEFAULT 0,
+		joins INTEGER NOT NULL DEFAULT 0,
+		created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		UNIQUE(account_id, day),
+		FOREIGN KEY(account_id) REFERENCES accounts(id) ON DELETE CASCADE
+	)`)
+	_, _ = tx.Exec(`CREATE INDEX IF NOT EXISTS idx_digests_day ON digests(day);`)
+
+	// Status task cron housekeeping (persisten agar jadwal tidak reset saat restart)
+	_, _ = tx.Exec(`CREATE TABLE IF NOT EXISTS cron_tasks (
+		name TEXT PRIMARY KEY,
+		last_started_at TIMESTAMP,
+		last_finished_at TIMESTAMP,
+		last_status TEXT,
+		last_message TEXT,
+		last_error TEXT,
+		last_duration_ms INTEGER NOT NULL DEFAULT 0,
+		runs INTEGER NOT NULL DEFAULT 0,
+		next_run_at TIMESTAMP
+	)`)
+	// Hasil cek kesehatan media template (kosong = sehat)
+	_, _ = tx.Exec(`ALTER TABLE templates ADD COLUMN health_error TEXT;`)
+	_, _ = tx.Exec(`ALTER TABLE templates ADD COLUMN health_checked_at TIMESTAMP;`)
+
+	// Custom field per grup untuk personalisasi template ({field:key})
+	_, _ = tx.Exec(`CREATE TABLE IF NOT EXISTS group_fields (
+		group_id TEXT NOT NULL,
+		key TEXT NOT NULL,
+		value TEXT NOT NULL,
+		updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY(group_id, key),
+		FOREIGN KEY(group_id) REFERENCES groups(id) ON DELETE CASCADE
+	)`)
+
+	// Atribut grup untuk targeting campaign: tag, jumlah anggota, bahasa
+	_, _ = tx.Exec(`CREATE TABLE IF NOT EXISTS tags (
+		name TEXT PRIMARY KEY,
+		created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+	)`)
+	_, _ = tx.Exec(`CREATE TABLE IF NOT EXISTS group_tags (
+		group_id TEXT NOT NULL,
+		tag TEXT NOT NULL,
+		PRIMARY KEY(group_id, tag),
+		FOREIGN KEY(group_id) REFERENCES groups(id) ON DELETE CASCADE,
+		FOREIGN KEY(tag) REFERENCES tags(name) ON DELETE CASCADE
+	)`)
+	_, _ = tx.Exec(`CREATE INDEX IF NOT EXISTS idx_group_tags_tag ON group_tags(tag);`)
+	_, _ = tx.Exec(`ALTER TABLE groups ADD COLUMN participant_count INTEGER NOT NULL DEFAULT 0;`)
+	_, _ = tx.Exec(`ALTER TABLE groups ADD COLUMN language TEXT;`)
+	// Filter targeting per campaign (JSON model.Targeting)
+	_, _ = tx.Exec(`ALTER TABLE campaigns ADD COLUMN targeting TEXT;`)
+
+	// Media tambahan campaign (JSON array URL)
+	_, _ = tx.Exec(`ALTER TABLE campaigns ADD COLUMN media_stickers TEXT;`)
+	_, _ = tx.Exec(`ALTER TABLE campaigns ADD COLUMN media_docs TEXT;`)
+
+	// Pengaturan global key/value (mis. safe_mode)
+	_, _ = tx.Exec(`CREATE TABLE IF NOT EXISTS settings (
+		key TEXT PRIMARY KEY,
+		value TEXT NOT NULL,
+		updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+	)`)
+	// Grup uji per akun: tujuan semua kiriman saat safe mode aktif
+	_, _ = tx.Exec(`ALTER TABLE groups ADD COLUMN is_test INTEGER NOT NULL DEFAULT 0;`)
+	// Waktu terakhir anggota grup di-scrape (job scraping inkremental)
+	_, _ = tx.Exec(`ALTER TABLE groups ADD COLUMN participants_synced_at TIMESTAMP;`)
+	// API key untuk autentikasi route yang mengubah data (hanya hash SHA-256 yang disimpan)
+	_, _ = tx.Exec(`CREATE TABLE IF NOT EXISTS api_keys (
+		id TEXT PRIMARY KEY,
+		name TEXT NOT NULL,
+		prefix TEXT NOT NULL,
+		key_hash TEXT NOT NULL UNIQUE,
+		created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		last_used_at TIMESTAMP,
+		revoked_at TIMESTAMP
+	)`)
+	// DM: daftar supresi, riwayat kirim DM, dan snapshot audiens hasil preflight per campaign
+	_, _ = tx.Exec(`CREATE TABLE IF NOT EXISTS dm_suppressions (
+		number TEXT PRIMARY KEY,
+		reason TEXT,
+		created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+	)`)
+	_, _ = tx.Exec(`CREATE TABLE IF NOT EXISTS dm_sends (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		ts TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		account_id TEXT,
+		number TEXT NOT NULL,
+		campaign_id TEXT,
+		status TEXT NOT NULL,
+		error TEXT
+	)`)
+	_, _ = tx.Exec(`CREATE INDEX IF NOT EXISTS idx_dm_sends_number ON dm_sends(number, ts);`)
+	_, _ = tx.Exec(`CREATE TABLE IF NOT EXISTS dm_runs (
+		id TEXT PRIMARY KEY,
+		campaign_id TEXT NOT NULL,
+		account_id TEXT NOT NULL,
+		input_count INTEGER NOT NULL DEFAULT 0,
+		audience TEXT NOT NULL,
+		removed TEXT NOT NULL,
+		created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY(campaign_id) REFERENCES campaigns(id) ON DELETE CASCADE
+	)`)
+	_, _ = tx.Exec(`CREATE INDEX IF NOT EXISTS idx_dm_runs_campaign ON dm_runs(campaign_id, created_at);`)
+	// Multi-user: akun login (admin|operator), sesi login, dan role per API key
+	_, _ = tx.Exec(`CREATE TABLE IF NOT EXISTS users (
+		id TEXT PRIMARY KEY,
+		username TEXT NOT NULL UNIQUE,
+		password_hash TEXT NOT NULL,
+		role TEXT NOT NULL DEFAULT 'operator',
+		disabled INTEGER NOT NULL DEFAULT 0,
+		created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		last_login_at TIMESTAMP
+	)`)
+	_, _ = tx.Exec(`CREATE TABLE IF NOT EXISTS user_sessions (
+		token_hash TEXT PRIMARY KEY,
+		user_id TEXT NOT NULL,
+		created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		expires_at TIMESTAMP NOT NULL,
+		FOREIGN KEY(user_id) REFERENCES users(id) ON DELETE CASCADE
+	)`)
+	_, _ = tx.Exec(`ALTER TABLE api_keys ADD COLUMN role TEXT NOT NULL DEFAULT 'admin';`)
+	// Variabel dinamis template: {nama:key} di-resolve saat kirim lewat HTTP lookup
+	_, _ = tx.Exec(`CREATE TABLE IF NOT EXISTS dynamic_vars (
+		name TEXT PRIMARY KEY,
+		url_template TEXT NOT NULL,
+		json_path TEXT,
+		timeout_ms INTEGER NOT NULL DEFAULT 3000,
+		cache_ttl_sec INTEGER NOT NULL DEFAULT 300,
+		fallback TEXT,
+		enabled INTEGER NOT NULL DEFAULT 1,
+		created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+	)`)
+	// Webhook keluar untuk event kirim/akun/auto-join beserta log pengiriman (dengan retry)
+	_, _ = tx.Exec(`CREATE TABLE IF NOT EXISTS webhooks (
+		id TEXT PRIMARY KEY,
+		name TEXT NOT NULL,
+		url TEXT NOT NULL,
+		events TEXT,
+		secret TEXT,
+		enabled INTEGER NOT NULL DEFAULT 1,
+		created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+	)`)
+	_, _ = tx.Exec(`CREATE TABLE IF NOT EXISTS webhook_deliveries (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		webhook_id TEXT NOT NULL,
+		event TEXT NOT NULL,
+		payload TEXT NOT NULL,
+		status TEXT NOT NULL DEFAULT 'pending',
+		attempts INTEGER NOT NULL DEFAULT 0,
+		response_code INTEGER,
+		last_error TEXT,
+		next_attempt_at TIMESTAMP NOT NULL,
+		created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		delivered_at TIMESTAMP,
+		FOREIGN KEY(webhook_id) REFERENCES webhooks(id) ON DELETE CASCADE
+	)`)
+	_, _ = tx.Exec(`CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_due ON webhook_deliveries(status, next_attempt_at);`)
+	_, _ = tx.Exec(`CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_hook ON webhook_deliveries(webhook_id, created_at);`)
+	// Error budget per akun: jeda otomatis saat rasio gagal melewati budget, override manual
+	_, _ = tx.Exec(`ALTER TABLE accounts ADD COLUMN budget_paused_at TIMESTAMP;`)
+	_, _ = tx.Exec(`ALTER TABLE accounts ADD COLUMN budget_pause_reason TEXT;`)
+	_, _ = tx.Exec(`ALTER TABLE accounts ADD COLUMN budget_override_until TIMESTAMP;`)
+	// Label akun unik (case-insensitive); label duplikat lama diberi suffix ID agar index bisa dibuat
+	_, _ = tx.Exec(`UPDATE accounts SET label = label || '-' || substr(id,1,8)
+		WHERE rowid NOT IN (SELECT MIN(rowid) FROM accounts GROUP BY label COLLATE NOCASE)`)
+	_, _ = tx.Exec(`CREATE UNIQUE INDEX IF NOT EXISTS idx_accounts_label ON accounts(label COLLATE NOCASE);`)
+	// Kartu kontak (vCard) di template
+	_, _ = tx.Exec(`ALTER TABLE templates ADD COLUMN contact_name TEXT;`)
+	_, _ = tx.Exec(`ALTER TABLE templates ADD COLUMN contact_phone TEXT;`)
+	// Kebijakan media gagal per template
+	_, _ = tx.Exec(`ALTER TABLE templates ADD COLUMN media_fail_policy TEXT;`)
+	_, _ = tx.Exec(`ALTER TABLE templates ADD COLUMN fallback_image_url TEXT;`)
+	// Mention-all per template
+	_, _ = tx.Exec(`ALTER TABLE templates ADD COLUMN mention_all INTEGER NOT NULL DEFAULT 0;`)
+	// SLO: round-trip kirim WA per pesan dan waktu rencana (jadwal/outbox)
+	_, _ = tx.Exec(`ALTER TABLE message_acks ADD COLUMN rtt_ms INTEGER;`)
+	_, _ = tx.Exec(`ALTER TABLE message_acks ADD COLUMN planned_at TIMESTAMP;`)
+	// Voice note (PTT) per template
+	_, _ = tx.Exec(`ALTER TABLE templates ADD COLUMN voice_json TEXT;`)
+	// Cold-start ramp: tanggal paling awal grup baru boleh menerima kiriman pertama
+	_, _ = tx.Exec(`ALTER TABLE groups ADD COLUMN ramp_at TIMESTAMP;`)
+	// Video GIF (GifPlayback) per template
+	_, _ = tx.Exec(`ALTER TABLE templates ADD COLUMN gifs_json TEXT;`)
+	// Item katalog (product message) per template
+	_, _ = tx.Exec(`ALTER TABLE templates ADD COLUMN products_json TEXT;`)
+	// ID pesan WhatsApp per log kirim (untuk edit pesan)
+	_, _ = tx.Exec(`ALTER TABLE logs ADD COLUMN message_id TEXT;`)
+	// Waktu pesan dihapus untuk semua orang (revoke)
+	_, _ = tx.Exec(`ALTER TABLE logs ADD COLUMN revoked_at TIMESTAMP;`)
+	// Profil WhatsApp Business per akun: hasil sync (live) dan versi terkelola (managed)
+	_, _ = tx.Exec(`CREATE TABLE IF NOT EXISTS business_profiles (
+		account_id TEXT PRIMARY KEY REFERENCES accounts(id) ON DELETE CASCADE,
+		is_business INTEGER NOT NULL DEFAULT 0,
+		live_json TEXT,
+		managed_json TEXT,
+		synced_at TIMESTAMP,
+		sync_error TEXT,
+		updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+	)`)
+	// Alur balasan kata kunci per campaign ("balas PROMO") dan opt-in yang tertangkap
+	_, _ = tx.Exec(`CREATE TABLE IF NOT EXISTS reply_flows (
+		campaign_id TEXT PRIMARY KEY REFERENCES campaigns(id) ON DELETE CASCADE,
+		keyword TEXT NOT NULL,
+		template_id TEXT REFERENCES templates(id) ON DELETE SET NULL,
+		window_min INTEGER NOT NULL DEFAULT 60,
+		max_per_hour INTEGER NOT NULL DEFAULT 20,
+		enabled INTEGER NOT NULL DEFAULT 1,
+		created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+	)`)
+	_, _ = tx.Exec(`CREATE TABLE IF NOT EXISTS reply_optins (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		campaign_id TEXT NOT NULL REFERENCES campaigns(id) ON DELETE CASCADE,
+		account_id TEXT NOT NULL,
+		group_id TEXT NOT NULL,
+		number TEXT NOT NULL,
+		reply TEXT,
+		dm_status TEXT NOT NULL DEFAULT 'pending',
+		dm_error TEXT,
+		created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		dm_at TIMESTAMP,
+		UNIQUE(campaign_id, number)
+	)`)
+	_, _ = tx.Exec(`CREATE INDEX IF NOT EXISTS idx_reply_optins_status ON reply_optins(dm_status, id);`)
+	// Campaign mode dm: kirim ke nomor (dm_targets) dengan limit DM harian per akun
+	_, _ = tx.Exec(`ALTER TABLE campaigns ADD COLUMN mode TEXT NOT NULL DEFAULT 'group';`)
+	_, _ = tx.Exec(`ALTER TABLE accounts ADD COLUMN dm_daily_limit INTEGER NOT NULL DEFAULT 30;`)
+	_, _ = tx.Exec(`CREATE TABLE IF NOT EXISTS dm_targets (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		campaign_id TEXT NOT NULL REFERENCES campaigns(id) ON DELETE CASCADE,
+		account_id TEXT NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+		number TEXT NOT NULL,
+		run_id TEXT,
+		status TEXT NOT NULL DEFAULT 'pending',
+		error TEXT,
+		created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		sent_at TIMESTAMP,
+		UNIQUE(campaign_id, number)
+	)`)
+	_, _ = tx.Exec(`CREATE INDEX IF NOT EXISTS idx_dm_targets_pending ON dm_targets(account_id, status, id);`)
+	// Audiens: daftar nomor (manual, CSV, anggota grup) yang bisa dipakai sebagai target DM
+	_, _ = tx.Exec(`CREATE TABLE IF NOT EXISTS audiences (
+		id TEXT PRIMARY KEY,
+		name TEXT NOT NULL,
+		description TEXT,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);`)
+	_, _ = tx.Exec(`CREATE TABLE IF NOT EXISTS audience_members (
+		audience_id TEXT NOT NULL REFERENCES audiences(id) ON DELETE CASCADE,
+		number TEXT NOT NULL,
+		name TEXT,
+		source TEXT NOT NULL DEFAULT 'manual',
+		added_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (audience_id, number)
+	);`)
+	// Hasil lint konten template (skor risiko + issue) dalam JSON
+	_, _ = tx.Exec(`ALTER TABLE templates ADD COLUMN lint_json TEXT;`)
+	// Daftar supresi global: grup yang tidak boleh dikirimi + asal supresi nomor (manual/stop_reply)
+	_, _ = tx.Exec(`CREATE TABLE IF NOT EXISTS group_suppressions (
+		group_id TEXT PRIMARY KEY,
+		reason TEXT,
+		created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+	)`)
+	_, _ = tx.Exec(`ALTER TABLE dm_suppressions ADD COLUMN source TEXT NOT NULL DEFAULT 'manual';`)
+	// Versi template: snapshot isi tiap perubahan + versi yang dipakai di setiap log kirim
+	_, _ = tx.Exec(`ALTER TABLE templates ADD COLUMN version INTEGER NOT NULL DEFAULT 1;`)
+	_, _ = tx.Exec(`CREATE TABLE IF NOT EXISTS template_versions (
+		template_id TEXT NOT NULL,
+		version INTEGER NOT NULL,
+		content_json TEXT NOT NULL,
+		created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (template_id, version)
+	)`)
+	_, _ = tx.Exec(`ALTER TABLE logs ADD COLUMN template_id TEXT;`)
+	_, _ = tx.Exec(`ALTER TABLE logs ADD COLUMN template_version INTEGER;`)
+	// Template lama (sebelum versioning) mendapat snapshot versi saat ini
+	if err := backfillTemplateVersions(tx); err != nil {
+		return err
+	}
+	// Grup yang ditinggalkan lewat API (soft delete; hilang dari daftar sampai join lagi)
+	_, _ = tx.Exec(`ALTER TABLE groups ADD COLUMN left_at TIMESTAMP;`)
+	// Mode observer: akun hanya untuk monitoring, semua jalur kirim menolaknya
+	_, _ = tx.Exec(`ALTER TABLE accounts ADD COLUMN observer INTEGER NOT NULL DEFAULT 0;`)
+	// WhatsApp Channels (newsletter) milik akun dan riwayat posting ke channel
+	_, _ = tx.Exec(`CREATE TABLE IF NOT EXISTS channels (
+		id TEXT PRIMARY KEY,
+		account_id TEXT NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+		name TEXT NOT NULL,
+		role TEXT NOT NULL,
+		subscribers INTEGER NOT NULL DEFAULT 0,
+		invite_code TEXT,
+		enabled INTEGER NOT NULL DEFAULT 0,
+		last_posted_at TIMESTAMP,
+		synced_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+	)`)
+	_, _ = tx.Exec(`CREATE TABLE IF NOT EXISTS channel_posts (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		channel_id TEXT NOT NULL REFERENCES channels(id) ON DELETE CASCADE,
+		account_id TEXT NOT NULL,
+		template_id TEXT,
+		template_version INTEGER,
+		status TEXT NOT NULL,
+		error TEXT,
+		message_id TEXT,
+		preview TEXT,
+		ts TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+	)`)
+	_, _ = tx.Exec(`CREATE INDEX IF NOT EXISTS idx_channel_posts_channel_ts ON channel_posts(channel_id, ts)`)
+	// Status (story) broadcast: limit harian per akun (0 = nonaktif) dan riwayat posting.
+	_, _ = tx.Exec(`ALTER TABLE accounts ADD COLUMN status_daily_limit INTEGER NOT NULL DEFAULT 0;`)
+	_, _ = tx.Exec(`CREATE TABLE IF NOT EXISTS status_posts (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		account_id TEXT NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+		template_id TEXT,
+		template_version INTEGER,
+		kind TEXT NOT NULL,
+		status TEXT NOT NULL,
+		error TEXT,
+		message_id TEXT,
+		preview TEXT,
+		ts TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+	)`)
+	_, _ = tx.Exec(`CREATE INDEX IF NOT EXISTS idx_status_posts_account_ts ON status_posts(account_id, ts)`)
+	// Komunitas WhatsApp: peran grup (induk/pengumuman/sub-grup), induknya, dan flag pengecualian broadcast.
+	_, _ = tx.Exec(`ALTER TABLE groups ADD COLUMN community_role TEXT NOT NULL DEFAULT '';`)
+	_, _ = tx.Exec(`ALTER TABLE groups ADD COLUMN parent_jid TEXT;`)
+	_, _ = tx.Exec(`ALTER TABLE groups ADD COLUMN community_excluded INTEGER NOT NULL DEFAULT 0;`)
+	// Segmen audiens: aturan (JSON model.SegmentRule) yang dimaterialisasi ke audience_members saat refresh
+	_, _ = tx.Exec(`ALTER TABLE audiences ADD COLUMN segment_json TEXT;`)
+	_, _ = tx.Exec(`ALTER TABLE audiences ADD COLUMN refreshed_at TIMESTAMP;`)
+	// Link undangan grup milik sendiri (akun admin) dan hasil cek kesehatan berkala
+	_, _ = tx.Exec(`CREATE TABLE IF NOT EXISTS group_links (
+		group_id TEXT PRIMARY KEY REFERENCES groups(id) ON DELETE CASCADE,
+		account_id TEXT NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+		invite_link TEXT NOT NULL,
+		status TEXT NOT NULL DEFAULT '',
+		member_count INTEGER NOT NULL DEFAULT 0,
+		error TEXT,
+		checked_at TIMESTAMP,
+		updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+	)`)
+	_, _ = tx.Exec(`CREATE INDEX IF NOT EXISTS idx_group_links_account ON group_links(account_id, status)`)
+	// Antrean persetujuan auto-join: link terdeteksi menunggu approve/reject operator
+	_, _ = tx.Exec(`ALTER TABLE auto_join_settings ADD COLUMN require_approval INTEGER NOT NULL DEFAULT 0;`)
+	_, _ = tx.Exec(`CREATE TABLE IF NOT EXISTS auto_join_queue (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		account_id TEXT NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+		invite_code TEXT NOT NULL,
+		group_name TEXT,
+		shared_by TEXT,
+		shared_in TEXT,
+		status TEXT NOT NULL DEFAULT 'pending',
+		reason TEXT,
+		created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		decided_at TIMESTAMP,
+		done_at TIMESTAMP,
+		UNIQUE(account_id, invite_code)
+	)`)
+	_, _ = tx.Exec(`CREATE INDEX IF NOT EXISTS idx_auto_join_queue_status ON auto_join_queue(status, decided_at)`)
+	// Filter jumlah anggota auto-join (0 = tanpa batas)
+	_, _ = tx.Exec(`ALTER TABLE auto_join_settings ADD COLUMN min_participants INTEGER NOT NULL DEFAULT 0;`)
+	_, _ = tx.Exec(`ALTER TABLE auto_join_settings ADD COLUMN max_participants INTEGER NOT NULL DEFAULT 0;`)
+	// Cross-promo: campaign mempromosikan grup sendiri; pasangan (grup dipromosikan, grup tujuan)
+	// yang sudah terkirim dicatat agar tidak diulang
+	_, _ = tx.Exec(`ALTER TABLE campaigns ADD COLUMN promote_group_id TEXT REFERENCES groups(id) ON DELETE SET NULL;`)
+	_, _ = tx.Exec(`CREATE TABLE IF NOT EXISTS cross_promos (
+		promoted_group_id TEXT NOT NULL REFERENCES groups(id) ON DELETE CASCADE,
+		target_group_id TEXT NOT NULL REFERENCES groups(id) ON DELETE CASCADE,
+		campaign_id TEXT REFERENCES campaigns(id) ON DELETE SET NULL,
+		account_id TEXT NOT NULL,
+		sent_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY(promoted_group_id, target_group_id)
+	)`)
+	// Bahasa grup hasil deteksi otomatis (language manual tetap diutamakan)
+	_, _ = tx.Exec(`ALTER TABLE groups ADD COLUMN language_detected TEXT;`)
+	_, _ = tx.Exec(`ALTER TABLE templates ADD COLUMN languages TEXT;`)
+	// Anotasi operator (catatan shift/insiden) yang disisipkan di timeline log
+	_, _ = tx.Exec(`CREATE TABLE IF NOT EXISTS annotations (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		at TIMESTAMP NOT NULL,
+		severity TEXT NOT NULL DEFAULT 'info',
+		text TEXT NOT NULL,
+		account_id TEXT REFERENCES accounts(id) ON DELETE CASCADE,
+		group_id TEXT REFERENCES groups(id) ON DELETE CASCADE,
+		author TEXT,
+		created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+	)`)
+	_, _ = tx.Exec(`CREATE INDEX IF NOT EXISTS idx_annotations_at ON annotations(at)`)
+	// Impor massal link auto-join: item antrean dijadwalkan bertahap per akun
+	_, _ = tx.Exec(`ALTER TABLE auto_join_queue ADD COLUMN not_before TIMESTAMP;`)
+	// Retry join auto-join yang gagal sementara (backoff lewat not_before)
+	_, _ = tx.Exec(`ALTER TABLE auto_join_queue ADD COLUMN attempts INTEGER NOT NULL DEFAULT 0;`)
+	// Throttle WhatsApp (rate-limit/spam): akun dihentikan sampai throttled_until
+	_, _ = tx.Exec(`ALTER TABLE accounts ADD COLUMN throttled_until TIMESTAMP;`)
+	_, _ = tx.Exec(`ALTER TABLE accounts ADD COLUMN throttle_reason TEXT;`)
+	// Metrik streak gagal per akun (/metrics): log terakhir per akun & status
+	_, _ = tx.Exec(`CREATE INDEX IF NOT EXISTS idx_logs_account_status ON logs(account_id, status, id);`)
+	// Aktifkan otomatis grup hasil auto-join (opsional setelah jeda settle lewat groups.enable_at)
+	_, _ = tx.Exec(`ALTER TABLE auto_join_settings ADD COLUMN auto_enable_after_join INTEGER NOT NULL DEFAULT 0;`)
+	_, _ = tx.Exec(`ALTER TABLE auto_join_settings ADD COLUMN auto_enable_settle_min INTEGER NOT NULL DEFAULT 0;`)
+	_, _ = tx.Exec(`ALTER TABLE groups ADD COLUMN enable_at TIMESTAMP;`)
+	// Intro pasca-join: template perkenalan dikirim N menit setelah auto-join
+	_, _ = tx.Exec(`ALTER TABLE auto_join_settings ADD COLUMN intro_template_id TEXT;`)
+	_, _ = tx.Exec(`ALTER TABLE auto_join_settings ADD COLUMN intro_delay_min INTEGER NOT NULL DEFAULT 30;`)
+	_, _ = tx.Exec(`ALTER TABLE auto_join_settings ADD COLUMN intro_jitter_min INTEGER NOT NULL DEFAULT 15;`)
+	_, _ = tx.Exec(`CREATE TABLE IF NOT EXISTS auto_join_intros (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		account_id TEXT NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+		group_id TEXT NOT NULL,
+		template_id TEXT NOT NULL,
+		due_at TIMESTAMP NOT NULL,
+		status TEXT NOT NULL DEFAULT 'pending',
+		error TEXT,
+		sent_at TIMESTAMP,
+		created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		UNIQUE(account_id, group_id)
+	)`)
+	_, _ = tx.Exec(`CREATE INDEX IF NOT EXISTS idx_auto_join_intros_due ON auto_join_intros(status, due_at)`)
+	// Masa uji grup baru: verdict otomatis setelah N kiriman pertama
+	_, _ = tx.Exec(`CREATE TABLE IF NOT EXISTS group_trials (
+		group_id TEXT PRIMARY KEY REFERENCES groups(id) ON DELETE CASCADE,
+		account_id TEXT NOT NULL,
+		started_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		replies INTEGER NOT NULL DEFAULT 0,
+		kicked INTEGER NOT NULL DEFAULT 0,
+		verdict TEXT,
+		reason TEXT,
+		decided_at TIMESTAMP
+	)`)
+	// Jendela jam & jitter acak antar auto-join per akun
+	_, _ = tx.Exec(`ALTER TABLE auto_join_settings ADD COLUMN join_windows TEXT;`)
+	_, _ = tx.Exec(`ALTER TABLE auto_join_settings ADD COLUMN join_jitter_min_sec INTEGER NOT NULL DEFAULT 3;`)
+	_, _ = tx.Exec(`ALTER TABLE auto_join_settings ADD COLUMN join_jitter_max_sec INTEGER NOT NULL DEFAULT 3;`)
+	// Auto-leave grup bernilai rendah: riwayat keputusan (kebijakan di settings "auto_leave")
+	_, _ = tx.Exec(`CREATE TABLE IF NOT EXISTS auto_leave_logs (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		account_id TEXT NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+		group_id TEXT NOT NULL,
+		group_name TEXT,
+		status TEXT NOT NULL,
+		reason TEXT NOT NULL,
+		error TEXT,
+		created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+	)`)
+	_, _ = tx.Exec(`CREATE INDEX IF NOT EXISTS idx_logs_group_account_status ON logs(group_id, account_id, status, id);`)
+	// Link pairing swalayan (QR satu akun, berbatas waktu)
+	_, _ = tx.Exec(`CREATE TABLE IF NOT EXISTS pairing_links (
+		id TEXT PRIMARY KEY,
+		account_id TEXT NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+		token_hash TEXT NOT NULL UNIQUE,
+		created_by TEXT,
+		created_at TIMESTAMP NOT NULL,
+		expires_at TIMESTAMP NOT NULL,
+		paired_at TIMESTAMP,
+		revoked_at TIMESTAMP
+	)`)
+	// Jendela kirim khusus per akun (menggantikan setting send_windows untuk akun itu)
+	_, _ = tx.Exec(`CREATE TABLE IF NOT EXISTS account_send_windows (
+		account_id TEXT PRIMARY KEY REFERENCES accounts(id) ON DELETE CASCADE,
+		windows TEXT NOT NULL,
+		updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	)`)
+	// Outbox: konten kustom untuk kiriman terjadwal sekali jalan (POST /api/send/schedule)
+	_, _ = tx.Exec(`ALTER TABLE outbox ADD COLUMN content TEXT`)
+	// Jadwal berulang ala cron (hari + jam WIB), dievaluasi scheduler ke outbox
+	_, _ = tx.Exec(`CREATE TABLE IF NOT EXISTS recurring_schedules (
+		id TEXT PRIMARY KEY,
+		name TEXT NOT NULL DEFAULT '',
+		account_id TEXT REFERENCES accounts(id) ON DELETE CASCADE,
+		days_mask TEXT,
+		times TEXT NOT NULL,
+		template_id TEXT,
+		campaign_id TEXT,
+		targeting TEXT,
+		priority INTEGER NOT NULL DEFAULT 0,
+		enabled INTEGER NOT NULL DEFAULT 1,
+		last_run_at TIMESTAMP,
+		created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+	)`)
+	// Warmup akun: umur dihitung dari first_paired_at (akun lama yang sudah pernah terhubung
+	// memakai created_at) dan profil khusus per akun menggantikan profil default
+	_, _ = tx.Exec(`ALTER TABLE accounts ADD COLUMN first_paired_at TIMESTAMP`)
+	_, _ = tx.Exec(`UPDATE accounts SET first_paired_at=created_at
+		WHERE first_paired_at IS NULL AND status NOT IN ('inactive','pairing')`)
+	_, _ = tx.Exec(`CREATE TABLE IF NOT EXISTS account_warmup (
+		account_id TEXT PRIMARY KEY REFERENCES accounts(id) ON DELETE CASCADE,
+		profile TEXT NOT NULL,
+		updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	)`)
+	// Batas kiriman per grup (harian/mingguan, dari semua akun); NULL = batas default setting
+	_, _ = tx.Exec(`ALTER TABLE groups ADD COLUMN max_per_day INTEGER`)
+	_, _ = tx.Exec(`ALTER TABLE groups ADD COLUMN max_per_week INTEGER`)
+	_, _ = tx.Exec(`CREATE INDEX IF NOT EXISTS idx_logs_group_status_ts ON logs(group_id, status, ts);`)
+	// Asal item outbox; item lama bertemplate/konten kustom adalah kiriman terjadwal eksplisit
+	_, _ = tx.Exec(`ALTER TABLE outbox ADD COLUMN origin TEXT NOT NULL DEFAULT 'scheduler'`)
+	_, _ = tx.Exec(`UPDATE outbox SET origin='scheduled' WHERE origin='scheduler' AND (content IS NOT NULL OR template_id IS NOT NULL)`)
+	// Hari libur: broadcast otomatis dilewati pada tanggal ini (WIB)
+	_, _ = tx.Exec(`CREATE TABLE IF NOT EXISTS holidays (
+		date TEXT PRIMARY KEY,
+		name TEXT NOT NULL DEFAULT '',
+		created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+	)`)
+	// Campaign asal template (mis. template yang dibuat feed milik campaign)
+	_, _ = tx.Exec(`ALTER TABLE templates ADD COLUMN campaign_id TEXT REFERENCES campaigns(id) ON DELETE SET NULL`)
+
 	// Remove old text column after migration (optional, commented for safety)
 	// _, _ = tx.Exec(`ALTER TABLE templates DROP COLUMN text;`)
 	return tx.Commit()
@@ -213,6 +864,9 @@ func (s *Store) CreateAccount(label, msisdn string, enabled bool, dailyLimit int
 	_, err := s.DB.Exec(`INSERT INTO accounts (id,label,msisdn,enabled,daily_limit,status,last_error,created_at,updated_at)
 		VALUES (?,?,?,?,?,'inactive','',?,?)`,
 		id, label, msisdn, btoi(enabled), dailyLimit, now, now)
+	if isUniqueViolation(err) {
+		return "", ErrAccountLabelTaken
+	}
 	if err != nil {
 		return "", err
 	}
@@ -221,22 +875,7 @@ func (s *Store) CreateAccount(label, msisdn string, enabled bool, dailyLimit int
 
 // ListAccounts returns all accounts ordered by created_at desc.
 func (s *Store) ListAccounts() ([]model.Account, error) {
-	rows, err := s.DB.Query(`SELECT id,label,msisdn,enabled,daily_limit,status,COALESCE(last_error,''),created_at,updated_at FROM accounts ORDER BY created_at DESC`)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-	var list []model.Account
-	for rows.Next() {
-		var a model.Account
-		var enabledInt int
-		if err := rows.Scan(&a.ID, &a.Label, &a.Msisdn, &enabledInt, &a.DailyLimit, &a.Status, &a.LastError, &a.CreatedAt, &a.UpdatedAt); err != nil {
-			return nil, err
-		}
-		a.Enabled = enabledInt == 1
-		list = append(list, a)
-	}
-	return list, nil
+	return s.queryAccounts(`1=1`)
 }
 
 func (s *Store) AccountExists(id string) (bool, error) {
@@ -265,18 +904,25 @@ func (s *Store) UpsertGroup(accountID, groupID, name string) error {
 		VALUES (?,?,?,?, CURRENT_TIMESTAMP)
 		ON CONFLICT(id) DO UPDATE SET
 			account_id=excluded.account_id,
-			name=COALESCE(NULLIF(excluded.name,''), groups.name)
+			name=COALESCE(NULLIF(excluded.name,''), groups.name),
+			left_at=NULL
 	`, groupID, accountID, name, 0)
 	return err
 }
 
+// groupCols kolom yang dibaca ListGroups (tag digabung koma, urut nama).
+const groupCols = `id,account_id,name,enabled,last_sent_at,risk_score,created_at,COALESCE(compliance_flag,''),
+	participant_count,COALESCE(language,''),COALESCE(language_detected,''),
+	COALESCE((SELECT group_concat(tag, ',') FROM (SELECT tag FROM group_tags WHERE group_id=groups.id ORDER BY tag)),''),
+	is_test, community_role, COALESCE(parent_jid,''), community_excluded`
+
 func (s *Store) ListGroups(accountID string) ([]model.Group, error) {
 	var rows *sql.Rows
 	var err error
 	if accountID != "" {
-		rows, err = s.DB.Query(`SELECT id,account_id,name,enabled,last_sent_at,risk_score,created_at FROM groups WHERE account_id=? ORDER BY name`, accountID)
+		rows, err = s.DB.Query(`SELECT `+groupCols+` FROM groups WHERE account_id=? AND left_at IS NULL ORDER BY name`, accountID)
 	} else {
-		rows, err = s.DB.Query(`SELECT id,account_id,name,enabled,last_sent_at,risk_score,created_at FROM groups ORDER BY name`)
+		rows, err = s.DB.Query(`SELECT ` + groupCols + ` FROM groups WHERE left_at IS NULL ORDER BY name`)
 	}
 	if err != nil {
 		return nil, err
@@ -287,10 +933,18 @@ func (s *Store) ListGroups(accountID string) ([]model.Group, error) {
 		var g model.Group
 		var enabled int
 		var lastSent sql.NullTime
-		if err := rows.Scan(&g.ID, &g.AccountID, &g.Name, &enabled, &lastSent, &g.RiskScore, &g.CreatedAt); err != nil {
+		var tags string
+		var isTest, excluded int
+		if err := rows.Scan(&g.ID, &g.AccountID, &g.Name, &enabled, &lastSent, &g.RiskScore, &g.CreatedAt, &g.ComplianceFlag,
+			&g.ParticipantCount, &g.Language, &g.DetectedLanguage, &tags, &isTest, &g.CommunityRole, &g.ParentJID, &excluded); err != nil {
 			return nil, err
 		}
 		g.Enabled = enabled == 1
+		g.TestGroup = isTest == 1
+		g.CommunityExcluded = excluded == 1
+		if tags != "" {
+			g.Tags = strings.Split(tags, ",")
+		}
 		if lastSent.Valid {
 			t := lastSent.Time
 			g.LastSentAt = &t
@@ -301,21 +955,53 @@ func (s *Store) ListGroups(accountID string) ([]model.Group, error) {
 }
 
 func (s *Store) ToggleGroup(groupID string, enabled bool) (int64, error) {
-	res, err := s.DB.Exec(`UPDATE groups SET enabled=? WHERE id=?`, btoi(enabled), groupID)
+	// toggle manual membatalkan aktivasi otomatis yang masih tertunda
+	res, err := s.DB.Exec(`UPDATE groups SET enabled=?, enable_at=NULL WHERE id=?`, btoi(enabled), groupID)
 	if err != nil {
 		return 0, err
 	}
 	return res.RowsAffected()
 }
 
+// MarkGroupLeft mencatat bahwa accountID keluar dari grup. Jika masih ada akun lain yang menjadi
+// anggota, grup dialihkan ke akun itu; jika tidak, grup dinonaktifkan dan ditandai left_at
+// (tidak tampil lagi sampai join ulang). false jika grup tidak ada.
+func (s *Store) MarkGroupLeft(accountID, groupID string) (bool, error) {
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+	if _, err := tx.Exec(`DELETE FROM group_members WHERE group_id=? AND account_id=?`, groupID, accountID); err != nil {
+		return false, err
+	}
+	var other string
+	err = tx.QueryRow(`SELECT account_id FROM group_members WHERE group_id=? ORDER BY updated_at DESC LIMIT 1`, groupID).Scan(&other)
+	var res sql.Result
+	switch {
+	case err == sql.ErrNoRows:
+		res, err = tx.Exec(`UPDATE groups SET enabled=0, left_at=CURRENT_TIMESTAMP WHERE id=?`, groupID)
+	case err == nil:
+		res, err = tx.Exec(`UPDATE groups SET account_id=CASE WHEN account_id=? THEN ? ELSE account_id END WHERE id=?`, accountID, other, groupID)
+	}
+	if err != nil {
+		return false, err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return false, nil
+	}
+	return true, tx.Commit()
+}
+
 func (s *Store) StatsToday() (total, success, failed int64, err error) {
+	from, to := s.TodayArgs()
 	row := s.DB.QueryRow(`
 		SELECT
 			COUNT(*) AS total,
 			COALESCE(SUM(CASE WHEN status='sent' THEN 1 ELSE 0 END), 0) AS success,
 			COALESCE(SUM(CASE WHEN status='failed' THEN 1 ELSE 0 END), 0) AS failed
 		FROM logs
-		WHERE ts >= datetime('now','start of day') AND ts < datetime('now','start of day','+1 day')`)
+		WHERE ts >= ? AND ts < ?`, from, to)
 	if err := row.Scan(&total, &success, &failed); err != nil {
 		return 0, 0, 0, err
 	}
@@ -338,6 +1024,9 @@ func (s *Store) UpdateAccount(id, label, msisdn string, enabled bool, dailyLimit
 		SET label=?, msisdn=?, enabled=?, daily_limit=?, updated_at=CURRENT_TIMESTAMP 
 		WHERE id=?`,
 		label, msisdn, btoi(enabled), dailyLimit, id)
+	if isUniqueViolation(err) {
+		return ErrAccountLabelTaken
+	}
 	return err
 }
 
@@ -348,12 +1037,7 @@ func (s *Store) DeleteAccount(id string) error {
 }
 
 // CacheGroupParticipants menyimpan/update daftar participants grup ke cache database
-func (s *Store) CacheGroupParticipants(groupID string, participants []struct {
-	JID          string
-	Number       string
-	IsAdmin      bool
-	IsSuperAdmin bool
-}) error {
+func (s *Store) CacheGroupParticipants(groupID string, participants []model.Participant) error {
 	tx, err := s.DB.Begin()
 	if err != nil {
 		return err
@@ -378,17 +1062,16 @@ func (s *Store) CacheGroupParticipants(groupID string, participants []struct {
 			return err
 		}
 	}
+	// Jumlah anggota dipakai targeting campaign (min_participants)
+	if _, err := tx.Exec(`UPDATE groups SET participant_count=?, participants_synced_at=CURRENT_TIMESTAMP WHERE id=?`, len(participants), groupID); err != nil {
+		return err
+	}
 
 	return tx.Commit()
 }
 
 // GetCachedGroupParticipants mengambil participants dari cache jika ada dan masih valid
-func (s *Store) GetCachedGroupParticipants(groupID string, maxAgeMinutes int) ([]struct {
-	JID          string
-	Number       string
-	IsAdmin      bool
-	IsSuperAdmin bool
-}, bool, error) {
+func (s *Store) GetCachedGroupParticipants(groupID string, maxAgeMinutes int) ([]model.Participant, bool, error) {
 	// Cek apakah ada cache yang valid
 	var count int
 	err := s.DB.QueryRow(`SELECT COUNT(*) FROM group_participants 
@@ -406,20 +1089,10 @@ func (s *Store) GetCachedGroupParticipants(groupID string, maxAgeMinutes int) ([
 	}
 	defer rows.Close()
 
-	var participants []struct {
-		JID          string
-		Number       string
-		IsAdmin      bool
-		IsSuperAdmin bool
-	}
+	var participants []model.Participant
 
 	for rows.Next() {
-		var p struct {
-			JID          string
-			Number       string
-			IsAdmin      bool
-			IsSuperAdmin bool
-		}
+		var p model.Participant
 		var isAdmin, isSuperAdmin int
 		if err := rows.Scan(&p.JID, &p.Number, &isAdmin, &isSuperAdmin); err != nil {
 			return nil, false, err
@@ -437,3 +1110,93 @@ func (s *Store) InvalidateGroupParticipantsCache(groupID string) error {
 	_, err := s.DB.Exec(`DELETE FROM group_participants WHERE group_id=?`, groupID)
 	return err
 }
+
+// ListAccountStatuses mengembalikan ringkasan status semua akun (kuota hari ini,
+// kirim terakhir, jumlah grup) dalam satu query agar dashboard tidak perlu loop per akun.
+func (s *Store) ListAccountStatuses() ([]model.AccountStatus, error) {
+	from, _ := s.TodayArgs()
+	rows, err := s.DB.Query(`
+		SELECT a.id, a.label, COALESCE(a.msisdn,''), a.enabled, a.daily_limit, a.status, COALESCE(a.last_error,''),
+			COALESCE(l.sent_today, 0), COALESCE(l.failed_today, 0), l.last_sent,
+			COALESCE(g.total, 0), COALESCE(g.enabled, 0)
+		FROM accounts a
+		LEFT JOIN (
+			SELECT account_id,
+				SUM(CASE WHEN status='sent' AND ts >= ? THEN 1 ELSE 0 END) AS sent_today,
+				SUM(CASE WHEN status='failed' AND ts >= ? THEN 1 ELSE 0 END) AS failed_today,
+				MAX(CASE WHEN status='sent' THEN ts END) AS last_sent
+			FROM logs GROUP BY account_id
+		) l ON l.account_id = a.id
+		LEFT JOIN (
+			SELECT account_id, COUNT(*) AS total, SUM(enabled) AS enabled
+			FROM groups GROUP BY account_id
+		) g ON g.account_id = a.id
+		ORDER BY a.created_at DESC`, from, from)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var list []model.AccountStatus
+	for rows.Next() {
+		var st model.AccountStatus
+		var enabledInt int
+		var lastSent sql.NullString
+		if err := rows.Scan(&st.ID, &st.Label, &st.Msisdn, &enabledInt, &st.DailyLimit, &st.Status, &st.LastError,
+			&st.SentToday, &st.FailedToday, &lastSent, &st.GroupsTotal, &st.GroupsEnabled); err != nil {
+			return nil, err
+		}
+		st.Enabled = enabledInt == 1
+		if st.DailyLimit <= 0 {
+			st.DailyLimit = 100
+		}
+		if left := int64(st.DailyLimit) - st.SentToday; left > 0 {
+			st.QuotaLeft = left
+		}
+		if t, ok := parseDBTime(lastSent); ok {
+			st.LastSentAt = &t
+		}
+		list = append(list, st)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	// Soft bounce hari ini dan rasio 7 hari (soft_bounce / pesan yang sudah ada hasilnya)
+	now := time.Now().UTC()
+	today, err := s.AckCounts(s.Day.Start(now))
+	if err != nil {
+		return nil, err
+	}
+	week, err := s.AckCounts(now.Add(-7 * 24 * time.Hour))
+	if err != nil {
+		return nil, err
+	}
+	for i := range list {
+		list[i].SoftBouncedToday = today[list[i].ID][AckSoftBounce]
+		w := week[list[i].ID]
+		if settled := w[AckDelivered] + w[AckSoftBounce]; settled > 0 {
+			list[i].SoftBounceRate7d = float64(w[AckSoftBounce]) / float64(settled)
+		}
+	}
+	return list, nil
+}
+
+// parseDBTime mengurai nilai timestamp hasil agregasi SQLite (MAX/MIN kehilangan tipe kolom
+// sehingga driver mengembalikan string).
+func parseDBTime(v sql.NullString) (time.Time, bool) {
+	if !v.Valid || v.String == "" {
+		return time.Time{}, false
+	}
+	layouts := []string{
+		"2006-01-02 15:04:05.999999999-07:00",
+		"2006-01-02T15:04:05.999999999-07:00",
+		"2006-01-02 15:04:05.999999999",
+		"2006-01-02T15:04:05Z07:00",
+		"2006-01-02 15:04:05",
+	}
+	for _, l := range layouts {
+		if t, err := time.ParseInLocation(l, v.String, time.UTC); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
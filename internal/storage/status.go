@@ -0,0 +1,111 @@
+package storage
+
+import (
+	"strconv"
+	"time"
+
+	"promote/internal/model"
+)
+
+// SettingStatusMinInterval jeda minimum (menit) antar posting status terjadwal satu akun.
+const SettingStatusMinInterval = "status_min_interval_min"
+
+// DefaultStatusMinInterval: status berjarak minimal 3 jam.
+const DefaultStatusMinInterval = 180
+
+// InsertStatusPost mencatat satu posting status.
+func (s *Store) InsertStatusPost(p model.StatusPost) error {
+	var tplVersion any
+	if p.TemplateVersion > 0 {
+		tplVersion = p.TemplateVersion
+	}
+	_, err := s.DB.Exec(`INSERT INTO status_posts (account_id, template_id, template_version, kind, status, error, message_id, preview)
+		VALUES (?,?,?,?,?,?,?,?)`,
+		p.AccountID, nullStr(p.TemplateID), tplVersion, p.Kind, p.Status, nullStr(p.Error), nullStr(p.MessageID), nullStr(p.Preview))
+	return err
+}
+
+// ListStatusPosts riwayat posting status (opsional per akun), terbaru dulu.
+func (s *Store) ListStatusPosts(accountID string, limit int) ([]model.StatusPost, error) {
+	rows, err := s.DB.Query(`SELECT id, account_id, COALESCE(template_id,''), COALESCE(template_version,0), kind,
+		status, COALESCE(error,''), COALESCE(message_id,''), COALESCE(preview,''), ts
+		FROM status_posts WHERE (?='' OR account_id=?) ORDER BY id DESC LIMIT ?`, accountID, accountID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var list []model.StatusPost
+	for rows.Next() {
+		var p model.StatusPost
+		if err := rows.Scan(&p.ID, &p.AccountID, &p.TemplateID, &p.TemplateVersion, &p.Kind,
+			&p.Status, &p.Error, &p.MessageID, &p.Preview, &p.TS); err != nil {
+			return nil, err
+		}
+		list = append(list, p)
+	}
+	return list, rows.Err()
+}
+
+// CountStatusPostsToday jumlah posting status sukses akun pada hari kuota ini.
+func (s *Store) CountStatusPostsToday(accountID string) (int, error) {
+	var n int
+	from, to := s.TodayArgs()
+	err := s.DB.QueryRow(`SELECT COUNT(*) FROM status_posts WHERE account_id=? AND status='sent' AND ts >= ? AND ts < ?`,
+		accountID, from, to).Scan(&n)
+	return n, err
+}
+
+// DueStatusAccounts akun aktif (bukan observer, tidak dijeda error budget) dengan
+// status_daily_limit > 0, yang kuota status hari ini belum habis dan posting status suksesnya
+// yang terakhir lebih lama dari minInterval. Nilai map = limit harian akun.
+func (s *Store) DueStatusAccounts(minInterval time.Duration) (map[string]int, error) {
+	from, to := s.TodayArgs()
+	rows, err := s.DB.Query(`SELECT a.id, a.status_daily_limit FROM accounts a
+		WHERE a.enabled=1 AND a.observer=0 AND a.budget_paused_at IS NULL AND a.status_daily_limit > 0
+		  AND (SELECT COUNT(*) FROM status_posts p WHERE p.account_id=a.id AND p.status='sent' AND p.ts >= ? AND p.ts < ?) < a.status_daily_limit
+		  AND NOT EXISTS (SELECT 1 FROM status_posts p WHERE p.account_id=a.id AND p.status='sent' AND p.ts >= ?)`,
+		from, to, time.Now().Add(-minInterval).UTC().Format(ctsLayout))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := map[string]int{}
+	for rows.Next() {
+		var id string
+		var limit int
+		if err := rows.Scan(&id, &limit); err != nil {
+			return nil, err
+		}
+		out[id] = limit
+	}
+	return out, rows.Err()
+}
+
+// SetStatusDailyLimit mengubah limit status harian akun; ErrAccountNotFound jika akun tidak ada.
+func (s *Store) SetStatusDailyLimit(accountID string, limit int) error {
+	res, err := s.DB.Exec(`UPDATE accounts SET status_daily_limit=?, updated_at=CURRENT_TIMESTAMP WHERE id=?`, limit, accountID)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrAccountNotFound
+	}
+	return nil
+}
+
+// StatusMinInterval membaca jeda minimum antar status (default DefaultStatusMinInterval menit).
+func (s *Store) StatusMinInterval() (int, error) {
+	v, err := s.GetSetting(SettingStatusMinInterval)
+	if err != nil {
+		return DefaultStatusMinInterval, err
+	}
+	if n, err := strconv.Atoi(v); err == nil && n > 0 {
+		return n, nil
+	}
+	return DefaultStatusMinInterval, nil
+}
+
+// SetStatusMinInterval menyimpan jeda minimum antar status (menit).
+func (s *Store) SetStatusMinInterval(min int) error {
+	return s.SetSetting(SettingStatusMinInterval, strconv.Itoa(min))
+}
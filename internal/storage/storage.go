@@ -0,0 +1,85 @@
+package storage
+
+import (
+	"context"
+	"time"
+
+	"promote/internal/model"
+	"promote/internal/rng"
+)
+
+// TemplateStore operasi template global.
+type TemplateStore interface {
+	ListTemplates() ([]model.Template, error)
+	RandomTemplate(ctx context.Context, lang string, r *rng.Rand) (model.Template, error)
+	GetTemplate(id string) (model.Template, error)
+	CountActiveTemplates() (int, error)
+	CreateTemplate(t model.Template) (string, error)
+	UpdateTemplate(t model.Template) error
+	SetTemplateEnabled(id string, enabled bool) error
+	DeleteTemplate(id string) error
+}
+
+// LogStore operasi log kirim.
+type LogStore interface {
+	InsertLog(e model.LogEntry) error
+	RecentLogs(limit int) ([]model.LogEntry, error)
+	LogsAfter(afterID int64, limit int) ([]model.LogEntry, error)
+	CountAccountSentToday(accountID string) (int, error)
+	CountSentToday(accountID, campaignID string) (int, error)
+}
+
+// CampaignStore operasi campaign.
+type CampaignStore interface {
+	ListCampaigns() ([]model.Campaign, error)
+	GetCampaign(id string) (model.Campaign, error)
+	CreateCampaign(c model.Campaign) (string, error)
+	UpdateCampaign(c model.Campaign) error
+	DeleteCampaign(id string) error
+}
+
+// ParticipantStore cache anggota grup.
+type ParticipantStore interface {
+	CacheGroupParticipants(groupID string, participants []model.Participant) error
+	GetCachedGroupParticipants(groupID string, maxAgeMinutes int) ([]model.Participant, bool, error)
+	InvalidateGroupParticipantsCache(groupID string) error
+	GroupParticipantNumbers(groupIDs []string) ([]string, error)
+}
+
+// Storage adalah kontrak penyimpanan bertipe untuk template, log kirim, campaign dan
+// participant, plus lookup kecil yang dipakai sender. *Store (SQLite) adalah implementasi
+// bawaannya; paket yang hanya bergantung pada Storage tidak menjalankan SQL mentah sehingga
+// backend lain atau fake untuk unit test bisa dipasang.
+type Storage interface {
+	TemplateStore
+	LogStore
+	CampaignStore
+	ParticipantStore
+
+	AccountLabel(accountID string) (string, error)
+	GroupName(groupID string) (string, error)
+	GroupFields(groupID string) (map[string]string, error)
+	GroupLanguage(groupID string) (string, error)
+	BumpGroupRisk(groupID string, threshold int) error
+	TestGroupForAccount(accountID string) (string, error)
+	SettingBool(key string) (bool, error)
+	IsBusinessAccount(accountID string) (bool, error)
+	IsObserverAccount(accountID string) (bool, error)
+	ThrottleAccount(accountID string, until time.Time, reason string) error
+	AccountThrottledUntil(accountID string) (*time.Time, error)
+	RecordDMSend(accountID, number, campaignID, status, errMsg string) error
+	GetChannel(id string) (model.Channel, error)
+	InsertChannelPost(p model.ChannelPost) error
+	InsertStatusPost(p model.StatusPost) error
+	FetchPolicy() (model.FetchPolicy, error)
+	IsSuppressed(target string) (bool, error)
+	DMSuppressedSet() (map[string]bool, error)
+	CrossPromoSource(groupID string) (model.GroupLink, error)
+	RecordCrossPromo(promotedGroupID, targetGroupID, campaignID, accountID string) error
+
+	InsertMessageAck(messageID, accountID, groupID, sessionID string, sentAt time.Time, rtt time.Duration, plannedAt time.Time) error
+	MarkMessagesDelivered(accountID string, messageIDs []string, at time.Time) (int64, error)
+	MarkSoftBounces(cutoff time.Time) (int64, error)
+}
+
+var _ Storage = (*Store)(nil)
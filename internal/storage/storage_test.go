@@ -0,0 +1,1193 @@
+package storage_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"promote/internal/model"
+	"promote/internal/rng"
+	"promote/internal/storage"
+	"promote/internal/storage/storagetest"
+)
+
+func TestMigrateIsIdempotent(t *testing.T) {
+	dsn := storagetest.DSN(t)
+	st := storagetest.OpenDSN(t, dsn)
+	storagetest.SeedAccount(t, st, storagetest.Account{ID: "a"})
+	id := storagetest.SeedTemplate(t, st, "promo", "hello {group_name}")
+
+	// Membuka ulang database yang sama menjalankan migrate lagi di atas skema terbaru.
+	st2 := storagetest.OpenDSN(t, dsn)
+	accs, err := st2.ListAccounts()
+	if err != nil {
+		t.Fatalf("list accounts after reopen: %v", err)
+	}
+	if len(accs) != 1 || accs[0].ID != "a" {
+		t.Fatalf("accounts after reopen = %+v, want [a]", accs)
+	}
+	versions, err := st2.ListTemplateVersions(id)
+	if err != nil {
+		t.Fatalf("list versions: %v", err)
+	}
+	if len(versions) != 1 {
+		t.Fatalf("versions after reopen = %d, want 1 (no duplicate backfill)", len(versions))
+	}
+}
+
+func TestMigrateBackfillsTemplateVersions(t *testing.T) {
+	dsn := storagetest.DSN(t)
+	st := storagetest.OpenDSN(t, dsn)
+	// Template dari sebelum versioning: baris ada, snapshot belum ada.
+	if _, err := st.DB.Exec(`INSERT INTO templates (id, name, text_only, enabled) VALUES ('legacy','old','legacy text',1)`); err != nil {
+		t.Fatal(err)
+	}
+	if err := st.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	st2 := storagetest.OpenDSN(t, dsn)
+	v, err := st2.GetTemplateVersion("legacy", 1)
+	if err != nil {
+		t.Fatalf("backfilled version: %v", err)
+	}
+	if v.Content.TextOnly != "legacy text" {
+		t.Fatalf("backfilled content = %q, want %q", v.Content.TextOnly, "legacy text")
+	}
+}
+
+func TestTemplateVersionsOnUpdate(t *testing.T) {
+	st := storagetest.Open(t)
+	id := storagetest.SeedTemplate(t, st, "promo", "v1")
+	for _, text := range []string{"v2", "v3"} {
+		tpl, err := st.GetTemplate(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		tpl.TextOnly = text
+		if err := st.UpdateTemplate(tpl); err != nil {
+			t.Fatalf("update: %v", err)
+		}
+	}
+	tpl, err := st.GetTemplate(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tpl.Version != 3 {
+		t.Fatalf("version = %d, want 3", tpl.Version)
+	}
+	list, err := st.ListTemplateVersions(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 3 || list[0].Version != 3 || list[2].Content.TextOnly != "v1" {
+		t.Fatalf("versions = %+v, want 3..1 with v1 content last", list)
+	}
+	if _, err := st.GetTemplateVersion(id, 9); err != storage.ErrTemplateVersionNotFound {
+		t.Fatalf("missing version err = %v, want ErrTemplateVersionNotFound", err)
+	}
+}
+
+func TestStatsTodayFollowsDayBoundary(t *testing.T) {
+	st := storagetest.Open(t)
+	clock := storagetest.NewClock(time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC))
+	storagetest.UseClock(st, clock)
+	storagetest.SeedAccount(t, st, storagetest.Account{ID: "a"})
+	storagetest.SeedGroup(t, st, storagetest.Group{ID: "g@g.us", AccountID: "a", Enabled: true})
+
+	now := clock.Now()
+	storagetest.SeedLog(t, st, "a", "g@g.us", "sent", now.Add(-time.Hour))
+	storagetest.SeedLog(t, st, "a", "g@g.us", "sent", now.Add(-11*time.Hour))
+	storagetest.SeedLog(t, st, "a", "g@g.us", "failed", now.Add(-2*time.Hour))
+	// Kemarin 23:59 UTC, di luar hari ini.
+	storagetest.SeedLog(t, st, "a", "g@g.us", "sent", now.Add(-12*time.Hour-time.Minute))
+
+	total, success, failed, err := st.StatsToday()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total != 3 || success != 2 || failed != 1 {
+		t.Fatalf("stats = %d/%d/%d, want 3/2/1", total, success, failed)
+	}
+	sent, err := st.CountAccountSentToday("a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sent != 2 {
+		t.Fatalf("sent today = %d, want 2", sent)
+	}
+
+	clock.Advance(24 * time.Hour)
+	total, _, _, err = st.StatsToday()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total != 0 {
+		t.Fatalf("stats next day total = %d, want 0", total)
+	}
+}
+
+func TestMarkGroupLeft(t *testing.T) {
+	st := storagetest.Open(t)
+	storagetest.SeedAccount(t, st, storagetest.Account{ID: "a"})
+	storagetest.SeedAccount(t, st, storagetest.Account{ID: "b"})
+	storagetest.SeedGroup(t, st, storagetest.Group{ID: "solo@g.us", AccountID: "a", Enabled: true})
+	storagetest.SeedGroup(t, st, storagetest.Group{ID: "shared@g.us", AccountID: "a", Enabled: true})
+	if err := st.AddGroupMember("b", "shared@g.us"); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, gid := range []string{"solo@g.us", "shared@g.us"} {
+		found, err := st.MarkGroupLeft("a", gid)
+		if err != nil || !found {
+			t.Fatalf("leave %s: found=%v err=%v", gid, found, err)
+		}
+	}
+	if found, err := st.MarkGroupLeft("a", "missing@g.us"); err != nil || found {
+		t.Fatalf("leave missing: found=%v err=%v", found, err)
+	}
+
+	groups, err := st.ListGroups("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(groups) != 1 || groups[0].ID != "shared@g.us" || groups[0].AccountID != "b" || !groups[0].Enabled {
+		t.Fatalf("groups = %+v, want only shared@g.us handed over to b", groups)
+	}
+
+	// Join ulang (sync grup) memunculkan grup lagi.
+	if err := st.UpsertGroup("a", "solo@g.us", ""); err != nil {
+		t.Fatal(err)
+	}
+	if groups, _ = st.ListGroups("a"); len(groups) != 1 {
+		t.Fatalf("groups of a after rejoin = %d, want 1", len(groups))
+	}
+}
+
+func TestIsSuppressed(t *testing.T) {
+	st := storagetest.Open(t)
+	if err := st.AddDMSuppression("6281234567890", "asked", model.SuppressionManual); err != nil {
+		t.Fatal(err)
+	}
+	if err := st.AddGroupSuppression("g@g.us", "no promo"); err != nil {
+		t.Fatal(err)
+	}
+	for target, want := range map[string]bool{
+		"6281234567890":                true,
+		"6281234567890@s.whatsapp.net": true,
+		"g@g.us":                       true,
+		"6289999999999":                false,
+		"other@g.us":                   false,
+	} {
+		got, err := st.IsSuppressed(target)
+		if err != nil {
+			t.Fatalf("%s: %v", target, err)
+		}
+		if got != want {
+			t.Errorf("IsSuppressed(%s) = %v, want %v", target, got, want)
+		}
+	}
+}
+
+func TestRepairForeignKeys(t *testing.T) {
+	st := storagetest.Open(t)
+	storagetest.SeedAccount(t, st, storagetest.Account{ID: "a"})
+	conn, err := st.DB.Conn(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Baris yatim hanya bisa dibuat dengan foreign key nonaktif (mis. DB_DSN tanpa _foreign_keys=on).
+	for _, q := range []string{
+		`PRAGMA foreign_keys=OFF`,
+		`INSERT INTO groups (id, account_id, name) VALUES ('lost@g.us', 'gone', 'x')`,
+		`INSERT INTO logs (account_id, group_id, status) VALUES ('a', 'ghost@g.us', 'sent')`,
+		`PRAGMA foreign_keys=ON`,
+	} {
+		if _, err := conn.ExecContext(context.Background(), q); err != nil {
+			t.Fatal(err)
+		}
+	}
+	conn.Close()
+
+	list, err := st.ForeignKeyViolations()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 2 {
+		t.Fatalf("violations = %+v, want 2", list)
+	}
+	if n, err := st.RepairForeignKeys(list); err != nil || n != 2 {
+		t.Fatalf("repaired = %d, %v", n, err)
+	}
+	var logs, groups int
+	st.DB.QueryRow(`SELECT COUNT(*) FROM logs WHERE group_id IS NULL AND account_id='a'`).Scan(&logs)
+	st.DB.QueryRow(`SELECT COUNT(*) FROM groups WHERE id='lost@g.us'`).Scan(&groups)
+	if logs != 1 || groups != 0 {
+		t.Fatalf("after repair: logs with NULL group = %d (want 1), orphan groups = %d (want 0)", logs, groups)
+	}
+	if list, _ := st.ForeignKeyViolations(); len(list) != 0 {
+		t.Fatalf("violations after repair = %+v", list)
+	}
+}
+
+func TestApplyConfigReconcilesExport(t *testing.T) {
+	ctx := context.Background()
+	src, dst := storagetest.Open(t), storagetest.Open(t)
+	for _, st := range []*storage.Store{src, dst} {
+		if _, err := st.CreateCampaign(model.Campaign{Name: "harian", Mode: "group"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	storagetest.SeedAccount(t, src, storagetest.Account{ID: "a"})
+	storagetest.SeedTemplate(t, src, "promo", "Halo grup!")
+	storagetest.SeedTemplate(t, src, "baru", "Produk baru")
+	if _, err := src.DB.Exec(`INSERT INTO tags (name) VALUES ('vip')`); err != nil {
+		t.Fatal(err)
+	}
+	campaigns, _ := src.ListCampaigns()
+	if _, err := src.CreateSchedule(model.Schedule{CampaignID: campaigns[0].ID, AccountID: "a", BatchSize: 10,
+		EndHour: 24, MinDelaySec: 30, MaxDelaySec: 60, DailyLimit: 50, Enabled: true}); err != nil {
+		t.Fatal(err)
+	}
+	if err := src.SetSetting(storage.SettingSafeMode, "1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := src.SetTelegramMirror(model.TelegramMirror{Enabled: true, BotToken: "123:bot-token", ChatID: "@promo"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Instance tujuan: akun lain dengan label yang sama, isi template berbeda, tag dan template usang.
+	storagetest.SeedAccount(t, dst, storagetest.Account{ID: "x"})
+	if _, err := dst.DB.Exec(`UPDATE accounts SET label='acc-a' WHERE id='x'`); err != nil {
+		t.Fatal(err)
+	}
+	storagetest.SeedTemplate(t, dst, "promo", "teks lama")
+	storagetest.SeedTemplate(t, dst, "usang", "hapus saya")
+	if _, err := dst.DB.Exec(`INSERT INTO tags (name) VALUES ('lama')`); err != nil {
+		t.Fatal(err)
+	}
+
+	spec, err := src.ExportConfig(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(spec.Schedules) != 1 || spec.Schedules[0].Account != "label:acc-a" || spec.Schedules[0].Campaign != "harian" {
+		t.Fatalf("exported schedules = %+v", spec.Schedules)
+	}
+	if _, ok := spec.Settings[storage.SettingTelegramMirror]; ok {
+		t.Fatal("config export must not include the Telegram bot token setting")
+	}
+
+	dry, err := dst.ApplyConfig(ctx, *spec, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rep, err := dst.ApplyConfig(ctx, *spec, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dry.DryRun = false
+	if !reflect.DeepEqual(dry, rep) {
+		t.Errorf("dry run report %+v differs from applied %+v", dry, rep)
+	}
+	want := storage.ConfigReport{
+		Templates: storage.ConfigChanges{Created: []string{"baru"}, Updated: []string{"promo"}, Removed: []string{"usang"}},
+		Tags:      storage.ConfigChanges{Created: []string{"vip"}, Updated: []string{}, Removed: []string{"lama"}},
+		Schedules: storage.ConfigChanges{Created: []string{"harian @ label:acc-a"}, Updated: []string{}, Removed: []string{}},
+		Settings:  storage.ConfigChanges{Created: []string{storage.SettingSafeMode}, Updated: []string{}, Removed: []string{}},
+	}
+	if !reflect.DeepEqual(rep, want) {
+		t.Errorf("report = %+v\nwant     %+v", rep, want)
+	}
+	if sch, _ := dst.ListSchedules("", "x"); len(sch) != 1 || sch[0].BatchSize != 10 {
+		t.Errorf("schedules on target = %+v", sch)
+	}
+
+	again, err := dst.ApplyConfig(ctx, *spec, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for kind, ch := range map[string]storage.ConfigChanges{"templates": again.Templates, "tags": again.Tags,
+		"schedules": again.Schedules, "settings": again.Settings} {
+		if len(ch.Created)+len(ch.Updated)+len(ch.Removed) != 0 {
+			t.Errorf("second apply changed %s: %+v", kind, ch)
+		}
+	}
+}
+
+func TestRefreshSegment(t *testing.T) {
+	st := storagetest.Open(t)
+	storagetest.SeedAccount(t, st, storagetest.Account{ID: "a"})
+	for _, g := range []string{"g1@g.us", "g2@g.us", "g3@g.us"} {
+		storagetest.SeedGroup(t, st, storagetest.Group{ID: g, AccountID: "a", Enabled: true})
+	}
+	if err := st.SetGroupTags("g1@g.us", []string{"reseller"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := st.SetGroupTags("g2@g.us", []string{"reseller"}); err != nil {
+		t.Fatal(err)
+	}
+	p := func(number string, admin bool) model.Participant {
+		return model.Participant{JID: number + "@s.whatsapp.net", Number: number, IsAdmin: admin}
+	}
+	parts := map[string][]model.Participant{
+		"g1@g.us": {p("6281111111", true), p("6282222222", false), p("62800a", true)},
+		"g2@g.us": {p("6281111111", false), p("6282222222", false), p("4470000000", true)},
+		"g3@g.us": {p("6282222222", false), p("4470000000", false)},
+	}
+	for gid, list := range parts {
+		if err := st.CacheGroupParticipants(gid, list); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	admins, err := st.SegmentNumbers(model.SegmentRule{Tags: []string{"reseller"}, Role: model.SegmentRoleAdmin})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(admins) != 2 || admins[0].Number != "4470000000" || admins[1].Number != "6281111111" {
+		t.Errorf("admins of tagged groups = %+v", admins)
+	}
+
+	aud := model.Audience{Name: "62 di 3 grup", Segment: &model.SegmentRule{Prefix: "+62", MinGroups: 3}}
+	if err := st.CreateAudience(&aud); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := st.AddAudienceMembers(aud.ID, []model.AudienceMember{{Number: "6289999999", Source: model.AudienceSourceManual}}); err != nil {
+		t.Fatal(err)
+	}
+	if n, err := st.RefreshSegment(aud.ID); err != nil || n != 1 {
+		t.Fatalf("RefreshSegment = %d, %v; want 1", n, err)
+	}
+	// Aturan dilonggarkan: refresh mengganti anggota segmen tanpa menyentuh anggota manual.
+	if err := st.SetAudienceSegment(aud.ID, &model.SegmentRule{Prefix: "62", MinGroups: 2}); err != nil {
+		t.Fatal(err)
+	}
+	if n, err := st.RefreshSegment(aud.ID); err != nil || n != 2 {
+		t.Fatalf("RefreshSegment = %d, %v; want 2", n, err)
+	}
+	got, err := st.AudienceNumbers([]string{aud.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"6289999999", "6281111111", "6282222222"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("members = %v, want %v", got, want)
+	}
+	if a, _ := st.GetAudience(aud.ID); a.RefreshedAt == nil || a.Segment == nil || a.Segment.MinGroups != 2 {
+		t.Errorf("audience after refresh = %+v", a)
+	}
+}
+
+func TestAutoJoinSettingsAndLogs(t *testing.T) {
+	st := storagetest.Open(t)
+	storagetest.SeedAccount(t, st, storagetest.Account{ID: "a"})
+
+	def, err := st.LoadAutoJoinSettings("a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(def, model.DefaultAutoJoinSettings("a")) {
+		t.Fatalf("settings before save = %+v, want defaults", def)
+	}
+	want := model.AutoJoinSettings{AccountID: "a", Enabled: true, DailyLimit: 5, PreviewBeforeJoin: true,
+		WhitelistContacts: []string{"628111"}, BlacklistKeywords: []string{}, MinParticipants: 200,
+		AutoEnableAfterJoin: true, AutoEnableSettleMin: 30, JoinJitterMinSec: 20, JoinJitterMaxSec: 90}
+	var windows model.WeekWindows
+	for i := range windows {
+		windows[i] = [][2]int{}
+	}
+	windows[time.Monday] = [][2]int{{9 * 60, 12 * 60}, {15 * 60, 21 * 60}}
+	want.JoinWindows = &windows
+	if err := st.SaveAutoJoinSettings(want); err != nil {
+		t.Fatal(err)
+	}
+	if err := st.SetAutoJoinEnabled("a", false); err != nil {
+		t.Fatal(err)
+	}
+	want.Enabled = false
+	if got, _ := st.LoadAutoJoinSettings("a"); !reflect.DeepEqual(got, want) {
+		t.Fatalf("settings = %+v, want %+v", got, want)
+	}
+
+	for _, l := range []model.AutoJoinLog{
+		{AccountID: "a", InviteCode: "AAA", GroupID: "g1@g.us", Status: model.AutoJoinJoined},
+		{AccountID: "a", InviteCode: "BBB", Status: model.AutoJoinFailed, Reason: "join: gone"},
+		{AccountID: "a", InviteCode: "CCC", Status: model.AutoJoinSkipped, Reason: "rate_limit"},
+	} {
+		if err := st.LogAutoJoinAttempt(l); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n, err := st.CountJoinsToday("a"); err != nil || n != 1 {
+		t.Fatalf("joins today = %d, %v; want 1", n, err)
+	}
+	if ok, _ := st.HasJoinedInvite("a", "AAA"); !ok {
+		t.Fatal("HasJoinedInvite(AAA) = false, want true")
+	}
+	if ok, _ := st.HasJoinedInvite("a", "BBB"); ok {
+		t.Fatal("HasJoinedInvite(BBB) = true, want false (failed attempt)")
+	}
+	failed, err := st.ListAutoJoinLogs("a", model.AutoJoinFailed, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(failed) != 1 || failed[0].InviteCode != "BBB" || failed[0].Reason != "join: gone" {
+		t.Fatalf("failed logs = %+v", failed)
+	}
+	stats, err := st.AutoJoinStats("a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats != (model.AutoJoinStats{TotalJoined: 1, TotalFailed: 1, TotalSkipped: 1, JoinedToday: 1}) {
+		t.Fatalf("stats = %+v", stats)
+	}
+}
+
+func TestSaveGroupLinkResetsCheckOnChange(t *testing.T) {
+	st := storagetest.Open(t)
+	storagetest.SeedAccount(t, st, storagetest.Account{ID: "a"})
+	storagetest.SeedGroup(t, st, storagetest.Group{ID: "g@g.us", AccountID: "a"})
+
+	if err := st.SaveGroupLink("g@g.us", "a", "https://chat.whatsapp.com/OLD"); err != nil {
+		t.Fatal(err)
+	}
+	if err := st.RecordGroupLinkCheck("g@g.us", model.LinkRevoked, 10, "invite link revoked", time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	// Simpan ulang link yang sama tidak menghapus hasil cek.
+	if err := st.SaveGroupLink("g@g.us", "a", "https://chat.whatsapp.com/OLD"); err != nil {
+		t.Fatal(err)
+	}
+	l, err := st.GetGroupLink("g@g.us")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l.Status != model.LinkRevoked || l.CheckedAt == nil {
+		t.Fatalf("after same link: %+v, want revoked with checked_at", l)
+	}
+	broken, _ := st.ListGroupLinks("a", model.LinkRevoked)
+	if len(broken) != 1 {
+		t.Fatalf("revoked links = %d, want 1", len(broken))
+	}
+
+	if err := st.SaveGroupLink("g@g.us", "a", "https://chat.whatsapp.com/NEW"); err != nil {
+		t.Fatal(err)
+	}
+	l, _ = st.GetGroupLink("g@g.us")
+	if l.InviteLink != "https://chat.whatsapp.com/NEW" || l.Status != "" || l.CheckedAt != nil || l.Error != "" {
+		t.Fatalf("after new link: %+v, want unchecked", l)
+	}
+	if _, err := st.GetGroupLink("other@g.us"); err != storage.ErrGroupLinkNotFound {
+		t.Fatalf("missing link err = %v, want ErrGroupLinkNotFound", err)
+	}
+}
+
+func TestAutoJoinQueueDecisions(t *testing.T) {
+	st := storagetest.Open(t)
+	storagetest.SeedAccount(t, st, storagetest.Account{ID: "a"})
+
+	id1, created, err := st.EnqueueAutoJoin(model.AutoJoinQueueItem{AccountID: "a", InviteCode: "AAA", SharedBy: "x@s.whatsapp.net"})
+	if err != nil || !created {
+		t.Fatalf("enqueue AAA: created=%v err=%v", created, err)
+	}
+	// Kode yang sama tidak diantrekan dua kali.
+	again, created, err := st.EnqueueAutoJoin(model.AutoJoinQueueItem{AccountID: "a", InviteCode: "AAA"})
+	if err != nil || created || again != id1 {
+		t.Fatalf("re-enqueue AAA: id=%d created=%v err=%v, want id %d not created", again, created, err, id1)
+	}
+	id2, _, _ := st.EnqueueAutoJoin(model.AutoJoinQueueItem{AccountID: "a", InviteCode: "BBB"})
+
+	if n, err := st.DecideAutoJoinQueue([]int64{id1}, true); err != nil || n != 1 {
+		t.Fatalf("approve: n=%d err=%v", n, err)
+	}
+	if n, _ := st.DecideAutoJoinQueue([]int64{id1, id2}, false); n != 1 {
+		t.Fatalf("reject = %d, want 1 (approved item is not re-decided)", n)
+	}
+	approved, err := st.ApprovedAutoJoins(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(approved) != 1 || approved[0].InviteCode != "AAA" || approved[0].DecidedAt == nil {
+		t.Fatalf("approved = %+v", approved)
+	}
+	if err := st.FinishAutoJoinQueue(id1, model.AutoJoinJoined, ""); err != nil {
+		t.Fatal(err)
+	}
+	if approved, _ = st.ApprovedAutoJoins(10); len(approved) != 0 {
+		t.Fatalf("approved after finish = %d, want 0", len(approved))
+	}
+	pending, _ := st.ListAutoJoinQueue("a", model.AutoJoinPending, 10)
+	if len(pending) != 0 {
+		t.Fatalf("pending = %d, want 0", len(pending))
+	}
+}
+
+func TestImportAutoJoinsHonorsNotBefore(t *testing.T) {
+	st := storagetest.Open(t)
+	storagetest.SeedAccount(t, st, storagetest.Account{ID: "a"})
+	if _, _, err := st.EnqueueAutoJoin(model.AutoJoinQueueItem{AccountID: "a", InviteCode: "OLD"}); err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	later := now.Add(time.Hour)
+	n, err := st.ImportAutoJoins([]model.AutoJoinQueueItem{
+		{AccountID: "a", InviteCode: "NOW", SharedBy: "import", NotBefore: &now},
+		{AccountID: "a", InviteCode: "LATER", SharedBy: "import", NotBefore: &later},
+		{AccountID: "a", InviteCode: "OLD", SharedBy: "import", NotBefore: &now},
+	})
+	if err != nil || n != 2 {
+		t.Fatalf("import: n=%d err=%v, want 2 (OLD already queued)", n, err)
+	}
+	approved, err := st.ApprovedAutoJoins(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(approved) != 1 || approved[0].InviteCode != "NOW" || approved[0].NotBefore == nil {
+		t.Fatalf("approved = %+v, want only NOW due", approved)
+	}
+}
+
+func TestAutoJoinRetryAndRequeue(t *testing.T) {
+	st := storagetest.Open(t)
+	storagetest.SeedAccount(t, st, storagetest.Account{ID: "a"})
+	later := time.Now().Add(time.Hour)
+	it := model.AutoJoinQueueItem{AccountID: "a", InviteCode: "CODE", SharedBy: "x", NotBefore: &later, Attempts: 1}
+	if err := st.ScheduleAutoJoinRetry(it, "join: timeout"); err != nil {
+		t.Fatal(err)
+	}
+	if n, _ := st.AutoJoinAttempts("a", "CODE"); n != 1 {
+		t.Fatalf("attempts = %d, want 1", n)
+	}
+	if due, _ := st.ApprovedAutoJoins(10); len(due) != 0 {
+		t.Fatalf("due before backoff = %d, want 0", len(due))
+	}
+	list, _ := st.ListAutoJoinQueue("a", model.AutoJoinRetrying, 10)
+	if len(list) != 1 || list[0].Reason != "join: timeout" {
+		t.Fatalf("retrying = %+v", list)
+	}
+
+	id, err := st.RequeueAutoJoin(model.AutoJoinQueueItem{AccountID: "a", InviteCode: "CODE"})
+	if err != nil || id != list[0].ID {
+		t.Fatalf("requeue: id=%d err=%v, want %d", id, err, list[0].ID)
+	}
+	due, _ := st.ApprovedAutoJoins(10)
+	if len(due) != 1 || due[0].Attempts != 0 || due[0].Status != model.AutoJoinApproved || due[0].SharedBy != "x" {
+		t.Fatalf("due after requeue = %+v", due)
+	}
+}
+
+func TestCrossPromoSkipsPromotedPairs(t *testing.T) {
+	st := storagetest.Open(t)
+	storagetest.SeedAccount(t, st, storagetest.Account{ID: "a"})
+	for _, g := range []string{"promo@g.us", "t1@g.us", "t2@g.us"} {
+		storagetest.SeedGroup(t, st, storagetest.Group{ID: g, AccountID: "a", Enabled: true})
+		if err := st.SetGroupTags(g, []string{"kuliner"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := st.SetGroupParticipantCount("promo@g.us", 42); err != nil {
+		t.Fatal(err)
+	}
+	if err := st.SaveGroupLink("promo@g.us", "a", "https://chat.whatsapp.com/PROMO"); err != nil {
+		t.Fatal(err)
+	}
+	src, err := st.CrossPromoSource("promo@g.us")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if src.MemberCount != 42 || src.InviteLink != "https://chat.whatsapp.com/PROMO" {
+		t.Fatalf("source = %+v, want unchecked link with participant count 42", src)
+	}
+
+	if err := st.RecordCrossPromo("promo@g.us", "t1@g.us", "", "a"); err != nil {
+		t.Fatal(err)
+	}
+	target := model.Targeting{IncludeTags: []string{"kuliner"}}
+	got, err := st.PickCrossPromoTarget("a", 0, 100, "promo@g.us", target, rng.New(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "t2@g.us" {
+		t.Fatalf("picked %q, want t2@g.us (promo itself and t1 already done)", got)
+	}
+	if err := st.RecordCrossPromo("promo@g.us", "t2@g.us", "", "a"); err != nil {
+		t.Fatal(err)
+	}
+	got, err = st.PickCrossPromoTarget("a", 0, 100, "promo@g.us", target, rng.New(1))
+	if err != nil || got != "" {
+		t.Fatalf("picked %q err=%v, want none left", got, err)
+	}
+	list, _ := st.ListCrossPromos("promo@g.us", 10)
+	if len(list) != 2 {
+		t.Fatalf("cross promos = %d, want 2", len(list))
+	}
+}
+
+func TestTimelineInterleavesAnnotations(t *testing.T) {
+	st := storagetest.Open(t)
+	storagetest.SeedAccount(t, st, storagetest.Account{ID: "a"})
+	storagetest.SeedAccount(t, st, storagetest.Account{ID: "b"})
+	storagetest.SeedGroup(t, st, storagetest.Group{ID: "g@g.us", AccountID: "a", Enabled: true})
+	now := time.Now().UTC().Truncate(time.Second)
+	storagetest.SeedLog(t, st, "a", "g@g.us", "sent", now.Add(-3*time.Hour))
+	storagetest.SeedLog(t, st, "a", "g@g.us", "failed", now.Add(-time.Hour))
+	for _, n := range []model.Annotation{
+		{At: now.Add(-2 * time.Hour), Severity: model.AnnotationWarning, Text: "akun a dijeda", AccountID: "a"},
+		{At: now.Add(-30 * time.Minute), Text: "serah terima shift"},
+		{At: now.Add(-90 * time.Minute), Text: "akun b diganti nomor", AccountID: "b"},
+	} {
+		if _, err := st.CreateAnnotation(n); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tl, err := st.Timeline(model.TimelineFilter{AccountID: "a", Limit: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, e := range tl {
+		if e.Kind == model.TimelineLog {
+			got = append(got, "log:"+e.Log.Status)
+		} else {
+			got = append(got, "note:"+e.Annotation.Text)
+		}
+	}
+	want := []string{"note:serah terima shift", "log:failed", "note:akun a dijeda", "log:sent"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("timeline = %v, want %v", got, want)
+	}
+	if tl, _ := st.Timeline(model.TimelineFilter{AccountID: "a", Limit: 2}); len(tl) != 2 {
+		t.Fatalf("limited timeline = %d entries, want 2", len(tl))
+	}
+}
+
+func TestDetectedLanguageFiltersTemplates(t *testing.T) {
+	st := storagetest.Open(t)
+	storagetest.SeedAccount(t, st, storagetest.Account{ID: "a"})
+	storagetest.SeedGroup(t, st, storagetest.Group{ID: "g@g.us", AccountID: "a", Enabled: true})
+	if err := st.SetGroupDetectedLanguage("g@g.us", "en"); err != nil {
+		t.Fatal(err)
+	}
+	if lang, _ := st.GroupLanguage("g@g.us"); lang != "en" {
+		t.Fatalf("language = %q, want detected en", lang)
+	}
+	if err := st.SetGroupLanguage("g@g.us", "id"); err != nil {
+		t.Fatal(err)
+	}
+	if lang, _ := st.GroupLanguage("g@g.us"); lang != "id" {
+		t.Fatalf("language = %q, want manual id to win", lang)
+	}
+
+	if _, err := st.CreateTemplate(model.Template{Name: "en", TextOnly: "hello", Enabled: true, Languages: []string{"EN"}}); err != nil {
+		t.Fatal(err)
+	}
+	if tpl, err := st.RandomTemplate(context.Background(), "id", rng.New(1)); !errors.Is(err, storage.ErrTemplateNotFound) {
+		t.Fatalf("got %q err=%v, want no template for id", tpl.Name, err)
+	}
+	tpl, err := st.RandomTemplate(context.Background(), "en", rng.New(1))
+	if err != nil || tpl.Name != "en" || len(tpl.Languages) != 1 || tpl.Languages[0] != "en" {
+		t.Fatalf("got %+v err=%v, want en template", tpl, err)
+	}
+}
+
+func TestAccountStreaksCountFailuresSinceLastSuccess(t *testing.T) {
+	st := storagetest.Open(t)
+	storagetest.SeedAccount(t, st, storagetest.Account{ID: "a"})
+	storagetest.SeedAccount(t, st, storagetest.Account{ID: "b"})
+	storagetest.SeedGroup(t, st, storagetest.Group{ID: "g@g.us", AccountID: "a", Enabled: true})
+	now := time.Now().UTC()
+	for i, status := range []string{"failed", "sent", "failed", "skipped", "throttled"} {
+		storagetest.SeedLog(t, st, "a", "g@g.us", status, now.Add(time.Duration(i-10)*time.Minute))
+	}
+	storagetest.SeedLog(t, st, "b", "g@g.us", "failed", now)
+
+	list, err := st.AccountStreaks()
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := map[string]model.AccountStreak{}
+	for _, s := range list {
+		got[s.AccountID] = s
+	}
+	a := got["a"]
+	if a.FailureStreak != 2 || a.LastSuccessAt == nil || a.LastFailureAt == nil {
+		t.Fatalf("a = %+v, want streak 2 with last success/failure", a)
+	}
+	if d := now.Sub(*a.LastSuccessAt); d < 8*time.Minute || d > 10*time.Minute {
+		t.Fatalf("since last success = %v, want ~9m", d)
+	}
+	if b := got["b"]; b.FailureStreak != 1 || b.LastSuccessAt != nil {
+		t.Fatalf("b = %+v, want streak 1 and no success", b)
+	}
+}
+
+func TestScheduledGroupEnableWaitsForSettle(t *testing.T) {
+	st := storagetest.Open(t)
+	storagetest.SeedAccount(t, st, storagetest.Account{ID: "a"})
+	now := time.Now()
+	if err := st.ScheduleGroupEnable("a", "due@g.us", "Due", now.Add(-time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	if err := st.ScheduleGroupEnable("a", "later@g.us", "Later", now.Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	if err := st.ScheduleGroupEnable("a", "manual@g.us", "Manual", now.Add(-time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	// toggle manual membatalkan jadwal
+	if _, err := st.ToggleGroup("manual@g.us", false); err != nil {
+		t.Fatal(err)
+	}
+
+	ids, err := st.EnableSettledGroups()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(ids, []string{"due@g.us"}) {
+		t.Fatalf("enabled = %v, want [due@g.us]", ids)
+	}
+	groups, _ := st.ListGroups("a")
+	for _, g := range groups {
+		if g.Enabled != (g.ID == "due@g.us") {
+			t.Fatalf("group %s enabled = %v", g.ID, g.Enabled)
+		}
+	}
+	if ids, _ := st.EnableSettledGroups(); len(ids) != 0 {
+		t.Fatalf("second pass enabled %v, want none", ids)
+	}
+}
+
+func TestAutoJoinIntroSchedule(t *testing.T) {
+	st := storagetest.Open(t)
+	storagetest.SeedAccount(t, st, storagetest.Account{ID: "a"})
+	tpl := storagetest.SeedTemplate(t, st, "intro", "Halo semua, salam kenal!")
+	now := time.Now()
+	if ok, err := st.ScheduleAutoJoinIntro("a", "due@g.us", tpl, now.Add(-time.Minute)); err != nil || !ok {
+		t.Fatalf("schedule = %v, %v", ok, err)
+	}
+	if ok, _ := st.ScheduleAutoJoinIntro("a", "due@g.us", tpl, now); ok {
+		t.Fatal("second intro for the same group was scheduled")
+	}
+	if _, err := st.ScheduleAutoJoinIntro("a", "later@g.us", tpl, now.Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+
+	due, err := st.DueAutoJoinIntros(10)
+	if err != nil || len(due) != 1 || due[0].GroupID != "due@g.us" || due[0].TemplateID != tpl {
+		t.Fatalf("due = %+v err=%v, want due@g.us", due, err)
+	}
+	if err := st.FinishAutoJoinIntro(due[0].ID, model.IntroSent, ""); err != nil {
+		t.Fatal(err)
+	}
+	if due, _ := st.DueAutoJoinIntros(10); len(due) != 0 {
+		t.Fatalf("due after send = %d, want 0", len(due))
+	}
+	list, _ := st.ListAutoJoinIntros("a", 10)
+	if len(list) != 2 || list[1].Status != model.IntroSent || list[1].SentAt == nil {
+		t.Fatalf("intros = %+v", list)
+	}
+}
+
+func TestAutoLeaveCandidatesAndPolicy(t *testing.T) {
+	st := storagetest.Open(t)
+	storagetest.SeedAccount(t, st, storagetest.Account{ID: "a"})
+	now := time.Now().UTC()
+	for _, id := range []string{"fail@g.us", "tiny@g.us", "idle@g.us", "ok@g.us", "own@g.us"} {
+		storagetest.SeedGroup(t, st, storagetest.Group{ID: id, AccountID: "a", Enabled: true})
+		if err := st.SetGroupParticipantCount(id, 50); err != nil {
+			t.Fatal(err)
+		}
+	}
+	storagetest.SeedGroup(t, st, storagetest.Group{ID: "test@g.us", AccountID: "a", TestGroup: true})
+	if err := st.SetGroupParticipantCount("tiny@g.us", 2); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := st.DB.Exec(`UPDATE groups SET created_at=? WHERE id IN ('idle@g.us','test@g.us')`,
+		now.Add(-90*24*time.Hour).Format(storagetest.SQLTime)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := st.DB.Exec(`INSERT INTO group_links (group_id, account_id, invite_link) VALUES ('own@g.us','a','x')`); err != nil {
+		t.Fatal(err)
+	}
+	for i, status := range []string{"sent", "failed", "failed", "failed"} {
+		storagetest.SeedLog(t, st, "a", "fail@g.us", status, now.Add(time.Duration(i-10)*time.Minute))
+	}
+	for _, id := range []string{"tiny@g.us", "ok@g.us"} {
+		storagetest.SeedLog(t, st, "a", id, "sent", now.Add(-time.Hour))
+	}
+
+	list, err := st.AutoLeaveCandidates("a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := model.AutoLeavePolicy{Enabled: true, FailureStreak: 3, MinMembers: 5, InactiveDays: 30, MaxPerRun: 5}
+	got := map[string]string{}
+	for _, c := range list {
+		got[c.GroupID] = p.Reason(c, now)
+	}
+	if _, ok := got["test@g.us"]; ok {
+		t.Fatal("test group must not be a candidate")
+	}
+	if _, ok := got["own@g.us"]; ok {
+		t.Fatal("own group (group_links) must not be a candidate")
+	}
+	if len(got) != 4 || got["fail@g.us"] == "" || got["tiny@g.us"] == "" || got["idle@g.us"] == "" || got["ok@g.us"] != "" {
+		t.Fatalf("reasons = %v", got)
+	}
+
+	if err := st.LogAutoLeave(model.AutoLeaveLog{AccountID: "a", GroupID: "idle@g.us", Status: model.AutoLeaveDryRun, Reason: got["idle@g.us"]}); err != nil {
+		t.Fatal(err)
+	}
+	if logs, _ := st.ListAutoLeaveLogs("a", 10); len(logs) != 1 || logs[0].Status != model.AutoLeaveDryRun {
+		t.Fatalf("logs = %+v", logs)
+	}
+}
+
+func TestPairingLinks(t *testing.T) {
+	st := storagetest.Open(t)
+	storagetest.SeedAccount(t, st, storagetest.Account{ID: "a"})
+	l, token, err := st.CreatePairingLink("a", "admin", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := st.PairingLinkByToken(token)
+	if err != nil || got.ID != l.ID || got.AccountID != "a" {
+		t.Fatalf("lookup = %+v, %v", got, err)
+	}
+	if _, err := st.PairingLinkByToken("pl_wrong"); !errors.Is(err, storage.ErrPairingLinkNotFound) {
+		t.Fatalf("wrong token err = %v", err)
+	}
+	if err := st.MarkPairingLinkUsed(l.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := st.PairingLinkByToken(token); !errors.Is(err, storage.ErrPairingLinkNotFound) {
+		t.Fatalf("used link err = %v", err)
+	}
+
+	expired, expiredToken, err := st.CreatePairingLink("a", "", -time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := st.PairingLinkByToken(expiredToken); !errors.Is(err, storage.ErrPairingLinkNotFound) {
+		t.Fatalf("expired link err = %v", err)
+	}
+	_, revokedToken, err := st.CreatePairingLink("a", "", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	list, err := st.ListPairingLinks("a")
+	if err != nil || len(list) != 3 {
+		t.Fatalf("list = %d, %v", len(list), err)
+	}
+	if err := st.RevokePairingLink("a", list[0].ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := st.PairingLinkByToken(revokedToken); !errors.Is(err, storage.ErrPairingLinkNotFound) {
+		t.Fatalf("revoked link err = %v", err)
+	}
+	if err := st.RevokePairingLink("b", expired.ID); !errors.Is(err, storage.ErrPairingLinkNotFound) {
+		t.Fatalf("revoke other account err = %v", err)
+	}
+}
+
+func TestOutboxClaimFinishAndRecover(t *testing.T) {
+	st := storagetest.Open(t)
+	storagetest.SeedAccount(t, st, storagetest.Account{ID: "a"})
+	now := time.Now()
+	later, err := st.EnqueueOutbox(model.OutboxItem{AccountID: "a", GroupID: "later@g.us", ScheduledAt: now.Add(time.Hour)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	low, _ := st.EnqueueOutbox(model.OutboxItem{AccountID: "a", GroupID: "low@g.us", ScheduledAt: now.Add(-time.Minute)})
+	high, _ := st.EnqueueOutbox(model.OutboxItem{AccountID: "a", GroupID: "high@g.us", Priority: 5, ScheduledAt: now})
+
+	it, ok, err := st.ClaimNextOutbox(now.Add(time.Second))
+	if err != nil || !ok || it.ID != high || it.Status != model.OutboxClaimed || it.Attempts != 1 {
+		t.Fatalf("first claim = %+v ok=%v err=%v, want high-priority item", it, ok, err)
+	}
+	if err := st.FinishOutbox(it.ID, nil); err != nil {
+		t.Fatal(err)
+	}
+	it, ok, _ = st.ClaimNextOutbox(now.Add(time.Second))
+	if !ok || it.ID != low {
+		t.Fatalf("second claim = %+v ok=%v, want low", it, ok)
+	}
+	if err := st.FinishOutbox(it.ID, errors.New("boom")); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok, _ := st.ClaimNextOutbox(now.Add(time.Second)); ok {
+		t.Fatal("item scheduled later must not be claimed yet")
+	}
+	got, _ := st.GetOutboxItem(low)
+	if got.Status != model.OutboxFailed || got.LastError != "boom" {
+		t.Fatalf("failed item = %+v", got)
+	}
+	if got, _ := st.GetOutboxItem(high); got.Status != model.OutboxSent || got.SentAt == nil {
+		t.Fatalf("sent item = %+v", got)
+	}
+
+	// Dua item tertinggal claimed (proses mati): yang sudah punya log sent ditandai sent, sisanya pending.
+	it, ok, _ = st.ClaimNextOutbox(now.Add(2 * time.Hour))
+	if !ok || it.ID != later {
+		t.Fatalf("claim later = %+v ok=%v", it, ok)
+	}
+	crashed, _ := st.EnqueueOutbox(model.OutboxItem{AccountID: "a", GroupID: "crash@g.us", ScheduledAt: now.Add(-time.Minute)})
+	if _, ok, _ := st.ClaimNextOutbox(now); !ok {
+		t.Fatal("claim crashed item")
+	}
+	storagetest.SeedGroup(t, st, storagetest.Group{ID: "later@g.us", AccountID: "a", Enabled: true})
+	storagetest.SeedLog(t, st, "a", "later@g.us", "sent", now.Add(3*time.Hour))
+	sent, requeued, err := st.RecoverOutbox()
+	if err != nil || sent != 1 || requeued != 1 {
+		t.Fatalf("recover sent=%d requeued=%d err=%v, want 1/1", sent, requeued, err)
+	}
+	if got, _ := st.GetOutboxItem(later); got.Status != model.OutboxSent {
+		t.Fatalf("already-sent item status = %s, want sent", got.Status)
+	}
+	if got, _ := st.GetOutboxItem(crashed); got.Status != model.OutboxPending || got.ClaimedAt != nil {
+		t.Fatalf("interrupted item = %+v, want pending", got)
+	}
+}
+
+func TestHolidays(t *testing.T) {
+	st := storagetest.Open(t)
+	for _, h := range []model.Holiday{{Date: "2027-03-10", Name: "Idul Fitri"}, {Date: "2027-03-09", Name: "Cuti"}, {Date: "2027-03-10", Name: "Lebaran"}} {
+		if err := st.SetHoliday(h); err != nil {
+			t.Fatal(err)
+		}
+	}
+	list, err := st.ListHolidays("")
+	if err != nil || len(list) != 2 || list[0].Date != "2027-03-09" || list[1].Name != "Lebaran" {
+		t.Fatalf("holidays = %+v err=%v", list, err)
+	}
+	if list, _ := st.ListHolidays("2027-03-10"); len(list) != 1 {
+		t.Fatalf("upcoming holidays = %+v, want 1", list)
+	}
+	wib := time.FixedZone("WIB", 7*3600)
+	// 2027-03-09 18:00 UTC sudah 2027-03-10 di WIB.
+	if h, ok, err := st.HolidayOn(time.Date(2027, 3, 9, 18, 0, 0, 0, time.UTC).In(wib)); err != nil || !ok || h.Name != "Lebaran" {
+		t.Fatalf("holiday on = %+v ok=%v err=%v", h, ok, err)
+	}
+	if _, ok, _ := st.HolidayOn(time.Date(2027, 3, 11, 12, 0, 0, 0, wib)); ok {
+		t.Fatal("2027-03-11 is not a holiday")
+	}
+	if err := st.DeleteHoliday("2027-03-09"); err != nil {
+		t.Fatal(err)
+	}
+	if err := st.DeleteHoliday("2027-03-09"); !errors.Is(err, storage.ErrHolidayNotFound) {
+		t.Fatalf("delete twice err = %v, want ErrHolidayNotFound", err)
+	}
+}
+
+// workspaceRuntimeTables tabel data runtime yang sengaja tidak ikut ekspor workspace.
+var workspaceRuntimeTables = map[string]bool{
+	"account_events": true, "annotations": true, "api_keys": true, "auto_join_intros": true,
+	"auto_join_logs": true, "auto_join_queue": true, "auto_leave_logs": true, "channel_posts": true,
+	"channels": true, "cron_tasks": true, "digests": true, "dm_runs": true, "dm_sends": true,
+	"dm_targets": true, "feed_items": true, "group_links": true, "group_members": true,
+	"group_participants": true, "group_trials": true, "logs": true, "message_acks": true,
+	"outbox": true, "pairing_links": true, "reply_optins": true, "status_posts": true,
+	"template_versions": true, "user_sessions": true, "users": true, "webhook_deliveries": true,
+}
+
+func TestWorkspaceTablesCoverSchema(t *testing.T) {
+	st := storagetest.Open(t)
+	rows, err := st.DB.Query(`SELECT name FROM sqlite_master WHERE type='table' AND name NOT LIKE 'sqlite_%'`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	listed := map[string]bool{}
+	for _, tbl := range storage.WorkspaceTables {
+		listed[tbl] = true
+	}
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			t.Fatal(err)
+		}
+		if !listed[name] && !workspaceRuntimeTables[name] {
+			t.Errorf("table %s is neither exported in the workspace nor marked as runtime data", name)
+		}
+	}
+}
+
+func TestWorkspaceRoundTrip(t *testing.T) {
+	src := storagetest.Open(t)
+	storagetest.SeedAccount(t, src, storagetest.Account{ID: "a"})
+	storagetest.SeedGroup(t, src, storagetest.Group{ID: "g1@g.us", AccountID: "a", Enabled: true})
+	tpl := storagetest.SeedTemplate(t, src, "promo", "Halo {group_name}")
+	if _, err := src.CreateRecurringSchedule(model.RecurringSchedule{AccountID: "a", Times: []string{"09:00"}, TemplateID: tpl, Enabled: true}); err != nil {
+		t.Fatal(err)
+	}
+	if err := src.SetHoliday(model.Holiday{Date: "2027-03-10", Name: "Idul Fitri"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := src.SetAccountWarmup("a", model.DefaultWarmupProfile()); err != nil {
+		t.Fatal(err)
+	}
+	var wins model.WeekWindows
+	wins[1] = [][2]int{{9 * 60, 17 * 60}}
+	if err := src.SetAccountSendWindow("a", wins); err != nil {
+		t.Fatal(err)
+	}
+	if err := src.SetGroupCaps("g1@g.us", nil, new(int)); err != nil {
+		t.Fatal(err)
+	}
+	if err := src.SetAccountRotation(model.AccountRotation{Strategy: model.RotationLRU}); err != nil {
+		t.Fatal(err)
+	}
+	if err := src.UpsertDynamicVar(model.DynamicVar{Name: "stok", URLTemplate: "https://example.com/stok", TimeoutMs: 3000, CacheTTLSec: 60, Enabled: true}); err != nil {
+		t.Fatal(err)
+	}
+	aud := &model.Audience{Name: "pelanggan"}
+	if err := src.CreateAudience(aud); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := src.AddAudienceMembers(aud.ID, []model.AudienceMember{{Number: "628111", Source: "manual"}}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := src.CreateWebhook(model.Webhook{Name: "ops", URL: "https://example.com/hook", Enabled: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	ws, err := src.ExportWorkspace(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw, err := json.Marshal(ws)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var in storage.Workspace
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	if err := dec.Decode(&in); err != nil {
+		t.Fatal(err)
+	}
+	dst := storagetest.Open(t)
+	if _, err := dst.ImportWorkspace(context.Background(), &in); err != nil {
+		t.Fatal(err)
+	}
+	got, err := dst.ExportWorkspace(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tbl := range []string{"recurring_schedules", "holidays", "account_warmup", "account_send_windows",
+		"groups", "settings", "dynamic_vars", "audiences", "audience_members", "webhooks"} {
+		if len(ws.Tables[tbl]) == 0 {
+			t.Fatalf("table %s not exported", tbl)
+		}
+		want, _ := json.Marshal(ws.Tables[tbl])
+		have, _ := json.Marshal(got.Tables[tbl])
+		if !bytes.Equal(want, have) {
+			t.Errorf("table %s after round trip:\n got %s\nwant %s", tbl, have, want)
+		}
+	}
+}
+
+func TestFeedTemplateLinkedToCampaign(t *testing.T) {
+	st := storagetest.Open(t)
+	cid, err := st.CreateCampaign(model.Campaign{Name: "flash sale", Mode: "group"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	fid, err := st.CreateFeed(model.Feed{CampaignID: cid, Name: "blog", URL: "https://example.com/feed.xml"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	item := model.FeedItem{FeedID: fid, GUID: "post-1", Title: "Diskon", Status: "pending"}
+	if _, err := st.SaveFeedItem(item, &storage.FeedTemplate{Name: "[feed] Diskon", TextOnly: "Diskon", CampaignID: cid}, false); err != nil {
+		t.Fatal(err)
+	}
+	items, err := st.ListFeedItems(fid, "", 0)
+	if err != nil || len(items) != 1 {
+		t.Fatalf("feed items = %d err=%v, want 1", len(items), err)
+	}
+	tpl, err := st.GetTemplate(items[0].TemplateID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tpl.CampaignID != cid {
+		t.Fatalf("template campaign = %q, want %q", tpl.CampaignID, cid)
+	}
+}
+
+func TestWorkspaceExportRedactsSecrets(t *testing.T) {
+	st := storagetest.Open(t)
+	hook, err := st.CreateWebhook(model.Webhook{Name: "ops", URL: "https://example.com/hook", Secret: "hook-secret", Enabled: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := st.SetTelegramMirror(model.TelegramMirror{Enabled: true, BotToken: "123:bot-token", ChatID: "@promo"}); err != nil {
+		t.Fatal(err)
+	}
+	ws, err := st.ExportWorkspace(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw, err := json.Marshal(ws)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(raw, []byte("hook-secret")) || bytes.Contains(raw, []byte("bot-token")) {
+		t.Fatalf("workspace export leaks secrets: %s", raw)
+	}
+
+	// Import snapshot yang sudah disamarkan tidak menghapus rahasia yang tersimpan.
+	var in storage.Workspace
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	if err := dec.Decode(&in); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := st.ImportWorkspace(context.Background(), &in); err != nil {
+		t.Fatal(err)
+	}
+	if w, err := st.GetWebhook(hook); err != nil || w.Secret != "hook-secret" {
+		t.Fatalf("webhook secret after import = %q err=%v", w.Secret, err)
+	}
+	if m, err := st.TelegramMirror(); err != nil || m.BotToken != "123:bot-token" || m.ChatID != "@promo" {
+		t.Fatalf("telegram mirror after import = %+v err=%v", m, err)
+	}
+}
+
+func TestAccountOpsHelpers(t *testing.T) {
+	st := storagetest.Open(t)
+	storagetest.SeedAccount(t, st, storagetest.Account{ID: "a"})
+	storagetest.SeedAccount(t, st, storagetest.Account{ID: "b"})
+	for _, g := range []storagetest.Group{
+		{ID: "g1@g.us", AccountID: "a", RiskScore: 5, LastSentAt: storagetest.Ago(time.Hour)},
+		{ID: "g2@g.us", AccountID: "a", RiskScore: 4},
+		{ID: "g3@g.us", AccountID: "b", RiskScore: 4},
+	} {
+		storagetest.SeedGroup(t, st, g)
+	}
+
+	if list, err := st.SearchAccounts("", "acc-b"); err != nil || len(list) != 1 || list[0].ID != "b" {
+		t.Fatalf("search by label = %+v, %v", list, err)
+	}
+	if id, err := st.AccountIDByMsisdn("62800a"); err != nil || id != "a" {
+		t.Fatalf("by msisdn = %q, %v", id, err)
+	}
+	if id, err := st.AccountIDByMsisdn("0"); err != nil || id != "" {
+		t.Fatalf("unknown msisdn = %q, %v", id, err)
+	}
+	if n, err := st.EnableAllGroups("a"); err != nil || n != 2 {
+		t.Fatalf("enable all = %d, %v", n, err)
+	}
+	if n, err := st.ResetGroupRisk("a", []string{"g1@g.us", " ", "g3@g.us"}); err != nil || n != 1 {
+		t.Fatalf("reset listed = %d, %v (g3 belongs to b)", n, err)
+	}
+	if n, err := st.ResetGroupRisk("a", nil); err != nil || n != 2 {
+		t.Fatalf("reset all = %d, %v", n, err)
+	}
+	if n, err := st.CountEligibleGroups("a", 48, 3); err != nil || n != 2 {
+		t.Fatalf("eligible after reset = %d, %v", n, err)
+	}
+}
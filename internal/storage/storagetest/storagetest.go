@@ -0,0 +1,166 @@
+// Package storagetest menyediakan scaffold test untuk storage: database SQLite sementara per
+// test, fixture seed (akun, grup, template, log) dan jam palsu.
+package storagetest
+
+import (
+	"fmt"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"promote/internal/model"
+	"promote/internal/storage"
+)
+
+// SQLTime format CURRENT_TIMESTAMP SQLite (UTC), untuk kolom yang dibandingkan dengan datetime('now').
+const SQLTime = "2006-01-02 15:04:05"
+
+// DSN mengembalikan DSN file SQLite baru di direktori sementara test (dihapus otomatis).
+func DSN(t testing.TB) string {
+	t.Helper()
+	return "file:" + filepath.Join(t.TempDir(), "test.db") + "?_foreign_keys=on&_busy_timeout=5000"
+}
+
+// Open membuka Store baru (sudah dimigrasi) di database sementara; ditutup saat test selesai.
+// Batas hari memakai UTC tengah malam agar hasil tidak bergantung env DAY_RESET_*.
+func Open(t testing.TB) *storage.Store {
+	t.Helper()
+	return OpenDSN(t, DSN(t))
+}
+
+// OpenDSN seperti Open tetapi untuk DSN tertentu (mis. membuka ulang database yang sama untuk
+// menguji migrasi ulang).
+func OpenDSN(t testing.TB, dsn string) *storage.Store {
+	t.Helper()
+	st, err := storage.Open(dsn)
+	if err != nil {
+		t.Fatalf("open store: %v", err)
+	}
+	st.Day = storage.DayBoundary{Loc: time.UTC}
+	t.Cleanup(func() { _ = st.Close() })
+	return st
+}
+
+// Clock jam palsu yang aman dipakai bersamaan; Now bisa dipasang ke DayBoundary.Now.
+type Clock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+// NewClock membuat jam palsu yang menunjuk ke t.
+func NewClock(t time.Time) *Clock {
+	return &Clock{now: t}
+}
+
+// Now waktu jam palsu saat ini.
+func (c *Clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// Advance memajukan jam sebesar d.
+func (c *Clock) Advance(d time.Duration) {
+	c.mu.Lock()
+	c.now = c.now.Add(d)
+	c.mu.Unlock()
+}
+
+// Set memindahkan jam ke t.
+func (c *Clock) Set(t time.Time) {
+	c.mu.Lock()
+	c.now = t
+	c.mu.Unlock()
+}
+
+// UseClock memasang c sebagai sumber waktu batas hari Store.
+func UseClock(st *storage.Store, c *Clock) {
+	st.Day.Now = c.Now
+}
+
+// exec menjalankan statement fixture dan menggagalkan test jika error.
+func exec(t testing.TB, st *storage.Store, q string, args ...any) {
+	t.Helper()
+	if _, err := st.DB.Exec(q, args...); err != nil {
+		t.Fatalf("seed %q: %v", q, err)
+	}
+}
+
+// Account fixture akun. Kosong = default aktif dengan limit harian 100.
+type Account struct {
+	ID         string
+	DailyLimit int
+	Disabled   bool
+	Observer   bool
+}
+
+// SeedAccount menyimpan akun fixture (label dan msisdn diturunkan dari ID).
+func SeedAccount(t testing.TB, st *storage.Store, a Account) {
+	t.Helper()
+	if a.DailyLimit == 0 {
+		a.DailyLimit = 100
+	}
+	exec(t, st, `INSERT INTO accounts (id, label, msisdn, enabled, daily_limit, observer, status) VALUES (?,?,?,?,?,?,'connected')`,
+		a.ID, "acc-"+a.ID, "62800"+a.ID, btoi(!a.Disabled), a.DailyLimit, btoi(a.Observer))
+}
+
+// Group fixture grup. LastSentAt/RampAt nil = kosong di database.
+type Group struct {
+	ID         string
+	AccountID  string
+	Name       string
+	Enabled    bool
+	RiskScore  int
+	TestGroup  bool
+	LastSentAt *time.Time
+	RampAt     *time.Time
+}
+
+// SeedGroup menyimpan grup fixture beserta keanggotaan akunnya.
+func SeedGroup(t testing.TB, st *storage.Store, g Group) {
+	t.Helper()
+	if g.Name == "" {
+		g.Name = "group " + g.ID
+	}
+	exec(t, st, `INSERT INTO groups (id, account_id, name, enabled, risk_score, is_test, last_sent_at, ramp_at) VALUES (?,?,?,?,?,?,?,?)`,
+		g.ID, g.AccountID, g.Name, btoi(g.Enabled), g.RiskScore, btoi(g.TestGroup), timeArg(g.LastSentAt), timeArg(g.RampAt))
+	exec(t, st, `INSERT OR IGNORE INTO group_members (group_id, account_id, updated_at) VALUES (?,?,CURRENT_TIMESTAMP)`, g.ID, g.AccountID)
+}
+
+// SeedTemplate menyimpan template teks aktif dan mengembalikan ID-nya.
+func SeedTemplate(t testing.TB, st *storage.Store, name, text string) string {
+	t.Helper()
+	id, err := st.CreateTemplate(model.Template{Name: name, TextOnly: text, Enabled: true})
+	if err != nil {
+		t.Fatalf("seed template: %v", err)
+	}
+	return id
+}
+
+// SeedLog menyimpan satu log kirim dengan timestamp ts (bukan waktu sekarang).
+func SeedLog(t testing.TB, st *storage.Store, accountID, groupID, status string, ts time.Time) {
+	t.Helper()
+	exec(t, st, `INSERT INTO logs (ts, account_id, group_id, status, message_preview) VALUES (?,?,?,?,?)`,
+		ts.UTC().Format(SQLTime), accountID, groupID, status, fmt.Sprintf("fixture %s", status))
+}
+
+// Ago waktu d sebelum sekarang (jam dinding, sama dengan datetime('now') SQLite).
+func Ago(d time.Duration) *time.Time {
+	t := time.Now().Add(-d)
+	return &t
+}
+
+func timeArg(t *time.Time) any {
+	if t == nil {
+		return nil
+	}
+	return t.UTC().Format(SQLTime)
+}
+
+func btoi(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,58 @@
+package storage
+
+import (
+	"strings"
+	"time"
+
+	"promote/internal/model"
+)
+
+// ListGroupSuppressions mengembalikan grup yang disupresi, terbaru dulu.
+func (s *Store) ListGroupSuppressions() ([]model.GroupSuppression, error) {
+	rows, err := s.DB.Query(`SELECT group_id, COALESCE(reason,''), created_at FROM group_suppressions ORDER BY created_at DESC`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var list []model.GroupSuppression
+	for rows.Next() {
+		var g model.GroupSuppression
+		if err := rows.Scan(&g.GroupID, &g.Reason, &g.CreatedAt); err != nil {
+			return nil, err
+		}
+		list = append(list, g)
+	}
+	return list, rows.Err()
+}
+
+// AddGroupSuppression menambah/memperbarui grup di daftar supresi.
+func (s *Store) AddGroupSuppression(groupID, reason string) error {
+	_, err := s.DB.Exec(`INSERT INTO group_suppressions (group_id, reason, created_at) VALUES (?,?,?)
+		ON CONFLICT(group_id) DO UPDATE SET reason=excluded.reason`, groupID, nullStr(reason), time.Now().UTC())
+	return err
+}
+
+// RemoveGroupSuppression menghapus grup dari daftar supresi; false jika tidak ada.
+func (s *Store) RemoveGroupSuppression(groupID string) (bool, error) {
+	res, err := s.DB.Exec(`DELETE FROM group_suppressions WHERE group_id=?`, groupID)
+	if err != nil {
+		return false, err
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
+
+// IsSuppressed true jika JID grup (…@g.us) atau nomor/JID kontak ada di daftar supresi global.
+func (s *Store) IsSuppressed(target string) (bool, error) {
+	var n int
+	if strings.HasSuffix(target, "@g.us") {
+		err := s.DB.QueryRow(`SELECT COUNT(*) FROM group_suppressions WHERE group_id=?`, target).Scan(&n)
+		return n > 0, err
+	}
+	number := NormalizeNumber(target)
+	if number == "" {
+		return false, nil
+	}
+	err := s.DB.QueryRow(`SELECT COUNT(*) FROM dm_suppressions WHERE number=?`, number).Scan(&n)
+	return n > 0, err
+}
@@ -0,0 +1,297 @@
+package storage
+
+import (
+	"database/sql"
+	"encoding/json"
+	"strings"
+
+	"promote/internal/model"
+	"promote/internal/rng"
+)
+
+// normalizeTags merapikan daftar tag (trim, huruf kecil, tanpa duplikat/kosong).
+func normalizeTags(tags []string) []string {
+	seen := map[string]bool{}
+	out := []string{}
+	for _, t := range tags {
+		t = strings.ToLower(strings.TrimSpace(t))
+		if t == "" || seen[t] {
+			continue
+		}
+		seen[t] = true
+		out = append(out, t)
+	}
+	return out
+}
+
+// SetGroupTags mengganti seluruh tag grup. Tag baru otomatis dibuat.
+func (s *Store) SetGroupTags(groupID string, tags []string) error {
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if _, err := tx.Exec(`DELETE FROM group_tags WHERE group_id=?`, groupID); err != nil {
+		return err
+	}
+	for _, t := range normalizeTags(tags) {
+		if _, err := tx.Exec(`INSERT OR IGNORE INTO tags (name) VALUES (?)`, t); err != nil {
+			return err
+		}
+		if _, err := tx.Exec(`INSERT INTO group_tags (group_id, tag) VALUES (?,?)`, groupID, t); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// SetGroupLanguage menyimpan kode bahasa grup (mis. "id", "en"); kosong menghapus.
+func (s *Store) SetGroupLanguage(groupID, lang string) error {
+	_, err := s.DB.Exec(`UPDATE groups SET language=? WHERE id=?`, nullStr(strings.ToLower(strings.TrimSpace(lang))), groupID)
+	return err
+}
+
+// SetGroupDetectedLanguage menyimpan bahasa grup hasil deteksi otomatis; kosong menghapus.
+func (s *Store) SetGroupDetectedLanguage(groupID, lang string) error {
+	_, err := s.DB.Exec(`UPDATE groups SET language_detected=? WHERE id=?`, nullStr(lang), groupID)
+	return err
+}
+
+// GroupLanguage bahasa efektif grup: language manual, atau hasil deteksi jika kosong.
+func (s *Store) GroupLanguage(groupID string) (string, error) {
+	var lang string
+	err := s.DB.QueryRow(`SELECT COALESCE(language, language_detected, '') FROM groups WHERE id=?`, groupID).Scan(&lang)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	return lang, err
+}
+
+// SetGroupParticipantCount menyimpan jumlah anggota grup terakhir yang diketahui.
+func (s *Store) SetGroupParticipantCount(groupID string, n int) error {
+	_, err := s.DB.Exec(`UPDATE groups SET participant_count=? WHERE id=?`, n, groupID)
+	return err
+}
+
+// TagCount adalah tag beserta jumlah grup yang memakainya.
+type TagCount struct {
+	Name   string `json:"name"`
+	Groups int    `json:"groups"`
+}
+
+// ListTags mengembalikan semua tag dan jumlah grupnya.
+func (s *Store) ListTags() ([]TagCount, error) {
+	rows, err := s.DB.Query(`SELECT t.name, COUNT(gt.group_id) FROM tags t LEFT JOIN group_tags gt ON gt.tag = t.name
+		GROUP BY t.name ORDER BY t.name`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var list []TagCount
+	for rows.Next() {
+		var t TagCount
+		if err := rows.Scan(&t.Name, &t.Groups); err != nil {
+			return nil, err
+		}
+		list = append(list, t)
+	}
+	return list, rows.Err()
+}
+
+// targetingWhere menerjemahkan Targeting menjadi kondisi SQL atas tabel groups (alias g).
+// Mengembalikan "1=1" jika tidak ada kriteria.
+func targetingWhere(t model.Targeting) (string, []any) {
+	conds := []string{}
+	var args []any
+	if tags := normalizeTags(t.IncludeTags); len(tags) > 0 {
+		conds = append(conds, `EXISTS (SELECT 1 FROM group_tags gt WHERE gt.group_id=g.id AND gt.tag IN (?`+strings.Repeat(`,?`, len(tags)-1)+`))`)
+		for _, tag := range tags {
+			args = append(args, tag)
+		}
+	}
+	if tags := normalizeTags(t.ExcludeTags); len(tags) > 0 {
+		conds = append(conds, `NOT EXISTS (SELECT 1 FROM group_tags gt WHERE gt.group_id=g.id AND gt.tag IN (?`+strings.Repeat(`,?`, len(tags)-1)+`))`)
+		for _, tag := range tags {
+			args = append(args, tag)
+		}
+	}
+	if t.MinParticipants > 0 {
+		conds = append(conds, `g.participant_count >= ?`)
+		args = append(args, t.MinParticipants)
+	}
+	if t.RiskBelow != nil {
+		conds = append(conds, `g.risk_score < ?`)
+		args = append(args, *t.RiskBelow)
+	}
+	if t.JoinedBefore != "" {
+		// created_at = pertama kali grup tersinkron (CURRENT_TIMESTAMP, "YYYY-MM-DD HH:MM:SS")
+		conds = append(conds, `g.created_at < ?`)
+		args = append(args, t.JoinedBefore)
+	}
+	if langs := normalizeTags(t.Languages); len(langs) > 0 {
+		// Bahasa manual diutamakan; hasil deteksi otomatis dipakai jika belum diatur.
+		conds = append(conds, `COALESCE(g.language, g.language_detected) IN (?`+strings.Repeat(`,?`, len(langs)-1)+`)`)
+		for _, l := range langs {
+			args = append(args, l)
+		}
+	}
+	if len(conds) == 0 {
+		return "1=1", nil
+	}
+	return strings.Join(conds, " AND "), args
+}
+
+// TargetGroups mengembalikan ID grup aktif (tanpa grup komunitas yang dikecualikan) yang cocok
+// dengan targeting (opsional per akun).
+func (s *Store) TargetGroups(t model.Targeting, accountID string) ([]string, error) {
+	where, args := targetingWhere(t)
+	q := `SELECT g.id FROM groups g WHERE g.enabled=1 AND g.community_excluded=0 AND ` + where
+	if accountID != "" {
+		q += ` AND g.account_id=?`
+		args = append(args, accountID)
+	}
+	rows, err := s.DB.Query(q+` ORDER BY g.name`, args...)
+	if err != nil {
+		return nil, err
+	}
+	return scanIDs(rows)
+}
+
+// scanIDs membaca kolom id tunggal dari rows lalu menutupnya.
+func scanIDs(rows *sql.Rows) ([]string, error) {
+	defer rows.Close()
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// PickTargetedGroup memilih satu grup acak (memakai r) milik akun yang eligible (cooldown, risk)
+// dan cocok targeting, lalu langsung me-reserve-nya (last_sent_at) dalam satu transaksi.
+func (s *Store) PickTargetedGroup(accountID string, cooldownHours, riskThreshold int, t model.Targeting, r *rng.Rand) (string, error) {
+	where, targs := targetingWhere(t)
+	return s.pickTargeted(accountID, cooldownHours, riskThreshold, where, targs, r)
+}
+
+// PickCrossPromoTarget seperti PickTargetedGroup untuk campaign cross-promo: grup yang
+// dipromosikan sendiri dan grup yang sudah pernah menerima promo grup tersebut dilewati.
+func (s *Store) PickCrossPromoTarget(accountID string, cooldownHours, riskThreshold int, promotedGroupID string, t model.Targeting, r *rng.Rand) (string, error) {
+	where, targs := targetingWhere(t)
+	where += ` AND g.id != ? AND g.id NOT IN (SELECT target_group_id FROM cross_promos WHERE promoted_group_id=?)`
+	targs = append(targs, promotedGroupID, promotedGroupID)
+	return s.pickTargeted(accountID, cooldownHours, riskThreshold, where, targs, r)
+}
+
+func (s *Store) pickTargeted(accountID string, cooldownHours, riskThreshold int, where string, targs []any, r *rng.Rand) (string, error) {
+	capWhere, capArgs, err := s.GroupCapFilter("g")
+	if err != nil {
+		return "", err
+	}
+	args := append(eligibleArgs(accountID, cooldownHours, riskThreshold), targs...)
+	args = append(args, capArgs...)
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return "", err
+	}
+	defer tx.Rollback()
+	rows, err := tx.Query(`
+		SELECT g.id FROM groups g
+		WHERE `+eligibleWhere+`
+			AND `+where+` AND `+capWhere+`
+		ORDER BY g.id`, args...)
+	if err != nil {
+		return "", err
+	}
+	ids, err := scanIDs(rows)
+	if err != nil || len(ids) == 0 {
+		return "", err
+	}
+	id := ids[r.Intn(len(ids))]
+	if _, err := tx.Exec(`UPDATE groups SET last_sent_at=CURRENT_TIMESTAMP WHERE id=?`, id); err != nil {
+		return "", err
+	}
+	return id, tx.Commit()
+}
+
+// CampaignTargeting adalah campaign aktif beserta targeting-nya. PromoteGroupID terisi
+// untuk campaign cross-promo.
+type CampaignTargeting struct {
+	ID             string
+	Name           string
+	Targeting      model.Targeting
+	PromoteGroupID string
+}
+
+// GetCampaignTargeting membaca targeting campaign; ok=false jika campaign tidak ada.
+func (s *Store) GetCampaignTargeting(campaignID string) (t model.Targeting, ok bool, err error) {
+	var raw sql.NullString
+	err = s.DB.QueryRow(`SELECT targeting FROM campaigns WHERE id=?`, campaignID).Scan(&raw)
+	if err == sql.ErrNoRows {
+		return t, false, nil
+	}
+	if err != nil {
+		return t, false, err
+	}
+	if raw.String != "" {
+		if err := json.Unmarshal([]byte(raw.String), &t); err != nil {
+			return t, true, err
+		}
+	}
+	return t, true, nil
+}
+
+// CampaignPromoteGroup grup yang dipromosikan campaign cross-promo; kosong untuk campaign lain.
+func (s *Store) CampaignPromoteGroup(campaignID string) (string, error) {
+	var id string
+	err := s.DB.QueryRow(`SELECT COALESCE(promote_group_id,'') FROM campaigns WHERE id=? AND mode=?`,
+		campaignID, model.CampaignModeCrossPromo).Scan(&id)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	return id, err
+}
+
+// SetCampaignTargeting menyimpan targeting campaign (kosong = tanpa filter).
+func (s *Store) SetCampaignTargeting(campaignID string, t model.Targeting) (int64, error) {
+	var raw any
+	if !t.Empty() {
+		b, err := json.Marshal(t)
+		if err != nil {
+			return 0, err
+		}
+		raw = string(b)
+	}
+	res, err := s.DB.Exec(`UPDATE campaigns SET targeting=?, updated_at=CURRENT_TIMESTAMP WHERE id=?`, raw, campaignID)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// ListTargetedCampaigns mengembalikan campaign aktif yang punya targeting.
+func (s *Store) ListTargetedCampaigns() ([]CampaignTargeting, error) {
+	rows, err := s.DB.Query(`SELECT id, name, targeting, COALESCE(promote_group_id,'') FROM campaigns
+		WHERE enabled=1 AND COALESCE(targeting,'') != '' AND NOT (mode=? AND promote_group_id IS NULL)`, model.CampaignModeCrossPromo)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var list []CampaignTargeting
+	for rows.Next() {
+		var c CampaignTargeting
+		var raw string
+		if err := rows.Scan(&c.ID, &c.Name, &raw, &c.PromoteGroupID); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal([]byte(raw), &c.Targeting); err != nil {
+			continue
+		}
+		list = append(list, c)
+	}
+	return list, rows.Err()
+}
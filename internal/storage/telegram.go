@@ -0,0 +1,57 @@
+package storage
+
+import (
+	"encoding/json"
+
+	"promote/internal/model"
+)
+
+// SettingTelegramMirror konfigurasi mirror Telegram (JSON model.TelegramMirror).
+const SettingTelegramMirror = "telegram_mirror"
+
+// TelegramMirror membaca konfigurasi mirror Telegram; nonaktif jika belum diset.
+func (s *Store) TelegramMirror() (model.TelegramMirror, error) {
+	var m model.TelegramMirror
+	v, err := s.GetSetting(SettingTelegramMirror)
+	if err != nil || v == "" {
+		return m, err
+	}
+	err = json.Unmarshal([]byte(v), &m)
+	m.HasToken = m.BotToken != ""
+	return m, err
+}
+
+// redactTelegramMirror nilai setting telegram_mirror tanpa bot token (has_token tetap menandai).
+func redactTelegramMirror(v string) string {
+	var m model.TelegramMirror
+	if json.Unmarshal([]byte(v), &m) != nil {
+		return ""
+	}
+	m.HasToken, m.BotToken = m.BotToken != "", ""
+	b, _ := json.Marshal(m)
+	return string(b)
+}
+
+// keepTelegramToken mengisi bot token kosong pada nilai v dengan token dari nilai tersimpan cur.
+func keepTelegramToken(v, cur string) string {
+	var m, old model.TelegramMirror
+	if json.Unmarshal([]byte(v), &m) != nil || m.BotToken != "" {
+		return v
+	}
+	if json.Unmarshal([]byte(cur), &old) != nil || old.BotToken == "" {
+		return v
+	}
+	m.BotToken, m.HasToken = old.BotToken, false
+	b, _ := json.Marshal(m)
+	return string(b)
+}
+
+// SetTelegramMirror menyimpan konfigurasi mirror Telegram.
+func (s *Store) SetTelegramMirror(m model.TelegramMirror) error {
+	m.HasToken = false
+	b, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+	return s.SetSetting(SettingTelegramMirror, string(b))
+}
@@ -0,0 +1,329 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+
+	"github.com/google/uuid"
+
+	"promote/internal/model"
+	"promote/internal/rng"
+)
+
+// ErrTemplateNotFound dikembalikan jika template tidak ada (atau tidak ada template aktif).
+var ErrTemplateNotFound = errors.New("template not found")
+
+const templateCols = `id, name, COALESCE(text_only,''),
+	COALESCE(images_json,''), COALESCE(images_caption,''),
+	COALESCE(videos_json,''), COALESCE(videos_caption,''), COALESCE(products_json,''),
+	COALESCE(gifs_json,''), COALESCE(audio_json,''), COALESCE(voice_json,''), COALESCE(stickers_json,''),
+	COALESCE(docs_json,''), COALESCE(docs_caption,''),
+	COALESCE(link_preview,0), COALESCE(contact_name,''), COALESCE(contact_phone,''),
+	COALESCE(media_fail_policy,'abort'), COALESCE(fallback_image_url,''), COALESCE(mention_all,0),
+	COALESCE(health_error,''), COALESCE(lint_json,''), COALESCE(languages,''), COALESCE(campaign_id,''), version, enabled, created_at, updated_at`
+
+func scanTemplate(sc interface{ Scan(...any) error }) (model.Template, error) {
+	var t model.Template
+	var imgs, vids, products, gifs, audio, voice, stickers, docs, lint, langs string
+	var linkPreview, mentionAll, enabled int
+	if err := sc.Scan(&t.ID, &t.Name, &t.TextOnly, &imgs, &t.ImageCaption, &vids, &t.VideoCaption, &products, &gifs, &audio, &voice, &stickers,
+		&docs, &t.DocCaption, &linkPreview, &t.ContactName, &t.ContactPhone,
+		&t.MediaFailPolicy, &t.FallbackImageURL, &mentionAll, &t.HealthError, &lint, &langs, &t.CampaignID, &t.Version, &enabled,
+		&t.CreatedAt, &t.UpdatedAt); err != nil {
+		return t, err
+	}
+	t.ImageURLs = jsonList(imgs)
+	t.VideoURLs = jsonList(vids)
+	t.Products = productList(products)
+	t.GifURLs = jsonList(gifs)
+	t.AudioURLs = jsonList(audio)
+	t.VoiceURLs = jsonList(voice)
+	t.StickerURLs = jsonList(stickers)
+	t.DocURLs = jsonList(docs)
+	t.LinkPreview = linkPreview == 1
+	t.MentionAll = mentionAll == 1
+	t.Lint = lintReport(lint)
+	t.Languages = jsonList(langs)
+	t.Enabled = enabled == 1
+	return t, nil
+}
+
+// ListTemplates mengembalikan semua template, terbaru dulu.
+func (s *Store) ListTemplates() ([]model.Template, error) {
+	rows, err := s.DB.Query(`SELECT ` + templateCols + ` FROM templates ORDER BY created_at DESC`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	list := []model.Template{}
+	for rows.Next() {
+		t, err := scanTemplate(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, t)
+	}
+	return list, rows.Err()
+}
+
+// GetTemplate mengambil satu template; ErrTemplateNotFound jika tidak ada.
+func (s *Store) GetTemplate(id string) (model.Template, error) {
+	t, err := scanTemplate(s.DB.QueryRow(`SELECT `+templateCols+` FROM templates WHERE id=?`, id))
+	if err == sql.ErrNoRows {
+		return t, ErrTemplateNotFound
+	}
+	return t, err
+}
+
+// RandomTemplate memilih satu template aktif secara acak memakai r. Kandidat diurutkan (waktu dibuat,
+// nama) agar seed yang sama memberi pilihan yang sama; ErrTemplateNotFound jika tidak ada template aktif.
+// lang (bahasa grup tujuan) membatasi kandidat ke template tanpa batasan bahasa atau yang memuat lang;
+// kosong = semua template.
+func (s *Store) RandomTemplate(ctx context.Context, lang string, r *rng.Rand) (model.Template, error) {
+	rows, err := s.DB.QueryContext(ctx, `SELECT id FROM templates WHERE enabled=1
+		AND (?='' OR COALESCE(languages,'')='' OR EXISTS (SELECT 1 FROM json_each(templates.languages) WHERE value=?))
+		ORDER BY created_at, name, id`, lang, lang)
+	if err != nil {
+		return model.Template{}, err
+	}
+	ids, err := scanIDs(rows)
+	if err != nil {
+		return model.Template{}, err
+	}
+	if len(ids) == 0 {
+		return model.Template{}, ErrTemplateNotFound
+	}
+	t, err := scanTemplate(s.DB.QueryRowContext(ctx, `SELECT `+templateCols+` FROM templates WHERE id=?`, ids[r.Intn(len(ids))]))
+	if err == sql.ErrNoRows {
+		return t, ErrTemplateNotFound
+	}
+	return t, err
+}
+
+// CountActiveTemplates jumlah template yang aktif.
+func (s *Store) CountActiveTemplates() (int, error) {
+	var n int
+	err := s.DB.QueryRow(`SELECT COUNT(*) FROM templates WHERE enabled=1`).Scan(&n)
+	return n, err
+}
+
+// CreateTemplate menyimpan template baru (versi 1) dan mengembalikan ID-nya.
+func (s *Store) CreateTemplate(t model.Template) (string, error) {
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return "", err
+	}
+	defer tx.Rollback()
+	id := uuid.NewString()
+	if err := insertTemplate(tx, id, t); err != nil {
+		return "", err
+	}
+	return id, tx.Commit()
+}
+
+// insertTemplate menyisipkan template id beserta snapshot versi 1 di tx.
+func insertTemplate(tx *sql.Tx, id string, t model.Template) error {
+	_, err := tx.Exec(`INSERT INTO templates (id,name,text_only,images_json,images_caption,videos_json,videos_caption,products_json,gifs_json,audio_json,voice_json,stickers_json,docs_json,docs_caption,link_preview,contact_name,contact_phone,media_fail_policy,fallback_image_url,mention_all,lint_json,languages,enabled,created_at,updated_at)
+		VALUES (?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)`,
+		id, t.Name, t.TextOnly,
+		jsonListArg(t.ImageURLs), t.ImageCaption,
+		jsonListArg(t.VideoURLs), t.VideoCaption, productListArg(t.Products), jsonListArg(t.GifURLs),
+		jsonListArg(t.AudioURLs), jsonListArg(t.VoiceURLs), jsonListArg(t.StickerURLs),
+		jsonListArg(t.DocURLs), t.DocCaption,
+		btoi(t.LinkPreview), nullStr(t.ContactName), nullStr(t.ContactPhone),
+		nullStr(t.MediaFailPolicy), nullStr(t.FallbackImageURL), btoi(t.MentionAll), lintArg(t.Lint),
+		jsonListArg(normalizeTags(t.Languages)), btoi(t.Enabled))
+	if err != nil {
+		return err
+	}
+	return snapshotTemplate(tx, id)
+}
+
+// UpdateTemplate mengganti seluruh isi template sebagai versi baru.
+func (s *Store) UpdateTemplate(t model.Template) error {
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if err := updateTemplate(tx, t); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// updateTemplate mengganti isi template t.ID di tx dan menyimpan snapshot versi barunya.
+func updateTemplate(tx *sql.Tx, t model.Template) error {
+	res, err := tx.Exec(`UPDATE templates
+		SET name=?, text_only=?, images_json=?, images_caption=?, videos_json=?, videos_caption=?, products_json=?, gifs_json=?, audio_json=?, voice_json=?, stickers_json=?, docs_json=?, docs_caption=?, link_preview=?, contact_name=?, contact_phone=?, media_fail_policy=?, fallback_image_url=?, mention_all=?, lint_json=?, languages=?, enabled=?, version=version+1, updated_at=CURRENT_TIMESTAMP
+		WHERE id=?`,
+		t.Name, t.TextOnly,
+		jsonListArg(t.ImageURLs), t.ImageCaption,
+		jsonListArg(t.VideoURLs), t.VideoCaption, productListArg(t.Products), jsonListArg(t.GifURLs),
+		jsonListArg(t.AudioURLs), jsonListArg(t.VoiceURLs), jsonListArg(t.StickerURLs),
+		jsonListArg(t.DocURLs), t.DocCaption,
+		btoi(t.LinkPreview), nullStr(t.ContactName), nullStr(t.ContactPhone),
+		nullStr(t.MediaFailPolicy), nullStr(t.FallbackImageURL), btoi(t.MentionAll), lintArg(t.Lint),
+		jsonListArg(normalizeTags(t.Languages)), btoi(t.Enabled), t.ID)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrTemplateNotFound
+	}
+	return snapshotTemplate(tx, t.ID)
+}
+
+// SetTemplateEnabled mengaktifkan/menonaktifkan template.
+func (s *Store) SetTemplateEnabled(id string, enabled bool) error {
+	res, err := s.DB.Exec(`UPDATE templates SET enabled=?, updated_at=CURRENT_TIMESTAMP WHERE id=?`, btoi(enabled), id)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrTemplateNotFound
+	}
+	return nil
+}
+
+// DeleteTemplate menghapus template.
+func (s *Store) DeleteTemplate(id string) error {
+	res, err := s.DB.Exec(`DELETE FROM templates WHERE id=?`, id)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrTemplateNotFound
+	}
+	return nil
+}
+
+// TemplateMediaMatch template yang memakai URL media tertentu beserta jenis medianya
+// (image, video, audio, sticker, document).
+type TemplateMediaMatch struct {
+	ID    string   `json:"id"`
+	Name  string   `json:"name"`
+	Kinds []string `json:"kinds"`
+}
+
+// ReplaceTemplateMedia mengganti oldURL dengan newURL di semua daftar media template.
+// Dengan apply=false hanya mengembalikan template yang akan terpengaruh (dry run).
+func (s *Store) ReplaceTemplateMedia(oldURL, newURL string, apply bool) ([]TemplateMediaMatch, error) {
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	rows, err := tx.Query(`SELECT ` + templateCols + ` FROM templates ORDER BY created_at DESC`)
+	if err != nil {
+		return nil, err
+	}
+	var list []model.Template
+	for rows.Next() {
+		t, err := scanTemplate(rows)
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+		list = append(list, t)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	matches := []TemplateMediaMatch{}
+	for _, t := range list {
+		m := TemplateMediaMatch{ID: t.ID, Name: t.Name}
+		for _, f := range []struct {
+			kind string
+			urls []string
+		}{
+			{"image", t.ImageURLs}, {"video", t.VideoURLs}, {"gif", t.GifURLs}, {"audio", t.AudioURLs},
+			{"voice_note", t.VoiceURLs}, {"sticker", t.StickerURLs}, {"document", t.DocURLs},
+		} {
+			hit := false
+			for i, u := range f.urls {
+				if u == oldURL {
+					f.urls[i] = newURL
+					hit = true
+				}
+			}
+			if hit {
+				m.Kinds = append(m.Kinds, f.kind)
+			}
+		}
+		if len(m.Kinds) == 0 {
+			continue
+		}
+		matches = append(matches, m)
+		if !apply {
+			continue
+		}
+		if _, err := tx.Exec(`UPDATE templates SET images_json=?, videos_json=?, gifs_json=?, audio_json=?, voice_json=?, stickers_json=?, docs_json=?,
+			health_error=NULL, version=version+1, updated_at=CURRENT_TIMESTAMP WHERE id=?`,
+			jsonListArg(t.ImageURLs), jsonListArg(t.VideoURLs), jsonListArg(t.GifURLs), jsonListArg(t.AudioURLs), jsonListArg(t.VoiceURLs),
+			jsonListArg(t.StickerURLs), jsonListArg(t.DocURLs), t.ID); err != nil {
+			return nil, err
+		}
+		if err := snapshotTemplate(tx, t.ID); err != nil {
+			return nil, err
+		}
+	}
+	if !apply {
+		return matches, nil
+	}
+	return matches, tx.Commit()
+}
+
+func productList(s string) []model.ProductItem {
+	out := []model.ProductItem{}
+	if s != "" {
+		_ = json.Unmarshal([]byte(s), &out)
+	}
+	if out == nil {
+		out = []model.ProductItem{}
+	}
+	return out
+}
+
+func productListArg(list []model.ProductItem) any {
+	if len(list) == 0 {
+		return nil
+	}
+	b, _ := json.Marshal(list)
+	return string(b)
+}
+
+// SetTemplateLint menyimpan hasil lint template; ErrTemplateNotFound jika tidak ada.
+func (s *Store) SetTemplateLint(id string, rep model.LintReport) error {
+	res, err := s.DB.Exec(`UPDATE templates SET lint_json=? WHERE id=?`, lintArg(&rep), id)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrTemplateNotFound
+	}
+	return nil
+}
+
+func lintReport(s string) *model.LintReport {
+	if s == "" {
+		return nil
+	}
+	var rep model.LintReport
+	if json.Unmarshal([]byte(s), &rep) != nil {
+		return nil
+	}
+	return &rep
+}
+
+func lintArg(rep *model.LintReport) any {
+	if rep == nil {
+		return nil
+	}
+	b, _ := json.Marshal(rep)
+	return string(b)
+}
@@ -0,0 +1,95 @@
+package storage
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+
+	"promote/internal/model"
+)
+
+// ErrTemplateVersionNotFound dikembalikan jika snapshot versi template tidak ada.
+var ErrTemplateVersionNotFound = errors.New("template version not found")
+
+// snapshotTemplate menyimpan isi template saat ini sebagai snapshot versinya (sekali per versi).
+func snapshotTemplate(tx *sql.Tx, id string) error {
+	t, err := scanTemplate(tx.QueryRow(`SELECT `+templateCols+` FROM templates WHERE id=?`, id))
+	if err != nil {
+		return err
+	}
+	b, err := json.Marshal(t)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(`INSERT OR IGNORE INTO template_versions (template_id, version, content_json) VALUES (?,?,?)`,
+		t.ID, t.Version, string(b))
+	return err
+}
+
+// backfillTemplateVersions membuat snapshot untuk template yang belum punya versi sama sekali.
+func backfillTemplateVersions(tx *sql.Tx) error {
+	rows, err := tx.Query(`SELECT id FROM templates t
+		WHERE NOT EXISTS (SELECT 1 FROM template_versions v WHERE v.template_id=t.id)`)
+	if err != nil {
+		return err
+	}
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return err
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	for _, id := range ids {
+		if err := snapshotTemplate(tx, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func scanTemplateVersion(sc interface{ Scan(...any) error }) (model.TemplateVersion, error) {
+	var v model.TemplateVersion
+	var content string
+	if err := sc.Scan(&v.TemplateID, &v.Version, &content, &v.CreatedAt); err != nil {
+		return v, err
+	}
+	err := json.Unmarshal([]byte(content), &v.Content)
+	return v, err
+}
+
+// ListTemplateVersions mengembalikan semua snapshot template, versi terbaru dulu. Snapshot
+// tetap ada setelah template dihapus.
+func (s *Store) ListTemplateVersions(templateID string) ([]model.TemplateVersion, error) {
+	rows, err := s.DB.Query(`SELECT template_id, version, content_json, created_at FROM template_versions
+		WHERE template_id=? ORDER BY version DESC`, templateID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var list []model.TemplateVersion
+	for rows.Next() {
+		v, err := scanTemplateVersion(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, v)
+	}
+	return list, rows.Err()
+}
+
+// GetTemplateVersion mengambil satu snapshot; ErrTemplateVersionNotFound jika tidak ada.
+func (s *Store) GetTemplateVersion(templateID string, version int) (model.TemplateVersion, error) {
+	v, err := scanTemplateVersion(s.DB.QueryRow(`SELECT template_id, version, content_json, created_at FROM template_versions
+		WHERE template_id=? AND version=?`, templateID, version))
+	if err == sql.ErrNoRows {
+		return v, ErrTemplateVersionNotFound
+	}
+	return v, err
+}
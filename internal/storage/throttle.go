@@ -0,0 +1,37 @@
+package storage
+
+import (
+	"database/sql"
+	"time"
+)
+
+// ThrottleAccount menghentikan akun sampai until karena WhatsApp menolak kiriman (rate-limit/spam).
+func (s *Store) ThrottleAccount(accountID string, until time.Time, reason string) error {
+	_, err := s.DB.Exec(`UPDATE accounts SET throttled_until=?, throttle_reason=? WHERE id=?`,
+		until.UTC().Format(ctsLayout), nullStr(reason), accountID)
+	return err
+}
+
+// AccountThrottledUntil akhir cooldown throttle akun; nil jika akun tidak sedang di-throttle.
+func (s *Store) AccountThrottledUntil(accountID string) (*time.Time, error) {
+	var until sql.NullTime
+	err := s.DB.QueryRow(`SELECT throttled_until FROM accounts WHERE id=?`, accountID).Scan(&until)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil || !until.Valid || !until.Time.After(time.Now()) {
+		return nil, err
+	}
+	t := until.Time
+	return &t, nil
+}
+
+// ClearAccountThrottle mengakhiri cooldown throttle akun lebih awal (manual).
+func (s *Store) ClearAccountThrottle(accountID string) (bool, error) {
+	res, err := s.DB.Exec(`UPDATE accounts SET throttled_until=NULL, throttle_reason=NULL WHERE id=?`, accountID)
+	if err != nil {
+		return false, err
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
@@ -0,0 +1,222 @@
+package storage
+
+import (
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+
+	"promote/internal/model"
+)
+
+var (
+	ErrUserNotFound       = errors.New("user not found")
+	ErrUserExists         = errors.New("username already exists")
+	ErrInvalidCredentials = errors.New("invalid username or password")
+	ErrLastAdmin          = errors.New("cannot remove the last active admin")
+	ErrSessionNotFound    = errors.New("session not found or expired")
+)
+
+const userCols = `id, username, role, disabled, created_at, last_login_at`
+
+func scanUser(sc interface{ Scan(...any) error }) (model.User, error) {
+	var u model.User
+	var disabled int
+	var lastLogin sql.NullTime
+	err := sc.Scan(&u.ID, &u.Username, &u.Role, &disabled, &u.CreatedAt, &lastLogin)
+	u.Disabled = disabled == 1
+	u.LastLoginAt = nullTimePtr(lastLogin)
+	return u, err
+}
+
+// CountUsers menghitung semua pengguna.
+func (s *Store) CountUsers() (int, error) {
+	var n int
+	err := s.DB.QueryRow(`SELECT COUNT(*) FROM users`).Scan(&n)
+	return n, err
+}
+
+// ListUsers mengembalikan semua pengguna urut username.
+func (s *Store) ListUsers() ([]model.User, error) {
+	rows, err := s.DB.Query(`SELECT ` + userCols + ` FROM users ORDER BY username`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var list []model.User
+	for rows.Next() {
+		u, err := scanUser(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, u)
+	}
+	return list, rows.Err()
+}
+
+// GetUser mengambil pengguna; ErrUserNotFound jika tidak ada.
+func (s *Store) GetUser(id string) (model.User, error) {
+	u, err := scanUser(s.DB.QueryRow(`SELECT `+userCols+` FROM users WHERE id=?`, id))
+	if err == sql.ErrNoRows {
+		return u, ErrUserNotFound
+	}
+	return u, err
+}
+
+// CreateUser membuat pengguna baru; password disimpan sebagai hash bcrypt.
+func (s *Store) CreateUser(username, password, role string) (model.User, error) {
+	username = strings.ToLower(strings.TrimSpace(username))
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return model.User{}, err
+	}
+	var exists int
+	if err := s.DB.QueryRow(`SELECT COUNT(*) FROM users WHERE username=?`, username).Scan(&exists); err != nil {
+		return model.User{}, err
+	}
+	if exists > 0 {
+		return model.User{}, ErrUserExists
+	}
+	u := model.User{ID: uuid.NewString(), Username: username, Role: role, CreatedAt: time.Now().UTC()}
+	_, err = s.DB.Exec(`INSERT INTO users (id, username, password_hash, role, created_at) VALUES (?,?,?,?,?)`,
+		u.ID, u.Username, string(hash), u.Role, u.CreatedAt)
+	return u, err
+}
+
+// UserUpdate berisi perubahan parsial pengguna; field nil tidak diubah.
+type UserUpdate struct {
+	Role     *string
+	Password *string
+	Disabled *bool
+}
+
+// UpdateUser menerapkan perubahan; menolak menurunkan/menonaktifkan admin aktif terakhir.
+// Mengganti password atau menonaktifkan pengguna mencabut semua sesinya.
+func (s *Store) UpdateUser(id string, up UserUpdate) error {
+	u, err := s.GetUser(id)
+	if err != nil {
+		return err
+	}
+	demote := (up.Role != nil && *up.Role != model.RoleAdmin) || (up.Disabled != nil && *up.Disabled)
+	if u.Role == model.RoleAdmin && !u.Disabled && demote {
+		if err := s.ensureOtherAdmin(id); err != nil {
+			return err
+		}
+	}
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if up.Role != nil {
+		if _, err := tx.Exec(`UPDATE users SET role=? WHERE id=?`, *up.Role, id); err != nil {
+			return err
+		}
+	}
+	if up.Password != nil {
+		hash, err := bcrypt.GenerateFromPassword([]byte(*up.Password), bcrypt.DefaultCost)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec(`UPDATE users SET password_hash=? WHERE id=?`, string(hash), id); err != nil {
+			return err
+		}
+	}
+	if up.Disabled != nil {
+		if _, err := tx.Exec(`UPDATE users SET disabled=? WHERE id=?`, btoi(*up.Disabled), id); err != nil {
+			return err
+		}
+	}
+	if up.Password != nil || (up.Disabled != nil && *up.Disabled) {
+		if _, err := tx.Exec(`DELETE FROM user_sessions WHERE user_id=?`, id); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// DeleteUser menghapus pengguna beserta sesinya; admin aktif terakhir tidak bisa dihapus.
+func (s *Store) DeleteUser(id string) error {
+	u, err := s.GetUser(id)
+	if err != nil {
+		return err
+	}
+	if u.Role == model.RoleAdmin && !u.Disabled {
+		if err := s.ensureOtherAdmin(id); err != nil {
+			return err
+		}
+	}
+	_, err = s.DB.Exec(`DELETE FROM users WHERE id=?`, id)
+	return err
+}
+
+func (s *Store) ensureOtherAdmin(exceptID string) error {
+	var n int
+	if err := s.DB.QueryRow(`SELECT COUNT(*) FROM users WHERE role=? AND disabled=0 AND id<>?`,
+		model.RoleAdmin, exceptID).Scan(&n); err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrLastAdmin
+	}
+	return nil
+}
+
+// Login memverifikasi kredensial dan membuat sesi baru; token mentah dikembalikan sekali.
+func (s *Store) Login(username, password string, ttl time.Duration) (model.User, string, time.Time, error) {
+	username = strings.ToLower(strings.TrimSpace(username))
+	var id, hash string
+	var disabled int
+	err := s.DB.QueryRow(`SELECT id, password_hash, disabled FROM users WHERE username=?`, username).Scan(&id, &hash, &disabled)
+	if err == sql.ErrNoRows {
+		return model.User{}, "", time.Time{}, ErrInvalidCredentials
+	}
+	if err != nil {
+		return model.User{}, "", time.Time{}, err
+	}
+	if disabled == 1 || bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) != nil {
+		return model.User{}, "", time.Time{}, ErrInvalidCredentials
+	}
+	token, err := randomToken("ps_")
+	if err != nil {
+		return model.User{}, "", time.Time{}, err
+	}
+	now := time.Now().UTC()
+	expires := now.Add(ttl)
+	if _, err := s.DB.Exec(`INSERT INTO user_sessions (token_hash, user_id, created_at, expires_at) VALUES (?,?,?,?)`,
+		HashAPIKey(token), id, now, expires); err != nil {
+		return model.User{}, "", time.Time{}, err
+	}
+	_, _ = s.DB.Exec(`UPDATE users SET last_login_at=? WHERE id=?`, now, id)
+	u, err := s.GetUser(id)
+	return u, token, expires, err
+}
+
+// SessionUser mengembalikan pengguna aktif pemilik token sesi yang belum kedaluwarsa.
+func (s *Store) SessionUser(token string) (model.User, error) {
+	u, err := scanUser(s.DB.QueryRow(`SELECT u.id, u.username, u.role, u.disabled, u.created_at, u.last_login_at
+		FROM user_sessions t JOIN users u ON u.id=t.user_id
+		WHERE t.token_hash=? AND t.expires_at > ? AND u.disabled=0`, HashAPIKey(token), time.Now().UTC()))
+	if err == sql.ErrNoRows {
+		return u, ErrSessionNotFound
+	}
+	return u, err
+}
+
+// Logout menghapus sesi.
+func (s *Store) Logout(token string) error {
+	_, err := s.DB.Exec(`DELETE FROM user_sessions WHERE token_hash=?`, HashAPIKey(token))
+	return err
+}
+
+// PruneSessions menghapus sesi yang sudah kedaluwarsa.
+func (s *Store) PruneSessions() (int64, error) {
+	res, err := s.DB.Exec(`DELETE FROM user_sessions WHERE expires_at <= ?`, time.Now().UTC())
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
@@ -0,0 +1,127 @@
+package storage
+
+import (
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"promote/internal/model"
+)
+
+// SettingWarmup profil warmup default untuk akun tanpa profil khusus (JSON model.WarmupProfile).
+const SettingWarmup = "account_warmup"
+
+// WarmupDefault membaca profil warmup default; DefaultWarmupProfile (nonaktif) jika belum diset.
+func (s *Store) WarmupDefault() (model.WarmupProfile, error) {
+	p := model.DefaultWarmupProfile()
+	v, err := s.GetSetting(SettingWarmup)
+	if err != nil || v == "" {
+		return p, err
+	}
+	if err := json.Unmarshal([]byte(v), &p); err != nil || p.Validate() != nil {
+		return model.DefaultWarmupProfile(), err
+	}
+	return p, nil
+}
+
+// SetWarmupDefault menyimpan profil warmup default.
+func (s *Store) SetWarmupDefault(p model.WarmupProfile) error {
+	raw, err := json.Marshal(p)
+	if err != nil {
+		return err
+	}
+	return s.SetSetting(SettingWarmup, string(raw))
+}
+
+// AccountWarmups profil warmup khusus per akun (menggantikan profil default untuk akun itu).
+func (s *Store) AccountWarmups() (map[string]model.WarmupProfile, error) {
+	rows, err := s.DB.Query(`SELECT account_id, profile FROM account_warmup`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := map[string]model.WarmupProfile{}
+	for rows.Next() {
+		var id, raw string
+		if err := rows.Scan(&id, &raw); err != nil {
+			return nil, err
+		}
+		var p model.WarmupProfile
+		if err := json.Unmarshal([]byte(raw), &p); err != nil {
+			return nil, err
+		}
+		out[id] = p
+	}
+	return out, rows.Err()
+}
+
+// AccountWarmup profil warmup khusus akun; ok=false jika akun memakai profil default.
+func (s *Store) AccountWarmup(accountID string) (model.WarmupProfile, bool, error) {
+	var p model.WarmupProfile
+	var raw string
+	err := s.DB.QueryRow(`SELECT profile FROM account_warmup WHERE account_id=?`, accountID).Scan(&raw)
+	if err == sql.ErrNoRows {
+		return p, false, nil
+	}
+	if err != nil {
+		return p, false, err
+	}
+	err = json.Unmarshal([]byte(raw), &p)
+	return p, err == nil, err
+}
+
+// SetAccountWarmup menyimpan profil warmup khusus akun ({"enabled":false} = akun tanpa warmup).
+func (s *Store) SetAccountWarmup(accountID string, p model.WarmupProfile) error {
+	raw, err := json.Marshal(p)
+	if err != nil {
+		return err
+	}
+	_, err = s.DB.Exec(`INSERT INTO account_warmup (account_id, profile, updated_at) VALUES (?,?,CURRENT_TIMESTAMP)
+		ON CONFLICT(account_id) DO UPDATE SET profile=excluded.profile, updated_at=excluded.updated_at`, accountID, string(raw))
+	return err
+}
+
+// DeleteAccountWarmup mengembalikan akun ke profil default; false jika akun tidak punya profil
+// khusus.
+func (s *Store) DeleteAccountWarmup(accountID string) (bool, error) {
+	res, err := s.DB.Exec(`DELETE FROM account_warmup WHERE account_id=?`, accountID)
+	if err != nil {
+		return false, err
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
+
+// MarkAccountPaired mencatat waktu akun pertama kali terhubung (awal warmup); tidak berubah pada
+// koneksi berikutnya.
+func (s *Store) MarkAccountPaired(accountID string) error {
+	_, err := s.DB.Exec(`UPDATE accounts SET first_paired_at=? WHERE id=? AND first_paired_at IS NULL`,
+		time.Now().UTC(), accountID)
+	return err
+}
+
+// RestartAccountWarmup memulai ulang warmup akun dari sekarang (mis. akun dipasangkan ke nomor
+// baru); false jika akun tidak ada.
+func (s *Store) RestartAccountWarmup(accountID string) (bool, error) {
+	res, err := s.DB.Exec(`UPDATE accounts SET first_paired_at=? WHERE id=?`, time.Now().UTC(), accountID)
+	if err != nil {
+		return false, err
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
+
+// AccountPairedSince awal warmup akun: first_paired_at, atau created_at jika belum pernah
+// terhubung; juga daily_limit akun.
+func (s *Store) AccountPairedSince(accountID string) (since time.Time, dailyLimit int, err error) {
+	var paired sql.NullTime
+	err = s.DB.QueryRow(`SELECT first_paired_at, created_at, daily_limit FROM accounts WHERE id=?`, accountID).
+		Scan(&paired, &since, &dailyLimit)
+	if err == sql.ErrNoRows {
+		return since, 0, ErrAccountNotFound
+	}
+	if paired.Valid {
+		since = paired.Time
+	}
+	return since, dailyLimit, err
+}
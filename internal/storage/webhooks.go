@@ -0,0 +1,192 @@
+package storage
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+
+	"promote/internal/model"
+)
+
+// ErrWebhookNotFound dikembalikan jika webhook tidak ada.
+var ErrWebhookNotFound = errors.New("webhook not found")
+
+const webhookCols = `id, name, url, COALESCE(events,''), COALESCE(secret,''), enabled, created_at`
+
+func scanWebhook(sc interface{ Scan(...any) error }) (model.Webhook, error) {
+	var w model.Webhook
+	var events string
+	var enabled int
+	err := sc.Scan(&w.ID, &w.Name, &w.URL, &events, &w.Secret, &enabled, &w.CreatedAt)
+	w.Events = jsonList(events)
+	w.HasSecret = w.Secret != ""
+	w.Enabled = enabled == 1
+	return w, err
+}
+
+// ListWebhooks mengembalikan semua webhook urut waktu dibuat.
+func (s *Store) ListWebhooks() ([]model.Webhook, error) {
+	rows, err := s.DB.Query(`SELECT ` + webhookCols + ` FROM webhooks ORDER BY created_at`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var list []model.Webhook
+	for rows.Next() {
+		w, err := scanWebhook(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, w)
+	}
+	return list, rows.Err()
+}
+
+// GetWebhook mengambil satu webhook; ErrWebhookNotFound jika tidak ada.
+func (s *Store) GetWebhook(id string) (model.Webhook, error) {
+	w, err := scanWebhook(s.DB.QueryRow(`SELECT `+webhookCols+` FROM webhooks WHERE id=?`, id))
+	if err == sql.ErrNoRows {
+		return w, ErrWebhookNotFound
+	}
+	return w, err
+}
+
+// CreateWebhook menyimpan webhook baru dan mengembalikan ID-nya.
+func (s *Store) CreateWebhook(w model.Webhook) (string, error) {
+	id := uuid.NewString()
+	_, err := s.DB.Exec(`INSERT INTO webhooks (id, name, url, events, secret, enabled) VALUES (?,?,?,?,?,?)`,
+		id, w.Name, w.URL, jsonListArg(w.Events), nullStr(w.Secret), btoi(w.Enabled))
+	if err != nil {
+		return "", err
+	}
+	return id, nil
+}
+
+// UpdateWebhook mengganti nama, URL, event, dan status aktif; secret hanya diganti jika setSecret.
+func (s *Store) UpdateWebhook(w model.Webhook, setSecret bool) error {
+	res, err := s.DB.Exec(`UPDATE webhooks SET name=?, url=?, events=?, enabled=?,
+		secret=CASE WHEN ? THEN ? ELSE secret END WHERE id=?`,
+		w.Name, w.URL, jsonListArg(w.Events), btoi(w.Enabled), btoi(setSecret), nullStr(w.Secret), w.ID)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrWebhookNotFound
+	}
+	return nil
+}
+
+// DeleteWebhook menghapus webhook beserta log pengirimannya.
+func (s *Store) DeleteWebhook(id string) error {
+	res, err := s.DB.Exec(`DELETE FROM webhooks WHERE id=?`, id)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrWebhookNotFound
+	}
+	return nil
+}
+
+const webhookDeliveryCols = `id, webhook_id, event, payload, status, attempts, COALESCE(response_code,0),
+	COALESCE(last_error,''), next_attempt_at, created_at, delivered_at`
+
+func scanWebhookDelivery(sc interface{ Scan(...any) error }) (model.WebhookDelivery, error) {
+	var d model.WebhookDelivery
+	var payload string
+	var delivered sql.NullTime
+	err := sc.Scan(&d.ID, &d.WebhookID, &d.Event, &payload, &d.Status, &d.Attempts, &d.ResponseCode,
+		&d.LastError, &d.NextAttemptAt, &d.CreatedAt, &delivered)
+	d.Payload = []byte(payload)
+	d.DeliveredAt = nullTimePtr(delivered)
+	return d, err
+}
+
+func (s *Store) queryWebhookDeliveries(q string, args ...any) ([]model.WebhookDelivery, error) {
+	rows, err := s.DB.Query(q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var list []model.WebhookDelivery
+	for rows.Next() {
+		d, err := scanWebhookDelivery(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, d)
+	}
+	return list, rows.Err()
+}
+
+// EnqueueWebhookDelivery mencatat event untuk dikirim secepatnya ke webhook.
+func (s *Store) EnqueueWebhookDelivery(webhookID, event string, payload []byte) (int64, error) {
+	res, err := s.DB.Exec(`INSERT INTO webhook_deliveries (webhook_id, event, payload, status, next_attempt_at) VALUES (?,?,?,?,?)`,
+		webhookID, event, string(payload), model.DeliveryPending, time.Now().UTC())
+	if err != nil {
+		return 0, err
+	}
+	return res.LastInsertId()
+}
+
+// DueWebhookDeliveries mengembalikan pengiriman pending yang jadwal retry-nya sudah lewat.
+func (s *Store) DueWebhookDeliveries(now time.Time, limit int) ([]model.WebhookDelivery, error) {
+	return s.queryWebhookDeliveries(`SELECT `+webhookDeliveryCols+` FROM webhook_deliveries
+		WHERE status=? AND next_attempt_at <= ? ORDER BY next_attempt_at, id LIMIT ?`,
+		model.DeliveryPending, now.UTC(), limit)
+}
+
+// ListWebhookDeliveries mengembalikan log pengiriman terbaru, opsional difilter webhook dan status.
+func (s *Store) ListWebhookDeliveries(webhookID, status string, limit int) ([]model.WebhookDelivery, error) {
+	if limit <= 0 || limit > 500 {
+		limit = 100
+	}
+	return s.queryWebhookDeliveries(`SELECT `+webhookDeliveryCols+` FROM webhook_deliveries
+		WHERE (?='' OR webhook_id=?) AND (?='' OR status=?)
+		ORDER BY id DESC LIMIT ?`, webhookID, webhookID, status, status, limit)
+}
+
+// MarkWebhookDelivered menandai pengiriman sukses.
+func (s *Store) MarkWebhookDelivered(id int64, code int) error {
+	_, err := s.DB.Exec(`UPDATE webhook_deliveries SET status=?, attempts=attempts+1, response_code=?, last_error=NULL,
+		delivered_at=? WHERE id=?`, model.DeliveryDelivered, code, time.Now().UTC(), id)
+	return err
+}
+
+// MarkWebhookAttemptFailed mencatat percobaan gagal. next nil = menyerah (status failed),
+// selain itu dijadwalkan ulang pada next.
+func (s *Store) MarkWebhookAttemptFailed(id int64, code int, errMsg string, next *time.Time) error {
+	status := model.DeliveryFailed
+	nextAt := time.Now().UTC()
+	if next != nil {
+		status = model.DeliveryPending
+		nextAt = next.UTC()
+	}
+	_, err := s.DB.Exec(`UPDATE webhook_deliveries SET status=?, attempts=attempts+1, response_code=?, last_error=?,
+		next_attempt_at=? WHERE id=?`, status, code, nullStr(errMsg), nextAt, id)
+	return err
+}
+
+// RetryWebhookDelivery menjadwalkan ulang pengiriman yang sudah gagal; false jika tidak ada
+// atau statusnya bukan failed.
+func (s *Store) RetryWebhookDelivery(id int64) (bool, error) {
+	res, err := s.DB.Exec(`UPDATE webhook_deliveries SET status=?, attempts=0, next_attempt_at=? WHERE id=? AND status=?`,
+		model.DeliveryPending, time.Now().UTC(), id, model.DeliveryFailed)
+	if err != nil {
+		return false, err
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
+
+// PruneWebhookDeliveries menghapus log pengiriman yang sudah selesai dan lebih tua dari cutoff.
+func (s *Store) PruneWebhookDeliveries(cutoff time.Time) (int64, error) {
+	res, err := s.DB.Exec(`DELETE FROM webhook_deliveries WHERE status<>? AND created_at < ?`,
+		model.DeliveryPending, cutoff.UTC().Format(ctsLayout))
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
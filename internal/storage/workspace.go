@@ -0,0 +1,249 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// WorkspaceTables adalah tabel konfigurasi yang ikut diekspor, urut sesuai dependensi FK
+// (induk dulu). Tabel yang belum ada di DB dilewati. Tabel lain berisi data runtime (log,
+// antrean, sesi, kunci API, hasil sinkron WhatsApp) dan sengaja tidak ikut.
+var WorkspaceTables = []string{
+	"pools",
+	"accounts",
+	"account_warmup",
+	"account_send_windows",
+	"auto_join_settings",
+	"business_profiles",
+	"groups",
+	"tags",
+	"group_tags",
+	"group_fields",
+	"group_suppressions",
+	"campaigns",
+	"templates",
+	"reply_flows",
+	"cross_promos",
+	"schedules",
+	"recurring_schedules",
+	"holidays",
+	"settings",
+	"dynamic_vars",
+	"compliance_rules",
+	"audiences",
+	"audience_members",
+	"dm_suppressions",
+	"webhooks",
+	"feeds",
+	"short_links",
+}
+
+// WorkspaceSchemaVersion dinaikkan jika format Workspace berubah tidak kompatibel.
+const WorkspaceSchemaVersion = 1
+
+// Workspace adalah snapshot konfigurasi (tanpa sesi WhatsApp, log, atau antrean).
+type Workspace struct {
+	Version    int                         `json:"version"`
+	ExportedAt time.Time                   `json:"exported_at"`
+	Tables     map[string][]map[string]any `json:"tables"`
+}
+
+// dbTimeFormat dipakai saat menyerialisasi kolom TIMESTAMP agar dibaca ulang oleh driver sqlite3.
+const dbTimeFormat = "2006-01-02 15:04:05.999999999-07:00"
+
+// ExportWorkspace membaca semua WorkspaceTables dalam satu transaksi read-only sehingga
+// hasilnya konsisten (snapshot) meski ada penulisan bersamaan.
+func (s *Store) ExportWorkspace(ctx context.Context) (*Workspace, error) {
+	tx, err := s.DB.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	ws := &Workspace{Version: WorkspaceSchemaVersion, ExportedAt: time.Now().UTC(), Tables: map[string][]map[string]any{}}
+	for _, table := range WorkspaceTables {
+		cols, err := tableColumns(ctx, tx, table)
+		if err != nil {
+			return nil, err
+		}
+		if len(cols) == 0 {
+			continue
+		}
+		rows, err := tx.QueryContext(ctx, `SELECT `+quoteCols(cols)+` FROM `+quoteIdent(table))
+		if err != nil {
+			return nil, fmt.Errorf("export %s: %w", table, err)
+		}
+		list := []map[string]any{}
+		for rows.Next() {
+			vals := make([]any, len(cols))
+			ptrs := make([]any, len(cols))
+			for i := range vals {
+				ptrs[i] = &vals[i]
+			}
+			if err := rows.Scan(ptrs...); err != nil {
+				rows.Close()
+				return nil, fmt.Errorf("export %s: %w", table, err)
+			}
+			row := make(map[string]any, len(cols))
+			for i, c := range cols {
+				switch v := vals[i].(type) {
+				case []byte:
+					row[c] = string(v)
+				case time.Time:
+					row[c] = v.Format(dbTimeFormat)
+				default:
+					row[c] = v
+				}
+			}
+			redactWorkspaceRow(table, row)
+			list = append(list, row)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+		ws.Tables[table] = list
+	}
+	return ws, nil
+}
+
+// ImportWorkspace menulis snapshot ke DB (merge per primary key) dalam satu transaksi.
+// Kolom yang tidak dikenal di DB tujuan diabaikan; akun diimpor sebagai belum terhubung.
+// Mengembalikan jumlah baris per tabel.
+func (s *Store) ImportWorkspace(ctx context.Context, ws *Workspace) (map[string]int, error) {
+	if ws.Version > WorkspaceSchemaVersion {
+		return nil, fmt.Errorf("unsupported workspace version %d", ws.Version)
+	}
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	counts := map[string]int{}
+	for _, table := range WorkspaceTables {
+		rows := ws.Tables[table]
+		if len(rows) == 0 {
+			continue
+		}
+		cols, err := tableColumns(ctx, tx, table)
+		if err != nil {
+			return nil, err
+		}
+		known := map[string]bool{}
+		for _, c := range cols {
+			known[c] = true
+		}
+		for _, row := range rows {
+			if table == "accounts" {
+				row["status"] = "inactive"
+				row["last_error"] = nil
+			}
+			if err := keepWorkspaceSecrets(ctx, tx, table, row); err != nil {
+				return nil, fmt.Errorf("import %s: %w", table, err)
+			}
+			var names []string
+			var args []any
+			for c, v := range row {
+				if !known[c] {
+					continue
+				}
+				if n, ok := v.(json.Number); ok {
+					if i, err := n.Int64(); err == nil {
+						v = i
+					} else if f, err := n.Float64(); err == nil {
+						v = f
+					}
+				}
+				names = append(names, c)
+				args = append(args, v)
+			}
+			if len(names) == 0 {
+				continue
+			}
+			// Upsert (bukan INSERT OR REPLACE) agar baris anak tidak ikut terhapus lewat ON DELETE CASCADE.
+			sets := make([]string, len(names))
+			for i, c := range names {
+				sets[i] = quoteIdent(c) + `=excluded.` + quoteIdent(c)
+			}
+			q := `INSERT INTO ` + quoteIdent(table) + ` (` + quoteCols(names) + `) VALUES (?` + strings.Repeat(`,?`, len(names)-1) + `)
+				ON CONFLICT DO UPDATE SET ` + strings.Join(sets, `, `)
+			if _, err := tx.ExecContext(ctx, q, args...); err != nil {
+				return nil, fmt.Errorf("import %s: %w", table, err)
+			}
+			counts[table]++
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return counts, nil
+}
+
+// redactWorkspaceRow mengosongkan rahasia (secret webhook, bot token mirror Telegram) seperti
+// yang juga disembunyikan API, agar snapshot aman dibagikan.
+func redactWorkspaceRow(table string, row map[string]any) {
+	switch {
+	case table == "webhooks":
+		row["secret"] = nil
+	case table == "settings" && row["key"] == SettingTelegramMirror:
+		if v, ok := row["value"].(string); ok {
+			row["value"] = redactTelegramMirror(v)
+		}
+	}
+}
+
+// keepWorkspaceSecrets mempertahankan rahasia yang tersimpan di DB tujuan jika snapshot
+// berisi nilai yang sudah dikosongkan redactWorkspaceRow.
+func keepWorkspaceSecrets(ctx context.Context, tx *sql.Tx, table string, row map[string]any) error {
+	switch {
+	case table == "webhooks":
+		if s, _ := row["secret"].(string); s == "" {
+			delete(row, "secret")
+		}
+	case table == "settings" && row["key"] == SettingTelegramMirror:
+		v, ok := row["value"].(string)
+		if !ok {
+			return nil
+		}
+		var cur string
+		err := tx.QueryRowContext(ctx, `SELECT value FROM settings WHERE key=?`, SettingTelegramMirror).Scan(&cur)
+		if err != nil && err != sql.ErrNoRows {
+			return err
+		}
+		row["value"] = keepTelegramToken(v, cur)
+	}
+	return nil
+}
+
+// tableColumns mengembalikan nama kolom tabel; kosong jika tabel tidak ada.
+func tableColumns(ctx context.Context, tx *sql.Tx, table string) ([]string, error) {
+	rows, err := tx.QueryContext(ctx, `SELECT name FROM pragma_table_info(?)`, table)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var cols []string
+	for rows.Next() {
+		var c string
+		if err := rows.Scan(&c); err != nil {
+			return nil, err
+		}
+		cols = append(cols, c)
+	}
+	return cols, rows.Err()
+}
+
+func quoteIdent(s string) string { return `"` + strings.ReplaceAll(s, `"`, `""`) + `"` }
+
+func quoteCols(cols []string) string {
+	q := make([]string, len(cols))
+	for i, c := range cols {
+		q[i] = quoteIdent(c)
+	}
+	return strings.Join(q, ",")
+}
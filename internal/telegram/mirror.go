@@ -0,0 +1,281 @@
+package telegram
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime/multipart"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Part kinds yang didukung mirror.
+const (
+	PartText     = "text"
+	PartPhoto    = "photo"
+	PartVideo    = "video"
+	PartAudio    = "audio"
+	PartSticker  = "sticker"
+	PartDocument = "document"
+)
+
+// Part adalah satu komponen promo (teks atau media) yang dicerminkan ke Telegram.
+type Part struct {
+	Kind    string
+	Text    string
+	URL     string
+	Caption string
+}
+
+// Post adalah satu promo yang berhasil terkirim beserta header konteksnya.
+type Post struct {
+	// Key identitas promo (campaign, versi template atau isi kustom): promo yang sama dikirim
+	// ke banyak grup hanya dicerminkan sekali per repeatAfter.
+	Key    string
+	Header string
+	Parts  []Part
+}
+
+// repeatAfter jarak minimum sebelum promo dengan Key sama dicerminkan lagi.
+const repeatAfter = 24 * time.Hour
+
+// apiBase alamat Bot API; token ditambahkan per panggilan.
+const apiBase = "https://api.telegram.org/bot"
+
+// Fetcher memuat media dari URL (lokal /uploads atau remote); biasanya Sender.Fetch.
+type Fetcher func(ctx context.Context, url string) ([]byte, string, error)
+
+// Mirror meneruskan promo yang sukses ke channel Telegram (read-only feed untuk klien).
+// Nonaktif sampai SetConfig diberi token dan chat; nilai nil aman dipakai (Enqueue no-op).
+type Mirror struct {
+	client *http.Client
+	fetch  Fetcher
+	ch     chan Post
+	// jeda antar panggilan API agar tidak kena limit channel (~20 pesan/menit)
+	pace time.Duration
+
+	mu     sync.Mutex
+	token  string
+	chatID string
+	// waktu terakhir tiap Key dicerminkan
+	seen map[string]time.Time
+}
+
+// New membuat Mirror (belum aktif) dan menjalankan worker pengirimnya sampai ctx selesai.
+func New(ctx context.Context, fetch Fetcher) *Mirror {
+	m := &Mirror{
+		client: &http.Client{Timeout: 120 * time.Second},
+		fetch:  fetch,
+		ch:     make(chan Post, 200),
+		pace:   3 * time.Second,
+		seen:   map[string]time.Time{},
+	}
+	go m.run(ctx)
+	return m
+}
+
+// SetConfig mengganti bot token dan chat tujuan (dari setting telegram_mirror); token atau
+// chat kosong menonaktifkan mirror.
+func (m *Mirror) SetConfig(token, chatID string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.token, m.chatID = strings.TrimSpace(token), strings.TrimSpace(chatID)
+	if m.token != "" && m.chatID != "" {
+		log.Printf("[telegram] mirror enabled chat=%s", m.chatID)
+	}
+}
+
+// config token dan chat aktif; ok=false jika mirror nonaktif.
+func (m *Mirror) config() (token, chatID string, ok bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.token, m.chatID, m.token != "" && m.chatID != ""
+}
+
+// Enqueue mengantrikan post tanpa blocking. Post dilewati jika mirror nonaktif atau Key yang
+// sama sudah dicerminkan dalam repeatAfter; jika antrean penuh, post di-drop.
+func (m *Mirror) Enqueue(p Post) {
+	if m == nil || len(p.Parts) == 0 {
+		return
+	}
+	if _, _, ok := m.config(); !ok {
+		return
+	}
+	now := time.Now()
+	m.mu.Lock()
+	if at, ok := m.seen[p.Key]; ok && p.Key != "" && now.Sub(at) < repeatAfter {
+		m.mu.Unlock()
+		return
+	}
+	for k, at := range m.seen {
+		if now.Sub(at) >= repeatAfter {
+			delete(m.seen, k)
+		}
+	}
+	if p.Key != "" {
+		m.seen[p.Key] = now
+	}
+	m.mu.Unlock()
+	select {
+	case m.ch <- p:
+	default:
+		log.Printf("[telegram] queue full, dropping mirror post")
+	}
+}
+
+func (m *Mirror) run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case p := <-m.ch:
+			m.publish(ctx, p)
+		}
+	}
+}
+
+func (m *Mirror) publish(ctx context.Context, p Post) {
+	if _, _, ok := m.config(); !ok {
+		return
+	}
+	header := strings.TrimSpace(p.Header)
+	for i, part := range p.Parts {
+		var err error
+		switch part.Kind {
+		case PartText:
+			text := part.Text
+			if header != "" {
+				text = header + "\n\n" + text
+				header = ""
+			}
+			err = m.call(ctx, "sendMessage", map[string]string{"text": text}, "", nil, "")
+		default:
+			caption := part.Caption
+			if header != "" && part.Kind != PartSticker && part.Kind != PartAudio {
+				caption = strings.TrimSpace(header + "\n\n" + caption)
+				header = ""
+			}
+			err = m.sendMedia(ctx, part, caption)
+		}
+		if err != nil {
+			log.Printf("[telegram] mirror part %d (%s) failed: %v", i+1, part.Kind, err)
+		}
+		select {
+		case <-time.After(m.pace):
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (m *Mirror) sendMedia(ctx context.Context, part Part, caption string) error {
+	if m.fetch == nil {
+		return fmt.Errorf("no fetcher configured")
+	}
+	data, _, err := m.fetch(ctx, part.URL)
+	if err != nil {
+		return err
+	}
+	method := map[string]string{
+		PartPhoto:    "sendPhoto",
+		PartVideo:    "sendVideo",
+		PartAudio:    "sendAudio",
+		PartSticker:  "sendSticker",
+		PartDocument: "sendDocument",
+	}[part.Kind]
+	if method == "" {
+		return fmt.Errorf("unsupported part kind %q", part.Kind)
+	}
+	fields := map[string]string{}
+	if caption != "" {
+		fields["caption"] = caption
+	}
+	return m.call(ctx, method, fields, part.Kind, data, fileName(part.URL))
+}
+
+// call memanggil Bot API; jika fileField diisi, request dikirim sebagai multipart.
+// Respons 429 dihormati sekali sesuai retry_after.
+func (m *Mirror) call(ctx context.Context, method string, fields map[string]string, fileField string, data []byte, fname string) error {
+	token, chatID, ok := m.config()
+	if !ok {
+		return fmt.Errorf("mirror disabled")
+	}
+	for attempt := 0; attempt < 2; attempt++ {
+		var body bytes.Buffer
+		mw := multipart.NewWriter(&body)
+		_ = mw.WriteField("chat_id", chatID)
+		for k, v := range fields {
+			_ = mw.WriteField(k, v)
+		}
+		if fileField != "" {
+			fw, err := mw.CreateFormFile(fileField, fname)
+			if err != nil {
+				return err
+			}
+			if _, err := fw.Write(data); err != nil {
+				return err
+			}
+		}
+		if err := mw.Close(); err != nil {
+			return err
+		}
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, apiBase+token+"/"+method, &body)
+		if err != nil {
+			return redactToken(err, token)
+		}
+		req.Header.Set("Content-Type", mw.FormDataContentType())
+		res, err := m.client.Do(req)
+		if err != nil {
+			return redactToken(err, token)
+		}
+		raw, _ := io.ReadAll(io.LimitReader(res.Body, 4096))
+		res.Body.Close()
+		var out struct {
+			OK          bool   `json:"ok"`
+			Description string `json:"description"`
+			Parameters  struct {
+				RetryAfter int `json:"retry_after"`
+			} `json:"parameters"`
+		}
+		_ = json.Unmarshal(raw, &out)
+		if out.OK {
+			return nil
+		}
+		if res.StatusCode == http.StatusTooManyRequests && out.Parameters.RetryAfter > 0 && attempt == 0 {
+			select {
+			case <-time.After(time.Duration(out.Parameters.RetryAfter) * time.Second):
+				continue
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+		return fmt.Errorf("%s: status %d: %s", method, res.StatusCode, out.Description)
+	}
+	return fmt.Errorf("%s: rate limited", method)
+}
+
+// redactToken menyamarkan bot token di pesan error: URL Bot API memuat token, dan *url.Error
+// dari http.Client menyertakan URL itu.
+func redactToken(err error, token string) error {
+	if token == "" || !strings.Contains(err.Error(), token) {
+		return err
+	}
+	return errors.New(strings.ReplaceAll(err.Error(), token, "***"))
+}
+
+func fileName(u string) string {
+	s := u
+	if i := strings.Index(s, "?"); i >= 0 {
+		s = s[:i]
+	}
+	if j := strings.LastIndex(s, "/"); j >= 0 && j < len(s)-1 {
+		return s[j+1:]
+	}
+	return "file"
+}
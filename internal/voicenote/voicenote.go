@@ -0,0 +1,131 @@
+// Package voicenote menyiapkan audio sebagai voice note WhatsApp (PTT): konversi ke OGG/Opus
+// mono serta durasi dan waveform 64 titik untuk tampilan gelombang di chat. Konversi memakai
+// ffmpeg (FFMPEG_PATH, default "ffmpeg" di PATH).
+package voicenote
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// Mimetype voice note yang diterima WhatsApp.
+const Mimetype = "audio/ogg; codecs=opus"
+
+// waveformLen jumlah titik waveform yang dipakai klien WhatsApp.
+const waveformLen = 64
+
+// analyzeRate sample rate PCM untuk hitung durasi/waveform; cukup rendah agar cepat.
+const analyzeRate = 8000
+
+// ErrNoFFmpeg dikembalikan jika binary ffmpeg tidak ditemukan.
+var ErrNoFFmpeg = errors.New("ffmpeg not found (install ffmpeg or set FFMPEG_PATH)")
+
+func ffmpegPath() (string, error) {
+	bin := strings.TrimSpace(os.Getenv("FFMPEG_PATH"))
+	if bin == "" {
+		bin = "ffmpeg"
+	}
+	p, err := exec.LookPath(bin)
+	if err != nil {
+		return "", ErrNoFFmpeg
+	}
+	return p, nil
+}
+
+// IsOggOpus true jika data adalah stream Ogg berisi Opus.
+func IsOggOpus(data []byte) bool {
+	head := data
+	if len(head) > 512 {
+		head = head[:512]
+	}
+	return bytes.HasPrefix(data, []byte("OggS")) && bytes.Contains(head, []byte("OpusHead"))
+}
+
+// Convert mengubah audio (mp3, m4a, wav, ogg, ...) menjadi OGG/Opus mono 48 kHz.
+func Convert(ctx context.Context, data []byte) ([]byte, error) {
+	return run(ctx, data, "-vn", "-ac", "1", "-ar", "48000", "-c:a", "libopus", "-b:a", "32k",
+		"-application", "voip", "-f", "ogg", "pipe:1")
+}
+
+// Analyze mengembalikan durasi (detik, dibulatkan ke atas) dan waveform 64 titik (0-100).
+func Analyze(ctx context.Context, data []byte) (uint32, []byte, error) {
+	pcm, err := run(ctx, data, "-vn", "-ac", "1", "-ar", fmt.Sprint(analyzeRate), "-f", "s16le", "pipe:1")
+	if err != nil {
+		return 0, nil, err
+	}
+	n := len(pcm) / 2
+	if n == 0 {
+		return 0, nil, errors.New("audio has no samples")
+	}
+	seconds := uint32((n + analyzeRate - 1) / analyzeRate)
+	return seconds, waveform(pcm), nil
+}
+
+// waveform merata-rata amplitudo absolut PCM s16le ke 64 bucket lalu dinormalisasi ke 0-100.
+func waveform(pcm []byte) []byte {
+	n := len(pcm) / 2
+	sums := make([]float64, waveformLen)
+	counts := make([]int, waveformLen)
+	for i := 0; i < n; i++ {
+		v := int32(int16(binary.LittleEndian.Uint16(pcm[i*2:])))
+		if v < 0 {
+			v = -v
+		}
+		b := i * waveformLen / n
+		sums[b] += float64(v)
+		counts[b]++
+	}
+	max := 0.0
+	for i := range sums {
+		if counts[i] > 0 {
+			sums[i] /= float64(counts[i])
+		}
+		if sums[i] > max {
+			max = sums[i]
+		}
+	}
+	out := make([]byte, waveformLen)
+	if max == 0 {
+		return out
+	}
+	for i, s := range sums {
+		out[i] = byte(s * 100 / max)
+	}
+	return out
+}
+
+// run menulis input ke file sementara (m4a/mp4 butuh input yang bisa di-seek) lalu menjalankan
+// ffmpeg dengan output ke stdout.
+func run(ctx context.Context, data []byte, outArgs ...string) ([]byte, error) {
+	bin, err := ffmpegPath()
+	if err != nil {
+		return nil, err
+	}
+	in, err := os.CreateTemp("", "voicenote-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(in.Name())
+	if _, err := in.Write(data); err != nil {
+		in.Close()
+		return nil, err
+	}
+	if err := in.Close(); err != nil {
+		return nil, err
+	}
+	args := append([]string{"-hide_banner", "-loglevel", "error", "-nostdin", "-i", in.Name()}, outArgs...)
+	cmd := exec.CommandContext(ctx, bin, args...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("ffmpeg: %v: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.Bytes(), nil
+}
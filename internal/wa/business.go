@@ -0,0 +1,67 @@
+package wa
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.mau.fi/whatsmeow"
+	"go.mau.fi/whatsmeow/types"
+
+	"promote/internal/model"
+)
+
+// BusinessProfile mengambil profil WhatsApp Business nomor akun sendiri. Nomor biasa (bukan
+// Business) mengembalikan isBusiness=false tanpa error. About diisi dari status profil.
+func (m *Manager) BusinessProfile(ctx context.Context, accountID string) (p model.BusinessProfile, isBusiness bool, err error) {
+	c, err := m.ensureClient(accountID)
+	if err != nil {
+		return p, false, err
+	}
+	if !c.IsConnected() || c.Store == nil || c.Store.ID == nil {
+		return p, false, notConnected(accountID)
+	}
+	ctx, cancel := context.WithTimeout(ctx, 15*time.Second)
+	defer cancel()
+	jid := c.Store.ID.ToNonAD()
+	if info, err := c.GetUserInfo(ctx, []types.JID{jid}); err == nil {
+		p.About = info[jid].Status
+	}
+	bp, err := c.GetBusinessProfile(ctx, jid)
+	if errors.Is(err, whatsmeow.ErrIQNotFound) {
+		return p, false, nil
+	}
+	if err != nil {
+		return p, false, fmt.Errorf("business profile: %w", classify(err))
+	}
+	// Akun Business selalu punya minimal satu kategori.
+	if len(bp.Categories) == 0 {
+		return p, false, nil
+	}
+	names := make([]string, 0, len(bp.Categories))
+	for _, cat := range bp.Categories {
+		names = append(names, cat.Name)
+	}
+	p.Category = strings.Join(names, ", ")
+	p.Address = bp.Address
+	p.Email = bp.Email
+	p.CatalogURL = "https://wa.me/c/" + jid.User
+	return p, true, nil
+}
+
+// SetAbout mengganti teks about (status profil) akun. Field profil Business lain belum bisa
+// diubah lewat whatsmeow dan harus diterapkan dari aplikasi WhatsApp Business.
+func (m *Manager) SetAbout(ctx context.Context, accountID, about string) error {
+	c, err := m.ensureClient(accountID)
+	if err != nil {
+		return err
+	}
+	if !c.IsConnected() {
+		return notConnected(accountID)
+	}
+	ctx, cancel := context.WithTimeout(ctx, 15*time.Second)
+	defer cancel()
+	return c.SetStatusMessage(ctx, about)
+}
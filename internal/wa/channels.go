@@ -0,0 +1,48 @@
+package wa
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.mau.fi/whatsmeow/types"
+
+	"promote/internal/model"
+)
+
+// OwnedChannels mengambil WhatsApp Channels (newsletter) yang diikuti akun dan menyaring yang
+// bisa diposting akun (role owner atau admin).
+func (m *Manager) OwnedChannels(ctx context.Context, accountID string) ([]model.Channel, error) {
+	c, err := m.ensureClient(accountID)
+	if err != nil {
+		return nil, err
+	}
+	if !c.IsConnected() || c.Store == nil || c.Store.ID == nil {
+		return nil, notConnected(accountID)
+	}
+	ctx, cancel := context.WithTimeout(ctx, 20*time.Second)
+	defer cancel()
+	list, err := c.GetSubscribedNewsletters(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("newsletters: %w", classify(err))
+	}
+	out := []model.Channel{}
+	for _, n := range list {
+		if n == nil || n.ViewerMeta == nil {
+			continue
+		}
+		role := n.ViewerMeta.Role
+		if role != types.NewsletterRoleOwner && role != types.NewsletterRoleAdmin {
+			continue
+		}
+		out = append(out, model.Channel{
+			ID:          n.ID.String(),
+			AccountID:   accountID,
+			Name:        n.ThreadMeta.Name.Text,
+			Role:        string(role),
+			Subscribers: n.ThreadMeta.SubscriberCount,
+			InviteCode:  n.ThreadMeta.InviteCode,
+		})
+	}
+	return out, nil
+}
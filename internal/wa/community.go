@@ -0,0 +1,25 @@
+package wa
+
+import (
+	"go.mau.fi/whatsmeow/types"
+
+	"promote/internal/model"
+)
+
+// communityRole menentukan peran grup dalam komunitas WhatsApp dari info grup: induk komunitas,
+// grup pengumuman default, sub-grup tertaut, atau "" untuk grup biasa. parent berisi JID
+// komunitas induk untuk grup pengumuman dan sub-grup.
+func communityRole(info *types.GroupInfo) (role, parent string) {
+	if !info.LinkedParentJID.IsEmpty() {
+		parent = info.LinkedParentJID.String()
+	}
+	switch {
+	case info.IsParent:
+		return model.GroupRoleParent, ""
+	case info.IsDefaultSubGroup:
+		return model.GroupRoleAnnouncement, parent
+	case parent != "":
+		return model.GroupRoleSubgroup, parent
+	}
+	return "", ""
+}
@@ -0,0 +1,95 @@
+package wa
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"go.mau.fi/whatsmeow"
+)
+
+// Error sentinel Manager; error yang dikembalikan membungkus salah satunya (cek dengan errors.Is)
+// agar lapisan HTTP bisa memilih status code dan klien bisa bercabang lewat ErrorCode.
+var (
+	ErrNotPaired     = errors.New("account not paired")
+	ErrAlreadyPaired = errors.New("account already paired")
+	ErrNotConnected  = errors.New("account not connected")
+	ErrGroupNotFound = errors.New("group not found")
+	ErrGroupNoAccess = errors.New("no access to group")
+	ErrInvalidJID    = errors.New("invalid jid")
+	ErrTimeout       = errors.New("whatsapp request timed out")
+)
+
+// errorCodes kode stabil per sentinel untuk field "code" respons API, urut prioritas.
+var errorCodes = []struct {
+	err  error
+	code string
+}{
+	{ErrObserverAccount, "observer_account"},
+	{ErrNotPaired, "not_paired"},
+	{ErrAlreadyPaired, "already_paired"},
+	{ErrNotConnected, "not_connected"},
+	{ErrNotGroupAdmin, "not_group_admin"},
+	{ErrJoinApprovalOff, "join_approval_off"},
+	{ErrGroupNotFound, "group_not_found"},
+	{ErrGroupNoAccess, "group_no_access"},
+	{ErrInviteRevoked, "invite_revoked"},
+	{ErrInviteInvalid, "invite_invalid"},
+	{ErrInvalidJID, "invalid_jid"},
+	{ErrTimeout, "timeout"},
+	{ErrPairingByNumberUnsupported, "pairing_unsupported"},
+}
+
+// ErrorCode kode mesin untuk err ("not_paired", "timeout", ...); "" jika bukan error yang dikenal.
+func ErrorCode(err error) string {
+	for _, c := range errorCodes {
+		if errors.Is(err, c.err) {
+			return c.code
+		}
+	}
+	return ""
+}
+
+// notConnected error ErrNotConnected untuk akun.
+func notConnected(accountID string) error {
+	return fmt.Errorf("%w: %s", ErrNotConnected, accountID)
+}
+
+// notPaired error ErrNotPaired untuk akun.
+func notPaired(accountID string) error {
+	return fmt.Errorf("%w: %s", ErrNotPaired, accountID)
+}
+
+// classify membungkus error whatsmeow/context dengan sentinel yang sesuai (tetap bisa di-unwrap
+// ke error aslinya); error lain dikembalikan apa adanya.
+func classify(err error) error {
+	if err == nil || ErrorCode(err) != "" {
+		return err
+	}
+	var sentinel error
+	switch {
+	case errors.Is(err, context.DeadlineExceeded), errors.Is(err, whatsmeow.ErrIQTimedOut):
+		sentinel = ErrTimeout
+	case errors.Is(err, whatsmeow.ErrNotConnected), errors.Is(err, whatsmeow.ErrIQDisconnected):
+		sentinel = ErrNotConnected
+	case errors.Is(err, whatsmeow.ErrNotLoggedIn):
+		sentinel = ErrNotPaired
+	case errors.Is(err, whatsmeow.ErrGroupNotFound):
+		sentinel = ErrGroupNotFound
+	case errors.Is(err, whatsmeow.ErrNotInGroup), errors.Is(err, whatsmeow.ErrIQForbidden):
+		sentinel = ErrGroupNoAccess
+	case errors.Is(err, whatsmeow.ErrInviteLinkRevoked):
+		sentinel = ErrInviteRevoked
+	case errors.Is(err, whatsmeow.ErrInviteLinkInvalid):
+		sentinel = ErrInviteInvalid
+	default:
+		s := strings.ToLower(err.Error())
+		if strings.Contains(s, "timed out") || strings.Contains(s, "timeout") {
+			sentinel = ErrTimeout
+		} else {
+			return err
+		}
+	}
+	return fmt.Errorf("%w: %w", sentinel, err)
+}
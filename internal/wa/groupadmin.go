@@ -0,0 +1,101 @@
+package wa
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.mau.fi/whatsmeow"
+	"go.mau.fi/whatsmeow/types"
+)
+
+// ErrNotGroupAdmin dikembalikan jika akun bukan admin grup yang akan diubah.
+var ErrNotGroupAdmin = errors.New("account is not an admin of this group")
+
+// adminClient mengembalikan client akun beserta info grup, setelah memastikan akun terhubung,
+// bukan observer, dan admin di grup tersebut.
+func (m *Manager) adminClient(ctx context.Context, accountID, groupJID string) (*whatsmeow.Client, *types.GroupInfo, error) {
+	if err := m.checkObserver(accountID); err != nil {
+		return nil, nil, err
+	}
+	c, err := m.ensureClient(accountID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !c.IsConnected() || c.Store == nil || c.Store.ID == nil {
+		return nil, nil, notConnected(accountID)
+	}
+	jid, err := types.ParseJID(groupJID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %w", ErrInvalidJID, err)
+	}
+	info, err := c.GetGroupInfo(ctx, jid)
+	if err != nil {
+		return nil, nil, fmt.Errorf("group info: %w", classify(err))
+	}
+	if !selfIsAdmin(c, info) {
+		return nil, nil, ErrNotGroupAdmin
+	}
+	return c, info, nil
+}
+
+// selfIsAdmin true jika akun client adalah admin/superadmin di info grup.
+func selfIsAdmin(c *whatsmeow.Client, info *types.GroupInfo) bool {
+	self, selfLID := c.Store.ID.User, c.Store.LID.User
+	for _, p := range info.Participants {
+		if !p.IsAdmin && !p.IsSuperAdmin {
+			continue
+		}
+		for _, u := range []string{p.JID.User, p.PhoneNumber.User, p.LID.User} {
+			if u != "" && (u == self || u == selfLID) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// SetGroupSubject mengganti nama grup (maks 25 karakter, dibatasi server WhatsApp).
+func (m *Manager) SetGroupSubject(ctx context.Context, accountID, groupJID, subject string) error {
+	ctx, cancel := context.WithTimeout(ctx, 20*time.Second)
+	defer cancel()
+	c, info, err := m.adminClient(ctx, accountID, groupJID)
+	if err != nil {
+		return err
+	}
+	return classify(c.SetGroupName(ctx, info.JID, subject))
+}
+
+// SetGroupDescription mengganti deskripsi grup; teks kosong menghapus deskripsi.
+func (m *Manager) SetGroupDescription(ctx context.Context, accountID, groupJID, description string) error {
+	ctx, cancel := context.WithTimeout(ctx, 20*time.Second)
+	defer cancel()
+	c, info, err := m.adminClient(ctx, accountID, groupJID)
+	if err != nil {
+		return err
+	}
+	return classify(c.SetGroupTopic(ctx, info.JID, info.TopicID, "", description))
+}
+
+// SetGroupPhoto mengganti foto grup dengan gambar JPEG; mengembalikan ID foto baru.
+func (m *Manager) SetGroupPhoto(ctx context.Context, accountID, groupJID string, jpeg []byte) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	c, info, err := m.adminClient(ctx, accountID, groupJID)
+	if err != nil {
+		return "", err
+	}
+	id, err := c.SetGroupPhoto(ctx, info.JID, jpeg)
+	return id, classify(err)
+}
+
+// IsSelf true jika jid (nomor atau LID) adalah akun accountID sendiri. Hanya untuk client yang
+// sudah dimuat; tidak memicu koneksi baru.
+func (m *Manager) IsSelf(accountID string, jid types.JID) bool {
+	c, ok := m.Clients[accountID]
+	if !ok || c == nil || c.Store == nil || c.Store.ID == nil || jid.User == "" {
+		return false
+	}
+	return jid.User == c.Store.ID.User || jid.User == c.Store.LID.User
+}
@@ -0,0 +1,128 @@
+package wa
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.mau.fi/whatsmeow"
+	"go.mau.fi/whatsmeow/types"
+)
+
+// ErrInviteRevoked dikembalikan jika link undangan sudah dicabut; ErrInviteInvalid jika kodenya tidak dikenal.
+var (
+	ErrInviteRevoked = errors.New("invite link revoked")
+	ErrInviteInvalid = errors.New("invite link invalid")
+)
+
+// AdminGroup grup yang diikuti akun dengan akun sebagai admin.
+type AdminGroup struct {
+	JID          string
+	Name         string
+	Participants int
+}
+
+// AdminGroups daftar grup tempat akun menjadi admin/superadmin (hanya baca, observer diizinkan).
+func (m *Manager) AdminGroups(ctx context.Context, accountID string) ([]AdminGroup, error) {
+	c, ok := m.Clients[accountID]
+	if !ok || c == nil || c.Store == nil || c.Store.ID == nil {
+		return nil, notPaired(accountID)
+	}
+	if !c.IsConnected() {
+		return nil, notConnected(accountID)
+	}
+	groups, err := c.GetJoinedGroups(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var out []AdminGroup
+	for _, g := range groups {
+		if g.IsParent || !selfIsAdmin(c, g) {
+			continue
+		}
+		out = append(out, AdminGroup{JID: g.JID.String(), Name: g.Name, Participants: len(g.Participants)})
+	}
+	return out, nil
+}
+
+// GroupInviteLink link undangan grup saat ini (akun harus admin). reset=true mencabut link lama
+// dan membuat yang baru; karena mengubah grup, reset ditolak untuk akun observer.
+func (m *Manager) GroupInviteLink(ctx context.Context, accountID, groupJID string, reset bool) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, 20*time.Second)
+	defer cancel()
+	if reset {
+		c, info, err := m.adminClient(ctx, accountID, groupJID)
+		if err != nil {
+			return "", err
+		}
+		link, err := c.GetGroupInviteLink(ctx, info.JID, true)
+		return link, classify(err)
+	}
+	c, err := m.ensureClient(accountID)
+	if err != nil {
+		return "", err
+	}
+	if !c.IsConnected() {
+		return "", notConnected(accountID)
+	}
+	jid, err := types.ParseJID(groupJID)
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrInvalidJID, err)
+	}
+	link, err := c.GetGroupInviteLink(ctx, jid, false)
+	if errors.Is(err, whatsmeow.ErrGroupInviteLinkUnauthorized) {
+		return "", ErrNotGroupAdmin
+	}
+	return link, classify(err)
+}
+
+// CheckInviteLink memastikan link/kode undangan masih bisa dipakai; mengembalikan JID grup dan
+// jumlah peserta menurut server. Link dicabut -> ErrInviteRevoked, kode tak dikenal -> ErrInviteInvalid.
+func (m *Manager) CheckInviteLink(ctx context.Context, accountID, link string) (string, int, error) {
+	c, err := m.ensureClient(accountID)
+	if err != nil {
+		return "", 0, err
+	}
+	if !c.IsConnected() {
+		return "", 0, notConnected(accountID)
+	}
+	ctx, cancel := context.WithTimeout(ctx, 15*time.Second)
+	defer cancel()
+	info, err := c.GetGroupInfoFromLink(ctx, link)
+	switch {
+	case errors.Is(err, whatsmeow.ErrInviteLinkRevoked):
+		return "", 0, ErrInviteRevoked
+	case errors.Is(err, whatsmeow.ErrInviteLinkInvalid):
+		return "", 0, ErrInviteInvalid
+	case err != nil:
+		return "", 0, classify(err)
+	}
+	return info.JID.String(), len(info.Participants), nil
+}
+
+// GroupAvatarURL URL foto profil grup (bisa diunduh langsung); kosong tanpa error jika grup
+// tidak punya foto.
+func (m *Manager) GroupAvatarURL(ctx context.Context, accountID, groupJID string) (string, error) {
+	c, err := m.ensureClient(accountID)
+	if err != nil {
+		return "", err
+	}
+	if !c.IsConnected() {
+		return "", notConnected(accountID)
+	}
+	jid, err := types.ParseJID(groupJID)
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrInvalidJID, err)
+	}
+	ctx, cancel := context.WithTimeout(ctx, 15*time.Second)
+	defer cancel()
+	info, err := c.GetProfilePictureInfo(ctx, jid, nil)
+	if errors.Is(err, whatsmeow.ErrProfilePictureNotSet) || (err == nil && info == nil) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return info.URL, nil
+}
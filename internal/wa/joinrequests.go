@@ -0,0 +1,159 @@
+package wa
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.mau.fi/whatsmeow"
+	"go.mau.fi/whatsmeow/types"
+
+	"promote/internal/storage"
+)
+
+// ErrJoinApprovalOff dikembalikan jika grup tidak memakai persetujuan admin untuk bergabung.
+var ErrJoinApprovalOff = errors.New("join approval is not enabled for this group")
+
+// JoinRequest permintaan bergabung ke grup yang menunggu persetujuan admin.
+type JoinRequest struct {
+	JID string `json:"jid"`
+	// Number nomor peminta jika diketahui (JID LID dipetakan lewat store akun).
+	Number      string    `json:"number,omitempty"`
+	RequestedAt time.Time `json:"requested_at"`
+}
+
+// JoinRequestResult hasil approve/reject untuk satu target.
+type JoinRequestResult struct {
+	Target string `json:"target"`
+	JID    string `json:"jid,omitempty"`
+	OK     bool   `json:"ok"`
+	Error  string `json:"error,omitempty"`
+}
+
+// joinApprovalClient seperti adminClient, ditambah syarat grup memakai persetujuan bergabung.
+func (m *Manager) joinApprovalClient(ctx context.Context, accountID, groupJID string) (*whatsmeow.Client, types.JID, error) {
+	c, info, err := m.adminClient(ctx, accountID, groupJID)
+	if err != nil {
+		return nil, types.EmptyJID, err
+	}
+	if !info.IsJoinApprovalRequired {
+		return nil, types.EmptyJID, ErrJoinApprovalOff
+	}
+	return c, info.JID, nil
+}
+
+func pendingJoinRequests(ctx context.Context, c *whatsmeow.Client, group types.JID) ([]JoinRequest, error) {
+	reqs, err := c.GetGroupRequestParticipants(ctx, group)
+	if err != nil {
+		return nil, fmt.Errorf("join requests: %w", classify(err))
+	}
+	out := make([]JoinRequest, 0, len(reqs))
+	for _, r := range reqs {
+		jr := JoinRequest{JID: r.JID.String(), RequestedAt: r.RequestedAt}
+		switch {
+		case r.JID.Server == types.DefaultUserServer:
+			jr.Number = r.JID.User
+		case r.JID.Server == types.HiddenUserServer && c.Store.LIDs != nil:
+			if pn, err := c.Store.LIDs.GetPNForLID(ctx, r.JID); err == nil && !pn.IsEmpty() {
+				jr.Number = pn.User
+			}
+		}
+		out = append(out, jr)
+	}
+	return out, nil
+}
+
+// ListJoinRequests daftar permintaan bergabung yang pending (akun harus admin grup).
+func (m *Manager) ListJoinRequests(ctx context.Context, accountID, groupJID string) ([]JoinRequest, error) {
+	ctx, cancel := context.WithTimeout(ctx, 20*time.Second)
+	defer cancel()
+	c, group, err := m.joinApprovalClient(ctx, accountID, groupJID)
+	if err != nil {
+		return nil, err
+	}
+	return pendingJoinRequests(ctx, c, group)
+}
+
+// ResolveJoinRequests menyetujui (approve=true) atau menolak permintaan bergabung. targets berisi
+// JID atau nomor dari ListJoinRequests; kosong = semua yang pending. Target yang tidak sedang
+// pending dilaporkan gagal tanpa dikirim ke WhatsApp.
+func (m *Manager) ResolveJoinRequests(ctx context.Context, accountID, groupJID string, targets []string, approve bool) ([]JoinRequestResult, error) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	c, group, err := m.joinApprovalClient(ctx, accountID, groupJID)
+	if err != nil {
+		return nil, err
+	}
+	pending, err := pendingJoinRequests(ctx, c, group)
+	if err != nil {
+		return nil, err
+	}
+	byKey := map[string]string{}
+	for _, p := range pending {
+		byKey[p.JID] = p.JID
+		if p.Number != "" {
+			byKey[p.Number] = p.JID
+		}
+	}
+	if len(targets) == 0 {
+		for _, p := range pending {
+			targets = append(targets, p.JID)
+		}
+	}
+
+	results := make([]JoinRequestResult, 0, len(targets))
+	index := map[string]int{}
+	var jids []types.JID
+	for _, t := range targets {
+		t = strings.TrimSpace(t)
+		key := t
+		if !strings.Contains(t, "@") {
+			key = storage.NormalizeNumber(t)
+		}
+		res := JoinRequestResult{Target: t, JID: byKey[key]}
+		if res.JID == "" {
+			res.Error = "no pending join request"
+			results = append(results, res)
+			continue
+		}
+		if _, dup := index[res.JID]; dup {
+			continue
+		}
+		jid, err := types.ParseJID(res.JID)
+		if err != nil {
+			res.Error = err.Error()
+			results = append(results, res)
+			continue
+		}
+		index[res.JID] = len(results)
+		results = append(results, res)
+		jids = append(jids, jid)
+	}
+	if len(jids) == 0 {
+		return results, nil
+	}
+
+	action := whatsmeow.ParticipantChangeReject
+	if approve {
+		action = whatsmeow.ParticipantChangeApprove
+	}
+	changed, err := c.UpdateGroupRequestParticipants(ctx, group, jids, action)
+	if err != nil {
+		return nil, fmt.Errorf("%s join requests: %w", action, classify(err))
+	}
+	for i := range index {
+		results[index[i]].OK = true
+	}
+	for _, p := range changed {
+		if p.Error == 0 {
+			continue
+		}
+		if i, ok := index[p.JID.String()]; ok {
+			results[i].OK = false
+			results[i].Error = fmt.Sprintf("whatsapp error %d", p.Error)
+		}
+	}
+	return results, nil
+}
@@ -5,11 +5,11 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"path/filepath"
 	"strings"
 	"sync"
 	"time"
 
-	"github.com/skip2/go-qrcode"
 	"go.mau.fi/whatsmeow"
 	waProto "go.mau.fi/whatsmeow/binary/proto"
 	"go.mau.fi/whatsmeow/store/sqlstore"
@@ -17,12 +17,22 @@ import (
 	"go.mau.fi/whatsmeow/types/events"
 	waLog "go.mau.fi/whatsmeow/util/log"
 
+	"promote/internal/langdetect"
+	"promote/internal/logship"
+	"promote/internal/model"
 	"promote/internal/storage"
+	"promote/internal/webhook"
 )
 
 // MessageHandler is a callback for handling incoming messages
 type MessageHandler func(accountID string, evt *events.Message)
 
+// ReceiptHandler is a callback for delivery/read receipts of messages we sent
+type ReceiptHandler func(accountID string, evt *events.Receipt)
+
+// GroupInfoHandler is a callback for group metadata/membership changes (join, leave, kick)
+type GroupInfoHandler func(accountID string, evt *events.GroupInfo)
+
 type Manager struct {
 	Container     *sqlstore.Container
 	Clients       map[string]*whatsmeow.Client
@@ -32,25 +42,30 @@ type Manager struct {
 	ClientLogger  waLog.Logger
 	pairingMu     sync.Mutex
 	pairingActive map[string]bool
+	qrWatches     map[string]*qrWatch
 
 	// Multi-session isolation: satu sqlstore container per account
 	BaseDSN    string
 	Containers map[string]*sqlstore.Container
+	// SessionDir (opsional) direktori file sesi per akun; kosong = sama dengan DB utama.
+	SessionDir string
 	
 	// Message handlers (e.g., for auto-join)
 	messageHandlers []MessageHandler
+	receiptHandlers []ReceiptHandler
+	groupHandlers   []GroupInfoHandler
 	handlerMu       sync.RWMutex
+
+	// Ship (opsional) meneruskan event status akun ke sink log eksternal.
+	Ship *logship.Shipper
+	// Hooks (opsional) mengirim event status akun ke webhook.
+	Hooks *webhook.Dispatcher
 }
 
 var ErrPairingByNumberUnsupported = errors.New("pairing via phone number unsupported by current whatsmeow")
 
 // ParticipantInfo merepresentasikan anggota grup (user) beserta atribut admin.
-type ParticipantInfo struct {
-	JID          string `json:"jid"`
-	Number       string `json:"number"`
-	IsAdmin      bool   `json:"is_admin"`
-	IsSuperAdmin bool   `json:"is_superadmin"`
-}
+type ParticipantInfo = model.Participant
 
 func NewManager(ctx context.Context, dsn string, store *storage.Store) (*Manager, error) {
 	dbLog := waLog.Stdout("Database", "INFO", true)
@@ -68,6 +83,7 @@ func NewManager(ctx context.Context, dsn string, store *storage.Store) (*Manager
 		DBLogger:      dbLog,
 		ClientLogger:  clientLog,
 		pairingActive: make(map[string]bool),
+		qrWatches:     make(map[string]*qrWatch),
 		BaseDSN:       dsn,
 		Containers:    make(map[string]*sqlstore.Container),
 	}, nil
@@ -77,7 +93,7 @@ func NewManager(ctx context.Context, dsn string, store *storage.Store) (*Manager
 func (m *Manager) perAccountDSN(accountID string) string {
 	base := m.BaseDSN
 	if base == "" {
-		return fmt.Sprintf("file:promote_wa_%s.db?_foreign_keys=on", accountID)
+		return "file:" + m.sessionPath(fmt.Sprintf("promote_wa_%s.db", accountID)) + "?_foreign_keys=on"
 	}
 	// Pisahkan query string jika ada
 	var path, q string
@@ -95,7 +111,7 @@ func (m *Manager) perAccountDSN(accountID string) string {
 		} else {
 			fn = fn + "_wa_" + accountID + ".db"
 		}
-		dsn := "file:" + fn
+		dsn := "file:" + m.sessionPath(fn)
 		if q != "" {
 			dsn = dsn + "?" + q
 		}
@@ -108,6 +124,27 @@ func (m *Manager) perAccountDSN(accountID string) string {
 	return fmt.Sprintf("%s?acc=%s", base, accountID)
 }
 
+// sessionPath menempatkan file sesi di SessionDir jika diset.
+func (m *Manager) sessionPath(fn string) string {
+	if m.SessionDir == "" {
+		return fn
+	}
+	return filepath.Join(m.SessionDir, filepath.Base(fn))
+}
+
+// SessionFile mengembalikan path file SQLite sesi whatsmeow akun, atau "" jika DSN bukan file.
+func (m *Manager) SessionFile(accountID string) string {
+	dsn := m.perAccountDSN(accountID)
+	if !strings.HasPrefix(dsn, "file:") {
+		return ""
+	}
+	fn := strings.TrimPrefix(dsn, "file:")
+	if i := strings.Index(fn, "?"); i >= 0 {
+		fn = fn[:i]
+	}
+	return fn
+}
+
 func (m *Manager) ensureClient(accountID string) (*whatsmeow.Client, error) {
 	if c, ok := m.Clients[accountID]; ok {
 		return c, nil
@@ -146,13 +183,25 @@ func (m *Manager) ensureClient(accountID string) (*whatsmeow.Client, error) {
 				msisdn = &v
 			}
 			_ = m.Store.UpdateAccountStatus(accountID, "online", "", msisdn)
+			// Koneksi pertama menandai awal warmup akun
+			_ = m.Store.MarkAccountPaired(accountID)
+			m.emitAccountEvent(accountID, "online", "")
 		case *events.LoggedOut:
 			_ = m.Store.UpdateAccountStatus(accountID, "logged_out", "", nil)
+			m.emitAccountEvent(accountID, "logged_out", e.Reason.String())
 		case *events.StreamReplaced:
 			_ = m.Store.UpdateAccountStatus(accountID, "replaced", "", nil)
+			m.emitAccountEvent(accountID, "replaced", "")
+		case *events.Disconnected:
+			// Putus sementara (whatsmeow reconnect otomatis); dicatat untuk uptime di /api/slo.
+			m.emitAccountEvent(accountID, "disconnected", "")
 		case *events.Message:
 			// Dispatch to message handlers (e.g., auto-join)
 			m.dispatchMessage(accountID, e)
+		case *events.Receipt:
+			m.dispatchReceipt(accountID, e)
+		case *events.GroupInfo:
+			m.dispatchGroupInfo(accountID, e)
 		}
 	})
 
@@ -160,61 +209,13 @@ func (m *Manager) ensureClient(accountID string) (*whatsmeow.Client, error) {
 	return client, nil
 }
 
-func (m *Manager) StartPairing(ctx context.Context, accountID string) ([]byte, string, error) {
-	client, err := m.ensureClient(accountID)
-	if err != nil {
-		return nil, "", err
-	}
-	if client.Store.ID != nil {
-		return nil, "", fmt.Errorf("already paired")
-	}
-
-	// Pastikan Connect hanya dipanggil sekali saat pairing untuk akun ini
-	m.pairingMu.Lock()
-	if !m.pairingActive[accountID] {
-		m.ClientLogger.Infof("pair:qr: start connect account=%s", accountID)
-		m.pairingActive[accountID] = true
-		go func() {
-			if err := client.Connect(); err != nil {
-				m.ClientLogger.Errorf("pair:qr: connect err account=%s: %v", accountID, err)
-			}
-		}()
-	}
-	m.pairingMu.Unlock()
-
-	// Gunakan context.Background agar QR websocket tidak tertutup saat HTTP handler selesai
-	qrChan, _ := client.GetQRChannel(context.Background())
-	m.ClientLogger.Infof("pair:qr: waiting code account=%s", accountID)
-
-	for {
-		select {
-		case item, ok := <-qrChan:
-			if !ok {
-				m.ClientLogger.Errorf("pair:qr: channel closed account=%s", accountID)
-				return nil, "", fmt.Errorf("qr channel closed")
-			}
-			if item.Event == "code" && item.Code != "" {
-				png, err := qrcode.Encode(item.Code, qrcode.Medium, 256)
-				if err != nil {
-					return nil, "", err
-				}
-				m.ClientLogger.Infof("pair:qr: got code len=%d account=%s", len(item.Code), accountID)
-				return png, item.Code, nil
-			}
-		case <-ctx.Done():
-			m.ClientLogger.Errorf("pair:qr: timeout/cancel account=%s: %v", accountID, ctx.Err())
-			return nil, "", ctx.Err()
-		}
-	}
-}
-
 func (m *Manager) ConnectIfPaired(accountID string) error {
 	client, err := m.ensureClient(accountID)
 	if err != nil {
 		return err
 	}
 	if client.Store.ID == nil {
-		return fmt.Errorf("not paired")
+		return notPaired(accountID)
 	}
 	m.ClientLogger.Infof("connect: account=%s", accountID)
 	// Toleransi jika sudah terkoneksi: whatsmeow.Connect() kadang mengembalikan error "already connected".
@@ -243,7 +244,7 @@ func (m *Manager) RequestPairingCode(ctx context.Context, accountID, msisdn stri
 		return "", err
 	}
 	if client.Store.ID != nil {
-		return "", fmt.Errorf("already paired")
+		return "", ErrAlreadyPaired
 	}
 	if msisdn == "" {
 		return "", fmt.Errorf("msisdn required")
@@ -293,6 +294,68 @@ func (m *Manager) GetClient(accountID string) (*whatsmeow.Client, error) {
 	return m.ensureClient(accountID)
 }
 
+// GroupFromInvite mengambil JID dan nama grup dari invite code (tanpa join).
+func (m *Manager) GroupFromInvite(ctx context.Context, accountID, inviteCode string) (string, string, error) {
+	client, err := m.ensureClient(accountID)
+	if err != nil {
+		return "", "", err
+	}
+	if !client.IsConnected() {
+		return "", "", notConnected(accountID)
+	}
+	ctx2, cancel := context.WithTimeout(ctx, 15*time.Second)
+	defer cancel()
+	info, err := client.GetGroupInfoFromLink(ctx2, inviteCode)
+	if err != nil {
+		return "", "", fmt.Errorf("invite link: %w", classify(err))
+	}
+	return info.JID.String(), info.Name, nil
+}
+
+// CreateGroup membuat grup baru dari akun dengan nama subject dan nomor peserta awal (62812...).
+// Mengembalikan JID grup dan jumlah peserta. Akun observer ditolak dengan ErrObserverAccount.
+func (m *Manager) CreateGroup(ctx context.Context, accountID, subject string, numbers []string) (string, int, error) {
+	if err := m.checkObserver(accountID); err != nil {
+		return "", 0, err
+	}
+	client, err := m.ensureClient(accountID)
+	if err != nil {
+		return "", 0, err
+	}
+	if !client.IsConnected() {
+		return "", 0, notConnected(accountID)
+	}
+	participants := make([]types.JID, 0, len(numbers))
+	for _, n := range numbers {
+		participants = append(participants, types.NewJID(n, types.DefaultUserServer))
+	}
+	ctx2, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	info, err := client.CreateGroup(ctx2, whatsmeow.ReqCreateGroup{Name: subject, Participants: participants})
+	if err != nil {
+		return "", 0, fmt.Errorf("create group: %w", classify(err))
+	}
+	return info.JID.String(), len(info.Participants), nil
+}
+
+// LeaveGroup membuat akun keluar dari grup di WhatsApp.
+func (m *Manager) LeaveGroup(ctx context.Context, accountID, groupJID string) error {
+	client, err := m.ensureClient(accountID)
+	if err != nil {
+		return err
+	}
+	if !client.IsConnected() {
+		return notConnected(accountID)
+	}
+	jid, err := types.ParseJID(groupJID)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidJID, err)
+	}
+	ctx2, cancel := context.WithTimeout(ctx, 15*time.Second)
+	defer cancel()
+	return classify(client.LeaveGroup(ctx2, jid))
+}
+
 // Logout disconnects and logs out the account device session.
 func (m *Manager) Logout(accountID string) error {
 	c, err := m.ensureClient(accountID)
@@ -306,9 +369,47 @@ func (m *Manager) Logout(accountID string) error {
 		m.ClientLogger.Errorf("logout: account=%s err=%v", accountID, err)
 	}
 	_ = m.Store.UpdateAccountStatus(accountID, "logged_out", "", nil)
+	m.emitAccountEvent(accountID, "logged_out", "manual logout")
 	return nil
 }
 
+// EmitAccountEvent mencatat event akun dari luar manager (mis. jeda error budget) dengan jalur
+// yang sama seperti perubahan status koneksi.
+func (m *Manager) EmitAccountEvent(accountID, status, msg string) {
+	m.emitAccountEvent(accountID, status, msg)
+}
+
+// emitAccountEvent mencatat perubahan status akun (untuk digest harian) dan meneruskannya
+// ke log shipper dan webhook (jika dikonfigurasi).
+func (m *Manager) emitAccountEvent(accountID, status, msg string) {
+	if m.Store != nil {
+		_ = m.Store.InsertAccountEvent(accountID, status, msg)
+	}
+	m.Ship.Emit(logship.Event{
+		Kind:      logship.KindAccount,
+		AccountID: accountID,
+		Status:    status,
+		Message:   msg,
+	})
+	m.Hooks.Emit("account."+status, map[string]any{
+		"account_id": accountID,
+		"msisdn":     m.lookupMSISDN(accountID),
+		"status":     status,
+		"message":    msg,
+	})
+}
+
+// ConnectionState melaporkan status pairing & koneksi dari client yang sudah dimuat.
+// Tidak membuat client baru, sehingga aman dipanggil untuk semua akun sekaligus.
+func (m *Manager) ConnectionState(accountID string) (paired, connected bool) {
+	c, ok := m.Clients[accountID]
+	if !ok || c == nil {
+		return false, false
+	}
+	paired = c.Store != nil && c.Store.ID != nil
+	return paired, c.IsConnected()
+}
+
 // DropAccount disconnects client and removes it from manager cache.
 func (m *Manager) DropAccount(accountID string) {
 	if c, ok := m.Clients[accountID]; ok && c != nil {
@@ -327,37 +428,101 @@ func (m *Manager) lookupMSISDN(accountID string) string {
 	return ""
 }
 
+// ErrObserverAccount dikembalikan jika akun dalam mode observer (hanya monitoring, tidak boleh mengirim).
+var ErrObserverAccount = errors.New("account is in observer mode")
+
+// checkObserver menolak aksi keluar (kirim, buat grup) dari akun observer.
+func (m *Manager) checkObserver(accountID string) error {
+	if m.Store == nil {
+		return nil
+	}
+	observer, err := m.Store.IsObserverAccount(accountID)
+	if err != nil {
+		return err
+	}
+	if observer {
+		return fmt.Errorf("%w: %s", ErrObserverAccount, accountID)
+	}
+	return nil
+}
+
 // SendText sends a plain text message to a group JID string like "12345-67890@g.us".
+// Observer accounts are refused with ErrObserverAccount.
 func (m *Manager) SendText(ctx context.Context, accountID, groupJID, text string) error {
+	if err := m.checkObserver(accountID); err != nil {
+		return err
+	}
 	c, err := m.ensureClient(accountID)
 	if err != nil {
 		return err
 	}
 	if c.Store == nil || c.Store.ID == nil {
-		return fmt.Errorf("account %s not paired", accountID)
+		return notPaired(accountID)
 	}
 	jid, err := types.ParseJID(groupJID)
 	if err != nil {
-		return fmt.Errorf("parse JID: %w", err)
+		return fmt.Errorf("%w: %w", ErrInvalidJID, err)
 	}
 	msg := &waProto.Message{Conversation: strptr(text)}
 	_, err = c.SendMessage(ctx, jid, msg)
 	return err
 }
 
+// CheckOnWhatsApp memeriksa nomor (digit internasional, tanpa "+") terdaftar di WhatsApp,
+// dalam batch kecil agar tidak memicu rate limit. Nomor yang tidak ada di hasil dianggap tidak terdaftar.
+func (m *Manager) CheckOnWhatsApp(ctx context.Context, accountID string, numbers []string) (map[string]bool, error) {
+	c, err := m.ensureClient(accountID)
+	if err != nil {
+		return nil, err
+	}
+	if !c.IsConnected() {
+		return nil, notConnected(accountID)
+	}
+	out := make(map[string]bool, len(numbers))
+	const batch = 50
+	for i := 0; i < len(numbers); i += batch {
+		end := i + batch
+		if end > len(numbers) {
+			end = len(numbers)
+		}
+		phones := make([]string, 0, end-i)
+		for _, n := range numbers[i:end] {
+			phones = append(phones, "+"+n)
+		}
+		res, err := c.IsOnWhatsApp(ctx, phones)
+		if err != nil {
+			return nil, fmt.Errorf("is on whatsapp: %w", classify(err))
+		}
+		for _, r := range res {
+			if r.IsIn {
+				out[strings.TrimPrefix(r.Query, "+")] = true
+			}
+		}
+		if end < len(numbers) {
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(2 * time.Second):
+			}
+		}
+	}
+	return out, nil
+}
+
 // strptr returns a pointer to the given string (helper for proto messages).
 func strptr(s string) *string { return &s }
 
 // FetchAndSyncGroups obtains joined groups via WhatsApp and persists into DB.
 // NOTE: This depends on whatsmeow's group list API. In case of API changes,
 // adapt the mapping (name/subject) accordingly.
+// Peran komunitas (induk, grup pengumuman, sub-grup) ikut disimpan per grup.
 func (m *Manager) FetchAndSyncGroups(ctx context.Context, accountID string) (int, error) {
 	client, err := m.ensureClient(accountID)
 	if err != nil {
 		return 0, err
 	}
 	if client.Store == nil || client.Store.ID == nil {
-		return 0, fmt.Errorf("not paired")
+		return 0, notPaired(accountID)
 	}
 
 	// Ensure the client is connected before fetching groups.
@@ -390,6 +555,7 @@ func (m *Manager) FetchAndSyncGroups(ctx context.Context, accountID string) (int
 	}
 
 	count := 0
+	ids := make([]string, 0, len(gmap))
 	for _, info := range gmap {
 		name := info.Name
 		gid := info.JID.String()
@@ -399,8 +565,23 @@ func (m *Manager) FetchAndSyncGroups(ctx context.Context, accountID string) (int
 		if err := m.Store.UpsertGroup(accountID, gid, name); err != nil {
 			return count, err
 		}
+		// Induk komunitas dan grup pengumuman dicatat agar bisa dikecualikan dari broadcast.
+		role, parent := communityRole(info)
+		if err := m.Store.SetGroupCommunity(gid, role, parent); err != nil {
+			return count, err
+		}
+		if n := len(info.Participants); n > 0 {
+			_ = m.Store.SetGroupParticipantCount(gid, n)
+		}
+		// Bahasa ditebak dari nama + deskripsi; language manual tetap diutamakan saat targeting.
+		_ = m.Store.SetGroupDetectedLanguage(gid, langdetect.Detect(name, info.Topic))
+		ids = append(ids, gid)
 		count++
 	}
+	// Keanggotaan per akun dipakai rotasi pengirim dalam pool.
+	if err := m.Store.ReplaceGroupMembers(accountID, ids); err != nil {
+		return count, err
+	}
 	return count, nil
 }
 
@@ -413,12 +594,12 @@ func (m *Manager) GetGroupParticipants(ctx context.Context, accountID, groupJID
 		return nil, err
 	}
 	if client.Store == nil || client.Store.ID == nil {
-		return nil, fmt.Errorf("not paired")
+		return nil, notPaired(accountID)
 	}
 
 	jid, err := types.ParseJID(groupJID)
 	if err != nil {
-		return nil, fmt.Errorf("parse JID: %w", err)
+		return nil, fmt.Errorf("%w: %w", ErrInvalidJID, err)
 	}
 
 	// Strategy 1: Check database cache first (very fast, no network needed)
@@ -457,6 +638,23 @@ func (m *Manager) GetGroupParticipants(ctx context.Context, accountID, groupJID
 	return participants, nil
 }
 
+// RefreshGroupParticipants selalu mengambil anggota dari WhatsApp (tanpa cache) lalu menyimpannya.
+// Dipakai job scraping latar belakang; klien harus sudah terhubung.
+func (m *Manager) RefreshGroupParticipants(ctx context.Context, accountID, groupJID string) ([]ParticipantInfo, error) {
+	client, err := m.ensureClient(accountID)
+	if err != nil {
+		return nil, err
+	}
+	if !client.IsConnected() {
+		return nil, notConnected(accountID)
+	}
+	jid, err := types.ParseJID(groupJID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidJID, err)
+	}
+	return m.fetchAndCacheParticipants(ctx, client, jid, groupJID)
+}
+
 // getCachedParticipants mengambil participants dari database cache
 func (m *Manager) getCachedParticipants(ctx context.Context, groupJID string) ([]ParticipantInfo, error) {
 	// Cache valid for 24 hours (1440 minutes)
@@ -464,19 +662,7 @@ func (m *Manager) getCachedParticipants(ctx context.Context, groupJID string) ([
 	if err != nil || !found {
 		return nil, fmt.Errorf("cache miss or error")
 	}
-
-	// Convert to ParticipantInfo
-	participants := make([]ParticipantInfo, 0, len(cached))
-	for _, p := range cached {
-		participants = append(participants, ParticipantInfo{
-			JID:          p.JID,
-			Number:       p.Number,
-			IsAdmin:      p.IsAdmin,
-			IsSuperAdmin: p.IsSuperAdmin,
-		})
-	}
-
-	return participants, nil
+	return cached, nil
 }
 
 // fetchAndCacheParticipants fetches participants from WhatsApp and caches them
@@ -491,14 +677,15 @@ func (m *Manager) fetchAndCacheParticipants(ctx context.Context, client *whatsme
 	if err != nil {
 		// Check for specific errors and provide helpful messages
 		errMsg := err.Error()
-		if strings.Contains(strings.ToLower(errMsg), "timeout") {
-			return nil, fmt.Errorf("grup tidak dapat diakses (timeout) - mungkin grup sudah tidak aktif atau Anda bukan anggota")
-		} else if strings.Contains(strings.ToLower(errMsg), "not found") {
-			return nil, fmt.Errorf("grup tidak ditemukan - mungkin grup sudah dihapus atau ID salah")
-		} else if strings.Contains(strings.ToLower(errMsg), "forbidden") {
-			return nil, fmt.Errorf("tidak ada akses ke grup - mungkin Anda sudah dikeluarkan dari grup")
+		err = classify(err)
+		if errors.Is(err, ErrTimeout) || strings.Contains(strings.ToLower(errMsg), "timeout") {
+			return nil, fmt.Errorf("%w: grup tidak dapat diakses (timeout) - mungkin grup sudah tidak aktif atau Anda bukan anggota", ErrTimeout)
+		} else if errors.Is(err, ErrGroupNotFound) || strings.Contains(strings.ToLower(errMsg), "not found") {
+			return nil, fmt.Errorf("%w: grup tidak ditemukan - mungkin grup sudah dihapus atau ID salah", ErrGroupNotFound)
+		} else if errors.Is(err, ErrGroupNoAccess) || strings.Contains(strings.ToLower(errMsg), "forbidden") {
+			return nil, fmt.Errorf("%w: tidak ada akses ke grup - mungkin Anda sudah dikeluarkan dari grup", ErrGroupNoAccess)
 		}
-		return nil, fmt.Errorf("gagal mengambil info grup: %v", err)
+		return nil, fmt.Errorf("gagal mengambil info grup: %w", err)
 	}
 	
 	// Convert to ParticipantInfo
@@ -512,23 +699,8 @@ func (m *Manager) fetchAndCacheParticipants(ctx context.Context, client *whatsme
 		})
 	}
 	
-	// Cache the results for next time
-	cacheData := make([]struct {
-		JID          string
-		Number       string
-		IsAdmin      bool
-		IsSuperAdmin bool
-	}, len(participants))
-	
-	for i, p := range participants {
-		cacheData[i].JID = p.JID
-		cacheData[i].Number = p.Number
-		cacheData[i].IsAdmin = p.IsAdmin
-		cacheData[i].IsSuperAdmin = p.IsSuperAdmin
-	}
-	
 	// Best effort cache save - don't fail if caching fails
-	if err := m.Store.CacheGroupParticipants(groupJID, cacheData); err != nil {
+	if err := m.Store.CacheGroupParticipants(groupJID, participants); err != nil {
 		m.ClientLogger.Errorf("participants: failed to cache for group %s: %v", groupJID, err)
 	} else {
 		m.ClientLogger.Infof("participants: cached %d members for group %s", len(participants), groupJID)
@@ -563,3 +735,76 @@ func (m *Manager) dispatchMessage(accountID string, evt *events.Message) {
 		}(handler)
 	}
 }
+
+// AddReceiptHandler registers a handler for message receipts
+func (m *Manager) AddReceiptHandler(handler ReceiptHandler) {
+	m.handlerMu.Lock()
+	defer m.handlerMu.Unlock()
+	m.receiptHandlers = append(m.receiptHandlers, handler)
+}
+
+// dispatchReceipt calls all registered receipt handlers (synchronously; handlers must be quick)
+func (m *Manager) dispatchReceipt(accountID string, evt *events.Receipt) {
+	m.handlerMu.RLock()
+	handlers := make([]ReceiptHandler, len(m.receiptHandlers))
+	copy(handlers, m.receiptHandlers)
+	m.handlerMu.RUnlock()
+
+	for _, h := range handlers {
+		func() {
+			defer func() {
+				if r := recover(); r != nil {
+					m.ClientLogger.Errorf("receipt handler panic: %v", r)
+				}
+			}()
+			h(accountID, evt)
+		}()
+	}
+}
+
+// AddGroupInfoHandler registers a handler for group info changes
+func (m *Manager) AddGroupInfoHandler(handler GroupInfoHandler) {
+	m.handlerMu.Lock()
+	defer m.handlerMu.Unlock()
+	m.groupHandlers = append(m.groupHandlers, handler)
+}
+
+// dispatchGroupInfo calls all registered group info handlers (synchronously; handlers must be quick)
+func (m *Manager) dispatchGroupInfo(accountID string, evt *events.GroupInfo) {
+	m.handlerMu.RLock()
+	handlers := make([]GroupInfoHandler, len(m.groupHandlers))
+	copy(handlers, m.groupHandlers)
+	m.handlerMu.RUnlock()
+
+	for _, h := range handlers {
+		func() {
+			defer func() {
+				if r := recover(); r != nil {
+					m.ClientLogger.Errorf("group info handler panic: %v", r)
+				}
+			}()
+			h(accountID, evt)
+		}()
+	}
+}
+
+// GroupDescriptions mengambil deskripsi (topic) semua grup yang diikuti akun dalam satu request.
+// Hanya untuk client yang sudah dimuat & terhubung; tidak memicu koneksi baru.
+func (m *Manager) GroupDescriptions(ctx context.Context, accountID string) (map[string]string, error) {
+	c, ok := m.Clients[accountID]
+	if !ok || c == nil || c.Store == nil || c.Store.ID == nil {
+		return nil, notPaired(accountID)
+	}
+	if !c.IsConnected() {
+		return nil, notConnected(accountID)
+	}
+	groups, err := c.GetJoinedGroups(ctx)
+	if err != nil {
+		return nil, err
+	}
+	out := make(map[string]string, len(groups))
+	for _, g := range groups {
+		out[g.JID.String()] = g.Topic
+	}
+	return out, nil
+}
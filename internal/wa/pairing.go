@@ -0,0 +1,146 @@
+package wa
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/skip2/go-qrcode"
+	"go.mau.fi/whatsmeow"
+)
+
+// QRCode kode pairing terbaru yang dikirim server WhatsApp beserta PNG-nya.
+type QRCode struct {
+	Code      string
+	PNG       []byte
+	ExpiresAt time.Time
+}
+
+// qrWatch menampung kode terbaru dari QR channel satu sesi pairing. Dilindungi pairingMu.
+type qrWatch struct {
+	latest  *QRCode
+	err     error
+	done    bool
+	changed chan struct{} // ditutup lalu diganti setiap ada perubahan
+}
+
+func (w *qrWatch) notify() {
+	close(w.changed)
+	w.changed = make(chan struct{})
+}
+
+// StartPairing mengembalikan PNG dan kode QR pairing yang sedang berlaku.
+func (m *Manager) StartPairing(ctx context.Context, accountID string) ([]byte, string, error) {
+	qr, err := m.PairingQR(ctx, accountID)
+	if err != nil {
+		return nil, "", err
+	}
+	return qr.PNG, qr.Code, nil
+}
+
+// PairingQR mengembalikan kode QR pairing yang masih berlaku untuk akun. Panggilan pertama
+// membuka QR channel (sebelum Connect, syarat whatsmeow) dengan context.Background agar socket
+// pairing tetap hidup setelah handler HTTP selesai; goroutine watchQR lalu terus mengikuti kode
+// baru yang dirotasi server (~20 detik) sampai pairing sukses/timeout, sehingga pemanggilan
+// berikutnya langsung mendapat kode terbaru. Jika kode terakhir sudah kedaluwarsa, tunggu kode
+// berikutnya atau ctx selesai.
+func (m *Manager) PairingQR(ctx context.Context, accountID string) (QRCode, error) {
+	client, err := m.ensureClient(accountID)
+	if err != nil {
+		return QRCode{}, err
+	}
+	if client.Store.ID != nil {
+		return QRCode{}, ErrAlreadyPaired
+	}
+
+	m.pairingMu.Lock()
+	w := m.qrWatches[accountID]
+	if w == nil || w.done {
+		qrChan, err := client.GetQRChannel(context.Background())
+		if errors.Is(err, whatsmeow.ErrQRAlreadyConnected) {
+			// Socket pairing lama (mis. dari pairing nomor) tidak punya QR channel: sambung ulang.
+			m.ClientLogger.Infof("pair:qr: reconnect for qr channel account=%s", accountID)
+			client.Disconnect()
+			qrChan, err = client.GetQRChannel(context.Background())
+		}
+		if err != nil {
+			m.pairingMu.Unlock()
+			return QRCode{}, err
+		}
+		w = &qrWatch{changed: make(chan struct{})}
+		m.qrWatches[accountID] = w
+		m.pairingActive[accountID] = true
+		go m.watchQR(accountID, qrChan, w)
+		m.ClientLogger.Infof("pair:qr: start connect account=%s", accountID)
+		go func() {
+			if err := client.Connect(); err != nil {
+				m.ClientLogger.Errorf("pair:qr: connect err account=%s: %v", accountID, err)
+			}
+		}()
+	}
+	m.pairingMu.Unlock()
+
+	for {
+		m.pairingMu.Lock()
+		if w.latest != nil && time.Now().Before(w.latest.ExpiresAt) {
+			qr := *w.latest
+			m.pairingMu.Unlock()
+			return qr, nil
+		}
+		if w.done {
+			err := w.err
+			m.pairingMu.Unlock()
+			return QRCode{}, err
+		}
+		changed := w.changed
+		m.pairingMu.Unlock()
+
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			m.ClientLogger.Errorf("pair:qr: timeout/cancel account=%s: %v", accountID, ctx.Err())
+			return QRCode{}, ctx.Err()
+		}
+	}
+}
+
+// watchQR mengikuti QR channel dan menyimpan kode terbaru sampai channel selesai.
+func (m *Manager) watchQR(accountID string, qrChan <-chan whatsmeow.QRChannelItem, w *qrWatch) {
+	for item := range qrChan {
+		m.pairingMu.Lock()
+		switch item.Event {
+		case whatsmeow.QRChannelEventCode:
+			png, err := qrcode.Encode(item.Code, qrcode.Medium, 256)
+			if err != nil {
+				w.err = err
+				break
+			}
+			w.latest = &QRCode{Code: item.Code, PNG: png, ExpiresAt: time.Now().Add(item.Timeout)}
+			w.err = nil
+			m.ClientLogger.Infof("pair:qr: got code len=%d ttl=%s account=%s", len(item.Code), item.Timeout, accountID)
+		case whatsmeow.QRChannelSuccess.Event:
+			w.latest, w.done, w.err = nil, true, ErrAlreadyPaired
+			m.ClientLogger.Infof("pair:qr: success account=%s", accountID)
+		default:
+			err := item.Error
+			if err == nil {
+				err = fmt.Errorf("pairing %s", item.Event)
+			}
+			w.latest, w.done, w.err = nil, true, err
+			m.ClientLogger.Errorf("pair:qr: %s account=%s: %v", item.Event, accountID, err)
+		}
+		w.notify()
+		m.pairingMu.Unlock()
+	}
+
+	m.pairingMu.Lock()
+	if !w.done {
+		w.latest, w.done, w.err = nil, true, fmt.Errorf("qr channel closed")
+		w.notify()
+	}
+	if m.qrWatches[accountID] == w {
+		m.pairingActive[accountID] = false
+	}
+	m.pairingMu.Unlock()
+}
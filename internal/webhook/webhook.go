@@ -0,0 +1,260 @@
+// Package webhook mengirim notifikasi event (kirim sukses/gagal, status akun, auto-join) sebagai
+// POST JSON ke URL yang dikonfigurasi di tabel webhooks. Setiap event dicatat dulu di
+// webhook_deliveries lalu dikirim worker di background dengan retry + backoff eksponensial,
+// sehingga event tidak hilang saat endpoint sedang down atau aplikasi restart.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"math/rand"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"promote/internal/model"
+	"promote/internal/storage"
+)
+
+// Nama event yang bisa dilanggan webhook.
+const (
+	EventSendSent         = "send.sent"
+	EventSendFailed       = "send.failed"
+	EventAccountOnline    = "account.online"
+	EventAccountLoggedOut = "account.logged_out"
+	EventAccountReplaced  = "account.replaced"
+	EventBudgetPaused     = "account.budget_paused"
+	EventBudgetResumed    = "account.budget_resumed"
+	EventAutoJoinJoined   = "autojoin.joined"
+	EventAutoJoinFailed   = "autojoin.failed"
+	EventAutoJoinSkipped  = "autojoin.skipped"
+	EventAutoJoinQueued   = "autojoin.queued"
+	EventAutoJoinEnabled  = "autojoin.group_enabled"
+	EventAutoLeaveLeft    = "autoleave.left"
+	EventSchedulerPaused  = "scheduler.paused"
+	EventSchedulerResumed = "scheduler.resumed"
+	EventTest             = "test"
+)
+
+// Events daftar event yang valid untuk filter webhook.
+var Events = []string{
+	EventSendSent, EventSendFailed,
+	EventAccountOnline, EventAccountLoggedOut, EventAccountReplaced,
+	EventBudgetPaused, EventBudgetResumed,
+	EventAutoJoinJoined, EventAutoJoinFailed, EventAutoJoinSkipped, EventAutoJoinQueued,
+	EventAutoJoinEnabled, EventAutoLeaveLeft,
+	EventSchedulerPaused, EventSchedulerResumed,
+	EventTest,
+}
+
+// ValidEvent true jika nama event dikenal.
+func ValidEvent(e string) bool {
+	for _, v := range Events {
+		if v == e {
+			return true
+		}
+	}
+	return false
+}
+
+// Envelope adalah body JSON yang dikirim ke webhook.
+type Envelope struct {
+	Event string         `json:"event"`
+	TS    time.Time      `json:"ts"`
+	Data  map[string]any `json:"data"`
+}
+
+// Dispatcher mengantrikan event dan mengirimkannya ke webhook. Nilai nil aman dipakai
+// (Emit menjadi no-op), sehingga pemanggil tidak perlu memeriksa konfigurasi.
+type Dispatcher struct {
+	Store  *storage.Store
+	Client *http.Client
+	// MaxAttempts total percobaan sebelum pengiriman ditandai failed (WEBHOOK_MAX_ATTEMPTS, default 6).
+	MaxAttempts int
+	// BaseBackoff jeda retry pertama, digandakan tiap percobaan sampai MaxBackoff.
+	BaseBackoff time.Duration
+	MaxBackoff  time.Duration
+	// Poll interval worker memeriksa pengiriman yang jatuh tempo.
+	Poll time.Duration
+
+	wake chan struct{}
+}
+
+// New membuat Dispatcher dengan konfigurasi dari env (WEBHOOK_MAX_ATTEMPTS, WEBHOOK_TIMEOUT_SEC).
+func New(store *storage.Store) *Dispatcher {
+	d := &Dispatcher{
+		Store:       store,
+		Client:      &http.Client{Timeout: 10 * time.Second},
+		MaxAttempts: 6,
+		BaseBackoff: 30 * time.Second,
+		MaxBackoff:  time.Hour,
+		Poll:        5 * time.Second,
+		wake:        make(chan struct{}, 1),
+	}
+	if n, err := strconv.Atoi(strings.TrimSpace(os.Getenv("WEBHOOK_MAX_ATTEMPTS"))); err == nil && n > 0 {
+		d.MaxAttempts = n
+	}
+	if n, err := strconv.Atoi(strings.TrimSpace(os.Getenv("WEBHOOK_TIMEOUT_SEC"))); err == nil && n > 0 {
+		d.Client.Timeout = time.Duration(n) * time.Second
+	}
+	return d
+}
+
+// Start menjalankan worker pengiriman sampai ctx selesai.
+func (d *Dispatcher) Start(ctx context.Context) {
+	go func() {
+		tick := time.NewTicker(d.Poll)
+		defer tick.Stop()
+		for {
+			d.drain(ctx)
+			select {
+			case <-ctx.Done():
+				return
+			case <-tick.C:
+			case <-d.wake:
+			}
+		}
+	}()
+}
+
+// Emit mengantrikan event untuk semua webhook aktif yang melanggannya.
+func (d *Dispatcher) Emit(event string, data map[string]any) {
+	if d == nil {
+		return
+	}
+	hooks, err := d.Store.ListWebhooks()
+	if err != nil {
+		log.Printf("[webhook] list err=%v", err)
+		return
+	}
+	var body []byte
+	for _, h := range hooks {
+		if !h.Enabled || !h.Wants(event) {
+			continue
+		}
+		if body == nil {
+			if body, err = json.Marshal(Envelope{Event: event, TS: time.Now().UTC(), Data: data}); err != nil {
+				log.Printf("[webhook] encode event=%s err=%v", event, err)
+				return
+			}
+		}
+		if _, err := d.Store.EnqueueWebhookDelivery(h.ID, event, body); err != nil {
+			log.Printf("[webhook] enqueue webhook=%s event=%s err=%v", h.ID, event, err)
+		}
+	}
+	if body != nil {
+		d.Wake()
+	}
+}
+
+// Wake membangunkan worker agar segera memproses antrean.
+func (d *Dispatcher) Wake() {
+	if d == nil {
+		return
+	}
+	select {
+	case d.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (d *Dispatcher) drain(ctx context.Context) {
+	for ctx.Err() == nil {
+		due, err := d.Store.DueWebhookDeliveries(time.Now(), 20)
+		if err != nil {
+			log.Printf("[webhook] due query err=%v", err)
+			return
+		}
+		if len(due) == 0 {
+			return
+		}
+		hooks := map[string]model.Webhook{}
+		for _, del := range due {
+			h, ok := hooks[del.WebhookID]
+			if !ok {
+				if h, err = d.Store.GetWebhook(del.WebhookID); err != nil {
+					_ = d.Store.MarkWebhookAttemptFailed(del.ID, 0, err.Error(), nil)
+					continue
+				}
+				hooks[del.WebhookID] = h
+			}
+			d.deliver(ctx, h, del)
+		}
+		if len(due) < 20 {
+			return
+		}
+	}
+}
+
+func (d *Dispatcher) deliver(ctx context.Context, h model.Webhook, del model.WebhookDelivery) {
+	code, err := d.post(ctx, h, del)
+	if err == nil {
+		if err := d.Store.MarkWebhookDelivered(del.ID, code); err != nil {
+			log.Printf("[webhook] mark delivered id=%d err=%v", del.ID, err)
+		}
+		return
+	}
+	attempt := del.Attempts + 1
+	var next *time.Time
+	if attempt < d.MaxAttempts && h.Enabled {
+		t := time.Now().Add(d.backoff(attempt))
+		next = &t
+	}
+	log.Printf("[webhook] delivery id=%d webhook=%s event=%s attempt=%d/%d err=%v", del.ID, h.ID, del.Event, attempt, d.MaxAttempts, err)
+	if err := d.Store.MarkWebhookAttemptFailed(del.ID, code, err.Error(), next); err != nil {
+		log.Printf("[webhook] mark failed id=%d err=%v", del.ID, err)
+	}
+}
+
+func (d *Dispatcher) post(ctx context.Context, h model.Webhook, del model.WebhookDelivery) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, h.URL, bytes.NewReader(del.Payload))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "promote-webhook/1")
+	req.Header.Set("X-Webhook-Event", del.Event)
+	req.Header.Set("X-Webhook-Delivery", strconv.FormatInt(del.ID, 10))
+	if h.Secret != "" {
+		req.Header.Set("X-Webhook-Signature", "sha256="+Sign(h.Secret, del.Payload))
+	}
+	resp, err := d.Client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// backoff: BaseBackoff * 2^(attempt-1) dengan jitter ±20%, maksimal MaxBackoff.
+func (d *Dispatcher) backoff(attempt int) time.Duration {
+	b := d.BaseBackoff
+	for i := 1; i < attempt && b < d.MaxBackoff; i++ {
+		b *= 2
+	}
+	if b > d.MaxBackoff {
+		b = d.MaxBackoff
+	}
+	j := time.Duration(float64(b) * 0.2 * (rand.Float64()*2 - 1))
+	return b + j
+}
+
+// Sign menghitung HMAC-SHA256 hex dari body dengan secret webhook.
+func Sign(secret string, body []byte) string {
+	m := hmac.New(sha256.New, []byte(secret))
+	m.Write(body)
+	return hex.EncodeToString(m.Sum(nil))
+}
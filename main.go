@@ -2,22 +2,74 @@ package main
 
 import (
 	"context"
+	"flag"
 	"log"
 	"net/http"
 	"os"
 
 	"promote/internal/autojoin"
+	"promote/internal/autoleave"
+	"promote/internal/compliance"
+	"promote/internal/cron"
+	"promote/internal/digest"
+	"promote/internal/dm"
+	"promote/internal/doctor"
+	"promote/internal/dynvar"
+	"promote/internal/feeds"
+	"promote/internal/grouptrial"
 	httpapi "promote/internal/http"
+	"promote/internal/linkhealth"
+	"promote/internal/logship"
+	"promote/internal/mediacache"
+	"promote/internal/paths"
+	"promote/internal/replyflow"
 	"promote/internal/scheduler"
+	"promote/internal/scrape"
 	"promote/internal/sender"
+	"promote/internal/service"
+	"promote/internal/shortlink"
 	"promote/internal/storage"
+	"promote/internal/telegram"
 	"promote/internal/wa"
+	"promote/internal/webhook"
 )
 
 func main() {
+	// `promote service install|uninstall`: pasang unit systemd / Windows service.
+	if len(os.Args) > 1 && os.Args[1] == "service" {
+		os.Exit(service.Command(os.Args[2:], os.Stdout, os.Stderr))
+	}
+	// `promote doctor [-fix]`: cek integritas data dan perbaikan terpandu.
+	if len(os.Args) > 1 && os.Args[1] == "doctor" {
+		os.Exit(doctor.Command(os.Args[2:], os.Stdout, os.Stderr))
+	}
+	envFile := flag.String("env-file", "", "muat KEY=VALUE dari file sebelum start (env yang sudah di-set tidak ditimpa)")
+	flag.Parse()
+	if *envFile != "" {
+		if err := service.LoadEnvFile(*envFile); err != nil {
+			log.Fatal(err)
+		}
+	}
+	if service.RunAsService(run) {
+		return
+	}
+	run()
+}
+
+func run() {
+	// Direktori kerja absolut (DATA_DIR, SESSION_DIR, UPLOAD_DIR) agar tidak bergantung CWD.
+	dirs, err := paths.FromEnv()
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := dirs.Ensure(); err != nil {
+		log.Fatal(err)
+	}
+	log.Printf("data=%s sessions=%s uploads=%s", dirs.DataDir, dirs.SessionDir, dirs.UploadDir)
+
 	dsn := os.Getenv("DB_DSN")
 	if dsn == "" {
-		dsn = "file:promote.db?_foreign_keys=on"
+		dsn = dirs.DSN()
 	}
 
 	store, err := storage.Open(dsn)
@@ -31,18 +83,127 @@ func main() {
 	if err != nil {
 		log.Fatal(err)
 	}
+	manager.SessionDir = dirs.SessionDir
+
+	// Cek integritas data saat start (hanya melapor; perbaikan lewat `promote doctor -fix` atau /api/doctor).
+	doc := doctor.New(store, manager, dirs.UploadDir)
+	if rep, err := doc.Check(); err != nil {
+		log.Printf("[doctor] integrity check failed: %v", err)
+	} else {
+		for _, is := range rep.Issues {
+			log.Printf("[doctor] %s: %s (%d); run `promote doctor` to review", is.Check, is.Message, is.Count)
+		}
+	}
+
+	// Log shipping opsional ke syslog/Loki/Elasticsearch (lihat LOGSHIP_* env).
+	ship := logship.FromEnv(ctx)
+	defer ship.Close()
+	manager.Ship = ship
+
+	// Webhook keluar untuk event kirim/akun/auto-join (dikelola di /api/webhooks).
+	hooks := webhook.New(store)
+	hooks.Start(ctx)
+	manager.Hooks = hooks
 
 	// Inisialisasi auto-join handler
 	autoJoiner := autojoin.New(store, manager)
+	autoJoiner.Hooks = hooks
 	manager.AddMessageHandler(autoJoiner.HandleMessage)
+	// Worker antrean persetujuan: item yang di-approve (dan retry join yang gagal sementara) di-join satu per satu.
+	autoJoiner.Start(ctx)
 	log.Println("Auto-join handler registered")
 
 	// Inisialisasi pengirim dan scheduler anti-spam (aktif otomatis dengan jendela aman WIB).
 	snd := sender.New(store, manager)
+	snd.UploadDir = dirs.UploadDir
+	// Intro pasca-join (intro_template_id di pengaturan auto-join) dikirim lewat sender yang sama.
+	autoJoiner.Sender = snd
+	// Receipt delivered -> ack; tanpa receipt sampai SEND_ACK_TIMEOUT_MIN -> soft bounce.
+	manager.AddReceiptHandler(snd.HandleReceipt)
+	snd.StartAckWatcher(ctx)
+	// Mirror Telegram opsional untuk promo yang sukses (setting telegram_mirror, /api/settings/telegram).
+	snd.Mirror = telegram.New(ctx, snd.Fetch)
+	if cfg, err := store.TelegramMirror(); err != nil {
+		log.Printf("[telegram] load mirror settings: %v", err)
+	} else {
+		snd.SetTelegramMirror(cfg)
+	}
+	// Short link bermerek untuk URL di promo (SHORTLINK_BASE_URL, SHORTLINK_REWRITE=1).
+	links := shortlink.FromEnv(store)
+	snd.Links = links
+	// Variabel dinamis {name:key} di-resolve lewat HTTP lookup (dikelola di /api/dynamic-vars).
+	snd.Vars = dynvar.New(store)
+	// Cache media remote + prefetch di luar jendela kirim (MEDIA_CACHE_*, MEDIA_PREFETCH_*).
+	mediaCache := mediacache.FromEnv(dirs.DataDir)
+	snd.Media = mediaCache
+	prefetch := mediacache.NewPrefetcher(store, mediaCache, snd.Fetch)
 	sched := scheduler.New(store, manager, snd)
+	sched.Prefetch = prefetch
 	sched.Start(ctx)
 
-	router := httpapi.NewRouter(store, manager, autoJoiner)
+	// Opt-in "balas KATA": balasan grup dicatat, pengirimnya di-DM template follow-up (REPLY_FLOW_DM_GAP_SEC).
+	replies := replyflow.New(store, manager, snd)
+	manager.AddMessageHandler(replies.HandleMessage)
+	replies.Start(ctx)
+
+	// Masa uji grup baru: balasan ke pesan akun dan akun dikeluarkan dicatat; verdict oleh scheduler.
+	trials := grouptrial.New(store, manager)
+	manager.AddMessageHandler(trials.HandleMessage)
+	manager.AddGroupInfoHandler(trials.HandleGroupInfo)
+
+	// Feed watcher: item RSS/Atom/JSON baru -> template (approval atau langsung rotasi).
+	feedWatcher := feeds.New(store, snd.Client)
+	feedWatcher.UploadDir = dirs.UploadDir
+	feedWatcher.Start(ctx)
+
+	// Scan deskripsi grup untuk aturan "dilarang promo" (COMPLIANCE_SCAN_HOURS).
+	complianceScanner := compliance.New(store, manager)
+	complianceScanner.Start(ctx)
+
+	// Cek link undangan grup milik sendiri: dicabut atau grup penuh (LINK_HEALTH_HOURS, LINK_HEALTH_MEMBER_CAP).
+	linkMonitor := linkhealth.New(store, manager)
+	linkMonitor.Start(ctx)
+
+	// Keluar otomatis dari grup bernilai rendah menurut kebijakan /api/settings/auto-leave (AUTO_LEAVE_HOURS).
+	autoLeaver := autoleave.New(store, manager)
+	autoLeaver.Hooks = hooks
+	autoLeaver.Start(ctx)
+
+	// Digest harian per akun (DIGEST_AT, DIGEST_WEBHOOK_URL, DIGEST_OWNER).
+	digestRunner := digest.New(store, manager)
+	digestRunner.Start(ctx)
+
+	// Task housekeeping berkala (prune log, GC upload, rollup, cek template, checkpoint WAL).
+	cronRunner := cron.New(store)
+	housekeeping := cron.NewHousekeeping(store, dirs.UploadDir, digestRunner)
+	housekeeping.Media = mediaCache
+	housekeeping.Register(cronRunner)
+	cronRunner.Start(ctx)
+
+	// Scraping anggota grup bertahap (SCRAPE_DELAY_SEC, SCRAPE_MAX_AGE_HOURS, SCRAPE_EVERY_HOURS).
+	scraper := scrape.New(store, manager)
+	scraper.Start(ctx)
+
+	router := httpapi.NewRouter(store, manager, snd, autoJoiner, httpapi.Options{
+		Feeds:      feedWatcher,
+		Links:      links,
+		Compliance: complianceScanner,
+		LinkHealth: linkMonitor,
+		AutoLeave:  autoLeaver,
+		Digest:     digestRunner,
+		Cron:       cronRunner,
+		Scraper:    scraper,
+		// Preflight audiens DM (DM_FAILED_LOOKBACK_DAYS).
+		DMPreflight: dm.New(store, manager),
+		UploadDir:   dirs.UploadDir,
+		Webhooks:    hooks,
+		// Cek integritas data dan perbaikan terpandu.
+		Doctor: doc,
+		// Status/trigger prefetch media manual.
+		MediaPrefetch: prefetch,
+		// Key admin opsional; key lain dikelola lewat /api/keys.
+		AdminAPIKey: os.Getenv("ADMIN_API_KEY"),
+	})
 
 	port := os.Getenv("PORT")
 	if port == "" {